@@ -0,0 +1,43 @@
+package completion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/madstone-tech/maestro/pkg/config"
+)
+
+// Fetch asks the daemon on cfg's control transport for the names of a
+// kind that start with prefix.
+func Fetch(ctx context.Context, cfg *config.Config, kind Kind, prefix string) ([]string, error) {
+	client, err := cfg.ControlClient()
+	if err != nil {
+		return nil, err
+	}
+
+	address := cfg.Transport.Address
+	query := url.Values{"kind": {kind.String()}, "prefix": {prefix}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.ControlURL(Path+"?"+query.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response from %s: %s", address, resp.Status)
+	}
+
+	var response Response
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("invalid completion response from %s: %w", address, err)
+	}
+	return response.Names, nil
+}
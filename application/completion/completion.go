@@ -0,0 +1,256 @@
+// Package completion looks up library names for shell completion, with an
+// optional cache so that the daemon can answer repeated lookups quickly.
+package completion
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// Path is the daemon endpoint that answers completion lookups. It accepts a
+// GET with kind and prefix query parameters and answers with a JSON Response.
+const Path = "/v1/complete"
+
+// Kind is the kind of name being completed.
+type Kind int
+
+const (
+	// KindPlaylist completes playlist names
+	KindPlaylist Kind = iota
+
+	// KindArtist completes artist names
+	KindArtist
+
+	// KindAlbum completes album names
+	KindAlbum
+
+	// KindTrack completes track titles
+	KindTrack
+)
+
+// String returns the string representation of the Kind.
+func (k Kind) String() string {
+	switch k {
+	case KindPlaylist:
+		return "playlist"
+	case KindArtist:
+		return "artist"
+	case KindAlbum:
+		return "album"
+	case KindTrack:
+		return "track"
+	default:
+		return "unknown"
+	}
+}
+
+// IsValid returns true if the Kind is a valid value.
+func (k Kind) IsValid() bool {
+	return k >= KindPlaylist && k <= KindTrack
+}
+
+// ParseKind converts a name such as "artist" into a Kind.
+func ParseKind(name string) (Kind, error) {
+	for _, kind := range []Kind{KindPlaylist, KindArtist, KindAlbum, KindTrack} {
+		if strings.EqualFold(strings.TrimSpace(name), kind.String()) {
+			return kind, nil
+		}
+	}
+	return KindPlaylist, music.NewDomainError(music.ErrInvalidOperation,
+		fmt.Sprintf("unknown completion kind %q (expected playlist, artist, album or track)", name))
+}
+
+// Response is the body the daemon answers a completion lookup with.
+type Response struct {
+	// Names are the matching names, sorted
+	Names []string `json:"names"`
+}
+
+// Config configures a Completer.
+type Config struct {
+	// TTL is how long looked-up names are reused (0 disables caching)
+	TTL time.Duration
+
+	// Limit caps the names returned for one lookup (0 for no limit)
+	Limit int
+
+	// MinTrackPrefix is the shortest prefix that triggers a track search
+	MinTrackPrefix int
+
+	// MaxEntries caps the cached lookups
+	MaxEntries int
+}
+
+// DefaultConfig returns the default configuration, which does not cache.
+func DefaultConfig() *Config {
+	return &Config{
+		Limit:          50,
+		MinTrackPrefix: 2,
+		MaxEntries:     256,
+	}
+}
+
+// entry is a cached lookup.
+type entry struct {
+	names   []string
+	expires time.Time
+}
+
+// Completer finds the library names that start with a prefix.
+type Completer struct {
+	library music.LibraryRepository
+
+	mu      sync.Mutex
+	config  *Config
+	entries map[string]entry
+}
+
+// NewCompleter creates a completer for library.
+func NewCompleter(library music.LibraryRepository, config *Config) *Completer {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	return &Completer{library: library, config: config, entries: make(map[string]entry)}
+}
+
+// SetConfig replaces the configuration and drops the cached lookups.
+func (c *Completer) SetConfig(config *Config) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.config = config
+	c.entries = make(map[string]entry)
+}
+
+// Complete returns the sorted names of the given kind that start with
+// prefix, ignoring case. Track titles are only looked up once the prefix
+// is MinTrackPrefix long.
+func (c *Completer) Complete(ctx context.Context, kind Kind, prefix string) ([]string, error) {
+	if !kind.IsValid() {
+		return nil, music.NewDomainError(music.ErrInvalidOperation, "unknown completion kind")
+	}
+
+	c.mu.Lock()
+	config := *c.config
+	c.mu.Unlock()
+
+	// Whole lists are cached per kind; track searches per prefix
+	key := kind.String()
+	if kind == KindTrack {
+		if len([]rune(prefix)) < config.MinTrackPrefix {
+			return nil, nil
+		}
+		key += ":" + strings.ToLower(prefix)
+	}
+
+	names, ok := c.cached(key)
+	if !ok {
+		var err error
+		if names, err = c.lookup(ctx, kind, prefix); err != nil {
+			return nil, err
+		}
+		c.store(key, names, config)
+	}
+	return withPrefix(names, prefix, config.Limit), nil
+}
+
+// lookup queries the library for the names of a kind.
+func (c *Completer) lookup(ctx context.Context, kind Kind, prefix string) ([]string, error) {
+	switch kind {
+	case KindArtist:
+		return c.library.GetArtists(ctx)
+	case KindAlbum:
+		return c.library.GetAlbums(ctx)
+	case KindTrack:
+		tracks, err := c.library.Search(ctx, music.LibrarySearchOptions{
+			Query:  prefix,
+			Fields: []music.SearchField{music.SearchFieldTitle},
+		})
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, len(tracks))
+		for i, track := range tracks {
+			names[i] = track.Title
+		}
+		return names, nil
+	default:
+		playlists, err := c.library.GetPlaylists(ctx)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, len(playlists))
+		for i, playlist := range playlists {
+			names[i] = playlist.Name
+		}
+		return names, nil
+	}
+}
+
+// cached returns the names stored under key if they are still fresh.
+func (c *Completer) cached(key string) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.entries[key]
+	if !ok || time.Now().After(cached.expires) {
+		return nil, false
+	}
+	return cached.names, true
+}
+
+// store caches names under key, first dropping expired entries when the
+// cache is full, and every entry if that is not enough.
+func (c *Completer) store(key string, names []string, config Config) {
+	if config.TTL <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.entries) >= config.MaxEntries {
+		for k, cached := range c.entries {
+			if now.After(cached.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= config.MaxEntries {
+			c.entries = make(map[string]entry)
+		}
+	}
+	c.entries[key] = entry{names: names, expires: now.Add(config.TTL)}
+}
+
+// withPrefix returns the sorted, de-duplicated names that start with
+// prefix, ignoring case, keeping at most limit of them.
+func withPrefix(names []string, prefix string, limit int) []string {
+	lower := strings.ToLower(prefix)
+	seen := make(map[string]bool)
+	var matches []string
+	for _, name := range names {
+		if name == "" || seen[name] || !strings.HasPrefix(strings.ToLower(name), lower) {
+			continue
+		}
+		seen[name] = true
+		matches = append(matches, name)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return strings.ToLower(matches[i]) < strings.ToLower(matches[j])
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
@@ -0,0 +1,158 @@
+package completion
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/infrastructure/memory"
+	"github.com/madstone-tech/maestro/pkg/config"
+)
+
+// countingLibrary counts the library queries made through it.
+type countingLibrary struct {
+	music.LibraryRepository
+	queries int
+}
+
+func (l *countingLibrary) GetArtists(ctx context.Context) ([]string, error) {
+	l.queries++
+	return l.LibraryRepository.GetArtists(ctx)
+}
+
+func (l *countingLibrary) Search(ctx context.Context, options music.LibrarySearchOptions) ([]*music.Track, error) {
+	l.queries++
+	return l.LibraryRepository.Search(ctx, options)
+}
+
+func newLibrary() *countingLibrary {
+	return &countingLibrary{LibraryRepository: memory.NewRepositories(memory.DemoConfig())}
+}
+
+func TestParseKind(t *testing.T) {
+	for _, kind := range []Kind{KindPlaylist, KindArtist, KindAlbum, KindTrack} {
+		parsed, err := ParseKind(strings.ToUpper(kind.String()))
+		if err != nil || parsed != kind {
+			t.Errorf("ParseKind(%q) = %v, %v", kind.String(), parsed, err)
+		}
+	}
+	if _, err := ParseKind("genre"); err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+}
+
+func TestComplete(t *testing.T) {
+	completer := NewCompleter(newLibrary(), nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		kind   Kind
+		prefix string
+		want   []string
+	}{
+		{KindArtist, "j", []string{"John Coltrane"}},
+		{KindArtist, "", []string{"Bill Evans Trio", "Dave Brubeck Quartet", "John Coltrane", "Miles Davis"}},
+		{KindAlbum, "blue", []string{"Blue Train"}},
+		{KindPlaylist, "album", []string{"Album Openers"}},
+		{KindTrack, "so", []string{"So What", "Some Other Time"}},
+		{KindTrack, "s", nil},
+		{KindTrack, "zz", nil},
+	}
+
+	for _, tt := range tests {
+		got, err := completer.Complete(ctx, tt.kind, tt.prefix)
+		if err != nil {
+			t.Fatalf("Complete(%s, %q): %v", tt.kind, tt.prefix, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Complete(%s, %q) = %v, want %v", tt.kind, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestCompleteLimit(t *testing.T) {
+	config := DefaultConfig()
+	config.Limit = 2
+	completer := NewCompleter(newLibrary(), config)
+
+	got, err := completer.Complete(context.Background(), KindArtist, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Bill Evans Trio", "Dave Brubeck Quartet"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestCompleteCache(t *testing.T) {
+	library := newLibrary()
+	config := DefaultConfig()
+	config.TTL = time.Minute
+	completer := NewCompleter(library, config)
+	ctx := context.Background()
+
+	for _, prefix := range []string{"j", "m", ""} {
+		if _, err := completer.Complete(ctx, KindArtist, prefix); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if library.queries != 1 {
+		t.Errorf("expected artists to be looked up once, got %d queries", library.queries)
+	}
+
+	// Track searches are cached per prefix
+	_, _ = completer.Complete(ctx, KindTrack, "so")
+	_, _ = completer.Complete(ctx, KindTrack, "SO")
+	_, _ = completer.Complete(ctx, KindTrack, "som")
+	if library.queries != 3 {
+		t.Errorf("expected 2 track searches, got %d", library.queries-1)
+	}
+
+	completer.SetConfig(config)
+	_, _ = completer.Complete(ctx, KindArtist, "")
+	if library.queries != 4 {
+		t.Error("expected SetConfig to drop the cache")
+	}
+}
+
+func TestCompleteWithoutCache(t *testing.T) {
+	library := newLibrary()
+	completer := NewCompleter(library, nil)
+
+	_, _ = completer.Complete(context.Background(), KindArtist, "")
+	_, _ = completer.Complete(context.Background(), KindArtist, "")
+	if library.queries != 2 {
+		t.Errorf("expected every lookup to query the library, got %d queries", library.queries)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != Path || r.URL.Query().Get("kind") != "album" || r.URL.Query().Get("prefix") != "Kind of" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Response{Names: []string{"Kind of Blue"}})
+	}))
+	defer server.Close()
+	cfg := config.Default()
+	cfg.Transport.Address = strings.TrimPrefix(server.URL, "http://")
+
+	names, err := Fetch(context.Background(), cfg, KindAlbum, "Kind of")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"Kind of Blue"}) {
+		t.Errorf("unexpected names %v", names)
+	}
+
+	if _, err := Fetch(context.Background(), cfg, KindArtist, "x"); err == nil {
+		t.Error("expected an error for a non-200 response")
+	}
+}
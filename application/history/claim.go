@@ -0,0 +1,79 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// Claimer wraps a RepositoryManager and leaves a claim in the history store
+// whenever a command starts a track, so that the Recorder attributes the
+// track to maestro.
+type Claimer struct {
+	music.RepositoryManager
+	store music.HistoryRepository
+	now   func() time.Time
+}
+
+// Claiming wraps repos so that the commands that start a track claim it in
+// store.
+func Claiming(repos music.RepositoryManager, store music.HistoryRepository) *Claimer {
+	return &Claimer{RepositoryManager: repos, store: store, now: time.Now}
+}
+
+var (
+	_ music.RepositoryManager = (*Claimer)(nil)
+	_ music.PlayerBatcher     = (*Claimer)(nil)
+)
+
+// Play implements music.PlayerRepository.
+func (c *Claimer) Play(ctx context.Context, trackID music.TrackID) error {
+	return c.claim(ctx, c.RepositoryManager.Play(ctx, trackID))
+}
+
+// Start implements music.PlayerRepository. When stopped it plays the
+// current selection, so it claims playback like Play.
+func (c *Claimer) Start(ctx context.Context) error {
+	return c.claim(ctx, c.RepositoryManager.Start(ctx))
+}
+
+// Next implements music.PlayerRepository.
+func (c *Claimer) Next(ctx context.Context) error {
+	return c.claim(ctx, c.RepositoryManager.Next(ctx))
+}
+
+// Previous implements music.PlayerRepository.
+func (c *Claimer) Previous(ctx context.Context) error {
+	return c.claim(ctx, c.RepositoryManager.Previous(ctx))
+}
+
+// SetQueuePosition implements music.QueueRepository.
+func (c *Claimer) SetQueuePosition(ctx context.Context, position int) error {
+	return c.claim(ctx, c.RepositoryManager.SetQueuePosition(ctx, position))
+}
+
+// RunCommands implements music.PlayerBatcher, in one round trip when the
+// wrapped repositories batch commands. It claims playback when a command
+// that ran started playback or skipped to another track.
+func (c *Claimer) RunCommands(ctx context.Context, commands []music.PlayerCommand) (int, error) {
+	ran, err := music.RunPlayerCommands(ctx, c.RepositoryManager, commands)
+	for _, command := range commands[:ran] {
+		switch command.Kind {
+		case music.PlayerCommandStart, music.PlayerCommandNext, music.PlayerCommandPrevious:
+			_ = c.store.ClaimPlayback(ctx, c.now())
+			return ran, err
+		}
+	}
+	return ran, err
+}
+
+// claim claims playback when the command succeeded. A failing claim only
+// costs the attribution, so it does not fail the command.
+func (c *Claimer) claim(ctx context.Context, err error) error {
+	if err != nil {
+		return err
+	}
+	_ = c.store.ClaimPlayback(ctx, c.now())
+	return nil
+}
@@ -0,0 +1,258 @@
+// Package history records what was actually listened to.
+//
+// A Recorder follows the player through its events and appends an entry to
+// the history when a track passes its play threshold (see
+// music.PlayThreshold), or a skip when the track is left before that.
+// Listening time is measured by the clock while the player plays, so pauses
+// and seeks do not count.
+//
+// Music.app does not say who started a track, so maestro clients leave a
+// claim in the history store whenever they start one (see Claiming); a track
+// change observed close to an unused claim is attributed to maestro.
+package history
+
+import (
+	"context"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// restartWindow is how close to the start of the current track a jump back
+// must land to count as the track playing again, as with repeat one.
+const restartWindow = 5 * time.Second
+
+// Config configures a Recorder.
+type Config struct {
+	// Interval is how often Run checks whether the current track passed its
+	// play threshold
+	Interval time.Duration
+
+	// ClaimWindow is how close to a track change a claim must be for the
+	// change to be attributed to maestro
+	ClaimWindow time.Duration
+
+	// Now returns the current time
+	Now func() time.Time
+
+	// OnRecorded is called after each entry is appended, with the error
+	// that stopped it
+	OnRecorded func(entry *music.HistoryEntry, err error)
+}
+
+// DefaultConfig returns the default configuration, which checks every
+// second and attributes track changes within ten seconds of a claim.
+func DefaultConfig() *Config {
+	return &Config{
+		Interval:    time.Second,
+		ClaimWindow: 10 * time.Second,
+		Now:         time.Now,
+	}
+}
+
+// listen is the track being listened to.
+type listen struct {
+	entry music.HistoryEntry
+
+	// playing is whether the player was playing when last observed, and
+	// since is when that observation was made
+	playing bool
+	since   time.Time
+
+	// listened is the listening time up to since
+	listened time.Duration
+
+	// recorded is whether the play was already appended
+	recorded bool
+}
+
+// Recorder turns player events into history entries. Observe and Check are
+// not safe for concurrent use; Run calls them from a single goroutine.
+type Recorder struct {
+	player  music.PlayerRepository
+	library music.LibraryRepository
+	store   music.HistoryRepository
+	config  *Config
+
+	current *listen
+	// position is the player position when last observed
+	position music.Duration
+	// usedClaim is the claim last attributed, which is not used again
+	usedClaim time.Time
+}
+
+// NewRecorder creates a recorder that reads the player state from player,
+// snapshots tracks from library and appends entries to store.
+func NewRecorder(player music.PlayerRepository, library music.LibraryRepository,
+	store music.HistoryRepository, config *Config) *Recorder {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	return &Recorder{
+		player:  player,
+		library: library,
+		store:   store,
+		config:  config,
+	}
+}
+
+// Run records the history of the player events from source until ctx is
+// done. The track already playing is followed from when Run starts.
+func (r *Recorder) Run(ctx context.Context, source music.EventSource) {
+	events := source.Subscribe(ctx)
+
+	if state, err := r.player.GetCurrentState(ctx); err == nil {
+		r.Observe(ctx, state, r.config.Now())
+	}
+
+	var tick <-chan time.Time
+	if r.config.Interval > 0 {
+		ticker := time.NewTicker(r.config.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if event.IsPlayerEvent() && event.Player != nil {
+				r.Observe(ctx, event.Player, event.OccurredAt)
+			}
+		case <-tick:
+			r.Check(ctx, r.config.Now())
+		}
+	}
+}
+
+// Observe follows the player to the state observed at the given time: it
+// records the track that was left, if any, and starts following the one
+// loaded now.
+func (r *Recorder) Observe(ctx context.Context, player *music.Player, at time.Time) {
+	if r.current != nil && !r.current.entry.Track.ID.Equals(currentTrack(player)) {
+		r.finish(ctx, at)
+	}
+
+	if r.current != nil && r.restarted(player) {
+		if r.current.recorded {
+			r.finish(ctx, at)
+		}
+	}
+	r.position = player.Position
+
+	if r.current == nil {
+		if !player.HasCurrentTrack() {
+			return
+		}
+		r.start(ctx, *player.CurrentTrack, player.Position, at)
+	}
+
+	r.Check(ctx, at)
+	r.current.playing = player.IsPlaying()
+}
+
+// Check records the current track as played if it passed its play threshold
+// by the given time.
+func (r *Recorder) Check(ctx context.Context, at time.Time) {
+	if r.current == nil || r.current.recorded {
+		return
+	}
+	r.advance(at)
+	if r.current.listened >= music.PlayThreshold(r.current.entry.Track.Duration).ToTime() {
+		r.record(ctx, music.HistoryPlay, at)
+	}
+}
+
+// start begins following trackID, which was at position when observed at
+// the given time.
+func (r *Recorder) start(ctx context.Context, trackID music.TrackID, position music.Duration, at time.Time) {
+	track, err := r.library.GetTrack(ctx, trackID)
+	if err != nil || track == nil {
+		// The entry still says which track it was
+		track = &music.Track{ID: trackID}
+	}
+
+	r.current = &listen{
+		entry: music.HistoryEntry{
+			Track:     *track,
+			Source:    r.source(ctx, at),
+			StartedAt: at.Add(-position.ToTime()),
+		},
+		since: at,
+	}
+}
+
+// finish stops following the current track, recording it as skipped unless
+// it was already recorded as played.
+func (r *Recorder) finish(ctx context.Context, at time.Time) {
+	r.Check(ctx, at)
+	if !r.current.recorded {
+		r.record(ctx, music.HistorySkip, at)
+	}
+	r.current = nil
+}
+
+// advance adds the listening time up to at.
+func (r *Recorder) advance(at time.Time) {
+	if r.current.playing && at.After(r.current.since) {
+		r.current.listened += at.Sub(r.current.since)
+	}
+	if at.After(r.current.since) {
+		r.current.since = at
+	}
+}
+
+// record appends the current track as kind.
+func (r *Recorder) record(ctx context.Context, kind music.HistoryKind, at time.Time) {
+	r.current.recorded = true
+
+	entry := r.current.entry
+	entry.Kind = kind
+	entry.RecordedAt = at
+	entry.Listened = music.NewDurationFromTime(r.current.listened)
+
+	err := r.store.AppendHistory(ctx, &entry)
+	if r.config.OnRecorded != nil {
+		r.config.OnRecorded(&entry, err)
+	}
+}
+
+// restarted reports whether player jumped back to the start of the current
+// track.
+func (r *Recorder) restarted(player *music.Player) bool {
+	return player.Position.ToTime() < restartWindow && player.Position.Milliseconds() < r.position.Milliseconds() &&
+		r.position.ToTime()-player.Position.ToTime() > music.SeekTolerance
+}
+
+// source attributes a track change observed at the given time to maestro
+// when a claim was made close to it and not yet used.
+func (r *Recorder) source(ctx context.Context, at time.Time) music.HistorySource {
+	claim, err := r.store.LastClaim(ctx)
+	if err != nil || claim.IsZero() || !claim.After(r.usedClaim) {
+		return music.SourceExternal
+	}
+	gap := at.Sub(claim)
+	if gap < 0 {
+		gap = -gap
+	}
+	if gap > r.config.ClaimWindow {
+		return music.SourceExternal
+	}
+	r.usedClaim = claim
+	return music.SourceMaestro
+}
+
+// currentTrack returns the track loaded in player, or an empty ID.
+func currentTrack(player *music.Player) music.TrackID {
+	if !player.HasCurrentTrack() {
+		return music.TrackID{}
+	}
+	return *player.CurrentTrack
+}
@@ -0,0 +1,247 @@
+package history
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/infrastructure/memory"
+)
+
+var start = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+type fixture struct {
+	recorder *Recorder
+	store    *memory.History
+	repos    *memory.Repositories
+	tracks   []*music.Track
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	repos := memory.NewRepositories(memory.DemoConfig())
+	tracks, err := repos.GetAllTracks(context.Background(), 3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := memory.NewHistory()
+	config := DefaultConfig()
+	config.Now = func() time.Time { return start }
+	return &fixture{
+		recorder: NewRecorder(repos, repos, store, config),
+		store:    store,
+		repos:    repos,
+		tracks:   tracks,
+	}
+}
+
+// observe shows the recorder the player at the given offset from start.
+func (f *fixture) observe(state music.PlayerState, track *music.Track, position, offset time.Duration) {
+	player := &music.Player{State: state, Position: music.NewDurationFromTime(position)}
+	if track != nil {
+		id := track.ID
+		player.CurrentTrack = &id
+	}
+	f.recorder.Observe(context.Background(), player, start.Add(offset))
+}
+
+func (f *fixture) entries(t *testing.T) []*music.HistoryEntry {
+	t.Helper()
+	entries, err := f.store.GetHistory(context.Background(), music.HistoryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func TestRecorderRecordsPlayAtThreshold(t *testing.T) {
+	f := newFixture(t)
+	track := f.tracks[0]
+	threshold := music.PlayThreshold(track.Duration).ToTime()
+
+	f.observe(music.PlayerStatePlaying, track, 0, 0)
+	f.recorder.Check(context.Background(), start.Add(threshold-time.Second))
+	if entries := f.entries(t); len(entries) != 0 {
+		t.Fatalf("Expected nothing before the threshold, got %d entries", len(entries))
+	}
+
+	f.recorder.Check(context.Background(), start.Add(threshold))
+	entries := f.entries(t)
+	if len(entries) != 1 {
+		t.Fatalf("Expected a play at the threshold, got %d entries", len(entries))
+	}
+	entry := entries[0]
+	if entry.Kind != music.HistoryPlay || !entry.Track.ID.Equals(track.ID) || entry.Track.Title != track.Title ||
+		entry.Source != music.SourceExternal || !entry.StartedAt.Equal(start) || entry.Listened.ToTime() != threshold {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+
+	// Leaving a played track does not add a skip
+	f.observe(music.PlayerStatePlaying, f.tracks[1], 0, threshold+time.Minute)
+	if entries := f.entries(t); len(entries) != 1 {
+		t.Errorf("Expected the play only, got %d entries", len(entries))
+	}
+}
+
+func TestRecorderRecordsSkips(t *testing.T) {
+	f := newFixture(t)
+
+	f.observe(music.PlayerStatePlaying, f.tracks[0], 0, 0)
+	f.observe(music.PlayerStatePlaying, f.tracks[1], 0, 20*time.Second)
+	f.observe(music.PlayerStateStopped, nil, 0, 25*time.Second)
+
+	entries := f.entries(t)
+	if len(entries) != 2 {
+		t.Fatalf("Expected two skips, got %d entries", len(entries))
+	}
+	for i, want := range []time.Duration{20 * time.Second, 5 * time.Second} {
+		if entries[i].Kind != music.HistorySkip || !entries[i].Track.ID.Equals(f.tracks[i].ID) || entries[i].Listened.ToTime() != want {
+			t.Errorf("Entry %d: unexpected %+v", i, entries[i])
+		}
+	}
+}
+
+func TestRecorderIgnoresPauses(t *testing.T) {
+	f := newFixture(t)
+	track := f.tracks[0]
+	threshold := music.PlayThreshold(track.Duration).ToTime()
+
+	f.observe(music.PlayerStatePlaying, track, 0, 0)
+	f.observe(music.PlayerStatePaused, track, time.Minute, time.Minute)
+	f.recorder.Check(context.Background(), start.Add(30*time.Minute))
+	if entries := f.entries(t); len(entries) != 0 {
+		t.Fatalf("Expected paused time not to count, got %d entries", len(entries))
+	}
+
+	f.observe(music.PlayerStatePlaying, track, time.Minute, time.Hour)
+	f.recorder.Check(context.Background(), start.Add(time.Hour+threshold-time.Minute))
+	if entries := f.entries(t); len(entries) != 1 || entries[0].Kind != music.HistoryPlay {
+		t.Errorf("Expected a play once the playing time reached the threshold, got %v", entries)
+	}
+}
+
+func TestRecorderRepeatOne(t *testing.T) {
+	f := newFixture(t)
+	track := f.tracks[0]
+	length := track.Duration.ToTime()
+
+	f.observe(music.PlayerStatePlaying, track, 0, 0)
+	f.observe(music.PlayerStatePlaying, track, length-time.Second, length-time.Second)
+	f.observe(music.PlayerStatePlaying, track, time.Second, length+time.Second)
+	f.recorder.Check(context.Background(), start.Add(2*length))
+
+	entries := f.entries(t)
+	if len(entries) != 2 || entries[0].Kind != music.HistoryPlay || entries[1].Kind != music.HistoryPlay {
+		t.Fatalf("Expected a play for each time round, got %v", entries)
+	}
+	if !entries[1].StartedAt.Equal(start.Add(length)) {
+		t.Errorf("Expected the second play to start at %s, got %s", start.Add(length), entries[1].StartedAt)
+	}
+}
+
+func TestRecorderAttributesClaims(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	claimed := Claiming(f.repos, f.store)
+	claimed.now = func() time.Time { return start.Add(-2 * time.Second) }
+	if err := claimed.Play(ctx, f.tracks[0].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	f.observe(music.PlayerStatePlaying, f.tracks[0], 0, 0)
+	// The claim was used, so Music.app moving on is not attributed to maestro
+	f.observe(music.PlayerStatePlaying, f.tracks[1], 0, 3*time.Second)
+	// A claim long before a change does not count either
+	f.observe(music.PlayerStatePlaying, f.tracks[2], 0, time.Hour)
+	f.observe(music.PlayerStateStopped, nil, 0, time.Hour+time.Second)
+
+	entries := f.entries(t)
+	if len(entries) != 3 {
+		t.Fatalf("Expected three entries, got %d", len(entries))
+	}
+	for i, want := range []music.HistorySource{music.SourceMaestro, music.SourceExternal, music.SourceExternal} {
+		if entries[i].Source != want {
+			t.Errorf("Entry %d: source %s, want %s", i, entries[i].Source, want)
+		}
+	}
+}
+
+func TestClaimingClaimsTrackChanges(t *testing.T) {
+	ctx := context.Background()
+	repos := memory.NewRepositories(memory.DemoConfig())
+	store := memory.NewHistory()
+	claimed := Claiming(repos, store)
+
+	claimedAt := func() time.Time {
+		t.Helper()
+		at, err := store.LastClaim(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return at
+	}
+
+	claimed.now = func() time.Time { return start }
+	if err := claimed.SetVolume(ctx, music.NewVolume(30)); err != nil {
+		t.Fatal(err)
+	}
+	if !claimedAt().IsZero() {
+		t.Error("Expected a volume change not to claim playback")
+	}
+
+	tracks, err := repos.GetAllTracks(ctx, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := claimed.Play(ctx, tracks[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if !claimedAt().Equal(start) {
+		t.Errorf("Expected Play to claim playback at %s, got %s", start, claimedAt())
+	}
+
+	claimed.now = func() time.Time { return start.Add(time.Minute) }
+	ran, err := music.RunPlayerCommands(ctx, claimed, []music.PlayerCommand{
+		{Kind: music.PlayerCommandSetVolume, Volume: music.NewVolume(40)},
+		{Kind: music.PlayerCommandNext},
+	})
+	if err != nil || ran != 2 {
+		t.Fatalf("Expected both commands to run, got %d (%v)", ran, err)
+	}
+	if !claimedAt().Equal(start.Add(time.Minute)) {
+		t.Errorf("Expected a batched next to claim playback, got %s", claimedAt())
+	}
+
+	if err := claimed.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	claimed.now = func() time.Time { return start.Add(2 * time.Minute) }
+	if err := claimed.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !claimedAt().Equal(start.Add(2 * time.Minute)) {
+		t.Errorf("Expected Start to claim playback, got %s", claimedAt())
+	}
+
+	claimed.now = func() time.Time { return start.Add(3 * time.Minute) }
+	ran, err = music.RunPlayerCommands(ctx, claimed, []music.PlayerCommand{
+		{Kind: music.PlayerCommandPause},
+		{Kind: music.PlayerCommandStart},
+	})
+	if err != nil || ran != 2 {
+		t.Fatalf("Expected both commands to run, got %d (%v)", ran, err)
+	}
+	if !claimedAt().Equal(start.Add(3 * time.Minute)) {
+		t.Errorf("Expected a batched start to claim playback, got %s", claimedAt())
+	}
+
+	claimed.now = func() time.Time { return start.Add(4 * time.Minute) }
+	if err := claimed.Play(ctx, music.NewTrackID("missing")); err == nil {
+		t.Fatal("Expected playing a missing track to fail")
+	}
+	if !claimedAt().Equal(start.Add(3 * time.Minute)) {
+		t.Error("Expected a failed command not to claim playback")
+	}
+}
@@ -0,0 +1,228 @@
+package playback
+
+import (
+	"context"
+	"sync"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// DeferredConfig configures a Deferred player.
+type DeferredConfig struct {
+	// ContinueOnError runs the commands after a failed one instead of
+	// dropping them
+	ContinueOnError bool
+}
+
+// DefaultDeferredConfig returns a configuration that stops at the first
+// failure.
+func DefaultDeferredConfig() *DeferredConfig {
+	return &DeferredConfig{}
+}
+
+// deferredCommand is a held back command and the tag it was issued under.
+type deferredCommand struct {
+	command music.PlayerCommand
+	tag     int
+}
+
+// Deferred is a PlayerRepository that holds back player commands, such as
+// pauses and volume changes, and runs them together, in one round trip for
+// a music.PlayerBatcher, when Flush is called or something reads the
+// player. Commands are tagged, for example with the batch line that issued
+// them, so that failures found later can be traced back.
+type Deferred struct {
+	player music.PlayerRepository
+	config *DeferredConfig
+
+	mu       sync.Mutex
+	tag      int
+	pending  []deferredCommand
+	failures map[int]error
+	skipped  map[int]bool
+	halted   bool
+}
+
+// NewDeferred creates a Deferred player in front of player.
+func NewDeferred(player music.PlayerRepository, config *DeferredConfig) *Deferred {
+	if config == nil {
+		config = DefaultDeferredConfig()
+	}
+	return &Deferred{
+		player:   player,
+		config:   config,
+		failures: make(map[int]error),
+		skipped:  make(map[int]bool),
+	}
+}
+
+// Begin tags the commands issued from now on.
+func (d *Deferred) Begin(tag int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.tag = tag
+}
+
+// Flush runs the held back commands. Without ContinueOnError, a failure
+// drops the commands after it and halts the player: Flush and every later
+// call then return ErrInterrupted.
+func (d *Deferred) Flush(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.flush(ctx)
+}
+
+// flush runs the held back commands. Callers hold the lock.
+func (d *Deferred) flush(ctx context.Context) error {
+	for len(d.pending) > 0 && !d.halted {
+		commands := make([]music.PlayerCommand, len(d.pending))
+		for i, pending := range d.pending {
+			commands[i] = pending.command
+		}
+
+		done, err := music.RunPlayerCommands(ctx, d.player, commands)
+		if err == nil {
+			d.pending = nil
+			break
+		}
+
+		failed := d.pending[done]
+		if _, seen := d.failures[failed.tag]; !seen {
+			d.failures[failed.tag] = err
+		}
+		d.pending = d.pending[done+1:]
+		if !d.config.ContinueOnError {
+			d.halted = true
+		}
+	}
+
+	if d.halted {
+		for _, pending := range d.pending {
+			d.skipped[pending.tag] = true
+		}
+		d.pending = nil
+		return music.NewDomainError(music.ErrInterrupted, "an earlier command failed")
+	}
+	return nil
+}
+
+// Err returns the first failure of the commands issued under tag.
+func (d *Deferred) Err(tag int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.failures[tag]
+}
+
+// Skipped reports whether commands issued under tag were dropped after a
+// failure.
+func (d *Deferred) Skipped(tag int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.skipped[tag]
+}
+
+// Halted reports whether a failure stopped the player.
+func (d *Deferred) Halted() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.halted
+}
+
+// hold validates a command and holds it back until the next flush.
+func (d *Deferred) hold(command music.PlayerCommand) error {
+	if err := command.Validate(); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.halted {
+		d.skipped[d.tag] = true
+		return music.NewDomainError(music.ErrInterrupted, "an earlier command failed")
+	}
+	d.pending = append(d.pending, deferredCommand{command: command, tag: d.tag})
+	return nil
+}
+
+// Play runs the held back commands and then starts the track.
+func (d *Deferred) Play(ctx context.Context, trackID music.TrackID) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.flush(ctx); err != nil {
+		return err
+	}
+	return d.player.Play(ctx, trackID)
+}
+
+// Pause holds back a pause.
+func (d *Deferred) Pause(ctx context.Context) error {
+	return d.hold(music.PlayerCommand{Kind: music.PlayerCommandPause})
+}
+
+// Stop holds back a stop.
+func (d *Deferred) Stop(ctx context.Context) error {
+	return d.hold(music.PlayerCommand{Kind: music.PlayerCommandStop})
+}
+
+// Resume holds back a resume.
+func (d *Deferred) Resume(ctx context.Context) error {
+	return d.hold(music.PlayerCommand{Kind: music.PlayerCommandResume})
+}
+
+// Start holds back a start.
+func (d *Deferred) Start(ctx context.Context) error {
+	return d.hold(music.PlayerCommand{Kind: music.PlayerCommandStart})
+}
+
+// Next holds back a skip to the next track.
+func (d *Deferred) Next(ctx context.Context) error {
+	return d.hold(music.PlayerCommand{Kind: music.PlayerCommandNext})
+}
+
+// Previous holds back a skip to the previous track.
+func (d *Deferred) Previous(ctx context.Context) error {
+	return d.hold(music.PlayerCommand{Kind: music.PlayerCommandPrevious})
+}
+
+// Seek holds back a seek.
+func (d *Deferred) Seek(ctx context.Context, position music.Duration) error {
+	return d.hold(music.PlayerCommand{Kind: music.PlayerCommandSeek, Position: position})
+}
+
+// SetVolume holds back a volume change.
+func (d *Deferred) SetVolume(ctx context.Context, volume music.Volume) error {
+	return d.hold(music.PlayerCommand{Kind: music.PlayerCommandSetVolume, Volume: volume})
+}
+
+// SetShuffle holds back a shuffle change.
+func (d *Deferred) SetShuffle(ctx context.Context, enabled bool) error {
+	return d.hold(music.PlayerCommand{Kind: music.PlayerCommandSetShuffle, Shuffle: enabled})
+}
+
+// SetRepeat holds back a repeat mode change.
+func (d *Deferred) SetRepeat(ctx context.Context, mode music.RepeatMode) error {
+	return d.hold(music.PlayerCommand{Kind: music.PlayerCommandSetRepeat, Repeat: mode})
+}
+
+// GetCurrentState runs the held back commands and then reads the state.
+func (d *Deferred) GetCurrentState(ctx context.Context) (*music.Player, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.flush(ctx); err != nil {
+		return nil, err
+	}
+	return d.player.GetCurrentState(ctx)
+}
+
+// GetCurrentTrack runs the held back commands and then reads the track.
+func (d *Deferred) GetCurrentTrack(ctx context.Context) (*music.Track, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.flush(ctx); err != nil {
+		return nil, err
+	}
+	return d.player.GetCurrentTrack(ctx)
+}
+
+// Compile-time check that Deferred is a player repository.
+var _ music.PlayerRepository = (*Deferred)(nil)
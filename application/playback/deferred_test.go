@@ -0,0 +1,136 @@
+package playback
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/infrastructure/memory"
+)
+
+// batchingPlayer records the batches it runs and fails every seek.
+type batchingPlayer struct {
+	music.PlayerRepository
+	batches [][]music.PlayerCommand
+}
+
+func (p *batchingPlayer) RunCommands(ctx context.Context, commands []music.PlayerCommand) (int, error) {
+	p.batches = append(p.batches, commands)
+	for i, command := range commands {
+		if command.Kind == music.PlayerCommandSeek {
+			return i, music.NewDomainError(music.ErrOperationFailed, "seek failed")
+		}
+		if err := command.Apply(ctx, p.PlayerRepository); err != nil {
+			return i, err
+		}
+	}
+	return len(commands), nil
+}
+
+func newBatchingPlayer(t *testing.T) *batchingPlayer {
+	t.Helper()
+	repos := memory.NewRepositories(memory.DemoConfig())
+	if err := repos.Play(context.Background(), music.NewTrackID("1001")); err != nil {
+		t.Fatal(err)
+	}
+	return &batchingPlayer{PlayerRepository: repos}
+}
+
+func TestDeferredCoalesces(t *testing.T) {
+	ctx := context.Background()
+	player := newBatchingPlayer(t)
+	deferred := NewDeferred(player, nil)
+
+	deferred.Begin(1)
+	if err := deferred.Pause(ctx); err != nil {
+		t.Fatal(err)
+	}
+	deferred.Begin(2)
+	if err := deferred.SetVolume(ctx, music.NewVolume(30)); err != nil {
+		t.Fatal(err)
+	}
+	if len(player.batches) != 0 {
+		t.Fatalf("expected commands to be held back, got %v", player.batches)
+	}
+
+	state, err := deferred.GetCurrentState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(player.batches) != 1 || len(player.batches[0]) != 2 {
+		t.Fatalf("expected one batch of 2 commands, got %v", player.batches)
+	}
+	if state.State != music.PlayerStatePaused || state.Volume.Level() != 30 {
+		t.Errorf("expected a paused player at 30, got %s at %s", state.State, state.Volume)
+	}
+
+	if err := deferred.Flush(ctx); err != nil || len(player.batches) != 1 {
+		t.Errorf("expected nothing left to flush, got %v after %d batches", err, len(player.batches))
+	}
+}
+
+func TestDeferredStopsAtFailure(t *testing.T) {
+	ctx := context.Background()
+	player := newBatchingPlayer(t)
+	deferred := NewDeferred(player, nil)
+
+	deferred.Begin(1)
+	_ = deferred.Pause(ctx)
+	deferred.Begin(2)
+	_ = deferred.Seek(ctx, music.NewDuration(30))
+	deferred.Begin(3)
+	_ = deferred.SetVolume(ctx, music.NewVolume(10))
+
+	if err := deferred.Flush(ctx); !errors.Is(err, music.ErrInterrupted) {
+		t.Fatalf("expected ErrInterrupted, got %v", err)
+	}
+	if deferred.Err(1) != nil || !errors.Is(deferred.Err(2), music.ErrOperationFailed) || deferred.Err(3) != nil {
+		t.Errorf("expected only tag 2 to fail, got %v, %v, %v", deferred.Err(1), deferred.Err(2), deferred.Err(3))
+	}
+	if deferred.Skipped(1) || deferred.Skipped(2) || !deferred.Skipped(3) {
+		t.Error("expected only tag 3 to be skipped")
+	}
+	if !deferred.Halted() {
+		t.Error("expected the player to be halted")
+	}
+
+	deferred.Begin(4)
+	if err := deferred.Next(ctx); !errors.Is(err, music.ErrInterrupted) || !deferred.Skipped(4) {
+		t.Errorf("expected later commands to be skipped, got %v", err)
+	}
+}
+
+func TestDeferredContinuesAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	player := newBatchingPlayer(t)
+	deferred := NewDeferred(player, &DeferredConfig{ContinueOnError: true})
+
+	deferred.Begin(1)
+	_ = deferred.Seek(ctx, music.NewDuration(30))
+	deferred.Begin(2)
+	_ = deferred.SetVolume(ctx, music.NewVolume(10))
+
+	if err := deferred.Flush(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deferred.Err(1) == nil || deferred.Err(2) != nil || deferred.Skipped(2) || deferred.Halted() {
+		t.Errorf("expected only tag 1 to fail, got %v and %v", deferred.Err(1), deferred.Err(2))
+	}
+	if len(player.batches) != 2 {
+		t.Errorf("expected the rest to run in a second batch, got %v", player.batches)
+	}
+
+	state, err := deferred.GetCurrentState(ctx)
+	if err != nil || state.Volume.Level() != 10 {
+		t.Errorf("expected the volume change to run, got %v (%v)", state, err)
+	}
+}
+
+func TestDeferredValidates(t *testing.T) {
+	deferred := NewDeferred(newBatchingPlayer(t), nil)
+	err := deferred.SetRepeat(context.Background(), music.RepeatMode(42))
+	if !errors.Is(err, music.ErrInvalidRepeatMode) {
+		t.Errorf("expected ErrInvalidRepeatMode, got %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+// Package playback contains playback behaviour built on top of the player
+// repository, such as timed volume fades.
+package playback
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// FadePath is the daemon endpoint that starts a fade. It accepts a POST
+// with a JSON FadeRequest and answers 202 with the FadeRequest it started.
+const FadePath = "/v1/volume/fade"
+
+// MaxFadeDuration bounds how long a single fade may take.
+const MaxFadeDuration = 10 * time.Minute
+
+// FadeRequest describes a volume fade.
+type FadeRequest struct {
+	// Target is the volume to end at
+	Target music.Volume `json:"target"`
+
+	// Over is how long the fade takes
+	Over time.Duration `json:"over_ns"`
+
+	// Curve is the shape of the fade
+	Curve music.VolumeCurve `json:"curve"`
+}
+
+// Validate checks the request.
+func (r FadeRequest) Validate() error {
+	if !r.Target.IsValid() {
+		return music.WrapInvalidVolume(r.Target.Level(), nil)
+	}
+	if !r.Curve.IsValid() {
+		return music.NewDomainError(music.ErrInvalidVolume, "unknown fade curve")
+	}
+	if r.Over < 0 || r.Over > MaxFadeDuration {
+		return music.NewDomainError(music.ErrInvalidOperation,
+			fmt.Sprintf("fade duration must be between 0s and %s", MaxFadeDuration)).
+			WithContext("over", r.Over.String())
+	}
+	return nil
+}
+
+// FaderConfig configures a Fader.
+type FaderConfig struct {
+	// StepInterval is the time between volume steps
+	StepInterval time.Duration
+
+	// Tolerance is how far the volume may drift from the last step before
+	// the fade counts as interrupted by someone else
+	Tolerance int
+
+	// OnDone is called when a fade started with Start ends, with the volume
+	// it ended at and ErrInterrupted, a context error or nil
+	OnDone func(request FadeRequest, final music.Volume, err error)
+}
+
+// DefaultFaderConfig returns the default fader configuration.
+func DefaultFaderConfig() *FaderConfig {
+	return &FaderConfig{
+		StepInterval: 200 * time.Millisecond,
+		Tolerance:    1,
+	}
+}
+
+// Fader steps the player volume from its current level to a target.
+type Fader struct {
+	player music.PlayerRepository
+	config *FaderConfig
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewFader creates a fader for player.
+func NewFader(player music.PlayerRepository, config *FaderConfig) *Fader {
+	if config == nil {
+		config = DefaultFaderConfig()
+	}
+	return &Fader{player: player, config: config}
+}
+
+// Fade runs a fade to completion and returns the volume it ended at. It
+// stops early with ErrInterrupted when the volume is changed by someone
+// else, and with the context's error when ctx is done.
+func (f *Fader) Fade(ctx context.Context, request FadeRequest) (music.Volume, error) {
+	if err := request.Validate(); err != nil {
+		return music.Volume{}, err
+	}
+
+	state, err := f.player.GetCurrentState(ctx)
+	if err != nil {
+		return music.Volume{}, err
+	}
+	from, last := state.Volume, state.Volume
+
+	steps := int(request.Over / f.config.StepInterval)
+	if steps < 1 {
+		steps = 1
+	}
+	interval := request.Over / time.Duration(steps)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for step := 1; step <= steps; step++ {
+		if step > 1 {
+			timer.Reset(interval)
+			select {
+			case <-ctx.Done():
+				return last, ctx.Err()
+			case <-timer.C:
+			}
+
+			state, err := f.player.GetCurrentState(ctx)
+			if err != nil {
+				return last, err
+			}
+			if drift := state.Volume.Level() - last.Level(); drift > f.config.Tolerance || -drift > f.config.Tolerance {
+				return state.Volume, music.NewDomainError(music.ErrInterrupted,
+					fmt.Sprintf("fade stopped: volume was changed to %s", state.Volume)).
+					WithContext("volume", state.Volume.Level())
+			}
+		}
+
+		next := request.Curve.Interpolate(from, request.Target, float64(step)/float64(steps))
+		if next == last && step < steps {
+			continue
+		}
+		if err := f.player.SetVolume(ctx, next); err != nil {
+			return last, err
+		}
+		last = next
+	}
+	return last, nil
+}
+
+// Start runs a fade in the background, first stopping any fade started
+// earlier, and reports the outcome to FaderConfig.OnDone. The fade stops
+// when ctx is done.
+func (f *Fader) Start(ctx context.Context, request FadeRequest) error {
+	if err := request.Validate(); err != nil {
+		return err
+	}
+
+	f.Stop()
+
+	fadeCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	f.mu.Lock()
+	f.cancel, f.done = cancel, done
+	f.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		defer cancel()
+		final, err := f.Fade(fadeCtx, request)
+		if f.config.OnDone != nil {
+			f.config.OnDone(request, final, err)
+		}
+	}()
+	return nil
+}
+
+// Stop stops the background fade, if any, and waits for it to end.
+func (f *Fader) Stop() {
+	f.mu.Lock()
+	cancel, done := f.cancel, f.done
+	f.cancel, f.done = nil, nil
+	f.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
@@ -0,0 +1,115 @@
+package playback
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/infrastructure/memory"
+)
+
+// recordingPlayer records every volume set and can simulate a user
+// changing the volume after a number of steps.
+type recordingPlayer struct {
+	music.PlayerRepository
+	levels      []int
+	interruptAt int
+}
+
+func (p *recordingPlayer) SetVolume(ctx context.Context, volume music.Volume) error {
+	p.levels = append(p.levels, volume.Level())
+	if err := p.PlayerRepository.SetVolume(ctx, volume); err != nil {
+		return err
+	}
+	if len(p.levels) == p.interruptAt {
+		return p.PlayerRepository.SetVolume(ctx, music.NewVolume(90))
+	}
+	return nil
+}
+
+func newPlayer(t *testing.T, level int) *recordingPlayer {
+	t.Helper()
+	repos := memory.NewRepositories(memory.DemoConfig())
+	if err := repos.SetVolume(context.Background(), music.NewVolume(level)); err != nil {
+		t.Fatal(err)
+	}
+	return &recordingPlayer{PlayerRepository: repos}
+}
+
+func TestFade(t *testing.T) {
+	player := newPlayer(t, 80)
+	fader := NewFader(player, &FaderConfig{StepInterval: time.Millisecond, Tolerance: 1})
+
+	final, err := fader.Fade(context.Background(), FadeRequest{Target: music.NewVolume(20), Over: 4 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if final.Level() != 20 {
+		t.Errorf("expected to end at 20, got %d", final.Level())
+	}
+
+	want := []int{65, 50, 35, 20}
+	if len(player.levels) != len(want) {
+		t.Fatalf("expected steps %v, got %v", want, player.levels)
+	}
+	for i := range want {
+		if player.levels[i] != want[i] {
+			t.Errorf("expected steps %v, got %v", want, player.levels)
+		}
+	}
+}
+
+func TestFadeInterrupted(t *testing.T) {
+	player := newPlayer(t, 80)
+	player.interruptAt = 2
+	fader := NewFader(player, &FaderConfig{StepInterval: time.Millisecond, Tolerance: 1})
+
+	final, err := fader.Fade(context.Background(), FadeRequest{Target: music.NewVolume(0), Over: 10 * time.Millisecond, Curve: music.VolumeCurveLog})
+	if !errors.Is(err, music.ErrInterrupted) {
+		t.Fatalf("expected ErrInterrupted, got %v", err)
+	}
+	if final.Level() != 90 || len(player.levels) != 2 {
+		t.Errorf("expected the fade to stop at the user's 90 after 2 steps, got %d after %v", final.Level(), player.levels)
+	}
+}
+
+func TestFadeValidation(t *testing.T) {
+	fader := NewFader(newPlayer(t, 50), nil)
+	if _, err := fader.Fade(context.Background(), FadeRequest{Target: music.NewVolume(10), Over: time.Hour}); !errors.Is(err, music.ErrInvalidOperation) {
+		t.Errorf("expected an over-long fade to be rejected, got %v", err)
+	}
+}
+
+func TestStartReplacesRunningFade(t *testing.T) {
+	results := make(chan error, 2)
+	player := newPlayer(t, 50)
+	fader := NewFader(player, &FaderConfig{
+		StepInterval: 10 * time.Millisecond,
+		Tolerance:    1,
+		OnDone: func(_ FadeRequest, _ music.Volume, err error) {
+			results <- err
+		},
+	})
+
+	ctx := context.Background()
+	if err := fader.Start(ctx, FadeRequest{Target: music.NewVolume(0), Over: time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+	if err := fader.Start(ctx, FadeRequest{Target: music.NewVolume(60), Over: 20 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-results; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the first fade to be canceled, got %v", err)
+	}
+	select {
+	case err := <-results:
+		if err != nil {
+			t.Errorf("expected the second fade to finish, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("second fade did not finish")
+	}
+}
@@ -0,0 +1,115 @@
+// Package session contains the session management policies that decide how
+// different kinds of clients share control of the player.
+package session
+
+import (
+	"time"
+
+	"github.com/madstone-tech/maestro/pkg/config"
+)
+
+// ClientType identifies the kind of client talking to maestro.
+type ClientType int
+
+const (
+	// ClientTypeHuman is an interactive client such as the CLI or TUI
+	ClientTypeHuman ClientType = iota
+
+	// ClientTypeMCP is an AI assistant connected through the MCP server
+	ClientTypeMCP
+
+	// ClientTypeAdmin is an administrative client with takeover priority
+	ClientTypeAdmin
+)
+
+// String returns the string representation of the ClientType.
+func (c ClientType) String() string {
+	switch c {
+	case ClientTypeHuman:
+		return "human"
+	case ClientTypeMCP:
+		return "mcp"
+	case ClientTypeAdmin:
+		return "admin"
+	default:
+		return "unknown"
+	}
+}
+
+// IsValid returns true if the ClientType is a valid value.
+func (c ClientType) IsValid() bool {
+	return c >= ClientTypeHuman && c <= ClientTypeAdmin
+}
+
+// Policy describes how sessions for one client type behave.
+type Policy struct {
+	// ClientType is the kind of client this policy applies to
+	ClientType ClientType
+
+	// Stateless clients never hold a session; each request stands alone
+	Stateless bool
+
+	// Timeout is how long a session survives without activity (0 for stateless)
+	Timeout time.Duration
+
+	// CanTakeover indicates whether the client may take over another client's session
+	CanTakeover bool
+
+	// Priority orders takeover requests; higher wins
+	Priority int
+
+	// HeartbeatRequired indicates whether the client must send heartbeats
+	HeartbeatRequired bool
+
+	// RateLimit is the maximum number of requests per RateWindow (0 = unlimited)
+	RateLimit int
+
+	// RateWindow is the window over which RateLimit is applied
+	RateWindow time.Duration
+}
+
+// HasRateLimit returns true if requests from this client type are rate limited.
+func (p Policy) HasRateLimit() bool {
+	return p.RateLimit > 0 && p.RateWindow > 0
+}
+
+// PolicyFor returns the session policy for a client type, as defined in the
+// session management table of the project specification.
+func PolicyFor(clientType ClientType) Policy {
+	switch clientType {
+	case ClientTypeMCP:
+		return Policy{
+			ClientType: ClientTypeMCP,
+			Stateless:  true,
+			RateLimit:  20,
+			RateWindow: time.Minute,
+		}
+	case ClientTypeAdmin:
+		return Policy{
+			ClientType:        ClientTypeAdmin,
+			Timeout:           10 * time.Minute,
+			CanTakeover:       true,
+			Priority:          2,
+			HeartbeatRequired: true,
+		}
+	default:
+		return Policy{
+			ClientType:        ClientTypeHuman,
+			Timeout:           5 * time.Minute,
+			CanTakeover:       true,
+			Priority:          1,
+			HeartbeatRequired: true,
+		}
+	}
+}
+
+// ConfiguredPolicyFor returns the session policy for a client type with the
+// MCP rate limit taken from the session configuration.
+func ConfiguredPolicyFor(clientType ClientType, settings config.SessionConfig) Policy {
+	policy := PolicyFor(clientType)
+	if policy.ClientType == ClientTypeMCP {
+		policy.RateLimit = settings.MCPRateLimit
+		policy.RateWindow = settings.MCPRateWindow.Std()
+	}
+	return policy
+}
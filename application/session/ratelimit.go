@@ -0,0 +1,95 @@
+package session
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// RateLimiter enforces a sliding-window request limit.
+// It is safe for concurrent use.
+type RateLimiter struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time
+
+	mu       sync.Mutex
+	requests []time.Time
+}
+
+// NewRateLimiter creates a limiter allowing limit requests per window.
+// A limit of 0 allows every request.
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:  limit,
+		window: window,
+		now:    time.Now,
+	}
+}
+
+// NewRateLimiterForPolicy creates a limiter matching a session policy.
+func NewRateLimiterForPolicy(policy Policy) *RateLimiter {
+	if !policy.HasRateLimit() {
+		return NewRateLimiter(0, 0)
+	}
+	return NewRateLimiter(policy.RateLimit, policy.RateWindow)
+}
+
+// Allow records a request and reports whether it is within the limit.
+func (r *RateLimiter) Allow() bool {
+	return r.Reserve() == nil
+}
+
+// Reserve records a request, returning a music.ErrRateLimited domain error with
+// the time until the next slot frees up when the limit is exceeded.
+func (r *RateLimiter) Reserve() error {
+	if r.limit <= 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	cutoff := now.Add(-r.window)
+
+	kept := r.requests[:0]
+	for _, at := range r.requests {
+		if at.After(cutoff) {
+			kept = append(kept, at)
+		}
+	}
+	r.requests = kept
+
+	if len(r.requests) >= r.limit {
+		retryAfter := r.requests[0].Add(r.window).Sub(now)
+		return music.NewDomainError(
+			music.ErrRateLimited,
+			fmt.Sprintf("limit of %d requests per %s reached, retry in %s", r.limit, r.window, retryAfter.Round(time.Second)),
+		).WithContext("limit", r.limit).WithContext("retry_after_seconds", retryAfter.Seconds())
+	}
+
+	r.requests = append(r.requests, now)
+	return nil
+}
+
+// Remaining returns how many requests are still allowed in the current window.
+func (r *RateLimiter) Remaining() int {
+	if r.limit <= 0 {
+		return -1
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := r.now().Add(-r.window)
+	used := 0
+	for _, at := range r.requests {
+		if at.After(cutoff) {
+			used++
+		}
+	}
+	return r.limit - used
+}
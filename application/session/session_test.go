@@ -0,0 +1,103 @@
+package session
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/pkg/config"
+)
+
+func TestPolicyFor(t *testing.T) {
+	tests := []struct {
+		clientType ClientType
+		stateless  bool
+		timeout    time.Duration
+		rateLimit  int
+	}{
+		{ClientTypeHuman, false, 5 * time.Minute, 0},
+		{ClientTypeMCP, true, 0, 20},
+		{ClientTypeAdmin, false, 10 * time.Minute, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.clientType.String(), func(t *testing.T) {
+			policy := PolicyFor(tt.clientType)
+
+			if policy.ClientType != tt.clientType {
+				t.Errorf("expected client type %v, got %v", tt.clientType, policy.ClientType)
+			}
+			if policy.Stateless != tt.stateless {
+				t.Errorf("expected stateless %v, got %v", tt.stateless, policy.Stateless)
+			}
+			if policy.Timeout != tt.timeout {
+				t.Errorf("expected timeout %v, got %v", tt.timeout, policy.Timeout)
+			}
+			if policy.RateLimit != tt.rateLimit {
+				t.Errorf("expected rate limit %d, got %d", tt.rateLimit, policy.RateLimit)
+			}
+		})
+	}
+
+	if PolicyFor(ClientTypeAdmin).Priority <= PolicyFor(ClientTypeHuman).Priority {
+		t.Error("admin sessions should have priority over human sessions")
+	}
+}
+
+func TestConfiguredPolicyFor(t *testing.T) {
+	settings := config.Default().Session
+	settings.MCPRateLimit = 5
+
+	if got := ConfiguredPolicyFor(ClientTypeHuman, settings); got != PolicyFor(ClientTypeHuman) {
+		t.Errorf("expected the built-in human policy, got %+v", got)
+	}
+	mcp := ConfiguredPolicyFor(ClientTypeMCP, settings)
+	if mcp.RateLimit != 5 || mcp.RateWindow != time.Minute || !mcp.Stateless {
+		t.Errorf("unexpected MCP policy %+v", mcp)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(3, time.Minute)
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if !limiter.Allow() {
+			t.Fatalf("request %d should be allowed", i+1)
+		}
+	}
+
+	err := limiter.Reserve()
+	if !errors.Is(err, music.ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+
+	if limiter.Remaining() != 0 {
+		t.Errorf("expected 0 remaining, got %d", limiter.Remaining())
+	}
+
+	// Once the window has passed the oldest requests expire.
+	now = now.Add(61 * time.Second)
+	if !limiter.Allow() {
+		t.Error("request should be allowed after the window has passed")
+	}
+	if limiter.Remaining() != 2 {
+		t.Errorf("expected 2 remaining, got %d", limiter.Remaining())
+	}
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	limiter := NewRateLimiterForPolicy(PolicyFor(ClientTypeHuman))
+
+	for i := 0; i < 100; i++ {
+		if !limiter.Allow() {
+			t.Fatal("unlimited limiter should allow every request")
+		}
+	}
+
+	if limiter.Remaining() != -1 {
+		t.Errorf("expected -1 remaining for unlimited limiter, got %d", limiter.Remaining())
+	}
+}
@@ -0,0 +1,342 @@
+// Package smartlist materializes maestro's smart playlists into Music.app.
+//
+// Music.app does not let scripts read or write the rules of its own smart
+// playlists, so maestro keeps rules itself (see music.SmartPlaylist) and
+// writes the tracks they select into a regular user playlist of the same
+// name. Live-updating playlists are refreshed by the daemon whenever a
+// library change alters what their rules select.
+package smartlist
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// Config configures a Materializer.
+type Config struct {
+	// Interval is how often Run checks the library for changes (0 disables)
+	Interval time.Duration
+
+	// Now returns the current time; periods such as "in the last 30 days"
+	// are measured back from it
+	Now func() time.Time
+
+	// OnRefreshed is called after Refresh re-materializes a playlist, with the
+	// number of tracks written or the error that stopped it
+	OnRefreshed func(playlist *music.SmartPlaylist, tracks int, err error)
+}
+
+// DefaultConfig returns the default configuration, which refreshes every
+// five minutes.
+func DefaultConfig() *Config {
+	return &Config{
+		Interval: 5 * time.Minute,
+		Now:      time.Now,
+	}
+}
+
+// Materializer evaluates smart playlist rules against the library and keeps
+// the matching Music.app playlists in sync.
+type Materializer struct {
+	library   music.LibraryRepository
+	playlists music.PlaylistRepository
+	store     music.SmartPlaylistRepository
+
+	mu     sync.Mutex
+	config *Config
+	reset  chan struct{}
+
+	// written remembers the tracks last written per playlist, so that a
+	// refresh only touches playlists whose selection changed
+	written map[string]string
+}
+
+// NewMaterializer creates a materializer that reads tracks from library,
+// writes playlists and keeps rules in store.
+func NewMaterializer(library music.LibraryRepository, playlists music.PlaylistRepository,
+	store music.SmartPlaylistRepository, config *Config) *Materializer {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	return &Materializer{
+		library:   library,
+		playlists: playlists,
+		store:     store,
+		config:    config,
+		reset:     make(chan struct{}, 1),
+		written:   make(map[string]string),
+	}
+}
+
+// SetConfig replaces the configuration. A running Run picks up the new
+// interval straight away.
+func (m *Materializer) SetConfig(config *Config) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	m.mu.Lock()
+	m.config = config
+	m.mu.Unlock()
+
+	select {
+	case m.reset <- struct{}{}:
+	default:
+	}
+}
+
+// currentConfig returns a copy of the configuration.
+func (m *Materializer) currentConfig() Config {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	config := *m.config
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	return config
+}
+
+// Evaluate returns the library tracks that playlist's rules select.
+func (m *Materializer) Evaluate(ctx context.Context, playlist *music.SmartPlaylist) ([]*music.Track, error) {
+	tracks, err := m.library.GetAllTracks(ctx, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return playlist.Rules.Evaluate(tracks, m.currentConfig().Now())
+}
+
+// Materialize evaluates playlist's rules, writes the selected tracks into
+// its Music.app playlist, creating the playlist when it does not exist yet,
+// and saves the playlist with its Music.app ID. It returns the tracks
+// written.
+func (m *Materializer) Materialize(ctx context.Context, playlist *music.SmartPlaylist) ([]*music.Track, error) {
+	tracks, err := m.Evaluate(ctx, playlist)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.write(ctx, playlist, tracks); err != nil {
+		return nil, err
+	}
+	return tracks, nil
+}
+
+// Refresh re-materializes the live-updating playlists whose selection
+// changed since they were last written by this materializer, reading the
+// library once. It returns the playlists it wrote and the first error;
+// a failing playlist does not stop the others.
+func (m *Materializer) Refresh(ctx context.Context) ([]*music.SmartPlaylist, error) {
+	stored, err := m.store.GetSmartPlaylists(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var live []*music.SmartPlaylist
+	for _, playlist := range stored {
+		if playlist.Rules.LiveUpdating {
+			live = append(live, playlist)
+		}
+	}
+	if len(live) == 0 {
+		return nil, nil
+	}
+
+	library, err := m.library.GetAllTracks(ctx, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	config := m.currentConfig()
+	now := config.Now()
+	var refreshed []*music.SmartPlaylist
+	var firstErr error
+	for _, playlist := range live {
+		tracks, err := playlist.Rules.Evaluate(library, now)
+		if err == nil {
+			if m.unchanged(playlist, tracks) {
+				continue
+			}
+			err = m.write(ctx, playlist, tracks)
+		}
+		if config.OnRefreshed != nil {
+			config.OnRefreshed(playlist, len(tracks), err)
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		refreshed = append(refreshed, playlist)
+	}
+	return refreshed, firstErr
+}
+
+// Run calls Refresh every Interval until ctx is done. Errors are reported
+// through OnRefreshed.
+func (m *Materializer) Run(ctx context.Context) {
+	for {
+		var tick <-chan time.Time
+		var timer *time.Timer
+		if interval := m.currentConfig().Interval; interval > 0 {
+			timer = time.NewTimer(interval)
+			tick = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-m.reset:
+		case <-tick:
+			_, _ = m.Refresh(ctx)
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// Delete removes the smart playlist called name and, unless keepPlaylist
+// is set, its Music.app playlist.
+func (m *Materializer) Delete(ctx context.Context, name string, keepPlaylist bool) error {
+	playlist, err := m.store.GetSmartPlaylist(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !keepPlaylist && !playlist.PlaylistID.IsEmpty() {
+		err := m.playlists.DeletePlaylist(ctx, playlist.PlaylistID)
+		if err != nil && !errors.Is(err, music.ErrPlaylistNotFound) {
+			return err
+		}
+	}
+	if err := m.store.DeleteSmartPlaylist(ctx, playlist.Name); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	delete(m.written, key(playlist.Name))
+	m.mu.Unlock()
+	return nil
+}
+
+// write syncs the Music.app playlist with tracks and saves playlist.
+func (m *Materializer) write(ctx context.Context, playlist *music.SmartPlaylist, tracks []*music.Track) error {
+	target, err := m.target(ctx, playlist)
+	if err != nil {
+		return err
+	}
+	if err := m.sync(ctx, target, tracks); err != nil {
+		return err
+	}
+
+	playlist.PlaylistID = target.ID
+	playlist.MaterializedAt = m.currentConfig().Now()
+	if err := m.store.SaveSmartPlaylist(ctx, playlist); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.written[key(playlist.Name)] = fingerprint(target.ID, tracks)
+	m.mu.Unlock()
+	return nil
+}
+
+// unchanged reports whether tracks are what was last written for playlist.
+func (m *Materializer) unchanged(playlist *music.SmartPlaylist, tracks []*music.Track) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	written, ok := m.written[key(playlist.Name)]
+	return ok && written == fingerprint(playlist.PlaylistID, tracks)
+}
+
+// target returns the Music.app playlist holding playlist's tracks, creating
+// it when it was never created or has since been deleted.
+func (m *Materializer) target(ctx context.Context, playlist *music.SmartPlaylist) (*music.Playlist, error) {
+	if !playlist.PlaylistID.IsEmpty() {
+		target, err := m.library.GetPlaylist(ctx, playlist.PlaylistID)
+		if err == nil {
+			if target.ReadOnly {
+				return nil, music.NewDomainError(music.ErrPlaylistReadOnly, "smart playlist target is read-only").
+					WithContext("playlist_id", target.ID.Value())
+			}
+			return target, nil
+		}
+		if !errors.Is(err, music.ErrPlaylistNotFound) {
+			return nil, err
+		}
+	}
+	return m.playlists.CreatePlaylist(ctx, playlist.Name)
+}
+
+// sync makes target hold exactly tracks, in order, with as few changes as
+// possible.
+func (m *Materializer) sync(ctx context.Context, target *music.Playlist, tracks []*music.Track) error {
+	wanted := make(map[music.TrackID]bool, len(tracks))
+	order := make([]music.TrackID, len(tracks))
+	for i, track := range tracks {
+		wanted[track.ID] = true
+		order[i] = track.ID
+	}
+
+	// Removing a duplicate removes its first occurrence, so the resulting
+	// order is only known when nothing was duplicated
+	kept := make([]music.TrackID, 0, len(target.Tracks))
+	present := make(map[music.TrackID]bool, len(target.Tracks))
+	reorder := false
+	for _, id := range target.Tracks {
+		if wanted[id] && !present[id] {
+			present[id] = true
+			kept = append(kept, id)
+			continue
+		}
+		reorder = reorder || present[id]
+		if err := m.playlists.RemoveTrackFromPlaylist(ctx, target.ID, id); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range order {
+		if present[id] {
+			continue
+		}
+		if err := m.playlists.AddTrackToPlaylist(ctx, target.ID, id); err != nil {
+			return err
+		}
+		kept = append(kept, id)
+	}
+
+	if !reorder {
+		for i := range order {
+			if !kept[i].Equals(order[i]) {
+				reorder = true
+				break
+			}
+		}
+	}
+	if reorder && len(order) > 1 {
+		return m.playlists.ReorderPlaylistTracks(ctx, target.ID, order)
+	}
+	return nil
+}
+
+// key is the key a playlist's written tracks are remembered under.
+func key(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// fingerprint identifies a playlist ID and the tracks written to it.
+func fingerprint(id music.PlaylistID, tracks []*music.Track) string {
+	var b strings.Builder
+	b.WriteString(id.Value())
+	for _, track := range tracks {
+		b.WriteByte(0)
+		b.WriteString(track.ID.Value())
+	}
+	return b.String()
+}
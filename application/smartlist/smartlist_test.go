@@ -0,0 +1,253 @@
+package smartlist
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/infrastructure/memory"
+)
+
+// library serves the demo repositories with a library whose tracks can be
+// changed under the materializer.
+type library struct {
+	*memory.Repositories
+	tracks []*music.Track
+	reads  int
+}
+
+func (l *library) GetAllTracks(ctx context.Context, limit, offset int) ([]*music.Track, error) {
+	l.reads++
+	return l.tracks, nil
+}
+
+func newTestMaterializer(t *testing.T) (*Materializer, *library, *memory.SmartPlaylists) {
+	t.Helper()
+	repos := memory.NewRepositories(memory.DemoConfig())
+	tracks, err := repos.GetAllTracks(context.Background(), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lib := &library{Repositories: repos, tracks: tracks}
+	store := memory.NewSmartPlaylists()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	return NewMaterializer(lib, repos, store, &Config{Now: func() time.Time { return now }}), lib, store
+}
+
+func smartPlaylist(t *testing.T, name string, live bool, rules ...string) *music.SmartPlaylist {
+	t.Helper()
+	smart := music.SmartRules{LiveUpdating: live}
+	for _, text := range rules {
+		rule, err := music.ParseSmartRule(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		smart.Rules = append(smart.Rules, rule)
+	}
+	playlist, err := music.NewSmartPlaylist(name, smart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return playlist
+}
+
+func playlistTitles(t *testing.T, lib *library, id music.PlaylistID) []string {
+	t.Helper()
+	tracks, err := lib.GetPlaylistTracks(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	titles := make([]string, len(tracks))
+	for i, track := range tracks {
+		titles[i] = track.Title
+	}
+	return titles
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMaterializeCreatesAndSyncs(t *testing.T) {
+	ctx := context.Background()
+	materializer, lib, store := newTestMaterializer(t)
+
+	playlist := smartPlaylist(t, "Short Coltrane", false, "artist is John Coltrane", "duration less_than 5:00")
+	playlist.Rules.Sort = &music.SmartSort{Field: music.SmartFieldDuration}
+	tracks, err := materializer.Materialize(ctx, playlist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracks) != 3 || playlist.PlaylistID.IsEmpty() || playlist.MaterializedAt.IsZero() {
+		t.Fatalf("expected 3 tracks in a new playlist, got %d in %+v", len(tracks), playlist)
+	}
+	if got := playlistTitles(t, lib, playlist.PlaylistID); !equal(got, []string{"Countdown", "Naima", "Giant Steps"}) {
+		t.Errorf("unexpected playlist tracks %v", got)
+	}
+	if saved, err := store.GetSmartPlaylist(ctx, "short coltrane"); err != nil || !saved.PlaylistID.Equals(playlist.PlaylistID) {
+		t.Errorf("expected the playlist ID to be saved, got %+v (%v)", saved, err)
+	}
+
+	// Changing the rules reuses the playlist, keeping what still matches
+	playlist.Rules.Sort.Descending = true
+	playlist.Rules.Rules[1].Value = "4:30"
+	id := playlist.PlaylistID
+	if _, err := materializer.Materialize(ctx, playlist); err != nil {
+		t.Fatal(err)
+	}
+	if !playlist.PlaylistID.Equals(id) {
+		t.Errorf("expected playlist %s to be reused, got %s", id, playlist.PlaylistID)
+	}
+	if got := playlistTitles(t, lib, id); !equal(got, []string{"Naima", "Countdown"}) {
+		t.Errorf("unexpected playlist tracks %v", got)
+	}
+
+	// A playlist deleted in Music.app is created again
+	if err := lib.DeletePlaylist(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := materializer.Materialize(ctx, playlist); err != nil {
+		t.Fatal(err)
+	}
+	if got := playlistTitles(t, lib, playlist.PlaylistID); !equal(got, []string{"Naima", "Countdown"}) {
+		t.Errorf("expected the playlist to be created again, got %v", got)
+	}
+}
+
+func TestRefreshFollowsLibraryChanges(t *testing.T) {
+	ctx := context.Background()
+	var refreshed []string
+	materializer, lib, store := newTestMaterializer(t)
+	materializer.SetConfig(&Config{
+		Now: time.Now,
+		OnRefreshed: func(playlist *music.SmartPlaylist, tracks int, err error) {
+			if err != nil {
+				t.Errorf("unexpected error for %s: %v", playlist.Name, err)
+			}
+			refreshed = append(refreshed, playlist.Name)
+		},
+	})
+
+	for _, playlist := range []*music.SmartPlaylist{
+		smartPlaylist(t, "Loved", true, "loved is true"),
+		smartPlaylist(t, "Bill Evans", true, "artist contains evans"),
+		smartPlaylist(t, "Static", false, "loved is true"),
+	} {
+		if err := store.SaveSmartPlaylist(ctx, playlist); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The first refresh writes every live playlist
+	if _, err := materializer.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !equal(refreshed, []string{"Bill Evans", "Loved"}) || lib.reads != 1 {
+		t.Fatalf("expected both live playlists from one library read, got %v after %d reads", refreshed, lib.reads)
+	}
+
+	// Nothing changed, so nothing is written
+	refreshed = nil
+	if _, err := materializer.Refresh(ctx); err != nil || len(refreshed) != 0 {
+		t.Fatalf("expected no changes, got %v (%v)", refreshed, err)
+	}
+
+	// Loving a track only changes the loved playlist
+	loved := *lib.tracks[0]
+	loved.Loved = true
+	lib.tracks = append([]*music.Track{&loved}, lib.tracks[1:]...)
+	if _, err := materializer.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !equal(refreshed, []string{"Loved"}) {
+		t.Fatalf("expected only Loved to be refreshed, got %v", refreshed)
+	}
+	saved, err := store.GetSmartPlaylist(ctx, "Loved")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := playlistTitles(t, lib, saved.PlaylistID); !equal(got, []string{"So What"}) {
+		t.Errorf("unexpected playlist tracks %v", got)
+	}
+	if static, _ := store.GetSmartPlaylist(ctx, "Static"); !static.PlaylistID.IsEmpty() {
+		t.Error("expected a playlist that is not live to be left alone")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+	materializer, lib, store := newTestMaterializer(t)
+
+	playlist := smartPlaylist(t, "Waltzes", false, "title contains waltz")
+	if _, err := materializer.Materialize(ctx, playlist); err != nil {
+		t.Fatal(err)
+	}
+	if err := materializer.Delete(ctx, "WALTZES", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lib.GetPlaylist(ctx, playlist.PlaylistID); !errors.Is(err, music.ErrPlaylistNotFound) {
+		t.Errorf("expected the Music.app playlist to be deleted, got %v", err)
+	}
+	if _, err := store.GetSmartPlaylist(ctx, "Waltzes"); !errors.Is(err, music.ErrPlaylistNotFound) {
+		t.Errorf("expected the smart playlist to be deleted, got %v", err)
+	}
+
+	kept := smartPlaylist(t, "Kept", false, "title contains waltz")
+	if _, err := materializer.Materialize(ctx, kept); err != nil {
+		t.Fatal(err)
+	}
+	if err := materializer.Delete(ctx, "Kept", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lib.GetPlaylist(ctx, kept.PlaylistID); err != nil {
+		t.Errorf("expected the Music.app playlist to be kept, got %v", err)
+	}
+	if err := materializer.Delete(ctx, "Kept", false); !errors.Is(err, music.ErrPlaylistNotFound) {
+		t.Errorf("expected ErrPlaylistNotFound, got %v", err)
+	}
+}
+
+func TestRunStopsWithContext(t *testing.T) {
+	materializer, _, store := newTestMaterializer(t)
+	if err := store.SaveSmartPlaylist(context.Background(), smartPlaylist(t, "Jazz", true, "genre is jazz")); err != nil {
+		t.Fatal(err)
+	}
+	materializer.SetConfig(&Config{Interval: time.Millisecond, Now: time.Now})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		materializer.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		if saved, _ := store.GetSmartPlaylist(context.Background(), "Jazz"); !saved.PlaylistID.IsEmpty() {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatal("expected Run to materialize the live playlist")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected Run to return when the context is done")
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/madstone-tech/maestro/application/history"
+	"github.com/madstone-tech/maestro/application/session"
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/infrastructure/applescript"
+	"github.com/madstone-tech/maestro/infrastructure/filestore"
+	"github.com/madstone-tech/maestro/infrastructure/mcp"
+	"github.com/madstone-tech/maestro/pkg/config"
+	"github.com/madstone-tech/maestro/pkg/logger"
+	"github.com/madstone-tech/maestro/pkg/version"
+)
+
+func main() {
+	cfg, err := config.Load(config.DefaultLoadOptions("maestro"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	// stdout carries the MCP protocol, so logs must go to stderr.
+	logConfig := cfg.Log.Logger("maestro-mcp")
+	logConfig.Output = "stderr"
+	if err := logger.Initialize(logConfig); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Initialize infrastructure
+	executor := applescript.NewExecutor(applescript.ExecutorConfigFrom(cfg))
+	repos := applescript.NewRepositories(executor)
+
+	// Player events back the now-playing and queue resource subscriptions;
+	// the poller runs no scripts while nothing is subscribed
+	poller := applescript.NewPoller(repos, repos, nil)
+	go func() {
+		_ = poller.Run(ctx)
+	}()
+
+	// Tracks started through MCP are attributed to maestro in the history
+	var served music.RepositoryManager = repos
+	if cfg.History.Enabled {
+		served = history.Claiming(repos, filestore.NewHistory(&filestore.HistoryConfig{Path: cfg.History.File}))
+	}
+
+	policy := session.ConfiguredPolicyFor(session.ClientTypeMCP, cfg.Session)
+	server := mcp.NewServer(served, &mcp.ServerConfig{
+		Name:     "maestro-mcp",
+		Version:  version.Version,
+		Logger:   logger.Component("mcp"),
+		Events:   poller,
+		PageSize: 100,
+		Policy:   &policy,
+	})
+
+	if err := server.Serve(ctx, os.Stdin, os.Stdout); err != nil && err != context.Canceled {
+		logger.ErrorMsg("MCP server stopped", logger.Error(err))
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/madstone-tech/maestro/application/history"
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/infrastructure/applescript"
+	"github.com/madstone-tech/maestro/infrastructure/filestore"
+	"github.com/madstone-tech/maestro/infrastructure/memory"
+	"github.com/madstone-tech/maestro/pkg/config"
+	"github.com/madstone-tech/maestro/pkg/logger"
+	"github.com/madstone-tech/maestro/pkg/version"
+	"github.com/madstone-tech/maestro/presentation/tui"
+)
+
+func main() {
+	demo := flag.Bool("demo", false, "run against a built-in demo library instead of Music.app")
+	logFile := flag.String("log-file", os.DevNull, "file to write logs to")
+	showVersion := flag.Bool("version", false, "print the version and exit")
+	configFile := flag.String("config", "", "read configuration from this file as well")
+	var overrides []string
+	flag.Func("set", "override a configuration key (section.key=value); may be repeated", func(value string) error {
+		if !strings.Contains(value, "=") {
+			return fmt.Errorf("expected section.key=value")
+		}
+		overrides = append(overrides, value)
+		return nil
+	})
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println("maestro-tui", version.Version)
+		return
+	}
+
+	options := config.DefaultLoadOptions("maestro")
+	options.File = *configFile
+	options.Overrides = overrides
+	cfg, err := config.Load(options)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	// The terminal belongs to the UI, so logs must go to a file.
+	logConfig := cfg.Log.Logger("maestro-tui")
+	logConfig.Output = *logFile
+	if err := logger.Initialize(logConfig); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// The UI drives Music.app in-process, or the demo library. There is no
+	// daemon-backed mode: the daemon's control transport serves fades and
+	// completion only, not the repositories the views need.
+	var repos music.RepositoryManager
+	var events music.EventSource
+	if *demo {
+		backend := memory.NewRepositories(memory.DemoConfig())
+		repos, events = backend, backend
+	} else {
+		backend := applescript.NewRepositories(applescript.NewExecutor(applescript.ExecutorConfigFrom(cfg)))
+		poller := applescript.NewPoller(backend, backend, nil)
+		go func() {
+			_ = poller.Run(ctx)
+		}()
+		repos, events = backend, poller
+		// Tracks started from the UI are attributed to maestro in the history
+		if cfg.History.Enabled {
+			repos = history.Claiming(backend, filestore.NewHistory(&filestore.HistoryConfig{Path: cfg.History.File}))
+		}
+	}
+
+	config := tui.DefaultConfig()
+	config.Events = events
+	app := tui.NewApp(repos, config)
+
+	if err := app.Run(ctx, tea.WithAltScreen()); err != nil {
+		logger.ErrorMsg("TUI stopped", logger.Error(err))
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
@@ -2,68 +2,51 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"os"
 
+	"github.com/madstone-tech/maestro/application/history"
+	"github.com/madstone-tech/maestro/domain/music"
 	"github.com/madstone-tech/maestro/infrastructure/applescript"
+	"github.com/madstone-tech/maestro/infrastructure/filestore"
+	"github.com/madstone-tech/maestro/pkg/config"
+	"github.com/madstone-tech/maestro/pkg/logger"
 	"github.com/madstone-tech/maestro/presentation/cli"
-	"github.com/spf13/cobra"
-)
-
-var (
-	// Global flags
-	jsonOutput bool
-	verbose    bool
 )
 
 func main() {
-	rootCmd := &cobra.Command{
-		Use:   "maestro",
-		Short: "Maestro - Control your music from the command line",
-		Long: `Maestro is a command-line interface for controlling music playback.
-It provides simple commands to play, pause, skip tracks, and manage volume
-using your system's music player.`,
-		SilenceUsage:  true,
-		SilenceErrors: true,
-	}
-
-	// Add global flags
-	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
-	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
-
-	// Initialize infrastructure
-	executor := applescript.NewExecutor(nil)
-	playerRepo := applescript.NewPlayerRepository(executor)
-
-	// Create command context (OutputFormatter will be set in PersistentPreRun)
+	// Create command context (OutputFormatter and the repositories are set
+	// in PersistentPreRunE once the configuration is loaded)
 	ctx := context.Background()
 	cmdCtx := &cli.CommandContext{
-		Context:    ctx,
-		PlayerRepo: playerRepo,
+		Context: ctx,
 	}
+	cmdCtx.Connect = func(cfg *config.Config) error {
+		if err := logger.Initialize(cfg.Log.Logger("maestro")); err != nil {
+			return err
+		}
 
-	// Set up PersistentPreRun to initialize OutputFormatter after flags are parsed
-	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
-		cmdCtx.OutputFormatter = cli.NewOutputFormatter(jsonOutput, verbose)
+		// Initialize infrastructure
+		executor := applescript.NewExecutor(applescript.ExecutorConfigFrom(cfg))
+		var repos music.RepositoryManager = applescript.NewRepositories(executor)
+		historyStore := filestore.NewHistory(&filestore.HistoryConfig{Path: cfg.History.File})
+		if cfg.History.Enabled {
+			// Tracks started here are attributed to maestro in the history
+			repos = history.Claiming(repos, historyStore)
+		}
+		cmdCtx.PlayerRepo = repos
+		cmdCtx.LibraryRepo = repos
+		cmdCtx.QueueRepo = repos
+		cmdCtx.PlaylistRepo = repos
+		cmdCtx.RatingRepo = repos
+		cmdCtx.SmartPlaylistRepo = filestore.NewSmartPlaylists(&filestore.Config{Path: cfg.SmartPlaylists.File})
+		cmdCtx.HistoryRepo = historyStore
+		return nil
 	}
 
-	// Add all commands
-	rootCmd.AddCommand(cli.NewPlayCommand(cmdCtx))
-	rootCmd.AddCommand(cli.NewPauseCommand(cmdCtx))
-	rootCmd.AddCommand(cli.NewStopCommand(cmdCtx))
-	rootCmd.AddCommand(cli.NewResumeCommand(cmdCtx))
-	rootCmd.AddCommand(cli.NewNextCommand(cmdCtx))
-	rootCmd.AddCommand(cli.NewPreviousCommand(cmdCtx))
-	rootCmd.AddCommand(cli.NewVolumeCommand(cmdCtx))
-	rootCmd.AddCommand(cli.NewStatusCommand(cmdCtx))
+	rootCmd := cli.NewRootCommand(cmdCtx)
 
 	// Execute the command
 	if err := rootCmd.Execute(); err != nil {
-		if jsonOutput {
-			fmt.Fprintf(os.Stderr, `{"error": "%s"}%s`, err.Error(), "\n")
-		} else {
-			fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
-		}
-		os.Exit(1)
+		os.Exit(cli.ReportError(cmdCtx, rootCmd, err))
 	}
 }
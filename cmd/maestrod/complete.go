@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/madstone-tech/maestro/application/completion"
+	"github.com/madstone-tech/maestro/pkg/config"
+)
+
+// completionTimeout bounds the library queries made for one lookup. The
+// lookup outlives a client that gives up sooner, so that its result is
+// cached for the next one.
+const completionTimeout = 2 * time.Second
+
+// completionConfig returns the completer configuration for the cache
+// settings in cfg.
+func completionConfig(cfg config.CacheConfig) *completion.Config {
+	completionCfg := completion.DefaultConfig()
+	if cfg.Enabled {
+		completionCfg.TTL = cfg.TTL.Std()
+	}
+	return completionCfg
+}
+
+// completeHandler answers shell completion lookups from the daemon's cache.
+func (d *daemon) completeHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		kind, err := completion.ParseKind(r.URL.Query().Get("kind"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), completionTimeout)
+		defer cancel()
+
+		names, err := d.completer.Complete(ctx, kind, r.URL.Query().Get("prefix"))
+		if err != nil {
+			writeError(w, http.StatusServiceUnavailable, err)
+			return
+		}
+		if names == nil {
+			names = []string{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(completion.Response{Names: names})
+	})
+}
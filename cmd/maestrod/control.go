@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/madstone-tech/maestro/application/completion"
+	"github.com/madstone-tech/maestro/application/playback"
+	"github.com/madstone-tech/maestro/pkg/logger"
+)
+
+// controlShutdownTimeout bounds graceful shutdown of the control server.
+const controlShutdownTimeout = 5 * time.Second
+
+// controlHandler routes the requests clients send to the daemon, such as
+// fades and completion lookups, as opposed to health and metrics scrapes.
+func (d *daemon) controlHandler(ctx context.Context) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("POST "+playback.FadePath, d.fadeHandler(ctx))
+	mux.Handle("GET "+completion.Path, d.completeHandler())
+	return mux
+}
+
+// runControl serves the control endpoints on the transport address until
+// ctx is done: over mutual TLS when TLS is enabled, otherwise as plain HTTP,
+// which validation only allows on a loopback address.
+func (d *daemon) runControl(ctx context.Context) error {
+	listener, err := net.Listen("tcp", d.startup.Transport.Address)
+	if err != nil {
+		return fmt.Errorf("control server: %w", err)
+	}
+	if d.startup.TLS.Enabled {
+		tlsConfig, err := d.startup.TLS.ServerConfig()
+		if err != nil {
+			_ = listener.Close()
+			return fmt.Errorf("control server: %w", err)
+		}
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	logger.Info("Control server listening",
+		logger.String("address", d.startup.Transport.Address), logger.Bool("tls", d.startup.TLS.Enabled))
+
+	server := &http.Server{
+		Handler:           d.controlHandler(ctx),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), controlShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/madstone-tech/maestro/application/completion"
+	"github.com/madstone-tech/maestro/application/history"
+	"github.com/madstone-tech/maestro/application/playback"
+	"github.com/madstone-tech/maestro/application/smartlist"
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/infrastructure/applescript"
+	"github.com/madstone-tech/maestro/infrastructure/filestore"
+	"github.com/madstone-tech/maestro/infrastructure/metrics"
+	"github.com/madstone-tech/maestro/pkg/config"
+	"github.com/madstone-tech/maestro/pkg/health"
+	"github.com/madstone-tech/maestro/pkg/logger"
+	"github.com/madstone-tech/maestro/pkg/version"
+)
+
+// certificateWarning is how long before expiry the certificate check
+// reports degraded.
+const certificateWarning = 30 * 24 * time.Hour
+
+// daemon owns the long-lived infrastructure and applies configuration
+// reloads to it.
+type daemon struct {
+	executor  *applescript.Executor
+	repos     *applescript.Repositories
+	poller    *applescript.Poller
+	fader     *playback.Fader
+	completer *completion.Completer
+	smart     *smartlist.Materializer
+	history   *history.Recorder // nil when history is disabled
+	health    *health.Registry
+	metrics   *metrics.Metrics
+
+	// startup is the configuration the daemon was started with; keys that
+	// are not reload-safe keep these values until restart
+	startup *config.Config
+}
+
+func newDaemon(cfg *config.Config) *daemon {
+	executor := applescript.NewExecutor(applescript.ExecutorConfigFrom(cfg))
+	repos := applescript.NewRepositories(executor)
+
+	// Everything the daemon serves goes through the instrumented repositories
+	var served music.RepositoryManager = repos
+	var m *metrics.Metrics
+	if cfg.Metrics.Enabled {
+		m = metrics.New()
+		executor.SetObserver(m)
+		served = metrics.InstrumentRepositories(repos, m)
+	}
+
+	d := &daemon{
+		executor: executor,
+		repos:    repos,
+		poller:   applescript.NewPoller(served, served, nil),
+		fader: playback.NewFader(served, &playback.FaderConfig{
+			StepInterval: playback.DefaultFaderConfig().StepInterval,
+			Tolerance:    playback.DefaultFaderConfig().Tolerance,
+			OnDone:       fadeDone,
+		}),
+		completer: completion.NewCompleter(served, completionConfig(cfg.Cache)),
+		smart: smartlist.NewMaterializer(served, served,
+			filestore.NewSmartPlaylists(&filestore.Config{Path: cfg.SmartPlaylists.File}), smartConfig(cfg.SmartPlaylists)),
+		metrics: m,
+		startup: cfg,
+		health: health.NewRegistry(&health.RegistryConfig{
+			DefaultTimeout:  cfg.Health.Timeout.Std(),
+			DefaultCacheTTL: cfg.Health.CacheTTL.Std(),
+			Version:         version.Version,
+		}),
+	}
+	if cfg.History.Enabled {
+		d.history = history.NewRecorder(served, served,
+			filestore.NewHistory(&filestore.HistoryConfig{Path: cfg.History.File}), historyConfig())
+	}
+	d.registerChecks(cfg)
+	return d
+}
+
+// registerChecks registers a health check for each component.
+func (d *daemon) registerChecks(cfg *config.Config) {
+	checks := []health.Check{
+		{Name: "executor", Func: d.executor.HealthCheck, Critical: true},
+		{Name: "music_app", Func: d.repos.PlayerRepository.HealthCheck, Critical: true},
+	}
+	if cfg.TLS.Enabled {
+		checks = append(checks, health.Check{
+			Name:     "certificates",
+			Func:     health.CertificateCheck(cfg.TLS.CAFile, cfg.TLS.CertFile, cfg.TLS.KeyFile, certificateWarning),
+			Critical: true,
+			CacheTTL: time.Minute,
+		})
+	}
+	for _, check := range checks {
+		if err := d.health.Register(check); err != nil {
+			logger.ErrorMsg("Failed to register health check", logger.String("check", check.Name), logger.Error(err))
+		}
+	}
+}
+
+// run keeps the daemon's background work going until ctx is done.
+func (d *daemon) run(ctx context.Context) error {
+	if d.startup.Health.Enabled {
+		server := health.NewServer(d.health, &health.ServerConfig{
+			Address:         d.startup.Health.Address,
+			ShutdownTimeout: health.DefaultServerConfig().ShutdownTimeout,
+		})
+		if d.metrics != nil {
+			server.Handle(d.startup.Metrics.Path, d.metrics.Handler())
+		}
+		go func() {
+			if err := server.Run(ctx); err != nil {
+				logger.ErrorMsg("Health server stopped", logger.Error(err))
+			}
+		}()
+		logger.Info("Health server listening", logger.String("address", d.startup.Health.Address))
+	}
+
+	go func() {
+		if err := d.runControl(ctx); err != nil {
+			logger.ErrorMsg("Control server stopped", logger.Error(err))
+		}
+	}()
+
+	// Keep live smart playlists in step with the library
+	go d.smart.Run(ctx)
+
+	// Record what is listened to, from the same events the poller derives
+	if d.history != nil {
+		go d.history.Run(ctx, d.poller)
+	}
+
+	return d.poller.Run(ctx)
+}
+
+// reload applies the reload-safe sections of a new configuration. Changes
+// to the transport, TLS, sessions, log output, smart playlist file and
+// history are reported and wait for a restart.
+func (d *daemon) reload(_, current *config.Config, changed []string) {
+	var applied, pending []string
+	for _, key := range changed {
+		if config.ReloadSafe(key) {
+			applied = append(applied, key)
+		} else {
+			pending = append(pending, key)
+		}
+	}
+
+	sections := make(map[string]bool)
+	for _, key := range applied {
+		section, _, _ := strings.Cut(key, ".")
+		sections[section] = true
+	}
+
+	if sections["executor"] || sections["retry"] {
+		d.executor.SetConfig(applescript.ExecutorConfigFrom(current))
+	}
+	if sections["cache"] {
+		d.completer.SetConfig(completionConfig(current.Cache))
+	}
+	if sections["smart_playlists"] {
+		d.smart.SetConfig(smartConfig(current.SmartPlaylists))
+	}
+	if sections["log"] {
+		// The destination is fixed at startup; only level, format and caller change
+		logConfig := current.Log.Logger("maestrod")
+		logConfig.Output = d.startup.Log.Output
+		if err := logger.Initialize(logConfig); err != nil {
+			logger.ErrorMsg("Failed to apply log configuration", logger.Error(err))
+		}
+	}
+
+	if len(applied) > 0 {
+		logger.Info("Configuration reloaded", logger.Any("applied", applied))
+	}
+	if len(pending) > 0 {
+		logger.Warn("Configuration changes require a restart", logger.Any("keys", pending))
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/madstone-tech/maestro/application/playback"
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/pkg/logger"
+)
+
+// maxFadeRequestSize bounds the body of a fade request.
+const maxFadeRequestSize = 4 << 10
+
+// fadeHandler starts fades requested by local clients on the daemon's fader,
+// so that a fade outlives the command that asked for it.
+func (d *daemon) fadeHandler(ctx context.Context) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request playback.FadeRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxFadeRequestSize)).Decode(&request); err != nil {
+			writeError(w, http.StatusBadRequest, music.NewDomainErrorWithCause(music.ErrInvalidOperation, "invalid fade request", err))
+			return
+		}
+
+		// The fade belongs to the daemon, not to the request that started it
+		if err := d.fader.Start(ctx, request); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		logger.Info("Fade started",
+			logger.Int("target", request.Target.Level()),
+			logger.Duration("over", request.Over),
+			logger.String("curve", request.Curve.String()))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(request)
+	})
+}
+
+// fadeDone logs the outcome of a fade.
+func fadeDone(request playback.FadeRequest, final music.Volume, err error) {
+	switch {
+	case err == nil:
+		logger.Info("Fade finished", logger.Int("volume", final.Level()))
+	case errors.Is(err, context.Canceled):
+		logger.Info("Fade replaced or stopped", logger.Int("volume", final.Level()))
+	case errors.Is(err, music.ErrInterrupted):
+		logger.Info("Fade interrupted by a volume change", logger.Int("volume", final.Level()))
+	default:
+		logger.ErrorMsg("Fade failed", logger.Int("volume", final.Level()), logger.Error(err))
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error(), "code": music.ErrorID(err)})
+}
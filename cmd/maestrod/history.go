@@ -0,0 +1,27 @@
+package main
+
+import (
+	"github.com/madstone-tech/maestro/application/history"
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/pkg/logger"
+)
+
+// historyConfig returns the recorder configuration for the daemon.
+func historyConfig() *history.Config {
+	historyCfg := history.DefaultConfig()
+	historyCfg.OnRecorded = historyRecorded
+	return historyCfg
+}
+
+// historyRecorded logs each play and skip the daemon records.
+func historyRecorded(entry *music.HistoryEntry, err error) {
+	if err != nil {
+		logger.ErrorMsg("Failed to record history", logger.String("track_id", entry.Track.ID.Value()), logger.Error(err))
+		return
+	}
+	logger.Debug("History recorded",
+		logger.String("kind", entry.Kind.String()),
+		logger.String("track_id", entry.Track.ID.Value()),
+		logger.String("source", entry.Source.String()),
+		logger.Duration("listened", entry.Listened.ToTime()))
+}
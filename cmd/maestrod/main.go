@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/madstone-tech/maestro/pkg/config"
+	"github.com/madstone-tech/maestro/pkg/logger"
+	"github.com/madstone-tech/maestro/pkg/version"
+)
+
+func main() {
+	configFile := flag.String("config", "", "read configuration from this file as well")
+	showVersion := flag.Bool("version", false, "print the version and exit")
+	var overrides []string
+	flag.Func("set", "override a configuration key (section.key=value); may be repeated", func(value string) error {
+		if !strings.Contains(value, "=") {
+			return fmt.Errorf("expected section.key=value")
+		}
+		overrides = append(overrides, value)
+		return nil
+	})
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println("maestrod", version.Version)
+		return
+	}
+
+	options := config.DefaultLoadOptions("maestrod")
+	options.File = *configFile
+	options.Overrides = overrides
+	cfg, err := config.Load(options)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	if err := logger.Initialize(cfg.Log.Logger("maestrod")); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	d := newDaemon(cfg)
+	watcher := config.NewWatcher(options, cfg, &config.WatcherConfig{
+		Interval: config.DefaultWatcherConfig().Interval,
+		OnReload: d.reload,
+		OnError: func(err error) {
+			logger.ErrorMsg("Configuration reload failed; keeping the previous configuration", logger.Error(err))
+		},
+	})
+	go watcher.Run(ctx)
+
+	// SIGHUP forces a reload without waiting for a file change
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hangup:
+				logger.Info("Reloading configuration on SIGHUP")
+				watcher.Reload()
+			}
+		}
+	}()
+
+	logger.Info("maestrod started",
+		logger.String("version", version.Version),
+		logger.Any("config_files", cfg.Files()))
+
+	if err := d.run(ctx); err != nil && err != context.Canceled {
+		logger.ErrorMsg("maestrod stopped", logger.Error(err))
+		os.Exit(1)
+	}
+	logger.Info("maestrod stopped")
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"github.com/madstone-tech/maestro/application/smartlist"
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/pkg/config"
+	"github.com/madstone-tech/maestro/pkg/logger"
+)
+
+// smartConfig returns the materializer configuration for the smart playlist
+// settings in cfg.
+func smartConfig(cfg config.SmartPlaylistsConfig) *smartlist.Config {
+	smartCfg := smartlist.DefaultConfig()
+	smartCfg.Interval = cfg.RefreshInterval.Std()
+	smartCfg.OnRefreshed = smartRefreshed
+	return smartCfg
+}
+
+// smartRefreshed logs each smart playlist the daemon re-materializes.
+func smartRefreshed(playlist *music.SmartPlaylist, tracks int, err error) {
+	if err != nil {
+		logger.ErrorMsg("Failed to refresh smart playlist", logger.String("playlist", playlist.Name), logger.Error(err))
+		return
+	}
+	logger.Info("Smart playlist refreshed", logger.String("playlist", playlist.Name), logger.Int("tracks", tracks))
+}
@@ -0,0 +1,184 @@
+package music
+
+import (
+	"context"
+	"fmt"
+)
+
+// PlayerCommandKind is a player operation that changes the player's state
+// without returning anything, such as a pause or a volume change.
+type PlayerCommandKind int
+
+const (
+	// PlayerCommandPause pauses playback
+	PlayerCommandPause PlayerCommandKind = iota
+
+	// PlayerCommandStop stops playback
+	PlayerCommandStop
+
+	// PlayerCommandResume resumes paused playback
+	PlayerCommandResume
+
+	// PlayerCommandStart resumes paused playback or, when stopped, plays
+	// the player's current selection
+	PlayerCommandStart
+
+	// PlayerCommandNext skips to the next track
+	PlayerCommandNext
+
+	// PlayerCommandPrevious goes back to the previous track
+	PlayerCommandPrevious
+
+	// PlayerCommandSeek moves to PlayerCommand.Position
+	PlayerCommandSeek
+
+	// PlayerCommandSetVolume sets the volume to PlayerCommand.Volume
+	PlayerCommandSetVolume
+
+	// PlayerCommandSetShuffle sets shuffle to PlayerCommand.Shuffle
+	PlayerCommandSetShuffle
+
+	// PlayerCommandSetRepeat sets the repeat mode to PlayerCommand.Repeat
+	PlayerCommandSetRepeat
+)
+
+// String returns the string representation of the PlayerCommandKind.
+func (k PlayerCommandKind) String() string {
+	switch k {
+	case PlayerCommandPause:
+		return "pause"
+	case PlayerCommandStop:
+		return "stop"
+	case PlayerCommandResume:
+		return "resume"
+	case PlayerCommandStart:
+		return "start"
+	case PlayerCommandNext:
+		return "next"
+	case PlayerCommandPrevious:
+		return "previous"
+	case PlayerCommandSeek:
+		return "seek"
+	case PlayerCommandSetVolume:
+		return "set_volume"
+	case PlayerCommandSetShuffle:
+		return "set_shuffle"
+	case PlayerCommandSetRepeat:
+		return "set_repeat"
+	default:
+		return "unknown"
+	}
+}
+
+// IsValid returns true if the PlayerCommandKind is a valid value.
+func (k PlayerCommandKind) IsValid() bool {
+	return k >= PlayerCommandPause && k <= PlayerCommandSetRepeat
+}
+
+// Transition returns the player state transition the command makes, if it
+// makes one. Start makes none: it leaves the player playing from any state.
+func (k PlayerCommandKind) Transition() (PlayerTransition, bool) {
+	switch k {
+	case PlayerCommandPause:
+		return PlayerTransitionPause, true
+	case PlayerCommandStop:
+		return PlayerTransitionStop, true
+	case PlayerCommandResume:
+		return PlayerTransitionResume, true
+	default:
+		return PlayerTransitionPlay, false
+	}
+}
+
+// PlayerCommand is one player operation with its argument. Only the field
+// its kind names is used.
+type PlayerCommand struct {
+	// Kind is the operation
+	Kind PlayerCommandKind
+
+	// Position is the target of a seek
+	Position Duration
+
+	// Volume is the target of a volume change
+	Volume Volume
+
+	// Shuffle is the shuffle setting
+	Shuffle bool
+
+	// Repeat is the repeat mode
+	Repeat RepeatMode
+}
+
+// Validate checks the command's kind and argument.
+func (c PlayerCommand) Validate() error {
+	switch {
+	case !c.Kind.IsValid():
+		return NewDomainError(ErrInvalidOperation, "unknown player command")
+	case c.Kind == PlayerCommandSeek && !c.Position.IsValid():
+		return WrapInvalidPosition(c.Position, nil)
+	case c.Kind == PlayerCommandSetVolume && !c.Volume.IsValid():
+		return WrapInvalidVolume(c.Volume.Level(), nil)
+	case c.Kind == PlayerCommandSetRepeat && !c.Repeat.IsValid():
+		return NewDomainError(ErrInvalidRepeatMode, "invalid repeat mode")
+	}
+	return nil
+}
+
+// String describes the command, e.g. "seek to 1:30".
+func (c PlayerCommand) String() string {
+	switch c.Kind {
+	case PlayerCommandSeek:
+		return fmt.Sprintf("seek to %s", c.Position)
+	case PlayerCommandSetVolume:
+		return fmt.Sprintf("set volume to %s", c.Volume)
+	case PlayerCommandSetShuffle:
+		return fmt.Sprintf("set shuffle to %t", c.Shuffle)
+	case PlayerCommandSetRepeat:
+		return fmt.Sprintf("set repeat to %s", c.Repeat)
+	default:
+		return c.Kind.String()
+	}
+}
+
+// Apply runs the command on player.
+func (c PlayerCommand) Apply(ctx context.Context, player PlayerRepository) error {
+	switch c.Kind {
+	case PlayerCommandPause:
+		return player.Pause(ctx)
+	case PlayerCommandStop:
+		return player.Stop(ctx)
+	case PlayerCommandResume:
+		return player.Resume(ctx)
+	case PlayerCommandStart:
+		return player.Start(ctx)
+	case PlayerCommandNext:
+		return player.Next(ctx)
+	case PlayerCommandPrevious:
+		return player.Previous(ctx)
+	case PlayerCommandSeek:
+		return player.Seek(ctx, c.Position)
+	case PlayerCommandSetVolume:
+		return player.SetVolume(ctx, c.Volume)
+	case PlayerCommandSetShuffle:
+		return player.SetShuffle(ctx, c.Shuffle)
+	case PlayerCommandSetRepeat:
+		return player.SetRepeat(ctx, c.Repeat)
+	default:
+		return c.Validate()
+	}
+}
+
+// RunPlayerCommands runs commands in order on player, in one round trip
+// when player is a PlayerBatcher, and stops at the first failure. It
+// returns how many commands succeeded.
+func RunPlayerCommands(ctx context.Context, player PlayerRepository, commands []PlayerCommand) (int, error) {
+	if batcher, ok := player.(PlayerBatcher); ok {
+		return batcher.RunCommands(ctx, commands)
+	}
+	for i, command := range commands {
+		if err := command.Apply(ctx, player); err != nil {
+			return i, err
+		}
+	}
+	return len(commands), nil
+}
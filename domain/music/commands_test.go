@@ -0,0 +1,93 @@
+package music
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// scriptedPlayer records pauses, skips and volume changes and fails volume
+// changes above a limit.
+type scriptedPlayer struct {
+	PlayerRepository
+	calls []string
+	limit int
+}
+
+func (p *scriptedPlayer) Pause(ctx context.Context) error {
+	p.calls = append(p.calls, "pause")
+	return nil
+}
+
+func (p *scriptedPlayer) Next(ctx context.Context) error {
+	p.calls = append(p.calls, "next")
+	return nil
+}
+
+func (p *scriptedPlayer) SetVolume(ctx context.Context, volume Volume) error {
+	if volume.Level() > p.limit {
+		return NewDomainError(ErrOperationFailed, "too loud")
+	}
+	p.calls = append(p.calls, volume.String())
+	return nil
+}
+
+// batchingPlayer runs commands in one call.
+type batchingPlayer struct {
+	scriptedPlayer
+	batches int
+}
+
+func (p *batchingPlayer) RunCommands(ctx context.Context, commands []PlayerCommand) (int, error) {
+	p.batches++
+	for i, command := range commands {
+		if err := command.Apply(ctx, &p.scriptedPlayer); err != nil {
+			return i, err
+		}
+	}
+	return len(commands), nil
+}
+
+func TestPlayerCommandValidate(t *testing.T) {
+	tests := []struct {
+		command PlayerCommand
+		code    error
+	}{
+		{PlayerCommand{Kind: PlayerCommandNext}, nil},
+		{PlayerCommand{Kind: PlayerCommandSetVolume, Volume: Volume{level: 101}}, ErrInvalidVolume},
+		{PlayerCommand{Kind: PlayerCommandSeek, Position: Duration{millis: -1}}, ErrInvalidPosition},
+		{PlayerCommand{Kind: PlayerCommandSetRepeat, Repeat: RepeatMode(9)}, ErrInvalidRepeatMode},
+		{PlayerCommand{Kind: PlayerCommandKind(99)}, ErrInvalidOperation},
+	}
+
+	for _, tt := range tests {
+		err := tt.command.Validate()
+		if tt.code == nil && err != nil || tt.code != nil && !errors.Is(err, tt.code) {
+			t.Errorf("%s: expected %v, got %v", tt.command, tt.code, err)
+		}
+	}
+}
+
+func TestRunPlayerCommands(t *testing.T) {
+	commands := []PlayerCommand{
+		{Kind: PlayerCommandPause},
+		{Kind: PlayerCommandSetVolume, Volume: NewVolume(40)},
+		{Kind: PlayerCommandSetVolume, Volume: NewVolume(90)},
+		{Kind: PlayerCommandNext},
+	}
+
+	player := &scriptedPlayer{limit: 50}
+	done, err := RunPlayerCommands(context.Background(), player, commands)
+	if done != 2 || !errors.Is(err, ErrOperationFailed) {
+		t.Errorf("expected 2 commands and a failure, got %d, %v", done, err)
+	}
+	if len(player.calls) != 2 {
+		t.Errorf("expected the commands after the failure not to run, got %v", player.calls)
+	}
+
+	batcher := &batchingPlayer{scriptedPlayer: scriptedPlayer{limit: 100}}
+	done, err = RunPlayerCommands(context.Background(), batcher, commands)
+	if done != 4 || err != nil || batcher.batches != 1 {
+		t.Errorf("expected 4 commands in 1 batch, got %d in %d, %v", done, batcher.batches, err)
+	}
+}
@@ -4,6 +4,8 @@
 package music
 
 import (
+	"fmt"
+	"slices"
 	"time"
 )
 
@@ -25,11 +27,125 @@ type Track struct {
 
 	// Duration is the length of the track
 	Duration Duration `json:"duration"`
+
+	// TrackMetadata holds the optional details; its fields are promoted
+	TrackMetadata
+}
+
+// TrackMetadata holds the optional details of a track. Zero values mean
+// unknown, or never for the dates.
+type TrackMetadata struct {
+	// AlbumArtist is the artist the album is filed under
+	AlbumArtist string `json:"album_artist,omitempty"`
+
+	// Genre is the track's genre
+	Genre string `json:"genre,omitempty"`
+
+	// Year is the release year
+	Year int `json:"year,omitempty"`
+
+	// TrackNumber is the track's 1-based position on its disc
+	TrackNumber int `json:"track_number,omitempty"`
+
+	// TrackCount is the number of tracks on the disc
+	TrackCount int `json:"track_count,omitempty"`
+
+	// DiscNumber is the 1-based disc the track is on
+	DiscNumber int `json:"disc_number,omitempty"`
+
+	// DiscCount is the number of discs of the album
+	DiscCount int `json:"disc_count,omitempty"`
+
+	// Composer is the track's composer
+	Composer string `json:"composer,omitempty"`
+
+	// Rating is the user's rating
+	Rating Rating `json:"rating,omitzero"`
+
+	// Loved is set when the user loved the track
+	Loved bool `json:"loved,omitempty"`
+
+	// Disliked is set when the user disliked the track
+	Disliked bool `json:"disliked,omitempty"`
+
+	// PlayCount is the number of times the track was played to the end
+	PlayCount int `json:"play_count,omitempty"`
+
+	// SkipCount is the number of times the track was skipped
+	SkipCount int `json:"skip_count,omitempty"`
+
+	// LastPlayed is when the track was last played
+	LastPlayed time.Time `json:"last_played,omitzero"`
+
+	// DateAdded is when the track was added to the library
+	DateAdded time.Time `json:"date_added,omitzero"`
+
+	// BitRate is the encoding's bit rate in kbps
+	BitRate int `json:"bit_rate,omitempty"`
+
+	// Kind describes the file, e.g. "AAC audio file"
+	Kind string `json:"kind,omitempty"`
+
+	// Explicit is set for tracks with explicit content
+	Explicit bool `json:"explicit,omitempty"`
+}
+
+// Validate checks that the numbers, counts and rating are in range.
+func (m TrackMetadata) Validate() error {
+	switch {
+	case m.Year < 0 || m.Year > 9999:
+		return NewDomainError(ErrInvalidTrack, fmt.Sprintf("track year %d is invalid", m.Year)).
+			WithContext("year", m.Year)
+	case m.TrackNumber < 0 || m.TrackCount < 0 || m.TrackCount > 0 && m.TrackNumber > m.TrackCount:
+		return NewDomainError(ErrInvalidTrack, fmt.Sprintf("track number %d of %d is invalid", m.TrackNumber, m.TrackCount)).
+			WithContext("track_number", m.TrackNumber)
+	case m.DiscNumber < 0 || m.DiscCount < 0 || m.DiscCount > 0 && m.DiscNumber > m.DiscCount:
+		return NewDomainError(ErrInvalidTrack, fmt.Sprintf("disc number %d of %d is invalid", m.DiscNumber, m.DiscCount)).
+			WithContext("disc_number", m.DiscNumber)
+	case !m.Rating.IsValid():
+		return WrapInvalidRating(m.Rating.Value(), nil)
+	case m.Loved && m.Disliked:
+		return NewDomainError(ErrInvalidRating, "a track cannot be both loved and disliked")
+	case m.PlayCount < 0 || m.SkipCount < 0:
+		return NewDomainError(ErrInvalidTrack, "track play and skip counts cannot be negative")
+	case m.BitRate < 0:
+		return NewDomainError(ErrInvalidTrack, "track bit rate cannot be negative")
+	}
+	return nil
+}
+
+// Preference returns whether the track is loved, disliked or neither.
+func (m TrackMetadata) Preference() Preference {
+	switch {
+	case m.Loved:
+		return PreferenceLoved
+	case m.Disliked:
+		return PreferenceDisliked
+	default:
+		return PreferenceNone
+	}
+}
+
+// SetPreference sets the Loved and Disliked flags from preference.
+func (m *TrackMetadata) SetPreference(preference Preference) error {
+	if !preference.IsValid() {
+		return NewDomainError(ErrInvalidRating, fmt.Sprintf("preference %d is invalid", preference)).
+			WithContext("preference", int(preference))
+	}
+	m.Loved = preference == PreferenceLoved
+	m.Disliked = preference == PreferenceDisliked
+	return nil
 }
 
 // NewTrack creates a new Track entity with validation.
 // It ensures all required fields are provided and valid.
 func NewTrack(id TrackID, title, artist, album string, duration Duration) (*Track, error) {
+	return NewTrackWithMetadata(id, title, artist, album, duration, TrackMetadata{})
+}
+
+// NewTrackWithMetadata creates a new Track entity with optional details,
+// validating both.
+func NewTrackWithMetadata(id TrackID, title, artist, album string, duration Duration, metadata TrackMetadata) (*Track, error) {
 	if id.IsEmpty() {
 		return nil, NewDomainError(ErrInvalidTrackID, "track ID cannot be empty")
 	}
@@ -46,12 +162,17 @@ func NewTrack(id TrackID, title, artist, album string, duration Duration) (*Trac
 		return nil, NewDomainError(ErrInvalidTrack, "track duration must be valid")
 	}
 
+	if err := metadata.Validate(); err != nil {
+		return nil, err
+	}
+
 	return &Track{
-		ID:       id,
-		Title:    title,
-		Artist:   artist,
-		Album:    album,
-		Duration: duration,
+		ID:            id,
+		Title:         title,
+		Artist:        artist,
+		Album:         album,
+		Duration:      duration,
+		TrackMetadata: metadata,
 	}, nil
 }
 
@@ -86,6 +207,10 @@ type Playlist struct {
 	// Tracks is the ordered list of tracks in this playlist
 	Tracks []TrackID `json:"tracks"`
 
+	// AllowDuplicates lets AddTrack and InsertAt add a track that is already
+	// in the playlist
+	AllowDuplicates bool `json:"allow_duplicates,omitempty"`
+
 	// CreatedAt is when the playlist was created
 	CreatedAt time.Time `json:"created_at"`
 
@@ -116,29 +241,105 @@ func NewPlaylist(id PlaylistID, name string, playlistType PlaylistType, readOnly
 	}, nil
 }
 
-// AddTrack adds a track to the playlist if it's not read-only.
+// AddTrack adds a track to the end of the playlist if it's not read-only.
 func (p *Playlist) AddTrack(trackID TrackID) error {
+	return p.InsertAt(len(p.Tracks), trackID)
+}
+
+// InsertAt inserts tracks before the 0-based position; position len(Tracks)
+// appends. Unless AllowDuplicates is set, a track that is already in the
+// playlist, or given twice, is rejected and nothing is inserted.
+func (p *Playlist) InsertAt(position int, trackIDs ...TrackID) error {
 	if p.ReadOnly {
 		return NewDomainError(ErrPlaylistReadOnly, "cannot modify read-only playlist")
 	}
 
-	if trackID.IsEmpty() {
-		return NewDomainError(ErrInvalidTrackID, "cannot add empty track ID to playlist")
+	if position < 0 || position > len(p.Tracks) {
+		return WrapInvalidPlaylistPosition(position, len(p.Tracks)+1)
 	}
 
-	// Check if track is already in playlist
-	for _, existing := range p.Tracks {
-		if existing.Equals(trackID) {
-			return NewDomainError(ErrTrackAlreadyInPlaylist, "track is already in playlist")
+	for i, trackID := range trackIDs {
+		if trackID.IsEmpty() {
+			return NewDomainError(ErrInvalidTrackID, "cannot add empty track ID to playlist")
+		}
+		if !p.AllowDuplicates && (p.ContainsTrack(trackID) || slices.Contains(trackIDs[:i], trackID)) {
+			return NewDomainError(ErrTrackAlreadyInPlaylist, "track is already in playlist").
+				WithContext("track_id", trackID.Value())
 		}
 	}
 
-	p.Tracks = append(p.Tracks, trackID)
+	p.Tracks = slices.Insert(p.Tracks, position, trackIDs...)
 	p.ModifiedAt = time.Now()
 
 	return nil
 }
 
+// RemoveAt removes and returns the track at the 0-based position, leaving
+// any other occurrence of the same track in place.
+func (p *Playlist) RemoveAt(position int) (TrackID, error) {
+	if p.ReadOnly {
+		return TrackID{}, NewDomainError(ErrPlaylistReadOnly, "cannot modify read-only playlist")
+	}
+
+	if err := p.checkPosition(position); err != nil {
+		return TrackID{}, err
+	}
+
+	trackID := p.Tracks[position]
+	p.Tracks = slices.Delete(p.Tracks, position, position+1)
+	p.ModifiedAt = time.Now()
+
+	return trackID, nil
+}
+
+// Move moves the track at the 0-based position from so that it ends up at
+// position to, shifting the tracks in between.
+func (p *Playlist) Move(from, to int) error {
+	if p.ReadOnly {
+		return NewDomainError(ErrPlaylistReadOnly, "cannot modify read-only playlist")
+	}
+
+	if err := p.checkPosition(from); err != nil {
+		return err
+	}
+	if err := p.checkPosition(to); err != nil {
+		return err
+	}
+
+	trackID := p.Tracks[from]
+	p.Tracks = slices.Insert(slices.Delete(p.Tracks, from, from+1), to, trackID)
+	p.ModifiedAt = time.Now()
+
+	return nil
+}
+
+// Swap exchanges the tracks at the 0-based positions i and j.
+func (p *Playlist) Swap(i, j int) error {
+	if p.ReadOnly {
+		return NewDomainError(ErrPlaylistReadOnly, "cannot modify read-only playlist")
+	}
+
+	if err := p.checkPosition(i); err != nil {
+		return err
+	}
+	if err := p.checkPosition(j); err != nil {
+		return err
+	}
+
+	p.Tracks[i], p.Tracks[j] = p.Tracks[j], p.Tracks[i]
+	p.ModifiedAt = time.Now()
+
+	return nil
+}
+
+// checkPosition rejects a 0-based position that does not hold a track.
+func (p *Playlist) checkPosition(position int) error {
+	if position < 0 || position >= len(p.Tracks) {
+		return WrapInvalidPlaylistPosition(position, len(p.Tracks))
+	}
+	return nil
+}
+
 // RemoveTrack removes a track from the playlist if it's not read-only.
 func (p *Playlist) RemoveTrack(trackID TrackID) error {
 	if p.ReadOnly {
@@ -247,35 +448,34 @@ func (p *Player) SetPosition(position Duration) error {
 	return nil
 }
 
-// Play starts playback with the specified track.
-func (p *Player) Play(trackID *TrackID) {
-	p.State = PlayerStatePlaying
-	p.CurrentTrack = trackID
-	p.LastUpdated = time.Now()
+// Play starts playback of the specified track. See Transition.
+func (p *Player) Play(trackID *TrackID) (*Event, error) {
+	return p.Transition(PlayerTransitionPlay, trackID, time.Now())
+}
+
+// Load starts loading the specified track; the player buffers until Ready.
+func (p *Player) Load(trackID *TrackID) (*Event, error) {
+	return p.Transition(PlayerTransitionLoad, trackID, time.Now())
+}
+
+// Ready starts playing a track that finished loading.
+func (p *Player) Ready() (*Event, error) {
+	return p.Transition(PlayerTransitionReady, nil, time.Now())
 }
 
 // Pause pauses playback without changing the current track.
-func (p *Player) Pause() {
-	if p.State == PlayerStatePlaying {
-		p.State = PlayerStatePaused
-		p.LastUpdated = time.Now()
-	}
+func (p *Player) Pause() (*Event, error) {
+	return p.Transition(PlayerTransitionPause, nil, time.Now())
 }
 
 // Stop stops playback and clears the current track.
-func (p *Player) Stop() {
-	p.State = PlayerStateStopped
-	p.CurrentTrack = nil
-	p.Position = NewDuration(0)
-	p.LastUpdated = time.Now()
+func (p *Player) Stop() (*Event, error) {
+	return p.Transition(PlayerTransitionStop, nil, time.Now())
 }
 
-// Resume resumes playback if currently paused.
-func (p *Player) Resume() {
-	if p.State == PlayerStatePaused {
-		p.State = PlayerStatePlaying
-		p.LastUpdated = time.Now()
-	}
+// Resume resumes paused playback.
+func (p *Player) Resume() (*Event, error) {
+	return p.Transition(PlayerTransitionResume, nil, time.Now())
 }
 
 // SetShuffle enables or disables shuffle mode.
@@ -290,6 +490,17 @@ func (p *Player) SetRepeat(mode RepeatMode) {
 	p.LastUpdated = time.Now()
 }
 
+// EstimatedPosition returns the playback position at now, extrapolated
+// from Position and LastUpdated while playing, so that a client can show
+// progress between refreshes. The estimate may run past the end of the
+// track; callers clamp it to the track duration.
+func (p *Player) EstimatedPosition(now time.Time) Duration {
+	if p.State != PlayerStatePlaying || p.LastUpdated.IsZero() || !now.After(p.LastUpdated) {
+		return p.Position
+	}
+	return p.Position.Add(NewDurationFromTime(now.Sub(p.LastUpdated)))
+}
+
 // IsPlaying returns true if the player is currently playing.
 func (p *Player) IsPlaying() bool {
 	return p.State == PlayerStatePlaying
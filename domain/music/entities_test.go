@@ -1,8 +1,10 @@
 package music
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 )
 
 func TestNewTrack(t *testing.T) {
@@ -61,7 +63,7 @@ func TestNewTrack(t *testing.T) {
 			title:         "Test Song",
 			artist:        "Test Artist",
 			album:         "Test Album",
-			duration:      Duration{seconds: -1}, // Create invalid duration directly
+			duration:      Duration{millis: -1}, // Create invalid duration directly
 			expectedError: true,
 			errorType:     ErrInvalidTrack,
 		},
@@ -113,6 +115,75 @@ func TestNewTrack(t *testing.T) {
 	}
 }
 
+func TestNewTrackWithMetadata(t *testing.T) {
+	valid := TrackMetadata{
+		AlbumArtist: "Miles Davis",
+		Genre:       "Jazz",
+		Year:        1959,
+		TrackNumber: 1,
+		TrackCount:  5,
+		DiscNumber:  1,
+		DiscCount:   1,
+		Rating:      NewRatingFromStars(4),
+		PlayCount:   12,
+		BitRate:     256,
+		DateAdded:   time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	track, err := NewTrackWithMetadata(NewTrackID("1"), "So What", "Miles Davis", "Kind of Blue", NewDuration(562), valid)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if track.Genre != "Jazz" || track.Year != 1959 || track.Rating.Stars() != 4 {
+		t.Errorf("expected the metadata to be kept, got %+v", track.TrackMetadata)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*TrackMetadata)
+		code   error
+	}{
+		{"negative year", func(m *TrackMetadata) { m.Year = -1 }, ErrInvalidTrack},
+		{"track number past count", func(m *TrackMetadata) { m.TrackNumber = 6 }, ErrInvalidTrack},
+		{"negative disc number", func(m *TrackMetadata) { m.DiscNumber = -1 }, ErrInvalidTrack},
+		{"rating out of range", func(m *TrackMetadata) { m.Rating = Rating{value: 120} }, ErrInvalidRating},
+		{"negative play count", func(m *TrackMetadata) { m.PlayCount = -1 }, ErrInvalidTrack},
+		{"negative bit rate", func(m *TrackMetadata) { m.BitRate = -1 }, ErrInvalidTrack},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := valid
+			tt.modify(&metadata)
+			_, err := NewTrackWithMetadata(NewTrackID("1"), "So What", "Miles Davis", "Kind of Blue", NewDuration(562), metadata)
+			if !errors.Is(err, tt.code) {
+				t.Errorf("expected %v, got %v", tt.code, err)
+			}
+		})
+	}
+}
+
+func TestTrackMetadataJSON(t *testing.T) {
+	track, _ := NewTrack(NewTrackID("1"), "So What", "Miles Davis", "Kind of Blue", NewDuration(562))
+	data, err := json.Marshal(track)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"id":"1","title":"So What","artist":"Miles Davis","album":"Kind of Blue","duration":562}`; string(data) != want {
+		t.Errorf("expected unknown details to be left out:\n got %s\nwant %s", data, want)
+	}
+
+	track.Year = 1959
+	track.Rating = NewRatingFromStars(5)
+	data, _ = json.Marshal(track)
+	var decoded Track
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Year != 1959 || decoded.Rating.Value() != 100 {
+		t.Errorf("expected the details to round-trip, got %s", data)
+	}
+}
+
 func TestTrackEquals(t *testing.T) {
 	id1 := NewTrackID("track-1")
 	id2 := NewTrackID("track-2")
@@ -332,6 +403,172 @@ func TestPlaylistRemoveTrack(t *testing.T) {
 	}
 }
 
+// playlistOf returns a writable playlist holding the given track IDs.
+func playlistOf(t *testing.T, ids ...string) *Playlist {
+	t.Helper()
+	playlist, err := NewPlaylist(NewPlaylistID("playlist-1"), "Test Playlist", PlaylistTypeUser, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range ids {
+		playlist.Tracks = append(playlist.Tracks, NewTrackID(id))
+	}
+	return playlist
+}
+
+// playlistTracks returns the track IDs of a playlist as strings.
+func playlistTracks(playlist *Playlist) []string {
+	ids := make([]string, len(playlist.Tracks))
+	for i, id := range playlist.Tracks {
+		ids[i] = id.Value()
+	}
+	return ids
+}
+
+func TestPlaylistInsertAt(t *testing.T) {
+	playlist := playlistOf(t, "a", "b")
+
+	if err := playlist.InsertAt(1, NewTrackID("x"), NewTrackID("y")); err != nil {
+		t.Fatalf("unexpected error inserting tracks: %v", err)
+	}
+	if got := playlistTracks(playlist); !equalStrings(got, []string{"a", "x", "y", "b"}) {
+		t.Errorf("unexpected tracks %v", got)
+	}
+
+	if err := playlist.InsertAt(4, NewTrackID("z")); err != nil {
+		t.Fatalf("unexpected error appending track: %v", err)
+	}
+	if got := playlistTracks(playlist); !equalStrings(got, []string{"a", "x", "y", "b", "z"}) {
+		t.Errorf("unexpected tracks %v", got)
+	}
+
+	err := playlist.InsertAt(7, NewTrackID("c"))
+	if !IsError(err, ErrInvalidPlaylistPosition) {
+		t.Errorf("expected ErrInvalidPlaylistPosition, got %v", err)
+	}
+	err = playlist.InsertAt(-1, NewTrackID("c"))
+	if !IsError(err, ErrInvalidPlaylistPosition) {
+		t.Errorf("expected ErrInvalidPlaylistPosition, got %v", err)
+	}
+
+	// Duplicates are rejected as a whole unless the playlist allows them
+	err = playlist.InsertAt(0, NewTrackID("c"), NewTrackID("a"))
+	if !IsError(err, ErrTrackAlreadyInPlaylist) {
+		t.Errorf("expected ErrTrackAlreadyInPlaylist, got %v", err)
+	}
+	err = playlist.InsertAt(0, NewTrackID("c"), NewTrackID("c"))
+	if !IsError(err, ErrTrackAlreadyInPlaylist) {
+		t.Errorf("expected ErrTrackAlreadyInPlaylist for a repeated track, got %v", err)
+	}
+	if playlist.TrackCount() != 5 {
+		t.Errorf("expected a rejected insert to change nothing, got %v", playlistTracks(playlist))
+	}
+
+	playlist.AllowDuplicates = true
+	if err := playlist.InsertAt(0, NewTrackID("a")); err != nil {
+		t.Fatalf("unexpected error inserting a duplicate: %v", err)
+	}
+	if err := playlist.AddTrack(NewTrackID("a")); err != nil {
+		t.Fatalf("unexpected error adding a duplicate: %v", err)
+	}
+	if got := playlistTracks(playlist); !equalStrings(got, []string{"a", "a", "x", "y", "b", "z", "a"}) {
+		t.Errorf("unexpected tracks %v", got)
+	}
+
+	if err := playlist.InsertAt(0, NewTrackID("")); !IsError(err, ErrInvalidTrackID) {
+		t.Errorf("expected ErrInvalidTrackID, got %v", err)
+	}
+
+	playlist.ReadOnly = true
+	if err := playlist.InsertAt(0, NewTrackID("c")); !IsError(err, ErrPlaylistReadOnly) {
+		t.Errorf("expected ErrPlaylistReadOnly, got %v", err)
+	}
+}
+
+func TestPlaylistRemoveAt(t *testing.T) {
+	playlist := playlistOf(t, "a", "b", "a")
+
+	// Only the occurrence at the position goes
+	removed, err := playlist.RemoveAt(2)
+	if err != nil {
+		t.Fatalf("unexpected error removing track: %v", err)
+	}
+	if removed.Value() != "a" {
+		t.Errorf("expected track a to be removed, got %s", removed.Value())
+	}
+	if got := playlistTracks(playlist); !equalStrings(got, []string{"a", "b"}) {
+		t.Errorf("unexpected tracks %v", got)
+	}
+
+	_, err = playlist.RemoveAt(2)
+	if !IsError(err, ErrInvalidPlaylistPosition) {
+		t.Errorf("expected ErrInvalidPlaylistPosition, got %v", err)
+	}
+	var domainErr *DomainError
+	if errors.As(err, &domainErr) && (domainErr.Context["position"] != 2 || domainErr.Context["length"] != 2) {
+		t.Errorf("expected the position and length in the context, got %v", domainErr.Context)
+	}
+
+	playlist.ReadOnly = true
+	if _, err := playlist.RemoveAt(0); !IsError(err, ErrPlaylistReadOnly) {
+		t.Errorf("expected ErrPlaylistReadOnly, got %v", err)
+	}
+}
+
+func TestPlaylistMove(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to int
+		expected []string
+		err      error
+	}{
+		{"forward", 0, 2, []string{"b", "c", "a", "d"}, nil},
+		{"backward", 3, 1, []string{"a", "d", "b", "c"}, nil},
+		{"same position", 1, 1, []string{"a", "b", "c", "d"}, nil},
+		{"to the end", 1, 3, []string{"a", "c", "d", "b"}, nil},
+		{"from out of range", 4, 0, []string{"a", "b", "c", "d"}, ErrInvalidPlaylistPosition},
+		{"to out of range", 0, 4, []string{"a", "b", "c", "d"}, ErrInvalidPlaylistPosition},
+		{"negative", -1, 0, []string{"a", "b", "c", "d"}, ErrInvalidPlaylistPosition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			playlist := playlistOf(t, "a", "b", "c", "d")
+			err := playlist.Move(tt.from, tt.to)
+			if tt.err != nil {
+				if !IsError(err, tt.err) {
+					t.Errorf("expected %v, got %v", tt.err, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := playlistTracks(playlist); !equalStrings(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPlaylistSwap(t *testing.T) {
+	playlist := playlistOf(t, "a", "b", "c")
+
+	if err := playlist.Swap(0, 2); err != nil {
+		t.Fatalf("unexpected error swapping tracks: %v", err)
+	}
+	if got := playlistTracks(playlist); !equalStrings(got, []string{"c", "b", "a"}) {
+		t.Errorf("unexpected tracks %v", got)
+	}
+
+	if err := playlist.Swap(0, 3); !IsError(err, ErrInvalidPlaylistPosition) {
+		t.Errorf("expected ErrInvalidPlaylistPosition, got %v", err)
+	}
+
+	playlist.ReadOnly = true
+	if err := playlist.Swap(0, 1); !IsError(err, ErrPlaylistReadOnly) {
+		t.Errorf("expected ErrPlaylistReadOnly, got %v", err)
+	}
+}
+
 func TestPlaylistEquals(t *testing.T) {
 	id1 := NewPlaylistID("playlist-1")
 	id2 := NewPlaylistID("playlist-2")
@@ -408,7 +645,9 @@ func TestPlayerPlayback(t *testing.T) {
 	trackID := NewTrackID("track-1")
 
 	// Test play
-	player.Play(&trackID)
+	if _, err := player.Play(&trackID); err != nil {
+		t.Fatalf("unexpected error playing: %v", err)
+	}
 	if !player.IsPlaying() {
 		t.Error("player should be playing after Play()")
 	}
@@ -417,7 +656,9 @@ func TestPlayerPlayback(t *testing.T) {
 	}
 
 	// Test pause
-	player.Pause()
+	if _, err := player.Pause(); err != nil {
+		t.Fatalf("unexpected error pausing: %v", err)
+	}
 	if !player.IsPaused() {
 		t.Error("player should be paused after Pause()")
 	}
@@ -426,13 +667,17 @@ func TestPlayerPlayback(t *testing.T) {
 	}
 
 	// Test resume
-	player.Resume()
+	if _, err := player.Resume(); err != nil {
+		t.Fatalf("unexpected error resuming: %v", err)
+	}
 	if !player.IsPlaying() {
 		t.Error("player should be playing after Resume()")
 	}
 
 	// Test stop
-	player.Stop()
+	if _, err := player.Stop(); err != nil {
+		t.Fatalf("unexpected error stopping: %v", err)
+	}
 	if !player.IsStopped() {
 		t.Error("player should be stopped after Stop()")
 	}
@@ -444,6 +689,26 @@ func TestPlayerPlayback(t *testing.T) {
 	}
 }
 
+func TestPlayerEstimatedPosition(t *testing.T) {
+	updated := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	player := NewPlayer()
+	player.State = PlayerStatePlaying
+	player.Position = NewDurationFromMillis(30250)
+	player.LastUpdated = updated
+
+	if got := player.EstimatedPosition(updated.Add(1500 * time.Millisecond)); got.Milliseconds() != 31750 {
+		t.Errorf("expected 31750ms while playing, got %dms", got.Milliseconds())
+	}
+	if got := player.EstimatedPosition(updated.Add(-time.Second)); got.Milliseconds() != 30250 {
+		t.Errorf("expected no estimate before the last update, got %dms", got.Milliseconds())
+	}
+
+	player.State = PlayerStatePaused
+	if got := player.EstimatedPosition(updated.Add(time.Minute)); got.Milliseconds() != 30250 {
+		t.Errorf("expected the position to hold while paused, got %dms", got.Milliseconds())
+	}
+}
+
 func TestPlayerState(t *testing.T) {
 	player := NewPlayer()
 
@@ -461,7 +726,7 @@ func TestPlayerState(t *testing.T) {
 	}
 
 	trackID := NewTrackID("track-1")
-	player.Play(&trackID)
+	_, _ = player.Play(&trackID)
 
 	if !player.HasCurrentTrack() {
 		t.Error("player should have current track after play")
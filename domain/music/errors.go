@@ -1,6 +1,7 @@
 package music
 
 import (
+	"context"
 	"errors"
 	"fmt"
 )
@@ -13,13 +14,16 @@ var (
 	ErrTrackNotFound  = errors.New("track not found")
 	ErrInvalidTrackID = errors.New("invalid track ID")
 	ErrInvalidTrack   = errors.New("invalid track")
+	ErrInvalidRating  = errors.New("invalid rating")
 
 	// Playlist-related errors
-	ErrPlaylistNotFound       = errors.New("playlist not found")
-	ErrInvalidPlaylistID      = errors.New("invalid playlist ID")
-	ErrInvalidPlaylist        = errors.New("invalid playlist")
-	ErrPlaylistReadOnly       = errors.New("playlist is read-only")
-	ErrTrackAlreadyInPlaylist = errors.New("track already in playlist")
+	ErrPlaylistNotFound        = errors.New("playlist not found")
+	ErrInvalidPlaylistID       = errors.New("invalid playlist ID")
+	ErrInvalidPlaylist         = errors.New("invalid playlist")
+	ErrPlaylistReadOnly        = errors.New("playlist is read-only")
+	ErrTrackAlreadyInPlaylist  = errors.New("track already in playlist")
+	ErrInvalidPlaylistPosition = errors.New("invalid playlist position")
+	ErrInvalidSmartRule        = errors.New("invalid smart playlist rule")
 
 	// Player-related errors
 	ErrPlayerNotAvailable = errors.New("player not available")
@@ -42,6 +46,8 @@ var (
 	ErrTimeout          = errors.New("operation timed out")
 	ErrPermissionDenied = errors.New("permission denied")
 	ErrInvalidOperation = errors.New("invalid operation")
+	ErrRateLimited      = errors.New("rate limit exceeded")
+	ErrInterrupted      = errors.New("operation interrupted")
 )
 
 // DomainError represents an error that occurred in the music domain.
@@ -120,7 +126,8 @@ func (e *DomainError) GetContext(key string) (interface{}, bool) {
 func (e *DomainError) IsTrackError() bool {
 	return errors.Is(e.Code, ErrTrackNotFound) ||
 		errors.Is(e.Code, ErrInvalidTrackID) ||
-		errors.Is(e.Code, ErrInvalidTrack)
+		errors.Is(e.Code, ErrInvalidTrack) ||
+		errors.Is(e.Code, ErrInvalidRating)
 }
 
 // IsPlaylistError returns true if this is a playlist-related error.
@@ -129,7 +136,9 @@ func (e *DomainError) IsPlaylistError() bool {
 		errors.Is(e.Code, ErrInvalidPlaylistID) ||
 		errors.Is(e.Code, ErrInvalidPlaylist) ||
 		errors.Is(e.Code, ErrPlaylistReadOnly) ||
-		errors.Is(e.Code, ErrTrackAlreadyInPlaylist)
+		errors.Is(e.Code, ErrTrackAlreadyInPlaylist) ||
+		errors.Is(e.Code, ErrInvalidPlaylistPosition) ||
+		errors.Is(e.Code, ErrInvalidSmartRule)
 }
 
 // IsPlayerError returns true if this is a player-related error.
@@ -159,7 +168,8 @@ func (e *DomainError) IsRetryable() bool {
 	return errors.Is(e.Code, ErrTimeout) ||
 		errors.Is(e.Code, ErrPlayerNotAvailable) ||
 		errors.Is(e.Code, ErrLibraryNotAvailable) ||
-		errors.Is(e.Code, ErrOperationFailed)
+		errors.Is(e.Code, ErrOperationFailed) ||
+		errors.Is(e.Code, ErrRateLimited)
 }
 
 // IsPermanent returns true if this error is unlikely to succeed if retried.
@@ -169,6 +179,9 @@ func (e *DomainError) IsPermanent() bool {
 		errors.Is(e.Code, ErrInvalidPlaylistID) ||
 		errors.Is(e.Code, ErrInvalidVolume) ||
 		errors.Is(e.Code, ErrInvalidPosition) ||
+		errors.Is(e.Code, ErrInvalidRating) ||
+		errors.Is(e.Code, ErrInvalidPlaylistPosition) ||
+		errors.Is(e.Code, ErrInvalidSmartRule) ||
 		errors.Is(e.Code, ErrInvalidSearchQuery) ||
 		errors.Is(e.Code, ErrPlaylistReadOnly)
 }
@@ -205,6 +218,16 @@ func WrapInvalidVolume(volume int, cause error) *DomainError {
 	return err.WithContext("volume", volume)
 }
 
+// WrapInvalidRating wraps an invalid rating error with the attempted value.
+func WrapInvalidRating(rating int, cause error) *DomainError {
+	err := NewDomainErrorWithCause(
+		ErrInvalidRating,
+		fmt.Sprintf("rating %d is invalid (must be 0-100)", rating),
+		cause,
+	)
+	return err.WithContext("rating", rating)
+}
+
 // WrapInvalidPosition wraps an invalid position error with the attempted value.
 func WrapInvalidPosition(position Duration, cause error) *DomainError {
 	err := NewDomainErrorWithCause(
@@ -215,6 +238,16 @@ func WrapInvalidPosition(position Duration, cause error) *DomainError {
 	return err.WithContext("position_seconds", position.Seconds())
 }
 
+// WrapInvalidPlaylistPosition wraps an invalid playlist position error with
+// the attempted 0-based position and the number of valid positions.
+func WrapInvalidPlaylistPosition(position, length int) *DomainError {
+	err := NewDomainError(
+		ErrInvalidPlaylistPosition,
+		fmt.Sprintf("position %d is out of range for %d positions", position, length),
+	)
+	return err.WithContext("position", position).WithContext("length", length)
+}
+
 // WrapOperationTimeout wraps a timeout error with operation context.
 func WrapOperationTimeout(operation string, timeout Duration, cause error) *DomainError {
 	err := NewDomainErrorWithCause(
@@ -272,3 +305,184 @@ func IsPermanent(err error) bool {
 		errors.Is(err, ErrInvalidTrackID) ||
 		errors.Is(err, ErrInvalidPlaylistID)
 }
+
+// ErrorKind groups error codes by how a caller can react to them. Kinds and
+// the identifiers returned by ErrorID are stable: scripts and clients may
+// branch on them.
+type ErrorKind int
+
+const (
+	// ErrorKindFailure is an operation that failed for another reason
+	ErrorKindFailure ErrorKind = iota
+
+	// ErrorKindInvalidInput is a request that can never succeed as given
+	ErrorKindInvalidInput
+
+	// ErrorKindNotFound is a track, playlist or queue entry that does not exist
+	ErrorKindNotFound
+
+	// ErrorKindUnavailable is a player or library that cannot be reached
+	ErrorKindUnavailable
+
+	// ErrorKindTimeout is an operation that ran out of time
+	ErrorKindTimeout
+
+	// ErrorKindPermission is an operation that is not allowed
+	ErrorKindPermission
+
+	// ErrorKindRateLimited is a request rejected by a rate limit
+	ErrorKindRateLimited
+
+	// ErrorKindCanceled is an operation canceled by the caller
+	ErrorKindCanceled
+)
+
+// String returns the string representation of the ErrorKind.
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorKindFailure:
+		return "failure"
+	case ErrorKindInvalidInput:
+		return "invalid_input"
+	case ErrorKindNotFound:
+		return "not_found"
+	case ErrorKindUnavailable:
+		return "unavailable"
+	case ErrorKindTimeout:
+		return "timeout"
+	case ErrorKindPermission:
+		return "permission"
+	case ErrorKindRateLimited:
+		return "rate_limited"
+	case ErrorKindCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
+// IsValid returns true if the ErrorKind is a valid value.
+func (k ErrorKind) IsValid() bool {
+	return k >= ErrorKindFailure && k <= ErrorKindCanceled
+}
+
+// errorCodes gives every error code its stable identifier and kind.
+var errorCodes = []struct {
+	code error
+	id   string
+	kind ErrorKind
+}{
+	{ErrTrackNotFound, "track_not_found", ErrorKindNotFound},
+	{ErrInvalidTrackID, "invalid_track_id", ErrorKindInvalidInput},
+	{ErrInvalidTrack, "invalid_track", ErrorKindInvalidInput},
+	{ErrInvalidRating, "invalid_rating", ErrorKindInvalidInput},
+	{ErrPlaylistNotFound, "playlist_not_found", ErrorKindNotFound},
+	{ErrInvalidPlaylistID, "invalid_playlist_id", ErrorKindInvalidInput},
+	{ErrInvalidPlaylist, "invalid_playlist", ErrorKindInvalidInput},
+	{ErrPlaylistReadOnly, "playlist_read_only", ErrorKindPermission},
+	{ErrTrackAlreadyInPlaylist, "track_already_in_playlist", ErrorKindInvalidInput},
+	{ErrInvalidPlaylistPosition, "invalid_playlist_position", ErrorKindInvalidInput},
+	{ErrInvalidSmartRule, "invalid_smart_rule", ErrorKindInvalidInput},
+	{ErrPlayerNotAvailable, "player_not_available", ErrorKindUnavailable},
+	{ErrInvalidPlayerState, "invalid_player_state", ErrorKindInvalidInput},
+	{ErrInvalidVolume, "invalid_volume", ErrorKindInvalidInput},
+	{ErrInvalidPosition, "invalid_position", ErrorKindInvalidInput},
+	{ErrInvalidRepeatMode, "invalid_repeat_mode", ErrorKindInvalidInput},
+	{ErrQueueEmpty, "queue_empty", ErrorKindNotFound},
+	{ErrInvalidQueuePosition, "invalid_queue_position", ErrorKindInvalidInput},
+	{ErrLibraryNotAvailable, "library_not_available", ErrorKindUnavailable},
+	{ErrSearchFailed, "search_failed", ErrorKindFailure},
+	{ErrInvalidSearchQuery, "invalid_search_query", ErrorKindInvalidInput},
+	{ErrOperationFailed, "operation_failed", ErrorKindFailure},
+	{ErrTimeout, "timeout", ErrorKindTimeout},
+	{ErrPermissionDenied, "permission_denied", ErrorKindPermission},
+	{ErrInvalidOperation, "invalid_operation", ErrorKindInvalidInput},
+	{ErrRateLimited, "rate_limit_exceeded", ErrorKindRateLimited},
+	{ErrInterrupted, "interrupted", ErrorKindFailure},
+}
+
+// ID returns the stable identifier of the error's code, such as
+// "track_not_found", or "unknown" for a code that is not an Err* variable.
+func (e *DomainError) ID() string {
+	for _, entry := range errorCodes {
+		if errors.Is(e.Code, entry.code) {
+			return entry.id
+		}
+	}
+	return "unknown"
+}
+
+// Kind returns the kind of the error's code.
+func (e *DomainError) Kind() ErrorKind {
+	for _, entry := range errorCodes {
+		if errors.Is(e.Code, entry.code) {
+			return entry.kind
+		}
+	}
+	return ErrorKindFailure
+}
+
+// ErrorID returns the stable identifier of err: the ID of its outermost
+// domain error or Err* code, "timeout" or "canceled" for context errors, and
+// "unknown" otherwise.
+func ErrorID(err error) string {
+	var domainErr *DomainError
+	if errors.As(err, &domainErr) {
+		return domainErr.ID()
+	}
+	for _, entry := range errorCodes {
+		if errors.Is(err, entry.code) {
+			return entry.id
+		}
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
+// KindOf returns the kind of err, classified like ErrorID. Errors from
+// outside the domain are failures unless they come from a context.
+func KindOf(err error) ErrorKind {
+	var domainErr *DomainError
+	if errors.As(err, &domainErr) {
+		return domainErr.Kind()
+	}
+	for _, entry := range errorCodes {
+		if errors.Is(err, entry.code) {
+			return entry.kind
+		}
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorKindTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorKindCanceled
+	default:
+		return ErrorKindFailure
+	}
+}
+
+// ErrorContext returns the context of every domain error in err's chain,
+// outer errors taking precedence. It is never nil.
+func ErrorContext(err error) map[string]interface{} {
+	merged := make(map[string]interface{})
+	for ; err != nil; err = errors.Unwrap(err) {
+		domainErr, ok := err.(*DomainError)
+		if !ok {
+			continue
+		}
+		for key, value := range domainErr.Context {
+			if _, exists := merged[key]; !exists {
+				merged[key] = value
+			}
+		}
+	}
+	return merged
+}
@@ -1,7 +1,9 @@
 package music
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -175,6 +177,7 @@ func TestDomainErrorRetryable(t *testing.T) {
 		{"player not available", ErrPlayerNotAvailable, true, false},
 		{"library not available", ErrLibraryNotAvailable, true, false},
 		{"operation failed", ErrOperationFailed, true, false},
+		{"rate limited", ErrRateLimited, true, false},
 		{"permission denied", ErrPermissionDenied, false, true},
 		{"invalid track ID", ErrInvalidTrackID, false, true},
 		{"invalid volume", ErrInvalidVolume, false, true},
@@ -395,3 +398,60 @@ func TestUtilityRetryableAndPermanent(t *testing.T) {
 		t.Error("expected generic error to not be permanent by default")
 	}
 }
+
+func TestErrorID(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		id   string
+		kind ErrorKind
+	}{
+		{"domain error", NewDomainError(ErrTrackNotFound, "missing"), "track_not_found", ErrorKindNotFound},
+		{"wrapped domain error", fmt.Errorf("play: %w", NewDomainError(ErrPlaylistReadOnly, "smart")), "playlist_read_only", ErrorKindPermission},
+		{"outermost code wins", NewDomainErrorWithCause(ErrPlayerNotAvailable, "down", NewDomainError(ErrTimeout, "slow")), "player_not_available", ErrorKindUnavailable},
+		{"bare code", ErrInvalidVolume, "invalid_volume", ErrorKindInvalidInput},
+		{"rate limited", NewDomainError(ErrRateLimited, "slow down"), "rate_limit_exceeded", ErrorKindRateLimited},
+		{"deadline", context.DeadlineExceeded, "timeout", ErrorKindTimeout},
+		{"canceled", fmt.Errorf("wait: %w", context.Canceled), "canceled", ErrorKindCanceled},
+		{"foreign code", NewDomainError(errors.New("other"), "x"), "unknown", ErrorKindFailure},
+		{"other", errors.New("boom"), "unknown", ErrorKindFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if id := ErrorID(tt.err); id != tt.id {
+				t.Errorf("ErrorID() = %q, want %q", id, tt.id)
+			}
+			if kind := KindOf(tt.err); kind != tt.kind {
+				t.Errorf("KindOf() = %v, want %v", kind, tt.kind)
+			}
+		})
+	}
+}
+
+func TestErrorIDsAreUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, entry := range errorCodes {
+		if seen[entry.id] {
+			t.Errorf("duplicate error ID %q", entry.id)
+		}
+		seen[entry.id] = true
+		if !entry.kind.IsValid() {
+			t.Errorf("%s has invalid kind %v", entry.id, entry.kind)
+		}
+	}
+}
+
+func TestErrorContext(t *testing.T) {
+	inner := NewDomainError(ErrTimeout, "slow").WithContext("operation", "inner").WithContext("timeout_seconds", 5)
+	outer := NewDomainErrorWithCause(ErrPlayerNotAvailable, "down", fmt.Errorf("call: %w", inner)).
+		WithContext("operation", "outer")
+
+	merged := ErrorContext(outer)
+	if merged["operation"] != "outer" || merged["timeout_seconds"] != 5 {
+		t.Errorf("unexpected context %v", merged)
+	}
+	if ErrorContext(errors.New("plain")) == nil {
+		t.Error("expected an empty context, got nil")
+	}
+}
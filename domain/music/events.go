@@ -0,0 +1,133 @@
+package music
+
+import (
+	"time"
+)
+
+// EventType identifies the kind of change a domain event describes.
+type EventType string
+
+const (
+	// EventTrackChanged is raised when the player loads a different track
+	EventTrackChanged EventType = "track_changed"
+
+	// EventPlayerStateChanged is raised when playback starts, pauses or stops
+	EventPlayerStateChanged EventType = "player_state_changed"
+
+	// EventVolumeChanged is raised when the volume level changes
+	EventVolumeChanged EventType = "volume_changed"
+
+	// EventPlaybackModeChanged is raised when shuffle or repeat changes
+	EventPlaybackModeChanged EventType = "playback_mode_changed"
+
+	// EventPositionChanged is raised when the position jumps within a track (a seek)
+	EventPositionChanged EventType = "position_changed"
+
+	// EventQueueChanged is raised when the queue contents or queue position change
+	EventQueueChanged EventType = "queue_changed"
+)
+
+// SeekTolerance is how far the observed position may drift from the position
+// expected from elapsed time before the difference is treated as a seek.
+const SeekTolerance = 2 * time.Second
+
+// Event describes a change in player or queue state.
+// Player events carry the player state before and after the change; queue
+// events carry the new queue contents and position.
+type Event struct {
+	// Type identifies what changed
+	Type EventType `json:"type"`
+
+	// OccurredAt is when the change was observed
+	OccurredAt time.Time `json:"occurred_at"`
+
+	// Player is the player state after the change (player events only)
+	Player *Player `json:"player,omitempty"`
+
+	// Previous is the player state before the change (player events only)
+	Previous *Player `json:"previous,omitempty"`
+
+	// QueueTracks is the queue contents after the change (queue events only)
+	QueueTracks []TrackID `json:"queue_tracks,omitempty"`
+
+	// QueuePosition is the queue position after the change (queue events only)
+	QueuePosition int `json:"queue_position,omitempty"`
+}
+
+// IsPlayerEvent returns true if the event describes a change in player state.
+func (e Event) IsPlayerEvent() bool {
+	return e.Type != EventQueueChanged
+}
+
+// PlayerEvents compares two observations of the player and returns the events
+// describing what changed between them, in a stable order. A nil previous
+// state means the player has not been observed before and yields no events.
+func PlayerEvents(previous, current *Player, at time.Time) []Event {
+	if previous == nil || current == nil {
+		return nil
+	}
+
+	var events []Event
+	raise := func(eventType EventType) {
+		events = append(events, Event{Type: eventType, OccurredAt: at, Player: current, Previous: previous})
+	}
+
+	trackChanged := !sameTrack(previous.CurrentTrack, current.CurrentTrack)
+	if trackChanged {
+		raise(EventTrackChanged)
+	}
+
+	if previous.State != current.State {
+		raise(EventPlayerStateChanged)
+	}
+
+	if previous.Volume.Level() != current.Volume.Level() {
+		raise(EventVolumeChanged)
+	}
+
+	if previous.Shuffle != current.Shuffle || previous.Repeat != current.Repeat {
+		raise(EventPlaybackModeChanged)
+	}
+
+	// Elapsed playback time is only predictable when the state held steady.
+	if !trackChanged && previous.State == current.State && current.HasCurrentTrack() && positionJumped(previous, current) {
+		raise(EventPositionChanged)
+	}
+
+	return events
+}
+
+// NewQueueChangedEvent creates an event describing the queue after a change.
+func NewQueueChangedEvent(tracks []TrackID, position int, at time.Time) Event {
+	return Event{
+		Type:          EventQueueChanged,
+		OccurredAt:    at,
+		QueueTracks:   tracks,
+		QueuePosition: position,
+	}
+}
+
+// sameTrack reports whether two optional track IDs refer to the same track.
+func sameTrack(a, b *TrackID) bool {
+	aEmpty := a == nil || a.IsEmpty()
+	bEmpty := b == nil || b.IsEmpty()
+	if aEmpty || bEmpty {
+		return aEmpty == bEmpty
+	}
+	return a.Equals(*b)
+}
+
+// positionJumped reports whether the position moved by more than SeekTolerance
+// relative to where playback should be given the time between observations.
+func positionJumped(previous, current *Player) bool {
+	expected := previous.Position.ToTime()
+	if previous.IsPlaying() {
+		expected += current.LastUpdated.Sub(previous.LastUpdated)
+	}
+
+	drift := current.Position.ToTime() - expected
+	if drift < 0 {
+		drift = -drift
+	}
+	return drift > SeekTolerance
+}
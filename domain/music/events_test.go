@@ -0,0 +1,120 @@
+package music
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlayerEvents(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	trackA := NewTrackID("1")
+	trackB := NewTrackID("2")
+
+	snapshot := func(state PlayerState, track *TrackID, position int, at time.Time) *Player {
+		return &Player{
+			State:        state,
+			CurrentTrack: track,
+			Position:     NewDuration(position),
+			Volume:       NewVolume(50),
+			Repeat:       RepeatModeOff,
+			LastUpdated:  at,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		previous *Player
+		current  func() *Player
+		expected []EventType
+	}{
+		{
+			name:     "first observation",
+			previous: nil,
+			current:  func() *Player { return snapshot(PlayerStatePlaying, &trackA, 0, base) },
+			expected: nil,
+		},
+		{
+			name:     "steady playback",
+			previous: snapshot(PlayerStatePlaying, &trackA, 10, base),
+			current:  func() *Player { return snapshot(PlayerStatePlaying, &trackA, 15, base.Add(5*time.Second)) },
+			expected: nil,
+		},
+		{
+			name:     "track changed",
+			previous: snapshot(PlayerStatePlaying, &trackA, 200, base),
+			current:  func() *Player { return snapshot(PlayerStatePlaying, &trackB, 1, base.Add(time.Second)) },
+			expected: []EventType{EventTrackChanged},
+		},
+		{
+			name:     "stopped",
+			previous: snapshot(PlayerStatePlaying, &trackA, 10, base),
+			current:  func() *Player { return snapshot(PlayerStateStopped, nil, 0, base.Add(time.Second)) },
+			expected: []EventType{EventTrackChanged, EventPlayerStateChanged},
+		},
+		{
+			name:     "paused",
+			previous: snapshot(PlayerStatePlaying, &trackA, 10, base),
+			current:  func() *Player { return snapshot(PlayerStatePaused, &trackA, 11, base.Add(time.Second)) },
+			expected: []EventType{EventPlayerStateChanged},
+		},
+		{
+			name:     "seek while playing",
+			previous: snapshot(PlayerStatePlaying, &trackA, 10, base),
+			current:  func() *Player { return snapshot(PlayerStatePlaying, &trackA, 90, base.Add(time.Second)) },
+			expected: []EventType{EventPositionChanged},
+		},
+		{
+			name:     "seek while paused",
+			previous: snapshot(PlayerStatePaused, &trackA, 10, base),
+			current:  func() *Player { return snapshot(PlayerStatePaused, &trackA, 5, base.Add(5*time.Second)) },
+			expected: []EventType{EventPositionChanged},
+		},
+		{
+			name:     "volume and modes",
+			previous: snapshot(PlayerStatePaused, &trackA, 10, base),
+			current: func() *Player {
+				p := snapshot(PlayerStatePaused, &trackA, 10, base.Add(time.Second))
+				p.Volume = NewVolume(80)
+				p.Shuffle = true
+				return p
+			},
+			expected: []EventType{EventVolumeChanged, EventPlaybackModeChanged},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := tt.current()
+			at := current.LastUpdated
+			events := PlayerEvents(tt.previous, current, at)
+
+			if len(events) != len(tt.expected) {
+				t.Fatalf("expected events %v, got %d events", tt.expected, len(events))
+			}
+
+			for i, event := range events {
+				if event.Type != tt.expected[i] {
+					t.Errorf("event %d: expected %s, got %s", i, tt.expected[i], event.Type)
+				}
+				if event.Player != current || event.Previous != tt.previous {
+					t.Errorf("event %d: expected before and after snapshots", i)
+				}
+				if !event.OccurredAt.Equal(at) || !event.IsPlayerEvent() {
+					t.Errorf("event %d: unexpected metadata %+v", i, event)
+				}
+			}
+		})
+	}
+}
+
+func TestNewQueueChangedEvent(t *testing.T) {
+	at := time.Now()
+	event := NewQueueChangedEvent([]TrackID{NewTrackID("1"), NewTrackID("2")}, 1, at)
+
+	if event.Type != EventQueueChanged || event.IsPlayerEvent() {
+		t.Errorf("expected a queue event, got %s", event.Type)
+	}
+	if len(event.QueueTracks) != 2 || event.QueuePosition != 1 || !event.OccurredAt.Equal(at) {
+		t.Errorf("unexpected queue event %+v", event)
+	}
+}
@@ -0,0 +1,80 @@
+package music
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// VolumeCurve is the shape of a volume fade.
+type VolumeCurve int
+
+const (
+	// VolumeCurveLinear changes the level by the same amount at every step
+	VolumeCurveLinear VolumeCurve = iota
+
+	// VolumeCurveLog changes the level by the same ratio at every step, which
+	// sounds even to the ear: slow at low levels, faster at high ones
+	VolumeCurveLog
+)
+
+// String returns the string representation of the VolumeCurve.
+func (c VolumeCurve) String() string {
+	switch c {
+	case VolumeCurveLinear:
+		return "linear"
+	case VolumeCurveLog:
+		return "log"
+	default:
+		return "unknown"
+	}
+}
+
+// IsValid returns true if the VolumeCurve is a valid value.
+func (c VolumeCurve) IsValid() bool {
+	return c >= VolumeCurveLinear && c <= VolumeCurveLog
+}
+
+// VolumeCurves returns every valid VolumeCurve in declaration order.
+func VolumeCurves() []VolumeCurve {
+	return []VolumeCurve{VolumeCurveLinear, VolumeCurveLog}
+}
+
+// ParseVolumeCurve converts a name such as "log" into a VolumeCurve.
+func ParseVolumeCurve(name string) (VolumeCurve, error) {
+	for _, curve := range VolumeCurves() {
+		if strings.EqualFold(strings.TrimSpace(name), curve.String()) {
+			return curve, nil
+		}
+	}
+	return VolumeCurveLinear, NewDomainError(ErrInvalidVolume, fmt.Sprintf("unknown fade curve %q (expected linear or log)", name))
+}
+
+// MarshalText encodes the VolumeCurve as its name.
+func (c VolumeCurve) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText decodes a VolumeCurve from its name.
+func (c *VolumeCurve) UnmarshalText(text []byte) error {
+	curve, err := ParseVolumeCurve(string(text))
+	if err != nil {
+		return err
+	}
+	*c = curve
+	return nil
+}
+
+// Interpolate returns the volume part of the way from one level to another,
+// where progress runs from 0 (from) to 1 (to).
+func (c VolumeCurve) Interpolate(from, to Volume, progress float64) Volume {
+	progress = math.Max(0, math.Min(1, progress))
+
+	start, end := float64(from.Level()), float64(to.Level())
+	if c == VolumeCurveLog {
+		// Interpolate the logarithm of level+1 so that silence is reachable
+		level := math.Exp(math.Log(start+1)+(math.Log(end+1)-math.Log(start+1))*progress) - 1
+		return NewVolume(int(math.Round(level)))
+	}
+	return NewVolume(int(math.Round(start + (end-start)*progress)))
+}
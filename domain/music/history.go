@@ -0,0 +1,242 @@
+package music
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Scrobble thresholds: a track counts as played once it has been listened
+// to for half its duration or for PlayThresholdCap, whichever comes first.
+const (
+	// PlayThresholdFraction is the part of a track that must be heard
+	PlayThresholdFraction = 0.5
+
+	// PlayThresholdCap is the listening time that counts as a play for
+	// tracks longer than twice its length
+	PlayThresholdCap = 4 * time.Minute
+)
+
+// PlayThreshold returns how long a track of the given duration must be
+// listened to before it counts as played. A track of unknown (zero)
+// duration counts after PlayThresholdCap.
+func PlayThreshold(duration Duration) Duration {
+	limit := NewDurationFromTime(PlayThresholdCap)
+	if duration.Milliseconds() <= 0 {
+		return limit
+	}
+	half := NewDurationFromMillis(int64(float64(duration.Milliseconds()) * PlayThresholdFraction))
+	if half.Milliseconds() < limit.Milliseconds() {
+		return half
+	}
+	return limit
+}
+
+// HistoryKind is whether a history entry is a play or a skip.
+type HistoryKind int
+
+const (
+	// HistoryPlay is a track listened to past its play threshold
+	HistoryPlay HistoryKind = iota
+
+	// HistorySkip is a track left before its play threshold
+	HistorySkip
+)
+
+// String returns the string representation of the HistoryKind.
+func (k HistoryKind) String() string {
+	switch k {
+	case HistoryPlay:
+		return "play"
+	case HistorySkip:
+		return "skip"
+	default:
+		return "unknown"
+	}
+}
+
+// IsValid returns true if the HistoryKind is a valid value.
+func (k HistoryKind) IsValid() bool {
+	return k >= HistoryPlay && k <= HistorySkip
+}
+
+// ParseHistoryKind converts a name such as "skip" into a HistoryKind.
+func ParseHistoryKind(name string) (HistoryKind, error) {
+	for _, kind := range []HistoryKind{HistoryPlay, HistorySkip} {
+		if strings.EqualFold(strings.TrimSpace(name), kind.String()) {
+			return kind, nil
+		}
+	}
+	return HistoryPlay, NewDomainError(ErrInvalidOperation, fmt.Sprintf("unknown history kind %q (expected play or skip)", name))
+}
+
+// MarshalText encodes the HistoryKind as its name.
+func (k HistoryKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText decodes a HistoryKind from its name.
+func (k *HistoryKind) UnmarshalText(text []byte) error {
+	kind, err := ParseHistoryKind(string(text))
+	if err != nil {
+		return err
+	}
+	*k = kind
+	return nil
+}
+
+// HistorySource is who started a track: maestro or something else, such as
+// Music.app itself or a media key.
+type HistorySource int
+
+const (
+	// SourceExternal indicates the track was started outside maestro
+	SourceExternal HistorySource = iota
+
+	// SourceMaestro indicates a maestro client started the track
+	SourceMaestro
+)
+
+// String returns the string representation of the HistorySource.
+func (s HistorySource) String() string {
+	switch s {
+	case SourceExternal:
+		return "external"
+	case SourceMaestro:
+		return "maestro"
+	default:
+		return "unknown"
+	}
+}
+
+// IsValid returns true if the HistorySource is a valid value.
+func (s HistorySource) IsValid() bool {
+	return s >= SourceExternal && s <= SourceMaestro
+}
+
+// ParseHistorySource converts a name such as "maestro" into a HistorySource.
+func ParseHistorySource(name string) (HistorySource, error) {
+	for _, source := range []HistorySource{SourceExternal, SourceMaestro} {
+		if strings.EqualFold(strings.TrimSpace(name), source.String()) {
+			return source, nil
+		}
+	}
+	return SourceExternal, NewDomainError(ErrInvalidOperation, fmt.Sprintf("unknown history source %q (expected maestro or external)", name))
+}
+
+// MarshalText encodes the HistorySource as its name.
+func (s HistorySource) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a HistorySource from its name.
+func (s *HistorySource) UnmarshalText(text []byte) error {
+	source, err := ParseHistorySource(string(text))
+	if err != nil {
+		return err
+	}
+	*s = source
+	return nil
+}
+
+// HistoryEntry records one play or skip of a track.
+type HistoryEntry struct {
+	// Kind is whether the track was played or skipped
+	Kind HistoryKind `json:"kind"`
+
+	// Track is the track as it was when it started; later edits to the
+	// library do not change it
+	Track Track `json:"track"`
+
+	// Source is who started the track
+	Source HistorySource `json:"source"`
+
+	// StartedAt is when the track started
+	StartedAt time.Time `json:"started_at"`
+
+	// RecordedAt is when the play threshold was passed, or when the track
+	// was left for a skip
+	RecordedAt time.Time `json:"recorded_at"`
+
+	// Listened is how long the track had been playing when it was recorded,
+	// not counting pauses or seeks
+	Listened Duration `json:"listened"`
+}
+
+// HistoryFilter selects history entries. Zero fields select everything.
+type HistoryFilter struct {
+	// Since keeps entries recorded at or after this time
+	Since time.Time
+
+	// Until keeps entries recorded before this time
+	Until time.Time
+
+	// Kinds keeps entries of these kinds (empty = all)
+	Kinds []HistoryKind
+
+	// Limit keeps only the most recent entries (0 = no limit)
+	Limit int
+}
+
+// ParseHistoryTime reads a bound of a history range: a period back from now
+// such as "12h" or "7d", a day (2006-01-02) in now's location or an RFC 3339
+// time.
+func ParseHistoryTime(text string, now time.Time) (time.Time, error) {
+	if period, err := parseSmartPeriod(text); err == nil {
+		return now.Add(-period), nil
+	}
+	t, _, err := parseSmartDate(text, now.Location())
+	if err != nil {
+		return time.Time{}, NewDomainError(ErrInvalidOperation,
+			fmt.Sprintf("%q is not a period such as 7d, a day such as 2024-01-31 or an RFC 3339 time", strings.TrimSpace(text)))
+	}
+	return t, nil
+}
+
+// Validate checks that the filter selects a possible range.
+func (f HistoryFilter) Validate() error {
+	if !f.Since.IsZero() && !f.Until.IsZero() && !f.Until.After(f.Since) {
+		return NewDomainError(ErrInvalidOperation, "history range ends before it starts").
+			WithContext("since", f.Since.Format(time.RFC3339)).
+			WithContext("until", f.Until.Format(time.RFC3339))
+	}
+	if f.Limit < 0 {
+		return NewDomainError(ErrInvalidOperation, "history limit cannot be negative")
+	}
+	return nil
+}
+
+// Matches reports whether entry is selected by the filter, ignoring Limit.
+func (f HistoryFilter) Matches(entry *HistoryEntry) bool {
+	if !f.Since.IsZero() && entry.RecordedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !entry.RecordedAt.Before(f.Until) {
+		return false
+	}
+	if len(f.Kinds) == 0 {
+		return true
+	}
+	for _, kind := range f.Kinds {
+		if entry.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterHistory returns the entries selected by filter, oldest first.
+// entries must be in the order they were recorded; with a Limit the most
+// recent entries are kept.
+func FilterHistory(entries []*HistoryEntry, filter HistoryFilter) []*HistoryEntry {
+	selected := make([]*HistoryEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry != nil && filter.Matches(entry) {
+			selected = append(selected, entry)
+		}
+	}
+	if filter.Limit > 0 && len(selected) > filter.Limit {
+		selected = selected[len(selected)-filter.Limit:]
+	}
+	return selected
+}
@@ -0,0 +1,142 @@
+package music
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPlayThreshold(t *testing.T) {
+	tests := []struct {
+		duration Duration
+		want     time.Duration
+	}{
+		{NewDuration(180), 90 * time.Second},
+		{NewDurationFromMillis(1001), 500 * time.Millisecond},
+		{NewDuration(480), 4 * time.Minute},
+		{NewDuration(1200), 4 * time.Minute},
+		{NewDuration(0), 4 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := PlayThreshold(tt.duration).ToTime(); got != tt.want {
+			t.Errorf("PlayThreshold(%s) = %s, want %s", tt.duration, got, tt.want)
+		}
+	}
+}
+
+func TestHistoryEntryJSON(t *testing.T) {
+	track, err := NewTrack(NewTrackID("42"), "So What", "Miles Davis", "Kind of Blue", NewDuration(545))
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	entry := HistoryEntry{
+		Kind:       HistorySkip,
+		Track:      *track,
+		Source:     SourceMaestro,
+		StartedAt:  started,
+		RecordedAt: started.Add(time.Minute),
+		Listened:   NewDurationFromMillis(61500),
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded HistoryEntry
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Kind != HistorySkip || decoded.Source != SourceMaestro || decoded.Track.Title != "So What" ||
+		!decoded.RecordedAt.Equal(entry.RecordedAt) || decoded.Listened.Milliseconds() != 61500 {
+		t.Errorf("Round trip changed the entry: %s", data)
+	}
+
+	if err := json.Unmarshal([]byte(`{"kind":"replay"}`), &decoded); !errors.Is(err, ErrInvalidOperation) {
+		t.Errorf("Expected an unknown kind to be rejected, got %v", err)
+	}
+	if _, err := ParseHistorySource("radio"); !errors.Is(err, ErrInvalidOperation) {
+		t.Errorf("Expected an unknown source to be rejected, got %v", err)
+	}
+}
+
+func TestFilterHistory(t *testing.T) {
+	base := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	var entries []*HistoryEntry
+	for i := 0; i < 6; i++ {
+		kind := HistoryPlay
+		if i%2 == 1 {
+			kind = HistorySkip
+		}
+		entries = append(entries, &HistoryEntry{Kind: kind, Track: Track{ID: NewTrackID(string(rune('a' + i)))},
+			RecordedAt: base.Add(time.Duration(i) * time.Hour)})
+	}
+	ids := func(selected []*HistoryEntry) string {
+		var s string
+		for _, entry := range selected {
+			s += entry.Track.ID.Value()
+		}
+		return s
+	}
+
+	tests := []struct {
+		name   string
+		filter HistoryFilter
+		want   string
+	}{
+		{"everything", HistoryFilter{}, "abcdef"},
+		{"since is inclusive", HistoryFilter{Since: base.Add(2 * time.Hour)}, "cdef"},
+		{"until is exclusive", HistoryFilter{Until: base.Add(2 * time.Hour)}, "ab"},
+		{"range", HistoryFilter{Since: base.Add(time.Hour), Until: base.Add(4 * time.Hour)}, "bcd"},
+		{"plays", HistoryFilter{Kinds: []HistoryKind{HistoryPlay}}, "ace"},
+		{"most recent skips", HistoryFilter{Kinds: []HistoryKind{HistorySkip}, Limit: 2}, "df"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			if got := ids(FilterHistory(entries, tt.filter)); got != tt.want {
+				t.Errorf("FilterHistory = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	inverted := HistoryFilter{Since: base.Add(time.Hour), Until: base}
+	if err := inverted.Validate(); !errors.Is(err, ErrInvalidOperation) {
+		t.Errorf("Expected an inverted range to be rejected, got %v", err)
+	}
+	if err := (HistoryFilter{Limit: -1}).Validate(); !errors.Is(err, ErrInvalidOperation) {
+		t.Errorf("Expected a negative limit to be rejected, got %v", err)
+	}
+}
+
+func TestParseHistoryTime(t *testing.T) {
+	loc := time.FixedZone("test", 2*60*60)
+	now := time.Date(2024, 6, 15, 12, 30, 0, 0, loc)
+
+	tests := []struct {
+		text string
+		want time.Time
+	}{
+		{"7d", now.Add(-7 * 24 * time.Hour)},
+		{"12h", now.Add(-12 * time.Hour)},
+		{"2024-06-01", time.Date(2024, 6, 1, 0, 0, 0, 0, loc)},
+		{"2024-06-01T08:00:00Z", time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseHistoryTime(tt.text, now)
+		if err != nil {
+			t.Errorf("ParseHistoryTime(%q) failed: %v", tt.text, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseHistoryTime(%q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+
+	if _, err := ParseHistoryTime("last tuesday", now); !errors.Is(err, ErrInvalidOperation) {
+		t.Errorf("Expected an unknown time to be rejected, got %v", err)
+	}
+}
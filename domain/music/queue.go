@@ -0,0 +1,474 @@
+package music
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Queue is the play queue aggregate. It holds the context being played (the
+// album or playlist a track was started from), a "play next" segment of
+// tracks queued by hand that play before the rest of the context, the
+// current track and the tracks already played.
+//
+// Queue is pure: it never talks to a player, and shuffling is driven by a
+// seed the caller records, so the same calls always give the same order.
+// It is not safe for concurrent use.
+type Queue struct {
+	// context is the context in its original order
+	context []TrackID
+
+	// order is the play order, as indexes into context
+	order []int
+
+	// index is the position in order of the last context track made
+	// current, or -1 before the first
+	index int
+
+	// queued is the play next segment, in play order
+	queued []TrackID
+
+	// current is the playing track, or nil
+	current *queueEntry
+
+	// history is the tracks already played, oldest first
+	history      []queueEntry
+	historyLimit int
+
+	repeat   RepeatMode
+	shuffled bool
+	seed     int64
+}
+
+// queueEntry is a track that is or was current.
+type queueEntry struct {
+	track TrackID
+
+	// context is the index of the track in the context, or -1 for a track
+	// from the play next segment or an earlier context
+	context int
+}
+
+// NewQueue creates an empty queue that remembers the last historyLimit
+// tracks played (0 for no limit).
+func NewQueue(historyLimit int) *Queue {
+	if historyLimit < 0 {
+		historyLimit = 0
+	}
+	return &Queue{index: -1, historyLimit: historyLimit}
+}
+
+// SetContext replaces the context with tracks and, unless start is -1,
+// makes the track at start current. The play next segment is kept and the
+// current track moves to the history. A shuffled queue shuffles the new
+// context with its seed, starting from start.
+func (q *Queue) SetContext(tracks []TrackID, start int) error {
+	if err := requireQueueTracks(tracks); err != nil {
+		return err
+	}
+	if start < -1 || start >= len(tracks) {
+		return invalidQueuePosition(start, len(tracks))
+	}
+
+	q.retire()
+	q.forgetContext()
+	q.context = append([]TrackID{}, tracks...)
+	q.order = identityOrder(len(tracks))
+	q.index = -1
+
+	if start < 0 {
+		if q.shuffled {
+			q.shuffleFrom(0)
+		}
+		return nil
+	}
+	if q.shuffled {
+		q.order[0], q.order[start] = q.order[start], q.order[0]
+		q.shuffleFrom(1)
+		start = 0
+	}
+	q.play(start)
+	return nil
+}
+
+// Append adds tracks to the end of the context. A shuffled queue mixes them
+// into the tracks that have not played yet with its seed, keeping the order
+// of those already up next.
+func (q *Queue) Append(trackIDs ...TrackID) error {
+	if err := requireQueueTracks(trackIDs); err != nil {
+		return err
+	}
+
+	// The context length varies the seed so that each append mixes
+	// differently, while the same calls still give the same order
+	random := rand.New(rand.NewSource(q.seed + int64(len(q.context))))
+	for _, trackID := range trackIDs {
+		pos := len(q.order)
+		if q.shuffled {
+			pos = q.index + 1 + random.Intn(len(q.order)-q.index)
+		}
+		q.order = append(q.order[:pos:pos], append([]int{len(q.context)}, q.order[pos:]...)...)
+		q.context = append(q.context, trackID)
+	}
+	return nil
+}
+
+// PlayNext puts tracks at the front of the play next segment, in the order
+// given, so that they play straight after the current track.
+func (q *Queue) PlayNext(trackIDs ...TrackID) error {
+	if err := requireQueueTracks(trackIDs); err != nil {
+		return err
+	}
+	q.queued = append(append([]TrackID{}, trackIDs...), q.queued...)
+	return nil
+}
+
+// PlayLater adds tracks to the end of the play next segment, ahead of the
+// rest of the context.
+func (q *Queue) PlayLater(trackIDs ...TrackID) error {
+	if err := requireQueueTracks(trackIDs); err != nil {
+		return err
+	}
+	q.queued = append(q.queued, trackIDs...)
+	return nil
+}
+
+// Current returns the playing track, if any.
+func (q *Queue) Current() (TrackID, bool) {
+	if q.current == nil {
+		return TrackID{}, false
+	}
+	return q.current.track, true
+}
+
+// Index returns the position in the play order of the last context track
+// made current, or -1 before the first.
+func (q *Queue) Index() int {
+	return q.index
+}
+
+// Context returns the context in its original order.
+func (q *Queue) Context() []TrackID {
+	return append([]TrackID{}, q.context...)
+}
+
+// Order returns the context in play order.
+func (q *Queue) Order() []TrackID {
+	order := make([]TrackID, len(q.order))
+	for pos, index := range q.order {
+		order[pos] = q.context[index]
+	}
+	return order
+}
+
+// Position returns the position in the play order of the current track,
+// or -1 when no track is current or it did not come from the context.
+func (q *Queue) Position() int {
+	if q.current == nil || q.current.context < 0 {
+		return -1
+	}
+	return q.position(q.current.context)
+}
+
+// Queued returns the play next segment.
+func (q *Queue) Queued() []TrackID {
+	return append([]TrackID{}, q.queued...)
+}
+
+// History returns the tracks already played, oldest first.
+func (q *Queue) History() []TrackID {
+	history := make([]TrackID, len(q.history))
+	for i, entry := range q.history {
+		history[i] = entry.track
+	}
+	return history
+}
+
+// UpNext returns up to count tracks (0 for all) in the order Next would
+// play them: the play next segment, then the rest of the context. With
+// RepeatModeAll the context is followed once more from its start, up to and
+// including the current track.
+func (q *Queue) UpNext(count int) []TrackID {
+	upcoming := append([]TrackID{}, q.queued...)
+	for pos := q.index + 1; pos < len(q.order); pos++ {
+		upcoming = append(upcoming, q.context[q.order[pos]])
+	}
+	if q.repeat == RepeatModeAll {
+		for pos := 0; pos <= q.index && pos < len(q.order); pos++ {
+			upcoming = append(upcoming, q.context[q.order[pos]])
+		}
+	}
+
+	if count > 0 && count < len(upcoming) {
+		upcoming = upcoming[:count]
+	}
+	return upcoming
+}
+
+// Advance moves on when the current track finishes playing. RepeatModeOne
+// plays the current track again; otherwise it behaves like Next. It
+// returns false when the queue has run out.
+func (q *Queue) Advance() (TrackID, bool) {
+	if q.repeat == RepeatModeOne && q.current != nil {
+		return q.current.track, true
+	}
+	return q.Next()
+}
+
+// Next skips to the next track: the front of the play next segment, else
+// the next context track. At the end of the context RepeatModeAll starts it
+// again; otherwise nothing is current and Next returns false.
+func (q *Queue) Next() (TrackID, bool) {
+	q.retire()
+
+	if len(q.queued) > 0 {
+		trackID := q.queued[0]
+		q.queued = q.queued[1:]
+		q.current = &queueEntry{track: trackID, context: -1}
+		return trackID, true
+	}
+
+	next := q.index + 1
+	if next >= len(q.order) {
+		if q.repeat != RepeatModeAll || len(q.order) == 0 {
+			return TrackID{}, false
+		}
+		next = 0
+	}
+	return q.play(next), true
+}
+
+// Previous goes back to the last track played. The current track returns
+// to where it came from, so that Next plays it again. It returns false when
+// there is no history.
+func (q *Queue) Previous() (TrackID, bool) {
+	if len(q.history) == 0 {
+		return TrackID{}, false
+	}
+	entry := q.history[len(q.history)-1]
+	q.history = q.history[:len(q.history)-1]
+
+	if q.current != nil {
+		if q.current.context < 0 {
+			q.queued = append([]TrackID{q.current.track}, q.queued...)
+		} else {
+			q.index = q.position(q.current.context) - 1
+		}
+	}
+	if entry.context >= 0 {
+		q.index = q.position(entry.context)
+	}
+	q.current = &entry
+	return entry.track, true
+}
+
+// Jump makes the context track at position in the play order current.
+func (q *Queue) Jump(position int) error {
+	if position < 0 || position >= len(q.order) {
+		return invalidQueuePosition(position, len(q.order))
+	}
+	q.retire()
+	q.play(position)
+	return nil
+}
+
+// Remove removes the track at position in UpNext(0) and returns it.
+// Positions past the play next segment count into the rest of the context.
+func (q *Queue) Remove(position int) (TrackID, error) {
+	remaining := len(q.order) - q.index - 1
+	if position < 0 || position >= len(q.queued)+remaining {
+		return TrackID{}, invalidQueuePosition(position, len(q.queued)+remaining)
+	}
+
+	if position < len(q.queued) {
+		trackID := q.queued[position]
+		q.queued = append(q.queued[:position:position], q.queued[position+1:]...)
+		return trackID, nil
+	}
+
+	return q.removeOrder(q.index + 1 + position - len(q.queued)), nil
+}
+
+// RemovePlayed removes the context track at position in the play order,
+// which must be at or before Index. Removing the current track leaves it
+// current, but Next and Previous no longer return to it.
+func (q *Queue) RemovePlayed(position int) (TrackID, error) {
+	if position < 0 || position > q.index {
+		return TrackID{}, invalidQueuePosition(position, q.index+1)
+	}
+	trackID := q.removeOrder(position)
+	q.index--
+	return trackID, nil
+}
+
+// Stop moves the current track to the history, leaving nothing current.
+// Next continues after it.
+func (q *Queue) Stop() {
+	q.retire()
+}
+
+// Clear removes the play next segment and the context, keeping the current
+// track and the history.
+func (q *Queue) Clear() {
+	q.forgetContext()
+	q.queued = nil
+	q.context = nil
+	q.order = nil
+	q.index = -1
+}
+
+// Repeat returns the repeat mode.
+func (q *Queue) Repeat() RepeatMode {
+	return q.repeat
+}
+
+// SetRepeat changes the repeat mode.
+func (q *Queue) SetRepeat(mode RepeatMode) error {
+	if !mode.IsValid() {
+		return NewDomainError(ErrInvalidRepeatMode, fmt.Sprintf("repeat mode %d is invalid", mode)).
+			WithContext("repeat_mode", int(mode))
+	}
+	q.repeat = mode
+	return nil
+}
+
+// Shuffled reports whether the context plays in shuffled order.
+func (q *Queue) Shuffled() bool {
+	return q.shuffled
+}
+
+// Seed returns the seed of the current shuffle.
+func (q *Queue) Seed() int64 {
+	return q.seed
+}
+
+// Shuffle shuffles the context tracks that have not played yet using seed,
+// which is recorded so that the same seed gives the same order. The play
+// next segment keeps its order.
+func (q *Queue) Shuffle(seed int64) {
+	q.shuffled = true
+	q.seed = seed
+	q.shuffleFrom(q.index + 1)
+}
+
+// Unshuffle restores the original context order. Play continues after the
+// last context track played, as if the queue had never been shuffled.
+func (q *Queue) Unshuffle() {
+	if q.index >= 0 {
+		q.index = q.order[q.index]
+	}
+	q.order = identityOrder(len(q.context))
+	q.shuffled = false
+	q.seed = 0
+}
+
+// play makes the context track at position in the play order current.
+func (q *Queue) play(position int) TrackID {
+	q.index = position
+	q.current = &queueEntry{track: q.context[q.order[position]], context: q.order[position]}
+	return q.current.track
+}
+
+// retire moves the current track to the history.
+func (q *Queue) retire() {
+	if q.current == nil {
+		return
+	}
+	q.history = append(q.history, *q.current)
+	if q.historyLimit > 0 && len(q.history) > q.historyLimit {
+		q.history = append([]queueEntry{}, q.history[len(q.history)-q.historyLimit:]...)
+	}
+	q.current = nil
+}
+
+// removeOrder removes the context track at pos in the play order.
+func (q *Queue) removeOrder(pos int) TrackID {
+	removed := q.order[pos]
+	trackID := q.context[removed]
+
+	q.context = append(q.context[:removed:removed], q.context[removed+1:]...)
+	q.order = append(q.order[:pos:pos], q.order[pos+1:]...)
+	for i, index := range q.order {
+		if index > removed {
+			q.order[i] = index - 1
+		}
+	}
+	for i := range q.history {
+		q.history[i].context = shiftContext(q.history[i].context, removed)
+	}
+	if q.current != nil {
+		q.current.context = shiftContext(q.current.context, removed)
+	}
+	return trackID
+}
+
+// forgetContext detaches the current track and the history from the
+// context before it is replaced.
+func (q *Queue) forgetContext() {
+	for i := range q.history {
+		q.history[i].context = -1
+	}
+	if q.current != nil {
+		q.current.context = -1
+	}
+}
+
+// position returns the position in the play order of a context index.
+func (q *Queue) position(context int) int {
+	for pos, index := range q.order {
+		if index == context {
+			return pos
+		}
+	}
+	return -1
+}
+
+// shuffleFrom shuffles the play order from position on with the seed.
+func (q *Queue) shuffleFrom(position int) {
+	if position >= len(q.order) {
+		return
+	}
+	rest := q.order[position:]
+	random := rand.New(rand.NewSource(q.seed))
+	random.Shuffle(len(rest), func(i, j int) {
+		rest[i], rest[j] = rest[j], rest[i]
+	})
+}
+
+// identityOrder returns the play order of an unshuffled context.
+func identityOrder(n int) []int {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	return order
+}
+
+// shiftContext adjusts a context index for the removal of removed.
+func shiftContext(context, removed int) int {
+	switch {
+	case context == removed:
+		return -1
+	case context > removed:
+		return context - 1
+	default:
+		return context
+	}
+}
+
+// requireQueueTracks rejects empty track IDs.
+func requireQueueTracks(trackIDs []TrackID) error {
+	for _, trackID := range trackIDs {
+		if trackID.IsEmpty() {
+			return NewDomainError(ErrInvalidTrackID, "cannot queue an empty track ID")
+		}
+	}
+	return nil
+}
+
+// invalidQueuePosition reports a position outside the length positions.
+func invalidQueuePosition(position, length int) *DomainError {
+	return NewDomainError(
+		ErrInvalidQueuePosition,
+		fmt.Sprintf("position %d is out of range (queue has %d tracks)", position, length),
+	).WithContext("position", position).WithContext("length", length)
+}
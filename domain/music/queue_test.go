@@ -0,0 +1,332 @@
+package music
+
+import "testing"
+
+// queueIDs builds track IDs from their values.
+func queueIDs(values ...string) []TrackID {
+	ids := make([]TrackID, len(values))
+	for i, value := range values {
+		ids[i] = NewTrackID(value)
+	}
+	return ids
+}
+
+// idValues returns the values of track IDs.
+func idValues(ids []TrackID) []string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = id.Value()
+	}
+	return values
+}
+
+// newTestQueue returns a queue playing the context a..e from start.
+func newTestQueue(t *testing.T, start int) *Queue {
+	t.Helper()
+	q := NewQueue(0)
+	if err := q.SetContext(queueIDs("a", "b", "c", "d", "e"), start); err != nil {
+		t.Fatal(err)
+	}
+	return q
+}
+
+// playAll advances until the queue runs out or limit tracks have played.
+func playAll(q *Queue, limit int) []string {
+	var played []string
+	for range limit {
+		trackID, ok := q.Advance()
+		if !ok {
+			break
+		}
+		played = append(played, trackID.Value())
+	}
+	return played
+}
+
+// current returns the value of the current track, or "" when none.
+func current(q *Queue) string {
+	trackID, _ := q.Current()
+	return trackID.Value()
+}
+
+func TestQueueAdvance(t *testing.T) {
+	q := newTestQueue(t, 1)
+	if current(q) != "b" || q.Index() != 1 {
+		t.Fatalf("expected b current at index 1, got %q at %d", current(q), q.Index())
+	}
+
+	if got := playAll(q, 10); !equalStrings(got, []string{"c", "d", "e"}) {
+		t.Errorf("unexpected tracks played %v", got)
+	}
+	if _, ok := q.Current(); ok {
+		t.Error("expected nothing current after the end of the context")
+	}
+	if got := idValues(q.History()); !equalStrings(got, []string{"b", "c", "d", "e"}) {
+		t.Errorf("unexpected history %v", got)
+	}
+
+	// Tracks appended after the end play next
+	if err := q.Append(NewTrackID("f")); err != nil {
+		t.Fatal(err)
+	}
+	if trackID, ok := q.Next(); !ok || trackID.Value() != "f" {
+		t.Errorf("expected the appended track to play, got %q", trackID.Value())
+	}
+}
+
+func TestQueueRepeat(t *testing.T) {
+	q := newTestQueue(t, 3)
+
+	if err := q.SetRepeat(RepeatModeOne); err != nil {
+		t.Fatal(err)
+	}
+	if got := playAll(q, 2); !equalStrings(got, []string{"d", "d"}) {
+		t.Errorf("expected repeat one to replay the track, got %v", got)
+	}
+
+	// Skipping ignores repeat one
+	if trackID, _ := q.Next(); trackID.Value() != "e" {
+		t.Errorf("expected Next to skip to e, got %q", trackID.Value())
+	}
+
+	if err := q.SetRepeat(RepeatModeAll); err != nil {
+		t.Fatal(err)
+	}
+	if got := idValues(q.UpNext(0)); !equalStrings(got, []string{"a", "b", "c", "d", "e"}) {
+		t.Errorf("expected the wrap in up next, got %v", got)
+	}
+	if got := playAll(q, 3); !equalStrings(got, []string{"a", "b", "c"}) {
+		t.Errorf("expected repeat all to wrap, got %v", got)
+	}
+
+	if err := q.SetRepeat(RepeatMode(9)); !IsError(err, ErrInvalidRepeatMode) {
+		t.Errorf("expected ErrInvalidRepeatMode, got %v", err)
+	}
+}
+
+func TestQueuePlayNextSegment(t *testing.T) {
+	q := newTestQueue(t, 0)
+
+	if err := q.PlayLater(NewTrackID("y")); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.PlayNext(queueIDs("x1", "x2")...); err != nil {
+		t.Fatal(err)
+	}
+	if got := idValues(q.UpNext(0)); !equalStrings(got, []string{"x1", "x2", "y", "b", "c", "d", "e"}) {
+		t.Errorf("unexpected up next %v", got)
+	}
+	if got := idValues(q.UpNext(2)); !equalStrings(got, []string{"x1", "x2"}) {
+		t.Errorf("expected up next to be limited, got %v", got)
+	}
+
+	// The context resumes where it left off
+	if got := playAll(q, 4); !equalStrings(got, []string{"x1", "x2", "y", "b"}) {
+		t.Errorf("unexpected tracks played %v", got)
+	}
+	if q.Index() != 1 {
+		t.Errorf("expected index 1, got %d", q.Index())
+	}
+
+	if err := q.PlayNext(NewTrackID("")); !IsError(err, ErrInvalidTrackID) {
+		t.Errorf("expected ErrInvalidTrackID, got %v", err)
+	}
+}
+
+func TestQueuePrevious(t *testing.T) {
+	q := newTestQueue(t, 0)
+	_ = q.PlayLater(NewTrackID("x"))
+	playAll(q, 2) // x, b
+
+	// Back to x: b plays again after it
+	if trackID, ok := q.Previous(); !ok || trackID.Value() != "x" {
+		t.Fatalf("expected x, got %q", trackID.Value())
+	}
+	if got := idValues(q.UpNext(0)); !equalStrings(got, []string{"b", "c", "d", "e"}) {
+		t.Errorf("unexpected up next %v", got)
+	}
+
+	// Back to a: x returns to the play next segment
+	if trackID, _ := q.Previous(); trackID.Value() != "a" || q.Index() != 0 {
+		t.Fatalf("expected a at index 0, got %q at %d", trackID.Value(), q.Index())
+	}
+	if got := idValues(q.UpNext(0)); !equalStrings(got, []string{"x", "b", "c", "d", "e"}) {
+		t.Errorf("unexpected up next %v", got)
+	}
+
+	if _, ok := q.Previous(); ok {
+		t.Error("expected no previous track at the start of the history")
+	}
+}
+
+func TestQueueShuffle(t *testing.T) {
+	tracks := queueIDs("a", "b", "c", "d", "e", "f", "g", "h")
+	shuffled := func(seed int64) []string {
+		q := NewQueue(0)
+		_ = q.SetContext(tracks, 2)
+		q.Shuffle(seed)
+		return idValues(q.UpNext(0))
+	}
+
+	first := shuffled(42)
+	if !equalStrings(first, shuffled(42)) {
+		t.Errorf("expected the same seed to give the same order, got %v and %v", first, shuffled(42))
+	}
+	if equalStrings(first, []string{"d", "e", "f", "g", "h"}) || len(first) != 5 {
+		t.Errorf("expected the tracks after c to be shuffled, got %v", first)
+	}
+
+	q := NewQueue(0)
+	_ = q.SetContext(tracks, 2)
+	q.Shuffle(42)
+	if !q.Shuffled() || q.Seed() != 42 || current(q) != "c" {
+		t.Errorf("expected a shuffled queue still playing c, got %v %d %q", q.Shuffled(), q.Seed(), current(q))
+	}
+
+	// Unshuffling continues in album order after the last context track
+	next, _ := q.Next()
+	q.Unshuffle()
+	played := int(next.Value()[0] - 'a')
+	if q.Shuffled() || q.Index() != played {
+		t.Errorf("expected the original order from %s, got index %d", next.Value(), q.Index())
+	}
+	if got := idValues(q.UpNext(0)); !equalStrings(got, idValues(tracks[played+1:])) {
+		t.Errorf("expected the tracks after %s in order, got %v", next.Value(), got)
+	}
+
+	// A new context shuffles with the recorded seed, starting from start
+	q.Shuffle(7)
+	if err := q.SetContext(tracks, 5); err != nil {
+		t.Fatal(err)
+	}
+	if current(q) != "f" || q.Index() != 0 || len(q.UpNext(0)) != 7 {
+		t.Errorf("expected f first in a shuffled context, got %q at %d", current(q), q.Index())
+	}
+}
+
+func TestQueueAppendShuffled(t *testing.T) {
+	appended := func() []string {
+		q := newTestQueue(t, 1)
+		q.Shuffle(42)
+		if err := q.Append(queueIDs("f", "g", "h", "i")...); err != nil {
+			t.Fatal(err)
+		}
+		return idValues(q.UpNext(0))
+	}
+
+	first := appended()
+	if !equalStrings(first, appended()) {
+		t.Errorf("expected the same seed to give the same order, got %v and %v", first, appended())
+	}
+	if len(first) != 7 || equalStrings(first[3:], []string{"f", "g", "h", "i"}) {
+		t.Errorf("expected the appended tracks to be mixed in, got %v", first)
+	}
+
+	// The tracks already up next keep their order
+	q := newTestQueue(t, 1)
+	q.Shuffle(42)
+	before := idValues(q.UpNext(0))
+	_ = q.Append(queueIDs("f", "g", "h", "i")...)
+	var kept []string
+	for _, value := range idValues(q.UpNext(0)) {
+		if value < "f" {
+			kept = append(kept, value)
+		}
+	}
+	if !equalStrings(kept, before) {
+		t.Errorf("expected %v to keep their order, got %v", before, kept)
+	}
+
+	q.Unshuffle()
+	if got := idValues(q.UpNext(0)); !equalStrings(got, []string{"c", "d", "e", "f", "g", "h", "i"}) {
+		t.Errorf("expected the appended tracks last once unshuffled, got %v", got)
+	}
+}
+
+func TestQueueJumpRemoveClear(t *testing.T) {
+	q := newTestQueue(t, 0)
+	_ = q.PlayNext(NewTrackID("x"))
+
+	if err := q.Jump(3); err != nil {
+		t.Fatal(err)
+	}
+	if current(q) != "d" {
+		t.Errorf("expected d after the jump, got %q", current(q))
+	}
+	if err := q.Jump(5); !IsError(err, ErrInvalidQueuePosition) {
+		t.Errorf("expected ErrInvalidQueuePosition, got %v", err)
+	}
+
+	// Up next is x, e
+	if trackID, err := q.Remove(1); err != nil || trackID.Value() != "e" {
+		t.Errorf("expected e to be removed, got %q (%v)", trackID.Value(), err)
+	}
+	if trackID, err := q.Remove(0); err != nil || trackID.Value() != "x" {
+		t.Errorf("expected x to be removed, got %q (%v)", trackID.Value(), err)
+	}
+	if _, err := q.Remove(0); !IsError(err, ErrInvalidQueuePosition) {
+		t.Errorf("expected ErrInvalidQueuePosition, got %v", err)
+	}
+	if got := idValues(q.Context()); !equalStrings(got, []string{"a", "b", "c", "d"}) {
+		t.Errorf("unexpected context %v", got)
+	}
+
+	q.Clear()
+	if current(q) != "d" || len(q.UpNext(0)) != 0 || len(q.Context()) != 0 {
+		t.Errorf("expected only the current track to remain, got %q and %v", current(q), q.UpNext(0))
+	}
+	if got := idValues(q.History()); !equalStrings(got, []string{"a"}) {
+		t.Errorf("unexpected history %v", got)
+	}
+	if _, ok := q.Previous(); !ok || current(q) != "a" {
+		t.Errorf("expected to go back to a, got %q", current(q))
+	}
+}
+
+func TestQueueOrderAndPlayed(t *testing.T) {
+	q := newTestQueue(t, 2)
+	q.Shuffle(7)
+	order := idValues(q.Order())
+	if !equalStrings(order[:3], []string{"a", "b", "c"}) || q.Position() != 2 {
+		t.Errorf("expected a, b, c played with c at 2, got %v at %d", order, q.Position())
+	}
+
+	// A track from the play next segment has no position
+	_ = q.PlayNext(NewTrackID("x"))
+	q.Next()
+	if q.Position() != -1 || q.Index() != 2 {
+		t.Errorf("expected x outside the play order after c, got %d after %d", q.Position(), q.Index())
+	}
+	q.Next()
+
+	if trackID, err := q.RemovePlayed(0); err != nil || trackID.Value() != "a" {
+		t.Errorf("expected a to be removed, got %q (%v)", trackID.Value(), err)
+	}
+	if q.Position() != 2 || current(q) != order[3] {
+		t.Errorf("expected %s to stay current at 2, got %q at %d", order[3], current(q), q.Position())
+	}
+	if _, err := q.RemovePlayed(3); !IsError(err, ErrInvalidQueuePosition) {
+		t.Errorf("expected ErrInvalidQueuePosition for a track not played yet, got %v", err)
+	}
+
+	q.Stop()
+	if _, ok := q.Current(); ok || q.Position() != -1 {
+		t.Errorf("expected nothing current after stop, got %q", current(q))
+	}
+	if trackID, ok := q.Next(); !ok || trackID.Value() != order[4] {
+		t.Errorf("expected next to continue with %s, got %q", order[4], trackID.Value())
+	}
+}
+
+func TestQueueHistoryLimit(t *testing.T) {
+	q := NewQueue(2)
+	_ = q.SetContext(queueIDs("a", "b", "c", "d"), 0)
+	playAll(q, 3)
+
+	if got := idValues(q.History()); !equalStrings(got, []string{"b", "c"}) {
+		t.Errorf("expected the last 2 tracks, got %v", got)
+	}
+	if err := q.SetContext(queueIDs("a"), 1); !IsError(err, ErrInvalidQueuePosition) {
+		t.Errorf("expected ErrInvalidQueuePosition, got %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package music
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CheckRatingRange validates a rating range for GetTracksByRating.
+func CheckRatingRange(minimum, maximum Rating) error {
+	switch {
+	case !minimum.IsValid():
+		return WrapInvalidRating(minimum.Value(), nil)
+	case !maximum.IsValid():
+		return WrapInvalidRating(maximum.Value(), nil)
+	case minimum.Value() > maximum.Value():
+		return NewDomainError(ErrInvalidRating,
+			fmt.Sprintf("minimum rating %d is above maximum rating %d", minimum.Value(), maximum.Value())).
+			WithContext("minimum", minimum.Value()).
+			WithContext("maximum", maximum.Value())
+	}
+	return nil
+}
+
+// TracksByRating returns the tracks rated between minimum and maximum
+// inclusive, best rated first. Tracks with equal ratings keep their order.
+func TracksByRating(tracks []*Track, minimum, maximum Rating) ([]*Track, error) {
+	if err := CheckRatingRange(minimum, maximum); err != nil {
+		return nil, err
+	}
+
+	rated := make([]*Track, 0, len(tracks))
+	for _, track := range tracks {
+		if value := track.Rating.Value(); value >= minimum.Value() && value <= maximum.Value() {
+			rated = append(rated, track)
+		}
+	}
+	sort.SliceStable(rated, func(i, j int) bool {
+		return rated[i].Rating.Value() > rated[j].Rating.Value()
+	})
+	return rated, nil
+}
@@ -0,0 +1,59 @@
+package music
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTracksByRating(t *testing.T) {
+	rated := func(id string, stars int) *Track {
+		track, err := NewTrackWithMetadata(NewTrackID(id), "Title "+id, "Artist", "Album", NewDuration(60),
+			TrackMetadata{Rating: NewRatingFromStars(stars)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return track
+	}
+	tracks := []*Track{rated("a", 3), rated("b", 0), rated("c", 5), rated("d", 3), rated("e", 1)}
+
+	got, err := TracksByRating(tracks, NewRatingFromStars(3), NewRatingFromStars(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, track := range got {
+		ids = append(ids, track.ID.Value())
+	}
+	if !equalStrings(ids, []string{"c", "a", "d"}) {
+		t.Errorf("expected c, a, d, got %v", ids)
+	}
+
+	if got, _ := TracksByRating(tracks, NewRating(0), NewRating(0)); len(got) != 1 || got[0].ID.Value() != "b" {
+		t.Errorf("expected only the unrated track, got %v", got)
+	}
+	if _, err := TracksByRating(tracks, NewRatingFromStars(4), NewRatingFromStars(2)); !errors.Is(err, ErrInvalidRating) {
+		t.Errorf("expected ErrInvalidRating, got %v", err)
+	}
+}
+
+func TestTrackPreference(t *testing.T) {
+	var metadata TrackMetadata
+	for _, preference := range Preferences() {
+		if err := metadata.SetPreference(preference); err != nil {
+			t.Fatal(err)
+		}
+		if metadata.Preference() != preference {
+			t.Errorf("expected %v, got %v", preference, metadata.Preference())
+		}
+		if err := metadata.Validate(); err != nil {
+			t.Errorf("expected %v to be valid, got %v", preference, err)
+		}
+	}
+
+	if err := metadata.SetPreference(Preference(9)); !errors.Is(err, ErrInvalidRating) {
+		t.Errorf("expected ErrInvalidRating, got %v", err)
+	}
+	if err := (TrackMetadata{Loved: true, Disliked: true}).Validate(); !errors.Is(err, ErrInvalidRating) {
+		t.Errorf("expected a loved and disliked track to be invalid, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package music
 
 import (
 	"context"
+	"time"
 )
 
 // PlayerRepository defines the interface for controlling music playback.
@@ -20,6 +21,10 @@ type PlayerRepository interface {
 	// Resume resumes paused playback
 	Resume(ctx context.Context) error
 
+	// Start resumes paused playback or, when stopped, plays the player's
+	// current selection
+	Start(ctx context.Context) error
+
 	// Next advances to the next track in the current context
 	Next(ctx context.Context) error
 
@@ -45,6 +50,14 @@ type PlayerRepository interface {
 	GetCurrentTrack(ctx context.Context) (*Track, error)
 }
 
+// PlayerBatcher is implemented by player repositories that can run several
+// player commands in one round trip, such as one AppleScript invocation.
+type PlayerBatcher interface {
+	// RunCommands runs commands in order and stops at the first failure,
+	// returning how many commands succeeded
+	RunCommands(ctx context.Context, commands []PlayerCommand) (int, error)
+}
+
 // LibrarySearchOptions contains options for searching the music library.
 type LibrarySearchOptions struct {
 	// Query is the search term to look for
@@ -56,6 +69,9 @@ type LibrarySearchOptions struct {
 	// Album filters results to tracks from specific albums
 	Album string
 
+	// Fields limits which fields Query is matched against (empty = all)
+	Fields []SearchField
+
 	// Limit is the maximum number of results to return (0 = no limit)
 	Limit int
 
@@ -66,7 +82,8 @@ type LibrarySearchOptions struct {
 // LibraryRepository defines the interface for accessing the music library.
 // This provides read-only access to tracks and playlists in Music.app.
 type LibraryRepository interface {
-	// Search finds tracks in the library based on the provided criteria
+	// Search finds tracks in the library based on the provided criteria,
+	// best matches first (see RankTracks)
 	Search(ctx context.Context, options LibrarySearchOptions) ([]*Track, error)
 
 	// GetTrack retrieves a specific track by its ID
@@ -161,13 +178,79 @@ type PlaylistRepository interface {
 	// RemoveTrackFromPlaylist removes a track from a playlist
 	RemoveTrackFromPlaylist(ctx context.Context, playlistID PlaylistID, trackID TrackID) error
 
-	// ReorderPlaylistTracks changes the order of tracks in a playlist
+	// ReorderPlaylistTracks replaces the tracks of a playlist with trackIDs,
+	// in order. The list may insert, remove and repeat tracks, so it carries
+	// the result of the Playlist position operations (InsertAt, Move,
+	// RemoveAt, Swap)
 	ReorderPlaylistTracks(ctx context.Context, playlistID PlaylistID, trackIDs []TrackID) error
 
 	// DuplicatePlaylist creates a copy of an existing playlist
 	DuplicatePlaylist(ctx context.Context, playlistID PlaylistID, newName string) (*Playlist, error)
 }
 
+// RatingRepository reads and changes how the user rates tracks: the star
+// rating and the loved or disliked flag Music.app keeps for every track.
+type RatingRepository interface {
+	// SetRating sets the rating of a track; a zero rating clears it
+	SetRating(ctx context.Context, trackID TrackID, rating Rating) error
+
+	// SetPreference marks a track loved or disliked, or clears both with
+	// PreferenceNone
+	SetPreference(ctx context.Context, trackID TrackID, preference Preference) error
+
+	// GetTracksByRating returns the tracks rated between minimum and maximum
+	// inclusive, best rated first
+	GetTracksByRating(ctx context.Context, minimum, maximum Rating) ([]*Track, error)
+}
+
+// SmartPlaylistRepository stores the smart playlists maestro owns. Music.app
+// does not expose the rules of its own smart playlists to scripting, so
+// maestro keeps rules itself and materializes their tracks into regular
+// playlists.
+type SmartPlaylistRepository interface {
+	// GetSmartPlaylists returns every smart playlist, ordered by name
+	GetSmartPlaylists(ctx context.Context) ([]*SmartPlaylist, error)
+
+	// GetSmartPlaylist returns the smart playlist with the given name, or
+	// ErrPlaylistNotFound
+	GetSmartPlaylist(ctx context.Context, name string) (*SmartPlaylist, error)
+
+	// SaveSmartPlaylist creates or replaces the smart playlist with the
+	// playlist's name
+	SaveSmartPlaylist(ctx context.Context, playlist *SmartPlaylist) error
+
+	// DeleteSmartPlaylist removes a smart playlist, or returns
+	// ErrPlaylistNotFound
+	DeleteSmartPlaylist(ctx context.Context, name string) error
+}
+
+// HistoryRepository keeps maestro's play history, an append-only log of the
+// tracks played and skipped, along with the claims that attribute a track to
+// maestro.
+type HistoryRepository interface {
+	// AppendHistory adds an entry to the end of the log
+	AppendHistory(ctx context.Context, entry *HistoryEntry) error
+
+	// GetHistory returns the entries filter selects, oldest first
+	GetHistory(ctx context.Context, filter HistoryFilter) ([]*HistoryEntry, error)
+
+	// ClaimPlayback notes that a maestro client started a track at the given
+	// time, so that the recorder attributes the track change to maestro
+	ClaimPlayback(ctx context.Context, at time.Time) error
+
+	// LastClaim returns when a maestro client last started a track, or the
+	// zero time
+	LastClaim(ctx context.Context) (time.Time, error)
+}
+
+// EventSource delivers player and queue events as they are observed.
+// Music.app does not push notifications, so adapters typically derive
+// events by polling and comparing snapshots with PlayerEvents.
+type EventSource interface {
+	// Subscribe returns a channel of events that is closed when ctx is done
+	Subscribe(ctx context.Context) <-chan Event
+}
+
 // RepositoryManager aggregates all repository interfaces for convenience.
 // This can be implemented by a single struct that composes all the individual repositories,
 // or used as a service locator pattern.
@@ -176,6 +259,7 @@ type RepositoryManager interface {
 	LibraryRepository
 	QueueRepository
 	PlaylistRepository
+	RatingRepository
 }
 
 // SearchResult represents a single search result with relevance scoring.
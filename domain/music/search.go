@@ -0,0 +1,177 @@
+package music
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SearchField is a track field a search query can match.
+type SearchField int
+
+const (
+	// SearchFieldTitle matches the track title
+	SearchFieldTitle SearchField = iota
+
+	// SearchFieldArtist matches the artist name
+	SearchFieldArtist
+
+	// SearchFieldAlbum matches the album name
+	SearchFieldAlbum
+)
+
+// String returns the string representation of the SearchField.
+func (f SearchField) String() string {
+	switch f {
+	case SearchFieldTitle:
+		return "title"
+	case SearchFieldArtist:
+		return "artist"
+	case SearchFieldAlbum:
+		return "album"
+	default:
+		return "unknown"
+	}
+}
+
+// IsValid returns true if the SearchField is a valid value.
+func (f SearchField) IsValid() bool {
+	return f >= SearchFieldTitle && f <= SearchFieldAlbum
+}
+
+// SearchFields returns every valid SearchField in declaration order.
+func SearchFields() []SearchField {
+	return []SearchField{SearchFieldTitle, SearchFieldArtist, SearchFieldAlbum}
+}
+
+// ParseSearchField converts a name such as "artist" into a SearchField.
+func ParseSearchField(name string) (SearchField, error) {
+	for _, field := range SearchFields() {
+		if strings.EqualFold(strings.TrimSpace(name), field.String()) {
+			return field, nil
+		}
+	}
+	return SearchFieldTitle, NewDomainError(ErrInvalidSearchQuery, fmt.Sprintf("unknown search field %q (expected title, artist or album)", name))
+}
+
+// value returns the track's value for the field.
+func (f SearchField) value(track *Track) string {
+	switch f {
+	case SearchFieldArtist:
+		return track.Artist
+	case SearchFieldAlbum:
+		return track.Album
+	default:
+		return track.Title
+	}
+}
+
+// weight ranks matches in more specific fields above matches in broader ones.
+func (f SearchField) weight() float64 {
+	switch f {
+	case SearchFieldArtist:
+		return 0.9
+	case SearchFieldAlbum:
+		return 0.8
+	default:
+		return 1.0
+	}
+}
+
+// Match strengths, from strongest to weakest.
+const (
+	matchExact      = 1.0
+	matchPrefix     = 0.8
+	matchWordPrefix = 0.6
+	matchContains   = 0.4
+)
+
+// matchStrength rates how well query matches value, or returns 0.
+func matchStrength(value, query string) float64 {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch {
+	case value == query:
+		return matchExact
+	case strings.HasPrefix(value, query):
+		return matchPrefix
+	}
+	for _, word := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '(' || r == '/'
+	}) {
+		if strings.HasPrefix(word, query) {
+			return matchWordPrefix
+		}
+	}
+	if strings.Contains(value, query) {
+		return matchContains
+	}
+	return 0
+}
+
+// ScoreName rates how well query matches a name such as an album, artist
+// or playlist, from 0 (no match) to 1 (equal, ignoring case), as ScoreTrack
+// rates a single field.
+func ScoreName(name, query string) float64 {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return 0
+	}
+	return matchStrength(name, query)
+}
+
+// searchedFields returns the fields the query is matched against.
+func (o LibrarySearchOptions) searchedFields() []SearchField {
+	if len(o.Fields) == 0 {
+		return SearchFields()
+	}
+	return o.Fields
+}
+
+// ScoreTrack rates how well a track matches the free-text query in
+// options. The score is the strongest weighted field match, from 0 (no
+// match) to 1 (the title equals the query). Without a query every track
+// scores 1 and the artist and album filters in use are reported as matched.
+func ScoreTrack(track *Track, options LibrarySearchOptions) SearchResult {
+	result := SearchResult{Track: track, MatchedFields: []string{}}
+
+	query := strings.ToLower(strings.TrimSpace(options.Query))
+	if query == "" {
+		result.Score = 1
+		if strings.TrimSpace(options.Artist) != "" {
+			result.MatchedFields = append(result.MatchedFields, SearchFieldArtist.String())
+		}
+		if strings.TrimSpace(options.Album) != "" {
+			result.MatchedFields = append(result.MatchedFields, SearchFieldAlbum.String())
+		}
+		return result
+	}
+
+	for _, field := range options.searchedFields() {
+		strength := matchStrength(field.value(track), query)
+		if strength == 0 {
+			continue
+		}
+		result.MatchedFields = append(result.MatchedFields, field.String())
+		if score := strength * field.weight(); score > result.Score {
+			result.Score = score
+		}
+	}
+	return result
+}
+
+// RankTracks scores every track and orders them from best to worst match.
+// Tracks with equal scores keep their original order, except that one
+// matching in more fields comes first.
+func RankTracks(tracks []*Track, options LibrarySearchOptions) []SearchResult {
+	results := make([]SearchResult, len(tracks))
+	for i, track := range tracks {
+		results[i] = ScoreTrack(track, options)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return len(results[i].MatchedFields) > len(results[j].MatchedFields)
+	})
+	return results
+}
@@ -0,0 +1,92 @@
+package music
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSearchField(t *testing.T) {
+	for _, field := range SearchFields() {
+		parsed, err := ParseSearchField(" " + field.String() + " ")
+		if err != nil || parsed != field {
+			t.Errorf("ParseSearchField(%q) = %v, %v", field.String(), parsed, err)
+		}
+	}
+	if _, err := ParseSearchField("genre"); !errors.Is(err, ErrInvalidSearchQuery) {
+		t.Errorf("expected ErrInvalidSearchQuery, got %v", err)
+	}
+	if field, err := ParseSearchField("ALBUM"); err != nil || field != SearchFieldAlbum {
+		t.Errorf("expected album, got %v, %v", field, err)
+	}
+}
+
+func TestScoreTrack(t *testing.T) {
+	track, _ := NewTrack(NewTrackID("1"), "Blue Train", "John Coltrane", "Blue Train", NewDuration(643))
+
+	tests := []struct {
+		name    string
+		options LibrarySearchOptions
+		score   float64
+		matched []string
+	}{
+		{"exact title", LibrarySearchOptions{Query: "blue train"}, 1.0, []string{"title", "album"}},
+		{"title prefix", LibrarySearchOptions{Query: "Blue"}, 0.8, []string{"title", "album"}},
+		{"word prefix", LibrarySearchOptions{Query: "coltr"}, 0.6 * 0.9, []string{"artist"}},
+		{"contains", LibrarySearchOptions{Query: "rain"}, 0.4, []string{"title", "album"}},
+		{"album only", LibrarySearchOptions{Query: "blue train", Fields: []SearchField{SearchFieldAlbum}}, 0.8, []string{"album"}},
+		{"no match", LibrarySearchOptions{Query: "miles"}, 0, []string{}},
+		{"filters only", LibrarySearchOptions{Artist: "coltrane"}, 1, []string{"artist"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ScoreTrack(track, tt.options)
+			if result.Score != tt.score {
+				t.Errorf("expected score %v, got %v", tt.score, result.Score)
+			}
+			if len(result.MatchedFields) != len(tt.matched) {
+				t.Fatalf("expected matched fields %v, got %v", tt.matched, result.MatchedFields)
+			}
+			for i := range tt.matched {
+				if result.MatchedFields[i] != tt.matched[i] {
+					t.Errorf("expected matched fields %v, got %v", tt.matched, result.MatchedFields)
+				}
+			}
+		})
+	}
+}
+
+func TestRankTracks(t *testing.T) {
+	contains, _ := NewTrack(NewTrackID("1"), "All Blues", "Miles Davis", "Kind of Blue", NewDuration(693))
+	prefix, _ := NewTrack(NewTrackID("2"), "Blue in Green", "Miles Davis", "Kind of Blue", NewDuration(337))
+	exact, _ := NewTrack(NewTrackID("3"), "Blue", "Joni Mitchell", "Blue", NewDuration(180))
+
+	results := RankTracks([]*Track{contains, prefix, exact}, LibrarySearchOptions{Query: "blue"})
+
+	want := []string{"3", "2", "1"}
+	for i, result := range results {
+		if result.Track.ID.Value() != want[i] {
+			t.Errorf("position %d: expected track %s, got %s", i, want[i], result.Track.ID.Value())
+		}
+	}
+}
+
+func TestScoreName(t *testing.T) {
+	tests := []struct {
+		name, query string
+		score       float64
+	}{
+		{"Kind of Blue", "kind of blue", 1.0},
+		{"Kind of Blue", " Kind ", 0.8},
+		{"Kind of Blue", "blu", 0.6},
+		{"Kind of Blue", "nd of", 0.4},
+		{"Kind of Blue", "train", 0},
+		{"Kind of Blue", "  ", 0},
+	}
+
+	for _, tt := range tests {
+		if score := ScoreName(tt.name, tt.query); score != tt.score {
+			t.Errorf("ScoreName(%q, %q) = %v, want %v", tt.name, tt.query, score, tt.score)
+		}
+	}
+}
@@ -0,0 +1,993 @@
+package music
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SmartField is a track field that smart playlist rules test and sort on.
+type SmartField int
+
+const (
+	// SmartFieldTitle is the track title
+	SmartFieldTitle SmartField = iota
+
+	// SmartFieldArtist is the track artist
+	SmartFieldArtist
+
+	// SmartFieldAlbum is the album name
+	SmartFieldAlbum
+
+	// SmartFieldAlbumArtist is the artist the album is filed under
+	SmartFieldAlbumArtist
+
+	// SmartFieldGenre is the genre
+	SmartFieldGenre
+
+	// SmartFieldComposer is the composer
+	SmartFieldComposer
+
+	// SmartFieldKind is the file kind, e.g. "AAC audio file"
+	SmartFieldKind
+
+	// SmartFieldYear is the release year
+	SmartFieldYear
+
+	// SmartFieldDuration is the length in seconds; values may also be
+	// written as "4:30"
+	SmartFieldDuration
+
+	// SmartFieldTrackNumber is the position on the disc
+	SmartFieldTrackNumber
+
+	// SmartFieldDiscNumber is the disc number
+	SmartFieldDiscNumber
+
+	// SmartFieldRating is the rating from 0 to 100, 20 per star
+	SmartFieldRating
+
+	// SmartFieldPlayCount is the number of plays
+	SmartFieldPlayCount
+
+	// SmartFieldSkipCount is the number of skips
+	SmartFieldSkipCount
+
+	// SmartFieldBitRate is the bit rate in kbps
+	SmartFieldBitRate
+
+	// SmartFieldLoved is whether the track is loved
+	SmartFieldLoved
+
+	// SmartFieldExplicit is whether the track is explicit
+	SmartFieldExplicit
+
+	// SmartFieldLastPlayed is when the track was last played
+	SmartFieldLastPlayed
+
+	// SmartFieldDateAdded is when the track was added to the library
+	SmartFieldDateAdded
+)
+
+// String returns the string representation of the SmartField.
+func (f SmartField) String() string {
+	switch f {
+	case SmartFieldTitle:
+		return "title"
+	case SmartFieldArtist:
+		return "artist"
+	case SmartFieldAlbum:
+		return "album"
+	case SmartFieldAlbumArtist:
+		return "album_artist"
+	case SmartFieldGenre:
+		return "genre"
+	case SmartFieldComposer:
+		return "composer"
+	case SmartFieldKind:
+		return "kind"
+	case SmartFieldYear:
+		return "year"
+	case SmartFieldDuration:
+		return "duration"
+	case SmartFieldTrackNumber:
+		return "track_number"
+	case SmartFieldDiscNumber:
+		return "disc_number"
+	case SmartFieldRating:
+		return "rating"
+	case SmartFieldPlayCount:
+		return "play_count"
+	case SmartFieldSkipCount:
+		return "skip_count"
+	case SmartFieldBitRate:
+		return "bit_rate"
+	case SmartFieldLoved:
+		return "loved"
+	case SmartFieldExplicit:
+		return "explicit"
+	case SmartFieldLastPlayed:
+		return "last_played"
+	case SmartFieldDateAdded:
+		return "date_added"
+	default:
+		return "unknown"
+	}
+}
+
+// IsValid returns true if the SmartField is a valid value.
+func (f SmartField) IsValid() bool {
+	return f >= SmartFieldTitle && f <= SmartFieldDateAdded
+}
+
+// SmartFields returns every valid SmartField in declaration order.
+func SmartFields() []SmartField {
+	fields := make([]SmartField, 0, SmartFieldDateAdded+1)
+	for f := SmartFieldTitle; f <= SmartFieldDateAdded; f++ {
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// ParseSmartField converts a name such as "play_count" into a SmartField.
+func ParseSmartField(name string) (SmartField, error) {
+	for _, field := range SmartFields() {
+		if strings.EqualFold(strings.TrimSpace(name), field.String()) {
+			return field, nil
+		}
+	}
+	return SmartFieldTitle, NewDomainError(ErrInvalidSmartRule, fmt.Sprintf("unknown smart playlist field %q", name))
+}
+
+// MarshalText encodes the SmartField as its name.
+func (f SmartField) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+// UnmarshalText decodes a SmartField from its name.
+func (f *SmartField) UnmarshalText(text []byte) error {
+	field, err := ParseSmartField(string(text))
+	if err != nil {
+		return err
+	}
+	*f = field
+	return nil
+}
+
+// smartValueType is the kind of value a SmartField holds.
+type smartValueType int
+
+const (
+	smartText smartValueType = iota
+	smartNumber
+	smartBool
+	smartDate
+)
+
+// valueType returns the kind of value the field holds.
+func (f SmartField) valueType() smartValueType {
+	switch f {
+	case SmartFieldYear, SmartFieldDuration, SmartFieldTrackNumber, SmartFieldDiscNumber,
+		SmartFieldRating, SmartFieldPlayCount, SmartFieldSkipCount, SmartFieldBitRate:
+		return smartNumber
+	case SmartFieldLoved, SmartFieldExplicit:
+		return smartBool
+	case SmartFieldLastPlayed, SmartFieldDateAdded:
+		return smartDate
+	default:
+		return smartText
+	}
+}
+
+// text returns the value of a text field of track.
+func (f SmartField) text(track *Track) string {
+	switch f {
+	case SmartFieldTitle:
+		return track.Title
+	case SmartFieldArtist:
+		return track.Artist
+	case SmartFieldAlbum:
+		return track.Album
+	case SmartFieldAlbumArtist:
+		return track.AlbumArtist
+	case SmartFieldGenre:
+		return track.Genre
+	case SmartFieldComposer:
+		return track.Composer
+	case SmartFieldKind:
+		return track.Kind
+	default:
+		return ""
+	}
+}
+
+// number returns the value of a number or boolean field of track, with
+// true as 1.
+func (f SmartField) number(track *Track) int {
+	switch f {
+	case SmartFieldYear:
+		return track.Year
+	case SmartFieldDuration:
+		return track.Duration.Seconds()
+	case SmartFieldTrackNumber:
+		return track.TrackNumber
+	case SmartFieldDiscNumber:
+		return track.DiscNumber
+	case SmartFieldRating:
+		return track.Rating.Value()
+	case SmartFieldPlayCount:
+		return track.PlayCount
+	case SmartFieldSkipCount:
+		return track.SkipCount
+	case SmartFieldBitRate:
+		return track.BitRate
+	case SmartFieldLoved:
+		return boolNumber(track.Loved)
+	case SmartFieldExplicit:
+		return boolNumber(track.Explicit)
+	default:
+		return 0
+	}
+}
+
+// date returns the value of a date field of track.
+func (f SmartField) date(track *Track) time.Time {
+	switch f {
+	case SmartFieldLastPlayed:
+		return track.LastPlayed
+	case SmartFieldDateAdded:
+		return track.DateAdded
+	default:
+		return time.Time{}
+	}
+}
+
+func boolNumber(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// SmartOperator compares a track field with a rule's value.
+type SmartOperator int
+
+const (
+	// SmartOperatorIs matches equal values; text ignores case and dates
+	// match the whole day
+	SmartOperatorIs SmartOperator = iota
+
+	// SmartOperatorIsNot matches values that are not equal
+	SmartOperatorIsNot
+
+	// SmartOperatorContains matches text containing the value
+	SmartOperatorContains
+
+	// SmartOperatorDoesNotContain matches text without the value
+	SmartOperatorDoesNotContain
+
+	// SmartOperatorStartsWith matches text starting with the value
+	SmartOperatorStartsWith
+
+	// SmartOperatorEndsWith matches text ending with the value
+	SmartOperatorEndsWith
+
+	// SmartOperatorGreaterThan matches numbers above the value and dates
+	// after it
+	SmartOperatorGreaterThan
+
+	// SmartOperatorLessThan matches numbers below the value and dates
+	// before it
+	SmartOperatorLessThan
+
+	// SmartOperatorBetween matches numbers and dates from the value to
+	// SmartRule.To, inclusive
+	SmartOperatorBetween
+
+	// SmartOperatorInLast matches dates within a period before now, such
+	// as "30d"
+	SmartOperatorInLast
+
+	// SmartOperatorNotInLast matches dates before a period before now,
+	// and dates never set
+	SmartOperatorNotInLast
+)
+
+// String returns the string representation of the SmartOperator.
+func (o SmartOperator) String() string {
+	switch o {
+	case SmartOperatorIs:
+		return "is"
+	case SmartOperatorIsNot:
+		return "is_not"
+	case SmartOperatorContains:
+		return "contains"
+	case SmartOperatorDoesNotContain:
+		return "does_not_contain"
+	case SmartOperatorStartsWith:
+		return "starts_with"
+	case SmartOperatorEndsWith:
+		return "ends_with"
+	case SmartOperatorGreaterThan:
+		return "greater_than"
+	case SmartOperatorLessThan:
+		return "less_than"
+	case SmartOperatorBetween:
+		return "between"
+	case SmartOperatorInLast:
+		return "in_last"
+	case SmartOperatorNotInLast:
+		return "not_in_last"
+	default:
+		return "unknown"
+	}
+}
+
+// IsValid returns true if the SmartOperator is a valid value.
+func (o SmartOperator) IsValid() bool {
+	return o >= SmartOperatorIs && o <= SmartOperatorNotInLast
+}
+
+// SmartOperators returns every valid SmartOperator in declaration order.
+func SmartOperators() []SmartOperator {
+	return []SmartOperator{
+		SmartOperatorIs, SmartOperatorIsNot, SmartOperatorContains, SmartOperatorDoesNotContain,
+		SmartOperatorStartsWith, SmartOperatorEndsWith, SmartOperatorGreaterThan, SmartOperatorLessThan,
+		SmartOperatorBetween, SmartOperatorInLast, SmartOperatorNotInLast,
+	}
+}
+
+// ParseSmartOperator converts a name such as "contains" into a
+// SmartOperator.
+func ParseSmartOperator(name string) (SmartOperator, error) {
+	for _, operator := range SmartOperators() {
+		if strings.EqualFold(strings.TrimSpace(name), operator.String()) {
+			return operator, nil
+		}
+	}
+	return SmartOperatorIs, NewDomainError(ErrInvalidSmartRule, fmt.Sprintf("unknown smart playlist operator %q", name))
+}
+
+// MarshalText encodes the SmartOperator as its name.
+func (o SmartOperator) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+// UnmarshalText decodes a SmartOperator from its name.
+func (o *SmartOperator) UnmarshalText(text []byte) error {
+	operator, err := ParseSmartOperator(string(text))
+	if err != nil {
+		return err
+	}
+	*o = operator
+	return nil
+}
+
+// appliesTo reports whether the operator can compare values of type t.
+func (o SmartOperator) appliesTo(t smartValueType) bool {
+	switch o {
+	case SmartOperatorIs, SmartOperatorIsNot:
+		return true
+	case SmartOperatorContains, SmartOperatorDoesNotContain, SmartOperatorStartsWith, SmartOperatorEndsWith:
+		return t == smartText
+	case SmartOperatorGreaterThan, SmartOperatorLessThan, SmartOperatorBetween:
+		return t == smartNumber || t == smartDate
+	case SmartOperatorInLast, SmartOperatorNotInLast:
+		return t == smartDate
+	default:
+		return false
+	}
+}
+
+// SmartMatch selects whether a track must match all rules or any of them.
+type SmartMatch int
+
+const (
+	// SmartMatchAll matches tracks that pass every rule
+	SmartMatchAll SmartMatch = iota
+
+	// SmartMatchAny matches tracks that pass at least one rule
+	SmartMatchAny
+)
+
+// String returns the string representation of the SmartMatch.
+func (m SmartMatch) String() string {
+	switch m {
+	case SmartMatchAll:
+		return "all"
+	case SmartMatchAny:
+		return "any"
+	default:
+		return "unknown"
+	}
+}
+
+// IsValid returns true if the SmartMatch is a valid value.
+func (m SmartMatch) IsValid() bool {
+	return m == SmartMatchAll || m == SmartMatchAny
+}
+
+// SmartMatches returns every valid SmartMatch in declaration order.
+func SmartMatches() []SmartMatch {
+	return []SmartMatch{SmartMatchAll, SmartMatchAny}
+}
+
+// ParseSmartMatch converts a name such as "any" into a SmartMatch.
+func ParseSmartMatch(name string) (SmartMatch, error) {
+	for _, match := range SmartMatches() {
+		if strings.EqualFold(strings.TrimSpace(name), match.String()) {
+			return match, nil
+		}
+	}
+	return SmartMatchAll, NewDomainError(ErrInvalidSmartRule, fmt.Sprintf("unknown smart playlist match %q (expected all or any)", name))
+}
+
+// MarshalText encodes the SmartMatch as its name.
+func (m SmartMatch) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText decodes a SmartMatch from its name.
+func (m *SmartMatch) UnmarshalText(text []byte) error {
+	match, err := ParseSmartMatch(string(text))
+	if err != nil {
+		return err
+	}
+	*m = match
+	return nil
+}
+
+// SmartLimitUnit is what a smart playlist limit counts.
+type SmartLimitUnit int
+
+const (
+	// SmartLimitItems counts tracks
+	SmartLimitItems SmartLimitUnit = iota
+
+	// SmartLimitMinutes counts minutes of music
+	SmartLimitMinutes
+
+	// SmartLimitHours counts hours of music
+	SmartLimitHours
+)
+
+// String returns the string representation of the SmartLimitUnit.
+func (u SmartLimitUnit) String() string {
+	switch u {
+	case SmartLimitItems:
+		return "items"
+	case SmartLimitMinutes:
+		return "minutes"
+	case SmartLimitHours:
+		return "hours"
+	default:
+		return "unknown"
+	}
+}
+
+// IsValid returns true if the SmartLimitUnit is a valid value.
+func (u SmartLimitUnit) IsValid() bool {
+	return u >= SmartLimitItems && u <= SmartLimitHours
+}
+
+// SmartLimitUnits returns every valid SmartLimitUnit in declaration order.
+func SmartLimitUnits() []SmartLimitUnit {
+	return []SmartLimitUnit{SmartLimitItems, SmartLimitMinutes, SmartLimitHours}
+}
+
+// ParseSmartLimitUnit converts a name such as "minutes" into a
+// SmartLimitUnit.
+func ParseSmartLimitUnit(name string) (SmartLimitUnit, error) {
+	for _, unit := range SmartLimitUnits() {
+		if strings.EqualFold(strings.TrimSpace(name), unit.String()) {
+			return unit, nil
+		}
+	}
+	return SmartLimitItems, NewDomainError(ErrInvalidSmartRule,
+		fmt.Sprintf("unknown smart playlist limit unit %q (expected items, minutes or hours)", name))
+}
+
+// MarshalText encodes the SmartLimitUnit as its name.
+func (u SmartLimitUnit) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText decodes a SmartLimitUnit from its name.
+func (u *SmartLimitUnit) UnmarshalText(text []byte) error {
+	unit, err := ParseSmartLimitUnit(string(text))
+	if err != nil {
+		return err
+	}
+	*u = unit
+	return nil
+}
+
+// SmartRule is one condition of a smart playlist, such as "genre is Jazz".
+type SmartRule struct {
+	// Field is the track field tested
+	Field SmartField `json:"field"`
+
+	// Operator is the comparison
+	Operator SmartOperator `json:"operator"`
+
+	// Value is the value compared with: text, a whole number, true or
+	// false, a date (2006-01-02 or RFC 3339) or, for in_last and
+	// not_in_last, a period such as "12h", "30d", "2w", "6mo" or "1y"
+	Value string `json:"value"`
+
+	// To is the upper bound of a between rule
+	To string `json:"to,omitempty"`
+}
+
+// ParseSmartRule parses a rule written as "<field> <operator> <value>",
+// such as "genre is Jazz", "play_count greater_than 10",
+// "year between 1955 and 1960" or "last_played in_last 30d".
+func ParseSmartRule(text string) (SmartRule, error) {
+	parts := strings.Fields(text)
+	if len(parts) < 3 {
+		return SmartRule{}, NewDomainError(ErrInvalidSmartRule,
+			fmt.Sprintf("smart playlist rule %q must be <field> <operator> <value>", text))
+	}
+
+	field, err := ParseSmartField(parts[0])
+	if err != nil {
+		return SmartRule{}, err
+	}
+	operator, err := ParseSmartOperator(parts[1])
+	if err != nil {
+		return SmartRule{}, err
+	}
+
+	rule := SmartRule{Field: field, Operator: operator, Value: strings.Join(parts[2:], " ")}
+	if operator == SmartOperatorBetween {
+		bounds := parts[2:]
+		if len(bounds) == 3 && strings.EqualFold(bounds[1], "and") {
+			bounds = []string{bounds[0], bounds[2]}
+		}
+		if len(bounds) != 2 {
+			return SmartRule{}, NewDomainError(ErrInvalidSmartRule,
+				fmt.Sprintf("smart playlist rule %q must be <field> between <from> and <to>", text))
+		}
+		rule.Value, rule.To = bounds[0], bounds[1]
+	}
+
+	if err := rule.Validate(); err != nil {
+		return SmartRule{}, err
+	}
+	return rule, nil
+}
+
+// String returns the rule in the form ParseSmartRule reads.
+func (r SmartRule) String() string {
+	if r.Operator == SmartOperatorBetween {
+		return fmt.Sprintf("%s %s %s and %s", r.Field, r.Operator, r.Value, r.To)
+	}
+	return fmt.Sprintf("%s %s %s", r.Field, r.Operator, r.Value)
+}
+
+// Validate checks that the operator applies to the field and that the
+// value can be read as the field's type.
+func (r SmartRule) Validate() error {
+	_, err := r.compile(time.Time{})
+	return err
+}
+
+// smartMatcher tests one track.
+type smartMatcher func(track *Track) bool
+
+// compile checks the rule and returns a matcher for it. Periods are
+// measured back from now.
+func (r SmartRule) compile(now time.Time) (smartMatcher, error) {
+	invalid := func(message string) error {
+		return NewDomainError(ErrInvalidSmartRule, fmt.Sprintf("smart playlist rule %q: %s", r.String(), message)).
+			WithContext("field", r.Field.String())
+	}
+
+	if !r.Field.IsValid() {
+		return nil, invalid("unknown field")
+	}
+	if !r.Operator.IsValid() {
+		return nil, invalid("unknown operator")
+	}
+	valueType := r.Field.valueType()
+	if !r.Operator.appliesTo(valueType) {
+		return nil, invalid(fmt.Sprintf("%s does not apply to %s", r.Operator, r.Field))
+	}
+	if r.Operator != SmartOperatorBetween && r.To != "" {
+		return nil, invalid("only between takes an upper bound")
+	}
+
+	switch valueType {
+	case smartText:
+		return r.compileText(), nil
+	case smartBool:
+		value, err := parseSmartBool(r.Value)
+		if err != nil {
+			return nil, invalid(err.Error())
+		}
+		field, want := r.Field, boolNumber(value)
+		if r.Operator == SmartOperatorIsNot {
+			want = 1 - want
+		}
+		return func(track *Track) bool { return field.number(track) == want }, nil
+	case smartNumber:
+		matcher, err := r.compileNumber()
+		if err != nil {
+			return nil, invalid(err.Error())
+		}
+		return matcher, nil
+	default:
+		matcher, err := r.compileDate(now)
+		if err != nil {
+			return nil, invalid(err.Error())
+		}
+		return matcher, nil
+	}
+}
+
+// compileText returns a matcher for a text rule. Comparisons ignore case.
+func (r SmartRule) compileText() smartMatcher {
+	field, value := r.Field, strings.ToLower(r.Value)
+	var test func(text string) bool
+	switch r.Operator {
+	case SmartOperatorIs:
+		test = func(text string) bool { return text == value }
+	case SmartOperatorIsNot:
+		test = func(text string) bool { return text != value }
+	case SmartOperatorContains:
+		test = func(text string) bool { return strings.Contains(text, value) }
+	case SmartOperatorDoesNotContain:
+		test = func(text string) bool { return !strings.Contains(text, value) }
+	case SmartOperatorStartsWith:
+		test = func(text string) bool { return strings.HasPrefix(text, value) }
+	default:
+		test = func(text string) bool { return strings.HasSuffix(text, value) }
+	}
+	return func(track *Track) bool { return test(strings.ToLower(field.text(track))) }
+}
+
+// compileNumber returns a matcher for a number rule.
+func (r SmartRule) compileNumber() (smartMatcher, error) {
+	value, err := r.parseNumber(r.Value)
+	if err != nil {
+		return nil, err
+	}
+	to := value
+	if r.Operator == SmartOperatorBetween {
+		if to, err = r.parseNumber(r.To); err != nil {
+			return nil, err
+		}
+		if to < value {
+			return nil, fmt.Errorf("%d is below %d", to, value)
+		}
+	}
+
+	field := r.Field
+	var test func(n int) bool
+	switch r.Operator {
+	case SmartOperatorIs:
+		test = func(n int) bool { return n == value }
+	case SmartOperatorIsNot:
+		test = func(n int) bool { return n != value }
+	case SmartOperatorGreaterThan:
+		test = func(n int) bool { return n > value }
+	case SmartOperatorLessThan:
+		test = func(n int) bool { return n < value }
+	default:
+		test = func(n int) bool { return n >= value && n <= to }
+	}
+	return func(track *Track) bool { return test(field.number(track)) }, nil
+}
+
+// parseNumber reads a number value; durations may be written as "4:30".
+func (r SmartRule) parseNumber(text string) (int, error) {
+	text = strings.TrimSpace(text)
+	if r.Field == SmartFieldDuration {
+		if duration, sign, err := ParseDuration(text); err == nil && sign == 0 {
+			return duration.Seconds(), nil
+		}
+	}
+	n, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a whole number", text)
+	}
+	if r.Field == SmartFieldRating && (n < MinRating || n > MaxRating) {
+		return 0, fmt.Errorf("rating %d is not between %d and %d", n, MinRating, MaxRating)
+	}
+	return n, nil
+}
+
+// compileDate returns a matcher for a date rule. Dates that were never set
+// only match is_not and not_in_last.
+func (r SmartRule) compileDate(now time.Time) (smartMatcher, error) {
+	field := r.Field
+	var test func(t time.Time) bool
+
+	switch r.Operator {
+	case SmartOperatorInLast, SmartOperatorNotInLast:
+		period, err := parseSmartPeriod(r.Value)
+		if err != nil {
+			return nil, err
+		}
+		since := now.Add(-period)
+		if r.Operator == SmartOperatorInLast {
+			test = func(t time.Time) bool { return !t.IsZero() && !t.Before(since) }
+		} else {
+			test = func(t time.Time) bool { return t.IsZero() || t.Before(since) }
+		}
+	default:
+		from, fromExact, err := parseSmartDate(r.Value, now.Location())
+		if err != nil {
+			return nil, err
+		}
+		// A day covers up to its end; an exact time only itself
+		end := func(t time.Time, exact bool) time.Time {
+			if exact {
+				return t
+			}
+			return t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		until := end(from, fromExact)
+		if r.Operator == SmartOperatorBetween {
+			to, toExact, err := parseSmartDate(r.To, now.Location())
+			if err != nil {
+				return nil, err
+			}
+			if to.Before(from) {
+				return nil, fmt.Errorf("%s is before %s", r.To, r.Value)
+			}
+			until = end(to, toExact)
+		}
+
+		within := func(t time.Time) bool { return !t.IsZero() && !t.Before(from) && !t.After(until) }
+		switch r.Operator {
+		case SmartOperatorIs, SmartOperatorBetween:
+			test = within
+		case SmartOperatorIsNot:
+			test = func(t time.Time) bool { return !within(t) }
+		case SmartOperatorGreaterThan:
+			test = func(t time.Time) bool { return !t.IsZero() && t.After(until) }
+		default:
+			test = func(t time.Time) bool { return !t.IsZero() && t.Before(from) }
+		}
+	}
+	return func(track *Track) bool { return test(field.date(track)) }, nil
+}
+
+// parseSmartBool reads true or false, also written yes or no.
+func parseSmartBool(text string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(text)) {
+	case "true", "yes":
+		return true, nil
+	case "false", "no":
+		return false, nil
+	default:
+		return false, fmt.Errorf("%q is not true or false", text)
+	}
+}
+
+// parseSmartDate reads a day (2006-01-02) in loc or an RFC 3339 time. It
+// reports whether the value was an exact time rather than a day.
+func parseSmartDate(text string, loc *time.Location) (time.Time, bool, error) {
+	text = strings.TrimSpace(text)
+	if t, err := time.Parse(time.RFC3339, text); err == nil {
+		return t, true, nil
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	if t, err := time.ParseInLocation("2006-01-02", text, loc); err == nil {
+		return t, false, nil
+	}
+	return time.Time{}, false, fmt.Errorf("%q is not a date such as 2024-01-31", text)
+}
+
+// smartPeriodUnits are the units of a period such as "30d", longest
+// suffix first.
+var smartPeriodUnits = []struct {
+	suffix string
+	length time.Duration
+}{
+	{"mo", 30 * 24 * time.Hour},
+	{"h", time.Hour},
+	{"d", 24 * time.Hour},
+	{"w", 7 * 24 * time.Hour},
+	{"y", 365 * 24 * time.Hour},
+}
+
+// parseSmartPeriod reads a period such as "12h", "30d", "2w", "6mo" or "1y".
+func parseSmartPeriod(text string) (time.Duration, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	for _, unit := range smartPeriodUnits {
+		if number, ok := strings.CutSuffix(text, unit.suffix); ok {
+			n, err := strconv.Atoi(strings.TrimSpace(number))
+			if err != nil || n <= 0 {
+				break
+			}
+			return time.Duration(n) * unit.length, nil
+		}
+	}
+	return 0, fmt.Errorf("%q is not a period such as 12h, 30d, 2w, 6mo or 1y", text)
+}
+
+// SmartLimit caps the tracks of a smart playlist. A zero Count is no limit.
+type SmartLimit struct {
+	// Count is the number of units
+	Count int `json:"count"`
+
+	// Unit is what Count counts
+	Unit SmartLimitUnit `json:"unit"`
+}
+
+// SmartSort orders the tracks of a smart playlist, and with a limit picks
+// which ones are kept.
+type SmartSort struct {
+	// Field is the track field sorted on
+	Field SmartField `json:"field"`
+
+	// Descending puts the highest values first
+	Descending bool `json:"descending,omitempty"`
+}
+
+// SmartRules are the conditions, order and limit of a smart playlist.
+type SmartRules struct {
+	// Match selects whether tracks must pass all rules or any of them
+	Match SmartMatch `json:"match"`
+
+	// Rules are the conditions; no rules match every track
+	Rules []SmartRule `json:"rules"`
+
+	// Limit caps the tracks kept after sorting
+	Limit SmartLimit `json:"limit,omitzero"`
+
+	// Sort orders the tracks; nil keeps library order
+	Sort *SmartSort `json:"sort,omitempty"`
+
+	// LiveUpdating re-evaluates the rules when the library changes
+	LiveUpdating bool `json:"live_updating"`
+}
+
+// Validate checks every rule, the limit and the sort.
+func (s SmartRules) Validate() error {
+	_, err := s.compile(time.Time{})
+	return err
+}
+
+// compile checks the rules and returns their matchers.
+func (s SmartRules) compile(now time.Time) ([]smartMatcher, error) {
+	if !s.Match.IsValid() {
+		return nil, NewDomainError(ErrInvalidSmartRule, "smart playlist match must be all or any")
+	}
+	if s.Limit.Count < 0 || !s.Limit.Unit.IsValid() {
+		return nil, NewDomainError(ErrInvalidSmartRule, "smart playlist limit must be a positive number of items, minutes or hours")
+	}
+	if s.Sort != nil && !s.Sort.Field.IsValid() {
+		return nil, NewDomainError(ErrInvalidSmartRule, "smart playlist sort field is unknown")
+	}
+
+	matchers := make([]smartMatcher, len(s.Rules))
+	for i, rule := range s.Rules {
+		matcher, err := rule.compile(now)
+		if err != nil {
+			return nil, err
+		}
+		matchers[i] = matcher
+	}
+	return matchers, nil
+}
+
+// Evaluate returns the tracks that match the rules, sorted and limited.
+// Periods such as "in the last 30 days" are measured back from now.
+func (s SmartRules) Evaluate(tracks []*Track, now time.Time) ([]*Track, error) {
+	matchers, err := s.compile(now)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make([]*Track, 0, len(tracks))
+	for _, track := range tracks {
+		if track != nil && s.matches(matchers, track) {
+			matched = append(matched, track)
+		}
+	}
+
+	if s.Sort != nil {
+		sortTracks(matched, *s.Sort)
+	}
+	return s.limit(matched), nil
+}
+
+// matches reports whether track passes all or any of the matchers.
+func (s SmartRules) matches(matchers []smartMatcher, track *Track) bool {
+	if len(matchers) == 0 {
+		return true
+	}
+	for _, matcher := range matchers {
+		if matcher(track) == (s.Match == SmartMatchAny) {
+			return s.Match == SmartMatchAny
+		}
+	}
+	return s.Match == SmartMatchAll
+}
+
+// limit keeps the leading tracks that fit the limit. A time limit stops
+// before the first track that would pass it.
+func (s SmartRules) limit(tracks []*Track) []*Track {
+	if s.Limit.Count == 0 {
+		return tracks
+	}
+
+	var budget time.Duration
+	switch s.Limit.Unit {
+	case SmartLimitItems:
+		if len(tracks) > s.Limit.Count {
+			return tracks[:s.Limit.Count]
+		}
+		return tracks
+	case SmartLimitMinutes:
+		budget = time.Duration(s.Limit.Count) * time.Minute
+	default:
+		budget = time.Duration(s.Limit.Count) * time.Hour
+	}
+
+	var total time.Duration
+	for i, track := range tracks {
+		total += track.Duration.ToTime()
+		if total > budget {
+			return tracks[:i]
+		}
+	}
+	return tracks
+}
+
+// sortTracks orders tracks on a field, keeping library order for ties.
+func sortTracks(tracks []*Track, order SmartSort) {
+	field := order.Field
+	less := func(a, b *Track) bool {
+		switch field.valueType() {
+		case smartText:
+			return strings.ToLower(field.text(a)) < strings.ToLower(field.text(b))
+		case smartDate:
+			return field.date(a).Before(field.date(b))
+		default:
+			return field.number(a) < field.number(b)
+		}
+	}
+	sort.SliceStable(tracks, func(i, j int) bool {
+		if order.Descending {
+			return less(tracks[j], tracks[i])
+		}
+		return less(tracks[i], tracks[j])
+	})
+}
+
+// SmartPlaylist is a rule-based playlist owned by maestro. Its rules live
+// with maestro; its tracks are materialized into a regular Music.app
+// playlist of the same name, whose ID is kept once it exists.
+type SmartPlaylist struct {
+	// Name is the playlist name, unique among smart playlists
+	Name string `json:"name"`
+
+	// Rules select the playlist's tracks
+	Rules SmartRules `json:"rules"`
+
+	// PlaylistID is the Music.app playlist holding the tracks, empty until
+	// it is first materialized
+	PlaylistID PlaylistID `json:"playlist_id,omitzero"`
+
+	// MaterializedAt is when the tracks were last written to Music.app
+	MaterializedAt time.Time `json:"materialized_at,omitzero"`
+}
+
+// NewSmartPlaylist creates a smart playlist with validation.
+func NewSmartPlaylist(name string, rules SmartRules) (*SmartPlaylist, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, NewDomainError(ErrInvalidPlaylist, "smart playlist name cannot be empty")
+	}
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	return &SmartPlaylist{Name: name, Rules: rules}, nil
+}
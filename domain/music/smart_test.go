@@ -0,0 +1,292 @@
+package music
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+var smartNow = time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+// smartTracks returns a small library to evaluate rules against.
+func smartTracks(t *testing.T) []*Track {
+	t.Helper()
+	specs := []struct {
+		id, title, artist, genre string
+		seconds, year, plays     int
+		stars                    int
+		loved                    bool
+		lastPlayed               time.Time
+	}{
+		{"1", "So What", "Miles Davis", "Jazz", 562, 1959, 12, 5, true, smartNow.AddDate(0, 0, -2)},
+		{"2", "Blue in Green", "Miles Davis", "Jazz", 337, 1959, 3, 4, false, smartNow.AddDate(0, -2, 0)},
+		{"3", "Blue Train", "John Coltrane", "Jazz", 643, 1957, 8, 0, false, time.Time{}},
+		{"4", "Clair de Lune", "Claude Debussy", "Classical", 300, 1905, 20, 3, true, smartNow.AddDate(0, 0, -10)},
+		{"5", "Take Five", "Dave Brubeck", "jazz", 324, 1959, 0, 0, false, time.Time{}},
+	}
+
+	tracks := make([]*Track, 0, len(specs))
+	for _, spec := range specs {
+		track, err := NewTrackWithMetadata(NewTrackID(spec.id), spec.title, spec.artist, "", NewDuration(spec.seconds),
+			TrackMetadata{
+				Genre:      spec.genre,
+				Year:       spec.year,
+				PlayCount:  spec.plays,
+				Rating:     NewRatingFromStars(spec.stars),
+				Loved:      spec.loved,
+				LastPlayed: spec.lastPlayed,
+				DateAdded:  time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tracks = append(tracks, track)
+	}
+	return tracks
+}
+
+func trackIDs(tracks []*Track) []string {
+	ids := make([]string, len(tracks))
+	for i, track := range tracks {
+		ids[i] = track.ID.Value()
+	}
+	return ids
+}
+
+func TestParseSmartRule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected SmartRule
+	}{
+		{"genre is Jazz", SmartRule{Field: SmartFieldGenre, Operator: SmartOperatorIs, Value: "Jazz"}},
+		{"title contains blue in", SmartRule{Field: SmartFieldTitle, Operator: SmartOperatorContains, Value: "blue in"}},
+		{"year between 1955 and 1960", SmartRule{Field: SmartFieldYear, Operator: SmartOperatorBetween, Value: "1955", To: "1960"}},
+		{"YEAR BETWEEN 1955 1960", SmartRule{Field: SmartFieldYear, Operator: SmartOperatorBetween, Value: "1955", To: "1960"}},
+		{"last_played in_last 30d", SmartRule{Field: SmartFieldLastPlayed, Operator: SmartOperatorInLast, Value: "30d"}},
+		{"loved is true", SmartRule{Field: SmartFieldLoved, Operator: SmartOperatorIs, Value: "true"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			rule, err := ParseSmartRule(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rule != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, rule)
+			}
+			if again, err := ParseSmartRule(rule.String()); err != nil || again != rule {
+				t.Errorf("expected %q to parse back, got %+v (%v)", rule.String(), again, err)
+			}
+		})
+	}
+}
+
+func TestParseSmartRuleInvalid(t *testing.T) {
+	inputs := []string{
+		"genre Jazz",
+		"mood is happy",
+		"genre resembles Jazz",
+		"genre greater_than Jazz",
+		"play_count contains 3",
+		"year is nineteen",
+		"year between 1960 and 1955",
+		"year between 1955",
+		"rating is 120",
+		"loved is maybe",
+		"date_added is yesterday",
+		"last_played in_last 30",
+		"last_played in_last -2d",
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseSmartRule(input)
+			if !errors.Is(err, ErrInvalidSmartRule) {
+				t.Errorf("expected ErrInvalidSmartRule, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSmartRulesEvaluate(t *testing.T) {
+	tests := []struct {
+		name     string
+		match    SmartMatch
+		rules    []string
+		expected []string
+	}{
+		{"no rules", SmartMatchAll, nil, []string{"1", "2", "3", "4", "5"}},
+		{"text ignores case", SmartMatchAll, []string{"genre is jazz"}, []string{"1", "2", "3", "5"}},
+		{"is not", SmartMatchAll, []string{"artist is_not miles davis"}, []string{"3", "4", "5"}},
+		{"starts with", SmartMatchAll, []string{"title starts_with blue"}, []string{"2", "3"}},
+		{"ends with", SmartMatchAll, []string{"title ends_with five"}, []string{"5"}},
+		{"does not contain", SmartMatchAll, []string{"title does_not_contain e"}, []string{"1"}},
+		{"greater than", SmartMatchAll, []string{"play_count greater_than 8"}, []string{"1", "4"}},
+		{"between is inclusive", SmartMatchAll, []string{"year between 1957 and 1959"}, []string{"1", "2", "3", "5"}},
+		{"duration as clock time", SmartMatchAll, []string{"duration less_than 5:30"}, []string{"4", "5"}},
+		{"rating", SmartMatchAll, []string{"rating greater_than 60"}, []string{"1", "2"}},
+		{"bool", SmartMatchAll, []string{"loved is yes"}, []string{"1", "4"}},
+		{"bool is not", SmartMatchAll, []string{"loved is_not true"}, []string{"2", "3", "5"}},
+		{"in last", SmartMatchAll, []string{"last_played in_last 2w"}, []string{"1", "4"}},
+		{"not in last includes never", SmartMatchAll, []string{"last_played not_in_last 1w"}, []string{"2", "3", "4", "5"}},
+		{"date is a whole day", SmartMatchAll, []string{"date_added is 2024-03-01"}, []string{"1", "2", "3", "4", "5"}},
+		{"date before", SmartMatchAll, []string{"last_played less_than 2024-06-01"}, []string{"2"}},
+		{"all", SmartMatchAll, []string{"genre is jazz", "year is 1959"}, []string{"1", "2", "5"}},
+		{"any", SmartMatchAny, []string{"genre is classical", "play_count is 0"}, []string{"4", "5"}},
+	}
+
+	tracks := smartTracks(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := SmartRules{Match: tt.match}
+			for _, text := range tt.rules {
+				rule, err := ParseSmartRule(text)
+				if err != nil {
+					t.Fatal(err)
+				}
+				rules.Rules = append(rules.Rules, rule)
+			}
+
+			matched, err := rules.Evaluate(tracks, smartNow)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := trackIDs(matched); !equalStrings(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSmartRulesSortAndLimit(t *testing.T) {
+	tracks := smartTracks(t)
+	tests := []struct {
+		name     string
+		sort     *SmartSort
+		limit    SmartLimit
+		expected []string
+	}{
+		{"most played", &SmartSort{Field: SmartFieldPlayCount, Descending: true}, SmartLimit{Count: 2}, []string{"4", "1"}},
+		{"stable for ties", &SmartSort{Field: SmartFieldYear}, SmartLimit{}, []string{"4", "3", "1", "2", "5"}},
+		{"text", &SmartSort{Field: SmartFieldTitle}, SmartLimit{Count: 3}, []string{"2", "3", "4"}},
+		{"recently played", &SmartSort{Field: SmartFieldLastPlayed, Descending: true}, SmartLimit{Count: 1}, []string{"1"}},
+		{"minutes stop before overflow", nil, SmartLimit{Count: 14, Unit: SmartLimitMinutes}, []string{"1"}},
+		{"hours", nil, SmartLimit{Count: 1, Unit: SmartLimitHours}, []string{"1", "2", "3", "4", "5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := SmartRules{Sort: tt.sort, Limit: tt.limit}
+			matched, err := rules.Evaluate(tracks, smartNow)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := trackIDs(matched); !equalStrings(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	if got := trackIDs(tracks); !equalStrings(got, []string{"1", "2", "3", "4", "5"}) {
+		t.Errorf("expected the input to keep its order, got %v", got)
+	}
+}
+
+func TestSmartRulesValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules SmartRules
+	}{
+		{"match", SmartRules{Match: SmartMatch(9)}},
+		{"negative limit", SmartRules{Limit: SmartLimit{Count: -1}}},
+		{"limit unit", SmartRules{Limit: SmartLimit{Count: 1, Unit: SmartLimitUnit(9)}}},
+		{"sort field", SmartRules{Sort: &SmartSort{Field: SmartField(99)}}},
+		{"rule", SmartRules{Rules: []SmartRule{{Field: SmartFieldGenre, Operator: SmartOperatorInLast, Value: "1d"}}}},
+		{"stray upper bound", SmartRules{Rules: []SmartRule{{Field: SmartFieldYear, Operator: SmartOperatorIs, Value: "1959", To: "1960"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rules.Validate(); !errors.Is(err, ErrInvalidSmartRule) {
+				t.Errorf("expected ErrInvalidSmartRule, got %v", err)
+			}
+			if _, err := tt.rules.Evaluate(nil, smartNow); !errors.Is(err, ErrInvalidSmartRule) {
+				t.Errorf("expected Evaluate to fail with ErrInvalidSmartRule, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNewSmartPlaylist(t *testing.T) {
+	if _, err := NewSmartPlaylist("  ", SmartRules{}); !errors.Is(err, ErrInvalidPlaylist) {
+		t.Errorf("expected ErrInvalidPlaylist, got %v", err)
+	}
+
+	bad := SmartRules{Rules: []SmartRule{{Field: SmartFieldYear, Operator: SmartOperatorContains, Value: "19"}}}
+	if _, err := NewSmartPlaylist("Bad", bad); !errors.Is(err, ErrInvalidSmartRule) {
+		t.Errorf("expected ErrInvalidSmartRule, got %v", err)
+	}
+
+	playlist, err := NewSmartPlaylist("Jazz", SmartRules{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if playlist.Name != "Jazz" || !playlist.PlaylistID.IsEmpty() || !playlist.MaterializedAt.IsZero() {
+		t.Errorf("unexpected playlist %+v", playlist)
+	}
+}
+
+func TestSmartPlaylistJSON(t *testing.T) {
+	playlist := &SmartPlaylist{
+		Name: "Recent Jazz",
+		Rules: SmartRules{
+			Match: SmartMatchAny,
+			Rules: []SmartRule{
+				{Field: SmartFieldGenre, Operator: SmartOperatorIs, Value: "Jazz"},
+				{Field: SmartFieldYear, Operator: SmartOperatorBetween, Value: "1955", To: "1960"},
+			},
+			Limit:        SmartLimit{Count: 90, Unit: SmartLimitMinutes},
+			Sort:         &SmartSort{Field: SmartFieldPlayCount, Descending: true},
+			LiveUpdating: true,
+		},
+	}
+
+	data, err := json.Marshal(playlist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"name":"Recent Jazz","rules":{"match":"any","rules":[` +
+		`{"field":"genre","operator":"is","value":"Jazz"},` +
+		`{"field":"year","operator":"between","value":"1955","to":"1960"}],` +
+		`"limit":{"count":90,"unit":"minutes"},"sort":{"field":"play_count","descending":true},"live_updating":true}}`
+	if string(data) != expected {
+		t.Errorf("unexpected JSON:\n%s", data)
+	}
+
+	var decoded SmartPlaylist
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Rules.Match != SmartMatchAny || len(decoded.Rules.Rules) != 2 || decoded.Rules.Rules[1].To != "1960" ||
+		decoded.Rules.Sort == nil || decoded.Rules.Limit.Unit != SmartLimitMinutes {
+		t.Errorf("unexpected round trip %+v", decoded)
+	}
+
+	if err := json.Unmarshal([]byte(`{"name":"x","rules":{"match":"some"}}`), &decoded); !errors.Is(err, ErrInvalidSmartRule) {
+		t.Errorf("expected ErrInvalidSmartRule for an unknown match, got %v", err)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
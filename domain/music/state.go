@@ -0,0 +1,183 @@
+package music
+
+import (
+	"fmt"
+	"time"
+)
+
+// PlayerTransition is a change of playback state the player can be asked to
+// make. Which transitions are allowed from which state is fixed by the
+// transition table; see NextPlayerState.
+type PlayerTransition int
+
+const (
+	// PlayerTransitionPlay starts playing a track
+	PlayerTransitionPlay PlayerTransition = iota
+
+	// PlayerTransitionLoad starts loading a track, which buffers until Ready
+	PlayerTransitionLoad
+
+	// PlayerTransitionReady starts playing a loaded track
+	PlayerTransitionReady
+
+	// PlayerTransitionPause pauses playback, keeping the track
+	PlayerTransitionPause
+
+	// PlayerTransitionResume resumes paused playback
+	PlayerTransitionResume
+
+	// PlayerTransitionStop stops playback and unloads the track
+	PlayerTransitionStop
+)
+
+// playerTransitions is the transition table: for each transition, the state
+// it leads to from each state it is allowed in. Transitions that leave the
+// state as it is (pausing while paused) are allowed and change nothing.
+var playerTransitions = map[PlayerTransition]map[PlayerState]PlayerState{
+	PlayerTransitionPlay: {
+		PlayerStateStopped:   PlayerStatePlaying,
+		PlayerStatePlaying:   PlayerStatePlaying,
+		PlayerStatePaused:    PlayerStatePlaying,
+		PlayerStateBuffering: PlayerStatePlaying,
+	},
+	PlayerTransitionLoad: {
+		PlayerStateStopped:   PlayerStateBuffering,
+		PlayerStatePlaying:   PlayerStateBuffering,
+		PlayerStatePaused:    PlayerStateBuffering,
+		PlayerStateBuffering: PlayerStateBuffering,
+	},
+	PlayerTransitionReady: {
+		PlayerStateBuffering: PlayerStatePlaying,
+	},
+	PlayerTransitionPause: {
+		PlayerStatePlaying:   PlayerStatePaused,
+		PlayerStatePaused:    PlayerStatePaused,
+		PlayerStateBuffering: PlayerStatePaused,
+	},
+	PlayerTransitionResume: {
+		PlayerStatePaused:  PlayerStatePlaying,
+		PlayerStatePlaying: PlayerStatePlaying,
+	},
+	PlayerTransitionStop: {
+		PlayerStateStopped:   PlayerStateStopped,
+		PlayerStatePlaying:   PlayerStateStopped,
+		PlayerStatePaused:    PlayerStateStopped,
+		PlayerStateBuffering: PlayerStateStopped,
+	},
+}
+
+// String returns the string representation of the PlayerTransition.
+func (t PlayerTransition) String() string {
+	switch t {
+	case PlayerTransitionPlay:
+		return "play"
+	case PlayerTransitionLoad:
+		return "load"
+	case PlayerTransitionReady:
+		return "ready"
+	case PlayerTransitionPause:
+		return "pause"
+	case PlayerTransitionResume:
+		return "resume"
+	case PlayerTransitionStop:
+		return "stop"
+	default:
+		return "unknown"
+	}
+}
+
+// IsValid returns true if the PlayerTransition is a valid value.
+func (t PlayerTransition) IsValid() bool {
+	return t >= PlayerTransitionPlay && t <= PlayerTransitionStop
+}
+
+// From returns the states the transition is allowed in, in declaration
+// order.
+func (t PlayerTransition) From() []PlayerState {
+	var states []PlayerState
+	for _, state := range PlayerStates() {
+		if _, ok := playerTransitions[t][state]; ok {
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
+// NeedsTrack reports whether the transition loads a track, which must be
+// given.
+func (t PlayerTransition) NeedsTrack() bool {
+	return t == PlayerTransitionPlay || t == PlayerTransitionLoad
+}
+
+// NextPlayerState returns the state transition leads to from state, or an
+// ErrInvalidPlayerState error when the table does not allow it.
+func NextPlayerState(state PlayerState, transition PlayerTransition) (PlayerState, error) {
+	next, ok := playerTransitions[transition][state]
+	if !ok {
+		return state, NewDomainError(ErrInvalidPlayerState,
+			fmt.Sprintf("cannot %s while %s", transition, state)).
+			WithContext("state", state.String()).
+			WithContext("transition", transition.String())
+	}
+	return next, nil
+}
+
+// CanTransition reports whether transition is allowed from the state.
+func (ps PlayerState) CanTransition(transition PlayerTransition) bool {
+	_, ok := playerTransitions[transition][ps]
+	return ok
+}
+
+// Transition applies transition to the player at time at. Play and Load
+// need trackID and start it from the beginning; Stop unloads the track;
+// the others keep it. It returns the event describing the change, or nil
+// when nothing changed, and an ErrInvalidPlayerState error, leaving the
+// player as it was, when the transition is not allowed.
+func (p *Player) Transition(transition PlayerTransition, trackID *TrackID, at time.Time) (*Event, error) {
+	if transition.NeedsTrack() && (trackID == nil || trackID.IsEmpty()) {
+		return nil, NewDomainError(ErrInvalidPlayerState, fmt.Sprintf("cannot %s without a track", transition)).
+			WithContext("transition", transition.String())
+	}
+
+	next, err := NextPlayerState(p.State, transition)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := p.copy()
+	p.State = next
+	switch {
+	case transition.NeedsTrack():
+		id := *trackID
+		p.CurrentTrack = &id
+		p.Position = NewDuration(0)
+	case next == PlayerStateStopped:
+		p.CurrentTrack = nil
+		p.Position = NewDuration(0)
+	}
+
+	var eventType EventType
+	switch {
+	case previous.State != p.State:
+		eventType = EventPlayerStateChanged
+	case !sameTrack(previous.CurrentTrack, p.CurrentTrack):
+		eventType = EventTrackChanged
+	case previous.Position != p.Position:
+		eventType = EventPositionChanged
+	default:
+		return nil, nil
+	}
+
+	p.LastUpdated = at
+	return &Event{Type: eventType, OccurredAt: at, Player: p.copy(), Previous: previous}, nil
+}
+
+// copy returns a copy of the player that shares nothing with it.
+func (p *Player) copy() *Player {
+	copied := *p
+	if p.CurrentTrack != nil {
+		id := *p.CurrentTrack
+		copied.CurrentTrack = &id
+	}
+	return &copied
+}
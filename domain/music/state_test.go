@@ -0,0 +1,119 @@
+package music
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextPlayerState(t *testing.T) {
+	tests := []struct {
+		from       PlayerState
+		transition PlayerTransition
+		expected   PlayerState
+		valid      bool
+	}{
+		{PlayerStateStopped, PlayerTransitionPlay, PlayerStatePlaying, true},
+		{PlayerStateStopped, PlayerTransitionLoad, PlayerStateBuffering, true},
+		{PlayerStateStopped, PlayerTransitionPause, PlayerStateStopped, false},
+		{PlayerStateStopped, PlayerTransitionResume, PlayerStateStopped, false},
+		{PlayerStateStopped, PlayerTransitionReady, PlayerStateStopped, false},
+		{PlayerStateStopped, PlayerTransitionStop, PlayerStateStopped, true},
+		{PlayerStateBuffering, PlayerTransitionReady, PlayerStatePlaying, true},
+		{PlayerStateBuffering, PlayerTransitionPause, PlayerStatePaused, true},
+		{PlayerStateBuffering, PlayerTransitionResume, PlayerStateBuffering, false},
+		{PlayerStatePlaying, PlayerTransitionPause, PlayerStatePaused, true},
+		{PlayerStatePlaying, PlayerTransitionReady, PlayerStatePlaying, false},
+		{PlayerStatePaused, PlayerTransitionResume, PlayerStatePlaying, true},
+		{PlayerStatePaused, PlayerTransitionPause, PlayerStatePaused, true},
+		{PlayerStatePaused, PlayerTransitionStop, PlayerStateStopped, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from.String()+"_"+tt.transition.String(), func(t *testing.T) {
+			next, err := NextPlayerState(tt.from, tt.transition)
+			if tt.valid != (err == nil) {
+				t.Fatalf("expected valid=%v, got %v", tt.valid, err)
+			}
+			if !tt.valid && !IsError(err, ErrInvalidPlayerState) {
+				t.Errorf("expected ErrInvalidPlayerState, got %v", err)
+			}
+			if next != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, next)
+			}
+			if tt.from.CanTransition(tt.transition) != tt.valid {
+				t.Errorf("expected CanTransition to agree with NextPlayerState")
+			}
+		})
+	}
+}
+
+func TestPlayerTransitionFrom(t *testing.T) {
+	from := PlayerTransitionPause.From()
+	if len(from) != 3 || from[0] != PlayerStatePlaying || from[2] != PlayerStateBuffering {
+		t.Errorf("unexpected states for pause %v", from)
+	}
+	if from := PlayerTransitionReady.From(); len(from) != 1 || from[0] != PlayerStateBuffering {
+		t.Errorf("unexpected states for ready %v", from)
+	}
+	for transition := PlayerTransitionPlay; transition.IsValid(); transition++ {
+		if len(transition.From()) == 0 {
+			t.Errorf("transition %s is never allowed", transition)
+		}
+	}
+	if PlayerTransition(99).IsValid() {
+		t.Error("expected transition 99 to be invalid")
+	}
+}
+
+func TestPlayerTransitionEvents(t *testing.T) {
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	player := NewPlayer()
+	first, second := NewTrackID("1"), NewTrackID("2")
+
+	if _, err := player.Transition(PlayerTransitionPlay, nil, at); !IsError(err, ErrInvalidPlayerState) {
+		t.Errorf("expected playing without a track to fail, got %v", err)
+	}
+
+	event, err := player.Transition(PlayerTransitionLoad, &first, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Type != EventPlayerStateChanged || event.Previous.State != PlayerStateStopped || event.Player.State != PlayerStateBuffering {
+		t.Errorf("unexpected load event %+v", event)
+	}
+	if !player.LastUpdated.Equal(at) || !event.OccurredAt.Equal(at) {
+		t.Errorf("expected the transition time to be used, got %v", player.LastUpdated)
+	}
+
+	if event, _ := player.Transition(PlayerTransitionReady, nil, at); event.Player.State != PlayerStatePlaying {
+		t.Errorf("expected playing once ready, got %+v", event)
+	}
+
+	// A different track while playing is a track change
+	event, _ = player.Transition(PlayerTransitionPlay, &second, at)
+	if event.Type != EventTrackChanged || !event.Previous.CurrentTrack.Equals(first) || !event.Player.CurrentTrack.Equals(second) {
+		t.Errorf("unexpected play event %+v", event)
+	}
+
+	// Pausing twice changes nothing the second time
+	if event, _ := player.Transition(PlayerTransitionPause, nil, at); event == nil || event.Type != EventPlayerStateChanged {
+		t.Errorf("unexpected pause event %+v", event)
+	}
+	if event, err := player.Transition(PlayerTransitionPause, nil, at); event != nil || err != nil {
+		t.Errorf("expected no event, got %+v (%v)", event, err)
+	}
+
+	// The event does not share the player
+	event, _ = player.Transition(PlayerTransitionStop, nil, at)
+	if event.Player.CurrentTrack != nil || event.Previous.CurrentTrack == nil || event.Player == player {
+		t.Errorf("unexpected stop event %+v", event)
+	}
+
+	// An illegal transition leaves the player as it was
+	if _, err := player.Transition(PlayerTransitionResume, nil, at); !IsError(err, ErrInvalidPlayerState) {
+		t.Errorf("expected ErrInvalidPlayerState, got %v", err)
+	}
+	if !player.IsStopped() {
+		t.Errorf("expected the player to stay stopped, got %s", player.State)
+	}
+}
@@ -1,7 +1,9 @@
 package music
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -38,6 +40,21 @@ func (t TrackID) String() string {
 	return t.value
 }
 
+// MarshalJSON encodes the TrackID as a JSON string.
+func (t TrackID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.value)
+}
+
+// UnmarshalJSON decodes a JSON string into a TrackID using NewTrackID.
+func (t *TrackID) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return NewDomainErrorWithCause(ErrInvalidTrackID, "track ID must be a string", err)
+	}
+	*t = NewTrackID(value)
+	return nil
+}
+
 // PlaylistID is a value object representing a unique playlist identifier.
 // It provides type safety and validation for playlist identification.
 type PlaylistID struct {
@@ -69,73 +86,98 @@ func (p PlaylistID) String() string {
 	return p.value
 }
 
-// Duration is a value object representing a time duration in seconds.
-// It provides validation and utility methods for working with track durations and positions.
+// MarshalJSON encodes the PlaylistID as a JSON string.
+func (p PlaylistID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.value)
+}
+
+// UnmarshalJSON decodes a JSON string into a PlaylistID using NewPlaylistID.
+func (p *PlaylistID) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return NewDomainErrorWithCause(ErrInvalidPlaylistID, "playlist ID must be a string", err)
+	}
+	*p = NewPlaylistID(value)
+	return nil
+}
+
+// Duration is a value object representing a time duration with millisecond
+// precision. It provides validation and utility methods for working with
+// track durations and positions.
 type Duration struct {
-	seconds int
+	millis int64
 }
 
-// NewDuration creates a new Duration with validation.
+// NewDuration creates a new Duration of whole seconds with validation.
 func NewDuration(seconds int) Duration {
-	if seconds < 0 {
-		seconds = 0
+	return NewDurationFromMillis(int64(seconds) * 1000)
+}
+
+// NewDurationFromMillis creates a new Duration of milliseconds with validation.
+func NewDurationFromMillis(millis int64) Duration {
+	if millis < 0 {
+		millis = 0
 	}
-	return Duration{seconds: seconds}
+	return Duration{millis: millis}
 }
 
-// NewDurationFromTime creates a Duration from a time.Duration.
+// NewDurationFromTime creates a Duration from a time.Duration, dropping
+// anything below a millisecond.
 func NewDurationFromTime(d time.Duration) Duration {
-	return NewDuration(int(d.Seconds()))
+	return NewDurationFromMillis(d.Milliseconds())
 }
 
-// Seconds returns the duration in seconds.
+// Seconds returns the duration in whole seconds (rounded down).
 func (d Duration) Seconds() int {
-	return d.seconds
+	return int(d.millis / 1000)
+}
+
+// Milliseconds returns the duration in milliseconds.
+func (d Duration) Milliseconds() int64 {
+	return d.millis
 }
 
 // Minutes returns the duration in minutes (rounded down).
 func (d Duration) Minutes() int {
-	return d.seconds / 60
+	return d.Seconds() / 60
 }
 
 // Hours returns the duration in hours (rounded down).
 func (d Duration) Hours() int {
-	return d.seconds / 3600
+	return d.Seconds() / 3600
 }
 
 // IsValid returns true if the duration is non-negative.
 func (d Duration) IsValid() bool {
-	return d.seconds >= 0
+	return d.millis >= 0
 }
 
 // IsZero returns true if the duration is zero.
 func (d Duration) IsZero() bool {
-	return d.seconds == 0
+	return d.millis == 0
 }
 
 // Add adds another duration to this one.
 func (d Duration) Add(other Duration) Duration {
-	return NewDuration(d.seconds + other.seconds)
+	return NewDurationFromMillis(d.millis + other.millis)
 }
 
 // Subtract subtracts another duration from this one (minimum 0).
 func (d Duration) Subtract(other Duration) Duration {
-	result := d.seconds - other.seconds
-	if result < 0 {
-		result = 0
-	}
-	return NewDuration(result)
+	return NewDurationFromMillis(d.millis - other.millis)
 }
 
-// String returns a human-readable duration string in format "MM:SS" or "H:MM:SS".
+// String returns a human-readable duration string in format "MM:SS" or
+// "H:MM:SS"; fractions of a second are not shown.
 func (d Duration) String() string {
-	if d.seconds < 0 {
+	if d.millis < 0 {
 		return "0:00"
 	}
 
-	hours := d.seconds / 3600
-	minutes := (d.seconds % 3600) / 60
-	seconds := d.seconds % 60
+	total := d.Seconds()
+	hours := total / 3600
+	minutes := (total % 3600) / 60
+	seconds := total % 60
 
 	if hours > 0 {
 		return fmt.Sprintf("%d:%02d:%02d", hours, minutes, seconds)
@@ -145,9 +187,105 @@ func (d Duration) String() string {
 
 // ToTime converts the Duration to a time.Duration.
 func (d Duration) ToTime() time.Duration {
-	return time.Duration(d.seconds) * time.Second
+	return time.Duration(d.millis) * time.Millisecond
+}
+
+// ParseDuration parses a duration written as seconds ("90"), as a clock time
+// ("1:30", "1:02:03") or with units ("90s", "2m", "1h2m3s"). A leading "+"
+// or "-" makes it relative to some reference position: the sign is returned
+// as 1 or -1, and as 0 for an absolute duration. Fractions of a second are
+// kept to the millisecond, except in clock times.
+func ParseDuration(s string) (Duration, int, error) {
+	text := strings.TrimSpace(s)
+	sign := 0
+	if strings.HasPrefix(text, "+") || strings.HasPrefix(text, "-") {
+		sign = 1
+		if text[0] == '-' {
+			sign = -1
+		}
+		text = text[1:]
+	}
+
+	invalid := func(reason string) error {
+		return NewDomainError(ErrInvalidPosition, fmt.Sprintf("invalid duration %q: %s", s, reason)).
+			WithContext("input", s)
+	}
+
+	if text == "" || strings.ContainsAny(text, "+- ") {
+		return Duration{}, 0, invalid(`expected a form such as "90", "1:30", "90s" or "+15s"`)
+	}
+
+	if strings.Contains(text, ":") {
+		parts := strings.Split(text, ":")
+		if len(parts) > 3 {
+			return Duration{}, 0, invalid("too many ':' separators")
+		}
+		seconds := 0
+		for i, part := range parts {
+			n, err := strconv.Atoi(part)
+			if err != nil || n < 0 {
+				return Duration{}, 0, invalid(fmt.Sprintf("%q is not a whole number", part))
+			}
+			if i > 0 && (len(part) != 2 || n > 59) {
+				return Duration{}, 0, invalid("minutes and seconds must be two digits from 00 to 59")
+			}
+			seconds = seconds*60 + n
+		}
+		return NewDuration(seconds), sign, nil
+	}
+
+	if n, err := strconv.Atoi(text); err == nil {
+		return NewDuration(n), sign, nil
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil && f >= 0 && !math.IsInf(f, 0) {
+		return secondsDuration(f), sign, nil
+	}
+
+	d, err := time.ParseDuration(text)
+	if err != nil {
+		return Duration{}, 0, invalid(`expected a form such as "90", "1:30", "90s" or "+15s"`)
+	}
+	return NewDurationFromTime(d), sign, nil
+}
+
+// MarshalJSON encodes the Duration as a number of seconds: a whole number
+// unless it has a fraction of a second, so that whole-second values read
+// as before.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	if d.millis%1000 == 0 {
+		return json.Marshal(d.millis / 1000)
+	}
+	return []byte(strconv.FormatFloat(float64(d.millis)/1000, 'f', -1, 64)), nil
+}
+
+// UnmarshalJSON decodes a number of seconds, whole or fractional, into a
+// Duration. Negative values are rejected rather than clamped.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return NewDomainErrorWithCause(ErrInvalidPosition, "duration must be a number of seconds", err)
+	}
+	if seconds < 0 {
+		return NewDomainError(ErrInvalidPosition, "duration cannot be negative").WithContext("seconds", seconds)
+	}
+	*d = secondsDuration(seconds)
+	return nil
 }
 
+// secondsDuration converts fractional seconds into a Duration, rounding to
+// the nearest millisecond.
+func secondsDuration(seconds float64) Duration {
+	return NewDurationFromMillis(int64(math.Round(seconds * 1000)))
+}
+
+const (
+	// MinVolumeLevel is the lowest volume level (muted)
+	MinVolumeLevel = 0
+
+	// MaxVolumeLevel is the highest volume level
+	MaxVolumeLevel = 100
+)
+
 // Volume is a value object representing audio volume from 0 to 100.
 // It provides validation and ensures volume stays within valid bounds.
 type Volume struct {
@@ -156,10 +294,10 @@ type Volume struct {
 
 // NewVolume creates a new Volume with validation (0-100).
 func NewVolume(level int) Volume {
-	if level < 0 {
-		level = 0
-	} else if level > 100 {
-		level = 100
+	if level < MinVolumeLevel {
+		level = MinVolumeLevel
+	} else if level > MaxVolumeLevel {
+		level = MaxVolumeLevel
 	}
 	return Volume{level: level}
 }
@@ -171,7 +309,7 @@ func (v Volume) Level() int {
 
 // IsValid returns true if the volume is between 0 and 100 inclusive.
 func (v Volume) IsValid() bool {
-	return v.level >= 0 && v.level <= 100
+	return v.level >= MinVolumeLevel && v.level <= MaxVolumeLevel
 }
 
 // IsMuted returns true if the volume is 0.
@@ -204,6 +342,182 @@ func (v Volume) Percentage() float64 {
 	return float64(v.level) / 100.0
 }
 
+// MarshalJSON encodes the Volume as its integer level.
+func (v Volume) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.level)
+}
+
+// UnmarshalJSON decodes an integer level into a Volume.
+// Unlike NewVolume, out-of-range levels are rejected instead of clamped.
+func (v *Volume) UnmarshalJSON(data []byte) error {
+	var level int
+	if err := json.Unmarshal(data, &level); err != nil {
+		return NewDomainErrorWithCause(ErrInvalidVolume, "volume must be a whole number between 0 and 100", err)
+	}
+	if level < MinVolumeLevel || level > MaxVolumeLevel {
+		return WrapInvalidVolume(level, nil)
+	}
+	*v = NewVolume(level)
+	return nil
+}
+
+const (
+	// MinRating is an unrated track
+	MinRating = 0
+
+	// MaxRating is a five-star track
+	MaxRating = 100
+
+	// RatingPerStar is the rating of one star
+	RatingPerStar = 20
+)
+
+// Rating is a value object representing a track rating from 0 (unrated)
+// to 100, 20 per star, as Music.app stores it.
+type Rating struct {
+	value int
+}
+
+// NewRating creates a new Rating with validation (0-100).
+func NewRating(value int) Rating {
+	if value < MinRating {
+		value = MinRating
+	} else if value > MaxRating {
+		value = MaxRating
+	}
+	return Rating{value: value}
+}
+
+// NewRatingFromStars creates a Rating from a number of stars (0-5).
+func NewRatingFromStars(stars int) Rating {
+	return NewRating(stars * RatingPerStar)
+}
+
+// Value returns the rating (0-100).
+func (r Rating) Value() int {
+	return r.value
+}
+
+// Stars returns the number of whole stars (0-5).
+func (r Rating) Stars() int {
+	return r.value / RatingPerStar
+}
+
+// IsValid returns true if the rating is between 0 and 100 inclusive.
+func (r Rating) IsValid() bool {
+	return r.value >= MinRating && r.value <= MaxRating
+}
+
+// IsZero returns true if the track is unrated.
+func (r Rating) IsZero() bool {
+	return r.value == 0
+}
+
+// String returns the rating as stars, e.g. "★★★☆☆".
+func (r Rating) String() string {
+	if r.IsZero() {
+		return "unrated"
+	}
+	stars := r.Stars()
+	return strings.Repeat("★", stars) + strings.Repeat("☆", MaxRating/RatingPerStar-stars)
+}
+
+// MarshalJSON encodes the Rating as its value.
+func (r Rating) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.value)
+}
+
+// UnmarshalJSON decodes a value into a Rating.
+// Unlike NewRating, out-of-range values are rejected instead of clamped.
+func (r *Rating) UnmarshalJSON(data []byte) error {
+	var value int
+	if err := json.Unmarshal(data, &value); err != nil {
+		return NewDomainErrorWithCause(ErrInvalidRating, "rating must be a whole number between 0 and 100", err)
+	}
+	if value < MinRating || value > MaxRating {
+		return WrapInvalidRating(value, nil)
+	}
+	*r = NewRating(value)
+	return nil
+}
+
+// ParseStars converts a number of whole stars such as "4" into a Rating.
+// Unlike NewRatingFromStars, out-of-range counts are rejected.
+func ParseStars(s string) (Rating, error) {
+	stars, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return Rating{}, NewDomainErrorWithCause(ErrInvalidRating, fmt.Sprintf("rating %q is not a number of stars from 0 to 5", s), err)
+	}
+	if stars < 0 || stars > MaxRating/RatingPerStar {
+		return Rating{}, WrapInvalidRating(stars*RatingPerStar, nil).WithContext("stars", stars)
+	}
+	return NewRatingFromStars(stars), nil
+}
+
+// Preference is whether the user loved or disliked a track. Music.app
+// keeps the two flags apart, but never sets both.
+type Preference int
+
+const (
+	// PreferenceNone indicates the track is neither loved nor disliked
+	PreferenceNone Preference = iota
+
+	// PreferenceLoved indicates the user loved the track
+	PreferenceLoved
+
+	// PreferenceDisliked indicates the user disliked the track
+	PreferenceDisliked
+)
+
+// String returns the string representation of the Preference.
+func (p Preference) String() string {
+	switch p {
+	case PreferenceNone:
+		return "none"
+	case PreferenceLoved:
+		return "loved"
+	case PreferenceDisliked:
+		return "disliked"
+	default:
+		return "unknown"
+	}
+}
+
+// IsValid returns true if the Preference is a valid value.
+func (p Preference) IsValid() bool {
+	return p >= PreferenceNone && p <= PreferenceDisliked
+}
+
+// Preferences returns every valid Preference in declaration order.
+func Preferences() []Preference {
+	return []Preference{PreferenceNone, PreferenceLoved, PreferenceDisliked}
+}
+
+// ParsePreference converts a name such as "loved" into a Preference.
+func ParsePreference(name string) (Preference, error) {
+	for _, preference := range Preferences() {
+		if strings.EqualFold(strings.TrimSpace(name), preference.String()) {
+			return preference, nil
+		}
+	}
+	return PreferenceNone, NewDomainError(ErrInvalidRating, fmt.Sprintf("unknown preference %q (expected none, loved or disliked)", name))
+}
+
+// MarshalText encodes the Preference as its name.
+func (p Preference) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText decodes a Preference from its name.
+func (p *Preference) UnmarshalText(text []byte) error {
+	preference, err := ParsePreference(string(text))
+	if err != nil {
+		return err
+	}
+	*p = preference
+	return nil
+}
+
 // PlayerState represents the current state of the music player.
 type PlayerState int
 
@@ -242,6 +556,36 @@ func (ps PlayerState) IsValid() bool {
 	return ps >= PlayerStateStopped && ps <= PlayerStateBuffering
 }
 
+// PlayerStates returns every valid PlayerState in declaration order.
+func PlayerStates() []PlayerState {
+	return []PlayerState{PlayerStateStopped, PlayerStatePlaying, PlayerStatePaused, PlayerStateBuffering}
+}
+
+// ParsePlayerState converts a name such as "playing" into a PlayerState.
+func ParsePlayerState(name string) (PlayerState, error) {
+	for _, state := range PlayerStates() {
+		if strings.EqualFold(strings.TrimSpace(name), state.String()) {
+			return state, nil
+		}
+	}
+	return PlayerStateStopped, NewDomainError(ErrInvalidPlayerState, fmt.Sprintf("unknown player state %q", name))
+}
+
+// MarshalText encodes the PlayerState as its name.
+func (ps PlayerState) MarshalText() ([]byte, error) {
+	return []byte(ps.String()), nil
+}
+
+// UnmarshalText decodes a PlayerState from its name.
+func (ps *PlayerState) UnmarshalText(text []byte) error {
+	state, err := ParsePlayerState(string(text))
+	if err != nil {
+		return err
+	}
+	*ps = state
+	return nil
+}
+
 // RepeatMode represents the repeat behavior of the player.
 type RepeatMode int
 
@@ -275,6 +619,36 @@ func (rm RepeatMode) IsValid() bool {
 	return rm >= RepeatModeOff && rm <= RepeatModeOne
 }
 
+// RepeatModes returns every valid RepeatMode in declaration order.
+func RepeatModes() []RepeatMode {
+	return []RepeatMode{RepeatModeOff, RepeatModeAll, RepeatModeOne}
+}
+
+// ParseRepeatMode converts a name such as "all" into a RepeatMode.
+func ParseRepeatMode(name string) (RepeatMode, error) {
+	for _, mode := range RepeatModes() {
+		if strings.EqualFold(strings.TrimSpace(name), mode.String()) {
+			return mode, nil
+		}
+	}
+	return RepeatModeOff, NewDomainError(ErrInvalidRepeatMode, fmt.Sprintf("unknown repeat mode %q (expected off, all or one)", name))
+}
+
+// MarshalText encodes the RepeatMode as its name.
+func (rm RepeatMode) MarshalText() ([]byte, error) {
+	return []byte(rm.String()), nil
+}
+
+// UnmarshalText decodes a RepeatMode from its name.
+func (rm *RepeatMode) UnmarshalText(text []byte) error {
+	mode, err := ParseRepeatMode(string(text))
+	if err != nil {
+		return err
+	}
+	*rm = mode
+	return nil
+}
+
 // PlaylistType represents the type of playlist.
 type PlaylistType int
 
@@ -323,6 +697,43 @@ func (pt PlaylistType) IsValid() bool {
 	return pt >= PlaylistTypeUser && pt <= PlaylistTypeRecentlyAdded
 }
 
+// PlaylistTypes returns every valid PlaylistType in declaration order.
+func PlaylistTypes() []PlaylistType {
+	return []PlaylistType{
+		PlaylistTypeUser,
+		PlaylistTypeSmart,
+		PlaylistTypeLibrary,
+		PlaylistTypeQueue,
+		PlaylistTypeRecentlyPlayed,
+		PlaylistTypeRecentlyAdded,
+	}
+}
+
+// ParsePlaylistType converts a name such as "smart" into a PlaylistType.
+func ParsePlaylistType(name string) (PlaylistType, error) {
+	for _, pt := range PlaylistTypes() {
+		if strings.EqualFold(strings.TrimSpace(name), pt.String()) {
+			return pt, nil
+		}
+	}
+	return PlaylistTypeUser, NewDomainError(ErrInvalidPlaylist, fmt.Sprintf("unknown playlist type %q", name))
+}
+
+// MarshalText encodes the PlaylistType as its name.
+func (pt PlaylistType) MarshalText() ([]byte, error) {
+	return []byte(pt.String()), nil
+}
+
+// UnmarshalText decodes a PlaylistType from its name.
+func (pt *PlaylistType) UnmarshalText(text []byte) error {
+	parsed, err := ParsePlaylistType(string(text))
+	if err != nil {
+		return err
+	}
+	*pt = parsed
+	return nil
+}
+
 // IsReadOnly returns true if this playlist type should be read-only.
 func (pt PlaylistType) IsReadOnly() bool {
 	switch pt {
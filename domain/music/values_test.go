@@ -1,6 +1,9 @@
 package music
 
 import (
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -208,6 +211,46 @@ func TestDurationString(t *testing.T) {
 	}
 }
 
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input  string
+		millis int64
+		sign   int
+	}{
+		{"90", 90000, 0},
+		{"90.5", 90500, 0},
+		{"1:30", 90000, 0},
+		{"1:02:03", 3723000, 0},
+		{"0:05", 5000, 0},
+		{"90s", 90000, 0},
+		{"2m", 120000, 0},
+		{"1h2m3s", 3723000, 0},
+		{"1.5s", 1500, 0},
+		{"250ms", 250, 0},
+		{"+15s", 15000, 1},
+		{"-10", 10000, -1},
+		{" -1:00 ", 60000, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			duration, sign, err := ParseDuration(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if duration.Milliseconds() != tt.millis || sign != tt.sign {
+				t.Errorf("expected %dms with sign %d, got %dms with sign %d", tt.millis, tt.sign, duration.Milliseconds(), sign)
+			}
+		})
+	}
+
+	for _, input := range []string{"", "+", "abc", "1:3", "1:60", "1:2:3:4", "--5", "1:-30", "5x"} {
+		if _, _, err := ParseDuration(input); !errors.Is(err, ErrInvalidPosition) {
+			t.Errorf("ParseDuration(%q): expected ErrInvalidPosition, got %v", input, err)
+		}
+	}
+}
+
 func TestDurationToTime(t *testing.T) {
 	duration := NewDuration(180)
 	timeDuration := duration.ToTime()
@@ -217,6 +260,56 @@ func TestDurationToTime(t *testing.T) {
 	}
 }
 
+func TestDurationMilliseconds(t *testing.T) {
+	duration := NewDurationFromMillis(90750)
+	if duration.Seconds() != 90 || duration.Milliseconds() != 90750 {
+		t.Errorf("expected 90s and 90750ms, got %ds and %dms", duration.Seconds(), duration.Milliseconds())
+	}
+	if duration.String() != "1:30" {
+		t.Errorf("expected fractions to be dropped from the string, got %s", duration)
+	}
+	if duration.ToTime() != 90750*time.Millisecond {
+		t.Errorf("expected 90.75s, got %v", duration.ToTime())
+	}
+	if got := NewDurationFromTime(1500 * time.Millisecond); got.Milliseconds() != 1500 {
+		t.Errorf("expected 1500ms, got %dms", got.Milliseconds())
+	}
+	if got := NewDurationFromMillis(-1); got.Milliseconds() != 0 {
+		t.Errorf("expected a negative duration to be clamped, got %dms", got.Milliseconds())
+	}
+}
+
+func TestDurationJSON(t *testing.T) {
+	tests := []struct {
+		millis int64
+		json   string
+	}{
+		{90000, `90`},
+		{1500, `1.5`},
+		{250, `0.25`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			data, err := json.Marshal(NewDurationFromMillis(tt.millis))
+			if err != nil {
+				t.Fatalf("unexpected marshal error: %v", err)
+			}
+			if string(data) != tt.json {
+				t.Errorf("expected %s, got %s", tt.json, data)
+			}
+
+			var duration Duration
+			if err := json.Unmarshal(data, &duration); err != nil {
+				t.Fatalf("unexpected unmarshal error: %v", err)
+			}
+			if duration.Milliseconds() != tt.millis {
+				t.Errorf("expected %dms after the round trip, got %dms", tt.millis, duration.Milliseconds())
+			}
+		})
+	}
+}
+
 func TestNewVolume(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -240,6 +333,50 @@ func TestNewVolume(t *testing.T) {
 	}
 }
 
+func TestRating(t *testing.T) {
+	tests := []struct {
+		input  int
+		value  int
+		stars  int
+		string string
+	}{
+		{0, 0, 0, "unrated"},
+		{60, 60, 3, "★★★☆☆"},
+		{50, 50, 2, "★★☆☆☆"},
+		{100, 100, 5, "★★★★★"},
+		{-5, 0, 0, "unrated"},
+		{150, 100, 5, "★★★★★"},
+	}
+	for _, tt := range tests {
+		rating := NewRating(tt.input)
+		if rating.Value() != tt.value || rating.Stars() != tt.stars || rating.String() != tt.string {
+			t.Errorf("NewRating(%d): expected %d, %d stars, %q; got %d, %d stars, %q",
+				tt.input, tt.value, tt.stars, tt.string, rating.Value(), rating.Stars(), rating.String())
+		}
+	}
+
+	if NewRatingFromStars(4).Value() != 80 {
+		t.Errorf("expected 4 stars to be 80, got %d", NewRatingFromStars(4).Value())
+	}
+
+	var rating Rating
+	if err := json.Unmarshal([]byte("80"), &rating); err != nil || rating.Stars() != 4 {
+		t.Errorf("expected 4 stars, got %d (%v)", rating.Stars(), err)
+	}
+	if err := json.Unmarshal([]byte("101"), &rating); !errors.Is(err, ErrInvalidRating) {
+		t.Errorf("expected ErrInvalidRating, got %v", err)
+	}
+
+	if rating, err := ParseStars(" 4 "); err != nil || rating.Value() != 80 {
+		t.Errorf("expected 4 stars to parse as 80, got %d (%v)", rating.Value(), err)
+	}
+	for _, input := range []string{"6", "-1", "four", ""} {
+		if _, err := ParseStars(input); !errors.Is(err, ErrInvalidRating) {
+			t.Errorf("ParseStars(%q): expected ErrInvalidRating, got %v", input, err)
+		}
+	}
+}
+
 func TestVolumeValidation(t *testing.T) {
 	validVolume := NewVolume(50)
 	if !validVolume.IsValid() {
@@ -417,3 +554,154 @@ func TestPlaylistTypeEnum(t *testing.T) {
 		}
 	}
 }
+
+func TestValueJSONRoundTrip(t *testing.T) {
+	type payload struct {
+		Track    TrackID      `json:"track"`
+		Playlist PlaylistID   `json:"playlist"`
+		Position Duration     `json:"position"`
+		Volume   Volume       `json:"volume"`
+		State    PlayerState  `json:"state"`
+		Repeat   RepeatMode   `json:"repeat"`
+		Type     PlaylistType `json:"type"`
+	}
+
+	in := payload{
+		Track:    NewTrackID("42"),
+		Playlist: NewPlaylistID("ABCDEF"),
+		Position: NewDuration(90),
+		Volume:   NewVolume(65),
+		State:    PlayerStatePaused,
+		Repeat:   RepeatModeAll,
+		Type:     PlaylistTypeSmart,
+	}
+
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("unexpected marshal error: %v", err)
+	}
+
+	expected := `{"track":"42","playlist":"ABCDEF","position":90,"volume":65,"state":"paused","repeat":"all","type":"smart"}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+
+	var out payload
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("unexpected unmarshal error: %v", err)
+	}
+
+	if out != in {
+		t.Errorf("expected round trip to preserve %+v, got %+v", in, out)
+	}
+}
+
+func TestValueJSONValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		target    interface{}
+		errorType error
+	}{
+		{"volume above maximum", `101`, new(Volume), ErrInvalidVolume},
+		{"volume below minimum", `-1`, new(Volume), ErrInvalidVolume},
+		{"volume not a number", `"loud"`, new(Volume), ErrInvalidVolume},
+		{"negative duration", `-5`, new(Duration), ErrInvalidPosition},
+		{"track ID not a string", `42`, new(TrackID), ErrInvalidTrackID},
+		{"unknown repeat mode", `"sometimes"`, new(RepeatMode), ErrInvalidRepeatMode},
+		{"unknown player state", `"dancing"`, new(PlayerState), ErrInvalidPlayerState},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := json.Unmarshal([]byte(tt.input), tt.target)
+			if err == nil {
+				t.Fatal("expected error but got none")
+			}
+			if !errors.Is(err, tt.errorType) {
+				t.Errorf("expected error type %v, got %v", tt.errorType, err)
+			}
+		})
+	}
+}
+
+func TestTrackIDUnmarshalTrims(t *testing.T) {
+	var id TrackID
+	if err := json.Unmarshal([]byte(`"  track-1 "`), &id); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id.Value() != "track-1" {
+		t.Errorf("expected trimmed value 'track-1', got '%s'", id.Value())
+	}
+}
+
+func TestParseEnums(t *testing.T) {
+	for _, mode := range RepeatModes() {
+		parsed, err := ParseRepeatMode(strings.ToUpper(mode.String()))
+		if err != nil || parsed != mode {
+			t.Errorf("expected %v to round trip, got %v (%v)", mode, parsed, err)
+		}
+	}
+
+	for _, state := range PlayerStates() {
+		parsed, err := ParsePlayerState(state.String())
+		if err != nil || parsed != state {
+			t.Errorf("expected %v to round trip, got %v (%v)", state, parsed, err)
+		}
+	}
+
+	for _, pt := range PlaylistTypes() {
+		parsed, err := ParsePlaylistType(pt.String())
+		if err != nil || parsed != pt {
+			t.Errorf("expected %v to round trip, got %v (%v)", pt, parsed, err)
+		}
+	}
+
+	for _, preference := range Preferences() {
+		parsed, err := ParsePreference(preference.String())
+		if err != nil || parsed != preference {
+			t.Errorf("expected %v to round trip, got %v (%v)", preference, parsed, err)
+		}
+	}
+	if _, err := ParsePreference("adored"); !errors.Is(err, ErrInvalidRating) {
+		t.Errorf("expected ErrInvalidRating for unknown preference, got %v", err)
+	}
+
+	if _, err := ParsePlaylistType("folder"); !errors.Is(err, ErrInvalidPlaylist) {
+		t.Errorf("expected ErrInvalidPlaylist for unknown type, got %v", err)
+	}
+}
+
+func TestVolumeCurveInterpolate(t *testing.T) {
+	tests := []struct {
+		name     string
+		curve    VolumeCurve
+		from, to int
+		progress float64
+		expected int
+	}{
+		{"linear start", VolumeCurveLinear, 80, 20, 0, 80},
+		{"linear middle", VolumeCurveLinear, 80, 20, 0.5, 50},
+		{"linear end", VolumeCurveLinear, 80, 20, 1, 20},
+		{"linear past end", VolumeCurveLinear, 80, 20, 2, 20},
+		{"log fade out middle", VolumeCurveLog, 99, 0, 0.5, 9},
+		{"log fade in middle", VolumeCurveLog, 0, 99, 0.5, 9},
+		{"log end", VolumeCurveLog, 0, 99, 1, 99},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.curve.Interpolate(NewVolume(tt.from), NewVolume(tt.to), tt.progress)
+			if got.Level() != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, got.Level())
+			}
+		})
+	}
+
+	if curve, err := ParseVolumeCurve("LOG"); err != nil || curve != VolumeCurveLog {
+		t.Errorf("expected log, got %v, %v", curve, err)
+	}
+	if _, err := ParseVolumeCurve("cubic"); !errors.Is(err, ErrInvalidVolume) {
+		t.Errorf("expected ErrInvalidVolume, got %v", err)
+	}
+}
@@ -19,6 +19,37 @@ func NewDefaultPlayerRepository() music.PlayerRepository {
 	return NewPlayerRepository(executor)
 }
 
+// Repositories composes every AppleScript-backed repository so that a single
+// value satisfies music.RepositoryManager.
+type Repositories struct {
+	*PlayerRepository
+	*LibraryRepository
+	*QueueRepository
+	*PlaylistRepository
+	*RatingRepository
+}
+
+// NewRepositories creates all AppleScript repositories sharing one executor.
+func NewRepositories(executor *Executor) *Repositories {
+	if executor == nil {
+		executor = NewExecutor(nil)
+	}
+
+	return &Repositories{
+		PlayerRepository:   NewPlayerRepository(executor),
+		LibraryRepository:  NewLibraryRepository(executor),
+		QueueRepository:    NewQueueRepository(executor),
+		PlaylistRepository: NewPlaylistRepository(executor),
+		RatingRepository:   NewRatingRepository(executor),
+	}
+}
+
+// Compile-time check that Repositories implements the full domain port.
+var _ music.RepositoryManager = (*Repositories)(nil)
+
+// Compile-time check that the player runs batched commands in one script.
+var _ music.PlayerBatcher = (*PlayerRepository)(nil)
+
 // NewPlayerRepositoryWithConfig creates a PlayerRepository with custom configuration.
 func NewPlayerRepositoryWithConfig(config *ExecutorConfig) music.PlayerRepository {
 	executor := NewExecutor(config)
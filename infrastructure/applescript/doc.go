@@ -10,6 +10,12 @@
 //
 //   - Executor: Handles AppleScript execution with timeout and retry logic
 //   - PlayerRepository: Implements music.PlayerRepository for playback control
+//   - LibraryRepository: Implements music.LibraryRepository for search and browsing
+//   - QueueRepository: Implements music.QueueRepository on a "Maestro Queue" playlist
+//   - PlaylistRepository: Implements music.PlaylistRepository for user playlists
+//   - RatingRepository: Implements music.RatingRepository for ratings and loved tracks
+//   - Repositories: Composes all of the above into a music.RepositoryManager
+//   - Poller: Implements music.EventSource by polling the player and queue
 //   - Script Templates: Reusable AppleScript files for common operations
 //
 // # Usage
@@ -9,9 +9,11 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/pkg/config"
 )
 
 // ExecutorConfig holds configuration for the AppleScript executor.
@@ -39,10 +41,34 @@ func DefaultExecutorConfig() *ExecutorConfig {
 	}
 }
 
+// ExecutorConfigFrom returns the executor configuration from the executor
+// and retry sections of a loaded configuration.
+func ExecutorConfigFrom(settings *config.Config) *ExecutorConfig {
+	return &ExecutorConfig{
+		ExecPath:       settings.Executor.ExecPath,
+		DefaultTimeout: settings.Executor.Timeout.Std(),
+		MaxRetries:     settings.Retry.MaxRetries,
+		RetryDelay:     settings.Retry.Delay.Std(),
+	}
+}
+
+// Observer receives the outcome of every script execution, for metrics.
+type Observer interface {
+	// ObserveExecution is called once per execution, after any retries.
+	// script names the function that ran it, e.g. "PlayerRepository.Pause".
+	ObserveExecution(script string, result *ExecuteResult)
+}
+
+// observerBox lets an Observer interface value live in an atomic.Pointer.
+type observerBox struct {
+	Observer
+}
+
 // Executor provides a wrapper around maestro-exec for executing AppleScript commands.
 // It handles timeouts, retries, and error processing.
 type Executor struct {
-	config *ExecutorConfig
+	config   atomic.Pointer[ExecutorConfig]
+	observer atomic.Pointer[observerBox]
 }
 
 // NewExecutor creates a new AppleScript executor with the provided configuration.
@@ -51,9 +77,32 @@ func NewExecutor(config *ExecutorConfig) *Executor {
 		config = DefaultExecutorConfig()
 	}
 
-	return &Executor{
-		config: config,
+	e := &Executor{}
+	e.config.Store(config)
+	return e
+}
+
+// Config returns the configuration in effect.
+func (e *Executor) Config() *ExecutorConfig {
+	return e.config.Load()
+}
+
+// SetConfig replaces the configuration. Executions already in progress keep
+// the configuration they started with.
+func (e *Executor) SetConfig(config *ExecutorConfig) {
+	if config == nil {
+		config = DefaultExecutorConfig()
+	}
+	e.config.Store(config)
+}
+
+// SetObserver sets the observer told about every execution (nil for none).
+func (e *Executor) SetObserver(observer Observer) {
+	if observer == nil {
+		e.observer.Store(nil)
+		return
 	}
+	e.observer.Store(&observerBox{observer})
 }
 
 // ExecuteResult contains the result of AppleScript execution.
@@ -69,11 +118,21 @@ type ExecuteResult struct {
 
 	// RetryCount is how many retry attempts were made
 	RetryCount int
+
+	// ExitCode is the exit code of the last maestro-exec run (-1 when it
+	// did not run to completion)
+	ExitCode int
+
+	// Timeouts is how many attempts hit the execution timeout
+	Timeouts int
+
+	// timedOut reports whether this attempt hit the execution timeout
+	timedOut bool
 }
 
 // Execute runs an AppleScript string with the default timeout and retry logic.
 func (e *Executor) Execute(ctx context.Context, script string) *ExecuteResult {
-	return e.ExecuteWithTimeout(ctx, script, e.config.DefaultTimeout)
+	return e.ExecuteWithTimeout(ctx, script, e.Config().DefaultTimeout)
 }
 
 // ExecuteWithTimeout runs an AppleScript string with a specific timeout and retry logic.
@@ -81,63 +140,103 @@ func (e *Executor) ExecuteWithTimeout(ctx context.Context, script string, timeou
 	script = strings.TrimSpace(script)
 	if script == "" {
 		return &ExecuteResult{
-			Error: music.NewDomainError(music.ErrInvalidOperation, "AppleScript cannot be empty"),
+			Error:    music.NewDomainError(music.ErrInvalidOperation, "AppleScript cannot be empty"),
+			ExitCode: -1,
 		}
 	}
 
-	var lastErr error
+	config := e.Config()
+	var lastResult *ExecuteResult
+	timeouts := 0
 	startTime := time.Now()
 
-	for attempt := 0; attempt <= e.config.MaxRetries; attempt++ {
+	finish := func(result *ExecuteResult) *ExecuteResult {
+		result.Duration = time.Since(startTime)
+		result.Timeouts = timeouts
+		if box := e.observer.Load(); box != nil {
+			box.ObserveExecution(callerName(), result)
+		}
+		return result
+	}
+
+	for attempt := 0; attempt <= config.MaxRetries; attempt++ {
 		if attempt > 0 {
 			// Wait before retrying
 			select {
 			case <-ctx.Done():
-				return &ExecuteResult{
+				return finish(&ExecuteResult{
 					Error:      music.NewDomainErrorWithCause(music.ErrTimeout, "context cancelled during retry", ctx.Err()),
-					Duration:   time.Since(startTime),
 					RetryCount: attempt,
-				}
-			case <-time.After(e.config.RetryDelay):
+					ExitCode:   lastResult.ExitCode,
+				})
+			case <-time.After(config.RetryDelay):
 				// Continue to retry
 			}
 		}
 
-		result := e.executeOnce(ctx, script, timeout)
+		result := e.executeOnce(ctx, config.ExecPath, script, timeout)
 		result.RetryCount = attempt
+		if result.timedOut {
+			timeouts++
+		}
 
 		// If successful, return immediately
 		if result.Error == nil {
-			result.Duration = time.Since(startTime)
-			return result
+			return finish(result)
 		}
 
-		lastErr = result.Error
+		lastResult = result
 
 		// Check if this is a permanent error that shouldn't be retried
-		if music.IsPermanent(lastErr) {
-			result.Duration = time.Since(startTime)
-			return result
+		if music.IsPermanent(result.Error) {
+			return finish(result)
 		}
 
 		// Check if context is cancelled
 		if ctx.Err() != nil {
 			result.Error = music.NewDomainErrorWithCause(music.ErrTimeout, "context cancelled", ctx.Err())
-			result.Duration = time.Since(startTime)
-			return result
+			return finish(result)
 		}
 	}
 
 	// All retries exhausted
-	return &ExecuteResult{
-		Error:      music.NewDomainErrorWithCause(music.ErrOperationFailed, fmt.Sprintf("AppleScript execution failed after %d attempts", e.config.MaxRetries+1), lastErr),
-		Duration:   time.Since(startTime),
-		RetryCount: e.config.MaxRetries,
+	return finish(&ExecuteResult{
+		Error:      music.NewDomainErrorWithCause(music.ErrOperationFailed, fmt.Sprintf("AppleScript execution failed after %d attempts", config.MaxRetries+1), lastResult.Error),
+		RetryCount: config.MaxRetries,
+		ExitCode:   lastResult.ExitCode,
+	})
+}
+
+// executeMethods are the Executor entry points callerName looks past.
+var executeMethods = []string{".(*Executor).Execute", ".(*Executor).ExecuteWithTimeout", ".(*Executor).ExecuteTemplate"}
+
+// callerName returns the function that asked the Executor to run a script,
+// shortened to e.g. "PlayerRepository.Pause".
+func callerName() string {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		execute := false
+		for _, method := range executeMethods {
+			execute = execute || strings.HasSuffix(frame.Function, method)
+		}
+		if !execute {
+			name := frame.Function[strings.LastIndex(frame.Function, "/")+1:]
+			if _, rest, ok := strings.Cut(name, "."); ok {
+				name = rest
+			}
+			return strings.NewReplacer("(*", "", ")", "").Replace(name)
+		}
+		if !more {
+			return "unknown"
+		}
 	}
 }
 
 // executeOnce executes the AppleScript once without retries.
-func (e *Executor) executeOnce(ctx context.Context, script string, timeout time.Duration) *ExecuteResult {
+func (e *Executor) executeOnce(ctx context.Context, execPath, script string, timeout time.Duration) *ExecuteResult {
 	startTime := time.Now()
 
 	// Create context with timeout
@@ -145,7 +244,7 @@ func (e *Executor) executeOnce(ctx context.Context, script string, timeout time.
 	defer cancel()
 
 	// Create command to run maestro-exec
-	cmd := exec.CommandContext(execCtx, e.config.ExecPath)
+	cmd := exec.CommandContext(execCtx, execPath)
 
 	// Set up pipes
 	var stdout, stderr bytes.Buffer
@@ -158,6 +257,7 @@ func (e *Executor) executeOnce(ctx context.Context, script string, timeout time.
 		return &ExecuteResult{
 			Error:    music.NewDomainErrorWithCause(music.ErrOperationFailed, "failed to create stdin pipe", err),
 			Duration: time.Since(startTime),
+			ExitCode: -1,
 		}
 	}
 
@@ -167,6 +267,7 @@ func (e *Executor) executeOnce(ctx context.Context, script string, timeout time.
 		return &ExecuteResult{
 			Error:    music.NewDomainErrorWithCause(music.ErrOperationFailed, "failed to start maestro-exec", err),
 			Duration: time.Since(startTime),
+			ExitCode: -1,
 		}
 	}
 
@@ -180,6 +281,7 @@ func (e *Executor) executeOnce(ctx context.Context, script string, timeout time.
 		return &ExecuteResult{
 			Error:    music.NewDomainErrorWithCause(music.ErrOperationFailed, "failed to write script to stdin", writeErr),
 			Duration: time.Since(startTime),
+			ExitCode: -1,
 		}
 	}
 
@@ -189,6 +291,7 @@ func (e *Executor) executeOnce(ctx context.Context, script string, timeout time.
 	result := &ExecuteResult{
 		Output:   strings.TrimSpace(stdout.String()),
 		Duration: time.Since(startTime),
+		ExitCode: cmd.ProcessState.ExitCode(),
 	}
 
 	// Handle different types of errors
@@ -198,6 +301,7 @@ func (e *Executor) executeOnce(ctx context.Context, script string, timeout time.
 		// Check if it was a timeout
 		if execCtx.Err() == context.DeadlineExceeded {
 			result.Error = music.WrapOperationTimeout("AppleScript execution", music.NewDurationFromTime(timeout), err)
+			result.timedOut = true
 			return result
 		}
 
@@ -214,6 +318,7 @@ func (e *Executor) executeOnce(ctx context.Context, script string, timeout time.
 				result.Error = music.NewDomainErrorWithCause(music.ErrOperationFailed, fmt.Sprintf("AppleScript execution failed: %s", stderrOutput), err)
 			case 2: // Timeout
 				result.Error = music.WrapOperationTimeout("AppleScript execution", music.NewDurationFromTime(timeout), err)
+				result.timedOut = true
 			default:
 				result.Error = music.NewDomainErrorWithCause(music.ErrOperationFailed, fmt.Sprintf("AppleScript execution failed with exit code %d: %s", exitError.ExitCode(), stderrOutput), err)
 			}
@@ -272,19 +377,21 @@ func (e *Executor) ExecuteTemplate(ctx context.Context, templateName string, par
 // IsExecutable checks if the maestro-exec binary is available and executable.
 func (e *Executor) IsExecutable() error {
 	// Try to find the executable
-	execPath, err := exec.LookPath(e.config.ExecPath)
+	config := *e.Config()
+	execPath, err := exec.LookPath(config.ExecPath)
 	if err != nil {
 		return music.NewDomainErrorWithCause(music.ErrOperationFailed, "maestro-exec not found in PATH", err)
 	}
 
 	// Update config with full path
-	e.config.ExecPath = execPath
+	config.ExecPath = execPath
+	e.config.Store(&config)
 
 	// Test execution with a simple script
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	result := e.executeOnce(ctx, "return \"test\"", 2*time.Second)
+	result := e.executeOnce(ctx, execPath, "return \"test\"", 2*time.Second)
 	if result.Error != nil {
 		return music.NewDomainErrorWithCause(music.ErrOperationFailed, "maestro-exec is not working properly", result.Error)
 	}
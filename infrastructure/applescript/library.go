@@ -0,0 +1,478 @@
+package applescript
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// playlistRecordHandler is an AppleScript handler that serializes a playlist into
+// a single record: persistent ID, name, kind and a comma-separated list of track IDs.
+const playlistRecordHandler = `
+on playlistRecord(p)
+	set fs to character id 31
+	tell application "Music"
+		set kindName to "special"
+		if class of p is library playlist then
+			set kindName to "library"
+		else if class of p is user playlist then
+			set kindName to "user"
+			try
+				if smart of p then set kindName to "smart"
+			end try
+			try
+				if special kind of p is not none then set kindName to "special"
+			end try
+		end if
+		set AppleScript's text item delimiters to ","
+		set idList to (database ID of every track of p) as string
+		set AppleScript's text item delimiters to ""
+		return (persistent ID of p) & fs & (name of p) & fs & kindName & fs & idList
+	end tell
+end playlistRecord
+`
+
+// playlistRecordFieldCount is the number of fields produced by playlistRecordHandler.
+const playlistRecordFieldCount = 4
+
+// LibraryRepository implements the music.LibraryRepository interface using AppleScript
+// to read the Music.app library.
+type LibraryRepository struct {
+	executor *Executor
+}
+
+// NewLibraryRepository creates a new AppleScript-based library repository.
+func NewLibraryRepository(executor *Executor) *LibraryRepository {
+	if executor == nil {
+		executor = NewExecutor(nil)
+	}
+
+	return &LibraryRepository{
+		executor: executor,
+	}
+}
+
+// Search finds tracks in the library based on the provided criteria.
+func (l *LibraryRepository) Search(ctx context.Context, options music.LibrarySearchOptions) ([]*music.Track, error) {
+	query := strings.TrimSpace(options.Query)
+	artist := strings.TrimSpace(options.Artist)
+	album := strings.TrimSpace(options.Album)
+
+	if query == "" && artist == "" && album == "" {
+		return nil, music.NewDomainError(music.ErrInvalidSearchQuery, "search requires a query, artist or album")
+	}
+
+	if options.Limit < 0 || options.Offset < 0 {
+		return nil, music.NewDomainError(music.ErrInvalidSearchQuery, "limit and offset cannot be negative")
+	}
+
+	// Music.app's own search is much faster than a whose clause over the whole
+	// library, so use it whenever there is free text and filter the rest here.
+	var source string
+	if query != "" {
+		source = fmt.Sprintf("search library playlist 1 for %s only %s", quoteString(query), searchKind(options.Fields))
+	} else {
+		var clauses []string
+		if artist != "" {
+			clauses = append(clauses, "artist contains "+quoteString(artist))
+		}
+		if album != "" {
+			clauses = append(clauses, "album contains "+quoteString(album))
+		}
+		source = "every track of library playlist 1 whose " + strings.Join(clauses, " and ")
+	}
+
+	script := trackRecordHandler + fmt.Sprintf(`
+		tell application "Music"
+			try
+				set found to %s
+				set results to {}
+				repeat with t in found
+					set end of results to my trackRecord(t)
+				end repeat
+				return my joinRecords(results)
+			on error errMsg
+				error "Failed to search library: " & errMsg
+			end try
+		end tell
+	`, source)
+
+	result := l.executor.Execute(ctx, script)
+	if result.Error != nil {
+		return nil, music.NewDomainErrorWithCause(music.ErrSearchFailed, "failed to search library", result.Error).
+			WithContext("query", options.Query)
+	}
+
+	tracks, err := parseTrackRecords(result.Output)
+	if err != nil {
+		return nil, err
+	}
+
+	if query != "" {
+		filtered := tracks[:0]
+		for _, track := range tracks {
+			if containsFold(track.Artist, artist) && containsFold(track.Album, album) &&
+				music.ScoreTrack(track, options).Score > 0 {
+				filtered = append(filtered, track)
+			}
+		}
+		tracks = filtered
+	}
+
+	for i, ranked := range music.RankTracks(tracks, options) {
+		tracks[i] = ranked.Track
+	}
+	return paginate(tracks, options.Limit, options.Offset), nil
+}
+
+// searchKind returns the Music.app search kind for the requested fields.
+// A single field narrows the search in Music.app itself; anything else
+// searches songs and leaves the field filtering to ScoreTrack.
+func searchKind(fields []music.SearchField) string {
+	if len(fields) == 1 {
+		switch fields[0] {
+		case music.SearchFieldArtist:
+			return "artists"
+		case music.SearchFieldAlbum:
+			return "albums"
+		}
+	}
+	return "songs"
+}
+
+// GetTrack retrieves a specific track by its ID.
+func (l *LibraryRepository) GetTrack(ctx context.Context, trackID music.TrackID) (*music.Track, error) {
+	tracks, err := l.GetTracks(ctx, []music.TrackID{trackID})
+	if err != nil {
+		return nil, err
+	}
+	return tracks[0], nil
+}
+
+// GetTracks retrieves multiple tracks by their IDs, preserving the requested order.
+func (l *LibraryRepository) GetTracks(ctx context.Context, trackIDs []music.TrackID) ([]*music.Track, error) {
+	if len(trackIDs) == 0 {
+		return []*music.Track{}, nil
+	}
+
+	idList, err := scriptTrackIDList(trackIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	script := trackRecordHandler + fmt.Sprintf(`
+		tell application "Music"
+			set results to {}
+			repeat with trackID in %s
+				try
+					set t to first track of library playlist 1 whose database ID is (trackID as integer)
+					set end of results to my trackRecord(t)
+				end try
+			end repeat
+			return my joinRecords(results)
+		end tell
+	`, idList)
+
+	result := l.executor.Execute(ctx, script)
+	if result.Error != nil {
+		return nil, music.NewDomainErrorWithCause(music.ErrLibraryNotAvailable, "failed to get tracks", result.Error)
+	}
+
+	found, err := parseTrackRecords(result.Output)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*music.Track, len(found))
+	for _, track := range found {
+		byID[track.ID.Value()] = track
+	}
+
+	tracks := make([]*music.Track, 0, len(trackIDs))
+	for _, trackID := range trackIDs {
+		track, ok := byID[trackID.Value()]
+		if !ok {
+			return nil, music.WrapTrackNotFound(trackID, nil)
+		}
+		tracks = append(tracks, track)
+	}
+
+	return tracks, nil
+}
+
+// GetAllTracks returns all tracks in the library with pagination.
+func (l *LibraryRepository) GetAllTracks(ctx context.Context, limit, offset int) ([]*music.Track, error) {
+	if limit < 0 || offset < 0 {
+		return nil, music.NewDomainError(music.ErrInvalidOperation, "limit and offset cannot be negative")
+	}
+
+	script := trackRecordHandler + fmt.Sprintf(`
+		tell application "Music"
+			set total to count of tracks of library playlist 1
+			set startIndex to %d
+			set endIndex to total
+			if %d > 0 and (startIndex + %d - 1) < total then set endIndex to startIndex + %d - 1
+			set results to {}
+			if startIndex > total then return ""
+			repeat with i from startIndex to endIndex
+				set end of results to my trackRecord(track i of library playlist 1)
+			end repeat
+			return my joinRecords(results)
+		end tell
+	`, offset+1, limit, limit, limit)
+
+	result := l.executor.Execute(ctx, script)
+	if result.Error != nil {
+		return nil, music.NewDomainErrorWithCause(music.ErrLibraryNotAvailable, "failed to list library tracks", result.Error)
+	}
+
+	return parseTrackRecords(result.Output)
+}
+
+// GetTrackCount returns the total number of tracks in the library.
+func (l *LibraryRepository) GetTrackCount(ctx context.Context) (int, error) {
+	script := `
+		tell application "Music"
+			return count of tracks of library playlist 1
+		end tell
+	`
+
+	result := l.executor.Execute(ctx, script)
+	if result.Error != nil {
+		return 0, music.NewDomainErrorWithCause(music.ErrLibraryNotAvailable, "failed to count library tracks", result.Error)
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(result.Output))
+	if err != nil {
+		return 0, music.NewDomainErrorWithCause(music.ErrOperationFailed, "invalid track count format", err)
+	}
+
+	return count, nil
+}
+
+// GetPlaylists returns all playlists accessible to the user.
+func (l *LibraryRepository) GetPlaylists(ctx context.Context) ([]*music.Playlist, error) {
+	script := playlistRecordHandler + joinRecordsHandler + `
+		tell application "Music"
+			set results to {}
+			repeat with p in (every playlist)
+				set end of results to my playlistRecord(p)
+			end repeat
+			return my joinRecords(results)
+		end tell
+	`
+
+	result := l.executor.Execute(ctx, script)
+	if result.Error != nil {
+		return nil, music.NewDomainErrorWithCause(music.ErrLibraryNotAvailable, "failed to list playlists", result.Error)
+	}
+
+	records := splitRecords(result.Output)
+	playlists := make([]*music.Playlist, 0, len(records))
+	for _, record := range records {
+		playlist, err := parsePlaylistRecord(record)
+		if err != nil {
+			return nil, err
+		}
+		playlists = append(playlists, playlist)
+	}
+
+	return playlists, nil
+}
+
+// GetPlaylist retrieves a specific playlist by its ID.
+func (l *LibraryRepository) GetPlaylist(ctx context.Context, playlistID music.PlaylistID) (*music.Playlist, error) {
+	id, err := scriptPlaylistID(playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	script := playlistRecordHandler + fmt.Sprintf(`
+		tell application "Music"
+			try
+				set p to first playlist whose persistent ID is %s
+			on error
+				return ""
+			end try
+			return my playlistRecord(p)
+		end tell
+	`, id)
+
+	result := l.executor.Execute(ctx, script)
+	if result.Error != nil {
+		return nil, music.NewDomainErrorWithCause(music.ErrLibraryNotAvailable, "failed to get playlist", result.Error)
+	}
+
+	if strings.TrimSpace(result.Output) == "" {
+		return nil, music.WrapPlaylistNotFound(playlistID, nil)
+	}
+
+	return parsePlaylistRecord(result.Output)
+}
+
+// GetPlaylistTracks returns all tracks in a specific playlist.
+func (l *LibraryRepository) GetPlaylistTracks(ctx context.Context, playlistID music.PlaylistID) ([]*music.Track, error) {
+	id, err := scriptPlaylistID(playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	script := trackRecordHandler + fmt.Sprintf(`
+		tell application "Music"
+			try
+				set p to first playlist whose persistent ID is %s
+			on error
+				error "playlist not found"
+			end try
+			set results to {}
+			repeat with t in (every track of p)
+				set end of results to my trackRecord(t)
+			end repeat
+			return my joinRecords(results)
+		end tell
+	`, id)
+
+	result := l.executor.Execute(ctx, script)
+	if result.Error != nil {
+		if strings.Contains(result.Error.Error(), "playlist not found") {
+			return nil, music.WrapPlaylistNotFound(playlistID, result.Error)
+		}
+		return nil, music.NewDomainErrorWithCause(music.ErrLibraryNotAvailable, "failed to get playlist tracks", result.Error)
+	}
+
+	return parseTrackRecords(result.Output)
+}
+
+// GetArtists returns a sorted list of all artists in the library.
+func (l *LibraryRepository) GetArtists(ctx context.Context) ([]string, error) {
+	return l.distinctValues(ctx, "artist of every track of library playlist 1", "artists")
+}
+
+// GetAlbums returns a sorted list of all albums in the library.
+func (l *LibraryRepository) GetAlbums(ctx context.Context) ([]string, error) {
+	return l.distinctValues(ctx, "album of every track of library playlist 1", "albums")
+}
+
+// GetAlbumsByArtist returns albums by a specific artist.
+func (l *LibraryRepository) GetAlbumsByArtist(ctx context.Context, artist string) ([]string, error) {
+	if strings.TrimSpace(artist) == "" {
+		return nil, music.NewDomainError(music.ErrInvalidSearchQuery, "artist cannot be empty")
+	}
+	return l.distinctValues(ctx, "album of every track of library playlist 1 whose artist is "+quoteString(artist), "albums")
+}
+
+// GetTracksByArtist returns tracks by a specific artist.
+func (l *LibraryRepository) GetTracksByArtist(ctx context.Context, artist string) ([]*music.Track, error) {
+	if strings.TrimSpace(artist) == "" {
+		return nil, music.NewDomainError(music.ErrInvalidSearchQuery, "artist cannot be empty")
+	}
+	return l.tracksWhere(ctx, "artist is "+quoteString(artist))
+}
+
+// GetTracksByAlbum returns tracks from a specific album.
+func (l *LibraryRepository) GetTracksByAlbum(ctx context.Context, album string) ([]*music.Track, error) {
+	if strings.TrimSpace(album) == "" {
+		return nil, music.NewDomainError(music.ErrInvalidSearchQuery, "album cannot be empty")
+	}
+	return l.tracksWhere(ctx, "album is "+quoteString(album))
+}
+
+// tracksWhere returns every library track matching an AppleScript whose clause.
+func (l *LibraryRepository) tracksWhere(ctx context.Context, clause string) ([]*music.Track, error) {
+	script := trackRecordHandler + fmt.Sprintf(`
+		tell application "Music"
+			set results to {}
+			repeat with t in (every track of library playlist 1 whose %s)
+				set end of results to my trackRecord(t)
+			end repeat
+			return my joinRecords(results)
+		end tell
+	`, clause)
+
+	result := l.executor.Execute(ctx, script)
+	if result.Error != nil {
+		return nil, music.NewDomainErrorWithCause(music.ErrLibraryNotAvailable, "failed to query library tracks", result.Error)
+	}
+
+	return parseTrackRecords(result.Output)
+}
+
+// distinctValues evaluates an AppleScript expression returning a list of strings and
+// returns its unique, non-empty values in sorted order.
+func (l *LibraryRepository) distinctValues(ctx context.Context, expression, what string) ([]string, error) {
+	script := joinRecordsHandler + fmt.Sprintf(`
+		tell application "Music"
+			return my joinRecords(%s)
+		end tell
+	`, expression)
+
+	result := l.executor.Execute(ctx, script)
+	if result.Error != nil {
+		return nil, music.NewDomainErrorWithCause(music.ErrLibraryNotAvailable, "failed to list "+what, result.Error)
+	}
+
+	seen := make(map[string]bool)
+	values := make([]string, 0)
+	for _, value := range splitRecords(result.Output) {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		values = append(values, value)
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		return strings.ToLower(values[i]) < strings.ToLower(values[j])
+	})
+
+	return values, nil
+}
+
+// parsePlaylistRecord parses a record produced by playlistRecordHandler.
+func parsePlaylistRecord(record string) (*music.Playlist, error) {
+	fields := strings.Split(strings.TrimSpace(record), fieldSeparator)
+	if len(fields) != playlistRecordFieldCount {
+		return nil, music.NewDomainError(music.ErrOperationFailed, "invalid playlist record format")
+	}
+
+	var playlistType music.PlaylistType
+	readOnly := false
+	switch fields[2] {
+	case "user":
+		playlistType = music.PlaylistTypeUser
+	case "smart":
+		// Smart playlists are maintained by Music.app and cannot be edited directly.
+		playlistType = music.PlaylistTypeSmart
+		readOnly = true
+	default:
+		playlistType = music.PlaylistTypeLibrary
+	}
+
+	if fields[1] == queuePlaylistName {
+		playlistType = music.PlaylistTypeQueue
+	}
+
+	playlist, err := music.NewPlaylist(music.NewPlaylistID(fields[0]), fields[1], playlistType, readOnly || playlistType.IsReadOnly())
+	if err != nil {
+		return nil, err
+	}
+
+	if fields[3] != "" {
+		for _, id := range strings.Split(fields[3], ",") {
+			playlist.Tracks = append(playlist.Tracks, music.NewTrackID(id))
+		}
+	}
+
+	return playlist, nil
+}
+
+// containsFold reports whether substr is within s, ignoring case. An empty
+// substr always matches.
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
@@ -2,13 +2,27 @@ package applescript
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 
 	"github.com/madstone-tech/maestro/domain/music"
 )
 
+// invalidStateError is the error a player command script raises when Music.app
+// is in a state the command's transition is not allowed from.
+const invalidStateError = "maestro: invalid player state"
+
+// scriptStates are the Music.app player states that stand for each
+// PlayerState. Music.app never reports buffering.
+var scriptStates = map[music.PlayerState][]string{
+	music.PlayerStateStopped: {"stopped"},
+	music.PlayerStatePlaying: {"playing", "fast forwarding", "rewinding"},
+	music.PlayerStatePaused:  {"paused"},
+}
+
 // PlayerRepository implements the music.PlayerRepository interface using AppleScript
 // to control Music.app on macOS.
 type PlayerRepository struct {
@@ -28,8 +42,9 @@ func NewPlayerRepository(executor *Executor) *PlayerRepository {
 
 // Play starts playback of the specified track.
 func (p *PlayerRepository) Play(ctx context.Context, trackID music.TrackID) error {
-	if trackID.IsEmpty() {
-		return music.NewDomainError(music.ErrInvalidTrackID, "track ID cannot be empty")
+	trackRef, err := scriptTrackID(trackID)
+	if err != nil {
+		return err
 	}
 
 	script := fmt.Sprintf(`
@@ -41,7 +56,7 @@ func (p *PlayerRepository) Play(ctx context.Context, trackID music.TrackID) erro
 				error "Failed to play track: " & errMsg
 			end try
 		end tell
-	`, trackID.Value())
+	`, trackRef)
 
 	result := p.executor.Execute(ctx, script)
 	if result.Error != nil {
@@ -57,190 +72,209 @@ func (p *PlayerRepository) Play(ctx context.Context, trackID music.TrackID) erro
 
 // Pause pauses the current playback.
 func (p *PlayerRepository) Pause(ctx context.Context) error {
-	script := `
-		tell application "Music"
-			pause
-		end tell
-	`
-
-	result := p.executor.Execute(ctx, script)
-	if result.Error != nil {
-		return music.NewDomainErrorWithCause(music.ErrOperationFailed, "failed to pause playback", result.Error)
-	}
-
-	return nil
+	return p.run(ctx, music.PlayerCommand{Kind: music.PlayerCommandPause})
 }
 
 // Stop stops playback and clears the current track.
 func (p *PlayerRepository) Stop(ctx context.Context) error {
-	script := `
-		tell application "Music"
-			stop
-		end tell
-	`
-
-	result := p.executor.Execute(ctx, script)
-	if result.Error != nil {
-		return music.NewDomainErrorWithCause(music.ErrOperationFailed, "failed to stop playback", result.Error)
-	}
-
-	return nil
+	return p.run(ctx, music.PlayerCommand{Kind: music.PlayerCommandStop})
 }
 
 // Resume resumes paused playback.
 func (p *PlayerRepository) Resume(ctx context.Context) error {
-	script := `
-		tell application "Music"
-			play
-		end tell
-	`
-
-	result := p.executor.Execute(ctx, script)
-	if result.Error != nil {
-		return music.NewDomainErrorWithCause(music.ErrOperationFailed, "failed to resume playback", result.Error)
-	}
+	return p.run(ctx, music.PlayerCommand{Kind: music.PlayerCommandResume})
+}
 
-	return nil
+// Start resumes paused playback or, when stopped, plays Music.app's current
+// selection.
+func (p *PlayerRepository) Start(ctx context.Context) error {
+	return p.run(ctx, music.PlayerCommand{Kind: music.PlayerCommandStart})
 }
 
 // Next advances to the next track in the current context.
 func (p *PlayerRepository) Next(ctx context.Context) error {
-	script := `
-		tell application "Music"
-			next track
-		end tell
-	`
-
-	result := p.executor.Execute(ctx, script)
-	if result.Error != nil {
-		return music.NewDomainErrorWithCause(music.ErrOperationFailed, "failed to skip to next track", result.Error)
-	}
-
-	return nil
+	return p.run(ctx, music.PlayerCommand{Kind: music.PlayerCommandNext})
 }
 
 // Previous goes back to the previous track in the current context.
 func (p *PlayerRepository) Previous(ctx context.Context) error {
-	script := `
-		tell application "Music"
-			previous track
-		end tell
-	`
-
-	result := p.executor.Execute(ctx, script)
-	if result.Error != nil {
-		return music.NewDomainErrorWithCause(music.ErrOperationFailed, "failed to skip to previous track", result.Error)
-	}
-
-	return nil
+	return p.run(ctx, music.PlayerCommand{Kind: music.PlayerCommandPrevious})
 }
 
 // Seek changes the playback position within the current track.
 func (p *PlayerRepository) Seek(ctx context.Context, position music.Duration) error {
-	if !position.IsValid() {
-		return music.WrapInvalidPosition(position, nil)
-	}
+	return p.run(ctx, music.PlayerCommand{Kind: music.PlayerCommandSeek, Position: position})
+}
 
-	script := fmt.Sprintf(`
-		tell application "Music"
-			try
-				set player position to %d
-			on error errMsg
-				error "Failed to seek: " & errMsg
-			end try
-		end tell
-	`, position.Seconds())
+// SetVolume changes the playback volume.
+func (p *PlayerRepository) SetVolume(ctx context.Context, volume music.Volume) error {
+	return p.run(ctx, music.PlayerCommand{Kind: music.PlayerCommandSetVolume, Volume: volume})
+}
 
-	result := p.executor.Execute(ctx, script)
-	if result.Error != nil {
-		return music.NewDomainErrorWithCause(
-			music.ErrOperationFailed,
-			fmt.Sprintf("failed to seek to position %s", position.String()),
-			result.Error,
-		)
-	}
+// SetShuffle enables or disables shuffle mode.
+func (p *PlayerRepository) SetShuffle(ctx context.Context, enabled bool) error {
+	return p.run(ctx, music.PlayerCommand{Kind: music.PlayerCommandSetShuffle, Shuffle: enabled})
+}
 
-	return nil
+// SetRepeat changes the repeat mode.
+func (p *PlayerRepository) SetRepeat(ctx context.Context, mode music.RepeatMode) error {
+	return p.run(ctx, music.PlayerCommand{Kind: music.PlayerCommandSetRepeat, Repeat: mode})
 }
 
-// SetVolume changes the playback volume.
-func (p *PlayerRepository) SetVolume(ctx context.Context, volume music.Volume) error {
-	if !volume.IsValid() {
-		return music.WrapInvalidVolume(volume.Level(), nil)
+// RunCommands runs player commands in order in one script and stops at the
+// first failure, returning how many commands succeeded. Commands before an
+// invalid one still run.
+func (p *PlayerRepository) RunCommands(ctx context.Context, commands []music.PlayerCommand) (int, error) {
+	var body strings.Builder
+	var invalid error
+	count := 0
+	for _, command := range commands {
+		statement, err := commandStatement(command)
+		if err != nil {
+			invalid = err
+			break
+		}
+		count++
+		fmt.Fprintf(&body, "\t\t\t\t%s\n\t\t\t\tset done to %d\n", statement, count)
+	}
+	if count == 0 {
+		return 0, invalid
 	}
 
 	script := fmt.Sprintf(`
 		tell application "Music"
-			set sound volume to %d
+			set done to 0
+			try
+%s			on error errMsg
+				return (done as string) & "|" & errMsg
+			end try
+			return (done as string) & "|"
 		end tell
-	`, volume.Level())
+	`, body.String())
 
 	result := p.executor.Execute(ctx, script)
 	if result.Error != nil {
-		return music.NewDomainErrorWithCause(
-			music.ErrOperationFailed,
-			fmt.Sprintf("failed to set volume to %s", volume.String()),
-			result.Error,
-		)
+		return 0, music.NewDomainErrorWithCause(music.ErrOperationFailed, commandFailure(commands[0]), result.Error)
 	}
 
-	return nil
+	doneText, errMsg, _ := strings.Cut(strings.TrimSpace(result.Output), "|")
+	done, err := strconv.Atoi(doneText)
+	if err != nil || done < 0 || done > count {
+		return 0, music.NewDomainError(music.ErrOperationFailed, "invalid player command result format")
+	}
+	if done < count {
+		if errMsg == invalidStateError {
+			return done, music.NewDomainError(music.ErrInvalidPlayerState,
+				fmt.Sprintf("cannot %s in the current player state", commands[done].Kind)).
+				WithContext("command", commands[done].Kind.String())
+		}
+		return done, music.NewDomainErrorWithCause(music.ErrOperationFailed, commandFailure(commands[done]), errors.New(errMsg))
+	}
+	return done, invalid
 }
 
-// SetShuffle enables or disables shuffle mode.
-func (p *PlayerRepository) SetShuffle(ctx context.Context, enabled bool) error {
-	script := fmt.Sprintf(`
-		tell application "Music"
-			set shuffle enabled to %t
-		end tell
-	`, enabled)
+// run runs a single player command.
+func (p *PlayerRepository) run(ctx context.Context, command music.PlayerCommand) error {
+	_, err := p.RunCommands(ctx, []music.PlayerCommand{command})
+	return err
+}
 
-	result := p.executor.Execute(ctx, script)
-	if result.Error != nil {
-		return music.NewDomainErrorWithCause(
-			music.ErrOperationFailed,
-			fmt.Sprintf("failed to set shuffle mode to %t", enabled),
-			result.Error,
-		)
+// commandStatement returns the AppleScript statement, inside a tell block
+// for Music, that runs command. A command that changes the playback state is
+// guarded by the domain transition table.
+func commandStatement(command music.PlayerCommand) (string, error) {
+	if err := command.Validate(); err != nil {
+		return "", err
 	}
 
-	return nil
+	statement := actionStatement(command)
+	if transition, ok := command.Kind.Transition(); ok {
+		if guard := stateGuard(transition); guard != "" {
+			statement = guard + "\n\t\t\t\t" + statement
+		}
+	}
+	return statement, nil
 }
 
-// SetRepeat changes the repeat mode.
-func (p *PlayerRepository) SetRepeat(ctx context.Context, mode music.RepeatMode) error {
-	if !mode.IsValid() {
-		return music.NewDomainError(music.ErrInvalidRepeatMode, "invalid repeat mode")
+// stateGuard returns a statement that raises invalidStateError unless
+// Music.app is in a state transition is allowed from, or "" when it is
+// allowed from every state Music.app reports.
+func stateGuard(transition music.PlayerTransition) string {
+	var names []string
+	guarded := false
+	for _, state := range []music.PlayerState{music.PlayerStateStopped, music.PlayerStatePlaying, music.PlayerStatePaused} {
+		if !state.CanTransition(transition) {
+			guarded = true
+			continue
+		}
+		names = append(names, scriptStates[state]...)
 	}
+	if !guarded {
+		return ""
+	}
+	return fmt.Sprintf(`if {%s} does not contain player state then error "%s"`, strings.Join(names, ", "), invalidStateError)
+}
+
+// actionStatement returns the statement that performs a valid command.
+func actionStatement(command music.PlayerCommand) string {
+	switch command.Kind {
+	case music.PlayerCommandPause:
+		return "pause"
+	case music.PlayerCommandStop:
+		return "stop"
+	case music.PlayerCommandResume, music.PlayerCommandStart:
+		return "play"
+	case music.PlayerCommandNext:
+		return "next track"
+	case music.PlayerCommandPrevious:
+		return "previous track"
+	case music.PlayerCommandSeek:
+		return "set player position to " + strconv.FormatFloat(float64(command.Position.Milliseconds())/1000, 'f', -1, 64)
+	case music.PlayerCommandSetVolume:
+		return fmt.Sprintf("set sound volume to %d", command.Volume.Level())
+	case music.PlayerCommandSetShuffle:
+		return fmt.Sprintf("set shuffle enabled to %t", command.Shuffle)
+	default:
+		return "set song repeat to " + repeatSetting(command.Repeat)
+	}
+}
 
-	var repeatSetting string
+// repeatSetting returns the AppleScript name of a repeat mode.
+func repeatSetting(mode music.RepeatMode) string {
 	switch mode {
-	case music.RepeatModeOff:
-		repeatSetting = "off"
 	case music.RepeatModeAll:
-		repeatSetting = "all"
+		return "all"
 	case music.RepeatModeOne:
-		repeatSetting = "one"
+		return "one"
 	default:
-		return music.NewDomainError(music.ErrInvalidRepeatMode, "unsupported repeat mode")
+		return "off"
 	}
+}
 
-	script := fmt.Sprintf(`
-		tell application "Music"
-			set song repeat to %s
-		end tell
-	`, repeatSetting)
-
-	result := p.executor.Execute(ctx, script)
-	if result.Error != nil {
-		return music.NewDomainErrorWithCause(
-			music.ErrOperationFailed,
-			fmt.Sprintf("failed to set repeat mode to %s", mode.String()),
-			result.Error,
-		)
+// commandFailure describes a failed player command.
+func commandFailure(command music.PlayerCommand) string {
+	switch command.Kind {
+	case music.PlayerCommandPause:
+		return "failed to pause playback"
+	case music.PlayerCommandStop:
+		return "failed to stop playback"
+	case music.PlayerCommandResume:
+		return "failed to resume playback"
+	case music.PlayerCommandStart:
+		return "failed to start playback"
+	case music.PlayerCommandNext:
+		return "failed to skip to next track"
+	case music.PlayerCommandPrevious:
+		return "failed to skip to previous track"
+	case music.PlayerCommandSeek:
+		return fmt.Sprintf("failed to seek to position %s", command.Position.String())
+	case music.PlayerCommandSetVolume:
+		return fmt.Sprintf("failed to set volume to %s", command.Volume.String())
+	case music.PlayerCommandSetShuffle:
+		return fmt.Sprintf("failed to set shuffle mode to %t", command.Shuffle)
+	default:
+		return fmt.Sprintf("failed to set repeat mode to %s", command.Repeat.String())
 	}
-
-	return nil
 }
 
 // GetCurrentState returns the current player state.
@@ -278,21 +312,14 @@ func (p *PlayerRepository) GetCurrentState(ctx context.Context) (*music.Player,
 
 // GetCurrentTrack returns the currently playing track, if any.
 func (p *PlayerRepository) GetCurrentTrack(ctx context.Context) (*music.Track, error) {
-	script := `
+	script := trackRecordHandler + `
 		tell application "Music"
 			try
 				if player state is stopped then
 					return ""
 				end if
 				
-				set theTrack to current track
-				set trackID to (database ID of theTrack) as string
-				set trackName to name of theTrack
-				set trackArtist to artist of theTrack
-				set trackAlbum to album of theTrack
-				set trackDuration to duration of theTrack
-				
-				return trackID & "|" & trackName & "|" & trackArtist & "|" & trackAlbum & "|" & trackDuration
+				return my trackRecord(current track)
 			on error errMsg
 				error "Failed to get current track: " & errMsg
 			end try
@@ -308,7 +335,7 @@ func (p *PlayerRepository) GetCurrentTrack(ctx context.Context) (*music.Track, e
 		return nil, nil // No current track
 	}
 
-	return p.parseTrack(result.Output)
+	return parseTrackRecord(result.Output)
 }
 
 // parsePlayerState parses the player state output from AppleScript.
@@ -327,6 +354,8 @@ func (p *PlayerRepository) parsePlayerState(output string) (*music.Player, error
 		state = music.PlayerStatePlaying
 	case "paused":
 		state = music.PlayerStatePaused
+	case "fast forwarding", "rewinding":
+		state = music.PlayerStatePlaying
 	default:
 		state = music.PlayerStateStopped
 	}
@@ -344,11 +373,11 @@ func (p *PlayerRepository) parsePlayerState(output string) (*music.Player, error
 		// When stopped or no current track, position is 0
 		position = music.NewDuration(0)
 	} else {
-		positionSeconds, err := strconv.ParseFloat(parts[2], 64)
+		positionSeconds, err := parseSeconds(parts[2])
 		if err != nil {
 			return nil, music.NewDomainError(music.ErrOperationFailed, "invalid position format")
 		}
-		position = music.NewDuration(int(positionSeconds))
+		position = music.NewDurationFromMillis(int64(math.Round(positionSeconds * 1000)))
 	}
 
 	// Parse shuffle
@@ -385,28 +414,6 @@ func (p *PlayerRepository) parsePlayerState(output string) (*music.Player, error
 	return player, nil
 }
 
-// parseTrack parses track information from AppleScript output.
-func (p *PlayerRepository) parseTrack(output string) (*music.Track, error) {
-	parts := strings.Split(strings.TrimSpace(output), "|")
-	if len(parts) != 5 {
-		return nil, music.NewDomainError(music.ErrOperationFailed, "invalid track format")
-	}
-
-	trackID := music.NewTrackID(parts[0])
-	title := parts[1]
-	artist := parts[2]
-	album := parts[3]
-
-	// Parse duration (in seconds)
-	durationSeconds, err := strconv.ParseFloat(parts[4], 64)
-	if err != nil {
-		return nil, music.NewDomainError(music.ErrOperationFailed, "invalid track duration format")
-	}
-	duration := music.NewDuration(int(durationSeconds))
-
-	return music.NewTrack(trackID, title, artist, album, duration)
-}
-
 // HealthCheck performs a basic health check to ensure Music.app is accessible.
 func (p *PlayerRepository) HealthCheck(ctx context.Context) error {
 	script := `
@@ -0,0 +1,233 @@
+package applescript
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// fakeExecutor returns an executor whose maestro-exec prints output, and a
+// function returning the last script it was given ("" when none ran).
+func fakeExecutor(t *testing.T, output string) (*Executor, func() string) {
+	t.Helper()
+	dir := t.TempDir()
+	execPath := filepath.Join(dir, "maestro-exec")
+	if err := os.WriteFile(execPath+".out", []byte(output), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(execPath, []byte("#!/bin/sh\ncat > \"$0.in\"\ncat \"$0.out\"\n"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	executor := NewExecutor(&ExecutorConfig{ExecPath: execPath, DefaultTimeout: 5 * time.Second})
+	return executor, func() string {
+		script, _ := os.ReadFile(execPath + ".in")
+		return string(script)
+	}
+}
+
+func TestCommandStatement(t *testing.T) {
+	tests := []struct {
+		command music.PlayerCommand
+		action  string
+	}{
+		{music.PlayerCommand{Kind: music.PlayerCommandPause}, "pause"},
+		{music.PlayerCommand{Kind: music.PlayerCommandStop}, "stop"},
+		{music.PlayerCommand{Kind: music.PlayerCommandResume}, "play"},
+		{music.PlayerCommand{Kind: music.PlayerCommandStart}, "play"},
+		{music.PlayerCommand{Kind: music.PlayerCommandNext}, "next track"},
+		{music.PlayerCommand{Kind: music.PlayerCommandPrevious}, "previous track"},
+		{music.PlayerCommand{Kind: music.PlayerCommandSeek, Position: music.NewDurationFromMillis(90500)},
+			"set player position to 90.5"},
+		{music.PlayerCommand{Kind: music.PlayerCommandSeek, Position: music.NewDuration(0)}, "set player position to 0"},
+		{music.PlayerCommand{Kind: music.PlayerCommandSetVolume, Volume: music.NewVolume(35)}, "set sound volume to 35"},
+		{music.PlayerCommand{Kind: music.PlayerCommandSetShuffle, Shuffle: true}, "set shuffle enabled to true"},
+		{music.PlayerCommand{Kind: music.PlayerCommandSetRepeat, Repeat: music.RepeatModeOne}, "set song repeat to one"},
+		{music.PlayerCommand{Kind: music.PlayerCommandSetRepeat, Repeat: music.RepeatModeOff}, "set song repeat to off"},
+	}
+	for _, tt := range tests {
+		want := tt.action
+		if transition, ok := tt.command.Kind.Transition(); ok && stateGuard(transition) != "" {
+			want = stateGuard(transition) + "\n\t\t\t\t" + tt.action
+		}
+		if got, err := commandStatement(tt.command); err != nil || got != want {
+			t.Errorf("%s:\n got %q, %v\nwant %q", tt.command, got, err, want)
+		}
+	}
+
+	invalid := []music.PlayerCommand{
+		{Kind: music.PlayerCommandKind(99)},
+		{Kind: music.PlayerCommandSetRepeat, Repeat: music.RepeatMode(99)},
+	}
+	for _, command := range invalid {
+		if statement, err := commandStatement(command); err == nil {
+			t.Errorf("%s: expected an error, got %q", command, statement)
+		}
+	}
+}
+
+func TestStateGuard(t *testing.T) {
+	states := []music.PlayerState{music.PlayerStateStopped, music.PlayerStatePlaying, music.PlayerStatePaused}
+	for _, transition := range []music.PlayerTransition{music.PlayerTransitionPause, music.PlayerTransitionResume, music.PlayerTransitionStop} {
+		guard := stateGuard(transition)
+
+		everywhere := true
+		for _, state := range states {
+			everywhere = everywhere && state.CanTransition(transition)
+		}
+		if everywhere {
+			if guard != "" {
+				t.Errorf("%s is allowed from every state, but is guarded by %q", transition, guard)
+			}
+			continue
+		}
+
+		list, _, ok := strings.Cut(strings.TrimPrefix(guard, "if {"), "} does not contain player state then error ")
+		if !ok || !strings.HasPrefix(guard, "if {") {
+			t.Errorf("%s: unexpected guard %q", transition, guard)
+			continue
+		}
+		listed := strings.Split(list, ", ")
+		for _, state := range states {
+			for _, name := range scriptStates[state] {
+				if slices.Contains(listed, name) != state.CanTransition(transition) {
+					t.Errorf("%s guard %q: %q listed %t, but allowed from %s is %t",
+						transition, guard, name, !state.CanTransition(transition), state, state.CanTransition(transition))
+				}
+			}
+		}
+	}
+}
+
+func TestRunCommands(t *testing.T) {
+	commands := []music.PlayerCommand{
+		{Kind: music.PlayerCommandSetVolume, Volume: music.NewVolume(20)},
+		{Kind: music.PlayerCommandPause},
+		{Kind: music.PlayerCommandNext},
+	}
+
+	tests := []struct {
+		name   string
+		output string
+		ran    int
+		code   error
+	}{
+		{"all ran", "3|", 3, nil},
+		{"state guard", "1|" + invalidStateError, 1, music.ErrInvalidPlayerState},
+		{"Music.app error", "2|Music got an error", 2, music.ErrOperationFailed},
+		{"garbled output", "done", 0, music.ErrOperationFailed},
+		{"count out of range", "4|", 0, music.ErrOperationFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor, script := fakeExecutor(t, tt.output)
+			ran, err := NewPlayerRepository(executor).RunCommands(context.Background(), commands)
+			if ran != tt.ran || (tt.code == nil) != (err == nil) || (tt.code != nil && !errors.Is(err, tt.code)) {
+				t.Errorf("got %d, %v; want %d, %v", ran, err, tt.ran, tt.code)
+			}
+
+			body := script()
+			for i, want := range []string{"set sound volume to 20", "pause", "next track"} {
+				if !strings.Contains(body, want) || !strings.Contains(body, "set done to "+string(rune('1'+i))) {
+					t.Errorf("script is missing %q or its count:\n%s", want, body)
+				}
+			}
+			if strings.Index(body, "set sound volume to 20") > strings.Index(body, "next track") {
+				t.Errorf("commands out of order:\n%s", body)
+			}
+		})
+	}
+}
+
+func TestRunCommandsStopsAtInvalidCommand(t *testing.T) {
+	executor, script := fakeExecutor(t, "1|")
+	player := NewPlayerRepository(executor)
+
+	ran, err := player.RunCommands(context.Background(), []music.PlayerCommand{
+		{Kind: music.PlayerCommandNext},
+		{Kind: music.PlayerCommandSetRepeat, Repeat: music.RepeatMode(99)},
+		{Kind: music.PlayerCommandStop},
+	})
+	if ran != 1 || !errors.Is(err, music.ErrInvalidRepeatMode) {
+		t.Errorf("expected the command before the invalid one to run, got %d, %v", ran, err)
+	}
+	if body := script(); !strings.Contains(body, "next track") || strings.Contains(body, "stop") {
+		t.Errorf("expected only the commands before the invalid one in the script:\n%s", body)
+	}
+
+	// Nothing runs when the first command is invalid
+	executor, script = fakeExecutor(t, "0|")
+	ran, err = NewPlayerRepository(executor).RunCommands(context.Background(), []music.PlayerCommand{
+		{Kind: music.PlayerCommandKind(99)},
+	})
+	if ran != 0 || !errors.Is(err, music.ErrInvalidOperation) || script() != "" {
+		t.Errorf("expected no script for an invalid command, got %d, %v, %q", ran, err, script())
+	}
+}
+
+func TestPlayValidatesTrackID(t *testing.T) {
+	executor, script := fakeExecutor(t, "")
+	player := NewPlayerRepository(executor)
+
+	for _, id := range []string{`1" & (do shell script "id") & "`, "abc", ""} {
+		if err := player.Play(context.Background(), music.NewTrackID(id)); !errors.Is(err, music.ErrInvalidTrackID) {
+			t.Errorf("Play(%q): expected ErrInvalidTrackID, got %v", id, err)
+		}
+	}
+	if script() != "" {
+		t.Errorf("expected no script for invalid track IDs, got %q", script())
+	}
+
+	if err := player.Play(context.Background(), music.NewTrackID("1003")); err != nil {
+		t.Fatal(err)
+	}
+	if body := script(); !strings.Contains(body, "track id 1003") {
+		t.Errorf("expected the track ID in the script:\n%s", body)
+	}
+}
+
+func TestParsePlayerState(t *testing.T) {
+	tests := []struct {
+		output   string
+		state    music.PlayerState
+		volume   int
+		position int64
+		shuffle  bool
+		repeat   music.RepeatMode
+		track    string
+	}{
+		{"playing|65|90,25|true|all|1003", music.PlayerStatePlaying, 65, 90250, true, music.RepeatModeAll, "1003"},
+		{"paused|0|12.5|false|one|42", music.PlayerStatePaused, 0, 12500, false, music.RepeatModeOne, "42"},
+		{"stopped|100|missing value|false|off|", music.PlayerStateStopped, 100, 0, false, music.RepeatModeOff, ""},
+		{"fast forwarding|50||false|off|7", music.PlayerStatePlaying, 50, 0, false, music.RepeatModeOff, "7"},
+	}
+	player := NewPlayerRepository(nil)
+	for _, tt := range tests {
+		got, err := player.parsePlayerState(tt.output)
+		if err != nil {
+			t.Errorf("%q: %v", tt.output, err)
+			continue
+		}
+		track := ""
+		if got.CurrentTrack != nil {
+			track = got.CurrentTrack.Value()
+		}
+		if got.State != tt.state || got.Volume.Level() != tt.volume || got.Position.Milliseconds() != tt.position ||
+			got.Shuffle != tt.shuffle || got.Repeat != tt.repeat || track != tt.track {
+			t.Errorf("%q: got %+v", tt.output, got)
+		}
+	}
+
+	for _, output := range []string{"", "playing|65|90|true|all", "playing|loud|90|true|all|1", "playing|65|soon|true|all|1"} {
+		if _, err := player.parsePlayerState(output); !errors.Is(err, music.ErrOperationFailed) {
+			t.Errorf("%q: expected ErrOperationFailed, got %v", output, err)
+		}
+	}
+}
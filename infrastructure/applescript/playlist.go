@@ -0,0 +1,252 @@
+package applescript
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// PlaylistRepository implements the music.PlaylistRepository interface using AppleScript
+// to edit user playlists in Music.app.
+type PlaylistRepository struct {
+	executor *Executor
+	library  *LibraryRepository
+}
+
+// NewPlaylistRepository creates a new AppleScript-based playlist repository.
+func NewPlaylistRepository(executor *Executor) *PlaylistRepository {
+	if executor == nil {
+		executor = NewExecutor(nil)
+	}
+
+	return &PlaylistRepository{
+		executor: executor,
+		library:  NewLibraryRepository(executor),
+	}
+}
+
+// CreatePlaylist creates a new user playlist.
+func (p *PlaylistRepository) CreatePlaylist(ctx context.Context, name string) (*music.Playlist, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, music.NewDomainError(music.ErrInvalidPlaylist, "playlist name cannot be empty")
+	}
+
+	script := playlistRecordHandler + fmt.Sprintf(`
+		tell application "Music"
+			set p to make new user playlist with properties {name:%s}
+			return my playlistRecord(p)
+		end tell
+	`, quoteString(name))
+
+	result := p.executor.Execute(ctx, script)
+	if result.Error != nil {
+		return nil, music.NewDomainErrorWithCause(music.ErrOperationFailed, fmt.Sprintf("failed to create playlist '%s'", name), result.Error)
+	}
+
+	return parsePlaylistRecord(result.Output)
+}
+
+// UpdatePlaylist updates playlist metadata. Only the name can be changed.
+func (p *PlaylistRepository) UpdatePlaylist(ctx context.Context, playlist *music.Playlist) error {
+	if playlist == nil {
+		return music.NewDomainError(music.ErrInvalidPlaylist, "playlist cannot be nil")
+	}
+
+	if strings.TrimSpace(playlist.Name) == "" {
+		return music.NewDomainError(music.ErrInvalidPlaylist, "playlist name cannot be empty")
+	}
+
+	id, err := p.writablePlaylist(ctx, playlist.ID)
+	if err != nil {
+		return err
+	}
+
+	script := fmt.Sprintf(`
+		tell application "Music"
+			set name of (first user playlist whose persistent ID is %s) to %s
+		end tell
+	`, id, quoteString(playlist.Name))
+
+	result := p.executor.Execute(ctx, script)
+	if result.Error != nil {
+		return music.NewDomainErrorWithCause(music.ErrOperationFailed, "failed to update playlist", result.Error).
+			WithContext("playlist_id", playlist.ID.Value())
+	}
+
+	return nil
+}
+
+// DeletePlaylist removes a playlist (only user-created playlists).
+func (p *PlaylistRepository) DeletePlaylist(ctx context.Context, playlistID music.PlaylistID) error {
+	id, err := p.writablePlaylist(ctx, playlistID)
+	if err != nil {
+		return err
+	}
+
+	script := fmt.Sprintf(`
+		tell application "Music"
+			delete (first user playlist whose persistent ID is %s)
+		end tell
+	`, id)
+
+	result := p.executor.Execute(ctx, script)
+	if result.Error != nil {
+		return music.NewDomainErrorWithCause(music.ErrOperationFailed, "failed to delete playlist", result.Error).
+			WithContext("playlist_id", playlistID.Value())
+	}
+
+	return nil
+}
+
+// AddTrackToPlaylist adds a track to the end of a playlist.
+func (p *PlaylistRepository) AddTrackToPlaylist(ctx context.Context, playlistID music.PlaylistID, trackID music.TrackID) error {
+	trackRef, err := scriptTrackID(trackID)
+	if err != nil {
+		return err
+	}
+
+	playlist, err := p.library.GetPlaylist(ctx, playlistID)
+	if err != nil {
+		return err
+	}
+
+	// Validate against the domain rules before touching Music.app.
+	if err := playlist.AddTrack(trackID); err != nil {
+		return err
+	}
+
+	id, _ := scriptPlaylistID(playlistID)
+	script := fmt.Sprintf(`
+		tell application "Music"
+			duplicate (first track of library playlist 1 whose database ID is %s) to (first user playlist whose persistent ID is %s)
+		end tell
+	`, trackRef, id)
+
+	result := p.executor.Execute(ctx, script)
+	if result.Error != nil {
+		return music.NewDomainErrorWithCause(music.ErrOperationFailed, "failed to add track to playlist", result.Error).
+			WithContext("playlist_id", playlistID.Value()).
+			WithContext("track_id", trackID.Value())
+	}
+
+	return nil
+}
+
+// RemoveTrackFromPlaylist removes the first occurrence of a track from a playlist.
+func (p *PlaylistRepository) RemoveTrackFromPlaylist(ctx context.Context, playlistID music.PlaylistID, trackID music.TrackID) error {
+	trackRef, err := scriptTrackID(trackID)
+	if err != nil {
+		return err
+	}
+
+	playlist, err := p.library.GetPlaylist(ctx, playlistID)
+	if err != nil {
+		return err
+	}
+
+	if err := playlist.RemoveTrack(trackID); err != nil {
+		return err
+	}
+
+	id, _ := scriptPlaylistID(playlistID)
+	script := fmt.Sprintf(`
+		tell application "Music"
+			delete (first track of (first user playlist whose persistent ID is %s) whose database ID is %s)
+		end tell
+	`, id, trackRef)
+
+	result := p.executor.Execute(ctx, script)
+	if result.Error != nil {
+		return music.NewDomainErrorWithCause(music.ErrOperationFailed, "failed to remove track from playlist", result.Error).
+			WithContext("playlist_id", playlistID.Value()).
+			WithContext("track_id", trackID.Value())
+	}
+
+	return nil
+}
+
+// ReorderPlaylistTracks rebuilds a playlist from trackIDs, in order, so the
+// list may insert, remove and repeat tracks.
+func (p *PlaylistRepository) ReorderPlaylistTracks(ctx context.Context, playlistID music.PlaylistID, trackIDs []music.TrackID) error {
+	id, err := p.writablePlaylist(ctx, playlistID)
+	if err != nil {
+		return err
+	}
+
+	idList, err := scriptTrackIDList(trackIDs)
+	if err != nil {
+		return err
+	}
+
+	script := rebuildHandler + fmt.Sprintf(`
+		tell application "Music"
+			my rebuildPlaylist((first user playlist whose persistent ID is %s), %s)
+		end tell
+	`, id, idList)
+
+	result := p.executor.Execute(ctx, script)
+	if result.Error != nil {
+		return music.NewDomainErrorWithCause(music.ErrOperationFailed, "failed to reorder playlist", result.Error).
+			WithContext("playlist_id", playlistID.Value())
+	}
+
+	return nil
+}
+
+// DuplicatePlaylist creates a user playlist containing the same tracks as an existing one.
+func (p *PlaylistRepository) DuplicatePlaylist(ctx context.Context, playlistID music.PlaylistID, newName string) (*music.Playlist, error) {
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return nil, music.NewDomainError(music.ErrInvalidPlaylist, "playlist name cannot be empty")
+	}
+
+	id, err := scriptPlaylistID(playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	script := playlistRecordHandler + fmt.Sprintf(`
+		tell application "Music"
+			try
+				set source to first playlist whose persistent ID is %s
+			on error
+				error "playlist not found"
+			end try
+			set copyList to make new user playlist with properties {name:%s}
+			duplicate every track of source to copyList
+			return my playlistRecord(copyList)
+		end tell
+	`, id, quoteString(newName))
+
+	result := p.executor.Execute(ctx, script)
+	if result.Error != nil {
+		if strings.Contains(result.Error.Error(), "playlist not found") {
+			return nil, music.WrapPlaylistNotFound(playlistID, result.Error)
+		}
+		return nil, music.NewDomainErrorWithCause(music.ErrOperationFailed, "failed to duplicate playlist", result.Error).
+			WithContext("playlist_id", playlistID.Value())
+	}
+
+	return parsePlaylistRecord(result.Output)
+}
+
+// writablePlaylist loads a playlist, refuses read-only ones and returns its
+// script-safe ID.
+func (p *PlaylistRepository) writablePlaylist(ctx context.Context, playlistID music.PlaylistID) (string, error) {
+	playlist, err := p.library.GetPlaylist(ctx, playlistID)
+	if err != nil {
+		return "", err
+	}
+
+	if playlist.ReadOnly {
+		return "", music.NewDomainError(
+			music.ErrPlaylistReadOnly,
+			fmt.Sprintf("playlist '%s' is a %s playlist and cannot be modified", playlist.Name, playlist.Type.String()),
+		).WithContext("playlist_id", playlistID.Value())
+	}
+
+	return scriptPlaylistID(playlistID)
+}
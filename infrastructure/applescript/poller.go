@@ -0,0 +1,226 @@
+package applescript
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/pkg/logger"
+)
+
+// PollerConfig holds configuration for the player event poller.
+type PollerConfig struct {
+	// PlayingInterval is the delay between polls while a track is playing
+	PlayingInterval time.Duration
+
+	// IdleInterval is the delay between polls while paused, stopped or after an error
+	IdleInterval time.Duration
+
+	// QueueEvery checks the queue on every Nth poll (0 disables), and whenever the track changes
+	QueueEvery int
+
+	// BufferSize is the number of events buffered per subscriber before events are dropped
+	BufferSize int
+
+	// Logger receives poll failures and dropped events
+	Logger logger.Logger
+}
+
+// DefaultPollerConfig returns a default configuration for the poller.
+func DefaultPollerConfig() *PollerConfig {
+	return &PollerConfig{
+		PlayingInterval: time.Second,
+		IdleInterval:    5 * time.Second,
+		QueueEvery:      5,
+		BufferSize:      32,
+	}
+}
+
+// Poller derives domain events from Music.app by polling the player and queue
+// and comparing successive snapshots. Music.app offers no change notifications,
+// so the poll interval adapts to the player state: frequent while playing,
+// relaxed while idle.
+type Poller struct {
+	config *PollerConfig
+	player music.PlayerRepository
+	queue  music.QueueRepository
+	log    logger.Logger
+
+	mu          sync.Mutex
+	subscribers map[chan music.Event]struct{}
+
+	// subscribed wakes Run when the first subscriber arrives
+	subscribed chan struct{}
+
+	// Poll state, owned by the Run goroutine
+	last      *music.Player
+	lastQueue []music.TrackID
+	lastPos   int
+	queueSeen bool
+	polls     int
+}
+
+// NewPoller creates a poller over the given repositories.
+func NewPoller(player music.PlayerRepository, queue music.QueueRepository, config *PollerConfig) *Poller {
+	if config == nil {
+		config = DefaultPollerConfig()
+	}
+
+	log := config.Logger
+	if log == nil {
+		log = logger.Component("poller")
+	}
+
+	return &Poller{
+		config:      config,
+		player:      player,
+		queue:       queue,
+		log:         log,
+		subscribers: make(map[chan music.Event]struct{}),
+		subscribed:  make(chan struct{}, 1),
+	}
+}
+
+var _ music.EventSource = (*Poller)(nil)
+
+// Subscribe returns a channel of events that is closed when ctx is done.
+// Slow subscribers miss events rather than stalling the poller.
+func (p *Poller) Subscribe(ctx context.Context) <-chan music.Event {
+	ch := make(chan music.Event, p.config.BufferSize)
+
+	p.mu.Lock()
+	p.subscribers[ch] = struct{}{}
+	p.mu.Unlock()
+
+	select {
+	case p.subscribed <- struct{}{}:
+	default:
+	}
+
+	go func() {
+		<-ctx.Done()
+		p.mu.Lock()
+		delete(p.subscribers, ch)
+		close(ch)
+		p.mu.Unlock()
+	}()
+
+	return ch
+}
+
+// Run polls until ctx is done. It polls only while someone is subscribed,
+// so an idle poller runs no scripts.
+func (p *Poller) Run(ctx context.Context) error {
+	for {
+		if !p.hasSubscribers() {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-p.subscribed:
+			}
+			continue
+		}
+
+		interval := p.poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// poll takes one snapshot, publishes the resulting events and returns the
+// delay before the next poll.
+func (p *Poller) poll(ctx context.Context) time.Duration {
+	current, err := p.player.GetCurrentState(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			p.log.Debug("Player poll failed", logger.Error(err))
+		}
+		return p.config.IdleInterval
+	}
+
+	now := time.Now()
+	events := music.PlayerEvents(p.last, current, now)
+	p.last = current
+
+	trackChanged := false
+	for _, event := range events {
+		if event.Type == music.EventTrackChanged {
+			trackChanged = true
+		}
+		p.publish(event)
+	}
+
+	p.polls++
+	if p.queue != nil && (trackChanged || !p.queueSeen || (p.config.QueueEvery > 0 && p.polls%p.config.QueueEvery == 0)) {
+		p.pollQueue(ctx, now)
+	}
+
+	if current.IsPlaying() {
+		return p.config.PlayingInterval
+	}
+	return p.config.IdleInterval
+}
+
+// pollQueue publishes a queue event if the queue contents or position changed.
+func (p *Poller) pollQueue(ctx context.Context, now time.Time) {
+	queue, err := p.queue.GetQueue(ctx)
+	if err != nil {
+		p.log.Debug("Queue poll failed", logger.Error(err))
+		return
+	}
+
+	position, err := p.queue.GetQueuePosition(ctx)
+	if err != nil {
+		p.log.Debug("Queue position poll failed", logger.Error(err))
+		return
+	}
+
+	changed := position != p.lastPos || !trackIDsEqual(queue.Tracks, p.lastQueue)
+	first := !p.queueSeen
+
+	p.lastQueue = append([]music.TrackID{}, queue.Tracks...)
+	p.lastPos = position
+	p.queueSeen = true
+
+	if changed && !first {
+		p.publish(music.NewQueueChangedEvent(p.lastQueue, position, now))
+	}
+}
+
+// hasSubscribers reports whether anyone is subscribed.
+func (p *Poller) hasSubscribers() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.subscribers) > 0
+}
+
+// publish delivers an event to every subscriber without blocking.
+func (p *Poller) publish(event music.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for ch := range p.subscribers {
+		select {
+		case ch <- event:
+		default:
+			p.log.Warn("Dropped event for slow subscriber", logger.String("event", string(event.Type)))
+		}
+	}
+}
+
+func trackIDsEqual(a, b []music.TrackID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equals(b[i]) {
+			return false
+		}
+	}
+	return true
+}
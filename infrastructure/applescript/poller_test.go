@@ -0,0 +1,122 @@
+package applescript
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// scriptedPlayer reports a stopped player on the first poll and a playing
+// one afterwards, counting the polls.
+type scriptedPlayer struct {
+	music.PlayerRepository
+	polls atomic.Int32
+}
+
+func (p *scriptedPlayer) GetCurrentState(context.Context) (*music.Player, error) {
+	player := music.NewPlayer()
+	if p.polls.Add(1) > 1 {
+		trackID := music.NewTrackID("1001")
+		player.State = music.PlayerStatePlaying
+		player.CurrentTrack = &trackID
+	}
+	return player, nil
+}
+
+// growingQueue holds one more track each time it is read.
+type growingQueue struct {
+	music.QueueRepository
+	reads atomic.Int32
+}
+
+func (q *growingQueue) GetQueue(context.Context) (*music.Playlist, error) {
+	queue, _ := music.NewPlaylist(music.NewPlaylistID("QUEUE"), queuePlaylistName, music.PlaylistTypeQueue, true)
+	for range q.reads.Add(1) {
+		queue.Tracks = append(queue.Tracks, music.NewTrackID("1001"))
+	}
+	return queue, nil
+}
+
+func (q *growingQueue) GetQueuePosition(context.Context) (int, error) {
+	return 0, nil
+}
+
+func testPollerConfig() *PollerConfig {
+	return &PollerConfig{PlayingInterval: time.Millisecond, IdleInterval: time.Millisecond, QueueEvery: 1, BufferSize: 64}
+}
+
+// nextEvent waits for an event of type want, skipping others.
+func nextEvent(t *testing.T, events <-chan music.Event, want music.EventType) music.Event {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatalf("events closed before %s", want)
+			}
+			if event.Type == want {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("no %s event", want)
+		}
+	}
+}
+
+func TestPollerPollsOnlyWhileSubscribed(t *testing.T) {
+	player := &scriptedPlayer{}
+	poller := NewPoller(player, nil, testPollerConfig())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- poller.Run(ctx) }()
+
+	time.Sleep(20 * time.Millisecond)
+	if polls := player.polls.Load(); polls != 0 {
+		t.Fatalf("expected no polls without subscribers, got %d", polls)
+	}
+
+	subCtx, unsubscribe := context.WithCancel(context.Background())
+	events := poller.Subscribe(subCtx)
+	event := nextEvent(t, events, music.EventTrackChanged)
+	if event.Player == nil || event.Player.CurrentTrack == nil || event.Player.CurrentTrack.Value() != "1001" {
+		t.Errorf("unexpected track change %+v", event)
+	}
+
+	unsubscribe()
+	for range events {
+	}
+	// A poll already under way may finish; none start after it
+	time.Sleep(10 * time.Millisecond)
+	polls := player.polls.Load()
+	time.Sleep(20 * time.Millisecond)
+	if after := player.polls.Load(); after != polls {
+		t.Errorf("expected polling to stop with the last subscriber, went from %d to %d polls", polls, after)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("expected Run to end with the context, got %v", err)
+	}
+}
+
+func TestPollerQueueEvents(t *testing.T) {
+	queue := &growingQueue{}
+	poller := NewPoller(&scriptedPlayer{}, queue, testPollerConfig())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := poller.Subscribe(ctx)
+	go func() { _ = poller.Run(ctx) }()
+
+	// The first sighting of the queue is not a change
+	event := nextEvent(t, events, music.EventQueueChanged)
+	if len(event.QueueTracks) < 2 {
+		t.Errorf("expected the queue event to follow a change, got %d tracks", len(event.QueueTracks))
+	}
+}
@@ -0,0 +1,336 @@
+package applescript
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// queuePlaylistName is the name of the user playlist maestro uses as its play queue.
+// Music.app does not expose "Up Next" to AppleScript, so queue operations are
+// implemented on a dedicated playlist that is played from when the queue starts.
+const queuePlaylistName = "Maestro Queue"
+
+// queuePlaylistHandler is an AppleScript handler that returns the queue playlist,
+// creating it on first use.
+var queuePlaylistHandler = fmt.Sprintf(`
+on queuePlaylist()
+	tell application "Music"
+		try
+			return first user playlist whose name is %[1]s
+		on error
+			return make new user playlist with properties {name:%[1]s}
+		end try
+	end tell
+end queuePlaylist
+`, quoteString(queuePlaylistName))
+
+// rebuildHandler is an AppleScript handler that replaces the contents of a playlist
+// with the given library tracks, in order. Music.app has no insert-at-index
+// command, so reordering is done by clearing and re-adding.
+const rebuildHandler = `
+on rebuildPlaylist(p, trackIDs)
+	tell application "Music"
+		delete every track of p
+		repeat with trackID in trackIDs
+			duplicate (first track of library playlist 1 whose database ID is (trackID as integer)) to p
+		end repeat
+	end tell
+end rebuildPlaylist
+`
+
+// QueueRepository implements the music.QueueRepository interface using AppleScript.
+type QueueRepository struct {
+	executor *Executor
+	library  *LibraryRepository
+}
+
+// NewQueueRepository creates a new AppleScript-based queue repository.
+func NewQueueRepository(executor *Executor) *QueueRepository {
+	if executor == nil {
+		executor = NewExecutor(nil)
+	}
+
+	return &QueueRepository{
+		executor: executor,
+		library:  NewLibraryRepository(executor),
+	}
+}
+
+// GetQueue returns the current playback queue.
+func (q *QueueRepository) GetQueue(ctx context.Context) (*music.Playlist, error) {
+	script := queuePlaylistHandler + playlistRecordHandler + `
+		tell application "Music"
+			return my playlistRecord(my queuePlaylist())
+		end tell
+	`
+
+	result := q.executor.Execute(ctx, script)
+	if result.Error != nil {
+		return nil, music.NewDomainErrorWithCause(music.ErrOperationFailed, "failed to get queue", result.Error)
+	}
+
+	return parsePlaylistRecord(result.Output)
+}
+
+// AddToQueue adds a track to the end of the queue.
+func (q *QueueRepository) AddToQueue(ctx context.Context, trackID music.TrackID) error {
+	return q.AddTracksToQueue(ctx, []music.TrackID{trackID})
+}
+
+// AddTracksToQueue adds multiple tracks to the end of the queue.
+func (q *QueueRepository) AddTracksToQueue(ctx context.Context, trackIDs []music.TrackID) error {
+	if len(trackIDs) == 0 {
+		return music.NewDomainError(music.ErrInvalidTrackID, "no tracks to add to queue")
+	}
+
+	idList, err := scriptTrackIDList(trackIDs)
+	if err != nil {
+		return err
+	}
+
+	script := queuePlaylistHandler + fmt.Sprintf(`
+		tell application "Music"
+			set p to my queuePlaylist()
+			repeat with trackID in %s
+				duplicate (first track of library playlist 1 whose database ID is (trackID as integer)) to p
+			end repeat
+		end tell
+	`, idList)
+
+	result := q.executor.Execute(ctx, script)
+	if result.Error != nil {
+		return music.NewDomainErrorWithCause(music.ErrOperationFailed, "failed to add tracks to queue", result.Error)
+	}
+
+	return nil
+}
+
+// PlayNext adds a track to play immediately after the current track.
+func (q *QueueRepository) PlayNext(ctx context.Context, trackID music.TrackID) error {
+	if _, err := scriptTrackID(trackID); err != nil {
+		return err
+	}
+
+	queue, position, err := q.snapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	insertAt := position + 1
+	if insertAt > len(queue.Tracks) {
+		insertAt = len(queue.Tracks)
+	}
+
+	reordered := make([]music.TrackID, 0, len(queue.Tracks)+1)
+	reordered = append(reordered, queue.Tracks[:insertAt]...)
+	reordered = append(reordered, trackID)
+	reordered = append(reordered, queue.Tracks[insertAt:]...)
+
+	return q.rebuild(ctx, reordered, "failed to add track to play next")
+}
+
+// PlayLater adds a track to the end of the queue (same as AddToQueue).
+func (q *QueueRepository) PlayLater(ctx context.Context, trackID music.TrackID) error {
+	return q.AddToQueue(ctx, trackID)
+}
+
+// RemoveFromQueue removes a track from the queue by position.
+func (q *QueueRepository) RemoveFromQueue(ctx context.Context, position int) error {
+	queue, err := q.GetQueue(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := validateQueuePosition(position, queue.TrackCount()); err != nil {
+		return err
+	}
+
+	script := queuePlaylistHandler + fmt.Sprintf(`
+		tell application "Music"
+			delete track %d of my queuePlaylist()
+		end tell
+	`, position+1)
+
+	result := q.executor.Execute(ctx, script)
+	if result.Error != nil {
+		return music.NewDomainErrorWithCause(music.ErrOperationFailed, fmt.Sprintf("failed to remove queue position %d", position), result.Error)
+	}
+
+	return nil
+}
+
+// ClearQueue removes all tracks from the queue.
+func (q *QueueRepository) ClearQueue(ctx context.Context) error {
+	script := queuePlaylistHandler + `
+		tell application "Music"
+			delete every track of my queuePlaylist()
+		end tell
+	`
+
+	result := q.executor.Execute(ctx, script)
+	if result.Error != nil {
+		return music.NewDomainErrorWithCause(music.ErrOperationFailed, "failed to clear queue", result.Error)
+	}
+
+	return nil
+}
+
+// ShuffleQueue randomizes the order of the tracks that have not played yet.
+func (q *QueueRepository) ShuffleQueue(ctx context.Context) error {
+	queue, position, err := q.snapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	if queue.IsEmpty() {
+		return music.NewDomainError(music.ErrQueueEmpty, "cannot shuffle an empty queue")
+	}
+
+	start := position + 1
+	if start > len(queue.Tracks) {
+		start = len(queue.Tracks)
+	}
+
+	reordered := append([]music.TrackID(nil), queue.Tracks...)
+	upcoming := reordered[start:]
+	rand.Shuffle(len(upcoming), func(i, j int) {
+		upcoming[i], upcoming[j] = upcoming[j], upcoming[i]
+	})
+
+	return q.rebuild(ctx, reordered, "failed to shuffle queue")
+}
+
+// GetQueuePosition returns the current position in the queue (0-based), or -1
+// when the queue is not what is currently playing.
+func (q *QueueRepository) GetQueuePosition(ctx context.Context) (int, error) {
+	script := queuePlaylistHandler + `
+		tell application "Music"
+			if player state is stopped then return -1
+			try
+				if (persistent ID of current playlist) is not (persistent ID of my queuePlaylist()) then return -1
+				return index of current track
+			on error
+				return -1
+			end try
+		end tell
+	`
+
+	result := q.executor.Execute(ctx, script)
+	if result.Error != nil {
+		return 0, music.NewDomainErrorWithCause(music.ErrOperationFailed, "failed to get queue position", result.Error)
+	}
+
+	index, err := strconv.Atoi(strings.TrimSpace(result.Output))
+	if err != nil {
+		return 0, music.NewDomainErrorWithCause(music.ErrOperationFailed, "invalid queue position format", err)
+	}
+
+	if index < 0 {
+		return -1, nil
+	}
+	return index - 1, nil
+}
+
+// SetQueuePosition starts playing the queue from a specific position.
+func (q *QueueRepository) SetQueuePosition(ctx context.Context, position int) error {
+	queue, err := q.GetQueue(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := validateQueuePosition(position, queue.TrackCount()); err != nil {
+		return err
+	}
+
+	script := queuePlaylistHandler + fmt.Sprintf(`
+		tell application "Music"
+			play track %d of my queuePlaylist()
+		end tell
+	`, position+1)
+
+	result := q.executor.Execute(ctx, script)
+	if result.Error != nil {
+		return music.NewDomainErrorWithCause(music.ErrOperationFailed, fmt.Sprintf("failed to jump to queue position %d", position), result.Error)
+	}
+
+	return nil
+}
+
+// GetUpNext returns the next few tracks that will play from the queue.
+func (q *QueueRepository) GetUpNext(ctx context.Context, count int) ([]*music.Track, error) {
+	if count < 0 {
+		return nil, music.NewDomainError(music.ErrInvalidOperation, "count cannot be negative")
+	}
+
+	queue, position, err := q.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	start := position + 1
+	if start >= len(queue.Tracks) {
+		return []*music.Track{}, nil
+	}
+
+	upcoming := queue.Tracks[start:]
+	if count > 0 && count < len(upcoming) {
+		upcoming = upcoming[:count]
+	}
+
+	return q.library.GetTracks(ctx, upcoming)
+}
+
+// snapshot returns the queue together with the current position in it.
+func (q *QueueRepository) snapshot(ctx context.Context) (*music.Playlist, int, error) {
+	queue, err := q.GetQueue(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	position, err := q.GetQueuePosition(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return queue, position, nil
+}
+
+// rebuild replaces the queue contents with the given tracks.
+func (q *QueueRepository) rebuild(ctx context.Context, trackIDs []music.TrackID, failure string) error {
+	idList, err := scriptTrackIDList(trackIDs)
+	if err != nil {
+		return err
+	}
+
+	script := queuePlaylistHandler + rebuildHandler + fmt.Sprintf(`
+		tell application "Music"
+			my rebuildPlaylist(my queuePlaylist(), %s)
+		end tell
+	`, idList)
+
+	result := q.executor.Execute(ctx, script)
+	if result.Error != nil {
+		return music.NewDomainErrorWithCause(music.ErrOperationFailed, failure, result.Error)
+	}
+
+	return nil
+}
+
+// validateQueuePosition checks a 0-based position against the queue length.
+func validateQueuePosition(position, length int) error {
+	if length == 0 {
+		return music.NewDomainError(music.ErrQueueEmpty, "queue is empty")
+	}
+	if position < 0 || position >= length {
+		return music.NewDomainError(
+			music.ErrInvalidQueuePosition,
+			fmt.Sprintf("position %d is out of range (queue has %d tracks)", position, length),
+		).WithContext("position", position)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package applescript
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// trackMissing is what a rating script returns when the track does not exist.
+const trackMissing = "missing"
+
+// RatingRepository implements the music.RatingRepository interface using
+// AppleScript to rate library tracks.
+type RatingRepository struct {
+	executor *Executor
+	library  *LibraryRepository
+}
+
+// NewRatingRepository creates a new AppleScript-based rating repository.
+func NewRatingRepository(executor *Executor) *RatingRepository {
+	if executor == nil {
+		executor = NewExecutor(nil)
+	}
+
+	return &RatingRepository{
+		executor: executor,
+		library:  NewLibraryRepository(executor),
+	}
+}
+
+// SetRating sets the rating of a track; a zero rating clears it.
+func (r *RatingRepository) SetRating(ctx context.Context, trackID music.TrackID, rating music.Rating) error {
+	if !rating.IsValid() {
+		return music.WrapInvalidRating(rating.Value(), nil)
+	}
+	return r.setTrack(ctx, trackID, fmt.Sprintf("set rating of t to %d", rating.Value()), "failed to rate track")
+}
+
+// SetPreference marks a track loved or disliked, or clears both. Versions of
+// Music.app that cannot script disliked tracks only change the loved flag.
+func (r *RatingRepository) SetPreference(ctx context.Context, trackID music.TrackID, preference music.Preference) error {
+	var metadata music.TrackMetadata
+	if err := metadata.SetPreference(preference); err != nil {
+		return err
+	}
+
+	// Clear loved first, so that Music.app never sees both flags set
+	statements := fmt.Sprintf(`set loved of t to %t
+			try
+				set disliked of t to %t
+			end try`, metadata.Loved, metadata.Disliked)
+	return r.setTrack(ctx, trackID, statements, "failed to set track preference")
+}
+
+// GetTracksByRating returns the tracks rated between minimum and maximum
+// inclusive, best rated first.
+func (r *RatingRepository) GetTracksByRating(ctx context.Context, minimum, maximum music.Rating) ([]*music.Track, error) {
+	if err := music.CheckRatingRange(minimum, maximum); err != nil {
+		return nil, err
+	}
+
+	tracks, err := r.library.tracksWhere(ctx, fmt.Sprintf("rating ≥ %d and rating ≤ %d", minimum.Value(), maximum.Value()))
+	if err != nil {
+		return nil, err
+	}
+	return music.TracksByRating(tracks, minimum, maximum)
+}
+
+// setTrack runs statements against the library track t with trackID.
+func (r *RatingRepository) setTrack(ctx context.Context, trackID music.TrackID, statements, failure string) error {
+	trackRef, err := scriptTrackID(trackID)
+	if err != nil {
+		return err
+	}
+
+	script := fmt.Sprintf(`
+		tell application "Music"
+			set matches to (every track of library playlist 1 whose database ID is %s)
+			if matches is {} then return %s
+			set t to item 1 of matches
+			%s
+		end tell
+	`, trackRef, quoteString(trackMissing), statements)
+
+	result := r.executor.Execute(ctx, script)
+	if result.Error != nil {
+		return music.NewDomainErrorWithCause(music.ErrOperationFailed, failure, result.Error).
+			WithContext("track_id", trackID.Value())
+	}
+	if strings.TrimSpace(result.Output) == trackMissing {
+		return music.WrapTrackNotFound(trackID, nil)
+	}
+	return nil
+}
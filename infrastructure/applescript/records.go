@@ -0,0 +1,268 @@
+package applescript
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// Scripts that return several values join fields with the ASCII unit separator
+// and records with the ASCII record separator, so that titles containing "|"
+// or newlines survive the round trip through osascript.
+const (
+	fieldSeparator  = "\x1f"
+	recordSeparator = "\x1e"
+
+	// unknownArtist is used for library tracks that have no artist set,
+	// since music.NewTrack requires one.
+	unknownArtist = "Unknown Artist"
+)
+
+// joinRecordsHandler is an AppleScript handler that joins a list of records with
+// the record separator. Scripts call it as "my joinRecords(theList)".
+const joinRecordsHandler = `
+on joinRecords(theList)
+	set AppleScript's text item delimiters to character id 30
+	set joined to theList as string
+	set AppleScript's text item delimiters to ""
+	return joined
+end joinRecords
+`
+
+// trackRecordHandler is an AppleScript handler that serializes a track into
+// a single record. Scripts that return tracks must include it and call
+// "my trackRecord(t)" from within their tell block. It includes joinRecordsHandler.
+// Dates are sent as seconds before now, which unlike date strings do not
+// depend on the user's locale. Music.app does not script the explicit flag.
+const trackRecordHandler = joinRecordsHandler + `
+on trackRecord(t)
+	set fs to character id 31
+	set nowDate to current date
+	tell application "Music"
+		set trackDuration to duration of t
+		if trackDuration is missing value then set trackDuration to 0
+		set isLoved to false
+		try
+			set isLoved to loved of t
+		end try
+		set isDisliked to false
+		try
+			set isDisliked to disliked of t
+		end try
+		set details to my textOf(album artist of t) & fs & my textOf(genre of t) & fs & my textOf(year of t) ¬
+			& fs & my textOf(track number of t) & fs & my textOf(track count of t) ¬
+			& fs & my textOf(disc number of t) & fs & my textOf(disc count of t) ¬
+			& fs & my textOf(composer of t) & fs & my textOf(rating of t) & fs & (isLoved as string) ¬
+			& fs & my textOf(played count of t) & fs & my textOf(skipped count of t) ¬
+			& fs & my secondsBefore(played date of t, nowDate) & fs & my secondsBefore(date added of t, nowDate) ¬
+			& fs & my textOf(bit rate of t) & fs & my textOf(kind of t) & fs & (isDisliked as string)
+		return ((database ID of t) as string) & fs & (name of t) & fs & (artist of t) & fs & (album of t) & fs & (trackDuration as string) & fs & details
+	end tell
+end trackRecord
+
+on textOf(v)
+	if v is missing value then return ""
+	return v as string
+end textOf
+
+on secondsBefore(d, nowDate)
+	if d is missing value then return ""
+	return ((nowDate - d) as integer) as string
+end secondsBefore
+`
+
+// trackRecordFieldCount is the number of fields produced by trackRecordHandler.
+const trackRecordFieldCount = 22
+
+var (
+	numericIDPattern    = regexp.MustCompile(`^[0-9]+$`)
+	persistentIDPattern = regexp.MustCompile(`^[0-9A-Fa-f]+$`)
+)
+
+// quoteString returns s as a double-quoted AppleScript string literal.
+func quoteString(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + replacer.Replace(s) + `"`
+}
+
+// scriptTrackID validates that a track ID is a Music.app database ID and returns it
+// in a form that is safe to interpolate into a script.
+func scriptTrackID(trackID music.TrackID) (string, error) {
+	if trackID.IsEmpty() {
+		return "", music.NewDomainError(music.ErrInvalidTrackID, "track ID cannot be empty")
+	}
+	if !numericIDPattern.MatchString(trackID.Value()) {
+		return "", music.NewDomainError(
+			music.ErrInvalidTrackID,
+			fmt.Sprintf("track ID '%s' is not a Music.app database ID", trackID.Value()),
+		).WithContext("track_id", trackID.Value())
+	}
+	return trackID.Value(), nil
+}
+
+// scriptTrackIDList validates track IDs and renders them as an AppleScript list literal.
+func scriptTrackIDList(trackIDs []music.TrackID) (string, error) {
+	ids := make([]string, 0, len(trackIDs))
+	for _, trackID := range trackIDs {
+		id, err := scriptTrackID(trackID)
+		if err != nil {
+			return "", err
+		}
+		ids = append(ids, id)
+	}
+	return "{" + strings.Join(ids, ", ") + "}", nil
+}
+
+// scriptPlaylistID validates that a playlist ID is a Music.app persistent ID and
+// returns it as a quoted string literal.
+func scriptPlaylistID(playlistID music.PlaylistID) (string, error) {
+	if playlistID.IsEmpty() {
+		return "", music.NewDomainError(music.ErrInvalidPlaylistID, "playlist ID cannot be empty")
+	}
+	if !persistentIDPattern.MatchString(playlistID.Value()) {
+		return "", music.NewDomainError(
+			music.ErrInvalidPlaylistID,
+			fmt.Sprintf("playlist ID '%s' is not a Music.app persistent ID", playlistID.Value()),
+		).WithContext("playlist_id", playlistID.Value())
+	}
+	return quoteString(strings.ToUpper(playlistID.Value())), nil
+}
+
+// splitRecords splits script output into records, ignoring empty ones.
+func splitRecords(output string) []string {
+	output = strings.Trim(output, "\r\n")
+	if output == "" {
+		return nil
+	}
+
+	parts := strings.Split(output, recordSeparator)
+	records := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part != "" {
+			records = append(records, part)
+		}
+	}
+	return records
+}
+
+// parseSeconds parses a number of seconds as printed by AppleScript, which may use
+// a locale-specific decimal comma or be "missing value".
+func parseSeconds(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "missing value" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+}
+
+// parseTrackRecord parses a record produced by trackRecordHandler.
+func parseTrackRecord(record string) (*music.Track, error) {
+	fields := strings.Split(strings.TrimRight(record, "\r\n"), fieldSeparator)
+	if len(fields) != trackRecordFieldCount {
+		return nil, music.NewDomainError(music.ErrOperationFailed, "invalid track record format")
+	}
+
+	seconds, err := parseSeconds(fields[4])
+	if err != nil {
+		return nil, music.NewDomainErrorWithCause(music.ErrOperationFailed, "invalid track duration format", err)
+	}
+
+	artist := fields[2]
+	if strings.TrimSpace(artist) == "" {
+		artist = unknownArtist
+	}
+
+	metadata, err := parseTrackMetadata(fields[5:], time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return music.NewTrackWithMetadata(music.NewTrackID(fields[0]), fields[1], artist, fields[3], music.NewDurationFromMillis(int64(math.Round(seconds*1000))), metadata)
+}
+
+// parseTrackMetadata parses the detail fields of a track record. Dates are
+// relative to now.
+func parseTrackMetadata(fields []string, now time.Time) (music.TrackMetadata, error) {
+	var err error
+	count := func(field string) int {
+		n, fieldErr := parseCount(field)
+		if fieldErr != nil && err == nil {
+			err = fieldErr
+		}
+		return n
+	}
+	secondsBefore := func(field string) time.Time {
+		if strings.TrimSpace(field) == "" {
+			return time.Time{}
+		}
+		return now.Add(-time.Duration(count(field)) * time.Second).Truncate(time.Second)
+	}
+
+	metadata := music.TrackMetadata{
+		AlbumArtist: fields[0],
+		Genre:       fields[1],
+		Year:        count(fields[2]),
+		TrackNumber: count(fields[3]),
+		TrackCount:  count(fields[4]),
+		DiscNumber:  count(fields[5]),
+		DiscCount:   count(fields[6]),
+		Composer:    fields[7],
+		Rating:      music.NewRating(count(fields[8])),
+		Loved:       fields[9] == "true",
+		PlayCount:   count(fields[10]),
+		SkipCount:   count(fields[11]),
+		LastPlayed:  secondsBefore(fields[12]),
+		DateAdded:   secondsBefore(fields[13]),
+		BitRate:     count(fields[14]),
+		Kind:        fields[15],
+		Disliked:    fields[16] == "true" && fields[9] != "true",
+	}
+	if err != nil {
+		return music.TrackMetadata{}, music.NewDomainErrorWithCause(music.ErrOperationFailed, "invalid track record format", err)
+	}
+	return metadata, nil
+}
+
+// parseCount parses a whole number as printed by AppleScript, where an
+// empty field or "missing value" is 0.
+func parseCount(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "missing value" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// parseTrackRecords parses every track record in the script output.
+func parseTrackRecords(output string) ([]*music.Track, error) {
+	records := splitRecords(output)
+	tracks := make([]*music.Track, 0, len(records))
+	for _, record := range records {
+		track, err := parseTrackRecord(record)
+		if err != nil {
+			return nil, err
+		}
+		tracks = append(tracks, track)
+	}
+	return tracks, nil
+}
+
+// paginate applies offset and limit (0 = no limit) to a slice of tracks.
+func paginate(tracks []*music.Track, limit, offset int) []*music.Track {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(tracks) {
+		return []*music.Track{}
+	}
+	tracks = tracks[offset:]
+	if limit > 0 && limit < len(tracks) {
+		tracks = tracks[:limit]
+	}
+	return tracks
+}
@@ -0,0 +1,265 @@
+package applescript
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+func TestScriptTrackID(t *testing.T) {
+	tests := []struct {
+		id    string
+		valid bool
+	}{
+		{"1234", true},
+		{"0", true},
+		{"", false},
+		{"12a", false},
+		{"1A2B3C4D5E6F7A8B", false},
+		{"-1", false},
+		{"1.5", false},
+		{"1\n2", false},
+		{"1 or true", false},
+		{`1" & (do shell script "id") & "`, false},
+		{"１２", false},
+	}
+	for _, tt := range tests {
+		got, err := scriptTrackID(music.NewTrackID(tt.id))
+		if tt.valid {
+			if err != nil || got != tt.id {
+				t.Errorf("scriptTrackID(%q) = %q, %v; want %q", tt.id, got, err, tt.id)
+			}
+			continue
+		}
+		if !errors.Is(err, music.ErrInvalidTrackID) {
+			t.Errorf("scriptTrackID(%q): expected ErrInvalidTrackID, got %q, %v", tt.id, got, err)
+		}
+	}
+}
+
+func TestScriptTrackIDList(t *testing.T) {
+	tests := []struct {
+		ids  []string
+		want string
+	}{
+		{nil, "{}"},
+		{[]string{"7"}, "{7}"},
+		{[]string{"1", "22", "333"}, "{1, 22, 333}"},
+	}
+	for _, tt := range tests {
+		trackIDs := make([]music.TrackID, len(tt.ids))
+		for i, id := range tt.ids {
+			trackIDs[i] = music.NewTrackID(id)
+		}
+		if got, err := scriptTrackIDList(trackIDs); err != nil || got != tt.want {
+			t.Errorf("scriptTrackIDList(%v) = %q, %v; want %q", tt.ids, got, err, tt.want)
+		}
+	}
+
+	// One bad ID rejects the whole list
+	_, err := scriptTrackIDList([]music.TrackID{music.NewTrackID("1"), music.NewTrackID("2}, {3")})
+	if !errors.Is(err, music.ErrInvalidTrackID) {
+		t.Errorf("expected ErrInvalidTrackID, got %v", err)
+	}
+}
+
+func TestScriptPlaylistID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"A1B2C3D4E5F60001", `"A1B2C3D4E5F60001"`},
+		{"a1b2c3d4e5f60001", `"A1B2C3D4E5F60001"`},
+		{"", ""},
+		{"XYZ", ""},
+		{`A1" & quit & "`, ""},
+	}
+	for _, tt := range tests {
+		got, err := scriptPlaylistID(music.NewPlaylistID(tt.id))
+		if tt.want == "" {
+			if !errors.Is(err, music.ErrInvalidPlaylistID) {
+				t.Errorf("scriptPlaylistID(%q): expected ErrInvalidPlaylistID, got %q, %v", tt.id, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("scriptPlaylistID(%q) = %q, %v; want %q", tt.id, got, err, tt.want)
+		}
+	}
+}
+
+func TestQuoteString(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Kind of Blue", `"Kind of Blue"`},
+		{`Say "Hello"`, `"Say \"Hello\""`},
+		{`back\slash`, `"back\\slash"`},
+		{`\"`, `"\\\""`},
+	}
+	for _, tt := range tests {
+		if got := quoteString(tt.in); got != tt.want {
+			t.Errorf("quoteString(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSplitRecords(t *testing.T) {
+	tests := []struct {
+		output string
+		want   []string
+	}{
+		{"", nil},
+		{"\n", nil},
+		{"a", []string{"a"}},
+		{"a\x1eb\x1e\x1ec\n", []string{"a", "b", "c"}},
+		{"line\nbreak\x1ex|y", []string{"line\nbreak", "x|y"}},
+	}
+	for _, tt := range tests {
+		if got := splitRecords(tt.output); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitRecords(%q) = %q, want %q", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestParseSeconds(t *testing.T) {
+	tests := []struct {
+		value string
+		want  float64
+	}{
+		{"", 0},
+		{"missing value", 0},
+		{"42", 42},
+		{" 337.5 ", 337.5},
+		{"337,5", 337.5},
+	}
+	for _, tt := range tests {
+		if got, err := parseSeconds(tt.value); err != nil || got != tt.want {
+			t.Errorf("parseSeconds(%q) = %v, %v; want %v", tt.value, got, err, tt.want)
+		}
+	}
+	if _, err := parseSeconds("5:37"); err == nil {
+		t.Error("expected an error for a non-numeric duration")
+	}
+}
+
+// trackRecord joins the fields of a track record, with the details after
+// the first five.
+func trackRecord(fields ...string) string {
+	return strings.Join(fields, fieldSeparator)
+}
+
+// trackDetails are the detail fields of a track record, indexed as in
+// parseTrackMetadata.
+func trackDetails() []string {
+	return []string{
+		"Miles Davis", "Jazz", "1959", "3", "5", "1", "1", "Bill Evans", "80", "false",
+		"12", "2", "3600", "86400", "256", "AAC audio file", "false",
+	}
+}
+
+func TestParseTrackRecord(t *testing.T) {
+	record := trackRecord(append([]string{"1003", "Blue in Green", "Miles Davis", "Kind of Blue", "337,5"}, trackDetails()...)...)
+	track, err := parseTrackRecord(record + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if track.ID.Value() != "1003" || track.Title != "Blue in Green" || track.Artist != "Miles Davis" ||
+		track.Album != "Kind of Blue" || track.Duration.Milliseconds() != 337500 {
+		t.Errorf("unexpected track %+v", track)
+	}
+	if track.Year != 1959 || track.TrackNumber != 3 || track.Composer != "Bill Evans" ||
+		track.Rating.Value() != 80 || track.PlayCount != 12 || track.BitRate != 256 {
+		t.Errorf("unexpected metadata %+v", track.TrackMetadata)
+	}
+
+	// Tracks without an artist get a placeholder
+	record = trackRecord(append([]string{"1004", "Untitled", " ", "", "60"}, trackDetails()...)...)
+	if track, err := parseTrackRecord(record); err != nil || track.Artist != unknownArtist {
+		t.Errorf("expected %q for a missing artist, got %+v, %v", unknownArtist, track, err)
+	}
+
+	bad := map[string]string{
+		"too few fields":     trackRecord("1003", "Blue in Green", "Miles Davis", "Kind of Blue", "337"),
+		"invalid duration":   trackRecord(append([]string{"1003", "Blue in Green", "Miles Davis", "Kind of Blue", "long"}, trackDetails()...)...),
+		"invalid play count": trackRecord(append(append([]string{"1003", "Blue in Green", "Miles Davis", "Kind of Blue", "337"}, trackDetails()[:10]...), append([]string{"many"}, trackDetails()[11:]...)...)...),
+	}
+	for name, record := range bad {
+		if _, err := parseTrackRecord(record); !errors.Is(err, music.ErrOperationFailed) {
+			t.Errorf("%s: expected ErrOperationFailed, got %v", name, err)
+		}
+	}
+}
+
+func TestParseTrackMetadata(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	metadata, err := parseTrackMetadata(trackDetails(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !metadata.LastPlayed.Equal(now.Add(-time.Hour)) || !metadata.DateAdded.Equal(now.Add(-24*time.Hour)) {
+		t.Errorf("unexpected dates %s, %s", metadata.LastPlayed, metadata.DateAdded)
+	}
+
+	tests := []struct {
+		name     string
+		index    int
+		value    string
+		check    func(music.TrackMetadata) bool
+		describe string
+	}{
+		{"never played", 12, "", func(m music.TrackMetadata) bool { return m.LastPlayed.IsZero() }, "no last played date"},
+		{"missing count", 3, "missing value", func(m music.TrackMetadata) bool { return m.TrackNumber == 0 }, "track number 0"},
+		{"disliked", 16, "true", func(m music.TrackMetadata) bool { return m.Disliked && !m.Loved }, "disliked"},
+	}
+	for _, tt := range tests {
+		fields := trackDetails()
+		fields[tt.index] = tt.value
+		metadata, err := parseTrackMetadata(fields, now)
+		if err != nil || !tt.check(metadata) {
+			t.Errorf("%s: expected %s, got %+v, %v", tt.name, tt.describe, metadata, err)
+		}
+	}
+
+	// Loved wins over a stale disliked flag
+	fields := trackDetails()
+	fields[9], fields[16] = "true", "true"
+	if metadata, err := parseTrackMetadata(fields, now); err != nil || !metadata.Loved || metadata.Disliked {
+		t.Errorf("expected loved only, got %+v, %v", metadata, err)
+	}
+}
+
+func TestParsePlaylistRecord(t *testing.T) {
+	tests := []struct {
+		name     string
+		fields   []string
+		kind     music.PlaylistType
+		readOnly bool
+		tracks   int
+	}{
+		{"user", []string{"A1", "Friday Mix", "user", "1,2,3"}, music.PlaylistTypeUser, false, 3},
+		{"smart", []string{"A2", "Recently Added", "smart", ""}, music.PlaylistTypeSmart, true, 0},
+		{"library", []string{"A3", "Library", "library", "1"}, music.PlaylistTypeLibrary, true, 1},
+		{"queue", []string{"A4", queuePlaylistName, "user", "9"}, music.PlaylistTypeQueue, true, 1},
+	}
+	for _, tt := range tests {
+		playlist, err := parsePlaylistRecord(strings.Join(tt.fields, fieldSeparator))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if playlist.Type != tt.kind || playlist.ReadOnly != tt.readOnly || len(playlist.Tracks) != tt.tracks {
+			t.Errorf("%s: got %s read-only %t with %d tracks, want %s %t with %d",
+				tt.name, playlist.Type, playlist.ReadOnly, len(playlist.Tracks), tt.kind, tt.readOnly, tt.tracks)
+		}
+	}
+
+	if _, err := parsePlaylistRecord("A1" + fieldSeparator + "Friday Mix"); !errors.Is(err, music.ErrOperationFailed) {
+		t.Errorf("expected ErrOperationFailed for a short record, got %v", err)
+	}
+}
@@ -0,0 +1,211 @@
+package filestore
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// HistoryFile is the default history file name, in the home directory.
+const HistoryFile = ".maestro_history.jsonl"
+
+// claimSuffix is appended to the history path to name the file holding the
+// last playback claim.
+const claimSuffix = ".claim"
+
+// HistoryConfig holds configuration for the history store.
+type HistoryConfig struct {
+	// Path is the JSON lines file holding the entries; it is created on the
+	// first append. The last playback claim is kept next to it, in the same
+	// path with ".claim" appended
+	Path string
+}
+
+// DefaultHistoryConfig returns a configuration using HistoryFile in the home
+// directory, or in the working directory when there is no home.
+func DefaultHistoryConfig() *HistoryConfig {
+	return &HistoryConfig{Path: DefaultHistoryPath()}
+}
+
+// DefaultHistoryPath returns the path of HistoryFile in the home directory.
+func DefaultHistoryPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return HistoryFile
+	}
+	return filepath.Join(home, HistoryFile)
+}
+
+// History implements music.HistoryRepository in an append-only JSON lines
+// file, one entry per line. Each entry is written with a single append, so
+// concurrent readers see whole entries; a line cut short by a crash is
+// ignored when it is the last one.
+type History struct {
+	path string
+
+	// mu serializes appends within this process
+	mu sync.Mutex
+}
+
+var _ music.HistoryRepository = (*History)(nil)
+
+// NewHistory creates a history store backed by the configured file.
+func NewHistory(config *HistoryConfig) *History {
+	if config == nil {
+		config = DefaultHistoryConfig()
+	}
+	path := config.Path
+	if path == "" {
+		path = DefaultHistoryPath()
+	}
+	return &History{path: path}
+}
+
+// Path returns the file the entries are kept in.
+func (h *History) Path() string {
+	return h.path
+}
+
+// AppendHistory adds an entry to the end of the file.
+func (h *History) AppendHistory(ctx context.Context, entry *music.HistoryEntry) error {
+	if entry == nil {
+		return music.NewDomainError(music.ErrInvalidOperation, "history entry cannot be nil")
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return h.failure("cannot encode a history entry", err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return h.failure("cannot create the history directory", err)
+	}
+	file, err := os.OpenFile(h.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return h.failure("cannot open the history", err)
+	}
+	_, err = file.Write(append(data, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return h.failure("cannot append to the history", err)
+	}
+	return nil
+}
+
+// GetHistory returns the entries filter selects, oldest first. A missing
+// file holds none.
+func (h *History) GetHistory(ctx context.Context, filter music.HistoryFilter) ([]*music.HistoryEntry, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(h.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []*music.HistoryEntry{}, nil
+	}
+	if err != nil {
+		return nil, h.failure("cannot read the history", err)
+	}
+	defer file.Close()
+
+	entries, err := h.read(file)
+	if err != nil {
+		return nil, err
+	}
+	return music.FilterHistory(entries, filter), nil
+}
+
+// read decodes every entry in r.
+func (h *History) read(r io.Reader) ([]*music.HistoryEntry, error) {
+	var entries []*music.HistoryEntry
+	reader := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, h.failure("cannot read the history", err)
+		}
+		complete := err == nil
+
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			var entry music.HistoryEntry
+			if decodeErr := json.Unmarshal(data, &entry); decodeErr != nil {
+				// Only an append interrupted by a crash leaves a partial line
+				if !complete {
+					break
+				}
+				return nil, h.failure(fmt.Sprintf("cannot decode line %d of the history", line), decodeErr)
+			}
+			entries = append(entries, &entry)
+		}
+		if !complete {
+			break
+		}
+	}
+	return entries, nil
+}
+
+// ClaimPlayback notes that a maestro client started a track at the given
+// time. Claims older than the one recorded are ignored.
+func (h *History) ClaimPlayback(ctx context.Context, at time.Time) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	last, err := h.readClaim()
+	if err != nil {
+		return err
+	}
+	if !at.After(last) {
+		return nil
+	}
+	if err := replaceFile(h.claimPath(), []byte(at.UTC().Format(time.RFC3339Nano)+"\n")); err != nil {
+		return h.failure("cannot write the playback claim", err)
+	}
+	return nil
+}
+
+// LastClaim returns when a maestro client last started a track, or the zero
+// time when none has.
+func (h *History) LastClaim(ctx context.Context) (time.Time, error) {
+	return h.readClaim()
+}
+
+// readClaim reads the claim file. A missing file holds no claim.
+func (h *History) readClaim() (time.Time, error) {
+	data, err := os.ReadFile(h.claimPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, h.failure("cannot read the playback claim", err)
+	}
+	at, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(data)))
+	if err != nil {
+		return time.Time{}, h.failure("cannot decode the playback claim", err)
+	}
+	return at, nil
+}
+
+func (h *History) claimPath() string {
+	return h.path + claimSuffix
+}
+
+func (h *History) failure(message string, cause error) error {
+	return music.NewDomainErrorWithCause(music.ErrOperationFailed, fmt.Sprintf("%s in %s", message, h.path), cause).
+		WithContext("path", h.path)
+}
@@ -0,0 +1,109 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+func newTestHistory(t *testing.T) *History {
+	t.Helper()
+	return NewHistory(&HistoryConfig{Path: filepath.Join(t.TempDir(), "nested", "history.jsonl")})
+}
+
+func historyEntry(t *testing.T, id string, kind music.HistoryKind, at time.Time) *music.HistoryEntry {
+	t.Helper()
+	track, err := music.NewTrack(music.NewTrackID(id), "Track "+id, "Artist", "Album", music.NewDuration(200))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &music.HistoryEntry{Kind: kind, Track: *track, StartedAt: at.Add(-time.Minute), RecordedAt: at,
+		Listened: music.NewDuration(100)}
+}
+
+func TestHistoryAppendsAndFilters(t *testing.T) {
+	ctx := context.Background()
+	store := newTestHistory(t)
+	base := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	entries, err := store.GetHistory(ctx, music.HistoryFilter{})
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Expected an empty history before the first append, got %v (%v)", entries, err)
+	}
+
+	for i, kind := range []music.HistoryKind{music.HistoryPlay, music.HistorySkip, music.HistoryPlay} {
+		if err := store.AppendHistory(ctx, historyEntry(t, string(rune('1'+i)), kind, base.Add(time.Duration(i)*time.Hour))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A second store sees the same file, as the CLI sees the daemon's
+	other := NewHistory(&HistoryConfig{Path: store.Path()})
+	entries, err = other.GetHistory(ctx, music.HistoryFilter{Since: base.Add(time.Hour), Kinds: []music.HistoryKind{music.HistoryPlay}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Track.ID.Value() != "3" || entries[0].Track.Title != "Track 3" ||
+		entries[0].Listened.Seconds() != 100 || !entries[0].RecordedAt.Equal(base.Add(2*time.Hour)) {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+
+	if _, err := other.GetHistory(ctx, music.HistoryFilter{Since: base, Until: base}); !errors.Is(err, music.ErrInvalidOperation) {
+		t.Errorf("Expected an empty range to be rejected, got %v", err)
+	}
+}
+
+func TestHistoryIgnoresTornLastLine(t *testing.T) {
+	ctx := context.Background()
+	store := newTestHistory(t)
+	if err := store.AppendHistory(ctx, historyEntry(t, "1", music.HistoryPlay, time.Now())); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.OpenFile(store.Path(), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = file.WriteString(`{"kind":"play","tra`)
+	_ = file.Close()
+
+	entries, err := store.GetHistory(ctx, music.HistoryFilter{})
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Expected the torn line to be ignored, got %v (%v)", entries, err)
+	}
+
+	// A damaged line followed by others is not a torn append
+	if err := os.WriteFile(store.Path(), []byte("not json\n{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetHistory(ctx, music.HistoryFilter{}); !errors.Is(err, music.ErrOperationFailed) {
+		t.Errorf("Expected a corrupt line to be reported, got %v", err)
+	}
+}
+
+func TestHistoryClaims(t *testing.T) {
+	ctx := context.Background()
+	store := newTestHistory(t)
+	at := time.Date(2024, 6, 1, 12, 0, 0, 123, time.UTC)
+
+	if claim, err := store.LastClaim(ctx); err != nil || !claim.IsZero() {
+		t.Fatalf("Expected no claim yet, got %s (%v)", claim, err)
+	}
+	if err := store.ClaimPlayback(ctx, at); err != nil {
+		t.Fatal(err)
+	}
+	// An older claim, say from a slow client, does not replace a newer one
+	if err := store.ClaimPlayback(ctx, at.Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	claim, err := NewHistory(&HistoryConfig{Path: store.Path()}).LastClaim(ctx)
+	if err != nil || !claim.Equal(at) {
+		t.Errorf("Expected the claim at %s, got %s (%v)", at, claim, err)
+	}
+}
@@ -0,0 +1,234 @@
+// Package filestore keeps state maestro owns, rather than Music.app, in local
+// files.
+//
+// SmartPlaylists stores the rules of maestro's smart playlists as JSON. The
+// file is read on every call and replaced atomically on every write, so the
+// CLI and the daemon can share it: rules created with `maestro playlist
+// smart create` are picked up by the daemon's next refresh.
+//
+// History keeps the play history as JSON lines in a file that is only ever
+// appended to, so the daemon can record while `maestro history` reads.
+package filestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// SmartPlaylistFile is the default file name, in the home directory.
+const SmartPlaylistFile = ".maestro_smart_playlists.json"
+
+// Config holds configuration for the smart playlist store.
+type Config struct {
+	// Path is the JSON file holding the playlists; it is created on the
+	// first write
+	Path string
+}
+
+// DefaultConfig returns a configuration using SmartPlaylistFile in the home
+// directory, or in the working directory when there is no home.
+func DefaultConfig() *Config {
+	return &Config{Path: DefaultSmartPlaylistPath()}
+}
+
+// DefaultSmartPlaylistPath returns the path of SmartPlaylistFile in the home
+// directory.
+func DefaultSmartPlaylistPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return SmartPlaylistFile
+	}
+	return filepath.Join(home, SmartPlaylistFile)
+}
+
+// smartPlaylistDocument is the layout of the file.
+type smartPlaylistDocument struct {
+	Playlists []*music.SmartPlaylist `json:"playlists"`
+}
+
+// SmartPlaylists implements music.SmartPlaylistRepository in a JSON file.
+type SmartPlaylists struct {
+	path string
+
+	// mu serializes read-modify-write cycles within this process
+	mu sync.Mutex
+}
+
+var _ music.SmartPlaylistRepository = (*SmartPlaylists)(nil)
+
+// NewSmartPlaylists creates a smart playlist store backed by the configured
+// file.
+func NewSmartPlaylists(config *Config) *SmartPlaylists {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	path := config.Path
+	if path == "" {
+		path = DefaultSmartPlaylistPath()
+	}
+	return &SmartPlaylists{path: path}
+}
+
+// Path returns the file the playlists are kept in.
+func (s *SmartPlaylists) Path() string {
+	return s.path
+}
+
+// GetSmartPlaylists returns every smart playlist, ordered by name.
+func (s *SmartPlaylists) GetSmartPlaylists(ctx context.Context) ([]*music.SmartPlaylist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.read()
+}
+
+// GetSmartPlaylist returns the smart playlist with the given name. Names
+// are compared without regard to case.
+func (s *SmartPlaylists) GetSmartPlaylist(ctx context.Context, name string) (*music.SmartPlaylist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	playlists, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	if i := indexOf(playlists, name); i >= 0 {
+		return playlists[i], nil
+	}
+	return nil, notFound(name)
+}
+
+// SaveSmartPlaylist creates or replaces a smart playlist.
+func (s *SmartPlaylists) SaveSmartPlaylist(ctx context.Context, playlist *music.SmartPlaylist) error {
+	if playlist == nil {
+		return music.NewDomainError(music.ErrInvalidPlaylist, "smart playlist cannot be nil")
+	}
+	if _, err := music.NewSmartPlaylist(playlist.Name, playlist.Rules); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	playlists, err := s.read()
+	if err != nil {
+		return err
+	}
+	if i := indexOf(playlists, playlist.Name); i >= 0 {
+		playlists[i] = playlist
+	} else {
+		playlists = append(playlists, playlist)
+	}
+	return s.write(playlists)
+}
+
+// DeleteSmartPlaylist removes a smart playlist.
+func (s *SmartPlaylists) DeleteSmartPlaylist(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	playlists, err := s.read()
+	if err != nil {
+		return err
+	}
+	i := indexOf(playlists, name)
+	if i < 0 {
+		return notFound(name)
+	}
+	return s.write(append(playlists[:i], playlists[i+1:]...))
+}
+
+// read loads the playlists, sorted by name. A missing file holds none.
+func (s *SmartPlaylists) read() ([]*music.SmartPlaylist, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []*music.SmartPlaylist{}, nil
+	}
+	if err != nil {
+		return nil, s.failure("cannot read smart playlists", err)
+	}
+
+	var document smartPlaylistDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, s.failure("cannot decode smart playlists", err)
+	}
+
+	playlists := make([]*music.SmartPlaylist, 0, len(document.Playlists))
+	for _, playlist := range document.Playlists {
+		if playlist != nil {
+			playlists = append(playlists, playlist)
+		}
+	}
+	sort.SliceStable(playlists, func(i, j int) bool {
+		return strings.ToLower(playlists[i].Name) < strings.ToLower(playlists[j].Name)
+	})
+	return playlists, nil
+}
+
+// write replaces the file with playlists.
+func (s *SmartPlaylists) write(playlists []*music.SmartPlaylist) error {
+	data, err := json.MarshalIndent(smartPlaylistDocument{Playlists: playlists}, "", "  ")
+	if err != nil {
+		return s.failure("cannot encode smart playlists", err)
+	}
+
+	if err := replaceFile(s.path, append(data, '\n')); err != nil {
+		return s.failure("cannot write smart playlists", err)
+	}
+	return nil
+}
+
+// replaceFile replaces the file at path with data, creating its directory.
+// The data is written to a temporary file first, so readers never see a
+// partial file.
+func replaceFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	_, err = temp.Write(data)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), path)
+	}
+	return err
+}
+
+func (s *SmartPlaylists) failure(message string, cause error) error {
+	return music.NewDomainErrorWithCause(music.ErrOperationFailed, fmt.Sprintf("%s in %s", message, s.path), cause).
+		WithContext("path", s.path)
+}
+
+// indexOf returns the index of the playlist named name, or -1.
+func indexOf(playlists []*music.SmartPlaylist, name string) int {
+	name = strings.TrimSpace(name)
+	for i, playlist := range playlists {
+		if strings.EqualFold(strings.TrimSpace(playlist.Name), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func notFound(name string) error {
+	return music.NewDomainError(music.ErrPlaylistNotFound, fmt.Sprintf("smart playlist %q was not found", name)).
+		WithContext("name", name)
+}
@@ -0,0 +1,127 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+func newTestStore(t *testing.T) *SmartPlaylists {
+	t.Helper()
+	return NewSmartPlaylists(&Config{Path: filepath.Join(t.TempDir(), "nested", "smart.json")})
+}
+
+func jazzPlaylist(t *testing.T, name string) *music.SmartPlaylist {
+	t.Helper()
+	rule, err := music.ParseSmartRule("genre is Jazz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	playlist, err := music.NewSmartPlaylist(name, music.SmartRules{
+		Rules:        []music.SmartRule{rule},
+		Sort:         &music.SmartSort{Field: music.SmartFieldPlayCount, Descending: true},
+		Limit:        music.SmartLimit{Count: 25},
+		LiveUpdating: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return playlist
+}
+
+func TestSmartPlaylistsMissingFile(t *testing.T) {
+	store := newTestStore(t)
+	playlists, err := store.GetSmartPlaylists(context.Background())
+	if err != nil || len(playlists) != 0 {
+		t.Fatalf("expected no playlists, got %v (%v)", playlists, err)
+	}
+	if _, err := store.GetSmartPlaylist(context.Background(), "Jazz"); !errors.Is(err, music.ErrPlaylistNotFound) {
+		t.Errorf("expected ErrPlaylistNotFound, got %v", err)
+	}
+}
+
+func TestSmartPlaylistsRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	for _, name := range []string{"Jazz", "Bebop"} {
+		if err := store.SaveSmartPlaylist(ctx, jazzPlaylist(t, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A second store sees the same file, as the daemon sees the CLI's
+	other := NewSmartPlaylists(&Config{Path: store.Path()})
+	playlists, err := other.GetSmartPlaylists(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(playlists) != 2 || playlists[0].Name != "Bebop" || playlists[1].Name != "Jazz" {
+		t.Fatalf("expected Bebop and Jazz, got %v", playlists)
+	}
+	if rules := playlists[1].Rules; len(rules.Rules) != 1 || rules.Sort == nil || rules.Limit.Count != 25 || !rules.LiveUpdating {
+		t.Errorf("unexpected rules %+v", rules)
+	}
+
+	// Saving under the same name, in any case, replaces the playlist
+	updated := jazzPlaylist(t, "JAZZ")
+	updated.PlaylistID = music.NewPlaylistID("P1")
+	updated.MaterializedAt = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	if err := store.SaveSmartPlaylist(ctx, updated); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.GetSmartPlaylist(ctx, "jazz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "JAZZ" || got.PlaylistID.Value() != "P1" || !got.MaterializedAt.Equal(updated.MaterializedAt) {
+		t.Errorf("unexpected playlist %+v", got)
+	}
+
+	if err := store.DeleteSmartPlaylist(ctx, "bebop"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DeleteSmartPlaylist(ctx, "bebop"); !errors.Is(err, music.ErrPlaylistNotFound) {
+		t.Errorf("expected ErrPlaylistNotFound, got %v", err)
+	}
+	if playlists, _ := store.GetSmartPlaylists(ctx); len(playlists) != 1 {
+		t.Errorf("expected one playlist left, got %v", playlists)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(store.Path()))
+	if err != nil || len(entries) != 1 {
+		t.Errorf("expected only the store file to remain, got %v (%v)", entries, err)
+	}
+}
+
+func TestSmartPlaylistsRejectsInvalid(t *testing.T) {
+	store := newTestStore(t)
+	invalid := &music.SmartPlaylist{
+		Name:  "Bad",
+		Rules: music.SmartRules{Rules: []music.SmartRule{{Field: music.SmartFieldYear, Operator: music.SmartOperatorContains, Value: "19"}}},
+	}
+	if err := store.SaveSmartPlaylist(context.Background(), invalid); !errors.Is(err, music.ErrInvalidSmartRule) {
+		t.Errorf("expected ErrInvalidSmartRule, got %v", err)
+	}
+	if _, err := os.Stat(store.Path()); !os.IsNotExist(err) {
+		t.Errorf("expected no file to be written, got %v", err)
+	}
+}
+
+func TestSmartPlaylistsCorruptFile(t *testing.T) {
+	store := newTestStore(t)
+	if err := os.MkdirAll(filepath.Dir(store.Path()), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(store.Path(), []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetSmartPlaylists(context.Background()); !errors.Is(err, music.ErrOperationFailed) {
+		t.Errorf("expected ErrOperationFailed, got %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+// Package mcp implements a Model Context Protocol server that exposes Maestro's
+// music repositories to AI assistants.
+//
+// The server speaks JSON-RPC 2.0 over newline-delimited stdio, as described by
+// the MCP specification. Tools are backed by the music repository interfaces,
+// their input schemas are derived from the domain types, and arguments are
+// validated through the same domain constructors the CLI uses.
+//
+// Resources under the maestro:// scheme give assistants readable context:
+// the now-playing state, the queue, playlists and the library. The now-playing
+// and queue resources support subscriptions backed by a music.EventSource.
+//
+// MCP clients are stateless and rate limited according to the session policy
+// for session.ClientTypeMCP.
+package mcp
+
+import "encoding/json"
+
+// ProtocolVersion is the MCP protocol revision implemented by this server.
+const ProtocolVersion = "2025-06-18"
+
+// jsonRPCVersion is the only JSON-RPC version accepted.
+const jsonRPCVersion = "2.0"
+
+// Standard JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// codeResourceNotFound is the MCP error code for an unknown resource URI.
+const codeResourceNotFound = -32002
+
+// codeRateLimited is the server error code for a request refused by the
+// session rate limit. Its data carries retry_after_seconds.
+const codeRateLimited = -32003
+
+// request is an incoming JSON-RPC request or notification. Notifications have no ID.
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// isNotification returns true if the request does not expect a response.
+func (r *request) isNotification() bool {
+	return len(r.ID) == 0 || string(r.ID) == "null"
+}
+
+// response is an outgoing JSON-RPC response.
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// notification is an outgoing JSON-RPC notification.
+type notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// rpcError is a JSON-RPC error object.
+type rpcError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// Error implements the error interface.
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+// Implementation describes the name and version of an MCP peer.
+type Implementation struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// initializeParams is sent by the client to open a session.
+type initializeParams struct {
+	ProtocolVersion string         `json:"protocolVersion"`
+	ClientInfo      Implementation `json:"clientInfo"`
+}
+
+// initializeResult advertises the server's capabilities.
+type initializeResult struct {
+	ProtocolVersion string                 `json:"protocolVersion"`
+	Capabilities    map[string]interface{} `json:"capabilities"`
+	ServerInfo      Implementation         `json:"serverInfo"`
+	Instructions    string                 `json:"instructions,omitempty"`
+}
+
+// Tool describes a tool as listed by tools/list.
+type Tool struct {
+	Name        string  `json:"name"`
+	Title       string  `json:"title,omitempty"`
+	Description string  `json:"description"`
+	InputSchema *Schema `json:"inputSchema"`
+}
+
+// listToolsResult is the result of tools/list.
+type listToolsResult struct {
+	Tools []Tool `json:"tools"`
+}
+
+// callToolParams is sent by the client to invoke a tool.
+type callToolParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// content is a single content block in a tool result.
+type content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// callToolResult is the result of tools/call. Tool failures are reported in the
+// result with IsError set, so the assistant can see and react to them.
+type callToolResult struct {
+	Content           []content   `json:"content"`
+	StructuredContent interface{} `json:"structuredContent,omitempty"`
+	IsError           bool        `json:"isError,omitempty"`
+}
+
+// Resource describes a concrete resource as listed by resources/list.
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceTemplate describes a parameterized resource URI as listed by
+// resources/templates/list.
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// paginatedParams carries the opaque cursor of a paginated list request.
+type paginatedParams struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// listResourcesResult is the result of resources/list.
+type listResourcesResult struct {
+	Resources  []Resource `json:"resources"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
+
+// listResourceTemplatesResult is the result of resources/templates/list.
+type listResourceTemplatesResult struct {
+	ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
+}
+
+// resourceParams identifies a resource in resources/read, resources/subscribe
+// and resources/unsubscribe requests and in update notifications.
+type resourceParams struct {
+	URI string `json:"uri"`
+}
+
+// resourceContents is the text contents of a resource.
+type resourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text"`
+}
+
+// readResourceResult is the result of resources/read.
+type readResourceResult struct {
+	Contents []resourceContents `json:"contents"`
+}
@@ -0,0 +1,357 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// Resource URIs exposed by the server.
+const (
+	resourceScheme = "maestro://"
+
+	nowPlayingURI     = resourceScheme + "now-playing"
+	queueURI          = resourceScheme + "queue"
+	playlistsURI      = resourceScheme + "playlists"
+	artistsURI        = resourceScheme + "library/artists"
+	playlistURIPrefix = playlistsURI + "/"
+	trackURIPrefix    = resourceScheme + "library/tracks/"
+)
+
+// resourceMimeType is the MIME type of every resource; contents are JSON.
+const resourceMimeType = "application/json"
+
+// resourceUpdatedMethod notifies subscribers that a resource changed.
+const resourceUpdatedMethod = "notifications/resources/updated"
+
+// staticResources are listed on the first page of resources/list.
+var staticResources = []Resource{
+	{
+		URI:         nowPlayingURI,
+		Name:        "now-playing",
+		Title:       "Now playing",
+		Description: "Player state and the current track. Subscribe to be notified when the track or player state changes.",
+		MimeType:    resourceMimeType,
+	},
+	{
+		URI:         queueURI,
+		Name:        "queue",
+		Title:       "Play queue",
+		Description: "The play queue and the current position in it. Subscribe to be notified when the queue changes.",
+		MimeType:    resourceMimeType,
+	},
+	{
+		URI:         playlistsURI,
+		Name:        "playlists",
+		Title:       "Playlists",
+		Description: "Every playlist with its type and track count.",
+		MimeType:    resourceMimeType,
+	},
+	{
+		URI:         artistsURI,
+		Name:        "artists",
+		Title:       "Library artists",
+		Description: "Every artist in the music library.",
+		MimeType:    resourceMimeType,
+	},
+}
+
+// resourceTemplates describe the parameterized resources.
+var resourceTemplates = []ResourceTemplate{
+	{
+		URITemplate: playlistURIPrefix + "{id}",
+		Name:        "playlist",
+		Title:       "Playlist",
+		Description: "A playlist and its tracks, by persistent ID.",
+		MimeType:    resourceMimeType,
+	},
+	{
+		URITemplate: trackURIPrefix + "{id}",
+		Name:        "track",
+		Title:       "Track",
+		Description: "The metadata of a library track, by database ID.",
+		MimeType:    resourceMimeType,
+	},
+}
+
+// subscribableResources are the resources backed by player events.
+var subscribableResources = map[string]bool{
+	nowPlayingURI: true,
+	queueURI:      true,
+}
+
+// handleListResources lists the static resources and playlists followed by a
+// page of library tracks. The cursor is the offset of the next track page.
+func (s *Server) handleListResources(ctx context.Context, params json.RawMessage) (interface{}, *rpcError) {
+	var p paginatedParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: "invalid resources/list params: " + err.Error()}
+		}
+	}
+
+	offset, err := decodeCursor(p.Cursor)
+	if err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	if err := s.limiter.Reserve(); err != nil {
+		return nil, rateLimitError(err)
+	}
+
+	var resources []Resource
+	if offset == 0 {
+		resources = append(resources, staticResources...)
+
+		playlists, err := s.repos.GetPlaylists(ctx)
+		if err != nil {
+			return nil, &rpcError{Code: codeInternalError, Message: err.Error()}
+		}
+		for _, playlist := range playlists {
+			resources = append(resources, Resource{
+				URI:         playlistURIPrefix + url.PathEscape(playlist.ID.Value()),
+				Name:        playlist.Name,
+				Description: fmt.Sprintf("%s playlist with %d tracks", playlist.Type, playlist.TrackCount()),
+				MimeType:    resourceMimeType,
+			})
+		}
+	}
+
+	pageSize := s.config.PageSize
+	tracks, err := s.repos.GetAllTracks(ctx, pageSize, offset)
+	if err != nil {
+		return nil, &rpcError{Code: codeInternalError, Message: err.Error()}
+	}
+	for _, track := range tracks {
+		resources = append(resources, Resource{
+			URI:      trackURIPrefix + url.PathEscape(track.ID.Value()),
+			Name:     track.String(),
+			Title:    track.Title,
+			MimeType: resourceMimeType,
+		})
+	}
+
+	result := listResourcesResult{Resources: resources}
+	if len(tracks) == pageSize {
+		result.NextCursor = encodeCursor(offset + pageSize)
+	}
+	return result, nil
+}
+
+// handleListResourceTemplates lists the parameterized resources.
+func (s *Server) handleListResourceTemplates() interface{} {
+	return listResourceTemplatesResult{ResourceTemplates: resourceTemplates}
+}
+
+// handleReadResource returns the JSON contents of a resource.
+func (s *Server) handleReadResource(ctx context.Context, params json.RawMessage) (interface{}, *rpcError) {
+	uri, rpcErr := parseResourceParams(params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	if err := s.limiter.Reserve(); err != nil {
+		return nil, rateLimitError(err)
+	}
+
+	value, err := s.readResource(ctx, uri)
+	if err != nil {
+		if errors.Is(err, music.ErrTrackNotFound) || errors.Is(err, music.ErrPlaylistNotFound) ||
+			errors.Is(err, errUnknownResource) {
+			return nil, &rpcError{Code: codeResourceNotFound, Message: err.Error(), Data: map[string]string{"uri": uri}}
+		}
+		return nil, &rpcError{Code: codeInternalError, Message: err.Error()}
+	}
+
+	text, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return nil, &rpcError{Code: codeInternalError, Message: err.Error()}
+	}
+
+	return readResourceResult{
+		Contents: []resourceContents{{URI: uri, MimeType: resourceMimeType, Text: string(text)}},
+	}, nil
+}
+
+// errUnknownResource is returned for URIs that do not name a resource.
+var errUnknownResource = errors.New("resource not found")
+
+// readResource resolves a resource URI to its value.
+func (s *Server) readResource(ctx context.Context, uri string) (interface{}, error) {
+	switch uri {
+	case nowPlayingURI:
+		return s.nowPlaying(ctx)
+	case queueURI:
+		return s.queueView(ctx)
+	case playlistsURI:
+		return s.playlistSummaries(ctx)
+	case artistsURI:
+		artists, err := s.repos.GetArtists(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"artists": artists, "count": len(artists)}, nil
+	}
+
+	if id, ok := resourceID(uri, playlistURIPrefix); ok {
+		return s.playlistView(ctx, music.NewPlaylistID(id))
+	}
+	if id, ok := resourceID(uri, trackURIPrefix); ok {
+		return s.repos.GetTrack(ctx, music.NewTrackID(id))
+	}
+
+	return nil, fmt.Errorf("%w: %s", errUnknownResource, uri)
+}
+
+// handleSubscribe records a subscription to an event-backed resource. The
+// server subscribes to events with the first resource subscription and lets
+// go of them with the last, so that nothing polls Music.app unwatched.
+func (s *Server) handleSubscribe(ctx context.Context, params json.RawMessage, subscribe bool) (interface{}, *rpcError) {
+	uri, rpcErr := parseResourceParams(params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	if s.config.Events == nil || !subscribableResources[uri] {
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("resource %q does not support subscriptions", uri)}
+	}
+
+	s.subMu.Lock()
+	if subscribe {
+		s.subscriptions[uri] = true
+	} else {
+		delete(s.subscriptions, uri)
+	}
+	switch {
+	case len(s.subscriptions) > 0 && s.stopEvents == nil:
+		var eventsCtx context.Context
+		eventsCtx, s.stopEvents = context.WithCancel(ctx)
+		go s.watchEvents(s.config.Events.Subscribe(eventsCtx))
+	case len(s.subscriptions) == 0 && s.stopEvents != nil:
+		s.stopEvents()
+		s.stopEvents = nil
+	}
+	s.subMu.Unlock()
+
+	return struct{}{}, nil
+}
+
+// watchEvents forwards player events to subscribed resources until events
+// is closed.
+func (s *Server) watchEvents(events <-chan music.Event) {
+	for event := range events {
+		uri := nowPlayingURI
+		if !event.IsPlayerEvent() {
+			uri = queueURI
+		}
+
+		s.subMu.Lock()
+		subscribed := s.subscriptions[uri]
+		s.subMu.Unlock()
+
+		if subscribed {
+			s.write(notification{JSONRPC: jsonRPCVersion, Method: resourceUpdatedMethod, Params: resourceParams{URI: uri}})
+		}
+	}
+}
+
+// nowPlaying returns the player state and current track.
+func (s *Server) nowPlaying(ctx context.Context) (interface{}, error) {
+	player, err := s.repos.GetCurrentState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	view := statusView{Player: player}
+	if player.HasCurrentTrack() {
+		view.Track, _ = s.repos.GetCurrentTrack(ctx)
+	}
+	return view, nil
+}
+
+// queueView returns the queue and the current position in it.
+func (s *Server) queueView(ctx context.Context) (interface{}, error) {
+	queue, err := s.repos.GetQueue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	position, err := s.repos.GetQueuePosition(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"queue": queue, "position": position}, nil
+}
+
+// playlistSummaries returns every playlist without its tracks.
+func (s *Server) playlistSummaries(ctx context.Context) (interface{}, error) {
+	playlists, err := s.repos.GetPlaylists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]playlistSummary, 0, len(playlists))
+	for _, p := range playlists {
+		summaries = append(summaries, summarizePlaylist(p))
+	}
+	return map[string]interface{}{"playlists": summaries}, nil
+}
+
+// playlistView returns a playlist and its tracks.
+func (s *Server) playlistView(ctx context.Context, playlistID music.PlaylistID) (interface{}, error) {
+	playlist, err := s.repos.GetPlaylist(ctx, playlistID)
+	if err != nil {
+		return nil, err
+	}
+	tracks, err := s.repos.GetPlaylistTracks(ctx, playlistID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"playlist": summarizePlaylist(playlist), "tracks": tracks}, nil
+}
+
+// parseResourceParams extracts the URI from resource request params.
+func parseResourceParams(params json.RawMessage) (string, *rpcError) {
+	var p resourceParams
+	if err := json.Unmarshal(params, &p); err != nil || p.URI == "" {
+		return "", &rpcError{Code: codeInvalidParams, Message: "params must include a resource uri"}
+	}
+	return p.URI, nil
+}
+
+// resourceID extracts the unescaped ID from a URI with the given prefix.
+func resourceID(uri, prefix string) (string, bool) {
+	if !strings.HasPrefix(uri, prefix) {
+		return "", false
+	}
+	id, err := url.PathUnescape(strings.TrimPrefix(uri, prefix))
+	if err != nil || id == "" || strings.Contains(id, "/") {
+		return "", false
+	}
+	return id, true
+}
+
+// encodeCursor returns the opaque cursor for a track offset.
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+// decodeCursor returns the track offset of a cursor; the empty cursor is offset 0.
+func decodeCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	offset, err := strconv.Atoi(string(data))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return offset, nil
+}
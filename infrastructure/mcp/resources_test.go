@@ -0,0 +1,278 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// libraryRepos serves a fixed library for resource tests.
+type libraryRepos struct {
+	stubRepos
+	tracks []*music.Track
+}
+
+func newLibraryRepos(count int) *libraryRepos {
+	repos := &libraryRepos{}
+	for i := 1; i <= count; i++ {
+		track, _ := music.NewTrack(music.NewTrackID(fmt.Sprint(i)), fmt.Sprintf("Song %d", i), "Artist", "Album", music.NewDuration(60))
+		repos.tracks = append(repos.tracks, track)
+	}
+	return repos
+}
+
+func (r *libraryRepos) GetPlaylists(context.Context) ([]*music.Playlist, error) {
+	playlist, _ := music.NewPlaylist(music.NewPlaylistID("ABC123"), "Favourites", music.PlaylistTypeUser, false)
+	return []*music.Playlist{playlist}, nil
+}
+
+func (r *libraryRepos) GetAllTracks(_ context.Context, limit, offset int) ([]*music.Track, error) {
+	if offset >= len(r.tracks) {
+		return nil, nil
+	}
+	end := offset + limit
+	if end > len(r.tracks) {
+		end = len(r.tracks)
+	}
+	return r.tracks[offset:end], nil
+}
+
+func (r *libraryRepos) GetTrack(_ context.Context, trackID music.TrackID) (*music.Track, error) {
+	for _, track := range r.tracks {
+		if track.ID.Equals(trackID) {
+			return track, nil
+		}
+	}
+	return nil, music.WrapTrackNotFound(trackID, nil)
+}
+
+func (r *libraryRepos) GetCurrentState(context.Context) (*music.Player, error) {
+	return music.NewPlayer(), nil
+}
+
+// eventSource is an EventSource fed by the test.
+type eventSource struct {
+	events chan music.Event
+}
+
+func (e *eventSource) Subscribe(context.Context) <-chan music.Event {
+	return e.events
+}
+
+// recordingSource is an EventSource that records the subscriptions made
+// to it.
+type recordingSource struct {
+	contexts []context.Context
+}
+
+func (r *recordingSource) Subscribe(ctx context.Context) <-chan music.Event {
+	r.contexts = append(r.contexts, ctx)
+	return make(chan music.Event)
+}
+
+func TestSubscriptionsHoldEvents(t *testing.T) {
+	subscribe := func(id int, method, uri string) string {
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"resources/%s","params":{"uri":%q}}`, id, method, uri)
+	}
+
+	source := &recordingSource{}
+	server := NewServer(newLibraryRepos(1), &ServerConfig{Name: "test", Events: source})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server.handleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	if len(source.contexts) != 0 {
+		t.Fatal("expected no event subscription before a resource subscription")
+	}
+
+	server.handleMessage(ctx, []byte(subscribe(2, "subscribe", nowPlayingURI)))
+	server.handleMessage(ctx, []byte(subscribe(3, "subscribe", queueURI)))
+	server.handleMessage(ctx, []byte(subscribe(4, "unsubscribe", nowPlayingURI)))
+	if len(source.contexts) != 1 || source.contexts[0].Err() != nil {
+		t.Fatalf("expected one live event subscription while the queue is subscribed, got %d", len(source.contexts))
+	}
+
+	server.handleMessage(ctx, []byte(subscribe(5, "unsubscribe", queueURI)))
+	if source.contexts[0].Err() == nil {
+		t.Error("expected the event subscription to end with the last resource subscription")
+	}
+	server.handleMessage(ctx, []byte(subscribe(6, "subscribe", queueURI)))
+	if len(source.contexts) != 2 {
+		t.Errorf("expected a new event subscription, got %d", len(source.contexts))
+	}
+}
+
+func TestListResourcesPaginates(t *testing.T) {
+	repos := newLibraryRepos(5)
+	server := NewServer(repos, &ServerConfig{Name: "test", PageSize: 2})
+
+	var uris []string
+	cursor := ""
+	pages := 0
+	for {
+		params, _ := json.Marshal(paginatedParams{Cursor: cursor})
+		result, rpcErr := server.handleListResources(context.Background(), params)
+		if rpcErr != nil {
+			t.Fatalf("unexpected error: %v", rpcErr)
+		}
+		page := result.(listResourcesResult)
+		for _, resource := range page.Resources {
+			uris = append(uris, resource.URI)
+		}
+		pages++
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	if pages != 3 {
+		t.Errorf("expected 3 pages, got %d", pages)
+	}
+
+	expected := len(staticResources) + 1 + 5
+	if len(uris) != expected {
+		t.Fatalf("expected %d resources, got %d: %v", expected, len(uris), uris)
+	}
+	if uris[len(staticResources)] != "maestro://playlists/ABC123" {
+		t.Errorf("expected playlist resource after static resources, got %s", uris[len(staticResources)])
+	}
+	if uris[len(uris)-1] != "maestro://library/tracks/5" {
+		t.Errorf("expected last track resource, got %s", uris[len(uris)-1])
+	}
+
+	if _, rpcErr := server.handleListResources(context.Background(), json.RawMessage(`{"cursor":"%%%"}`)); rpcErr == nil {
+		t.Error("expected invalid cursor to be rejected")
+	}
+}
+
+func TestResourcesRateLimit(t *testing.T) {
+	requests := make([]string, 0, 21)
+	for i := 1; i <= 21; i++ {
+		requests = append(requests, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"resources/read","params":{"uri":"maestro://now-playing"}}`, i))
+	}
+
+	responses := roundTrip(t, newLibraryRepos(1), requests...)
+
+	errObj, ok := responses[20]["error"].(map[string]interface{})
+	if !ok {
+		t.Fatal("expected the 21st read within a minute to be rate limited")
+	}
+	if errObj["code"].(float64) != codeRateLimited {
+		t.Errorf("expected code %d, got %v", codeRateLimited, errObj["code"])
+	}
+	data, _ := errObj["data"].(map[string]interface{})
+	if retryAfter, _ := data["retry_after_seconds"].(float64); retryAfter <= 0 {
+		t.Errorf("expected a positive retry_after_seconds, got %v", errObj["data"])
+	}
+}
+
+func TestReadResource(t *testing.T) {
+	server := NewServer(newLibraryRepos(3), nil)
+
+	tests := []struct {
+		uri      string
+		code     int
+		contains string
+	}{
+		{uri: "maestro://library/tracks/2", contains: `"title": "Song 2"`},
+		{uri: "maestro://now-playing", contains: `"state": "stopped"`},
+		{uri: "maestro://library/tracks/99", code: codeResourceNotFound},
+		{uri: "maestro://unknown", code: codeResourceNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			params, _ := json.Marshal(resourceParams{URI: tt.uri})
+			result, rpcErr := server.handleReadResource(context.Background(), params)
+
+			if tt.code != 0 {
+				if rpcErr == nil || rpcErr.Code != tt.code {
+					t.Fatalf("expected error code %d, got %v", tt.code, rpcErr)
+				}
+				return
+			}
+			if rpcErr != nil {
+				t.Fatalf("unexpected error: %v", rpcErr)
+			}
+
+			contents := result.(readResourceResult).Contents
+			if len(contents) != 1 || contents[0].URI != tt.uri || contents[0].MimeType != resourceMimeType {
+				t.Fatalf("unexpected contents %+v", contents)
+			}
+			if !strings.Contains(contents[0].Text, tt.contains) {
+				t.Errorf("expected contents to contain %s, got %s", tt.contains, contents[0].Text)
+			}
+		})
+	}
+}
+
+func TestResourceSubscriptions(t *testing.T) {
+	events := &eventSource{events: make(chan music.Event, 4)}
+	server := NewServer(newLibraryRepos(1), &ServerConfig{Name: "test", Events: events})
+
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		_ = server.Serve(ctx, inReader, outWriter)
+		outWriter.Close()
+	}()
+
+	lines := bufio.NewScanner(outReader)
+	send := func(message string) {
+		if _, err := io.WriteString(inWriter, message+"\n"); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	receive := func() map[string]interface{} {
+		done := make(chan map[string]interface{}, 1)
+		go func() {
+			var message map[string]interface{}
+			if lines.Scan() {
+				_ = json.Unmarshal(lines.Bytes(), &message)
+			}
+			done <- message
+		}()
+		select {
+		case message := <-done:
+			return message
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for message")
+			return nil
+		}
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"maestro://now-playing"}}`)
+	if response := receive(); response["error"] != nil {
+		t.Fatalf("subscribe failed: %v", response["error"])
+	}
+
+	send(`{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"maestro://library/artists"}}`)
+	if response := receive(); response["error"] == nil {
+		t.Fatal("expected subscription to a static resource to be rejected")
+	}
+
+	// Queue events are not delivered without a queue subscription.
+	events.events <- music.NewQueueChangedEvent(nil, 0, time.Now())
+	events.events <- music.Event{Type: music.EventTrackChanged, OccurredAt: time.Now(), Player: music.NewPlayer()}
+
+	notification := receive()
+	if notification["method"] != resourceUpdatedMethod {
+		t.Fatalf("expected update notification, got %v", notification)
+	}
+	if uri := notification["params"].(map[string]interface{})["uri"]; uri != nowPlayingURI {
+		t.Errorf("expected now-playing update, got %v", uri)
+	}
+
+	inWriter.Close()
+}
@@ -0,0 +1,187 @@
+package mcp
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// Schema is the subset of JSON Schema used to describe tool inputs.
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	Maximum              *int               `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+}
+
+// domainSchemas maps domain value objects to the JSON representation produced by
+// their MarshalJSON/MarshalText methods, with the bounds enforced by their
+// constructors.
+var domainSchemas = map[reflect.Type]func() *Schema{
+	reflect.TypeOf(music.TrackID{}): func() *Schema {
+		return &Schema{Type: "string", MinLength: intPtr(1), Description: "Music.app track database ID"}
+	},
+	reflect.TypeOf(music.PlaylistID{}): func() *Schema {
+		return &Schema{Type: "string", MinLength: intPtr(1), Description: "Music.app playlist persistent ID"}
+	},
+	reflect.TypeOf(music.Duration{}): func() *Schema {
+		return &Schema{Type: "number", Minimum: intPtr(0), Description: "Duration in seconds, with millisecond precision"}
+	},
+	reflect.TypeOf(music.Volume{}): func() *Schema {
+		return &Schema{
+			Type:        "integer",
+			Minimum:     intPtr(music.MinVolumeLevel),
+			Maximum:     intPtr(music.MaxVolumeLevel),
+			Description: "Volume level",
+		}
+	},
+	reflect.TypeOf(music.Rating{}): func() *Schema {
+		return &Schema{
+			Type:        "integer",
+			Minimum:     intPtr(music.MinRating),
+			Maximum:     intPtr(music.MaxRating),
+			Description: "Rating, 20 per star",
+		}
+	},
+	reflect.TypeOf(music.Preference(0)): func() *Schema {
+		return &Schema{Type: "string", Enum: enumNames(music.Preferences()), Description: "Whether the track is loved, disliked or neither"}
+	},
+	reflect.TypeOf(music.RepeatMode(0)): func() *Schema {
+		return &Schema{Type: "string", Enum: enumNames(music.RepeatModes()), Description: "Repeat mode"}
+	},
+	reflect.TypeOf(music.PlayerState(0)): func() *Schema {
+		return &Schema{Type: "string", Enum: enumNames(music.PlayerStates()), Description: "Player state"}
+	},
+	reflect.TypeOf(music.PlaylistType(0)): func() *Schema {
+		return &Schema{Type: "string", Enum: enumNames(music.PlaylistTypes()), Description: "Playlist type"}
+	},
+	reflect.TypeOf(time.Time{}): func() *Schema {
+		return &Schema{Type: "string", Format: "date-time"}
+	},
+}
+
+// SchemaFor derives a JSON schema from the Go type of v. Struct fields are named
+// after their json tags, are required unless tagged omitempty, and take their
+// description from a "desc" tag. Domain value objects map to the JSON form they
+// marshal to.
+func SchemaFor(v interface{}) *Schema {
+	return schemaForType(reflect.TypeOf(v))
+}
+
+func schemaForType(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if build, ok := domainSchemas[t]; ok {
+		return build()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaForType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		return &Schema{}
+	}
+}
+
+func structSchema(t reflect.Type) *Schema {
+	schema := &Schema{
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: boolPtr(false),
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omitEmpty := jsonFieldName(field)
+		if name == "-" {
+			continue
+		}
+
+		property := schemaForType(field.Type)
+		if desc := field.Tag.Get("desc"); desc != "" {
+			property.Description = desc
+		}
+		if field.Type.Kind() == reflect.Slice && !omitEmpty {
+			property.MinItems = intPtr(1)
+		}
+		if minimum, err := strconv.Atoi(field.Tag.Get("min")); err == nil {
+			property.Minimum = intPtr(minimum)
+		}
+
+		schema.Properties[name] = property
+		if !omitEmpty {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
+
+// jsonFieldName returns the JSON name of a struct field and whether it is
+// omitempty or omitzero.
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "" {
+		return field.Name, false
+	}
+
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = field.Name
+	}
+
+	for _, option := range parts[1:] {
+		if option == "omitempty" || option == "omitzero" {
+			return name, true
+		}
+	}
+	return name, false
+}
+
+// enumNames returns the String() names of enum values.
+func enumNames[T interface{ String() string }](values []T) []string {
+	names := make([]string, len(values))
+	for i, value := range values {
+		names[i] = value.String()
+	}
+	return names
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
@@ -0,0 +1,358 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/madstone-tech/maestro/application/session"
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/pkg/logger"
+)
+
+// maxMessageSize is the largest JSON-RPC message accepted on stdin.
+const maxMessageSize = 4 * 1024 * 1024
+
+// supportedProtocolVersions lists the MCP revisions the server can speak.
+var supportedProtocolVersions = []string{ProtocolVersion, "2025-03-26", "2024-11-05"}
+
+// ServerConfig holds configuration for the MCP server.
+type ServerConfig struct {
+	// Name is reported to clients as the server implementation name
+	Name string
+
+	// Version is reported to clients as the server implementation version
+	Version string
+
+	// Logger receives diagnostic output; it must not write to the protocol stream
+	Logger logger.Logger
+
+	// Events backs resource subscriptions; subscriptions are unavailable when nil
+	Events music.EventSource
+
+	// PageSize is the number of library tracks per resources/list page
+	PageSize int
+
+	// Policy overrides the MCP session policy (nil for the built-in policy)
+	Policy *session.Policy
+}
+
+// DefaultServerConfig returns a default configuration for the MCP server.
+func DefaultServerConfig() *ServerConfig {
+	return &ServerConfig{
+		Name:     "maestro-mcp",
+		Version:  "dev",
+		PageSize: 100,
+	}
+}
+
+// Server is a Model Context Protocol server exposing the music repositories as tools.
+type Server struct {
+	config  *ServerConfig
+	repos   music.RepositoryManager
+	log     logger.Logger
+	policy  session.Policy
+	limiter *session.RateLimiter
+	tools   map[string]*tool
+	order   []string
+
+	subMu         sync.Mutex
+	subscriptions map[string]bool
+
+	// stopEvents stops the event subscription, which is held only while
+	// some resource is subscribed to
+	stopEvents context.CancelFunc
+
+	writeMu sync.Mutex
+	out     io.Writer
+}
+
+// NewServer creates an MCP server backed by the given repositories.
+func NewServer(repos music.RepositoryManager, config *ServerConfig) *Server {
+	if config == nil {
+		config = DefaultServerConfig()
+	}
+	if config.PageSize <= 0 {
+		config.PageSize = DefaultServerConfig().PageSize
+	}
+
+	log := config.Logger
+	if log == nil {
+		log = logger.Component("mcp")
+	}
+
+	policy := session.PolicyFor(session.ClientTypeMCP)
+	if config.Policy != nil {
+		policy = *config.Policy
+	}
+
+	s := &Server{
+		config:  config,
+		repos:   repos,
+		log:     log,
+		policy:  policy,
+		limiter: session.NewRateLimiterForPolicy(policy),
+		tools:   make(map[string]*tool),
+
+		subscriptions: make(map[string]bool),
+	}
+	s.registerTools()
+
+	return s
+}
+
+// Serve reads JSON-RPC messages from in and writes responses to out until in is
+// exhausted or ctx is cancelled.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.writeMu.Lock()
+	s.out = out
+	s.writeMu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	lines := make(chan []byte)
+	scanErr := make(chan error, 1)
+
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+		for scanner.Scan() {
+			line := append([]byte(nil), scanner.Bytes()...)
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		scanErr <- scanner.Err()
+	}()
+
+	s.log.Info("MCP server started", logger.String("protocol_version", ProtocolVersion))
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case line, ok := <-lines:
+			if !ok {
+				select {
+				case err := <-scanErr:
+					return err
+				default:
+					return nil
+				}
+			}
+			if len(line) == 0 {
+				continue
+			}
+			s.handleMessage(ctx, line)
+		}
+	}
+}
+
+// handleMessage decodes and dispatches one JSON-RPC message.
+func (s *Server) handleMessage(ctx context.Context, line []byte) {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		s.writeError(json.RawMessage("null"), &rpcError{Code: codeParseError, Message: "parse error: " + err.Error()})
+		return
+	}
+
+	if req.JSONRPC != jsonRPCVersion || req.Method == "" {
+		if !req.isNotification() {
+			s.writeError(req.ID, &rpcError{Code: codeInvalidRequest, Message: "invalid JSON-RPC 2.0 request"})
+		}
+		return
+	}
+
+	log := s.log.WithOperation(req.Method)
+	log.Debug("Handling MCP request")
+
+	result, rpcErr := s.dispatch(ctx, &req)
+
+	if req.isNotification() {
+		if rpcErr != nil {
+			log.Warn("Notification failed", logger.String("error", rpcErr.Message))
+		}
+		return
+	}
+
+	if rpcErr != nil {
+		log.Warn("Request failed", logger.Int("code", rpcErr.Code), logger.String("error", rpcErr.Message))
+		s.writeError(req.ID, rpcErr)
+		return
+	}
+
+	s.write(response{JSONRPC: jsonRPCVersion, ID: req.ID, Result: result})
+}
+
+// dispatch routes a request to its method handler.
+func (s *Server) dispatch(ctx context.Context, req *request) (interface{}, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		return s.handleInitialize(req.Params)
+	case "notifications/initialized", "notifications/cancelled":
+		if !req.isNotification() {
+			return nil, &rpcError{Code: codeInvalidRequest, Message: fmt.Sprintf("%s is a notification and must not have an id", req.Method)}
+		}
+		return nil, nil
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return s.handleListTools(), nil
+	case "tools/call":
+		return s.handleCallTool(ctx, req.Params)
+	case "resources/list":
+		return s.handleListResources(ctx, req.Params)
+	case "resources/templates/list":
+		return s.handleListResourceTemplates(), nil
+	case "resources/read":
+		return s.handleReadResource(ctx, req.Params)
+	case "resources/subscribe":
+		return s.handleSubscribe(ctx, req.Params, true)
+	case "resources/unsubscribe":
+		return s.handleSubscribe(ctx, req.Params, false)
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q not found", req.Method)}
+	}
+}
+
+// handleInitialize negotiates the protocol version and advertises capabilities.
+func (s *Server) handleInitialize(params json.RawMessage) (interface{}, *rpcError) {
+	var p initializeParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: "invalid initialize params: " + err.Error()}
+		}
+	}
+
+	version := ProtocolVersion
+	for _, supported := range supportedProtocolVersions {
+		if p.ProtocolVersion == supported {
+			version = supported
+			break
+		}
+	}
+
+	s.log.Info("MCP client connected",
+		logger.String("client", p.ClientInfo.Name),
+		logger.String("client_version", p.ClientInfo.Version),
+		logger.String("protocol_version", version),
+	)
+
+	return initializeResult{
+		ProtocolVersion: version,
+		Capabilities: map[string]interface{}{
+			"tools": map[string]interface{}{"listChanged": false},
+			"resources": map[string]interface{}{
+				"subscribe":   s.config.Events != nil,
+				"listChanged": false,
+			},
+		},
+		ServerInfo: Implementation{Name: s.config.Name, Version: s.config.Version},
+		Instructions: fmt.Sprintf(
+			"Controls Apple Music on this Mac. Track and playlist IDs come from search_library and list_playlists. "+
+				"Read maestro://now-playing and maestro://queue for context; subscribe to them to follow changes. "+
+				"Tool calls and resource reads are limited to %d per %s.", s.policy.RateLimit, s.policy.RateWindow),
+	}, nil
+}
+
+// handleListTools returns every registered tool in registration order.
+func (s *Server) handleListTools() interface{} {
+	tools := make([]Tool, 0, len(s.order))
+	for _, name := range s.order {
+		tools = append(tools, s.tools[name].Tool)
+	}
+	return listToolsResult{Tools: tools}
+}
+
+// handleCallTool validates arguments and invokes a tool.
+func (s *Server) handleCallTool(ctx context.Context, params json.RawMessage) (interface{}, *rpcError) {
+	var p callToolParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "invalid tools/call params: " + err.Error()}
+	}
+
+	t, ok := s.tools[p.Name]
+	if !ok {
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown tool %q", p.Name)}
+	}
+
+	if err := s.limiter.Reserve(); err != nil {
+		return nil, rateLimitError(err)
+	}
+
+	result, err := t.call(ctx, p.Arguments)
+	if err != nil {
+		s.log.Debug("Tool call failed", logger.String("tool", p.Name), logger.Error(err))
+		return toolError(err), nil
+	}
+
+	return toolResult(result), nil
+}
+
+// write encodes a message as a single line on the output stream.
+func (s *Server) write(message interface{}) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		s.log.Error("Failed to encode MCP message", logger.Error(err))
+		return
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if s.out == nil {
+		return
+	}
+	if _, err := s.out.Write(append(data, '\n')); err != nil {
+		s.log.Error("Failed to write MCP message", logger.Error(err))
+	}
+}
+
+// writeError sends a JSON-RPC error response.
+func (s *Server) writeError(id json.RawMessage, rpcErr *rpcError) {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	s.write(response{JSONRPC: jsonRPCVersion, ID: id, Error: rpcErr})
+}
+
+// toolResult wraps a tool's structured output in a tools/call result.
+func toolResult(value interface{}) callToolResult {
+	text, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return toolError(err)
+	}
+	return callToolResult{
+		Content:           []content{{Type: "text", Text: string(text)}},
+		StructuredContent: value,
+	}
+}
+
+// rateLimitError reports a request refused by the rate limiter, passing on
+// the wait from the domain error so clients can back off.
+func rateLimitError(err error) *rpcError {
+	rpcErr := &rpcError{Code: codeRateLimited, Message: err.Error()}
+	var domainErr *music.DomainError
+	if errors.As(err, &domainErr) {
+		if retryAfter, ok := domainErr.GetContext("retry_after_seconds"); ok {
+			rpcErr.Data = map[string]interface{}{"retry_after_seconds": retryAfter}
+		}
+	}
+	return rpcErr
+}
+
+// toolError reports a failed tool call to the assistant.
+func toolError(err error) callToolResult {
+	return callToolResult{
+		Content: []content{{Type: "text", Text: err.Error()}},
+		IsError: true,
+	}
+}
@@ -0,0 +1,283 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/infrastructure/memory"
+)
+
+// stubRepos implements the repository methods exercised by these tests. Calling
+// any other method panics through the nil embedded interface.
+type stubRepos struct {
+	music.RepositoryManager
+	volume   *music.Volume
+	searched *music.LibrarySearchOptions
+}
+
+func (s *stubRepos) SetVolume(_ context.Context, volume music.Volume) error {
+	s.volume = &volume
+	return nil
+}
+
+func (s *stubRepos) Search(_ context.Context, options music.LibrarySearchOptions) ([]*music.Track, error) {
+	s.searched = &options
+	track, _ := music.NewTrack(music.NewTrackID("42"), "So What", "Miles Davis", "Kind of Blue", music.NewDuration(562))
+	return []*music.Track{track}, nil
+}
+
+// roundTrip sends requests to a fresh server and returns the decoded responses.
+func roundTrip(t *testing.T, repos music.RepositoryManager, requests ...string) []map[string]interface{} {
+	t.Helper()
+
+	server := NewServer(repos, nil)
+	var out strings.Builder
+	if err := server.Serve(context.Background(), strings.NewReader(strings.Join(requests, "\n")), &out); err != nil {
+		t.Fatalf("unexpected serve error: %v", err)
+	}
+
+	var responses []map[string]interface{}
+	scanner := bufio.NewScanner(strings.NewReader(out.String()))
+	for scanner.Scan() {
+		var resp map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid response line %q: %v", scanner.Text(), err)
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+func callTool(id int, name, arguments string) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":%q,"arguments":%s}}`,
+		id, name, arguments)
+}
+
+func result(t *testing.T, resp map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	if errObj, ok := resp["error"]; ok {
+		t.Fatalf("unexpected JSON-RPC error: %v", errObj)
+	}
+	return resp["result"].(map[string]interface{})
+}
+
+func TestInitializeAndNotifications(t *testing.T) {
+	responses := roundTrip(t, &stubRepos{},
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","clientInfo":{"name":"test","version":"1"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+	)
+
+	if len(responses) != 2 {
+		t.Fatalf("expected 2 responses (notifications get none), got %d", len(responses))
+	}
+
+	init := result(t, responses[0])
+	if init["protocolVersion"] != "2024-11-05" {
+		t.Errorf("expected negotiated version 2024-11-05, got %v", init["protocolVersion"])
+	}
+	if _, ok := init["capabilities"].(map[string]interface{})["tools"]; !ok {
+		t.Error("expected tools capability")
+	}
+}
+
+func TestUnknownMethod(t *testing.T) {
+	responses := roundTrip(t, &stubRepos{}, `{"jsonrpc":"2.0","id":7,"method":"bogus"}`)
+
+	errObj, ok := responses[0]["error"].(map[string]interface{})
+	if !ok {
+		t.Fatal("expected error response")
+	}
+	if errObj["code"].(float64) != codeMethodNotFound {
+		t.Errorf("expected code %d, got %v", codeMethodNotFound, errObj["code"])
+	}
+}
+
+func TestNotificationWithID(t *testing.T) {
+	responses := roundTrip(t, &stubRepos{}, `{"jsonrpc":"2.0","id":3,"method":"notifications/initialized"}`)
+
+	if len(responses) != 1 {
+		t.Fatalf("expected 1 response, got %d", len(responses))
+	}
+	errObj, ok := responses[0]["error"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected error response, got %v", responses[0])
+	}
+	if errObj["code"].(float64) != codeInvalidRequest {
+		t.Errorf("expected code %d, got %v", codeInvalidRequest, errObj["code"])
+	}
+}
+
+func TestListToolsSchemas(t *testing.T) {
+	responses := roundTrip(t, &stubRepos{}, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+
+	var listed listToolsResult
+	data, _ := json.Marshal(result(t, responses[0]))
+	if err := json.Unmarshal(data, &listed); err != nil {
+		t.Fatalf("invalid tools/list result: %v", err)
+	}
+
+	byName := make(map[string]Tool)
+	for _, tool := range listed.Tools {
+		byName[tool.Name] = tool
+	}
+
+	for _, name := range []string{"play", "search_library", "add_to_queue", "create_playlist", "set_volume"} {
+		if _, ok := byName[name]; !ok {
+			t.Errorf("expected tool %q to be listed", name)
+		}
+	}
+
+	level := byName["set_volume"].InputSchema.Properties["level"]
+	if level.Type != "integer" || *level.Minimum != music.MinVolumeLevel || *level.Maximum != music.MaxVolumeLevel {
+		t.Errorf("expected volume schema derived from domain bounds, got %+v", level)
+	}
+
+	mode := byName["set_repeat"].InputSchema.Properties["mode"]
+	if strings.Join(mode.Enum, ",") != "off,all,one" {
+		t.Errorf("expected repeat mode enum from domain, got %v", mode.Enum)
+	}
+
+	if len(byName["play"].InputSchema.Required) != 0 {
+		t.Errorf("expected play track_id to be optional, got required %v", byName["play"].InputSchema.Required)
+	}
+}
+
+func TestCallToolValidation(t *testing.T) {
+	repos := &stubRepos{}
+	responses := roundTrip(t, repos,
+		callTool(1, "set_volume", `{"level":150}`),
+		callTool(2, "set_volume", `{}`),
+		callTool(3, "set_volume", `{"level":40}`),
+		callTool(4, "search_library", `{"query":"so what"}`),
+	)
+
+	for i, expectError := range []bool{true, true, false, false} {
+		isError, _ := result(t, responses[i])["isError"].(bool)
+		if isError != expectError {
+			t.Errorf("call %d: expected isError %v, got %v (%v)", i+1, expectError, isError, responses[i])
+		}
+	}
+
+	if repos.volume == nil || repos.volume.Level() != 40 {
+		t.Errorf("expected volume 40 to reach the repository, got %v", repos.volume)
+	}
+
+	if repos.searched == nil || repos.searched.Limit != defaultSearchLimit {
+		t.Errorf("expected default search limit %d, got %+v", defaultSearchLimit, repos.searched)
+	}
+
+	structured := result(t, responses[3])["structuredContent"].(map[string]interface{})
+	track := structured["tracks"].([]interface{})[0].(map[string]interface{})
+	if track["id"] != "42" || track["duration"].(float64) != 562 {
+		t.Errorf("expected domain JSON for track, got %v", track)
+	}
+}
+
+func TestCallToolTrackIDs(t *testing.T) {
+	// The stub has no Play or queue methods, so a rejected ID never reaches them
+	responses := roundTrip(t, &stubRepos{},
+		callTool(1, "play", `{"track_id":"1\ndo shell script \"rm -rf ~\""}`),
+		callTool(2, "add_to_queue", `{"track_ids":["1001","1 or true"]}`),
+		callTool(3, "play_next", `{"track_id":"abc"}`),
+		callTool(4, "rate_track", `{"track_id":"-1","rating":20}`),
+	)
+
+	for i, resp := range responses {
+		res := result(t, resp)
+		text := res["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+		if isError, _ := res["isError"].(bool); !isError || !strings.Contains(text, "not a database ID") {
+			t.Errorf("call %d: expected the track ID to be rejected, got %v", i+1, res)
+		}
+	}
+}
+
+func TestCallToolRateLimit(t *testing.T) {
+	requests := make([]string, 0, 21)
+	for i := 1; i <= 21; i++ {
+		requests = append(requests, callTool(i, "set_volume", `{"level":10}`))
+	}
+
+	responses := roundTrip(t, &stubRepos{}, requests...)
+
+	// Tools are limited like resources, with the wait to back off for
+	errObj, ok := responses[20]["error"].(map[string]interface{})
+	if !ok {
+		t.Fatal("expected the 21st call within a minute to be rate limited")
+	}
+	if errObj["code"].(float64) != codeRateLimited || !strings.Contains(errObj["message"].(string), music.ErrRateLimited.Error()) {
+		t.Errorf("expected code %d with the rate limit message, got %v", codeRateLimited, errObj)
+	}
+	data, _ := errObj["data"].(map[string]interface{})
+	if retryAfter, _ := data["retry_after_seconds"].(float64); retryAfter <= 0 {
+		t.Errorf("expected a positive retry_after_seconds, got %v", errObj["data"])
+	}
+}
+
+func TestPositionalPlaylistTools(t *testing.T) {
+	repos := memory.NewRepositories(memory.DemoConfig())
+	openers := music.NewPlaylistID("A1B2C3D4E5F60001")
+	before, err := repos.GetPlaylist(context.Background(), openers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := before.Tracks[0].Value()
+
+	responses := roundTrip(t, repos,
+		callTool(1, "insert_into_playlist", fmt.Sprintf(`{"playlist_id":"A1B2C3D4E5F60001","position":1,"track_ids":[%q]}`, first)),
+		callTool(2, "insert_into_playlist", fmt.Sprintf(`{"playlist_id":"A1B2C3D4E5F60001","position":1,"track_ids":[%q],"allow_duplicates":true}`, first)),
+		callTool(3, "move_in_playlist", `{"playlist_id":"A1B2C3D4E5F60001","from":0,"to":99}`),
+		callTool(4, "remove_from_playlist_at", `{"playlist_id":"A1B2C3D4E5F60001","position":0}`),
+		callTool(5, "reorder_playlist", `{"playlist_id":"A1B2C3D4E5F60001","track_ids":[]}`),
+	)
+
+	for i, expectError := range []bool{true, false, true, false, true} {
+		isError, _ := result(t, responses[i])["isError"].(bool)
+		if isError != expectError {
+			t.Errorf("call %d: expected isError %v, got %v (%v)", i+1, expectError, isError, responses[i])
+		}
+	}
+
+	// The duplicate went in at position 1, the original at 0 was removed and
+	// the empty reorder changed nothing
+	after, _ := repos.GetPlaylist(context.Background(), openers)
+	if len(after.Tracks) != len(before.Tracks) || after.Tracks[0].Value() != first {
+		t.Errorf("expected the inserted copy to remain first, got %v", after.Tracks)
+	}
+}
+
+func TestRatingTools(t *testing.T) {
+	repos := memory.NewRepositories(memory.DemoConfig())
+	if err := repos.Play(context.Background(), music.NewTrackID("1001")); err != nil {
+		t.Fatal(err)
+	}
+
+	responses := roundTrip(t, repos,
+		callTool(1, "rate_track", `{"rating":80}`),
+		callTool(2, "rate_track", `{"track_id":"1003","rating":100}`),
+		callTool(3, "rate_track", `{"rating":120}`),
+		callTool(4, "set_track_preference", `{"preference":"disliked"}`),
+		callTool(5, "get_tracks_by_rating", `{"minimum":60}`),
+		callTool(6, "get_tracks_by_rating", `{"minimum":100,"maximum":60}`),
+	)
+
+	for i, expectError := range []bool{false, false, true, false, false, true} {
+		isError, _ := result(t, responses[i])["isError"].(bool)
+		if isError != expectError {
+			t.Errorf("call %d: expected isError %v, got %v (%v)", i+1, expectError, isError, responses[i])
+		}
+	}
+
+	tracks, _ := repos.GetTracksByRating(context.Background(), music.NewRatingFromStars(3), music.NewRatingFromStars(5))
+	if len(tracks) != 2 || tracks[0].ID.Value() != "1003" || tracks[1].ID.Value() != "1001" {
+		t.Errorf("expected 1003 then 1001, got %v", tracks)
+	}
+	if !tracks[1].Disliked {
+		t.Error("expected the current track to be disliked")
+	}
+}
@@ -0,0 +1,644 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+const (
+	// defaultSearchLimit is used when search_library is called without a limit
+	defaultSearchLimit = 25
+
+	// defaultUpNextCount is used when get_up_next is called without a count
+	defaultUpNextCount = 10
+)
+
+// tool binds a tool description to its handler.
+type tool struct {
+	Tool
+	call func(ctx context.Context, arguments json.RawMessage) (interface{}, error)
+}
+
+// newTool creates a tool whose input schema is derived from the argument type A.
+// Arguments are checked against the schema's required properties and decoded
+// strictly, so domain UnmarshalJSON validation applies before the handler runs.
+func newTool[A any](name, title, description string, handle func(ctx context.Context, args A) (interface{}, error)) *tool {
+	var zero A
+	schema := SchemaFor(zero)
+
+	return &tool{
+		Tool: Tool{
+			Name:        name,
+			Title:       title,
+			Description: description,
+			InputSchema: schema,
+		},
+		call: func(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
+			args, err := decodeArguments[A](arguments, schema)
+			if err != nil {
+				return nil, err
+			}
+			return handle(ctx, args)
+		},
+	}
+}
+
+// decodeArguments decodes tool arguments into A after checking required properties.
+func decodeArguments[A any](arguments json.RawMessage, schema *Schema) (A, error) {
+	var args A
+
+	if len(bytes.TrimSpace(arguments)) == 0 || string(bytes.TrimSpace(arguments)) == "null" {
+		arguments = json.RawMessage("{}")
+	}
+
+	var present map[string]json.RawMessage
+	if err := json.Unmarshal(arguments, &present); err != nil {
+		return args, music.NewDomainErrorWithCause(music.ErrInvalidOperation, "arguments must be a JSON object", err)
+	}
+
+	var missing []string
+	for _, name := range schema.Required {
+		if _, ok := present[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return args, music.NewDomainError(music.ErrInvalidOperation, "missing required arguments: "+strings.Join(missing, ", "))
+	}
+
+	// Required lists must not be empty: an empty track_ids would, for
+	// one, wipe a playlist through reorder_playlist
+	for _, name := range schema.Required {
+		property := schema.Properties[name]
+		if property == nil || property.MinItems == nil {
+			continue
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal(present[name], &items); err == nil && len(items) < *property.MinItems {
+			return args, music.NewDomainError(music.ErrInvalidOperation,
+				fmt.Sprintf("%s needs at least %d item(s)", name, *property.MinItems))
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(arguments))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&args); err != nil {
+		var domainErr *music.DomainError
+		if errors.As(err, &domainErr) {
+			return args, err
+		}
+		return args, music.NewDomainErrorWithCause(music.ErrInvalidOperation, "invalid arguments", err)
+	}
+
+	return args, nil
+}
+
+// register adds tools to the server in listing order.
+func (s *Server) register(tools ...*tool) {
+	for _, t := range tools {
+		s.tools[t.Name] = t
+		s.order = append(s.order, t.Name)
+	}
+}
+
+// Tool argument types. Their JSON schemas are derived by SchemaFor.
+type (
+	noArgs struct{}
+
+	playArgs struct {
+		TrackID music.TrackID `json:"track_id,omitempty" desc:"Track to play; omit to resume the current track"`
+	}
+
+	seekArgs struct {
+		Position music.Duration `json:"position" desc:"Position within the current track, in seconds"`
+	}
+
+	volumeArgs struct {
+		Level music.Volume `json:"level" desc:"Volume level from 0 to 100"`
+	}
+
+	shuffleArgs struct {
+		Enabled bool `json:"enabled" desc:"Whether shuffle should be enabled"`
+	}
+
+	repeatArgs struct {
+		Mode music.RepeatMode `json:"mode"`
+	}
+
+	searchArgs struct {
+		Query  string `json:"query,omitempty" desc:"Free text matched against title, artist and album"`
+		Artist string `json:"artist,omitempty" desc:"Only return tracks whose artist contains this text"`
+		Album  string `json:"album,omitempty" desc:"Only return tracks whose album contains this text"`
+		Limit  int    `json:"limit,omitempty" min:"0" desc:"Maximum number of results (default 25)"`
+		Offset int    `json:"offset,omitempty" min:"0" desc:"Number of results to skip"`
+	}
+
+	trackArgs struct {
+		TrackID music.TrackID `json:"track_id"`
+	}
+
+	trackListArgs struct {
+		TrackIDs []music.TrackID `json:"track_ids"`
+	}
+
+	positionArgs struct {
+		Position int `json:"position" min:"0" desc:"0-based position in the queue"`
+	}
+
+	upNextArgs struct {
+		Count int `json:"count,omitempty" min:"0" desc:"Number of upcoming tracks (default 10)"`
+	}
+
+	playlistArgs struct {
+		PlaylistID music.PlaylistID `json:"playlist_id"`
+	}
+
+	nameArgs struct {
+		Name string `json:"name" desc:"Playlist name"`
+	}
+
+	renamePlaylistArgs struct {
+		PlaylistID music.PlaylistID `json:"playlist_id"`
+		Name       string           `json:"name" desc:"New playlist name"`
+	}
+
+	playlistTracksArgs struct {
+		PlaylistID music.PlaylistID `json:"playlist_id"`
+		TrackIDs   []music.TrackID  `json:"track_ids"`
+	}
+
+	playlistTrackArgs struct {
+		PlaylistID music.PlaylistID `json:"playlist_id"`
+		TrackID    music.TrackID    `json:"track_id"`
+	}
+
+	insertPlaylistArgs struct {
+		PlaylistID      music.PlaylistID `json:"playlist_id"`
+		Position        int              `json:"position" min:"0" desc:"0-based position to insert before; the track count appends"`
+		TrackIDs        []music.TrackID  `json:"track_ids"`
+		AllowDuplicates bool             `json:"allow_duplicates,omitempty" desc:"Allow tracks that are already in the playlist"`
+	}
+
+	movePlaylistArgs struct {
+		PlaylistID music.PlaylistID `json:"playlist_id"`
+		From       int              `json:"from" min:"0" desc:"0-based position of the track to move"`
+		To         int              `json:"to" min:"0" desc:"0-based position the track ends up at"`
+	}
+
+	playlistPositionArgs struct {
+		PlaylistID music.PlaylistID `json:"playlist_id"`
+		Position   int              `json:"position" min:"0" desc:"0-based position in the playlist"`
+	}
+
+	rateArgs struct {
+		TrackID music.TrackID `json:"track_id,omitempty" desc:"Track to rate; omit for the current track"`
+		Rating  music.Rating  `json:"rating" desc:"Rating from 0 (unrated) to 100, 20 per star"`
+	}
+
+	preferenceArgs struct {
+		TrackID    music.TrackID    `json:"track_id,omitempty" desc:"Track to mark; omit for the current track"`
+		Preference music.Preference `json:"preference"`
+	}
+
+	ratingRangeArgs struct {
+		Minimum music.Rating  `json:"minimum" desc:"Lowest rating to return, 20 per star"`
+		Maximum *music.Rating `json:"maximum,omitempty" desc:"Highest rating to return (default 100)"`
+	}
+
+	duplicatePlaylistArgs struct {
+		PlaylistID music.PlaylistID `json:"playlist_id"`
+		Name       string           `json:"name" desc:"Name for the copy"`
+	}
+)
+
+// statusView is the structured result of get_status.
+type statusView struct {
+	Player *music.Player `json:"player"`
+	Track  *music.Track  `json:"track"`
+}
+
+// playlistSummary describes a playlist without its full track list.
+type playlistSummary struct {
+	ID         music.PlaylistID   `json:"id"`
+	Name       string             `json:"name"`
+	Type       music.PlaylistType `json:"type"`
+	ReadOnly   bool               `json:"read_only"`
+	TrackCount int                `json:"track_count"`
+}
+
+func summarizePlaylist(p *music.Playlist) playlistSummary {
+	return playlistSummary{
+		ID:         p.ID,
+		Name:       p.Name,
+		Type:       p.Type,
+		ReadOnly:   p.ReadOnly,
+		TrackCount: p.TrackCount(),
+	}
+}
+
+// message is the structured result of tools that only acknowledge an action.
+func message(format string, args ...interface{}) interface{} {
+	return map[string]string{"message": fmt.Sprintf(format, args...)}
+}
+
+// registerTools registers every tool exposed by the server.
+func (s *Server) registerTools() {
+	s.registerPlaybackTools()
+	s.registerLibraryTools()
+	s.registerQueueTools()
+	s.registerPlaylistTools()
+	s.registerRatingTools()
+}
+
+func (s *Server) registerPlaybackTools() {
+	s.register(
+		newTool("get_status", "Get player status",
+			"Returns the player state, volume, position, shuffle and repeat settings and the current track.",
+			func(ctx context.Context, _ noArgs) (interface{}, error) {
+				return s.nowPlaying(ctx)
+			}),
+		newTool("play", "Play",
+			"Plays a specific track. Without a track_id, resumes paused playback or starts the current selection when stopped.",
+			func(ctx context.Context, args playArgs) (interface{}, error) {
+				if args.TrackID.IsEmpty() {
+					if err := s.repos.Start(ctx); err != nil {
+						return nil, err
+					}
+					return message("Playback started"), nil
+				}
+				if err := requireTrackIDs(args.TrackID); err != nil {
+					return nil, err
+				}
+				if err := s.repos.Play(ctx, args.TrackID); err != nil {
+					return nil, err
+				}
+				return message("Playing track %s", args.TrackID), nil
+			}),
+		newTool("pause", "Pause", "Pauses playback.",
+			func(ctx context.Context, _ noArgs) (interface{}, error) {
+				if err := s.repos.Pause(ctx); err != nil {
+					return nil, err
+				}
+				return message("Playback paused"), nil
+			}),
+		newTool("stop", "Stop", "Stops playback.",
+			func(ctx context.Context, _ noArgs) (interface{}, error) {
+				if err := s.repos.Stop(ctx); err != nil {
+					return nil, err
+				}
+				return message("Playback stopped"), nil
+			}),
+		newTool("next_track", "Next track", "Skips to the next track.",
+			func(ctx context.Context, _ noArgs) (interface{}, error) {
+				if err := s.repos.Next(ctx); err != nil {
+					return nil, err
+				}
+				return message("Skipped to next track"), nil
+			}),
+		newTool("previous_track", "Previous track", "Goes back to the previous track.",
+			func(ctx context.Context, _ noArgs) (interface{}, error) {
+				if err := s.repos.Previous(ctx); err != nil {
+					return nil, err
+				}
+				return message("Skipped to previous track"), nil
+			}),
+		newTool("seek", "Seek", "Moves the playback position within the current track.",
+			func(ctx context.Context, args seekArgs) (interface{}, error) {
+				if err := s.repos.Seek(ctx, args.Position); err != nil {
+					return nil, err
+				}
+				return message("Position set to %s", args.Position), nil
+			}),
+		newTool("set_volume", "Set volume", "Sets the playback volume.",
+			func(ctx context.Context, args volumeArgs) (interface{}, error) {
+				if err := s.repos.SetVolume(ctx, args.Level); err != nil {
+					return nil, err
+				}
+				return message("Volume set to %s", args.Level), nil
+			}),
+		newTool("set_shuffle", "Set shuffle", "Enables or disables shuffle.",
+			func(ctx context.Context, args shuffleArgs) (interface{}, error) {
+				if err := s.repos.SetShuffle(ctx, args.Enabled); err != nil {
+					return nil, err
+				}
+				return message("Shuffle set to %t", args.Enabled), nil
+			}),
+		newTool("set_repeat", "Set repeat", "Sets the repeat mode.",
+			func(ctx context.Context, args repeatArgs) (interface{}, error) {
+				if err := s.repos.SetRepeat(ctx, args.Mode); err != nil {
+					return nil, err
+				}
+				return message("Repeat set to %s", args.Mode), nil
+			}),
+	)
+}
+
+func (s *Server) registerLibraryTools() {
+	s.register(
+		newTool("search_library", "Search library",
+			"Searches the local music library. At least one of query, artist or album is required.",
+			func(ctx context.Context, args searchArgs) (interface{}, error) {
+				if args.Limit == 0 {
+					args.Limit = defaultSearchLimit
+				}
+				tracks, err := s.repos.Search(ctx, music.LibrarySearchOptions{
+					Query:  args.Query,
+					Artist: args.Artist,
+					Album:  args.Album,
+					Limit:  args.Limit,
+					Offset: args.Offset,
+				})
+				if err != nil {
+					return nil, err
+				}
+				return map[string]interface{}{"tracks": tracks, "count": len(tracks), "offset": args.Offset}, nil
+			}),
+		newTool("get_track", "Get track", "Returns the metadata of a track.",
+			func(ctx context.Context, args trackArgs) (interface{}, error) {
+				if err := requireTrackIDs(args.TrackID); err != nil {
+					return nil, err
+				}
+				return s.repos.GetTrack(ctx, args.TrackID)
+			}),
+	)
+}
+
+func (s *Server) registerQueueTools() {
+	s.register(
+		newTool("get_queue", "Get queue", "Returns the play queue and the current position in it.",
+			func(ctx context.Context, _ noArgs) (interface{}, error) {
+				return s.queueView(ctx)
+			}),
+		newTool("get_up_next", "Get up next", "Returns the tracks that will play next from the queue.",
+			func(ctx context.Context, args upNextArgs) (interface{}, error) {
+				if args.Count == 0 {
+					args.Count = defaultUpNextCount
+				}
+				tracks, err := s.repos.GetUpNext(ctx, args.Count)
+				if err != nil {
+					return nil, err
+				}
+				return map[string]interface{}{"tracks": tracks}, nil
+			}),
+		newTool("add_to_queue", "Add to queue", "Adds tracks to the end of the queue.",
+			func(ctx context.Context, args trackListArgs) (interface{}, error) {
+				if err := requireTrackIDs(args.TrackIDs...); err != nil {
+					return nil, err
+				}
+				if err := s.repos.AddTracksToQueue(ctx, args.TrackIDs); err != nil {
+					return nil, err
+				}
+				return message("Added %d track(s) to the queue", len(args.TrackIDs)), nil
+			}),
+		newTool("play_next", "Play next", "Queues a track to play right after the current one.",
+			func(ctx context.Context, args trackArgs) (interface{}, error) {
+				if err := requireTrackIDs(args.TrackID); err != nil {
+					return nil, err
+				}
+				if err := s.repos.PlayNext(ctx, args.TrackID); err != nil {
+					return nil, err
+				}
+				return message("Track %s will play next", args.TrackID), nil
+			}),
+		newTool("remove_from_queue", "Remove from queue", "Removes the track at a queue position.",
+			func(ctx context.Context, args positionArgs) (interface{}, error) {
+				if err := s.repos.RemoveFromQueue(ctx, args.Position); err != nil {
+					return nil, err
+				}
+				return message("Removed queue position %d", args.Position), nil
+			}),
+		newTool("jump_to_queue_position", "Jump to queue position", "Starts playing the queue from a position.",
+			func(ctx context.Context, args positionArgs) (interface{}, error) {
+				if err := s.repos.SetQueuePosition(ctx, args.Position); err != nil {
+					return nil, err
+				}
+				return message("Playing queue from position %d", args.Position), nil
+			}),
+		newTool("clear_queue", "Clear queue", "Removes every track from the queue.",
+			func(ctx context.Context, _ noArgs) (interface{}, error) {
+				if err := s.repos.ClearQueue(ctx); err != nil {
+					return nil, err
+				}
+				return message("Queue cleared"), nil
+			}),
+		newTool("shuffle_queue", "Shuffle queue", "Shuffles the tracks that have not played yet.",
+			func(ctx context.Context, _ noArgs) (interface{}, error) {
+				if err := s.repos.ShuffleQueue(ctx); err != nil {
+					return nil, err
+				}
+				return message("Queue shuffled"), nil
+			}),
+	)
+}
+
+func (s *Server) registerPlaylistTools() {
+	s.register(
+		newTool("list_playlists", "List playlists", "Lists all playlists with their type and track count.",
+			func(ctx context.Context, _ noArgs) (interface{}, error) {
+				return s.playlistSummaries(ctx)
+			}),
+		newTool("get_playlist", "Get playlist", "Returns a playlist and its tracks.",
+			func(ctx context.Context, args playlistArgs) (interface{}, error) {
+				return s.playlistView(ctx, args.PlaylistID)
+			}),
+		newTool("create_playlist", "Create playlist", "Creates an empty user playlist.",
+			func(ctx context.Context, args nameArgs) (interface{}, error) {
+				playlist, err := s.repos.CreatePlaylist(ctx, args.Name)
+				if err != nil {
+					return nil, err
+				}
+				return summarizePlaylist(playlist), nil
+			}),
+		newTool("rename_playlist", "Rename playlist", "Renames a user playlist.",
+			func(ctx context.Context, args renamePlaylistArgs) (interface{}, error) {
+				playlist, err := s.repos.GetPlaylist(ctx, args.PlaylistID)
+				if err != nil {
+					return nil, err
+				}
+				if playlist.ReadOnly {
+					return nil, music.NewDomainError(music.ErrPlaylistReadOnly, "cannot modify read-only playlist")
+				}
+				playlist.Name = args.Name
+				if err := s.repos.UpdatePlaylist(ctx, playlist); err != nil {
+					return nil, err
+				}
+				return summarizePlaylist(playlist), nil
+			}),
+		newTool("delete_playlist", "Delete playlist", "Deletes a user playlist.",
+			func(ctx context.Context, args playlistArgs) (interface{}, error) {
+				if err := s.repos.DeletePlaylist(ctx, args.PlaylistID); err != nil {
+					return nil, err
+				}
+				return message("Playlist %s deleted", args.PlaylistID), nil
+			}),
+		newTool("add_to_playlist", "Add to playlist", "Appends tracks to a user playlist.",
+			func(ctx context.Context, args playlistTracksArgs) (interface{}, error) {
+				if err := requireTrackIDs(args.TrackIDs...); err != nil {
+					return nil, err
+				}
+				for _, trackID := range args.TrackIDs {
+					if err := s.repos.AddTrackToPlaylist(ctx, args.PlaylistID, trackID); err != nil {
+						return nil, err
+					}
+				}
+				return message("Added %d track(s) to playlist %s", len(args.TrackIDs), args.PlaylistID), nil
+			}),
+		newTool("remove_from_playlist", "Remove from playlist", "Removes a track from a user playlist.",
+			func(ctx context.Context, args playlistTrackArgs) (interface{}, error) {
+				if err := requireTrackIDs(args.TrackID); err != nil {
+					return nil, err
+				}
+				if err := s.repos.RemoveTrackFromPlaylist(ctx, args.PlaylistID, args.TrackID); err != nil {
+					return nil, err
+				}
+				return message("Removed track %s from playlist %s", args.TrackID, args.PlaylistID), nil
+			}),
+		newTool("insert_into_playlist", "Insert into playlist", "Inserts tracks into a user playlist at a position.",
+			func(ctx context.Context, args insertPlaylistArgs) (interface{}, error) {
+				err := s.editPlaylist(ctx, args.PlaylistID, func(playlist *music.Playlist) error {
+					playlist.AllowDuplicates = args.AllowDuplicates
+					return playlist.InsertAt(args.Position, args.TrackIDs...)
+				})
+				if err != nil {
+					return nil, err
+				}
+				return message("Inserted %d track(s) into playlist %s", len(args.TrackIDs), args.PlaylistID), nil
+			}),
+		newTool("move_in_playlist", "Move in playlist", "Moves a track of a user playlist to another position.",
+			func(ctx context.Context, args movePlaylistArgs) (interface{}, error) {
+				err := s.editPlaylist(ctx, args.PlaylistID, func(playlist *music.Playlist) error {
+					return playlist.Move(args.From, args.To)
+				})
+				if err != nil {
+					return nil, err
+				}
+				return message("Moved track from position %d to %d in playlist %s", args.From, args.To, args.PlaylistID), nil
+			}),
+		newTool("remove_from_playlist_at", "Remove from playlist at", "Removes the track at a position from a user playlist.",
+			func(ctx context.Context, args playlistPositionArgs) (interface{}, error) {
+				var removed music.TrackID
+				err := s.editPlaylist(ctx, args.PlaylistID, func(playlist *music.Playlist) error {
+					var err error
+					removed, err = playlist.RemoveAt(args.Position)
+					return err
+				})
+				if err != nil {
+					return nil, err
+				}
+				return message("Removed track %s from playlist %s", removed, args.PlaylistID), nil
+			}),
+		newTool("reorder_playlist", "Reorder playlist", "Replaces the tracks of a user playlist, in order; tracks may be dropped or repeated.",
+			func(ctx context.Context, args playlistTracksArgs) (interface{}, error) {
+				if err := requireTrackIDs(args.TrackIDs...); err != nil {
+					return nil, err
+				}
+				if err := s.repos.ReorderPlaylistTracks(ctx, args.PlaylistID, args.TrackIDs); err != nil {
+					return nil, err
+				}
+				return message("Playlist %s reordered", args.PlaylistID), nil
+			}),
+		newTool("duplicate_playlist", "Duplicate playlist", "Copies a playlist into a new user playlist.",
+			func(ctx context.Context, args duplicatePlaylistArgs) (interface{}, error) {
+				playlist, err := s.repos.DuplicatePlaylist(ctx, args.PlaylistID, args.Name)
+				if err != nil {
+					return nil, err
+				}
+				return summarizePlaylist(playlist), nil
+			}),
+	)
+}
+
+func (s *Server) registerRatingTools() {
+	s.register(
+		newTool("rate_track", "Rate track", "Sets the rating of a track; a rating of 0 clears it.",
+			func(ctx context.Context, args rateArgs) (interface{}, error) {
+				track, err := s.ratingTarget(ctx, args.TrackID)
+				if err != nil {
+					return nil, err
+				}
+				if err := s.repos.SetRating(ctx, track.ID, args.Rating); err != nil {
+					return nil, err
+				}
+				return message("Rated %s %s", track.Title, args.Rating), nil
+			}),
+		newTool("set_track_preference", "Set track preference",
+			"Marks a track loved or disliked, or clears both with none.",
+			func(ctx context.Context, args preferenceArgs) (interface{}, error) {
+				track, err := s.ratingTarget(ctx, args.TrackID)
+				if err != nil {
+					return nil, err
+				}
+				if err := s.repos.SetPreference(ctx, track.ID, args.Preference); err != nil {
+					return nil, err
+				}
+				return message("Marked %s as %s", track.Title, args.Preference), nil
+			}),
+		newTool("get_tracks_by_rating", "Get tracks by rating",
+			"Returns the tracks rated between minimum and maximum inclusive, best rated first.",
+			func(ctx context.Context, args ratingRangeArgs) (interface{}, error) {
+				maximum := music.NewRating(music.MaxRating)
+				if args.Maximum != nil {
+					maximum = *args.Maximum
+				}
+				tracks, err := s.repos.GetTracksByRating(ctx, args.Minimum, maximum)
+				if err != nil {
+					return nil, err
+				}
+				return map[string]interface{}{"tracks": tracks, "count": len(tracks)}, nil
+			}),
+	)
+}
+
+// ratingTarget returns the track with trackID, or the current track when
+// trackID is empty.
+func (s *Server) ratingTarget(ctx context.Context, trackID music.TrackID) (*music.Track, error) {
+	if !trackID.IsEmpty() {
+		if err := requireTrackIDs(trackID); err != nil {
+			return nil, err
+		}
+		return s.repos.GetTrack(ctx, trackID)
+	}
+	track, err := s.repos.GetCurrentTrack(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if track == nil {
+		return nil, music.NewDomainError(music.ErrInvalidPlayerState, "no track is loaded: pass a track_id")
+	}
+	return track, nil
+}
+
+// editPlaylist applies edit to a playlist and writes the resulting tracks back.
+func (s *Server) editPlaylist(ctx context.Context, playlistID music.PlaylistID, edit func(*music.Playlist) error) error {
+	playlist, err := s.repos.GetPlaylist(ctx, playlistID)
+	if err != nil {
+		return err
+	}
+	if err := edit(playlist); err != nil {
+		return err
+	}
+	return s.repos.ReorderPlaylistTracks(ctx, playlist.ID, playlist.Tracks)
+}
+
+// requireTrackIDs rejects empty track IDs, which NewTrackID allows, and
+// anything but the numeric database IDs Music.app gives tracks. Clients
+// are untrusted and backends build scripts from these IDs.
+func requireTrackIDs(trackIDs ...music.TrackID) error {
+	for _, trackID := range trackIDs {
+		if trackID.IsEmpty() {
+			return music.NewDomainError(music.ErrInvalidTrackID, "track ID cannot be empty")
+		}
+		if strings.Trim(trackID.Value(), "0123456789") != "" {
+			return music.NewDomainError(music.ErrInvalidTrackID,
+				fmt.Sprintf("track ID %q is not a database ID", trackID.Value())).
+				WithContext("track_id", trackID.Value())
+		}
+	}
+	return nil
+}
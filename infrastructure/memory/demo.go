@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// demoAlbum is an album in the sample library used by DemoConfig.
+type demoAlbum struct {
+	artist string
+	album  string
+	year   int
+	tracks []demoTrack
+}
+
+type demoTrack struct {
+	title   string
+	seconds int
+}
+
+var demoAlbums = []demoAlbum{
+	{"Miles Davis", "Kind of Blue", 1959, []demoTrack{
+		{"So What", 562}, {"Freddie Freeloader", 589}, {"Blue in Green", 337}, {"All Blues", 693}, {"Flamenco Sketches", 566},
+	}},
+	{"John Coltrane", "Blue Train", 1957, []demoTrack{
+		{"Blue Train", 643}, {"Moment's Notice", 551}, {"Locomotion", 434}, {"I'm Old Fashioned", 478}, {"Lazy Bird", 421},
+	}},
+	{"John Coltrane", "Giant Steps", 1960, []demoTrack{
+		{"Giant Steps", 286}, {"Cousin Mary", 345}, {"Countdown", 141}, {"Spiral", 356},
+		{"Syeeda's Song Flute", 420}, {"Naima", 261}, {"Mr. P.C.", 419},
+	}},
+	{"Dave Brubeck Quartet", "Time Out", 1959, []demoTrack{
+		{"Blue Rondo à la Turk", 404}, {"Strange Meadow Lark", 442}, {"Take Five", 324}, {"Three to Get Ready", 324},
+		{"Kathy's Waltz", 288}, {"Everybody's Jumpin'", 263}, {"Pick Up Sticks", 256},
+	}},
+	{"Bill Evans Trio", "Waltz for Debby", 1961, []demoTrack{
+		{"My Foolish Heart", 296}, {"Waltz for Debby", 414}, {"Detour Ahead", 457},
+		{"My Romance", 432}, {"Some Other Time", 301}, {"Milestones", 392},
+	}},
+}
+
+// demoDateAdded is when the sample tracks were added to the library.
+var demoDateAdded = time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+// DemoConfig returns a configuration with a small sample library and playlists,
+// for running the user interfaces without Music.app.
+func DemoConfig() *Config {
+	config := DefaultConfig()
+
+	id := 1000
+	var favourites []music.TrackID
+	for _, album := range demoAlbums {
+		for i, t := range album.tracks {
+			id++
+			track, _ := music.NewTrackWithMetadata(music.NewTrackID(fmt.Sprint(id)), t.title, album.artist, album.album,
+				music.NewDuration(t.seconds), music.TrackMetadata{
+					AlbumArtist: album.artist,
+					Genre:       "Jazz",
+					Year:        album.year,
+					TrackNumber: i + 1,
+					TrackCount:  len(album.tracks),
+					DiscNumber:  1,
+					DiscCount:   1,
+					DateAdded:   demoDateAdded,
+					BitRate:     256,
+					Kind:        "AAC audio file",
+				})
+			config.Tracks = append(config.Tracks, track)
+			if i == 0 {
+				favourites = append(favourites, track.ID)
+			}
+		}
+	}
+
+	library, _ := music.NewPlaylist(music.NewPlaylistID("LIBRARY"), "Library", music.PlaylistTypeLibrary, true)
+	for _, track := range config.Tracks {
+		library.Tracks = append(library.Tracks, track.ID)
+	}
+
+	openers, _ := music.NewPlaylist(music.NewPlaylistID("A1B2C3D4E5F60001"), "Album Openers", music.PlaylistTypeUser, false)
+	openers.Tracks = favourites
+
+	longPlayers, _ := music.NewPlaylist(music.NewPlaylistID("A1B2C3D4E5F60002"), "Long Players", music.PlaylistTypeSmart, true)
+	for _, track := range config.Tracks {
+		if track.Duration.Minutes() >= 9 {
+			longPlayers.Tracks = append(longPlayers.Tracks, track.ID)
+		}
+	}
+
+	config.Playlists = []*music.Playlist{library, openers, longPlayers}
+	return config
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// History implements music.HistoryRepository in memory.
+type History struct {
+	mu      sync.Mutex
+	entries []*music.HistoryEntry
+	claim   time.Time
+}
+
+var _ music.HistoryRepository = (*History)(nil)
+
+// NewHistory creates an in-memory history holding entries, oldest first.
+func NewHistory(entries ...*music.HistoryEntry) *History {
+	h := &History{}
+	for _, entry := range entries {
+		h.entries = append(h.entries, copyHistoryEntry(entry))
+	}
+	return h
+}
+
+// AppendHistory adds an entry to the end of the log.
+func (h *History) AppendHistory(ctx context.Context, entry *music.HistoryEntry) error {
+	if entry == nil {
+		return music.NewDomainError(music.ErrInvalidOperation, "history entry cannot be nil")
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries = append(h.entries, copyHistoryEntry(entry))
+	return nil
+}
+
+// GetHistory returns the entries filter selects, oldest first.
+func (h *History) GetHistory(ctx context.Context, filter music.HistoryFilter) ([]*music.HistoryEntry, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	selected := music.FilterHistory(h.entries, filter)
+	entries := make([]*music.HistoryEntry, len(selected))
+	for i, entry := range selected {
+		entries[i] = copyHistoryEntry(entry)
+	}
+	return entries, nil
+}
+
+// ClaimPlayback notes that a maestro client started a track at the given
+// time.
+func (h *History) ClaimPlayback(ctx context.Context, at time.Time) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if at.After(h.claim) {
+		h.claim = at
+	}
+	return nil
+}
+
+// LastClaim returns when a maestro client last started a track.
+func (h *History) LastClaim(ctx context.Context) (time.Time, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.claim, nil
+}
+
+// copyHistoryEntry returns a copy of entry that shares no memory with it.
+func copyHistoryEntry(entry *music.HistoryEntry) *music.HistoryEntry {
+	copied := *entry
+	return &copied
+}
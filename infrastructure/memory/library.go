@@ -0,0 +1,230 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// Search finds tracks whose title, artist or album contain the query, filtered
+// by artist and album, case-insensitively.
+func (r *Repositories) Search(ctx context.Context, options music.LibrarySearchOptions) ([]*music.Track, error) {
+	query := strings.TrimSpace(options.Query)
+	artist := strings.TrimSpace(options.Artist)
+	album := strings.TrimSpace(options.Album)
+
+	if query == "" && artist == "" && album == "" {
+		return nil, music.NewDomainError(music.ErrInvalidSearchQuery, "search requires a query, artist or album")
+	}
+	if options.Limit < 0 || options.Offset < 0 {
+		return nil, music.NewDomainError(music.ErrInvalidSearchQuery, "limit and offset cannot be negative")
+	}
+
+	var matches []*music.Track
+	r.mu.Lock()
+	for _, track := range r.tracks {
+		if artist != "" && !containsFold(track.Artist, artist) {
+			continue
+		}
+		if album != "" && !containsFold(track.Album, album) {
+			continue
+		}
+		if music.ScoreTrack(track, options).Score == 0 {
+			continue
+		}
+		matches = append(matches, track)
+	}
+	r.mu.Unlock()
+
+	ranked := music.RankTracks(matches, options)
+	for i, result := range ranked {
+		matches[i] = result.Track
+	}
+	return copyTracks(paginate(matches, options.Limit, options.Offset)), nil
+}
+
+// GetTrack retrieves a specific track by its ID.
+func (r *Repositories) GetTrack(ctx context.Context, trackID music.TrackID) (*music.Track, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	track, ok := r.trackByID[trackID.Value()]
+	if !ok {
+		return nil, music.WrapTrackNotFound(trackID, nil)
+	}
+	copied := *track
+	return &copied, nil
+}
+
+// GetTracks retrieves multiple tracks by their IDs, in the order requested.
+func (r *Repositories) GetTracks(ctx context.Context, trackIDs []music.TrackID) ([]*music.Track, error) {
+	tracks := make([]*music.Track, 0, len(trackIDs))
+	for _, trackID := range trackIDs {
+		track, err := r.GetTrack(ctx, trackID)
+		if err != nil {
+			return nil, err
+		}
+		tracks = append(tracks, track)
+	}
+	return tracks, nil
+}
+
+// GetAllTracks returns all tracks in the library with pagination.
+func (r *Repositories) GetAllTracks(ctx context.Context, limit, offset int) ([]*music.Track, error) {
+	if limit < 0 || offset < 0 {
+		return nil, music.NewDomainError(music.ErrInvalidOperation, "limit and offset cannot be negative")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return copyTracks(paginate(r.tracks, limit, offset)), nil
+}
+
+// GetTrackCount returns the total number of tracks in the library.
+func (r *Repositories) GetTrackCount(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.tracks), nil
+}
+
+// GetPlaylists returns all playlists, followed by the queue.
+func (r *Repositories) GetPlaylists(ctx context.Context) ([]*music.Playlist, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	playlists := make([]*music.Playlist, 0, len(r.playlists)+1)
+	for _, playlist := range r.playlists {
+		playlists = append(playlists, copyPlaylist(playlist))
+	}
+	return append(playlists, copyPlaylist(r.queue)), nil
+}
+
+// GetPlaylist retrieves a specific playlist by its ID.
+func (r *Repositories) GetPlaylist(ctx context.Context, playlistID music.PlaylistID) (*music.Playlist, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	playlist, _, err := r.findPlaylist(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	return copyPlaylist(playlist), nil
+}
+
+// GetPlaylistTracks returns all tracks in a specific playlist.
+func (r *Repositories) GetPlaylistTracks(ctx context.Context, playlistID music.PlaylistID) ([]*music.Track, error) {
+	playlist, err := r.GetPlaylist(ctx, playlistID)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetTracks(ctx, playlist.Tracks)
+}
+
+// GetArtists returns every artist in the library, sorted.
+func (r *Repositories) GetArtists(ctx context.Context) ([]string, error) {
+	return r.distinct(func(t *music.Track) string { return t.Artist }, nil), nil
+}
+
+// GetAlbums returns every album in the library, sorted.
+func (r *Repositories) GetAlbums(ctx context.Context) ([]string, error) {
+	return r.distinct(func(t *music.Track) string { return t.Album }, nil), nil
+}
+
+// GetAlbumsByArtist returns the albums by an artist, sorted.
+func (r *Repositories) GetAlbumsByArtist(ctx context.Context, artist string) ([]string, error) {
+	return r.distinct(
+		func(t *music.Track) string { return t.Album },
+		func(t *music.Track) bool { return strings.EqualFold(t.Artist, artist) },
+	), nil
+}
+
+// GetTracksByArtist returns the tracks by an artist, in library order.
+func (r *Repositories) GetTracksByArtist(ctx context.Context, artist string) ([]*music.Track, error) {
+	return r.filter(func(t *music.Track) bool { return strings.EqualFold(t.Artist, artist) }), nil
+}
+
+// GetTracksByAlbum returns the tracks from an album, in library order.
+func (r *Repositories) GetTracksByAlbum(ctx context.Context, album string) ([]*music.Track, error) {
+	return r.filter(func(t *music.Track) bool { return strings.EqualFold(t.Album, album) }), nil
+}
+
+// findPlaylist returns a playlist, including the queue, and its index in
+// r.playlists (-1 for the queue). Callers hold the lock.
+func (r *Repositories) findPlaylist(playlistID music.PlaylistID) (*music.Playlist, int, error) {
+	if playlistID.Equals(r.queue.ID) {
+		return r.queue, -1, nil
+	}
+	for i, playlist := range r.playlists {
+		if playlist.ID.Equals(playlistID) {
+			return playlist, i, nil
+		}
+	}
+	return nil, -1, music.WrapPlaylistNotFound(playlistID, nil)
+}
+
+// distinct returns the sorted, de-duplicated non-empty values of field over
+// the tracks matching keep (all tracks when keep is nil).
+func (r *Repositories) distinct(field func(*music.Track) string, keep func(*music.Track) bool) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[string]bool)
+	values := []string{}
+	for _, track := range r.tracks {
+		if keep != nil && !keep(track) {
+			continue
+		}
+		value := field(track)
+		key := strings.ToLower(value)
+		if value == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		values = append(values, value)
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		return strings.ToLower(values[i]) < strings.ToLower(values[j])
+	})
+	return values
+}
+
+// filter returns copies of the tracks matching keep, in library order.
+func (r *Repositories) filter(keep func(*music.Track) bool) []*music.Track {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	matches := []*music.Track{}
+	for _, track := range r.tracks {
+		if keep(track) {
+			matches = append(matches, track)
+		}
+	}
+	return copyTracks(matches)
+}
+
+func paginate(tracks []*music.Track, limit, offset int) []*music.Track {
+	if offset >= len(tracks) {
+		return []*music.Track{}
+	}
+	tracks = tracks[offset:]
+	if limit > 0 && limit < len(tracks) {
+		tracks = tracks[:limit]
+	}
+	return tracks
+}
+
+func copyTracks(tracks []*music.Track) []*music.Track {
+	copies := make([]*music.Track, len(tracks))
+	for i, track := range tracks {
+		copied := *track
+		copies[i] = &copied
+	}
+	return copies
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
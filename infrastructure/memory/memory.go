@@ -0,0 +1,313 @@
+// Package memory provides an in-memory implementation of the music repository
+// interfaces.
+//
+// It simulates Music.app closely enough to drive the user interfaces without
+// macOS: playback position advances with the clock, tracks advance at their
+// end according to the repeat mode, the queue follows music.Queue, and
+// playlists behave like their AppleScript counterparts. It is used as the
+// demo backend for the TUI and as a fake in tests.
+//
+// Repositories also implements music.EventSource; every state change is
+// published as domain events.
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// queuePlaylistID is the ID of the playlist backing the play queue.
+const queuePlaylistID = "QUEUE"
+
+// historyLimit is how many played tracks Previous can go back through.
+const historyLimit = 100
+
+// Config holds configuration for the in-memory repositories.
+type Config struct {
+	// Tracks is the initial library, in library order
+	Tracks []*music.Track
+
+	// Playlists are the initial playlists
+	Playlists []*music.Playlist
+
+	// Now returns the current time; it drives playback position
+	Now func() time.Time
+
+	// BufferSize is the number of events buffered per subscriber
+	BufferSize int
+}
+
+// DefaultConfig returns a configuration with an empty library.
+func DefaultConfig() *Config {
+	return &Config{
+		Now:        time.Now,
+		BufferSize: 32,
+	}
+}
+
+// Repositories implements music.RepositoryManager and music.EventSource in memory.
+type Repositories struct {
+	config *Config
+	now    func() time.Time
+
+	mu        sync.Mutex
+	tracks    []*music.Track
+	trackByID map[string]*music.Track
+	playlists []*music.Playlist
+	queue     *music.Playlist // the play queue as listed; see queueTracks
+
+	// Playback state
+	player    *music.Player
+	playQueue *music.Queue  // the play queue
+	library   *music.Queue  // the library, when a track is played from it
+	fromQueue bool          // whether playback follows playQueue
+	elapsed   time.Duration // position when playback last started or seeked
+	startedAt time.Time     // when playback last started; zero unless playing
+
+	subscribers map[chan music.Event]struct{}
+}
+
+var (
+	_ music.RepositoryManager = (*Repositories)(nil)
+	_ music.EventSource       = (*Repositories)(nil)
+)
+
+// NewRepositories creates in-memory repositories seeded from the configuration.
+func NewRepositories(config *Config) *Repositories {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	now := config.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	queue, _ := music.NewPlaylist(music.NewPlaylistID(queuePlaylistID), "Queue", music.PlaylistTypeQueue, false)
+
+	r := &Repositories{
+		config:      config,
+		now:         now,
+		trackByID:   make(map[string]*music.Track),
+		queue:       queue,
+		player:      music.NewPlayer(),
+		playQueue:   music.NewQueue(historyLimit),
+		library:     music.NewQueue(historyLimit),
+		subscribers: make(map[chan music.Event]struct{}),
+	}
+
+	for _, track := range config.Tracks {
+		copied := *track
+		r.tracks = append(r.tracks, &copied)
+		r.trackByID[track.ID.Value()] = &copied
+	}
+	for _, playlist := range config.Playlists {
+		r.playlists = append(r.playlists, copyPlaylist(playlist))
+	}
+
+	return r
+}
+
+// Subscribe returns a channel of events that is closed when ctx is done.
+func (r *Repositories) Subscribe(ctx context.Context) <-chan music.Event {
+	size := r.config.BufferSize
+	if size <= 0 {
+		size = DefaultConfig().BufferSize
+	}
+	ch := make(chan music.Event, size)
+
+	r.mu.Lock()
+	r.subscribers[ch] = struct{}{}
+	r.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		r.mu.Lock()
+		delete(r.subscribers, ch)
+		close(ch)
+		r.mu.Unlock()
+	}()
+
+	return ch
+}
+
+// do runs fn under the lock after bringing playback up to date, then publishes
+// the events describing any change fn made.
+func (r *Repositories) do(fn func() error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	before := r.snapshot(now)
+	queueBefore := r.queue.Tracks
+	queuePosBefore := r.queuePosition()
+
+	r.advance(now)
+	err := fn()
+
+	after := r.snapshot(now)
+	for _, event := range music.PlayerEvents(before, after, now) {
+		r.publish(event)
+	}
+	if tracks := r.queueTracks(); !sameTracks(queueBefore, tracks) {
+		r.queue.Tracks = tracks
+		r.queue.ModifiedAt = now
+	}
+	if r.queuePosition() != queuePosBefore || !sameTracks(queueBefore, r.queue.Tracks) {
+		r.publish(music.NewQueueChangedEvent(append([]music.TrackID(nil), r.queue.Tracks...), r.queuePosition(), now))
+	}
+
+	return err
+}
+
+// publish delivers an event to every subscriber without blocking. Callers hold the lock.
+func (r *Repositories) publish(event music.Event) {
+	for ch := range r.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// position returns the playback position at now. Callers hold the lock.
+func (r *Repositories) position(now time.Time) time.Duration {
+	if r.player.IsPlaying() {
+		return r.elapsed + now.Sub(r.startedAt)
+	}
+	return r.elapsed
+}
+
+// snapshot returns a copy of the player state at now. Callers hold the lock.
+func (r *Repositories) snapshot(now time.Time) *music.Player {
+	player := *r.player
+	if player.CurrentTrack != nil {
+		id := *player.CurrentTrack
+		player.CurrentTrack = &id
+	}
+	player.Position = music.NewDurationFromTime(r.position(now))
+	player.LastUpdated = now
+	return &player
+}
+
+// advance moves past every track that finished playing since the last call,
+// following the repeat mode. Callers hold the lock.
+func (r *Repositories) advance(now time.Time) {
+	for r.player.IsPlaying() {
+		track := r.currentTrack()
+		if track == nil {
+			return
+		}
+
+		length := track.Duration.ToTime()
+		position := r.position(now)
+		if length <= 0 || position < length {
+			return
+		}
+
+		// The track ended at finishedAt; continue from there.
+		finishedAt := now.Add(length - position)
+		if _, ok := r.playback().Advance(); !ok {
+			r.stop()
+			return
+		}
+		r.load()
+		r.startAt(0, finishedAt)
+	}
+}
+
+// currentTrack returns the loaded track, or nil. Callers hold the lock.
+func (r *Repositories) currentTrack() *music.Track {
+	if !r.player.HasCurrentTrack() {
+		return nil
+	}
+	return r.trackByID[r.player.CurrentTrack.Value()]
+}
+
+// playback returns the queue playback follows. Callers hold the lock.
+func (r *Repositories) playback() *music.Queue {
+	if r.fromQueue {
+		return r.playQueue
+	}
+	return r.library
+}
+
+// load loads the current track of the playback queue. Callers hold the lock.
+func (r *Repositories) load() {
+	id, _ := r.playback().Current()
+	r.player.CurrentTrack = &id
+	r.elapsed = 0
+	r.player.LastUpdated = r.now()
+}
+
+// playLibrary plays the library from the track at index. Callers hold the lock.
+func (r *Repositories) playLibrary(index int) {
+	tracks := make([]music.TrackID, 0, len(r.tracks))
+	for _, track := range r.tracks {
+		tracks = append(tracks, track.ID)
+	}
+	r.playQueue.Stop()
+	r.fromQueue = false
+
+	// The library holds every track, so the context and start are valid
+	_ = r.library.SetContext(tracks, index)
+	r.load()
+	r.startAt(0, r.now())
+}
+
+// playQueueAt plays the queue from a position in queueTracks. Callers hold the lock.
+func (r *Repositories) playQueueAt(position int) error {
+	if err := validateQueuePosition(position, len(r.queueTracks())); err != nil {
+		return err
+	}
+	if !r.fromQueue {
+		r.library.Stop()
+		r.fromQueue = true
+	}
+	if err := r.jumpQueue(position); err != nil {
+		return err
+	}
+	r.load()
+	r.startAt(0, r.now())
+	return nil
+}
+
+// startAt starts playing the loaded track from position at time at. Callers hold the lock.
+func (r *Repositories) startAt(position time.Duration, at time.Time) {
+	if !r.player.IsPlaying() {
+		// A track is always loaded here, so playing it is allowed from any state
+		_, _ = r.player.Transition(music.PlayerTransitionPlay, r.player.CurrentTrack, at)
+	}
+	r.elapsed = position
+	r.startedAt = at
+}
+
+// stop stops playback and unloads the current track. Callers hold the lock.
+func (r *Repositories) stop() {
+	_, _ = r.player.Transition(music.PlayerTransitionStop, nil, r.now())
+	r.elapsed = 0
+	r.startedAt = time.Time{}
+	r.playback().Stop()
+	r.fromQueue = false
+}
+
+func sameTracks(a, b []music.TrackID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equals(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func copyPlaylist(playlist *music.Playlist) *music.Playlist {
+	copied := *playlist
+	copied.Tracks = append([]music.TrackID{}, playlist.Tracks...)
+	return &copied
+}
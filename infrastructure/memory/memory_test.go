@@ -0,0 +1,405 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// clock is a manually advanced time source.
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time { return c.now }
+
+func (c *clock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestRepositories(t *testing.T) (*Repositories, *clock) {
+	t.Helper()
+
+	c := &clock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	config := DemoConfig()
+	config.Now = c.Now
+	return NewRepositories(config), c
+}
+
+func currentID(t *testing.T, r *Repositories) string {
+	t.Helper()
+	player, err := r.GetCurrentState(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if player.CurrentTrack == nil {
+		return ""
+	}
+	return player.CurrentTrack.Value()
+}
+
+func TestPlaybackAdvancesWithClock(t *testing.T) {
+	ctx := context.Background()
+	r, c := newTestRepositories(t)
+
+	// 1001 "So What" is 562s long, 1002 follows it.
+	if err := r.Play(ctx, music.NewTrackID("1001")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c.Advance(90 * time.Second)
+	player, _ := r.GetCurrentState(ctx)
+	if !player.IsPlaying() || player.Position.Seconds() != 90 {
+		t.Errorf("expected playing at 90s, got %s at %s", player.State, player.Position)
+	}
+
+	if err := r.Pause(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.Advance(time.Hour)
+	player, _ = r.GetCurrentState(ctx)
+	if !player.IsPaused() || player.Position.Seconds() != 90 {
+		t.Errorf("expected paused at 90s, got %s at %s", player.State, player.Position)
+	}
+
+	if err := r.Resume(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.Advance(472*time.Second + 10*time.Second)
+	player, _ = r.GetCurrentState(ctx)
+	if player.CurrentTrack.Value() != "1002" || player.Position.Seconds() != 10 {
+		t.Errorf("expected track 1002 at 10s, got %s at %s", player.CurrentTrack, player.Position)
+	}
+}
+
+func TestNextPreviousAndRepeat(t *testing.T) {
+	ctx := context.Background()
+	r, c := newTestRepositories(t)
+
+	last := r.tracks[len(r.tracks)-1].ID
+	if err := r.Play(ctx, last); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := r.Next(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id := currentID(t, r); id != "" {
+		t.Errorf("expected playback to stop after the last track, got %s", id)
+	}
+
+	if err := r.Next(ctx); !errors.Is(err, music.ErrInvalidPlayerState) {
+		t.Errorf("expected ErrInvalidPlayerState with nothing loaded, got %v", err)
+	}
+	if err := r.Pause(ctx); !errors.Is(err, music.ErrInvalidPlayerState) {
+		t.Errorf("expected pausing while stopped to be rejected, got %v", err)
+	}
+	if err := r.Resume(ctx); !errors.Is(err, music.ErrInvalidPlayerState) {
+		t.Errorf("expected resuming while stopped to be rejected, got %v", err)
+	}
+
+	_ = r.SetRepeat(ctx, music.RepeatModeAll)
+	_ = r.Play(ctx, last)
+	_ = r.Next(ctx)
+	if id := currentID(t, r); id != "1001" {
+		t.Errorf("expected repeat all to wrap to 1001, got %s", id)
+	}
+
+	c.Advance(10 * time.Second)
+	_ = r.Previous(ctx)
+	if id := currentID(t, r); id != "1001" {
+		t.Errorf("expected previous after 10s to restart 1001, got %s", id)
+	}
+	_ = r.Previous(ctx)
+	if id := currentID(t, r); id != last.Value() {
+		t.Errorf("expected previous at start to wrap to %s, got %s", last, id)
+	}
+}
+
+func TestStart(t *testing.T) {
+	ctx := context.Background()
+	r, c := newTestRepositories(t)
+
+	// Stopped with an empty queue, the library plays from its first track
+	if err := r.Start(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id := currentID(t, r); id != "1001" {
+		t.Errorf("expected 1001, got %s", id)
+	}
+
+	c.Advance(30 * time.Second)
+	_ = r.Pause(ctx)
+	_ = r.Start(ctx)
+	player, _ := r.GetCurrentState(ctx)
+	if !player.IsPlaying() || player.Position.Seconds() != 30 {
+		t.Errorf("expected start to resume at 30s, got %s at %s", player.State, player.Position)
+	}
+
+	// Stopped with a queue, the queue plays from its start
+	_ = r.Stop(ctx)
+	_ = r.AddTracksToQueue(ctx, []music.TrackID{music.NewTrackID("1006"), music.NewTrackID("1011")})
+	if err := r.Start(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if position, _ := r.GetQueuePosition(ctx); position != 0 || currentID(t, r) != "1006" {
+		t.Errorf("expected the queue to play from 1006, got %s at %d", currentID(t, r), position)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newTestRepositories(t)
+
+	ids := []music.TrackID{music.NewTrackID("1001"), music.NewTrackID("1006"), music.NewTrackID("1011")}
+	if err := r.AddTracksToQueue(ctx, ids); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.AddToQueue(ctx, music.NewTrackID("missing")); !music.IsTrackNotFound(err) {
+		t.Errorf("expected track not found, got %v", err)
+	}
+
+	if position, _ := r.GetQueuePosition(ctx); position != -1 {
+		t.Errorf("expected -1 before the queue plays, got %d", position)
+	}
+
+	if err := r.SetQueuePosition(ctx, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.PlayNext(ctx, music.NewTrackID("1020")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	upNext, _ := r.GetUpNext(ctx, 2)
+	if len(upNext) != 2 || upNext[0].ID.Value() != "1020" || upNext[1].ID.Value() != "1006" {
+		t.Errorf("unexpected up next %v", upNext)
+	}
+
+	_ = r.Next(ctx)
+	if position, _ := r.GetQueuePosition(ctx); position != 1 || currentID(t, r) != "1020" {
+		t.Errorf("expected queue position 1 playing 1020, got %d", position)
+	}
+
+	if err := r.RemoveFromQueue(ctx, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if position, _ := r.GetQueuePosition(ctx); position != 0 || currentID(t, r) != "1020" {
+		t.Errorf("expected removal before the cursor to keep playing 1020 at 0, got %d", position)
+	}
+
+	if err := r.RemoveFromQueue(ctx, 5); !errors.Is(err, music.ErrInvalidQueuePosition) {
+		t.Errorf("expected ErrInvalidQueuePosition, got %v", err)
+	}
+
+	_ = r.ClearQueue(ctx)
+	if id := currentID(t, r); id != "" {
+		t.Errorf("expected clearing the playing queue to stop playback, got %s", id)
+	}
+	if err := r.ShuffleQueue(ctx); !errors.Is(err, music.ErrQueueEmpty) {
+		t.Errorf("expected ErrQueueEmpty, got %v", err)
+	}
+}
+
+func TestQueueShuffleAndPlayNext(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newTestRepositories(t)
+
+	queueValues := func() []string {
+		t.Helper()
+		queue, err := r.GetQueue(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		values := make([]string, len(queue.Tracks))
+		for i, trackID := range queue.Tracks {
+			values[i] = trackID.Value()
+		}
+		return values
+	}
+
+	ids := []music.TrackID{music.NewTrackID("1001"), music.NewTrackID("1002"), music.NewTrackID("1003")}
+	_ = r.AddTracksToQueue(ctx, ids)
+	_ = r.SetQueuePosition(ctx, 0)
+	_ = r.SetShuffle(ctx, true)
+	_ = r.AddTracksToQueue(ctx, []music.TrackID{music.NewTrackID("1004"), music.NewTrackID("1005")})
+	if got := queueValues(); len(got) != 5 || got[0] != "1001" {
+		t.Errorf("expected 1001 to stay first with the rest shuffled, got %v", got)
+	}
+
+	_ = r.SetShuffle(ctx, false)
+	if got := queueValues(); strings.Join(got, " ") != "1001 1002 1003 1004 1005" {
+		t.Errorf("expected the queue order back once shuffle is off, got %v", got)
+	}
+
+	// A track played next leaves the queue once the next one plays
+	_ = r.PlayNext(ctx, music.NewTrackID("1020"))
+	_ = r.Next(ctx)
+	if position, _ := r.GetQueuePosition(ctx); position != 1 || currentID(t, r) != "1020" {
+		t.Errorf("expected 1020 playing at 1, got %s at %d", currentID(t, r), position)
+	}
+	_ = r.Next(ctx)
+	if position, _ := r.GetQueuePosition(ctx); position != 1 || currentID(t, r) != "1002" {
+		t.Errorf("expected 1002 playing at 1, got %s at %d", currentID(t, r), position)
+	}
+
+	// Previous goes back through what played, not the list
+	_ = r.Previous(ctx)
+	if position, _ := r.GetQueuePosition(ctx); position != 1 || currentID(t, r) != "1020" {
+		t.Errorf("expected previous to return to 1020 at 1, got %s at %d", currentID(t, r), position)
+	}
+	if got := queueValues(); strings.Join(got, " ") != "1001 1020 1002 1003 1004 1005" {
+		t.Errorf("unexpected queue %v", got)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r, _ := newTestRepositories(t)
+	events := r.Subscribe(ctx)
+
+	_ = r.Play(ctx, music.NewTrackID("1001"))
+	_ = r.AddToQueue(ctx, music.NewTrackID("1002"))
+	_ = r.SetVolume(ctx, music.NewVolume(80))
+
+	expected := []music.EventType{
+		music.EventTrackChanged, music.EventPlayerStateChanged, music.EventQueueChanged, music.EventVolumeChanged,
+	}
+	for _, eventType := range expected {
+		select {
+		case event := <-events:
+			if event.Type != eventType {
+				t.Errorf("expected %s, got %s", eventType, event.Type)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %s", eventType)
+		}
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestPlaylists(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newTestRepositories(t)
+
+	playlist, err := r.CreatePlaylist(ctx, "  Mix ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if playlist.Name != "Mix" || playlist.Type != music.PlaylistTypeUser {
+		t.Errorf("unexpected playlist %+v", playlist)
+	}
+
+	_ = r.AddTrackToPlaylist(ctx, playlist.ID, music.NewTrackID("1001"))
+	_ = r.AddTrackToPlaylist(ctx, playlist.ID, music.NewTrackID("1002"))
+	if err := r.ReorderPlaylistTracks(ctx, playlist.ID, []music.TrackID{music.NewTrackID("1002"), music.NewTrackID("1001")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.ReorderPlaylistTracks(ctx, playlist.ID, []music.TrackID{music.NewTrackID("1002"), music.NewTrackID("9999")}); !errors.Is(err, music.ErrTrackNotFound) {
+		t.Errorf("expected a track outside the library to be rejected, got %v", err)
+	}
+
+	tracks, _ := r.GetPlaylistTracks(ctx, playlist.ID)
+	if len(tracks) != 2 || tracks[0].ID.Value() != "1002" {
+		t.Errorf("unexpected playlist tracks %v", tracks)
+	}
+
+	// The new list may repeat and drop tracks
+	repeated := []music.TrackID{music.NewTrackID("1001"), music.NewTrackID("1003"), music.NewTrackID("1001")}
+	if err := r.ReorderPlaylistTracks(ctx, playlist.ID, repeated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tracks, _ = r.GetPlaylistTracks(ctx, playlist.ID)
+	if len(tracks) != 3 || tracks[0].ID.Value() != "1001" || tracks[2].ID.Value() != "1001" {
+		t.Errorf("unexpected playlist tracks %v", tracks)
+	}
+
+	smart := music.NewPlaylistID("A1B2C3D4E5F60002")
+	if err := r.AddTrackToPlaylist(ctx, smart, music.NewTrackID("1001")); !errors.Is(err, music.ErrPlaylistReadOnly) {
+		t.Errorf("expected ErrPlaylistReadOnly, got %v", err)
+	}
+
+	if err := r.DeletePlaylist(ctx, playlist.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := r.GetPlaylist(ctx, playlist.ID); !music.IsPlaylistNotFound(err) {
+		t.Errorf("expected playlist not found after delete, got %v", err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newTestRepositories(t)
+
+	tracks, err := r.Search(ctx, music.LibrarySearchOptions{Query: "blue train"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tracks) != 5 || tracks[0].Title != "Blue Train" {
+		t.Errorf("expected the exact title match first, then its album, got %v", tracks)
+	}
+
+	tracks, _ = r.Search(ctx, music.LibrarySearchOptions{Query: "blue"})
+
+	titles, _ := r.Search(ctx, music.LibrarySearchOptions{Query: "blue", Fields: []music.SearchField{music.SearchFieldTitle}})
+	for _, track := range titles {
+		if track.Album == "Kind of Blue" && track.Title == "So What" {
+			t.Errorf("expected album-only matches to be excluded, got %v", track)
+		}
+	}
+	if len(titles) >= len(tracks) {
+		t.Errorf("expected fewer title matches (%d) than matches in any field (%d)", len(titles), len(tracks))
+	}
+
+	page, _ := r.Search(ctx, music.LibrarySearchOptions{Query: "blue", Limit: 2, Offset: 1})
+	if len(page) != 2 || page[0].ID != tracks[1].ID {
+		t.Errorf("expected the second page to follow the ranking, got %v", page)
+	}
+}
+
+func TestRatings(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newTestRepositories(t)
+
+	if err := r.SetRating(ctx, music.NewTrackID("1001"), music.NewRatingFromStars(3)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.SetRating(ctx, music.NewTrackID("1002"), music.NewRatingFromStars(5)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.SetRating(ctx, music.NewTrackID("missing"), music.NewRatingFromStars(5)); !music.IsTrackNotFound(err) {
+		t.Errorf("expected ErrTrackNotFound, got %v", err)
+	}
+
+	tracks, err := r.GetTracksByRating(ctx, music.NewRatingFromStars(1), music.NewRatingFromStars(5))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tracks) != 2 || tracks[0].ID.Value() != "1002" || tracks[1].ID.Value() != "1001" {
+		t.Errorf("expected 1002 then 1001, got %v", tracks)
+	}
+	if _, err := r.GetTracksByRating(ctx, music.NewRatingFromStars(4), music.NewRatingFromStars(2)); !errors.Is(err, music.ErrInvalidRating) {
+		t.Errorf("expected ErrInvalidRating, got %v", err)
+	}
+
+	// Loving clears a dislike
+	trackID := music.NewTrackID("1001")
+	for _, preference := range []music.Preference{music.PreferenceDisliked, music.PreferenceLoved} {
+		if err := r.SetPreference(ctx, trackID, preference); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	track, _ := r.GetTrack(ctx, trackID)
+	if track.Preference() != music.PreferenceLoved || track.Disliked {
+		t.Errorf("expected the track to be loved only, got loved %v disliked %v", track.Loved, track.Disliked)
+	}
+	if err := r.SetPreference(ctx, trackID, music.Preference(7)); !errors.Is(err, music.ErrInvalidRating) {
+		t.Errorf("expected ErrInvalidRating, got %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package memory
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// restartThreshold is how far into a track Previous restarts it instead of
+// going back, matching Music.app.
+const restartThreshold = 3 * time.Second
+
+// Play starts playback of the specified track in the context of the library.
+func (r *Repositories) Play(ctx context.Context, trackID music.TrackID) error {
+	if trackID.IsEmpty() {
+		return music.NewDomainError(music.ErrInvalidTrackID, "track ID cannot be empty")
+	}
+
+	return r.do(func() error {
+		if _, ok := r.trackByID[trackID.Value()]; !ok {
+			return music.WrapTrackNotFound(trackID, nil)
+		}
+
+		index := 0
+		for i, track := range r.tracks {
+			if track.ID.Equals(trackID) {
+				index = i
+			}
+		}
+		r.playLibrary(index)
+		return nil
+	})
+}
+
+// Pause pauses the current playback.
+func (r *Repositories) Pause(ctx context.Context) error {
+	return r.do(func() error {
+		position := r.position(r.now())
+		if _, err := r.player.Transition(music.PlayerTransitionPause, nil, r.now()); err != nil {
+			return err
+		}
+		r.elapsed = position
+		r.startedAt = time.Time{}
+		return nil
+	})
+}
+
+// Stop stops playback and clears the current track.
+func (r *Repositories) Stop(ctx context.Context) error {
+	return r.do(func() error {
+		r.stop()
+		return nil
+	})
+}
+
+// Resume resumes paused playback.
+func (r *Repositories) Resume(ctx context.Context) error {
+	return r.do(func() error {
+		if r.player.IsPlaying() {
+			return nil
+		}
+		if _, err := r.player.Transition(music.PlayerTransitionResume, nil, r.now()); err != nil {
+			return err
+		}
+		r.startAt(r.elapsed, r.now())
+		return nil
+	})
+}
+
+// Start resumes paused playback or, when stopped, plays the queue from its
+// start, or the library when the queue is empty, standing in for Music.app's
+// current selection.
+func (r *Repositories) Start(ctx context.Context) error {
+	return r.do(func() error {
+		switch {
+		case r.player.IsPlaying():
+			return nil
+		case r.player.IsPaused():
+			if _, err := r.player.Transition(music.PlayerTransitionResume, nil, r.now()); err != nil {
+				return err
+			}
+			r.startAt(r.elapsed, r.now())
+		case len(r.queueTracks()) > 0:
+			return r.playQueueAt(0)
+		case len(r.tracks) > 0:
+			r.playLibrary(0)
+		default:
+			return music.NewDomainError(music.ErrInvalidPlayerState, "nothing to play: the library is empty")
+		}
+		return nil
+	})
+}
+
+// Next advances to the next track in the current context.
+func (r *Repositories) Next(ctx context.Context) error {
+	return r.do(func() error {
+		return r.skip(true)
+	})
+}
+
+// Previous goes back to the last track played, or restarts the current track
+// when it has played for more than a few seconds or nothing played before it.
+func (r *Repositories) Previous(ctx context.Context) error {
+	return r.do(func() error {
+		if r.player.HasCurrentTrack() && r.position(r.now()) > restartThreshold {
+			return r.seek(0)
+		}
+		return r.skip(false)
+	})
+}
+
+// skip moves to the next track, or back to the last one played, and keeps
+// the playing or paused state. Callers hold the lock.
+func (r *Repositories) skip(forward bool) error {
+	if !r.player.HasCurrentTrack() {
+		return music.NewDomainError(music.ErrInvalidPlayerState, "no track is loaded")
+	}
+
+	playing := r.player.IsPlaying()
+	if forward {
+		if _, ok := r.playback().Next(); !ok {
+			r.stop()
+			return nil
+		}
+	} else if _, ok := r.playback().Previous(); !ok {
+		return r.seek(0)
+	}
+
+	r.load()
+	if playing {
+		r.startAt(0, r.now())
+	}
+	return nil
+}
+
+// Seek changes the playback position within the current track.
+func (r *Repositories) Seek(ctx context.Context, position music.Duration) error {
+	if !position.IsValid() {
+		return music.WrapInvalidPosition(position, nil)
+	}
+
+	return r.do(func() error {
+		track := r.currentTrack()
+		if track == nil {
+			return music.NewDomainError(music.ErrInvalidPlayerState, "no track is loaded")
+		}
+		if position.Milliseconds() > track.Duration.Milliseconds() {
+			return music.WrapInvalidPosition(position, nil).WithContext("duration_seconds", track.Duration.Seconds())
+		}
+		return r.seek(position.ToTime())
+	})
+}
+
+// seek moves to a position within the current track. Callers hold the lock.
+func (r *Repositories) seek(position time.Duration) error {
+	r.elapsed = position
+	if r.player.IsPlaying() {
+		r.startedAt = r.now()
+	}
+	return nil
+}
+
+// SetVolume changes the playback volume.
+func (r *Repositories) SetVolume(ctx context.Context, volume music.Volume) error {
+	if !volume.IsValid() {
+		return music.WrapInvalidVolume(volume.Level(), nil)
+	}
+
+	return r.do(func() error {
+		return r.player.SetVolume(volume)
+	})
+}
+
+// SetShuffle enables or disables shuffle mode. Enabling it shuffles the
+// tracks not played yet with a new seed; disabling it restores their order.
+func (r *Repositories) SetShuffle(ctx context.Context, enabled bool) error {
+	return r.do(func() error {
+		r.player.SetShuffle(enabled)
+		seed := rand.Int63()
+		for _, queue := range []*music.Queue{r.playQueue, r.library} {
+			switch {
+			case queue.Shuffled() == enabled:
+			case enabled:
+				queue.Shuffle(seed)
+			default:
+				queue.Unshuffle()
+			}
+		}
+		return nil
+	})
+}
+
+// SetRepeat changes the repeat mode.
+func (r *Repositories) SetRepeat(ctx context.Context, mode music.RepeatMode) error {
+	if !mode.IsValid() {
+		return music.NewDomainError(music.ErrInvalidRepeatMode, "invalid repeat mode")
+	}
+
+	return r.do(func() error {
+		r.player.SetRepeat(mode)
+		for _, queue := range []*music.Queue{r.playQueue, r.library} {
+			if err := queue.SetRepeat(mode); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetCurrentState returns the current player state.
+func (r *Repositories) GetCurrentState(ctx context.Context) (*music.Player, error) {
+	var player *music.Player
+	err := r.do(func() error {
+		player = r.snapshot(r.now())
+		return nil
+	})
+	return player, err
+}
+
+// GetCurrentTrack returns the currently playing track, or nil if there is none.
+func (r *Repositories) GetCurrentTrack(ctx context.Context) (*music.Track, error) {
+	var track *music.Track
+	err := r.do(func() error {
+		if current := r.currentTrack(); current != nil {
+			copied := *current
+			track = &copied
+		}
+		return nil
+	})
+	return track, err
+}
@@ -0,0 +1,155 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// CreatePlaylist creates a new, empty user playlist.
+func (r *Repositories) CreatePlaylist(ctx context.Context, name string) (*music.Playlist, error) {
+	name = strings.TrimSpace(name)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	playlist, err := music.NewPlaylist(r.nextPlaylistID(), name, music.PlaylistTypeUser, false)
+	if err != nil {
+		return nil, err
+	}
+	r.playlists = append(r.playlists, playlist)
+	return copyPlaylist(playlist), nil
+}
+
+// UpdatePlaylist renames a user playlist.
+func (r *Repositories) UpdatePlaylist(ctx context.Context, playlist *music.Playlist) error {
+	if playlist == nil {
+		return music.NewDomainError(music.ErrInvalidPlaylist, "playlist cannot be nil")
+	}
+	name := strings.TrimSpace(playlist.Name)
+	if name == "" {
+		return music.NewDomainError(music.ErrInvalidPlaylist, "playlist name cannot be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, err := r.writablePlaylist(playlist.ID)
+	if err != nil {
+		return err
+	}
+	existing.Name = name
+	existing.ModifiedAt = r.now()
+	return nil
+}
+
+// DeletePlaylist removes a user playlist.
+func (r *Repositories) DeletePlaylist(ctx context.Context, playlistID music.PlaylistID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// The queue is read-only, so a writable playlist is always in r.playlists.
+	if _, err := r.writablePlaylist(playlistID); err != nil {
+		return err
+	}
+	_, index, _ := r.findPlaylist(playlistID)
+	r.playlists = append(r.playlists[:index], r.playlists[index+1:]...)
+	return nil
+}
+
+// AddTrackToPlaylist appends a track to a user playlist.
+func (r *Repositories) AddTrackToPlaylist(ctx context.Context, playlistID music.PlaylistID, trackID music.TrackID) error {
+	if playlistID.Equals(music.NewPlaylistID(queuePlaylistID)) {
+		return r.AddToQueue(ctx, trackID)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	playlist, err := r.writablePlaylist(playlistID)
+	if err != nil {
+		return err
+	}
+	if err := r.requireTracks([]music.TrackID{trackID}); err != nil {
+		return err
+	}
+	return playlist.AddTrack(trackID)
+}
+
+// RemoveTrackFromPlaylist removes the first occurrence of a track from a user playlist.
+func (r *Repositories) RemoveTrackFromPlaylist(ctx context.Context, playlistID music.PlaylistID, trackID music.TrackID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	playlist, err := r.writablePlaylist(playlistID)
+	if err != nil {
+		return err
+	}
+	return playlist.RemoveTrack(trackID)
+}
+
+// ReorderPlaylistTracks replaces the tracks of a user playlist with trackIDs,
+// in order. Every track must be in the library; repeats are kept.
+func (r *Repositories) ReorderPlaylistTracks(ctx context.Context, playlistID music.PlaylistID, trackIDs []music.TrackID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	playlist, err := r.writablePlaylist(playlistID)
+	if err != nil {
+		return err
+	}
+	if err := r.requireTracks(trackIDs); err != nil {
+		return err
+	}
+	playlist.Tracks = append([]music.TrackID{}, trackIDs...)
+	playlist.ModifiedAt = r.now()
+	return nil
+}
+
+// DuplicatePlaylist copies a playlist into a new user playlist.
+func (r *Repositories) DuplicatePlaylist(ctx context.Context, playlistID music.PlaylistID, newName string) (*music.Playlist, error) {
+	newName = strings.TrimSpace(newName)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	source, _, err := r.findPlaylist(playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	playlist, err := music.NewPlaylist(r.nextPlaylistID(), newName, music.PlaylistTypeUser, false)
+	if err != nil {
+		return nil, err
+	}
+	playlist.Tracks = append(playlist.Tracks, source.Tracks...)
+	r.playlists = append(r.playlists, playlist)
+	return copyPlaylist(playlist), nil
+}
+
+// writablePlaylist returns a playlist that may be modified. Callers hold the lock.
+func (r *Repositories) writablePlaylist(playlistID music.PlaylistID) (*music.Playlist, error) {
+	playlist, _, err := r.findPlaylist(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	if playlist.ReadOnly || playlist.Type.IsReadOnly() {
+		return nil, music.NewDomainError(
+			music.ErrPlaylistReadOnly,
+			fmt.Sprintf("playlist '%s' is a %s playlist and cannot be modified", playlist.Name, playlist.Type),
+		).WithContext("playlist_id", playlistID.Value())
+	}
+	return playlist, nil
+}
+
+// nextPlaylistID returns an unused playlist ID. Callers hold the lock.
+func (r *Repositories) nextPlaylistID() music.PlaylistID {
+	for n := len(r.playlists) + 1; ; n++ {
+		id := music.NewPlaylistID(fmt.Sprintf("%016X", n))
+		if _, _, err := r.findPlaylist(id); err != nil {
+			return id
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// GetQueue returns the current playback queue.
+func (r *Repositories) GetQueue(ctx context.Context) (*music.Playlist, error) {
+	var queue *music.Playlist
+	err := r.do(func() error {
+		queue = copyPlaylist(r.queue)
+		return nil
+	})
+	return queue, err
+}
+
+// AddToQueue adds a track to the end of the queue.
+func (r *Repositories) AddToQueue(ctx context.Context, trackID music.TrackID) error {
+	return r.AddTracksToQueue(ctx, []music.TrackID{trackID})
+}
+
+// AddTracksToQueue adds multiple tracks to the end of the queue. With
+// shuffle on they are mixed into the tracks not played yet.
+func (r *Repositories) AddTracksToQueue(ctx context.Context, trackIDs []music.TrackID) error {
+	return r.do(func() error {
+		if err := r.requireTracks(trackIDs); err != nil {
+			return err
+		}
+		return r.playQueue.Append(trackIDs...)
+	})
+}
+
+// PlayNext queues a track to play right after the current one, ahead of
+// tracks queued to play next earlier.
+func (r *Repositories) PlayNext(ctx context.Context, trackID music.TrackID) error {
+	return r.do(func() error {
+		if err := r.requireTracks([]music.TrackID{trackID}); err != nil {
+			return err
+		}
+		return r.playQueue.PlayNext(trackID)
+	})
+}
+
+// PlayLater adds a track to the end of the queue (same as AddToQueue).
+func (r *Repositories) PlayLater(ctx context.Context, trackID music.TrackID) error {
+	return r.AddToQueue(ctx, trackID)
+}
+
+// RemoveFromQueue removes a track from the queue by position. Removing the
+// playing track stops playback.
+func (r *Repositories) RemoveFromQueue(ctx context.Context, position int) error {
+	return r.do(func() error {
+		if err := validateQueuePosition(position, len(r.queueTracks())); err != nil {
+			return err
+		}
+
+		if position == r.queuePosition() {
+			// A track played next leaves the queue when it stops
+			r.stop()
+			if r.playQueue.Index() < position {
+				return nil
+			}
+		}
+
+		played := r.playQueue.Index() + 1
+		if position < played {
+			_, err := r.playQueue.RemovePlayed(position)
+			return err
+		}
+		if r.playingNext() {
+			position--
+		}
+		_, err := r.playQueue.Remove(position - played)
+		return err
+	})
+}
+
+// ClearQueue removes all tracks from the queue.
+func (r *Repositories) ClearQueue(ctx context.Context) error {
+	return r.do(func() error {
+		if r.fromQueue {
+			r.stop()
+		}
+		r.playQueue.Clear()
+		return nil
+	})
+}
+
+// ShuffleQueue shuffles the tracks after the current queue position with a
+// new seed. Tracks added later are mixed in until shuffle is turned off.
+func (r *Repositories) ShuffleQueue(ctx context.Context) error {
+	return r.do(func() error {
+		if len(r.queueTracks()) == 0 {
+			return music.NewDomainError(music.ErrQueueEmpty, "cannot shuffle an empty queue")
+		}
+		r.playQueue.Shuffle(rand.Int63())
+		return nil
+	})
+}
+
+// GetQueuePosition returns the current position in the queue (0-based), or -1
+// when the queue is not what is currently playing.
+func (r *Repositories) GetQueuePosition(ctx context.Context) (int, error) {
+	position := -1
+	err := r.do(func() error {
+		position = r.queuePosition()
+		return nil
+	})
+	return position, err
+}
+
+// SetQueuePosition starts playing the queue from a position.
+func (r *Repositories) SetQueuePosition(ctx context.Context, position int) error {
+	return r.do(func() error {
+		return r.playQueueAt(position)
+	})
+}
+
+// GetUpNext returns the next few tracks that will play from the queue.
+func (r *Repositories) GetUpNext(ctx context.Context, count int) ([]*music.Track, error) {
+	if count < 0 {
+		return nil, music.NewDomainError(music.ErrInvalidOperation, "count cannot be negative")
+	}
+
+	var upcoming []music.TrackID
+	err := r.do(func() error {
+		if r.fromQueue {
+			upcoming = r.playQueue.UpNext(count)
+		} else {
+			upcoming = r.queueTracks()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if count > 0 && count < len(upcoming) {
+		upcoming = upcoming[:count]
+	}
+	return r.GetTracks(ctx, upcoming)
+}
+
+// queueTracks lists the play queue the way Music.app shows it: the tracks
+// played so far and the current one, then the tracks to play next, then the
+// rest. Tracks played next leave the list once another track plays. Callers
+// hold the lock.
+func (r *Repositories) queueTracks() []music.TrackID {
+	order := r.playQueue.Order()
+	played := r.playQueue.Index() + 1
+
+	tracks := append([]music.TrackID{}, order[:played]...)
+	if r.playingNext() {
+		current, _ := r.playQueue.Current()
+		tracks = append(tracks, current)
+	}
+	tracks = append(tracks, r.playQueue.Queued()...)
+	return append(tracks, order[played:]...)
+}
+
+// queuePosition returns the position of the playing track in queueTracks,
+// or -1 when the queue is not playing. Callers hold the lock.
+func (r *Repositories) queuePosition() int {
+	if !r.fromQueue || !r.player.HasCurrentTrack() {
+		return -1
+	}
+	if position := r.playQueue.Position(); position >= 0 {
+		return position
+	}
+	return r.playQueue.Index() + 1
+}
+
+// playingNext reports whether the current track of the play queue was
+// queued to play next. Callers hold the lock.
+func (r *Repositories) playingNext() bool {
+	_, ok := r.playQueue.Current()
+	return ok && r.playQueue.Position() < 0
+}
+
+// jumpQueue makes the track at position in queueTracks current. Tracks
+// queued to play next that it skips are dropped. Callers hold the lock.
+func (r *Repositories) jumpQueue(position int) error {
+	played := r.playQueue.Index() + 1
+	if position < played {
+		return r.playQueue.Jump(position)
+	}
+
+	position -= played
+	if r.playingNext() {
+		if position == 0 {
+			return nil
+		}
+		position--
+	}
+
+	queued := len(r.playQueue.Queued())
+	for range min(position, queued) {
+		if _, err := r.playQueue.Remove(0); err != nil {
+			return err
+		}
+	}
+	if position < queued {
+		r.playQueue.Next()
+		return nil
+	}
+	return r.playQueue.Jump(played + position - queued)
+}
+
+// requireTracks returns an error unless every track exists. Callers hold the lock.
+func (r *Repositories) requireTracks(trackIDs []music.TrackID) error {
+	for _, trackID := range trackIDs {
+		if trackID.IsEmpty() {
+			return music.NewDomainError(music.ErrInvalidTrackID, "track ID cannot be empty")
+		}
+		if _, ok := r.trackByID[trackID.Value()]; !ok {
+			return music.WrapTrackNotFound(trackID, nil)
+		}
+	}
+	return nil
+}
+
+func validateQueuePosition(position, length int) error {
+	if length == 0 {
+		return music.NewDomainError(music.ErrQueueEmpty, "queue is empty")
+	}
+	if position < 0 || position >= length {
+		return music.NewDomainError(
+			music.ErrInvalidQueuePosition,
+			fmt.Sprintf("position %d is out of range (queue has %d tracks)", position, length),
+		).WithContext("position", position)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// SetRating sets the rating of a track; a zero rating clears it.
+func (r *Repositories) SetRating(ctx context.Context, trackID music.TrackID, rating music.Rating) error {
+	if !rating.IsValid() {
+		return music.WrapInvalidRating(rating.Value(), nil)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	track, ok := r.trackByID[trackID.Value()]
+	if !ok {
+		return music.WrapTrackNotFound(trackID, nil)
+	}
+	track.Rating = rating
+	return nil
+}
+
+// SetPreference marks a track loved or disliked, or clears both.
+func (r *Repositories) SetPreference(ctx context.Context, trackID music.TrackID, preference music.Preference) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	track, ok := r.trackByID[trackID.Value()]
+	if !ok {
+		return music.WrapTrackNotFound(trackID, nil)
+	}
+	return track.SetPreference(preference)
+}
+
+// GetTracksByRating returns the tracks rated between minimum and maximum
+// inclusive, best rated first.
+func (r *Repositories) GetTracksByRating(ctx context.Context, minimum, maximum music.Rating) ([]*music.Track, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tracks, err := music.TracksByRating(r.tracks, minimum, maximum)
+	if err != nil {
+		return nil, err
+	}
+	return copyTracks(tracks), nil
+}
@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// SmartPlaylists implements music.SmartPlaylistRepository in memory.
+type SmartPlaylists struct {
+	mu        sync.Mutex
+	playlists map[string]*music.SmartPlaylist
+}
+
+var _ music.SmartPlaylistRepository = (*SmartPlaylists)(nil)
+
+// NewSmartPlaylists creates an in-memory smart playlist store holding
+// playlists.
+func NewSmartPlaylists(playlists ...*music.SmartPlaylist) *SmartPlaylists {
+	s := &SmartPlaylists{playlists: make(map[string]*music.SmartPlaylist)}
+	for _, playlist := range playlists {
+		s.playlists[smartKey(playlist.Name)] = copySmartPlaylist(playlist)
+	}
+	return s
+}
+
+// GetSmartPlaylists returns every smart playlist, ordered by name.
+func (s *SmartPlaylists) GetSmartPlaylists(ctx context.Context) ([]*music.SmartPlaylist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	playlists := make([]*music.SmartPlaylist, 0, len(s.playlists))
+	for _, playlist := range s.playlists {
+		playlists = append(playlists, copySmartPlaylist(playlist))
+	}
+	sort.Slice(playlists, func(i, j int) bool {
+		return smartKey(playlists[i].Name) < smartKey(playlists[j].Name)
+	})
+	return playlists, nil
+}
+
+// GetSmartPlaylist returns the smart playlist with the given name. Names
+// are compared without regard to case.
+func (s *SmartPlaylists) GetSmartPlaylist(ctx context.Context, name string) (*music.SmartPlaylist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	playlist, ok := s.playlists[smartKey(name)]
+	if !ok {
+		return nil, smartPlaylistNotFound(name)
+	}
+	return copySmartPlaylist(playlist), nil
+}
+
+// SaveSmartPlaylist creates or replaces a smart playlist.
+func (s *SmartPlaylists) SaveSmartPlaylist(ctx context.Context, playlist *music.SmartPlaylist) error {
+	if playlist == nil {
+		return music.NewDomainError(music.ErrInvalidPlaylist, "smart playlist cannot be nil")
+	}
+	if _, err := music.NewSmartPlaylist(playlist.Name, playlist.Rules); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.playlists[smartKey(playlist.Name)] = copySmartPlaylist(playlist)
+	return nil
+}
+
+// DeleteSmartPlaylist removes a smart playlist.
+func (s *SmartPlaylists) DeleteSmartPlaylist(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := smartKey(name)
+	if _, ok := s.playlists[key]; !ok {
+		return smartPlaylistNotFound(name)
+	}
+	delete(s.playlists, key)
+	return nil
+}
+
+// smartKey is the key a smart playlist is stored under.
+func smartKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func smartPlaylistNotFound(name string) error {
+	return music.NewDomainError(music.ErrPlaylistNotFound, fmt.Sprintf("smart playlist %q was not found", name)).
+		WithContext("name", name)
+}
+
+// copySmartPlaylist returns a copy of playlist that shares no memory with it.
+func copySmartPlaylist(playlist *music.SmartPlaylist) *music.SmartPlaylist {
+	copied := *playlist
+	copied.Rules.Rules = append([]music.SmartRule{}, playlist.Rules.Rules...)
+	if playlist.Rules.Sort != nil {
+		order := *playlist.Rules.Sort
+		copied.Rules.Sort = &order
+	}
+	return &copied
+}
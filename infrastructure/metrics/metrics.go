@@ -0,0 +1,239 @@
+// Package metrics instruments the daemon with Prometheus metrics.
+//
+// Metrics holds every collector. The AppleScript executor reports to it
+// through the applescript.Observer interface, repositories are wrapped with
+// InstrumentRepositories, and the cache, session and transport layers call
+// the matching Record methods. Handler serves the result in the Prometheus
+// text format.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/infrastructure/applescript"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every metric name.
+const namespace = "maestro"
+
+// scriptBuckets cover AppleScript round trips, which take from tens of
+// milliseconds to the 10s default timeout.
+var scriptBuckets = []float64{0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Metrics holds the daemon's Prometheus collectors.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	scriptDuration *prometheus.HistogramVec
+	scriptExits    *prometheus.CounterVec
+	scriptRetries  *prometheus.CounterVec
+	scriptTimeouts *prometheus.CounterVec
+
+	repositoryCalls    *prometheus.CounterVec
+	repositoryErrors   *prometheus.CounterVec
+	repositoryDuration *prometheus.HistogramVec
+
+	cacheRequests *prometheus.CounterVec
+
+	sessionsActive      *prometheus.GaugeVec
+	sessionsStarted     *prometheus.CounterVec
+	sessionsRateLimited *prometheus.CounterVec
+
+	transportConnections *prometheus.GaugeVec
+	transportMessages    *prometheus.CounterVec
+}
+
+// New creates the collectors and registers them, along with the Go runtime
+// and process collectors, on a new registry.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		scriptDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "applescript",
+			Name:      "execution_duration_seconds",
+			Help:      "Time to run one AppleScript, including retries.",
+			Buckets:   scriptBuckets,
+		}, []string{"script"}),
+		scriptExits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "applescript",
+			Name:      "executions_total",
+			Help:      "AppleScript executions by the exit code of the last maestro-exec run (none when it did not finish).",
+		}, []string{"script", "exit_code"}),
+		scriptRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "applescript",
+			Name:      "retries_total",
+			Help:      "AppleScript attempts retried after a failure.",
+		}, []string{"script"}),
+		scriptTimeouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "applescript",
+			Name:      "timeouts_total",
+			Help:      "AppleScript attempts that hit the execution timeout.",
+		}, []string{"script"}),
+
+		repositoryCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "calls_total",
+			Help:      "Repository calls by repository and method.",
+		}, []string{"repository", "method"}),
+		repositoryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "errors_total",
+			Help:      "Failed repository calls by repository, method and domain error code.",
+		}, []string{"repository", "method", "code"}),
+		repositoryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "call_duration_seconds",
+			Help:      "Time spent in repository calls.",
+			Buckets:   scriptBuckets,
+		}, []string{"repository", "method"}),
+
+		cacheRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "cache",
+			Name:      "requests_total",
+			Help:      "Cache lookups by cache and result (hit or miss).",
+		}, []string{"cache", "result"}),
+
+		sessionsActive: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "session",
+			Name:      "active",
+			Help:      "Sessions currently held, by client type.",
+		}, []string{"client_type"}),
+		sessionsStarted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "session",
+			Name:      "started_total",
+			Help:      "Sessions started, by client type.",
+		}, []string{"client_type"}),
+		sessionsRateLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "session",
+			Name:      "rate_limited_total",
+			Help:      "Requests rejected by a session rate limit, by client type.",
+		}, []string{"client_type"}),
+
+		transportConnections: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "transport",
+			Name:      "connections",
+			Help:      "Open client connections, by transport.",
+		}, []string{"transport"}),
+		transportMessages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "transport",
+			Name:      "messages_total",
+			Help:      "Messages exchanged with clients, by transport and direction (in or out).",
+		}, []string{"transport", "direction"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.scriptDuration, m.scriptExits, m.scriptRetries, m.scriptTimeouts,
+		m.repositoryCalls, m.repositoryErrors, m.repositoryDuration,
+		m.cacheRequests,
+		m.sessionsActive, m.sessionsStarted, m.sessionsRateLimited,
+		m.transportConnections, m.transportMessages,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Registry returns the registry the collectors are registered on.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// ObserveExecution records one AppleScript execution. It implements
+// applescript.Observer.
+func (m *Metrics) ObserveExecution(script string, result *applescript.ExecuteResult) {
+	m.scriptDuration.WithLabelValues(script).Observe(result.Duration.Seconds())
+
+	exitCode := "none"
+	if result.ExitCode >= 0 {
+		exitCode = strconv.Itoa(result.ExitCode)
+	}
+	m.scriptExits.WithLabelValues(script, exitCode).Inc()
+
+	if result.RetryCount > 0 {
+		m.scriptRetries.WithLabelValues(script).Add(float64(result.RetryCount))
+	}
+	if result.Timeouts > 0 {
+		m.scriptTimeouts.WithLabelValues(script).Add(float64(result.Timeouts))
+	}
+}
+
+// Compile-time check that Metrics can observe the executor.
+var _ applescript.Observer = (*Metrics)(nil)
+
+// observeCall records one repository call.
+func (m *Metrics) observeCall(repository, method string, started time.Time, err error) {
+	m.repositoryCalls.WithLabelValues(repository, method).Inc()
+	m.repositoryDuration.WithLabelValues(repository, method).Observe(time.Since(started).Seconds())
+	if err != nil {
+		m.repositoryErrors.WithLabelValues(repository, method, ErrorCode(err)).Inc()
+	}
+}
+
+// RecordCacheLookup records a cache hit or miss.
+func (m *Metrics) RecordCacheLookup(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.cacheRequests.WithLabelValues(cache, result).Inc()
+}
+
+// RecordSessionStarted records a new session for a client type.
+func (m *Metrics) RecordSessionStarted(clientType string) {
+	m.sessionsStarted.WithLabelValues(clientType).Inc()
+	m.sessionsActive.WithLabelValues(clientType).Inc()
+}
+
+// RecordSessionEnded records the end of a session for a client type.
+func (m *Metrics) RecordSessionEnded(clientType string) {
+	m.sessionsActive.WithLabelValues(clientType).Dec()
+}
+
+// RecordRateLimited records a request rejected by a session rate limit.
+func (m *Metrics) RecordRateLimited(clientType string) {
+	m.sessionsRateLimited.WithLabelValues(clientType).Inc()
+}
+
+// RecordConnection records a client connecting (delta 1) or disconnecting
+// (delta -1) on a transport.
+func (m *Metrics) RecordConnection(transport string, delta int) {
+	m.transportConnections.WithLabelValues(transport).Add(float64(delta))
+}
+
+// RecordMessage records a message received ("in") or sent ("out") on a
+// transport.
+func (m *Metrics) RecordMessage(transport, direction string) {
+	m.transportMessages.WithLabelValues(transport, direction).Inc()
+}
+
+// ErrorCode returns a metric label for err: the stable identifier of its
+// domain error code, e.g. "track_not_found", or "timeout", "canceled" or
+// "unknown" for errors from outside the domain.
+func ErrorCode(err error) string {
+	return music.ErrorID(err)
+}
@@ -0,0 +1,141 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/infrastructure/applescript"
+	"github.com/madstone-tech/maestro/infrastructure/memory"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveExecution(t *testing.T) {
+	m := New()
+
+	m.ObserveExecution("PlayerRepository.Pause", &applescript.ExecuteResult{Duration: 120 * time.Millisecond, ExitCode: 0})
+	m.ObserveExecution("PlayerRepository.Pause", &applescript.ExecuteResult{
+		Duration:   3 * time.Second,
+		RetryCount: 2,
+		Timeouts:   2,
+		ExitCode:   -1,
+		Error:      errors.New("timed out"),
+	})
+
+	if got := testutil.ToFloat64(m.scriptExits.WithLabelValues("PlayerRepository.Pause", "0")); got != 1 {
+		t.Errorf("expected 1 successful execution, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.scriptExits.WithLabelValues("PlayerRepository.Pause", "none")); got != 1 {
+		t.Errorf("expected 1 unfinished execution, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.scriptRetries.WithLabelValues("PlayerRepository.Pause")); got != 2 {
+		t.Errorf("expected 2 retries, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.scriptTimeouts.WithLabelValues("PlayerRepository.Pause")); got != 2 {
+		t.Errorf("expected 2 timeouts, got %v", got)
+	}
+	if got := testutil.CollectAndCount(m.scriptDuration); got != 1 {
+		t.Errorf("expected one duration series, got %d", got)
+	}
+}
+
+func TestInstrumentRepositories(t *testing.T) {
+	m := New()
+	repos := InstrumentRepositories(memory.NewRepositories(memory.DemoConfig()), m)
+	ctx := context.Background()
+
+	if _, err := repos.GetArtists(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repos.GetArtists(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repos.GetTrack(ctx, music.NewTrackID("missing")); err == nil {
+		t.Fatal("expected an error for a missing track")
+	}
+
+	if got := testutil.ToFloat64(m.repositoryCalls.WithLabelValues("library", "GetArtists")); got != 2 {
+		t.Errorf("expected 2 GetArtists calls, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.repositoryErrors.WithLabelValues("library", "GetTrack", "track_not_found")); got != 1 {
+		t.Errorf("expected 1 track_not_found error, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.repositoryErrors.WithLabelValues("library", "GetArtists", "track_not_found")); got != 0 {
+		t.Errorf("expected no GetArtists errors, got %v", got)
+	}
+}
+
+func TestRecorders(t *testing.T) {
+	m := New()
+
+	m.RecordCacheLookup("tracks", true)
+	m.RecordCacheLookup("tracks", true)
+	m.RecordCacheLookup("tracks", false)
+	m.RecordSessionStarted("human")
+	m.RecordSessionStarted("human")
+	m.RecordSessionEnded("human")
+	m.RecordRateLimited("mcp")
+	m.RecordConnection("grpc", 1)
+	m.RecordMessage("grpc", "in")
+
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"cache hits", testutil.ToFloat64(m.cacheRequests.WithLabelValues("tracks", "hit")), 2},
+		{"cache misses", testutil.ToFloat64(m.cacheRequests.WithLabelValues("tracks", "miss")), 1},
+		{"active sessions", testutil.ToFloat64(m.sessionsActive.WithLabelValues("human")), 1},
+		{"started sessions", testutil.ToFloat64(m.sessionsStarted.WithLabelValues("human")), 2},
+		{"rate limited", testutil.ToFloat64(m.sessionsRateLimited.WithLabelValues("mcp")), 1},
+		{"connections", testutil.ToFloat64(m.transportConnections.WithLabelValues("grpc")), 1},
+		{"messages", testutil.ToFloat64(m.transportMessages.WithLabelValues("grpc", "in")), 1},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, tt.got)
+		}
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{music.NewDomainError(music.ErrTrackNotFound, "gone"), "track_not_found"},
+		{fmt.Errorf("wrapped: %w", music.NewDomainError(music.ErrRateLimited, "slow down")), "rate_limit_exceeded"},
+		{context.DeadlineExceeded, "timeout"},
+		{context.Canceled, "canceled"},
+		{errors.New("other"), "unknown"},
+	}
+	for _, tt := range tests {
+		if got := ErrorCode(tt.err); got != tt.want {
+			t.Errorf("ErrorCode(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	m := New()
+	m.RecordCacheLookup("tracks", true)
+
+	recorder := httptest.NewRecorder()
+	m.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, _ := io.ReadAll(recorder.Body)
+	for _, want := range []string{
+		`maestro_cache_requests_total{cache="tracks",result="hit"} 1`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("expected exposition to contain %q", want)
+		}
+	}
+}
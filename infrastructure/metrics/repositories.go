@@ -0,0 +1,284 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// Repositories wraps a RepositoryManager and records every call, its
+// duration and any error by domain error code.
+type Repositories struct {
+	next    music.RepositoryManager
+	metrics *Metrics
+}
+
+// InstrumentRepositories wraps repos so that every call is recorded in m.
+func InstrumentRepositories(repos music.RepositoryManager, m *Metrics) *Repositories {
+	return &Repositories{next: repos, metrics: m}
+}
+
+// Compile-time check that Repositories implements the full domain port.
+var _ music.RepositoryManager = (*Repositories)(nil)
+
+// call runs a repository method that returns only an error.
+func (r *Repositories) call(repository, method string, fn func() error) error {
+	started := time.Now()
+	err := fn()
+	r.metrics.observeCall(repository, method, started, err)
+	return err
+}
+
+// callValue runs a repository method that returns a value and an error.
+func callValue[T any](r *Repositories, repository, method string, fn func() (T, error)) (T, error) {
+	started := time.Now()
+	value, err := fn()
+	r.metrics.observeCall(repository, method, started, err)
+	return value, err
+}
+
+// PlayerRepository methods
+
+// Play implements music.PlayerRepository.
+func (r *Repositories) Play(ctx context.Context, trackID music.TrackID) error {
+	return r.call("player", "Play", func() error { return r.next.Play(ctx, trackID) })
+}
+
+// Pause implements music.PlayerRepository.
+func (r *Repositories) Pause(ctx context.Context) error {
+	return r.call("player", "Pause", func() error { return r.next.Pause(ctx) })
+}
+
+// Stop implements music.PlayerRepository.
+func (r *Repositories) Stop(ctx context.Context) error {
+	return r.call("player", "Stop", func() error { return r.next.Stop(ctx) })
+}
+
+// Resume implements music.PlayerRepository.
+func (r *Repositories) Resume(ctx context.Context) error {
+	return r.call("player", "Resume", func() error { return r.next.Resume(ctx) })
+}
+
+// Start implements music.PlayerRepository.
+func (r *Repositories) Start(ctx context.Context) error {
+	return r.call("player", "Start", func() error { return r.next.Start(ctx) })
+}
+
+// Next implements music.PlayerRepository.
+func (r *Repositories) Next(ctx context.Context) error {
+	return r.call("player", "Next", func() error { return r.next.Next(ctx) })
+}
+
+// Previous implements music.PlayerRepository.
+func (r *Repositories) Previous(ctx context.Context) error {
+	return r.call("player", "Previous", func() error { return r.next.Previous(ctx) })
+}
+
+// Seek implements music.PlayerRepository.
+func (r *Repositories) Seek(ctx context.Context, position music.Duration) error {
+	return r.call("player", "Seek", func() error { return r.next.Seek(ctx, position) })
+}
+
+// SetVolume implements music.PlayerRepository.
+func (r *Repositories) SetVolume(ctx context.Context, volume music.Volume) error {
+	return r.call("player", "SetVolume", func() error { return r.next.SetVolume(ctx, volume) })
+}
+
+// SetShuffle implements music.PlayerRepository.
+func (r *Repositories) SetShuffle(ctx context.Context, enabled bool) error {
+	return r.call("player", "SetShuffle", func() error { return r.next.SetShuffle(ctx, enabled) })
+}
+
+// SetRepeat implements music.PlayerRepository.
+func (r *Repositories) SetRepeat(ctx context.Context, mode music.RepeatMode) error {
+	return r.call("player", "SetRepeat", func() error { return r.next.SetRepeat(ctx, mode) })
+}
+
+// GetCurrentState implements music.PlayerRepository.
+func (r *Repositories) GetCurrentState(ctx context.Context) (*music.Player, error) {
+	return callValue(r, "player", "GetCurrentState", func() (*music.Player, error) { return r.next.GetCurrentState(ctx) })
+}
+
+// GetCurrentTrack implements music.PlayerRepository.
+func (r *Repositories) GetCurrentTrack(ctx context.Context) (*music.Track, error) {
+	return callValue(r, "player", "GetCurrentTrack", func() (*music.Track, error) { return r.next.GetCurrentTrack(ctx) })
+}
+
+// LibraryRepository methods
+
+// Search implements music.LibraryRepository.
+func (r *Repositories) Search(ctx context.Context, options music.LibrarySearchOptions) ([]*music.Track, error) {
+	return callValue(r, "library", "Search", func() ([]*music.Track, error) { return r.next.Search(ctx, options) })
+}
+
+// GetTrack implements music.LibraryRepository.
+func (r *Repositories) GetTrack(ctx context.Context, trackID music.TrackID) (*music.Track, error) {
+	return callValue(r, "library", "GetTrack", func() (*music.Track, error) { return r.next.GetTrack(ctx, trackID) })
+}
+
+// GetTracks implements music.LibraryRepository.
+func (r *Repositories) GetTracks(ctx context.Context, trackIDs []music.TrackID) ([]*music.Track, error) {
+	return callValue(r, "library", "GetTracks", func() ([]*music.Track, error) { return r.next.GetTracks(ctx, trackIDs) })
+}
+
+// GetAllTracks implements music.LibraryRepository.
+func (r *Repositories) GetAllTracks(ctx context.Context, limit, offset int) ([]*music.Track, error) {
+	return callValue(r, "library", "GetAllTracks", func() ([]*music.Track, error) { return r.next.GetAllTracks(ctx, limit, offset) })
+}
+
+// GetTrackCount implements music.LibraryRepository.
+func (r *Repositories) GetTrackCount(ctx context.Context) (int, error) {
+	return callValue(r, "library", "GetTrackCount", func() (int, error) { return r.next.GetTrackCount(ctx) })
+}
+
+// GetPlaylists implements music.LibraryRepository.
+func (r *Repositories) GetPlaylists(ctx context.Context) ([]*music.Playlist, error) {
+	return callValue(r, "library", "GetPlaylists", func() ([]*music.Playlist, error) { return r.next.GetPlaylists(ctx) })
+}
+
+// GetPlaylist implements music.LibraryRepository.
+func (r *Repositories) GetPlaylist(ctx context.Context, playlistID music.PlaylistID) (*music.Playlist, error) {
+	return callValue(r, "library", "GetPlaylist", func() (*music.Playlist, error) { return r.next.GetPlaylist(ctx, playlistID) })
+}
+
+// GetPlaylistTracks implements music.LibraryRepository.
+func (r *Repositories) GetPlaylistTracks(ctx context.Context, playlistID music.PlaylistID) ([]*music.Track, error) {
+	return callValue(r, "library", "GetPlaylistTracks", func() ([]*music.Track, error) { return r.next.GetPlaylistTracks(ctx, playlistID) })
+}
+
+// GetArtists implements music.LibraryRepository.
+func (r *Repositories) GetArtists(ctx context.Context) ([]string, error) {
+	return callValue(r, "library", "GetArtists", func() ([]string, error) { return r.next.GetArtists(ctx) })
+}
+
+// GetAlbums implements music.LibraryRepository.
+func (r *Repositories) GetAlbums(ctx context.Context) ([]string, error) {
+	return callValue(r, "library", "GetAlbums", func() ([]string, error) { return r.next.GetAlbums(ctx) })
+}
+
+// GetAlbumsByArtist implements music.LibraryRepository.
+func (r *Repositories) GetAlbumsByArtist(ctx context.Context, artist string) ([]string, error) {
+	return callValue(r, "library", "GetAlbumsByArtist", func() ([]string, error) { return r.next.GetAlbumsByArtist(ctx, artist) })
+}
+
+// GetTracksByArtist implements music.LibraryRepository.
+func (r *Repositories) GetTracksByArtist(ctx context.Context, artist string) ([]*music.Track, error) {
+	return callValue(r, "library", "GetTracksByArtist", func() ([]*music.Track, error) { return r.next.GetTracksByArtist(ctx, artist) })
+}
+
+// GetTracksByAlbum implements music.LibraryRepository.
+func (r *Repositories) GetTracksByAlbum(ctx context.Context, album string) ([]*music.Track, error) {
+	return callValue(r, "library", "GetTracksByAlbum", func() ([]*music.Track, error) { return r.next.GetTracksByAlbum(ctx, album) })
+}
+
+// QueueRepository methods
+
+// GetQueue implements music.QueueRepository.
+func (r *Repositories) GetQueue(ctx context.Context) (*music.Playlist, error) {
+	return callValue(r, "queue", "GetQueue", func() (*music.Playlist, error) { return r.next.GetQueue(ctx) })
+}
+
+// AddToQueue implements music.QueueRepository.
+func (r *Repositories) AddToQueue(ctx context.Context, trackID music.TrackID) error {
+	return r.call("queue", "AddToQueue", func() error { return r.next.AddToQueue(ctx, trackID) })
+}
+
+// AddTracksToQueue implements music.QueueRepository.
+func (r *Repositories) AddTracksToQueue(ctx context.Context, trackIDs []music.TrackID) error {
+	return r.call("queue", "AddTracksToQueue", func() error { return r.next.AddTracksToQueue(ctx, trackIDs) })
+}
+
+// PlayNext implements music.QueueRepository.
+func (r *Repositories) PlayNext(ctx context.Context, trackID music.TrackID) error {
+	return r.call("queue", "PlayNext", func() error { return r.next.PlayNext(ctx, trackID) })
+}
+
+// PlayLater implements music.QueueRepository.
+func (r *Repositories) PlayLater(ctx context.Context, trackID music.TrackID) error {
+	return r.call("queue", "PlayLater", func() error { return r.next.PlayLater(ctx, trackID) })
+}
+
+// RemoveFromQueue implements music.QueueRepository.
+func (r *Repositories) RemoveFromQueue(ctx context.Context, position int) error {
+	return r.call("queue", "RemoveFromQueue", func() error { return r.next.RemoveFromQueue(ctx, position) })
+}
+
+// ClearQueue implements music.QueueRepository.
+func (r *Repositories) ClearQueue(ctx context.Context) error {
+	return r.call("queue", "ClearQueue", func() error { return r.next.ClearQueue(ctx) })
+}
+
+// ShuffleQueue implements music.QueueRepository.
+func (r *Repositories) ShuffleQueue(ctx context.Context) error {
+	return r.call("queue", "ShuffleQueue", func() error { return r.next.ShuffleQueue(ctx) })
+}
+
+// GetQueuePosition implements music.QueueRepository.
+func (r *Repositories) GetQueuePosition(ctx context.Context) (int, error) {
+	return callValue(r, "queue", "GetQueuePosition", func() (int, error) { return r.next.GetQueuePosition(ctx) })
+}
+
+// SetQueuePosition implements music.QueueRepository.
+func (r *Repositories) SetQueuePosition(ctx context.Context, position int) error {
+	return r.call("queue", "SetQueuePosition", func() error { return r.next.SetQueuePosition(ctx, position) })
+}
+
+// GetUpNext implements music.QueueRepository.
+func (r *Repositories) GetUpNext(ctx context.Context, count int) ([]*music.Track, error) {
+	return callValue(r, "queue", "GetUpNext", func() ([]*music.Track, error) { return r.next.GetUpNext(ctx, count) })
+}
+
+// PlaylistRepository methods
+
+// CreatePlaylist implements music.PlaylistRepository.
+func (r *Repositories) CreatePlaylist(ctx context.Context, name string) (*music.Playlist, error) {
+	return callValue(r, "playlist", "CreatePlaylist", func() (*music.Playlist, error) { return r.next.CreatePlaylist(ctx, name) })
+}
+
+// UpdatePlaylist implements music.PlaylistRepository.
+func (r *Repositories) UpdatePlaylist(ctx context.Context, playlist *music.Playlist) error {
+	return r.call("playlist", "UpdatePlaylist", func() error { return r.next.UpdatePlaylist(ctx, playlist) })
+}
+
+// DeletePlaylist implements music.PlaylistRepository.
+func (r *Repositories) DeletePlaylist(ctx context.Context, playlistID music.PlaylistID) error {
+	return r.call("playlist", "DeletePlaylist", func() error { return r.next.DeletePlaylist(ctx, playlistID) })
+}
+
+// AddTrackToPlaylist implements music.PlaylistRepository.
+func (r *Repositories) AddTrackToPlaylist(ctx context.Context, playlistID music.PlaylistID, trackID music.TrackID) error {
+	return r.call("playlist", "AddTrackToPlaylist", func() error { return r.next.AddTrackToPlaylist(ctx, playlistID, trackID) })
+}
+
+// RemoveTrackFromPlaylist implements music.PlaylistRepository.
+func (r *Repositories) RemoveTrackFromPlaylist(ctx context.Context, playlistID music.PlaylistID, trackID music.TrackID) error {
+	return r.call("playlist", "RemoveTrackFromPlaylist", func() error { return r.next.RemoveTrackFromPlaylist(ctx, playlistID, trackID) })
+}
+
+// ReorderPlaylistTracks implements music.PlaylistRepository.
+func (r *Repositories) ReorderPlaylistTracks(ctx context.Context, playlistID music.PlaylistID, trackIDs []music.TrackID) error {
+	return r.call("playlist", "ReorderPlaylistTracks", func() error { return r.next.ReorderPlaylistTracks(ctx, playlistID, trackIDs) })
+}
+
+// DuplicatePlaylist implements music.PlaylistRepository.
+func (r *Repositories) DuplicatePlaylist(ctx context.Context, playlistID music.PlaylistID, newName string) (*music.Playlist, error) {
+	return callValue(r, "playlist", "DuplicatePlaylist", func() (*music.Playlist, error) { return r.next.DuplicatePlaylist(ctx, playlistID, newName) })
+}
+
+// RatingRepository methods
+
+// SetRating implements music.RatingRepository.
+func (r *Repositories) SetRating(ctx context.Context, trackID music.TrackID, rating music.Rating) error {
+	return r.call("rating", "SetRating", func() error { return r.next.SetRating(ctx, trackID, rating) })
+}
+
+// SetPreference implements music.RatingRepository.
+func (r *Repositories) SetPreference(ctx context.Context, trackID music.TrackID, preference music.Preference) error {
+	return r.call("rating", "SetPreference", func() error { return r.next.SetPreference(ctx, trackID, preference) })
+}
+
+// GetTracksByRating implements music.RatingRepository.
+func (r *Repositories) GetTracksByRating(ctx context.Context, minimum, maximum music.Rating) ([]*music.Track, error) {
+	return callValue(r, "rating", "GetTracksByRating", func() ([]*music.Track, error) { return r.next.GetTracksByRating(ctx, minimum, maximum) })
+}
@@ -0,0 +1,281 @@
+// Package config loads Maestro configuration for the daemon and its clients.
+//
+// Configuration is layered. Later layers override earlier ones key by key:
+//
+//  1. built-in defaults
+//  2. the system file, /etc/maestro/<name>.toml
+//  3. the user file, $XDG_CONFIG_HOME/maestro/<name>.toml (~/.config by default)
+//  4. the project file, the nearest .<name>.toml in the working directory or a parent
+//  5. an explicit file passed with --config
+//  6. environment variables, MAESTRO_<SECTION>_<KEY> (e.g. MAESTRO_LOG_LEVEL)
+//  7. command-line overrides, --set <section>.<key>=<value>
+//
+// where <name> is "maestro" for clients and "maestrod" for the daemon. Every
+// key remembers the layer it came from, so validation errors point at the
+// exact file, line and column, environment variable or flag to fix.
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/madstone-tech/maestro/pkg/logger"
+)
+
+// Config is the complete configuration shared by the daemon and its clients.
+type Config struct {
+	// Executor configures how AppleScript is run
+	Executor ExecutorConfig `toml:"executor" json:"executor"`
+
+	// Retry configures retries of failed AppleScript executions
+	Retry RetryConfig `toml:"retry" json:"retry"`
+
+	// Session configures the session policies per client type
+	Session SessionConfig `toml:"session" json:"session"`
+
+	// Cache configures the daemon's library and player cache
+	Cache CacheConfig `toml:"cache" json:"cache"`
+
+	// SmartPlaylists configures maestro's rule-based playlists
+	SmartPlaylists SmartPlaylistsConfig `toml:"smart_playlists" json:"smart_playlists"`
+
+	// History configures the local play history
+	History HistoryConfig `toml:"history" json:"history"`
+
+	// Transport configures how clients reach the daemon
+	Transport TransportConfig `toml:"transport" json:"transport"`
+
+	// TLS configures mutual TLS between clients and the daemon
+	TLS TLSConfig `toml:"tls" json:"tls"`
+
+	// Health configures the daemon's health check server
+	Health HealthConfig `toml:"health" json:"health"`
+
+	// Metrics configures Prometheus metrics
+	Metrics MetricsConfig `toml:"metrics" json:"metrics"`
+
+	// Log configures logging
+	Log LogConfig `toml:"log" json:"log"`
+
+	// sources records where each key's value came from
+	sources map[string]Source
+}
+
+// ExecutorConfig configures the AppleScript executor.
+type ExecutorConfig struct {
+	// ExecPath is the path to the maestro-exec binary
+	ExecPath string `toml:"exec_path" json:"exec_path"`
+
+	// Timeout is the default timeout for one script execution
+	Timeout Duration `toml:"timeout" json:"timeout"`
+}
+
+// RetryConfig configures the retry policy for failed executions.
+type RetryConfig struct {
+	// MaxRetries is the maximum number of retries after the first attempt
+	MaxRetries int `toml:"max_retries" json:"max_retries"`
+
+	// Delay is the pause between attempts
+	Delay Duration `toml:"delay" json:"delay"`
+}
+
+// SessionConfig configures the session policies.
+type SessionConfig struct {
+	// MCPRateLimit is the maximum number of MCP requests per MCPRateWindow (0 = unlimited)
+	MCPRateLimit int `toml:"mcp_rate_limit" json:"mcp_rate_limit"`
+
+	// MCPRateWindow is the window over which MCPRateLimit is applied
+	MCPRateWindow Duration `toml:"mcp_rate_window" json:"mcp_rate_window"`
+}
+
+// CacheConfig configures caching in the daemon.
+type CacheConfig struct {
+	// Enabled turns caching on or off
+	Enabled bool `toml:"enabled" json:"enabled"`
+
+	// TTL is how long cached library data stays fresh
+	TTL Duration `toml:"ttl" json:"ttl"`
+}
+
+// SmartPlaylistsConfig configures the smart playlists maestro owns.
+type SmartPlaylistsConfig struct {
+	// File holds the playlists' rules ("" for ~/.maestro_smart_playlists.json)
+	File string `toml:"file" json:"file"`
+
+	// RefreshInterval is how often the daemon checks the library for changes
+	// to re-materialize live-updating playlists (0 disables)
+	RefreshInterval Duration `toml:"refresh_interval" json:"refresh_interval"`
+}
+
+// HistoryConfig configures the local play history.
+type HistoryConfig struct {
+	// Enabled records plays and skips in the daemon, and lets clients mark
+	// the tracks they start
+	Enabled bool `toml:"enabled" json:"enabled"`
+
+	// File is the append-only log of plays ("" for ~/.maestro_history.jsonl)
+	File string `toml:"file" json:"file"`
+}
+
+// TransportConfig configures the connection between clients and the daemon.
+type TransportConfig struct {
+	// Address is the host:port the daemon listens on and clients dial
+	Address string `toml:"address" json:"address"`
+
+	// DialTimeout bounds how long clients wait to connect
+	DialTimeout Duration `toml:"dial_timeout" json:"dial_timeout"`
+}
+
+// TLSConfig configures mutual TLS.
+type TLSConfig struct {
+	// Enabled turns on mutual TLS
+	Enabled bool `toml:"enabled" json:"enabled"`
+
+	// CAFile is the CA certificate used to verify the peer
+	CAFile string `toml:"ca_file" json:"ca_file"`
+
+	// CertFile is this side's certificate
+	CertFile string `toml:"cert_file" json:"cert_file"`
+
+	// KeyFile is this side's private key
+	KeyFile string `toml:"key_file" json:"key_file"`
+
+	// MinVersion is the minimum TLS version, "1.2" or "1.3"
+	MinVersion string `toml:"min_version" json:"min_version"`
+}
+
+// HealthConfig configures the health check server.
+type HealthConfig struct {
+	// Enabled turns the /healthz and /readyz endpoints on or off
+	Enabled bool `toml:"enabled" json:"enabled"`
+
+	// Address is the local host:port the endpoints listen on
+	Address string `toml:"address" json:"address"`
+
+	// Timeout bounds each component check
+	Timeout Duration `toml:"timeout" json:"timeout"`
+
+	// CacheTTL is how long a component's result is reused
+	CacheTTL Duration `toml:"cache_ttl" json:"cache_ttl"`
+}
+
+// MetricsConfig configures the Prometheus metrics endpoint.
+type MetricsConfig struct {
+	// Enabled serves metrics on the health server's address
+	Enabled bool `toml:"enabled" json:"enabled"`
+
+	// Path is the URL path of the metrics endpoint
+	Path string `toml:"path" json:"path"`
+}
+
+// LogConfig configures logging.
+type LogConfig struct {
+	// Level is the minimum level: trace, debug, info, warn or error
+	Level string `toml:"level" json:"level"`
+
+	// Format is "json" or "text"
+	Format string `toml:"format" json:"format"`
+
+	// Output is "stdout", "stderr" or a file path
+	Output string `toml:"output" json:"output"`
+
+	// Caller adds the calling function to each entry
+	Caller bool `toml:"caller" json:"caller"`
+}
+
+// Default returns the built-in configuration.
+func Default() *Config {
+	return &Config{
+		Executor: ExecutorConfig{
+			ExecPath: "maestro-exec",
+			Timeout:  Duration(10 * time.Second),
+		},
+		Retry: RetryConfig{
+			MaxRetries: 3,
+			Delay:      Duration(500 * time.Millisecond),
+		},
+		Session: SessionConfig{
+			MCPRateLimit:  20,
+			MCPRateWindow: Duration(time.Minute),
+		},
+		Cache: CacheConfig{
+			Enabled: true,
+			TTL:     Duration(5 * time.Minute),
+		},
+		SmartPlaylists: SmartPlaylistsConfig{
+			RefreshInterval: Duration(5 * time.Minute),
+		},
+		History: HistoryConfig{
+			Enabled: true,
+		},
+		Transport: TransportConfig{
+			Address:     "127.0.0.1:7433",
+			DialTimeout: Duration(5 * time.Second),
+		},
+		TLS: TLSConfig{
+			MinVersion: "1.3",
+		},
+		Health: HealthConfig{
+			Enabled:  true,
+			Address:  "127.0.0.1:7434",
+			Timeout:  Duration(2 * time.Second),
+			CacheTTL: Duration(5 * time.Second),
+		},
+		Metrics: MetricsConfig{
+			Enabled: true,
+			Path:    "/metrics",
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+			Output: "stdout",
+		},
+	}
+}
+
+// Logger returns the logger configuration for component.
+func (l LogConfig) Logger(component string) *logger.Config {
+	config := logger.DefaultConfig()
+	config.Level = l.Level
+	config.Format = l.Format
+	config.Output = l.Output
+	config.EnableCaller = l.Caller
+	config.Component = component
+	return config
+}
+
+// Source returns where the value of key (e.g. "log.level") came from.
+func (c *Config) Source(key string) Source {
+	if source, ok := c.sources[key]; ok {
+		return source
+	}
+	return Source{Kind: SourceDefault}
+}
+
+// Duration is a time.Duration written in TOML as a string such as "1m30s".
+type Duration time.Duration
+
+// Std returns the duration as a time.Duration.
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+// String formats the duration like time.Duration.
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// MarshalText encodes the duration as a string such as "1m30s".
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText decodes a duration string such as "1m30s".
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q (use a value such as \"500ms\", \"10s\" or \"5m\")", string(text))
+	}
+	*d = Duration(parsed)
+	return nil
+}
@@ -0,0 +1,241 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testOptions returns options that read only from the given directories.
+func testOptions(t *testing.T) (*LoadOptions, string, string) {
+	t.Helper()
+	root := t.TempDir()
+	system := filepath.Join(root, "etc")
+	user := filepath.Join(root, "home")
+	work := filepath.Join(root, "project", "sub")
+	for _, dir := range []string{system, user, work} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &LoadOptions{Name: "maestro", SystemDir: system, UserDir: user, WorkDir: work}, system, user
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDefaultIsValid(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Fatalf("Default config should be valid, got: %v", err)
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	options, system, user := testOptions(t)
+	writeFile(t, filepath.Join(system, "maestro.toml"), "[log]\nlevel = \"warn\"\nformat = \"text\"\n")
+	writeFile(t, filepath.Join(user, "maestro.toml"), "[log]\nlevel = \"debug\"\n")
+	project := filepath.Join(filepath.Dir(options.WorkDir), ".maestro.toml")
+	writeFile(t, project, "[executor]\ntimeout = \"30s\"\n")
+
+	options.Environ = []string{"MAESTRO_RETRY_MAX_RETRIES=1", "OTHER=1"}
+	options.Overrides = []string{"cache.enabled=false"}
+
+	config, err := Load(options)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if config.Log.Level != "debug" {
+		t.Errorf("Expected user file to override level, got %q", config.Log.Level)
+	}
+	if config.Log.Format != "text" {
+		t.Errorf("Expected system file format, got %q", config.Log.Format)
+	}
+	if config.Executor.Timeout.Std() != 30*time.Second {
+		t.Errorf("Expected project timeout 30s, got %s", config.Executor.Timeout)
+	}
+	if config.Retry.MaxRetries != 1 {
+		t.Errorf("Expected env max_retries 1, got %d", config.Retry.MaxRetries)
+	}
+	if config.Cache.Enabled {
+		t.Error("Expected --set to disable the cache")
+	}
+
+	tests := map[string]string{
+		"log.level":         filepath.Join(user, "maestro.toml") + ":2:1",
+		"log.format":        filepath.Join(system, "maestro.toml") + ":3:1",
+		"executor.timeout":  project + ":2:1",
+		"retry.max_retries": "$MAESTRO_RETRY_MAX_RETRIES",
+		"cache.enabled":     "--set cache.enabled",
+		"log.output":        "default",
+	}
+	for key, want := range tests {
+		if got := config.Source(key).String(); got != want {
+			t.Errorf("Source(%q) = %q, want %q", key, got, want)
+		}
+	}
+
+	if files := config.Files(); len(files) != 3 {
+		t.Errorf("Expected 3 files, got %v", files)
+	}
+}
+
+func TestLoadReportsLocations(t *testing.T) {
+	options, _, user := testOptions(t)
+	path := filepath.Join(user, "maestro.toml")
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"unknown key", "[log]\nlevel = \"info\"\ncolour = true\n", path + ":3:1: log.colour: unknown key"},
+		{"bad duration", "[executor]\ntimeout = \"soon\"\n", path + ":2:"},
+		{"invalid value", "[log]\n\nlevel = \"loud\"\n", path + ":3:1: log.level: \"loud\" is not one of"},
+		{"inline table", "log = { format = \"xml\" }\n", path + ":1:9: log.format:"},
+		{"syntax", "[log\n", path + ":1:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeFile(t, path, tt.content)
+			_, err := Load(options)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			var errs Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Expected Errors, got %T", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %q", tt.want, err.Error())
+			}
+		})
+	}
+}
+
+func TestLoadEnvAndOverrideErrors(t *testing.T) {
+	options, _, _ := testOptions(t)
+	options.Environ = []string{"MAESTRO_CACHE_TTL=forever"}
+	options.Overrides = []string{"log.nope=1", "retry.max_retries=9"}
+
+	_, err := Load(options)
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	for _, want := range []string{"$MAESTRO_CACHE_TTL: cache.ttl: invalid duration", "--set log.nope: log.nope: unknown key"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error containing %q, got %q", want, err.Error())
+		}
+	}
+
+	options.Environ = nil
+	options.Overrides = []string{"retry.max_retries=9"}
+	_, err = Load(options)
+	if err == nil || !strings.Contains(err.Error(), "--set retry.max_retries: retry.max_retries: must be between 0 and 5") {
+		t.Errorf("Expected a located validation error, got %v", err)
+	}
+}
+
+func TestLoadExplicitFileMustExist(t *testing.T) {
+	options, _, _ := testOptions(t)
+	options.File = filepath.Join(t.TempDir(), "missing.toml")
+	if _, err := Load(options); err == nil {
+		t.Error("Expected an error for a missing --config file")
+	}
+}
+
+func TestWriteTOMLRoundTrip(t *testing.T) {
+	config := Default()
+	config.Log.Level = "debug"
+	config.Session.MCPRateWindow = Duration(90 * time.Second)
+
+	var buf bytes.Buffer
+	if err := config.WriteTOML(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	options, _, user := testOptions(t)
+	writeFile(t, filepath.Join(user, "maestro.toml"), buf.String())
+	loaded, err := Load(options)
+	if err != nil {
+		t.Fatalf("Load failed: %v\n%s", err, buf.String())
+	}
+	if changed := Diff(config, loaded); len(changed) != 0 {
+		t.Errorf("Expected no differences after round trip, got %v", changed)
+	}
+}
+
+func TestDiffAndReloadSafe(t *testing.T) {
+	previous := Default()
+	current := Default()
+	current.Log.Level = "debug"
+	current.Transport.Address = "127.0.0.1:9000"
+
+	changed := Diff(previous, current)
+	if len(changed) != 2 || changed[0] != "transport.address" || changed[1] != "log.level" {
+		t.Errorf("Unexpected diff: %v", changed)
+	}
+	if ReloadSafe("transport.address") || ReloadSafe("tls.enabled") || ReloadSafe("log.output") ||
+		ReloadSafe("smart_playlists.file") || ReloadSafe("history.enabled") || ReloadSafe("session.mcp_rate_limit") {
+		t.Error("Expected transport, TLS, log output, the smart playlist file, history and sessions to require a restart")
+	}
+	if !ReloadSafe("log.level") || !ReloadSafe("executor.timeout") || !ReloadSafe("cache.ttl") {
+		t.Error("Expected log level, executor timeout and cache TTL to be reloadable")
+	}
+}
+
+func TestWatcherReloads(t *testing.T) {
+	options, _, user := testOptions(t)
+	path := filepath.Join(user, "maestro.toml")
+	writeFile(t, path, "[log]\nlevel = \"info\"\n")
+
+	initial, err := Load(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := make(chan []string, 1)
+	failed := make(chan error, 1)
+	watcher := NewWatcher(options, initial, &WatcherConfig{
+		Interval: 10 * time.Millisecond,
+		OnReload: func(_, _ *Config, changed []string) { reloaded <- changed },
+		OnError:  func(err error) { failed <- err },
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watcher.Run(ctx)
+
+	writeFile(t, path, "[log]\nlevel = \"debug\"\nformat = \"text\"\n")
+	select {
+	case changed := <-reloaded:
+		if len(changed) != 2 {
+			t.Errorf("Expected 2 changed keys, got %v", changed)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for reload")
+	}
+	if watcher.Current().Log.Level != "debug" {
+		t.Errorf("Expected reloaded level debug, got %q", watcher.Current().Log.Level)
+	}
+
+	writeFile(t, path, "[log]\nlevel = \"shouting\"\n")
+	select {
+	case <-failed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for reload error")
+	}
+	if watcher.Current().Log.Level != "debug" {
+		t.Error("Expected the previous configuration to stay in effect")
+	}
+}
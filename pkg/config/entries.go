@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Entry is one effective configuration value.
+type Entry struct {
+	// Key is the dotted path, e.g. "log.level"
+	Key string
+
+	// Value is the value formatted as a TOML literal
+	Value string
+
+	// Source is where the value came from
+	Source Source
+}
+
+// fields returns every leaf field keyed by dotted path.
+func (c *Config) fields() map[string]reflect.Value {
+	fields := make(map[string]reflect.Value)
+	walkFields(reflect.ValueOf(c).Elem(), "", func(key string, field reflect.Value) {
+		fields[key] = field
+	})
+	return fields
+}
+
+// walkFields calls fn for every exported leaf field of a section struct, in
+// declaration order.
+func walkFields(v reflect.Value, prefix string, fn func(key string, field reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("toml")
+		if !f.IsExported() || tag == "" {
+			continue
+		}
+		key := tag
+		if prefix != "" {
+			key = prefix + "." + tag
+		}
+		if f.Type.Kind() == reflect.Struct {
+			walkFields(v.Field(i), key, fn)
+			continue
+		}
+		fn(key, v.Field(i))
+	}
+}
+
+// Keys returns every configuration key in declaration order.
+func (c *Config) Keys() []string {
+	var keys []string
+	walkFields(reflect.ValueOf(c).Elem(), "", func(key string, _ reflect.Value) {
+		keys = append(keys, key)
+	})
+	return keys
+}
+
+// Entries returns every effective value with its source, in declaration order.
+func (c *Config) Entries() []Entry {
+	var entries []Entry
+	walkFields(reflect.ValueOf(c).Elem(), "", func(key string, field reflect.Value) {
+		entries = append(entries, Entry{Key: key, Value: formatValue(field), Source: c.Source(key)})
+	})
+	return entries
+}
+
+// formatValue renders a leaf field as a TOML literal.
+func formatValue(field reflect.Value) string {
+	if d, ok := field.Interface().(Duration); ok {
+		return strconv.Quote(d.String())
+	}
+	switch field.Kind() {
+	case reflect.String:
+		return strconv.Quote(field.String())
+	default:
+		return fmt.Sprint(field.Interface())
+	}
+}
+
+// WriteTOML writes the effective configuration as a TOML document.
+func (c *Config) WriteTOML(w io.Writer) error {
+	encoder := toml.NewEncoder(w)
+	encoder.SetIndentTables(false)
+	return encoder.Encode(c)
+}
+
+// Diff returns the keys whose values differ between two configurations.
+func Diff(previous, current *Config) []string {
+	before := make(map[string]string)
+	for _, entry := range previous.Entries() {
+		before[entry.Key] = entry.Value
+	}
+
+	var changed []string
+	for _, entry := range current.Entries() {
+		if before[entry.Key] != entry.Value {
+			changed = append(changed, entry.Key)
+		}
+	}
+	return changed
+}
+
+// ReloadSafe reports whether a running daemon can apply a change to key
+// without a restart. Listeners, TLS material, session policies, the log
+// destination, the smart playlist file and the history settings are only
+// read at startup.
+func ReloadSafe(key string) bool {
+	switch {
+	case strings.HasPrefix(key, "transport."), strings.HasPrefix(key, "tls."), strings.HasPrefix(key, "health."),
+		strings.HasPrefix(key, "metrics."), strings.HasPrefix(key, "session."), strings.HasPrefix(key, "history."):
+		return false
+	case key == "log.output", key == "smart_playlists.file":
+		return false
+	default:
+		return true
+	}
+}
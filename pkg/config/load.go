@@ -0,0 +1,424 @@
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+)
+
+// envPrefix prefixes every configuration environment variable.
+const envPrefix = "MAESTRO_"
+
+// SourceKind identifies the layer a value came from.
+type SourceKind int
+
+const (
+	// SourceDefault is the built-in default
+	SourceDefault SourceKind = iota
+
+	// SourceSystem is the system-wide file
+	SourceSystem
+
+	// SourceUser is the user's file
+	SourceUser
+
+	// SourceProject is the nearest project file
+	SourceProject
+
+	// SourceFile is a file named explicitly with --config
+	SourceFile
+
+	// SourceEnv is an environment variable
+	SourceEnv
+
+	// SourceFlag is a --set command-line override
+	SourceFlag
+)
+
+// String returns the string representation of the SourceKind.
+func (k SourceKind) String() string {
+	switch k {
+	case SourceDefault:
+		return "default"
+	case SourceSystem:
+		return "system"
+	case SourceUser:
+		return "user"
+	case SourceProject:
+		return "project"
+	case SourceFile:
+		return "file"
+	case SourceEnv:
+		return "env"
+	case SourceFlag:
+		return "flag"
+	default:
+		return "unknown"
+	}
+}
+
+// Source is where a configuration value came from.
+type Source struct {
+	// Kind is the layer
+	Kind SourceKind
+
+	// Name is the file path, environment variable or overridden key
+	Name string
+
+	// Line and Column locate the key within a file (1-based, 0 when unknown)
+	Line   int
+	Column int
+}
+
+// String renders the source as "path:line:col", "$VAR", "--set key" or "default".
+func (s Source) String() string {
+	switch s.Kind {
+	case SourceDefault:
+		return "default"
+	case SourceEnv:
+		return "$" + s.Name
+	case SourceFlag:
+		return "--set " + s.Name
+	}
+	if s.Line > 0 {
+		return fmt.Sprintf("%s:%d:%d", s.Name, s.Line, s.Column)
+	}
+	return s.Name
+}
+
+// LoadOptions controls where Load looks for configuration.
+type LoadOptions struct {
+	// Name selects the files to read: "maestro" for clients, "maestrod" for the daemon
+	Name string
+
+	// SystemDir holds the system file ("" skips the layer)
+	SystemDir string
+
+	// UserDir holds the user file ("" skips the layer)
+	UserDir string
+
+	// WorkDir is where the search for a project file starts ("" skips the layer)
+	WorkDir string
+
+	// File is an explicit file that must exist ("" for none)
+	File string
+
+	// Environ is the environment, as returned by os.Environ
+	Environ []string
+
+	// Overrides are "key=value" pairs from --set flags
+	Overrides []string
+}
+
+// DefaultLoadOptions returns the standard locations for the named program.
+func DefaultLoadOptions(name string) *LoadOptions {
+	options := &LoadOptions{
+		Name:      name,
+		SystemDir: "/etc/maestro",
+		Environ:   os.Environ(),
+	}
+
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		options.UserDir = filepath.Join(dir, "maestro")
+	} else if home, err := os.UserHomeDir(); err == nil {
+		options.UserDir = filepath.Join(home, ".config", "maestro")
+	}
+
+	if dir, err := os.Getwd(); err == nil {
+		options.WorkDir = dir
+	}
+
+	return options
+}
+
+// layer is one configuration file.
+type layer struct {
+	kind     SourceKind
+	path     string
+	required bool
+}
+
+// layers returns the candidate files in the order they are applied.
+func (o *LoadOptions) layers() []layer {
+	var layers []layer
+	file := o.Name + ".toml"
+
+	if o.SystemDir != "" {
+		layers = append(layers, layer{kind: SourceSystem, path: filepath.Join(o.SystemDir, file)})
+	}
+	if o.UserDir != "" {
+		layers = append(layers, layer{kind: SourceUser, path: filepath.Join(o.UserDir, file)})
+	}
+	if project := findProjectFile(o.WorkDir, "."+file); project != "" {
+		layers = append(layers, layer{kind: SourceProject, path: project})
+	}
+	if o.File != "" {
+		layers = append(layers, layer{kind: SourceFile, path: o.File, required: true})
+	}
+	return layers
+}
+
+// findProjectFile returns the nearest file called name in dir or a parent.
+func findProjectFile(dir, name string) string {
+	if dir == "" {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Load reads every layer over the defaults and validates the result. Errors
+// are returned as Errors, each pointing at the offending source.
+func Load(options *LoadOptions) (*Config, error) {
+	if options == nil {
+		options = DefaultLoadOptions("maestro")
+	}
+
+	config := Default()
+	config.sources = make(map[string]Source)
+	fields := config.fields()
+
+	var errs Errors
+	for _, l := range options.layers() {
+		errs = append(errs, config.loadFile(l, fields)...)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	errs = append(errs, config.loadEnv(options.Environ, fields)...)
+	errs = append(errs, config.loadOverrides(options.Overrides, fields)...)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Files returns the files that were read, in the order they were applied.
+func (c *Config) Files() []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, key := range c.Keys() {
+		source := c.Source(key)
+		if source.Kind >= SourceSystem && source.Kind <= SourceFile && !seen[source.Name] {
+			seen[source.Name] = true
+			files = append(files, source.Name)
+		}
+	}
+	return files
+}
+
+// loadFile decodes one file over c and records the position of every key.
+func (c *Config) loadFile(l layer, fields map[string]reflect.Value) Errors {
+	data, err := os.ReadFile(l.path)
+	if errors.Is(err, os.ErrNotExist) && !l.required {
+		return nil
+	}
+	if err != nil {
+		return Errors{{Source: Source{Kind: l.kind, Name: l.path}, Message: err.Error()}}
+	}
+
+	decoder := toml.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(c); err != nil {
+		return decodeErrors(l, err)
+	}
+
+	positions, err := keyPositions(data)
+	if err != nil {
+		return Errors{{Source: Source{Kind: l.kind, Name: l.path}, Message: err.Error()}}
+	}
+	for key, position := range positions {
+		if _, ok := fields[key]; ok {
+			c.sources[key] = Source{Kind: l.kind, Name: l.path, Line: position.Line, Column: position.Column}
+		}
+	}
+	return nil
+}
+
+// decodeErrors converts go-toml errors into located FieldErrors.
+func decodeErrors(l layer, err error) Errors {
+	located := func(de *toml.DecodeError) *FieldError {
+		line, column := de.Position()
+		return &FieldError{
+			Key:     strings.Join(de.Key(), "."),
+			Source:  Source{Kind: l.kind, Name: l.path, Line: line, Column: column},
+			Message: strings.TrimPrefix(de.Error(), "toml: "),
+		}
+	}
+
+	var strict *toml.StrictMissingError
+	if errors.As(err, &strict) {
+		errs := make(Errors, 0, len(strict.Errors))
+		for i := range strict.Errors {
+			fe := located(&strict.Errors[i])
+			fe.Message = "unknown key"
+			errs = append(errs, fe)
+		}
+		return errs
+	}
+
+	var de *toml.DecodeError
+	if errors.As(err, &de) {
+		return Errors{located(de)}
+	}
+	return Errors{{Source: Source{Kind: l.kind, Name: l.path}, Message: strings.TrimPrefix(err.Error(), "toml: ")}}
+}
+
+// keyPositions returns the position of every key assigned in a TOML document,
+// keyed by dotted path.
+func keyPositions(data []byte) (map[string]unstable.Position, error) {
+	positions := make(map[string]unstable.Position)
+
+	var parser unstable.Parser
+	parser.Reset(data)
+
+	var table []string
+	for parser.NextExpression() {
+		expr := parser.Expression()
+		switch expr.Kind {
+		case unstable.Table, unstable.ArrayTable:
+			table = keyParts(expr.Key())
+		case unstable.KeyValue:
+			recordKeyValue(&parser, positions, table, expr)
+		}
+	}
+	return positions, parser.Error()
+}
+
+// recordKeyValue records the position of a key/value expression, descending
+// into inline tables.
+func recordKeyValue(parser *unstable.Parser, positions map[string]unstable.Position, prefix []string, expr *unstable.Node) {
+	keys := expr.Key()
+	if !keys.Next() {
+		return
+	}
+	position := parser.Shape(keys.Node().Raw).Start
+
+	path := append(append([]string{}, prefix...), string(keys.Node().Data))
+	for keys.Next() {
+		path = append(path, string(keys.Node().Data))
+	}
+	positions[strings.Join(path, ".")] = position
+
+	if value := expr.Value(); value.Kind == unstable.InlineTable {
+		children := value.Children()
+		for children.Next() {
+			recordKeyValue(parser, positions, path, children.Node())
+		}
+	}
+}
+
+func keyParts(keys unstable.Iterator) []string {
+	var parts []string
+	for keys.Next() {
+		parts = append(parts, string(keys.Node().Data))
+	}
+	return parts
+}
+
+// loadEnv applies MAESTRO_<SECTION>_<KEY> variables.
+func (c *Config) loadEnv(environ []string, fields map[string]reflect.Value) Errors {
+	values := make(map[string]string, len(environ))
+	for _, entry := range environ {
+		if name, value, ok := strings.Cut(entry, "="); ok && strings.HasPrefix(name, envPrefix) {
+			values[name] = value
+		}
+	}
+
+	var errs Errors
+	for _, key := range c.Keys() {
+		name := EnvVar(key)
+		raw, ok := values[name]
+		if !ok {
+			continue
+		}
+		source := Source{Kind: SourceEnv, Name: name}
+		if err := setField(fields[key], raw); err != nil {
+			errs = append(errs, &FieldError{Key: key, Source: source, Message: err.Error()})
+			continue
+		}
+		c.sources[key] = source
+	}
+	return errs
+}
+
+// loadOverrides applies "key=value" overrides.
+func (c *Config) loadOverrides(overrides []string, fields map[string]reflect.Value) Errors {
+	var errs Errors
+	for _, override := range overrides {
+		key, raw, ok := strings.Cut(override, "=")
+		key = strings.TrimSpace(key)
+		source := Source{Kind: SourceFlag, Name: key}
+
+		field, known := fields[key]
+		switch {
+		case !ok:
+			errs = append(errs, &FieldError{Key: key, Source: source, Message: "expected key=value"})
+		case !known:
+			errs = append(errs, &FieldError{Key: key, Source: source, Message: "unknown key"})
+		default:
+			if err := setField(field, strings.TrimSpace(raw)); err != nil {
+				errs = append(errs, &FieldError{Key: key, Source: source, Message: err.Error()})
+				continue
+			}
+			c.sources[key] = source
+		}
+	}
+	return errs
+}
+
+// EnvVar returns the environment variable that sets key, e.g.
+// MAESTRO_LOG_LEVEL for "log.level".
+func EnvVar(key string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// setField parses raw into a leaf field.
+func setField(field reflect.Value, raw string) error {
+	if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(raw))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid value %q (must be a whole number)", raw)
+		}
+		field.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid value %q (must be true or false)", raw)
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+)
+
+// tlsVersionIDs maps tls.min_version values to crypto/tls versions.
+var tlsVersionIDs = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ControlURL returns the URL of path on the daemon's control transport:
+// https when TLS is enabled, http otherwise.
+func (c *Config) ControlURL(path string) string {
+	scheme := "http"
+	if c.TLS.Enabled {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Transport.Address + path
+}
+
+// ControlClient returns an HTTP client for the daemon's control transport.
+// It gives up connecting after transport.dial_timeout and, when TLS is
+// enabled, presents the configured certificate and trusts only the
+// configured CA.
+func (c *Config) ControlClient() (*http.Client, error) {
+	transport := &http.Transport{
+		DialContext: (&net.Dialer{Timeout: c.Transport.DialTimeout.Std()}).DialContext,
+	}
+	if c.TLS.Enabled {
+		tlsConfig, err := c.TLS.ClientConfig()
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// ServerConfig returns the TLS configuration of the daemon's side of the
+// control transport, which requires clients to present a certificate
+// signed by the CA.
+func (t TLSConfig) ServerConfig() (*tls.Config, error) {
+	certificate, pool, err := t.load()
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tlsVersionIDs[t.MinVersion],
+	}, nil
+}
+
+// ClientConfig returns the TLS configuration of a client of the control
+// transport, which verifies the daemon against the CA.
+func (t TLSConfig) ClientConfig() (*tls.Config, error) {
+	certificate, pool, err := t.load()
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		RootCAs:      pool,
+		MinVersion:   tlsVersionIDs[t.MinVersion],
+	}, nil
+}
+
+// load reads this side's certificate and the CA.
+func (t TLSConfig) load() (tls.Certificate, *x509.CertPool, error) {
+	certificate, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("tls: load certificate: %w", err)
+	}
+	pem, err := os.ReadFile(t.CAFile)
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("tls: read CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return tls.Certificate{}, nil, fmt.Errorf("tls: no certificates found in %s", t.CAFile)
+	}
+	return certificate, pool, nil
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransportAddressNeedsLoopbackOrTLS(t *testing.T) {
+	tests := []struct {
+		address string
+		tls     bool
+		valid   bool
+	}{
+		{"127.0.0.1:7433", false, true},
+		{"localhost:7433", false, true},
+		{"[::1]:7433", false, true},
+		{"0.0.0.0:7433", false, false},
+		{"192.168.1.20:7433", false, false},
+		{"192.168.1.20:7433", true, true},
+	}
+	for _, tt := range tests {
+		cfg := Default()
+		cfg.Transport.Address = tt.address
+		if tt.tls {
+			cfg.TLS = TLSConfig{Enabled: true, CAFile: "ca.pem", CertFile: "cert.pem", KeyFile: "key.pem", MinVersion: "1.3"}
+		}
+		err := cfg.Validate()
+		if (err == nil) != tt.valid {
+			t.Errorf("%s (tls %t): expected valid %t, got %v", tt.address, tt.tls, tt.valid, err)
+		}
+		if err != nil && !strings.Contains(err.Error(), "transport.address") {
+			t.Errorf("%s: expected the error to name transport.address, got %v", tt.address, err)
+		}
+	}
+}
+
+func TestControlURL(t *testing.T) {
+	cfg := Default()
+	if got := cfg.ControlURL("/v1/fade"); got != "http://127.0.0.1:7433/v1/fade" {
+		t.Errorf("unexpected plain URL %q", got)
+	}
+	cfg.TLS.Enabled = true
+	if got := cfg.ControlURL("/v1/fade"); got != "https://127.0.0.1:7433/v1/fade" {
+		t.Errorf("unexpected TLS URL %q", got)
+	}
+}
+
+func TestControlClientMutualTLS(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t, t.TempDir())
+	cfg := Default()
+	cfg.TLS = TLSConfig{Enabled: true, CAFile: certFile, CertFile: certFile, KeyFile: keyFile, MinVersion: "1.3"}
+
+	serverTLS, err := cfg.TLS.ServerConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = serverTLS
+	server.StartTLS()
+	defer server.Close()
+	cfg.Transport.Address = server.Listener.Addr().String()
+
+	client, err := cfg.ControlClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(cfg.ControlURL("/"))
+	if err != nil {
+		t.Fatalf("expected the configured client to connect, got %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("unexpected status %s", resp.Status)
+	}
+
+	// A client without a certificate is turned away
+	anonymous := &http.Client{Transport: &http.Transport{TLSClientConfig: serverTLS.Clone()}}
+	anonymous.Transport.(*http.Transport).TLSClientConfig.Certificates = nil
+	anonymous.Transport.(*http.Transport).TLSClientConfig.RootCAs = serverTLS.ClientCAs
+	if resp, err := anonymous.Get(cfg.ControlURL("/")); err == nil {
+		_ = resp.Body.Close()
+		t.Error("expected a client without a certificate to be rejected")
+	}
+
+	cfg.TLS.CAFile = filepath.Join(t.TempDir(), "missing.pem")
+	if _, err := cfg.ControlClient(); err == nil {
+		t.Error("expected a missing CA to be reported")
+	}
+}
+
+// writeTestCertificate writes a self-signed certificate for 127.0.0.1 that
+// serves as CA, server and client certificate, and its key.
+func writeTestCertificate(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "maestrod"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, "maestrod.pem")
+	keyFile := filepath.Join(dir, "maestrod.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
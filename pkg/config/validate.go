@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Allowed values for enumerated keys.
+var (
+	tlsVersions = []string{"1.2", "1.3"}
+	logLevels   = []string{"trace", "debug", "info", "warn", "error"}
+	logFormats  = []string{"json", "text"}
+)
+
+// maxRetries is the most retries the retry policy may allow.
+const maxRetries = 5
+
+// FieldError is a problem with one configuration key.
+type FieldError struct {
+	// Key is the dotted path of the offending key ("" when not known)
+	Key string
+
+	// Source is where the offending value came from
+	Source Source
+
+	// Message describes the problem
+	Message string
+}
+
+// Error renders the error as "source: key: message".
+func (e *FieldError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("%s: %s", e.Source, e.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", e.Source, e.Key, e.Message)
+}
+
+// Errors is a list of configuration problems.
+type Errors []*FieldError
+
+// Error renders one problem per line.
+func (e Errors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Validate checks every key and returns Errors locating each invalid value.
+func (c *Config) Validate() error {
+	v := &validator{config: c}
+
+	v.check("executor.exec_path", c.Executor.ExecPath != "", "must not be empty")
+	v.check("executor.timeout", c.Executor.Timeout > 0, "must be greater than zero")
+
+	v.check("retry.max_retries", c.Retry.MaxRetries >= 0 && c.Retry.MaxRetries <= maxRetries,
+		fmt.Sprintf("must be between 0 and %d", maxRetries))
+	v.check("retry.delay", c.Retry.Delay >= 0, "must not be negative")
+
+	v.check("session.mcp_rate_limit", c.Session.MCPRateLimit >= 0, "must not be negative")
+	v.check("session.mcp_rate_window", c.Session.MCPRateLimit == 0 || c.Session.MCPRateWindow > 0,
+		"must be greater than zero when session.mcp_rate_limit is set")
+
+	if c.Cache.Enabled {
+		v.check("cache.ttl", c.Cache.TTL > 0, "must be greater than zero when the cache is enabled")
+	}
+
+	v.check("smart_playlists.refresh_interval", c.SmartPlaylists.RefreshInterval >= 0, "must not be negative")
+
+	v.address("transport.address", c.Transport.Address)
+	if !c.TLS.Enabled {
+		v.check("transport.address", isLoopback(c.Transport.Address),
+			"must be a loopback address unless tls.enabled is set, as plain control requests are unauthenticated")
+	}
+	v.check("transport.dial_timeout", c.Transport.DialTimeout > 0, "must be greater than zero")
+
+	if c.TLS.Enabled {
+		v.check("tls.ca_file", c.TLS.CAFile != "", "is required when TLS is enabled")
+		v.check("tls.cert_file", c.TLS.CertFile != "", "is required when TLS is enabled")
+		v.check("tls.key_file", c.TLS.KeyFile != "", "is required when TLS is enabled")
+	}
+	v.oneOf("tls.min_version", c.TLS.MinVersion, tlsVersions)
+
+	if c.Health.Enabled {
+		v.address("health.address", c.Health.Address)
+		v.check("health.timeout", c.Health.Timeout > 0, "must be greater than zero")
+		v.check("health.cache_ttl", c.Health.CacheTTL >= 0, "must not be negative")
+	}
+
+	if c.Metrics.Enabled {
+		v.check("metrics.enabled", c.Health.Enabled, "requires health.enabled, whose server carries the endpoint")
+		v.check("metrics.path", strings.HasPrefix(c.Metrics.Path, "/"), fmt.Sprintf("%q must start with /", c.Metrics.Path))
+	}
+
+	v.oneOf("log.level", strings.ToLower(c.Log.Level), logLevels)
+	v.oneOf("log.format", strings.ToLower(c.Log.Format), logFormats)
+	v.check("log.output", c.Log.Output != "", "must not be empty")
+
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+	return nil
+}
+
+// validator collects FieldErrors located at each key's source.
+type validator struct {
+	config *Config
+	errs   Errors
+}
+
+func (v *validator) fail(key, message string) {
+	v.errs = append(v.errs, &FieldError{Key: key, Source: v.config.Source(key), Message: message})
+}
+
+func (v *validator) check(key string, ok bool, message string) {
+	if !ok {
+		v.fail(key, message)
+	}
+}
+
+func (v *validator) address(key, value string) {
+	if _, port, err := net.SplitHostPort(value); err != nil || port == "" {
+		v.fail(key, fmt.Sprintf("%q is not a host:port address", value))
+	}
+}
+
+// isLoopback reports whether a host:port address is on this machine only.
+func isLoopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (v *validator) oneOf(key, value string, allowed []string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.fail(key, fmt.Sprintf("%q is not one of %s", value, strings.Join(allowed, ", ")))
+}
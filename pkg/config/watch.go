@@ -0,0 +1,140 @@
+package config
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+)
+
+// WatcherConfig configures a Watcher.
+type WatcherConfig struct {
+	// Interval is how often the files are checked for changes
+	Interval time.Duration
+
+	// OnReload is called with the previous and new configuration and the
+	// keys that changed
+	OnReload func(previous, current *Config, changed []string)
+
+	// OnError is called when a changed file fails to load or validate; the
+	// previous configuration stays in effect
+	OnError func(err error)
+}
+
+// DefaultWatcherConfig returns the default watcher configuration.
+func DefaultWatcherConfig() *WatcherConfig {
+	return &WatcherConfig{
+		Interval: 2 * time.Second,
+	}
+}
+
+// Watcher reloads configuration when one of its files changes.
+type Watcher struct {
+	options *LoadOptions
+	config  *WatcherConfig
+
+	mu      sync.Mutex
+	current *Config
+	stamps  map[string]stamp
+}
+
+// stamp identifies one version of a file.
+type stamp struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+// NewWatcher creates a watcher for the files options loads, starting from
+// initial.
+func NewWatcher(options *LoadOptions, initial *Config, config *WatcherConfig) *Watcher {
+	if config == nil {
+		config = DefaultWatcherConfig()
+	}
+	w := &Watcher{
+		options: options,
+		config:  config,
+		current: initial,
+	}
+	w.stamps = w.snapshot()
+	return w
+}
+
+// Current returns the configuration in effect.
+func (w *Watcher) Current() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// Run checks for changes every Interval until ctx is done.
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.mu.Lock()
+			changed := !sameStamps(w.stamps, w.snapshot())
+			w.mu.Unlock()
+			if changed {
+				w.Reload()
+			}
+		}
+	}
+}
+
+// Reload loads the configuration again. On success it reports the changed
+// keys to OnReload; on failure it reports the error to OnError and keeps the
+// previous configuration. It returns the configuration in effect.
+func (w *Watcher) Reload() *Config {
+	w.mu.Lock()
+	w.stamps = w.snapshot()
+	previous := w.current
+	current, err := Load(w.options)
+	if err == nil {
+		w.current = current
+	}
+	w.mu.Unlock()
+
+	if err != nil {
+		if w.config.OnError != nil {
+			w.config.OnError(err)
+		}
+		return previous
+	}
+
+	if changed := Diff(previous, current); len(changed) > 0 && w.config.OnReload != nil {
+		w.config.OnReload(previous, current, changed)
+	}
+	return current
+}
+
+// snapshot stamps every candidate file, including ones that do not exist yet.
+func (w *Watcher) snapshot() map[string]stamp {
+	stamps := make(map[string]stamp)
+	for _, l := range w.options.layers() {
+		info, err := os.Stat(l.path)
+		if err != nil {
+			stamps[l.path] = stamp{}
+			continue
+		}
+		stamps[l.path] = stamp{exists: true, size: info.Size(), modTime: info.ModTime()}
+	}
+	return stamps
+}
+
+func sameStamps(a, b map[string]stamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, s := range a {
+		if other, ok := b[path]; !ok || !other.modTime.Equal(s.modTime) || other.size != s.size || other.exists != s.exists {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package health
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+)
+
+// CertificateCheck returns a check that loads a certificate and key pair and
+// verifies the certificate chains to the CA in caFile ("" skips the chain
+// check). It reports degraded when the certificate expires within warnBefore
+// and down once it has expired.
+func CertificateCheck(caFile, certFile, keyFile string, warnBefore time.Duration) CheckFunc {
+	return func(ctx context.Context) error {
+		pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("load certificate: %w", err)
+		}
+		cert, err := x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			return fmt.Errorf("parse certificate: %w", err)
+		}
+
+		now := time.Now()
+		if now.After(cert.NotAfter) {
+			return fmt.Errorf("certificate expired on %s", cert.NotAfter.Format(time.DateOnly))
+		}
+		if now.Before(cert.NotBefore) {
+			return fmt.Errorf("certificate is not valid until %s", cert.NotBefore.Format(time.DateOnly))
+		}
+
+		if caFile != "" {
+			pem, err := os.ReadFile(caFile)
+			if err != nil {
+				return fmt.Errorf("read CA: %w", err)
+			}
+			roots := x509.NewCertPool()
+			if !roots.AppendCertsFromPEM(pem) {
+				return fmt.Errorf("no certificates found in %s", caFile)
+			}
+			opts := x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}
+			if _, err := cert.Verify(opts); err != nil {
+				return fmt.Errorf("verify certificate: %w", err)
+			}
+		}
+
+		if cert.NotAfter.Sub(now) < warnBefore {
+			return Degraded(fmt.Errorf("certificate expires on %s", cert.NotAfter.Format(time.DateOnly)))
+		}
+		return nil
+	}
+}
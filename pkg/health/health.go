@@ -0,0 +1,306 @@
+// Package health runs named health checks for the daemon's components and
+// serves the results over HTTP.
+//
+// Each check runs with its own timeout and its result is cached for a short
+// time, so frequent probes do not hammer Music.app. A failing critical check
+// makes the daemon not ready; a failing non-critical check, or any check that
+// returns a Degraded error, only degrades it.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status is the health of a component or of the whole daemon.
+type Status string
+
+const (
+	// StatusUp means the component works
+	StatusUp Status = "up"
+
+	// StatusDegraded means the component works with reduced service
+	StatusDegraded Status = "degraded"
+
+	// StatusDown means the component does not work
+	StatusDown Status = "down"
+)
+
+// severity orders statuses from best to worst.
+func (s Status) severity() int {
+	switch s {
+	case StatusUp:
+		return 0
+	case StatusDegraded:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// CheckFunc checks one component. It returns nil when the component is up, a
+// Degraded error when it works with reduced service, and any other error when
+// it is down.
+type CheckFunc func(ctx context.Context) error
+
+// Check is a named health check.
+type Check struct {
+	// Name identifies the component, e.g. "executor"
+	Name string
+
+	// Func performs the check
+	Func CheckFunc
+
+	// Critical checks make the daemon not ready when they fail
+	Critical bool
+
+	// Timeout bounds one run of the check (0 for the registry default)
+	Timeout time.Duration
+
+	// CacheTTL is how long a result is reused (0 for the registry default)
+	CacheTTL time.Duration
+}
+
+// degradedError marks a check failure as degraded rather than down.
+type degradedError struct {
+	err error
+}
+
+func (e *degradedError) Error() string { return e.err.Error() }
+func (e *degradedError) Unwrap() error { return e.err }
+
+// Degraded wraps err to report the component as degraded rather than down.
+func Degraded(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &degradedError{err: err}
+}
+
+// IsDegraded reports whether err was wrapped with Degraded.
+func IsDegraded(err error) bool {
+	var d *degradedError
+	return errors.As(err, &d)
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	// Name is the component name
+	Name string `json:"name"`
+
+	// Status is the component's health
+	Status Status `json:"status"`
+
+	// Critical reports whether the component affects readiness
+	Critical bool `json:"critical"`
+
+	// Error describes the failure ("" when up)
+	Error string `json:"error,omitempty"`
+
+	// Duration is how long the check took
+	Duration time.Duration `json:"duration_ns"`
+
+	// CheckedAt is when the check ran
+	CheckedAt time.Time `json:"checked_at"`
+
+	// Cached reports whether the result was reused from an earlier run
+	Cached bool `json:"cached"`
+}
+
+// Report is the combined result of every check.
+type Report struct {
+	// Status is the worst critical status, or degraded when any
+	// non-critical check is not up
+	Status Status `json:"status"`
+
+	// Version is the daemon's version
+	Version string `json:"version,omitempty"`
+
+	// Components holds one result per check, sorted by name
+	Components []Result `json:"components"`
+}
+
+// Ready reports whether the daemon can serve requests.
+func (r *Report) Ready() bool {
+	return r.Status != StatusDown
+}
+
+// RegistryConfig configures a Registry.
+type RegistryConfig struct {
+	// DefaultTimeout bounds checks that do not set their own Timeout
+	DefaultTimeout time.Duration
+
+	// DefaultCacheTTL applies to checks that do not set their own CacheTTL
+	DefaultCacheTTL time.Duration
+
+	// Version is reported in every Report
+	Version string
+}
+
+// DefaultRegistryConfig returns the default registry configuration.
+func DefaultRegistryConfig() *RegistryConfig {
+	return &RegistryConfig{
+		DefaultTimeout:  2 * time.Second,
+		DefaultCacheTTL: 5 * time.Second,
+	}
+}
+
+// Registry holds the health checks of one process.
+type Registry struct {
+	config *RegistryConfig
+
+	mu      sync.RWMutex
+	entries map[string]*entry
+}
+
+// entry is a registered check and its cached result. The entry lock is held
+// while the check runs, so concurrent callers share one run.
+type entry struct {
+	check Check
+
+	mu     sync.Mutex
+	result *Result
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry(config *RegistryConfig) *Registry {
+	if config == nil {
+		config = DefaultRegistryConfig()
+	}
+	return &Registry{
+		config:  config,
+		entries: make(map[string]*entry),
+	}
+}
+
+// Register adds a check. Names must be unique.
+func (r *Registry) Register(check Check) error {
+	if check.Name == "" || check.Func == nil {
+		return fmt.Errorf("health check needs a name and a function")
+	}
+	if check.Timeout <= 0 {
+		check.Timeout = r.config.DefaultTimeout
+	}
+	if check.CacheTTL <= 0 {
+		check.CacheTTL = r.config.DefaultCacheTTL
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.entries[check.Name]; exists {
+		return fmt.Errorf("health check %q is already registered", check.Name)
+	}
+	r.entries[check.Name] = &entry{check: check}
+	return nil
+}
+
+// Unregister removes the named check.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.entries, name)
+}
+
+// Names returns the registered check names in order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.entries))
+	for name := range r.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Check runs every check concurrently, reusing fresh cached results, and
+// combines them into a Report.
+func (r *Registry) Check(ctx context.Context) *Report {
+	r.mu.RLock()
+	entries := make([]*entry, 0, len(r.entries))
+	for _, e := range r.entries {
+		entries = append(entries, e)
+	}
+	r.mu.RUnlock()
+
+	results := make([]Result, len(entries))
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func(i int, e *entry) {
+			defer wg.Done()
+			results[i] = e.run(ctx)
+		}(i, e)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	report := &Report{Status: StatusUp, Version: r.config.Version, Components: results}
+	for _, result := range results {
+		status := result.Status
+		if !result.Critical && status == StatusDown {
+			status = StatusDegraded
+		}
+		if status.severity() > report.Status.severity() {
+			report.Status = status
+		}
+	}
+	return report
+}
+
+// run returns the cached result while it is fresh, or runs the check.
+func (e *entry) run(ctx context.Context) Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.result != nil && time.Since(e.result.CheckedAt) < e.check.CacheTTL {
+		cached := *e.result
+		cached.Cached = true
+		return cached
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.check.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := runCheck(ctx, e.check.Func)
+
+	result := Result{
+		Name:      e.check.Name,
+		Status:    StatusUp,
+		Critical:  e.check.Critical,
+		Duration:  time.Since(start),
+		CheckedAt: start,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		if IsDegraded(err) {
+			result.Status = StatusDegraded
+		}
+		result.Error = err.Error()
+	}
+
+	e.result = &result
+	return result
+}
+
+// runCheck runs fn, returning when it finishes or ctx expires, whichever
+// comes first. A check that ignores its context is left to finish on its own.
+func runCheck(ctx context.Context, fn CheckFunc) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("timed out: %w", ctx.Err())
+	}
+}
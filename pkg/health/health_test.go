@@ -0,0 +1,264 @@
+package health
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func up(context.Context) error   { return nil }
+func down(context.Context) error { return errors.New("broken") }
+
+func TestRegistryStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks []Check
+		want   Status
+	}{
+		{"empty", nil, StatusUp},
+		{"all up", []Check{{Name: "a", Func: up, Critical: true}, {Name: "b", Func: up}}, StatusUp},
+		{"critical down", []Check{{Name: "a", Func: down, Critical: true}, {Name: "b", Func: up}}, StatusDown},
+		{"optional down", []Check{{Name: "a", Func: up, Critical: true}, {Name: "b", Func: down}}, StatusDegraded},
+		{"critical degraded", []Check{{Name: "a", Critical: true, Func: func(context.Context) error {
+			return Degraded(errors.New("slow"))
+		}}}, StatusDegraded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry(nil)
+			for _, check := range tt.checks {
+				if err := registry.Register(check); err != nil {
+					t.Fatal(err)
+				}
+			}
+			report := registry.Check(context.Background())
+			if report.Status != tt.want {
+				t.Errorf("expected status %s, got %s", tt.want, report.Status)
+			}
+			if len(report.Components) != len(tt.checks) {
+				t.Errorf("expected %d components, got %d", len(tt.checks), len(report.Components))
+			}
+		})
+	}
+}
+
+func TestRegistryRejectsDuplicates(t *testing.T) {
+	registry := NewRegistry(nil)
+	if err := registry.Register(Check{Name: "a", Func: up}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register(Check{Name: "a", Func: up}); err == nil {
+		t.Error("expected an error for a duplicate name")
+	}
+	if err := registry.Register(Check{Name: "b"}); err == nil {
+		t.Error("expected an error for a missing function")
+	}
+
+	registry.Unregister("a")
+	if names := registry.Names(); len(names) != 0 {
+		t.Errorf("expected no checks, got %v", names)
+	}
+}
+
+func TestRegistryTimeout(t *testing.T) {
+	registry := NewRegistry(nil)
+	_ = registry.Register(Check{Name: "slow", Critical: true, Timeout: 20 * time.Millisecond, Func: func(context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}})
+
+	start := time.Now()
+	report := registry.Check(context.Background())
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("check was not bounded by its timeout: took %v", elapsed)
+	}
+	if report.Status != StatusDown || !strings.Contains(report.Components[0].Error, "timed out") {
+		t.Errorf("expected a timed-out check to be down, got %+v", report.Components[0])
+	}
+}
+
+func TestRegistryCaching(t *testing.T) {
+	var runs atomic.Int32
+	registry := NewRegistry(nil)
+	_ = registry.Register(Check{Name: "counted", CacheTTL: time.Hour, Func: func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}})
+	_ = registry.Register(Check{Name: "fresh", CacheTTL: time.Nanosecond, Func: up})
+
+	first := registry.Check(context.Background())
+	second := registry.Check(context.Background())
+
+	if runs.Load() != 1 {
+		t.Errorf("expected the check to run once, ran %d times", runs.Load())
+	}
+	if first.Components[0].Cached || !second.Components[0].Cached {
+		t.Error("expected only the second result to be cached")
+	}
+	if second.Components[1].Cached {
+		t.Error("expected an expired result to be checked again")
+	}
+}
+
+func TestServerEndpoints(t *testing.T) {
+	healthy := atomic.Bool{}
+	healthy.Store(true)
+
+	registry := NewRegistry(&RegistryConfig{DefaultTimeout: time.Second, DefaultCacheTTL: time.Nanosecond, Version: "1.2.3"})
+	_ = registry.Register(Check{Name: "music_app", Critical: true, Func: func(context.Context) error {
+		if healthy.Load() {
+			return nil
+		}
+		return errors.New("not running")
+	}})
+
+	server := httptest.NewServer(NewServer(registry, nil).Handler())
+	defer server.Close()
+	address := strings.TrimPrefix(server.URL, "http://")
+
+	resp, err := http.Get(server.URL + LivenessPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected liveness 200, got %d", resp.StatusCode)
+	}
+
+	report, err := Fetch(context.Background(), address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Ready() || report.Version != "1.2.3" || report.Components[0].Name != "music_app" {
+		t.Errorf("unexpected report %+v", report)
+	}
+
+	healthy.Store(false)
+	resp, err = http.Get(server.URL + ReadinessPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected readiness 503, got %d", resp.StatusCode)
+	}
+
+	report, err = Fetch(context.Background(), address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Ready() || report.Components[0].Error != "not running" {
+		t.Errorf("expected a down report, got %+v", report)
+	}
+}
+
+func TestServerRunStops(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- NewServer(NewRegistry(nil), nil).Serve(ctx, listener) }()
+
+	if _, err := Fetch(context.Background(), listener.Addr().String()); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected a clean shutdown, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("server did not stop")
+	}
+}
+
+func TestCertificateCheck(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		notBefore time.Time
+		notAfter  time.Time
+		want      Status
+	}{
+		{"valid", now.Add(-time.Hour), now.Add(90 * 24 * time.Hour), StatusUp},
+		{"expiring", now.Add(-time.Hour), now.Add(24 * time.Hour), StatusDegraded},
+		{"expired", now.Add(-48 * time.Hour), now.Add(-24 * time.Hour), StatusDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			certFile, keyFile := writeCertificate(t, dir, tt.name, tt.notBefore, tt.notAfter)
+			registry := NewRegistry(nil)
+			_ = registry.Register(Check{
+				Name:     "certificates",
+				Critical: true,
+				Func:     CertificateCheck(certFile, certFile, keyFile, 30*24*time.Hour),
+			})
+			if got := registry.Check(context.Background()).Status; got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	err := CertificateCheck("", filepath.Join(dir, "missing.pem"), filepath.Join(dir, "missing.key"), 0)(context.Background())
+	if err == nil {
+		t.Error("expected an error for missing files")
+	}
+}
+
+// writeCertificate writes a self-signed CA certificate and its key.
+func writeCertificate(t *testing.T, dir, name string, notBefore, notAfter time.Time) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "maestrod"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, name+".pem")
+	keyFile := filepath.Join(dir, name+".key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
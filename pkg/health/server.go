@@ -0,0 +1,162 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Endpoint paths.
+const (
+	// LivenessPath reports whether the process is alive
+	LivenessPath = "/healthz"
+
+	// ReadinessPath runs the checks and reports whether the daemon is ready
+	ReadinessPath = "/readyz"
+)
+
+// ServerConfig configures the health HTTP server.
+type ServerConfig struct {
+	// Address is the host:port to listen on; keep it on a loopback address
+	Address string
+
+	// ShutdownTimeout bounds graceful shutdown
+	ShutdownTimeout time.Duration
+}
+
+// DefaultServerConfig returns the default server configuration.
+func DefaultServerConfig() *ServerConfig {
+	return &ServerConfig{
+		Address:         "127.0.0.1:7434",
+		ShutdownTimeout: 5 * time.Second,
+	}
+}
+
+// Server serves a Registry over HTTP.
+type Server struct {
+	registry *Registry
+	config   *ServerConfig
+	extra    map[string]http.Handler
+}
+
+// NewServer creates a health server for registry.
+func NewServer(registry *Registry, config *ServerConfig) *Server {
+	if config == nil {
+		config = DefaultServerConfig()
+	}
+	return &Server{registry: registry, config: config, extra: make(map[string]http.Handler)}
+}
+
+// Handle serves another local GET endpoint, such as metrics, on the same
+// listener. It must be called before Run.
+func (s *Server) Handle(path string, handler http.Handler) {
+	s.HandleMethod(http.MethodGet, path, handler)
+}
+
+// HandleMethod serves another local endpoint for a given method on the
+// same listener. It must be called before Run.
+func (s *Server) HandleMethod(method, path string, handler http.Handler) {
+	s.extra[method+" "+path] = handler
+}
+
+// Handler returns the HTTP handler for the health endpoints and any extra
+// endpoints.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+LivenessPath, s.handleLiveness)
+	mux.HandleFunc("GET "+ReadinessPath, s.handleReadiness)
+	for pattern, handler := range s.extra {
+		mux.Handle(pattern, handler)
+	}
+	return mux
+}
+
+// handleLiveness answers without running any checks: a live process can
+// always respond.
+func (s *Server) handleLiveness(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, &Report{Status: StatusUp, Version: s.registry.config.Version, Components: []Result{}})
+}
+
+// handleReadiness runs the checks and answers 503 when the daemon is down.
+func (s *Server) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	report := s.registry.Check(r.Context())
+	code := http.StatusOK
+	if !report.Ready() {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, report)
+}
+
+func writeJSON(w http.ResponseWriter, code int, report *Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// Run listens on the configured address and serves until ctx is done.
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.config.Address)
+	if err != nil {
+		return fmt.Errorf("health server: %w", err)
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve serves on listener until ctx is done.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	server := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
+
+// Fetch asks the health server at address for a readiness report. A report
+// whose status is down is returned without an error; an error means the
+// server could not be reached or answered something unexpected.
+func Fetch(ctx context.Context, address string) (*Report, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+address+ReadinessPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+		return nil, fmt.Errorf("unexpected response from %s: %s", address, resp.Status)
+	}
+
+	var report Report
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, fmt.Errorf("invalid health report from %s: %w", address, err)
+	}
+	return &report, nil
+}
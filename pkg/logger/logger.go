@@ -132,13 +132,19 @@ func NewLogger(config *Config) (Logger, error) {
 // GetGlobal returns the global logger instance
 func GetGlobal() Logger {
 	globalMutex.RLock()
-	defer globalMutex.RUnlock()
+	logger := globalLogger
+	globalMutex.RUnlock()
 
-	if globalLogger == nil {
-		// Initialize with default config if not initialized
-		_ = InitializeDefault()
+	if logger != nil {
+		return logger
 	}
 
+	// Initialize with default config if not initialized. Initialize takes the
+	// write lock, so the read lock must be released first.
+	_ = InitializeDefault()
+
+	globalMutex.RLock()
+	defer globalMutex.RUnlock()
 	return globalLogger
 }
 
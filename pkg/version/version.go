@@ -0,0 +1,19 @@
+// Package version holds build information injected at link time by GoReleaser.
+package version
+
+// Build information. These are overridden with -ldflags "-X ..." in release builds.
+var (
+	// Version is the semantic version of the build
+	Version = "dev"
+
+	// Commit is the git commit the build was made from
+	Commit = "none"
+
+	// Date is when the build was made
+	Date = "unknown"
+)
+
+// String returns a one-line description of the build.
+func String() string {
+	return Version + " (" + Commit + ", " + Date + ")"
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// negativeValue matches arguments such as "-5", "-10s", "-1:30" or "-10%"
+// that are values rather than shorthand flags.
+var negativeValue = regexp.MustCompile(`^-[0-9.]`)
+
+// acceptNegativeValues lets cmd take negative numbers as arguments, as in
+// "maestro volume -5", which flag parsing would otherwise reject as an
+// unknown shorthand flag. Flag parsing is taken over from cobra: negative
+// values are set aside as arguments, the rest are validated with cmd's Args
+// and everything else is parsed as usual before the root command's pre-run
+// hook sees the flags. Completion of flag
+// values, which cobra leaves to such commands, is handled too.
+// Subcommands of cmd are unaffected. It must be called after cmd's Args,
+// RunE and ValidArgsFunction are set.
+func acceptNegativeValues(cmd *cobra.Command) {
+	validate, run, complete := cmd.Args, cmd.RunE, cmd.ValidArgsFunction
+	var positional []string
+
+	cmd.DisableFlagParsing = true
+	cmd.Args = func(c *cobra.Command, args []string) error {
+		if validate == nil {
+			return nil
+		}
+		flags := c.Flags()
+		flags.AddFlagSet(c.InheritedFlags())
+		_, positional := splitArgs(flags, args)
+		return validate(c, positional)
+	}
+	cmd.PersistentPreRunE = func(c *cobra.Command, args []string) error {
+		if c == cmd {
+			var err error
+			if positional, err = parseWithNegativeValues(c, args); err != nil {
+				return err
+			}
+			args = positional
+		}
+
+		if root := c.Root(); root != cmd && root.PersistentPreRunE != nil {
+			return root.PersistentPreRunE(c, args)
+		}
+		return nil
+	}
+	cmd.RunE = func(c *cobra.Command, _ []string) error {
+		return run(c, positional)
+	}
+	cmd.ValidArgsFunction = func(c *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		flags := c.Flags()
+		flags.AddFlagSet(c.InheritedFlags())
+
+		if n := len(args); n > 0 {
+			if flag := valueFlag(flags, args[n-1]); flag != nil {
+				if completeValue, ok := c.GetFlagCompletionFunc(flag.Name); ok {
+					return completeValue(c, args, toComplete)
+				}
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		}
+		// Cobra completes flag names itself
+		if complete == nil || strings.HasPrefix(toComplete, "-") && !negativeValue.MatchString(toComplete) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		_, positional := splitArgs(flags, args)
+		return complete(c, positional, toComplete)
+	}
+}
+
+// parseWithNegativeValues parses c's flags from args and returns the
+// remaining arguments, negative values included, in their original order.
+func parseWithNegativeValues(c *cobra.Command, args []string) ([]string, error) {
+	flags := c.Flags()
+	flags.AddFlagSet(c.InheritedFlags())
+
+	flagArgs, positional := splitArgs(flags, args)
+	if err := flags.Parse(flagArgs); err != nil {
+		return nil, c.FlagErrorFunc()(c, err)
+	}
+	if help, _ := flags.GetBool("help"); help {
+		return nil, pflag.ErrHelp
+	}
+	return positional, nil
+}
+
+// splitArgs separates flag arguments, with their values, from the others.
+// Negative values count as positional arguments.
+func splitArgs(flags *pflag.FlagSet, args []string) (flagArgs, positional []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			positional = append(positional, args[i+1:]...)
+			i = len(args)
+		case negativeValue.MatchString(arg) || !strings.HasPrefix(arg, "-") || arg == "-":
+			positional = append(positional, arg)
+		default:
+			flagArgs = append(flagArgs, arg)
+			if valueFlag(flags, arg) != nil && i+1 < len(args) {
+				i++
+				flagArgs = append(flagArgs, args[i])
+			}
+		}
+	}
+	return flagArgs, positional
+}
+
+// valueFlag returns the flag named by an argument such as "--config" or
+// "-o" when it is followed by a separate value argument.
+func valueFlag(flags *pflag.FlagSet, arg string) *pflag.Flag {
+	if strings.Contains(arg, "=") {
+		return nil
+	}
+
+	var flag *pflag.Flag
+	if name, ok := strings.CutPrefix(arg, "--"); ok {
+		flag = flags.Lookup(name)
+	} else if len(arg) == 2 {
+		flag = flags.ShorthandLookup(arg[1:])
+	}
+	if flag == nil || flag.NoOptDefVal != "" {
+		return nil
+	}
+	return flag
+}
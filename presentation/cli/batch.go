@@ -0,0 +1,417 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/madstone-tech/maestro/application/playback"
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/spf13/cobra"
+)
+
+// Statuses of a batch line.
+const (
+	batchOK      = "ok"
+	batchFailed  = "failed"
+	batchSkipped = "skipped"
+	batchInvalid = "invalid"
+)
+
+// coalescedCommands are the commands whose player operations a batch holds
+// back and sends to the player together. Reads, such as the current volume
+// for "volume +10", send the held back operations first. "volume mute" and
+// "volume unmute" are left out: they update the mute file, which must only
+// change once the volume has.
+var coalescedCommands = map[string]bool{
+	"maestro pause":    true,
+	"maestro stop":     true,
+	"maestro resume":   true,
+	"maestro next":     true,
+	"maestro previous": true,
+	"maestro seek":     true,
+	"maestro volume":   true,
+	"maestro status":   true,
+}
+
+// batchLine is one command of a batch and what became of it.
+type batchLine struct {
+	number int
+	text   string
+	args   []string
+	path   string
+	status string
+	err    error
+	stdout bytes.Buffer
+}
+
+// NewBatchCommand creates the batch command
+func NewBatchCommand(ctx *CommandContext, newRoot func(*CommandContext) *cobra.Command) *cobra.Command {
+	var file string
+	var stopOnError, continueOnError bool
+
+	cmd := &cobra.Command{
+		Use:   "batch",
+		Args:  cobra.NoArgs,
+		Short: "Run maestro commands from a file or stdin",
+		Long: `Run maestro commands, one per line, from a file or stdin. Blank lines and
+lines starting with # are ignored, and a leading "maestro" is optional.
+
+Every line is checked before any runs: a batch with an unknown command or a
+bad flag or argument runs nothing. Player commands on adjacent lines, such
+as pause, next, seek and volume, are sent to the player together.
+
+By default the batch stops at the first failed command and skips the rest;
+--continue runs them anyway. The table format prints each command's output
+as usual. The other formats print one result per line with its status (ok,
+failed, skipped or invalid), its output and its error. Output flags on the
+lines themselves are ignored.`,
+		Example: `  maestro batch -f evening.txt
+  printf 'pause\nvolume 30\nnext\n' | maestro batch --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing batch command")
+
+			if stopOnError && continueOnError {
+				err := music.NewDomainError(music.ErrInvalidOperation, "--stop-on-error conflicts with --continue")
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			in := cmd.InOrStdin()
+			if file != "-" {
+				f, err := os.Open(file)
+				if err != nil {
+					err = music.NewDomainErrorWithCause(music.ErrInvalidOperation, "cannot read batch file", err).
+						WithContext("file", file)
+					ctx.OutputFormatter.Error(err)
+					return err
+				}
+				defer func() { _ = f.Close() }()
+				in = f
+			}
+
+			lines, err := readBatch(in)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			if err := validateBatch(ctx, newRoot, lines, file == "-"); err != nil {
+				printBatchResults(ctx.OutputFormatter, lines)
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			err = runBatch(ctx, newRoot, lines, continueOnError)
+			printBatchResults(ctx.OutputFormatter, lines)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "-", "Read commands from this file (- for stdin)")
+	cmd.Flags().BoolVar(&stopOnError, "stop-on-error", false, "Skip the commands after a failed one (the default)")
+	cmd.Flags().BoolVar(&continueOnError, "continue", false, "Run the commands after a failed one")
+
+	return cmd
+}
+
+// readBatch reads the command lines of a batch.
+func readBatch(in io.Reader) ([]*batchLine, error) {
+	var lines []*batchLine
+	scanner := bufio.NewScanner(in)
+	for number := 1; scanner.Scan(); number++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		lines = append(lines, &batchLine{number: number, text: text})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, music.NewDomainErrorWithCause(music.ErrInvalidOperation, "cannot read batch", err)
+	}
+	return lines, nil
+}
+
+// validateBatch parses and checks every line. When one is invalid, it marks
+// the others skipped and returns an error.
+func validateBatch(ctx *CommandContext, newRoot func(*CommandContext) *cobra.Command, lines []*batchLine, fromStdin bool) error {
+	invalid := 0
+	var first error
+	for _, line := range lines {
+		if line.err = validateBatchLine(ctx, newRoot, line, fromStdin); line.err != nil {
+			line.status = batchInvalid
+			invalid++
+			if first == nil {
+				first = line.err
+			}
+		}
+	}
+	if invalid == 0 {
+		return nil
+	}
+
+	for _, line := range lines {
+		if line.status == "" {
+			line.status = batchSkipped
+		}
+	}
+	return music.NewDomainErrorWithCause(music.ErrInvalidOperation,
+		fmt.Sprintf("%d of %d batch commands are invalid; nothing was run", invalid, len(lines)), first).
+		WithContext("invalid", invalid)
+}
+
+// validateBatchLine parses a line and checks its command, flags and
+// arguments without running it.
+func validateBatchLine(ctx *CommandContext, newRoot func(*CommandContext) *cobra.Command, line *batchLine, fromStdin bool) error {
+	args, err := splitLine(line.text)
+	if err != nil {
+		return usageError(err)
+	}
+	if len(args) > 0 && args[0] == "maestro" {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return music.NewDomainError(music.ErrInvalidOperation, "no command")
+	}
+	line.args = args
+
+	root := newRoot(ctx)
+	cmd, rest, err := root.Find(args)
+	if err != nil {
+		return usageError(err)
+	}
+	line.path = cmd.CommandPath()
+
+	switch {
+	case cmd == root:
+		return music.NewDomainError(music.ErrInvalidOperation, "no command")
+	case cmd.Name() == "batch" || cmd.Name() == "shell" || cmd.Name() == "completion":
+		return music.NewDomainError(music.ErrInvalidOperation, cmd.Name()+" cannot run in a batch")
+	case !cmd.Runnable() && len(rest) > 0 && !strings.HasPrefix(rest[0], "-"):
+		return music.NewDomainError(music.ErrInvalidOperation,
+			fmt.Sprintf("unknown command %q for %q", rest[0], line.path))
+	case !cmd.Runnable():
+		return music.NewDomainError(music.ErrInvalidOperation, line.path+" needs a subcommand")
+	}
+
+	// Commands that parse their own flags validate the raw arguments
+	positional, validated := rest, rest
+	if cmd.DisableFlagParsing {
+		if positional, err = parseWithNegativeValues(cmd, rest); err != nil {
+			return usageError(err)
+		}
+	} else {
+		if err := cmd.ParseFlags(rest); err != nil {
+			return usageError(err)
+		}
+		positional, validated = cmd.Flags().Args(), cmd.Flags().Args()
+	}
+	if err := cmd.ValidateArgs(validated); err != nil {
+		return usageError(err)
+	}
+	if err := cmd.ValidateRequiredFlags(); err != nil {
+		return usageError(err)
+	}
+	if err := cmd.ValidateFlagGroups(); err != nil {
+		return usageError(err)
+	}
+
+	for _, arg := range positional {
+		if arg == "-" && fromStdin {
+			return music.NewDomainError(music.ErrInvalidOperation, "cannot read stdin: it holds the batch")
+		}
+	}
+	return nil
+}
+
+// runBatch runs validated lines in order over ctx. Coalesced commands go
+// through a Deferred player so that adjacent ones reach the player in one
+// round trip.
+func runBatch(ctx *CommandContext, newRoot func(*CommandContext) *cobra.Command, lines []*batchLine, continueOnError bool) error {
+	formatter, player := ctx.OutputFormatter, ctx.PlayerRepo
+	defer func() {
+		ctx.OutputFormatter, ctx.PlayerRepo = formatter, player
+	}()
+
+	deferred := playback.NewDeferred(player, &playback.DeferredConfig{ContinueOnError: continueOnError})
+	_, table := formatter.renderer.(tableRenderer)
+	var group []*batchLine
+
+	flush := func() {
+		_ = deferred.Flush(ctx.Context)
+		for _, line := range group {
+			if line.status == "" {
+				switch {
+				case deferred.Err(line.number) != nil:
+					line.status, line.err = batchFailed, deferred.Err(line.number)
+				case deferred.Skipped(line.number):
+					line.status = batchSkipped
+				default:
+					line.status = batchOK
+				}
+			}
+			if table {
+				emitBatchLine(formatter, line)
+			}
+		}
+		group = nil
+	}
+
+	halted := false
+	for _, line := range lines {
+		if halted {
+			line.status = batchSkipped
+			continue
+		}
+
+		coalesced := coalescedCommands[line.path]
+		ctx.PlayerRepo = player
+		if coalesced {
+			deferred.Begin(line.number)
+			ctx.PlayerRepo = deferred
+		} else {
+			flush()
+			if deferred.Halted() {
+				halted = true
+				line.status = batchSkipped
+				continue
+			}
+		}
+
+		err := runBatchLine(ctx, newRoot, line, formatter, table)
+		switch {
+		case err == nil:
+		case coalesced && deferred.Halted() && errors.Is(err, music.ErrInterrupted):
+			line.status = batchSkipped
+		default:
+			line.status, line.err = batchFailed, err
+		}
+		group = append(group, line)
+
+		if !coalesced || line.status != "" {
+			flush()
+		}
+		if !continueOnError && (line.status == batchFailed || deferred.Halted()) {
+			halted = true
+		}
+	}
+	flush()
+
+	return batchError(lines)
+}
+
+// runBatchLine runs one line in a fresh command tree, capturing its output.
+// Structured output is captured as NDJSON so that it can be embedded in the
+// line's result.
+func runBatchLine(ctx *CommandContext, newRoot func(*CommandContext) *cobra.Command, line *batchLine, formatter *OutputFormatter, table bool) error {
+	var renderer Renderer = tableRenderer{}
+	if !table {
+		renderer = ndjsonRenderer{}
+	}
+
+	root := newRoot(ctx)
+	preRun := root.PersistentPreRunE
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := preRun(cmd, args); err != nil {
+			return err
+		}
+		ctx.OutputFormatter = NewOutputFormatter(renderer, formatter.verbose)
+		ctx.OutputFormatter.SetWriter(&line.stdout)
+		ctx.OutputFormatter.SetErrorWriter(io.Discard)
+		return nil
+	}
+	root.SetArgs(line.args)
+	root.SetOut(&line.stdout)
+	root.SetErr(io.Discard)
+	root.SetIn(strings.NewReader(""))
+
+	defer func() { ctx.OutputFormatter = formatter }()
+	return root.ExecuteContext(ctx.Context)
+}
+
+// emitBatchLine prints a finished line in the table format: its output if
+// it succeeded, its error if it failed or is invalid.
+func emitBatchLine(formatter *OutputFormatter, line *batchLine) {
+	switch line.status {
+	case batchOK:
+		_, _ = formatter.writer.Write(line.stdout.Bytes())
+	case batchFailed, batchInvalid:
+		formatter.Error(fmt.Errorf("line %d: %w", line.number, line.err))
+	}
+}
+
+// batchError summarizes the failed and skipped lines, or returns nil when
+// every line succeeded.
+func batchError(lines []*batchLine) error {
+	failed, skipped := 0, 0
+	var first error
+	for _, line := range lines {
+		switch line.status {
+		case batchFailed:
+			failed++
+			if first == nil {
+				first = line.err
+			}
+		case batchSkipped:
+			skipped++
+		}
+	}
+	if failed == 0 {
+		return nil
+	}
+	return music.NewDomainErrorWithCause(music.ErrOperationFailed,
+		fmt.Sprintf("%d of %d batch commands failed", failed, len(lines)), first).
+		WithContext("failed", failed).
+		WithContext("skipped", skipped)
+}
+
+// printBatchResults prints the result of each line. In the table format,
+// where lines print their own output as they finish, it only prints the
+// invalid lines.
+func printBatchResults(formatter *OutputFormatter, lines []*batchLine) {
+	if _, table := formatter.renderer.(tableRenderer); table {
+		for _, line := range lines {
+			if line.status == batchInvalid {
+				emitBatchLine(formatter, line)
+			}
+		}
+		return
+	}
+
+	results := make(BatchResultsView, len(lines))
+	for i, line := range lines {
+		results[i] = newBatchResultView(line)
+	}
+	formatter.PrintBatchResults(results)
+}
+
+// newBatchResultView builds the result of a line, embedding each NDJSON
+// document it printed.
+func newBatchResultView(line *batchLine) BatchResultView {
+	result := BatchResultView{
+		Line:    line.number,
+		Command: line.text,
+		Status:  line.status,
+		Output:  []json.RawMessage{},
+	}
+	if line.status == batchOK {
+		for _, doc := range bytes.Split(line.stdout.Bytes(), []byte("\n")) {
+			if len(bytes.TrimSpace(doc)) > 0 && json.Valid(doc) {
+				result.Output = append(result.Output, json.RawMessage(doc))
+			}
+		}
+	}
+	if line.err != nil {
+		result.Error = newErrorView(line.err)
+	}
+	return result
+}
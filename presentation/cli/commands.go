@@ -2,9 +2,9 @@ package cli
 
 import (
 	"context"
-	"strconv"
 
 	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/pkg/config"
 	"github.com/spf13/cobra"
 )
 
@@ -12,50 +12,33 @@ import (
 type CommandContext struct {
 	Context         context.Context
 	PlayerRepo      music.PlayerRepository
+	LibraryRepo     music.LibraryRepository
+	QueueRepo       music.QueueRepository
+	PlaylistRepo    music.PlaylistRepository
 	OutputFormatter *OutputFormatter
-}
 
-// NewPlayCommand creates the play command
-func NewPlayCommand(ctx *CommandContext) *cobra.Command {
-	return &cobra.Command{
-		Use:   "play",
-		Short: "Start or resume playback",
-		Long:  "Start or resume music playback. If music is paused, it will resume. If stopped, it will start playing.",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx.OutputFormatter.Debug("Executing play command")
+	// RatingRepo rates tracks and marks them loved or disliked
+	RatingRepo music.RatingRepository
 
-			// Check current state to determine if we should play or resume
-			player, err := ctx.PlayerRepo.GetCurrentState(ctx.Context)
-			if err != nil {
-				ctx.OutputFormatter.Error(err)
-				return err
-			}
+	// SmartPlaylistRepo keeps the rules of maestro's smart playlists
+	SmartPlaylistRepo music.SmartPlaylistRepository
 
-			if player.IsPaused() {
-				err = ctx.PlayerRepo.Resume(ctx.Context)
-				if err != nil {
-					ctx.OutputFormatter.Error(err)
-					return err
-				}
-				ctx.OutputFormatter.Success("Playback resumed")
-			} else {
-				err = ctx.PlayerRepo.Resume(ctx.Context) // In AppleScript, both play and resume use "play"
-				if err != nil {
-					ctx.OutputFormatter.Error(err)
-					return err
-				}
-				ctx.OutputFormatter.Success("Playback started")
-			}
+	// HistoryRepo reads the play history maestrod records
+	HistoryRepo music.HistoryRepository
 
-			return nil
-		},
-	}
+	// Config is the loaded configuration; the root command loads it before
+	// any command runs unless it is already set
+	Config *config.Config
+
+	// Connect sets up the repositories from the loaded configuration
+	Connect func(*config.Config) error
 }
 
 // NewPauseCommand creates the pause command
 func NewPauseCommand(ctx *CommandContext) *cobra.Command {
 	return &cobra.Command{
 		Use:   "pause",
+		Args:  cobra.NoArgs,
 		Short: "Pause playback",
 		Long:  "Pause the currently playing music. Playback can be resumed with the play command.",
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -77,6 +60,7 @@ func NewPauseCommand(ctx *CommandContext) *cobra.Command {
 func NewStopCommand(ctx *CommandContext) *cobra.Command {
 	return &cobra.Command{
 		Use:   "stop",
+		Args:  cobra.NoArgs,
 		Short: "Stop playback",
 		Long:  "Stop music playback completely. This will clear the current track and reset the position.",
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -98,18 +82,19 @@ func NewStopCommand(ctx *CommandContext) *cobra.Command {
 func NewResumeCommand(ctx *CommandContext) *cobra.Command {
 	return &cobra.Command{
 		Use:   "resume",
+		Args:  cobra.NoArgs,
 		Short: "Resume paused playback",
-		Long:  "Resume playback if it is currently paused. This is an alias for the play command.",
+		Long:  "Resume paused playback or, when stopped, start playing Music.app's current selection. This is an alias for the play command without a target.",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx.OutputFormatter.Debug("Executing resume command")
 
-			err := ctx.PlayerRepo.Resume(ctx.Context)
+			err := ctx.PlayerRepo.Start(ctx.Context)
 			if err != nil {
 				ctx.OutputFormatter.Error(err)
 				return err
 			}
 
-			ctx.OutputFormatter.Success("Playback resumed")
+			ctx.OutputFormatter.Success("Playback started")
 			return nil
 		},
 	}
@@ -119,6 +104,7 @@ func NewResumeCommand(ctx *CommandContext) *cobra.Command {
 func NewNextCommand(ctx *CommandContext) *cobra.Command {
 	return &cobra.Command{
 		Use:   "next",
+		Args:  cobra.NoArgs,
 		Short: "Skip to next track",
 		Long:  "Skip to the next track in the current playlist or queue.",
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -140,6 +126,7 @@ func NewNextCommand(ctx *CommandContext) *cobra.Command {
 func NewPreviousCommand(ctx *CommandContext) *cobra.Command {
 	return &cobra.Command{
 		Use:     "previous",
+		Args:    cobra.NoArgs,
 		Aliases: []string{"prev"},
 		Short:   "Skip to previous track",
 		Long:    "Skip to the previous track in the current playlist or queue.",
@@ -158,67 +145,11 @@ func NewPreviousCommand(ctx *CommandContext) *cobra.Command {
 	}
 }
 
-// NewVolumeCommand creates the volume command
-func NewVolumeCommand(ctx *CommandContext) *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "volume [level]",
-		Short: "Get or set volume",
-		Long: `Get the current volume level or set it to a specific value.
-Volume should be a number between 0 and 100.
-
-Examples:
-  maestro volume        # Show current volume
-  maestro volume 50     # Set volume to 50%
-  maestro volume 0      # Mute
-  maestro volume 100    # Maximum volume`,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx.OutputFormatter.Debug("Executing volume command")
-
-			// If no arguments, show current volume
-			if len(args) == 0 {
-				player, err := ctx.PlayerRepo.GetCurrentState(ctx.Context)
-				if err != nil {
-					ctx.OutputFormatter.Error(err)
-					return err
-				}
-				ctx.OutputFormatter.PrintVolume(player.Volume)
-				return nil
-			}
-
-			// Parse volume level
-			levelStr := args[0]
-			level, err := strconv.Atoi(levelStr)
-			if err != nil {
-				ctx.OutputFormatter.Error(music.NewDomainError(music.ErrInvalidVolume, "volume must be a number between 0 and 100"))
-				return err
-			}
-
-			// Validate and create volume
-			volume := music.NewVolume(level)
-			if !volume.IsValid() {
-				ctx.OutputFormatter.Error(music.NewDomainError(music.ErrInvalidVolume, "volume must be between 0 and 100"))
-				return music.NewDomainError(music.ErrInvalidVolume, "volume must be between 0 and 100")
-			}
-
-			// Set volume
-			err = ctx.PlayerRepo.SetVolume(ctx.Context, volume)
-			if err != nil {
-				ctx.OutputFormatter.Error(err)
-				return err
-			}
-
-			ctx.OutputFormatter.Success("Volume set to " + volume.String())
-			return nil
-		},
-	}
-
-	return cmd
-}
-
 // NewStatusCommand creates the status command
 func NewStatusCommand(ctx *CommandContext) *cobra.Command {
 	return &cobra.Command{
 		Use:     "status",
+		Args:    cobra.NoArgs,
 		Aliases: []string{"stat"},
 		Short:   "Show current player status",
 		Long:    "Display the current player status including state, volume, current track, and playback settings.",
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/madstone-tech/maestro/application/completion"
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/spf13/cobra"
+)
+
+// completionBudget bounds the time one dynamic completion may take, so that
+// a slow library never hangs the shell.
+const completionBudget = time.Second
+
+// completionShells are the shells completion scripts are generated for.
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// NewCompletionCommand creates the completion command
+func NewCompletionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion <bash|zsh|fish>",
+		Args:  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		Short: "Generate a shell completion script",
+		Long: `Generate a completion script for bash, zsh or fish.
+
+Besides commands and flags, the scripts complete playlist names, artists,
+albums and track titles from your library. Lookups go through maestrod's
+cache when the daemon is running and give up after a second otherwise.
+
+To load completions in the current shell:
+
+  bash: source <(maestro completion bash)
+  zsh:  source <(maestro completion zsh)
+  fish: maestro completion fish | source
+
+To load them in every session, write the script to your shell's completion
+directory, for example:
+
+  bash: maestro completion bash > /usr/local/etc/bash_completion.d/maestro
+  zsh:  maestro completion zsh > "${fpath[1]}/_maestro"
+  fish: maestro completion fish > ~/.config/fish/completions/maestro.fish`,
+		ValidArgs:             completionShells,
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			switch args[0] {
+			case "bash":
+				return cmd.Root().GenBashCompletionV2(out, true)
+			case "zsh":
+				return cmd.Root().GenZshCompletion(out)
+			case "fish":
+				return cmd.Root().GenFishCompletion(out, true)
+			default:
+				return music.NewDomainError(music.ErrInvalidOperation, fmt.Sprintf("unsupported shell %q", args[0]))
+			}
+		},
+	}
+}
+
+// completeArgs completes the nth argument with names of kinds[n]. Later
+// arguments are not completed.
+func completeArgs(ctx *CommandContext, kinds ...completion.Kind) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) >= len(kinds) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeNames(ctx, cmd, kinds[len(args)], toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeQuery completes the nth argument with names of kinds[n], and
+// every later argument, such as the words of a query, with the last kind.
+func completeQuery(ctx *CommandContext, kinds ...completion.Kind) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		kind := kinds[len(kinds)-1]
+		if len(args) < len(kinds) {
+			kind = kinds[len(args)]
+		}
+		return completeNames(ctx, cmd, kind, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeFlag completes a flag value with names of kind.
+func completeFlag(ctx *CommandContext, kind completion.Kind) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeNames(ctx, cmd, kind, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeNamesOf completes a flag value with the names of a fixed set of
+// values, such as the valid fade curves.
+func completeNamesOf[T fmt.Stringer](values []T) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	names := make([]string, len(values))
+	for i, value := range values {
+		names[i] = value.String()
+	}
+	return cobra.FixedCompletions(names, cobra.ShellCompDirectiveNoFileComp)
+}
+
+// completeNames returns the names of kind that start with prefix. It asks
+// maestrod first and falls back to the library when no daemon answers,
+// returning nothing once completionBudget has passed.
+func completeNames(ctx *CommandContext, cmd *cobra.Command, kind completion.Kind, prefix string) []string {
+	// Completion skips the pre-run hooks that load the configuration
+	if ctx.Config == nil {
+		if preRun := cmd.Root().PersistentPreRunE; preRun == nil || preRun(cmd, nil) != nil {
+			return nil
+		}
+	}
+
+	budgetCtx, cancel := context.WithTimeout(ctx.Context, completionBudget)
+	defer cancel()
+
+	names, err := completion.Fetch(budgetCtx, ctx.Config, kind, prefix)
+	if err == nil || budgetCtx.Err() != nil || ctx.LibraryRepo == nil {
+		return names
+	}
+
+	names, _ = completion.NewCompleter(ctx.LibraryRepo, nil).Complete(budgetCtx, kind, prefix)
+	return names
+}
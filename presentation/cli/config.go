@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"github.com/madstone-tech/maestro/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// loadConfig loads the client configuration once per process and connects
+// the repositories. The interactive shell reuses the configuration loaded
+// when it started.
+func loadConfig(ctx *CommandContext, file string, overrides []string) error {
+	if ctx.Config != nil {
+		return nil
+	}
+
+	options := config.DefaultLoadOptions("maestro")
+	options.File = file
+	options.Overrides = overrides
+
+	cfg, err := config.Load(options)
+	if err != nil {
+		return err
+	}
+	ctx.Config = cfg
+
+	if ctx.Connect != nil {
+		return ctx.Connect(cfg)
+	}
+	return nil
+}
+
+// NewConfigCommand creates the config command group
+func NewConfigCommand(ctx *CommandContext) *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect configuration",
+		Long: `Inspect the effective configuration.
+
+Configuration is merged from built-in defaults, /etc/maestro/maestro.toml,
+~/.config/maestro/maestro.toml, the nearest .maestro.toml, --config,
+MAESTRO_<SECTION>_<KEY> environment variables and --set flags, in that order.`,
+	}
+
+	configCmd.AddCommand(NewConfigShowCommand(ctx))
+
+	return configCmd
+}
+
+// NewConfigShowCommand creates the config show command
+func NewConfigShowCommand(ctx *CommandContext) *cobra.Command {
+	var sources bool
+
+	cmd := &cobra.Command{
+		Use:   "show",
+		Args:  cobra.NoArgs,
+		Short: "Show the effective configuration",
+		Long:  "Show the effective configuration as TOML. With --sources, each key is annotated with the file, environment variable or flag it came from.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing config show command")
+			ctx.OutputFormatter.PrintConfig(ctx.Config, sources)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&sources, "sources", false, "Annotate each key with where its value came from")
+
+	return cmd
+}
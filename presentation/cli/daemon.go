@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/pkg/health"
+	"github.com/spf13/cobra"
+)
+
+// NewDaemonCommand creates the daemon command group
+func NewDaemonCommand(ctx *CommandContext) *cobra.Command {
+	daemonCmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Inspect the maestrod daemon",
+	}
+
+	daemonCmd.AddCommand(NewDaemonHealthCommand(ctx))
+
+	return daemonCmd
+}
+
+// NewDaemonHealthCommand creates the daemon health command
+func NewDaemonHealthCommand(ctx *CommandContext) *cobra.Command {
+	var address string
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "health",
+		Args:  cobra.NoArgs,
+		Short: "Show the health of the daemon and its components",
+		Long: `Query the daemon's readiness endpoint and show the status of each component.
+Exits with an error when the daemon is unreachable or not ready.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing daemon health command")
+
+			if address == "" {
+				address = ctx.Config.Health.Address
+			}
+
+			fetchCtx, cancel := context.WithTimeout(ctx.Context, timeout)
+			defer cancel()
+
+			report, err := health.Fetch(fetchCtx, address)
+			if err != nil {
+				err = music.NewDomainErrorWithCause(music.ErrOperationFailed, fmt.Sprintf("maestrod is not reachable at %s", address), err)
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			ctx.OutputFormatter.PrintHealth(report)
+			if !report.Ready() {
+				return music.NewDomainError(music.ErrPlayerNotAvailable, "maestrod is not ready")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&address, "address", "", "Health server address (default from health.address)")
+	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Second, "How long to wait for the report")
+
+	return cmd
+}
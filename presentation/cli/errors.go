@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Exit codes of the maestro command. They are stable: scripts may branch on
+// them.
+const (
+	// ExitOK is a command that succeeded
+	ExitOK = 0
+
+	// ExitFailure is a command that failed for another reason
+	ExitFailure = 1
+
+	// ExitInvalidInput is a bad argument, flag or request
+	ExitInvalidInput = 2
+
+	// ExitNotFound is a missing track, playlist or queue entry
+	ExitNotFound = 3
+
+	// ExitUnavailable is a player, library or daemon that cannot be reached
+	ExitUnavailable = 4
+
+	// ExitTimeout is an operation that ran out of time
+	ExitTimeout = 5
+
+	// ExitPermission is an operation that is not allowed
+	ExitPermission = 6
+
+	// ExitRateLimited is a request rejected by a rate limit
+	ExitRateLimited = 7
+
+	// ExitCanceled is a command interrupted by the user, as by Ctrl-C
+	ExitCanceled = 130
+)
+
+// ExitCode returns the exit code for err, ExitOK when err is nil.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	switch music.KindOf(err) {
+	case music.ErrorKindInvalidInput:
+		return ExitInvalidInput
+	case music.ErrorKindNotFound:
+		return ExitNotFound
+	case music.ErrorKindUnavailable:
+		return ExitUnavailable
+	case music.ErrorKindTimeout:
+		return ExitTimeout
+	case music.ErrorKindPermission:
+		return ExitPermission
+	case music.ErrorKindRateLimited:
+		return ExitRateLimited
+	case music.ErrorKindCanceled:
+		return ExitCanceled
+	default:
+		return ExitFailure
+	}
+}
+
+// ReportError prints err in the output format selected on root, unless the
+// command already reported it, and returns the exit code for it. It is for
+// errors returned by executing root.
+func ReportError(ctx *CommandContext, root *cobra.Command, err error) int {
+	// Cobra reports unknown commands as plain errors
+	if strings.HasPrefix(err.Error(), "unknown command ") {
+		err = usageError(err)
+	}
+
+	formatter := ctx.OutputFormatter
+	if formatter == nil {
+		formatter = NewOutputFormatter(fallbackRenderer(root), false)
+	}
+
+	if !formatter.reported(err) {
+		formatter.Error(err)
+	}
+	return ExitCode(err)
+}
+
+// fallbackRenderer returns the renderer for the output flags among the
+// process arguments, for errors raised before the root pre-run hook has
+// parsed them, such as unknown commands or flags. It returns nil, the table
+// format, when the flags select no valid format.
+func fallbackRenderer(root *cobra.Command) Renderer {
+	flags := pflag.NewFlagSet(root.Name(), pflag.ContinueOnError)
+	flags.ParseErrorsWhitelist.UnknownFlags = true
+	flags.Usage = func() {}
+	flags.SetOutput(io.Discard)
+	flags.AddFlagSet(root.PersistentFlags())
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		return nil
+	}
+
+	jsonOutput, _ := flags.GetBool("json")
+	outputName, _ := flags.GetString("output")
+	templateText, _ := flags.GetString("template")
+	renderer, err := outputRenderer(root, outputName, templateText, jsonOutput)
+	if err != nil {
+		return nil
+	}
+	return renderer
+}
+
+// usageError makes a plain argument or flag error an invalid-input domain
+// error. Help requests and domain errors are returned unchanged.
+func usageError(err error) error {
+	var domainErr *music.DomainError
+	if err == nil || errors.Is(err, pflag.ErrHelp) || errors.As(err, &domainErr) {
+		return err
+	}
+	return music.NewDomainError(music.ErrInvalidOperation, err.Error())
+}
+
+// markUsageErrors makes the argument errors of cmd and its subcommands
+// invalid-input errors.
+func markUsageErrors(cmd *cobra.Command) {
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(c *cobra.Command, args []string) error {
+			return usageError(validate(c, args))
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		markUsageErrors(sub)
+	}
+}
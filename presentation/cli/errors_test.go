@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// TestExitCode pins the exit code table: scripts branch on these numbers,
+// so a change here is a breaking change.
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, 0},
+		{"plain error", errors.New("boom"), 1},
+		{"operation failed", music.NewDomainError(music.ErrOperationFailed, "script failed"), 1},
+		{"interrupted fade", music.NewDomainError(music.ErrInterrupted, "fade stopped"), 1},
+		{"invalid volume", music.NewDomainError(music.ErrInvalidVolume, "too loud"), 2},
+		{"usage error", usageError(errors.New("accepts 1 arg(s), received 2")), 2},
+		{"missing track", music.NewDomainError(music.ErrTrackNotFound, "no such track"), 3},
+		{"wrapped missing playlist", fmt.Errorf("play: %w", music.NewDomainError(music.ErrPlaylistNotFound, "gone")), 3},
+		{"empty queue", music.ErrQueueEmpty, 3},
+		{"player unavailable", music.NewDomainError(music.ErrPlayerNotAvailable, "Music.app is not running"), 4},
+		{"timeout", music.NewDomainError(music.ErrTimeout, "slow"), 5},
+		{"deadline", context.DeadlineExceeded, 5},
+		{"permission denied", music.NewDomainError(music.ErrPermissionDenied, "automation not allowed"), 6},
+		{"read-only playlist", music.NewDomainError(music.ErrPlaylistReadOnly, "smart playlist"), 6},
+		{"rate limited", music.NewDomainError(music.ErrRateLimited, "slow down"), 7},
+		{"interrupted by the user", fmt.Errorf("wait: %w", context.Canceled), 130},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsageErrorKeepsDomainErrors(t *testing.T) {
+	original := music.NewDomainError(music.ErrTrackNotFound, "no such track")
+	if err := usageError(original); err != original {
+		t.Errorf("expected a domain error to be returned unchanged, got %v", err)
+	}
+	if err := usageError(nil); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+}
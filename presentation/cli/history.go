@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/spf13/cobra"
+)
+
+// historyKindAll selects plays and skips.
+const historyKindAll = "all"
+
+// historyFlags are the range filters shared by the history commands
+type historyFlags struct {
+	since string
+	until string
+	kind  string
+}
+
+// NewHistoryCommand creates the history command
+func NewHistoryCommand(ctx *CommandContext) *cobra.Command {
+	flags := &historyFlags{}
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Args:  cobra.NoArgs,
+		Short: "Show what was listened to",
+		Long: `Show the tracks maestrod recorded as played, oldest first. A track counts as
+played once half of it, or four minutes, has been heard; tracks left before
+that are recorded as skips. Each entry says whether maestro or something
+else, such as Music.app itself, started the track.
+
+--since and --until take a period back from now such as 12h or 7d, a day
+such as 2024-01-31 or an RFC 3339 time.`,
+		Example: `  maestro history
+  maestro history --since 7d
+  maestro history --since 2024-01-01 --until 2024-02-01 --kind all
+  maestro history --kind skip --output json
+  maestro history export --since 30d history.json`,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing history command")
+
+			entries, err := flags.history(ctx, limit)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			ctx.OutputFormatter.PrintHistory(entries)
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&flags.since, "since", "", "Only entries recorded at or after this time")
+	cmd.PersistentFlags().StringVar(&flags.until, "until", "", "Only entries recorded before this time")
+	cmd.PersistentFlags().StringVar(&flags.kind, "kind", music.HistoryPlay.String(), "Entries to show: play, skip or all")
+	cmd.Flags().IntVar(&limit, "limit", 50, "Show only the most recent entries (0 for all)")
+
+	_ = cmd.RegisterFlagCompletionFunc("kind", cobra.FixedCompletions(
+		[]string{music.HistoryPlay.String(), music.HistorySkip.String(), historyKindAll}, cobra.ShellCompDirectiveNoFileComp))
+
+	cmd.AddCommand(NewHistoryExportCommand(ctx, flags))
+
+	return cmd
+}
+
+// NewHistoryExportCommand creates the history export command
+func NewHistoryExportCommand(ctx *CommandContext, flags *historyFlags) *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "export [file|-]",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "Export the history as JSON",
+		Long: `Write the selected history entries as a JSON array, with the full track
+details recorded for each, to a file or to standard output.`,
+		Example: `  maestro history export history.json
+  maestro history export --since 2024-01-01 --kind all -`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing history export command")
+
+			entries, err := flags.history(ctx, limit)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			if len(args) == 0 || args[0] == "-" {
+				if err := exportHistory(cmd.OutOrStdout(), entries); err != nil {
+					ctx.OutputFormatter.Error(err)
+					return err
+				}
+				return nil
+			}
+
+			if err := exportHistoryFile(args[0], entries); err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+			ctx.OutputFormatter.Success(fmt.Sprintf("Exported %d history entries to %s", len(entries), args[0]))
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 0, "Export only the most recent entries (0 for all)")
+
+	return cmd
+}
+
+// history reads the entries the flags select, keeping the most recent
+// limit entries (0 for all).
+func (f *historyFlags) history(ctx *CommandContext, limit int) ([]*music.HistoryEntry, error) {
+	filter, err := f.filter(time.Now(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.HistoryRepo.GetHistory(ctx.Context, filter)
+}
+
+// filter builds the history filter for the flags, with periods measured
+// back from now.
+func (f *historyFlags) filter(now time.Time, limit int) (music.HistoryFilter, error) {
+	filter := music.HistoryFilter{Limit: limit}
+
+	var err error
+	if strings.TrimSpace(f.since) != "" {
+		if filter.Since, err = music.ParseHistoryTime(f.since, now); err != nil {
+			return music.HistoryFilter{}, err
+		}
+	}
+	if strings.TrimSpace(f.until) != "" {
+		if filter.Until, err = music.ParseHistoryTime(f.until, now); err != nil {
+			return music.HistoryFilter{}, err
+		}
+	}
+	if !strings.EqualFold(strings.TrimSpace(f.kind), historyKindAll) {
+		kind, err := music.ParseHistoryKind(f.kind)
+		if err != nil {
+			return music.HistoryFilter{}, err
+		}
+		filter.Kinds = []music.HistoryKind{kind}
+	}
+	return filter, filter.Validate()
+}
+
+// exportHistory writes entries to w as an indented JSON array.
+func exportHistory(w io.Writer, entries []*music.HistoryEntry) error {
+	return jsonRenderer{}.Render(w, entries)
+}
+
+// exportHistoryFile writes entries to the file at path.
+func exportHistoryFile(path string, entries []*music.HistoryEntry) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return music.NewDomainErrorWithCause(music.ErrOperationFailed, "cannot create "+path, err).
+			WithContext("path", path)
+	}
+	err = exportHistory(file, entries)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return music.NewDomainErrorWithCause(music.ErrOperationFailed, "cannot write "+path, err).
+			WithContext("path", path)
+	}
+	return nil
+}
@@ -1,27 +1,41 @@
 package cli
 
 import (
-	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
 	"os"
 
 	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/pkg/config"
+	"github.com/madstone-tech/maestro/pkg/health"
 )
 
-// OutputFormatter handles formatting and displaying command output
+// OutputFormatter handles formatting and displaying command output. It
+// builds a view model for each kind of output and hands it to a Renderer.
 type OutputFormatter struct {
-	jsonMode bool
-	verbose  bool
-	writer   io.Writer
+	renderer  Renderer
+	verbose   bool
+	writer    io.Writer
+	errWriter io.Writer
+
+	// err is the first error met while rendering
+	err error
+
+	// lastError is the last error printed by Error
+	lastError error
 }
 
-// NewOutputFormatter creates a new output formatter
-func NewOutputFormatter(jsonMode, verbose bool) *OutputFormatter {
+// NewOutputFormatter creates a new output formatter that writes with
+// renderer (nil for the table format)
+func NewOutputFormatter(renderer Renderer, verbose bool) *OutputFormatter {
+	if renderer == nil {
+		renderer = tableRenderer{}
+	}
 	return &OutputFormatter{
-		jsonMode: jsonMode,
-		verbose:  verbose,
-		writer:   os.Stdout,
+		renderer:  renderer,
+		verbose:   verbose,
+		writer:    os.Stdout,
+		errWriter: os.Stderr,
 	}
 }
 
@@ -30,141 +44,151 @@ func (f *OutputFormatter) SetWriter(w io.Writer) {
 	f.writer = w
 }
 
+// SetErrorWriter sets the writer errors are printed to (useful for testing)
+func (f *OutputFormatter) SetErrorWriter(w io.Writer) {
+	f.errWriter = w
+}
+
+// Err returns the first error met while rendering, such as a template that
+// does not fit the output
+func (f *OutputFormatter) Err() error {
+	return f.err
+}
+
 // Success prints a success message
 func (f *OutputFormatter) Success(message string) {
-	if f.jsonMode {
-		f.printJSON(map[string]interface{}{
-			"success": true,
-			"message": message,
-		})
-	} else {
-		_, _ = fmt.Fprintf(f.writer, "%s\n", message)
-	}
+	f.render(&MessageView{Success: true, Message: message})
 }
 
-// Error prints an error message
+// Error prints an error with its stable code, kind and exit code to the
+// error writer
 func (f *OutputFormatter) Error(err error) {
-	if f.jsonMode {
-		f.printJSON(map[string]interface{}{
-			"success": false,
-			"error":   err.Error(),
-		})
-	} else {
-		_, _ = fmt.Fprintf(f.writer, "Error: %s\n", err.Error())
-	}
+	f.lastError = err
+	f.renderTo(f.errWriter, newErrorView(err))
+}
+
+// reported reports whether err, or an error it wraps, was printed by Error
+func (f *OutputFormatter) reported(err error) bool {
+	return f.lastError != nil && errors.Is(err, f.lastError)
 }
 
 // PrintPlayerStatus prints the current player status
 func (f *OutputFormatter) PrintPlayerStatus(player *music.Player, track *music.Track) {
-	if f.jsonMode {
-		f.printPlayerStatusJSON(player, track)
-	} else {
-		f.printPlayerStatusText(player, track)
-	}
+	f.render(newStatusView(player, track))
 }
 
 // PrintTrack prints track information
 func (f *OutputFormatter) PrintTrack(track *music.Track) {
-	if f.jsonMode {
-		f.printJSON(track)
-	} else {
-		if track != nil {
-			_, _ = fmt.Fprintf(f.writer, "%s - %s\n", track.Artist, track.Title)
-			if track.Album != "" {
-				_, _ = fmt.Fprintf(f.writer, "Album: %s\n", track.Album)
-			}
-			_, _ = fmt.Fprintf(f.writer, "Duration: %s\n", track.Duration.String())
-		} else {
-			_, _ = fmt.Fprintf(f.writer, "No current track\n")
-		}
+	if track == nil {
+		f.render((*TrackView)(nil))
+		return
 	}
+	trackView := newTrackView(track)
+	f.render(&trackView)
 }
 
 // PrintVolume prints volume information
 func (f *OutputFormatter) PrintVolume(volume music.Volume) {
-	if f.jsonMode {
-		f.printJSON(map[string]interface{}{
-			"volume": volume.Level(),
-		})
-	} else {
-		fmt.Fprintf(f.writer, "Volume: %s\n", volume.String())
-	}
+	f.render(&VolumeView{Volume: volume.Level()})
 }
 
-// printPlayerStatusJSON prints player status in JSON format
-func (f *OutputFormatter) printPlayerStatusJSON(player *music.Player, track *music.Track) {
-	status := map[string]interface{}{
-		"state":    player.State.String(),
-		"volume":   player.Volume.Level(),
-		"position": player.Position.String(),
-		"shuffle":  player.Shuffle,
-		"repeat":   player.Repeat.String(),
-	}
+// PrintConfig prints the effective configuration, optionally annotating each
+// key with the layer it came from
+func (f *OutputFormatter) PrintConfig(cfg *config.Config, withSources bool) {
+	f.render(newConfigView(cfg, withSources))
+}
+
+// PrintHealth prints a daemon health report
+func (f *OutputFormatter) PrintHealth(report *health.Report) {
+	f.render(HealthView{Report: report})
+}
 
-	if track != nil {
-		status["current_track"] = map[string]interface{}{
-			"id":       track.ID.Value(),
-			"title":    track.Title,
-			"artist":   track.Artist,
-			"album":    track.Album,
-			"duration": track.Duration.String(),
+// PrintSearchResults prints ranked search results as a table, or only the
+// track IDs when idsOnly is set
+func (f *OutputFormatter) PrintSearchResults(results []music.SearchResult, idsOnly bool) {
+	if idsOnly {
+		ids := make(TrackIDsView, len(results))
+		for i, result := range results {
+			ids[i] = result.Track.ID.Value()
 		}
-	} else {
-		status["current_track"] = nil
+		f.render(ids)
+		return
 	}
+	f.render(newSearchResultsView(results))
+}
 
-	f.printJSON(status)
+// PrintQueue prints queued tracks with their 1-based queue positions.
+// first is the 0-based queue position of tracks[0], and current marks the
+// playing track's position (-1 for none).
+func (f *OutputFormatter) PrintQueue(tracks []*music.Track, current, first int) {
+	f.render(newQueueView(tracks, current, first))
 }
 
-// printPlayerStatusText prints player status in human-readable format
-func (f *OutputFormatter) printPlayerStatusText(player *music.Player, track *music.Track) {
-	fmt.Fprintf(f.writer, "Status: %s\n", player.State.String())
-	fmt.Fprintf(f.writer, "Volume: %s\n", player.Volume.String())
+// PrintRatedTracks prints tracks with their ratings, best rated first
+func (f *OutputFormatter) PrintRatedTracks(tracks []*music.Track) {
+	f.render(newRatedTracksView(tracks))
+}
 
-	if track != nil {
-		fmt.Fprintf(f.writer, "Now Playing: %s - %s\n", track.Artist, track.Title)
-		if track.Album != "" {
-			fmt.Fprintf(f.writer, "Album: %s\n", track.Album)
-		}
-		fmt.Fprintf(f.writer, "Position: %s / %s\n", player.Position.String(), track.Duration.String())
-	} else {
-		fmt.Fprintf(f.writer, "No current track\n")
+// PrintHistory prints history entries, oldest first
+func (f *OutputFormatter) PrintHistory(entries []*music.HistoryEntry) {
+	f.render(newHistoryView(entries))
+}
+
+// PrintPlaylists prints playlists with their track counts, total durations
+// (keyed by playlist ID) and modification times
+func (f *OutputFormatter) PrintPlaylists(playlists []*music.Playlist, durations map[string]music.Duration) {
+	views := make(PlaylistsView, len(playlists))
+	for i, playlist := range playlists {
+		views[i] = newPlaylistView(playlist, durations[playlist.ID.Value()])
 	}
+	f.render(views)
+}
 
-	fmt.Fprintf(f.writer, "Shuffle: %t\n", player.Shuffle)
-	fmt.Fprintf(f.writer, "Repeat: %s\n", player.Repeat.String())
+// PrintPlaylist prints a playlist summary followed by its tracks
+func (f *OutputFormatter) PrintPlaylist(playlist *music.Playlist, tracks []*music.Track) {
+	f.render(newPlaylistDetailView(playlist, tracks))
 }
 
-// printJSON prints data in JSON format
-func (f *OutputFormatter) printJSON(data interface{}) {
-	jsonData, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		fmt.Fprintf(f.writer, `{"error": "Failed to format JSON output"}%s`, "\n")
-		return
+// PrintSmartPlaylists prints smart playlists with their rules
+func (f *OutputFormatter) PrintSmartPlaylists(playlists []*music.SmartPlaylist) {
+	views := make(SmartPlaylistsView, len(playlists))
+	for i, playlist := range playlists {
+		views[i] = newSmartPlaylistView(playlist)
 	}
-	fmt.Fprintf(f.writer, "%s\n", jsonData)
+	f.render(views)
+}
+
+// PrintSmartPlaylist prints a smart playlist's rules followed by the tracks
+// they select
+func (f *OutputFormatter) PrintSmartPlaylist(playlist *music.SmartPlaylist, tracks []*music.Track) {
+	f.render(newSmartPlaylistDetailView(playlist, tracks))
+}
+
+// PrintBatchResults prints the result of each line of a batch
+func (f *OutputFormatter) PrintBatchResults(results BatchResultsView) {
+	f.render(results)
 }
 
 // Debug prints debug information if verbose mode is enabled
 func (f *OutputFormatter) Debug(message string) {
 	if f.verbose {
-		if f.jsonMode {
-			f.printJSON(map[string]interface{}{
-				"debug": message,
-			})
-		} else {
-			fmt.Fprintf(f.writer, "DEBUG: %s\n", message)
-		}
+		f.render(&DebugView{Debug: message})
 	}
 }
 
 // Info prints informational messages
 func (f *OutputFormatter) Info(message string) {
-	if f.jsonMode {
-		f.printJSON(map[string]interface{}{
-			"info": message,
-		})
-	} else {
-		_, _ = fmt.Fprintf(f.writer, "%s\n", message)
+	f.render(&InfoView{Info: message})
+}
+
+// render writes a view model, remembering the first failure for Err
+func (f *OutputFormatter) render(v interface{}) {
+	f.renderTo(f.writer, v)
+}
+
+// renderTo writes a view model to w, remembering the first failure for Err
+func (f *OutputFormatter) renderTo(w io.Writer, v interface{}) {
+	if err := f.renderer.Render(w, v); err != nil && f.err == nil {
+		f.err = err
 	}
 }
@@ -0,0 +1,460 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/madstone-tech/maestro/application/completion"
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Play target limits.
+const (
+	// maxPlayTracks caps the tracks a free-text target is matched against
+	maxPlayTracks = 20
+
+	// maxPlayChoices caps the candidates listed when a target is ambiguous
+	maxPlayChoices = 9
+)
+
+// playKind is what a play target names. Kinds are declared in the order
+// that breaks ties between equally good matches.
+type playKind int
+
+const (
+	playAny playKind = iota
+	playPlaylist
+	playAlbum
+	playArtist
+	playTrack
+)
+
+// playPrefixes are the prefixes that restrict a play target to one kind.
+var playPrefixes = []struct {
+	prefix string
+	kind   playKind
+	names  completion.Kind
+}{
+	{"playlist:", playPlaylist, completion.KindPlaylist},
+	{"album:", playAlbum, completion.KindAlbum},
+	{"artist:", playArtist, completion.KindArtist},
+	{"track:", playTrack, completion.KindTrack},
+}
+
+func (k playKind) String() string {
+	switch k {
+	case playPlaylist:
+		return "playlist"
+	case playAlbum:
+		return "album"
+	case playArtist:
+		return "artist"
+	case playTrack:
+		return "track"
+	default:
+		return "anything"
+	}
+}
+
+// playCandidate is a playlist, album, artist or track a play target may
+// mean, or a list of tracks without a name. load returns the tracks to play
+// and the index to start at.
+type playCandidate struct {
+	kind  playKind
+	name  string
+	score float64
+	load  func() ([]music.TrackID, int, error)
+}
+
+func (c playCandidate) String() string {
+	return fmt.Sprintf("%s '%s'", c.kind, c.name)
+}
+
+// NewPlayCommand creates the play command
+func NewPlayCommand(ctx *CommandContext) *cobra.Command {
+	var first, replaceQueue bool
+
+	cmd := &cobra.Command{
+		Use:   "play [target...]",
+		Short: "Resume playback, or play a track, album, artist or playlist",
+		Long: `Without a target, resume paused playback or, when stopped, start playing
+Music.app's current selection.
+
+With a target, play it in context: the album, artist or playlist is added
+to the end of the queue and plays from there, so that next continues
+through it; --replace-queue clears the queue first. A track plays within
+its album. A target is one of
+
+  track IDs, or - to read IDs from stdin as "queue add" does
+  album:NAME, artist:NAME, playlist:NAME or track:TITLE
+  free text, matched against all of the above
+
+The best match plays when it is clearly better than the others. When
+several things match equally well, maestro asks which one to play if it
+runs in a terminal and otherwise fails with the matches; --first plays the
+best of them instead.`,
+		Example: `  maestro play
+  maestro play 'album:Kind of Blue'
+  maestro play artist:coltrane
+  maestro play so what
+  maestro play --replace-queue playlist:focus
+  maestro search --ids --album "blue train" | maestro play -`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing play command")
+
+			if len(args) > 0 {
+				return playTarget(ctx, cmd, args, first, replaceQueue)
+			}
+
+			if err := ctx.PlayerRepo.Start(ctx.Context); err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+			ctx.OutputFormatter.Success("Playback started")
+			return nil
+		},
+		ValidArgsFunction: completePlayTarget(ctx),
+	}
+
+	cmd.Flags().BoolVar(&first, "first", false, "Play the best match without asking when the target is ambiguous")
+	cmd.Flags().BoolVar(&replaceQueue, "replace-queue", false, "Clear the queue before playing the target")
+
+	return cmd
+}
+
+// playTarget resolves args to something to play and starts it.
+func playTarget(ctx *CommandContext, cmd *cobra.Command, args []string, first, replaceQueue bool) error {
+	candidate, err := resolvePlayTarget(ctx, cmd, args, first)
+	if err != nil {
+		ctx.OutputFormatter.Error(err)
+		return err
+	}
+
+	trackIDs, start, err := candidate.load()
+	if err == nil && len(trackIDs) == 0 {
+		err = music.NewDomainError(music.ErrTrackNotFound, fmt.Sprintf("%s has no tracks", candidate)).
+			WithContext("kind", candidate.kind.String())
+	}
+	if err == nil {
+		err = startPlayback(ctx, trackIDs, start, replaceQueue)
+	}
+	if err != nil {
+		ctx.OutputFormatter.Error(err)
+		return err
+	}
+
+	switch {
+	case candidate.name == "":
+		ctx.OutputFormatter.Success(fmt.Sprintf("Playing %d tracks", len(trackIDs)))
+	case candidate.kind == playTrack:
+		ctx.OutputFormatter.Success(fmt.Sprintf("Playing %s (track %d of %d)", candidate, start+1, len(trackIDs)))
+	default:
+		ctx.OutputFormatter.Success(fmt.Sprintf("Playing %s (%d tracks)", candidate, len(trackIDs)))
+	}
+	return nil
+}
+
+// startPlayback adds trackIDs to the end of the queue, cleared first with
+// replaceQueue set, and plays the one at start, so that next and previous
+// walk through the tracks.
+func startPlayback(ctx *CommandContext, trackIDs []music.TrackID, start int, replaceQueue bool) error {
+	if replaceQueue {
+		if err := ctx.QueueRepo.ClearQueue(ctx.Context); err != nil {
+			return err
+		}
+	}
+	if err := ctx.QueueRepo.AddTracksToQueue(ctx.Context, trackIDs); err != nil {
+		return err
+	}
+	queue, err := ctx.QueueRepo.GetQueue(ctx.Context)
+	if err != nil {
+		return err
+	}
+	return ctx.QueueRepo.SetQueuePosition(ctx.Context, queue.TrackCount()-len(trackIDs)+start)
+}
+
+// resolvePlayTarget picks the one thing args name: the only or clearly best
+// candidate, the best with first set, or the user's choice on a terminal.
+func resolvePlayTarget(ctx *CommandContext, cmd *cobra.Command, args []string, first bool) (playCandidate, error) {
+	if len(args) == 1 && args[0] == "-" {
+		trackIDs, err := readTrackIDs(cmd.InOrStdin())
+		return trackListCandidate(trackIDs), err
+	}
+	if looksLikeTrackIDs(args) {
+		if candidate, ok, err := trackIDCandidate(ctx, args); ok || err != nil {
+			return candidate, err
+		}
+	}
+
+	kind, query := parsePlayTarget(args)
+	if query == "" {
+		return playCandidate{}, music.NewDomainError(music.ErrInvalidSearchQuery, "play needs something to look for")
+	}
+
+	candidates, err := playCandidates(ctx, kind, query)
+	if err != nil {
+		return playCandidate{}, err
+	}
+
+	switch {
+	case len(candidates) == 0:
+		code := music.ErrTrackNotFound
+		if kind == playPlaylist {
+			code = music.ErrPlaylistNotFound
+		}
+		message := fmt.Sprintf("no %s matches %q", kind, query)
+		if kind == playAny {
+			message = fmt.Sprintf("nothing matches %q", query)
+		}
+		return playCandidate{}, music.NewDomainError(code, message).WithContext("query", query)
+	case len(candidates) == 1 || candidates[0].score > candidates[1].score || first:
+		return candidates[0], nil
+	case term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stderr.Fd())):
+		return pickPlayCandidate(cmd.InOrStdin(), cmd.ErrOrStderr(), query, candidates)
+	default:
+		return playCandidate{}, ambiguousPlayTarget(query, candidates)
+	}
+}
+
+// parsePlayTarget splits a target such as "album:Kind of Blue" into its
+// kind and query. Quotes around the query are dropped.
+func parsePlayTarget(args []string) (playKind, string) {
+	target := strings.TrimSpace(strings.Join(args, " "))
+	kind := playAny
+	for _, p := range playPrefixes {
+		if len(target) >= len(p.prefix) && strings.EqualFold(target[:len(p.prefix)], p.prefix) {
+			kind, target = p.kind, target[len(p.prefix):]
+			break
+		}
+	}
+	return kind, strings.TrimSpace(strings.Trim(strings.TrimSpace(target), `"'`))
+}
+
+// playCandidates returns everything of kind that matches query, best
+// first.
+func playCandidates(ctx *CommandContext, kind playKind, query string) ([]playCandidate, error) {
+	var candidates []playCandidate
+
+	if kind == playAny || kind == playPlaylist {
+		playlists, err := ctx.LibraryRepo.GetPlaylists(ctx.Context)
+		if err != nil {
+			return nil, err
+		}
+		for _, playlist := range playlists {
+			score := music.ScoreName(playlist.Name, query)
+			if strings.EqualFold(playlist.ID.Value(), query) {
+				score = 1
+			}
+			if score == 0 || playlist.Type == music.PlaylistTypeQueue {
+				continue
+			}
+			playlistID := playlist.ID
+			candidates = append(candidates, playCandidate{kind: playPlaylist, name: playlist.Name, score: score,
+				load: func() ([]music.TrackID, int, error) {
+					tracks, err := ctx.LibraryRepo.GetPlaylistTracks(ctx.Context, playlistID)
+					return trackIDsOf(tracks), 0, err
+				}})
+		}
+	}
+
+	if kind == playAny || kind == playAlbum {
+		albums, err := ctx.LibraryRepo.GetAlbums(ctx.Context)
+		if err != nil {
+			return nil, err
+		}
+		for _, album := range albums {
+			if score := music.ScoreName(album, query); score > 0 {
+				candidates = append(candidates, playCandidate{kind: playAlbum, name: album, score: score,
+					load: func() ([]music.TrackID, int, error) {
+						tracks, err := ctx.LibraryRepo.GetTracksByAlbum(ctx.Context, album)
+						return trackIDsOf(tracks), 0, err
+					}})
+			}
+		}
+	}
+
+	if kind == playAny || kind == playArtist {
+		artists, err := ctx.LibraryRepo.GetArtists(ctx.Context)
+		if err != nil {
+			return nil, err
+		}
+		for _, artist := range artists {
+			if score := music.ScoreName(artist, query); score > 0 {
+				candidates = append(candidates, playCandidate{kind: playArtist, name: artist, score: score,
+					load: func() ([]music.TrackID, int, error) {
+						tracks, err := ctx.LibraryRepo.GetTracksByArtist(ctx.Context, artist)
+						return trackIDsOf(tracks), 0, err
+					}})
+			}
+		}
+	}
+
+	if kind == playAny || kind == playTrack {
+		options := music.LibrarySearchOptions{Query: query, Fields: []music.SearchField{music.SearchFieldTitle}, Limit: maxPlayTracks}
+		tracks, err := ctx.LibraryRepo.Search(ctx.Context, options)
+		if err != nil {
+			return nil, err
+		}
+		for _, track := range tracks {
+			if kind == playAny && isTitleTrack(track, candidates) {
+				// Playing the album is what "play <album>" means
+				continue
+			}
+			candidate := trackCandidate(ctx, track)
+			candidate.score = music.ScoreTrack(track, options).Score
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].kind < candidates[j].kind
+	})
+	return candidates, nil
+}
+
+// isTitleTrack reports whether track is named after its album and that
+// album is among candidates.
+func isTitleTrack(track *music.Track, candidates []playCandidate) bool {
+	for _, candidate := range candidates {
+		if candidate.kind == playAlbum && strings.EqualFold(candidate.name, track.Album) &&
+			strings.EqualFold(track.Title, track.Album) {
+			return true
+		}
+	}
+	return false
+}
+
+// trackCandidate plays track within its album.
+func trackCandidate(ctx *CommandContext, track *music.Track) playCandidate {
+	name := track.Title
+	if track.Artist != "" {
+		name += " - " + track.Artist
+	}
+	return playCandidate{kind: playTrack, name: name, score: 1,
+		load: func() ([]music.TrackID, int, error) {
+			if track.Album == "" {
+				return []music.TrackID{track.ID}, 0, nil
+			}
+			album, err := ctx.LibraryRepo.GetTracksByAlbum(ctx.Context, track.Album)
+			if err != nil {
+				return nil, 0, err
+			}
+			for i, albumTrack := range album {
+				if albumTrack.ID.Equals(track.ID) {
+					return trackIDsOf(album), i, nil
+				}
+			}
+			return []music.TrackID{track.ID}, 0, nil
+		}}
+}
+
+// trackIDCandidate returns the candidate for track IDs given as arguments:
+// one track plays within its album, several play in the order given. ok is
+// false when the IDs are not all in the library.
+func trackIDCandidate(ctx *CommandContext, args []string) (playCandidate, bool, error) {
+	trackIDs := make([]music.TrackID, len(args))
+	for i, arg := range args {
+		trackIDs[i] = music.NewTrackID(arg)
+	}
+
+	tracks, err := ctx.LibraryRepo.GetTracks(ctx.Context, trackIDs)
+	switch {
+	case music.IsTrackNotFound(err):
+		return playCandidate{}, false, nil
+	case err != nil:
+		return playCandidate{}, false, err
+	case len(tracks) == 1:
+		return trackCandidate(ctx, tracks[0]), true, nil
+	default:
+		return trackListCandidate(trackIDs), true, nil
+	}
+}
+
+// trackListCandidate plays trackIDs in order. It has no name.
+func trackListCandidate(trackIDs []music.TrackID) playCandidate {
+	return playCandidate{kind: playTrack, score: 1,
+		load: func() ([]music.TrackID, int, error) {
+			return trackIDs, 0, nil
+		}}
+}
+
+// trackIDsOf returns the IDs of tracks.
+func trackIDsOf(tracks []*music.Track) []music.TrackID {
+	ids := make([]music.TrackID, len(tracks))
+	for i, track := range tracks {
+		ids[i] = track.ID
+	}
+	return ids
+}
+
+// pickPlayCandidate lists the best candidates on out and reads the number
+// of the one to play from in. An empty answer picks the first.
+func pickPlayCandidate(in io.Reader, out io.Writer, query string, candidates []playCandidate) (playCandidate, error) {
+	if len(candidates) > maxPlayChoices {
+		candidates = candidates[:maxPlayChoices]
+	}
+
+	_, _ = fmt.Fprintf(out, "%q matches several things:\n", query)
+	for i, candidate := range candidates {
+		_, _ = fmt.Fprintf(out, "  %d) %s\n", i+1, candidate)
+	}
+	_, _ = fmt.Fprintf(out, "Play which? [1-%d, Enter for 1] ", len(candidates))
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	answer = strings.TrimSpace(answer)
+	if answer == "" && err == nil {
+		return candidates[0], nil
+	}
+	choice, convErr := strconv.Atoi(answer)
+	if convErr != nil || choice < 1 || choice > len(candidates) {
+		return playCandidate{}, music.NewDomainError(music.ErrInvalidOperation, fmt.Sprintf("no choice made for %q", query)).
+			WithContext("query", query)
+	}
+	return candidates[choice-1], nil
+}
+
+// ambiguousPlayTarget reports a target that matches several things
+// equally well.
+func ambiguousPlayTarget(query string, candidates []playCandidate) error {
+	names := make([]string, 0, maxPlayChoices)
+	for i, candidate := range candidates {
+		if i == maxPlayChoices {
+			names = append(names, "...")
+			break
+		}
+		names = append(names, candidate.String())
+	}
+	return music.NewDomainError(music.ErrInvalidOperation,
+		fmt.Sprintf("%q matches %d things: %s; use --first or a prefix such as album:", query, len(candidates), strings.Join(names, ", "))).
+		WithContext("query", query).
+		WithContext("matches", len(candidates))
+}
+
+// completePlayTarget completes prefixed targets with names of their kind
+// and anything else with track titles.
+func completePlayTarget(ctx *CommandContext) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			for _, p := range playPrefixes {
+				if rest, ok := strings.CutPrefix(toComplete, p.prefix); ok {
+					names := completeNames(ctx, cmd, p.names, rest)
+					for i, name := range names {
+						names[i] = p.prefix + name
+					}
+					return names, cobra.ShellCompDirectiveNoFileComp
+				}
+			}
+		}
+		return completeNames(ctx, cmd, completion.KindTrack, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,257 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/infrastructure/memory"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+func newPlayContext() *CommandContext {
+	repos := memory.NewRepositories(memory.DemoConfig())
+	return &CommandContext{
+		Context:     context.Background(),
+		PlayerRepo:  repos,
+		LibraryRepo: repos,
+		QueueRepo:   repos,
+	}
+}
+
+func TestPlayWithoutTarget(t *testing.T) {
+	ctx := newPlayContext()
+	var out strings.Builder
+	ctx.OutputFormatter = NewOutputFormatter(nil, false)
+	ctx.OutputFormatter.SetWriter(&out)
+
+	// Stopped, play starts the current selection
+	cmd := NewPlayCommand(ctx)
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected play to start from stopped, got %v", err)
+	}
+	player, _ := ctx.PlayerRepo.GetCurrentState(ctx.Context)
+	if !player.IsPlaying() || player.CurrentTrack.Value() != "1001" {
+		t.Errorf("expected 1001 playing, got %s %v", player.State, player.CurrentTrack)
+	}
+	if !strings.Contains(out.String(), "Playback started") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+
+	// Paused, it resumes the same track
+	_ = ctx.PlayerRepo.Next(ctx.Context)
+	_ = ctx.PlayerRepo.Pause(ctx.Context)
+	cmd = NewPlayCommand(ctx)
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	player, _ = ctx.PlayerRepo.GetCurrentState(ctx.Context)
+	if !player.IsPlaying() || player.CurrentTrack.Value() != "1002" {
+		t.Errorf("expected 1002 to resume, got %s %v", player.State, player.CurrentTrack)
+	}
+}
+
+func TestPlayTargetQueue(t *testing.T) {
+	ctx := newPlayContext()
+	ctx.OutputFormatter = NewOutputFormatter(nil, false)
+	ctx.OutputFormatter.SetWriter(&strings.Builder{})
+
+	play := func(args ...string) {
+		t.Helper()
+		cmd := NewPlayCommand(ctx)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	queued := func() (int, int) {
+		t.Helper()
+		queue, err := ctx.QueueRepo.GetQueue(ctx.Context)
+		if err != nil {
+			t.Fatal(err)
+		}
+		position, err := ctx.QueueRepo.GetQueuePosition(ctx.Context)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return queue.TrackCount(), position
+	}
+
+	if err := ctx.QueueRepo.AddTracksToQueue(ctx.Context, []music.TrackID{music.NewTrackID("1010")}); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := queued()
+
+	// Blue in Green plays within Kind of Blue, after what was queued
+	play("1003")
+	if count, position := queued(); count != before+5 || position != before+2 {
+		t.Errorf("expected %d tracks from %d, got %d from %d", before+5, before+2, count, position)
+	}
+	player, _ := ctx.PlayerRepo.GetCurrentState(ctx.Context)
+	if !player.IsPlaying() || player.CurrentTrack.Value() != "1003" {
+		t.Errorf("expected 1003 playing, got %s %v", player.State, player.CurrentTrack)
+	}
+
+	play("--replace-queue", "1003")
+	if count, position := queued(); count != 5 || position != 2 {
+		t.Errorf("expected the album alone from 2, got %d tracks from %d", count, position)
+	}
+	player, _ = ctx.PlayerRepo.GetCurrentState(ctx.Context)
+	if player.CurrentTrack.Value() != "1003" {
+		t.Errorf("expected 1003 playing, got %v", player.CurrentTrack)
+	}
+}
+
+func TestParsePlayTarget(t *testing.T) {
+	tests := []struct {
+		args  []string
+		kind  playKind
+		query string
+	}{
+		{[]string{"Kind", "of", "Blue"}, playAny, "Kind of Blue"},
+		{[]string{`album:"Kind of Blue"`}, playAlbum, "Kind of Blue"},
+		{[]string{"album:Kind", "of", "Blue"}, playAlbum, "Kind of Blue"},
+		{[]string{`ALBUM: "Kind of Blue" `}, playAlbum, "Kind of Blue"},
+		{[]string{"playlist:'Album Openers'"}, playPlaylist, "Album Openers"},
+		{[]string{"artist:John", "Coltrane"}, playArtist, "John Coltrane"},
+		{[]string{"track:So What"}, playTrack, "So What"},
+		{[]string{"album:"}, playAlbum, ""},
+		{[]string{`album:""`}, playAlbum, ""},
+		{[]string{"genre:jazz"}, playAny, "genre:jazz"},
+		{nil, playAny, ""},
+	}
+	for _, tt := range tests {
+		kind, query := parsePlayTarget(tt.args)
+		if kind != tt.kind || query != tt.query {
+			t.Errorf("parsePlayTarget(%q) = %s %q, want %s %q", tt.args, kind, query, tt.kind, tt.query)
+		}
+	}
+}
+
+func TestResolvePlayTarget(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		kind   playKind
+		target string
+		tracks int
+		start  int
+	}{
+		{"quoted album", []string{`album:"Kind of Blue"`}, "", playAlbum, "Kind of Blue", 5, 0},
+		{"album over its title track", []string{"Blue", "Train"}, "", playAlbum, "Blue Train", 5, 0},
+		{"playlist", []string{"playlist:album openers"}, "", playPlaylist, "Album Openers", 5, 0},
+		{"artist", []string{"artist:john coltrane"}, "", playArtist, "John Coltrane", 12, 0},
+		{"track within its album", []string{"track:Take Five"}, "", playTrack, "Take Five - Dave Brubeck Quartet", 7, 2},
+		{"track ID within its album", []string{"1003"}, "", playTrack, "Blue in Green - Miles Davis", 5, 2},
+		{"track IDs in order", []string{"1010", "1001"}, "", playTrack, "", 2, 0},
+		{"track IDs from stdin", []string{"-"}, "1001 1002\n1003\n", playTrack, "", 3, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			cmd.SetIn(strings.NewReader(tt.stdin))
+
+			candidate, err := resolvePlayTarget(newPlayContext(), cmd, tt.args, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if candidate.kind != tt.kind || candidate.name != tt.target {
+				t.Errorf("resolved %s %q, want %s %q", candidate.kind, candidate.name, tt.kind, tt.target)
+			}
+			trackIDs, start, err := candidate.load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(trackIDs) != tt.tracks || start != tt.start {
+				t.Errorf("loaded %d tracks from %d, want %d from %d", len(trackIDs), start, tt.tracks, tt.start)
+			}
+		})
+	}
+}
+
+func TestResolvePlayTargetErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		code error
+	}{
+		{"nothing to look for", []string{`album:""`}, music.ErrInvalidSearchQuery},
+		{"no match", []string{"Bitches", "Brew"}, music.ErrTrackNotFound},
+		{"no playlist", []string{"playlist:Road Trip"}, music.ErrPlaylistNotFound},
+		{"no album", []string{"album:Bitches Brew"}, music.ErrTrackNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := resolvePlayTarget(newPlayContext(), &cobra.Command{}, tt.args, false)
+			if !errors.Is(err, tt.code) {
+				t.Errorf("expected %v, got %v", tt.code, err)
+			}
+		})
+	}
+}
+
+func TestResolvePlayTargetAmbiguous(t *testing.T) {
+	if term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stderr.Fd())) {
+		t.Skip("on a terminal, an ambiguous target asks which to play")
+	}
+	// "blue" starts an album and two track titles
+	args := []string{"blue"}
+
+	_, err := resolvePlayTarget(newPlayContext(), &cobra.Command{}, args, false)
+	if !errors.Is(err, music.ErrInvalidOperation) || !strings.Contains(err.Error(), "--first") {
+		t.Fatalf("expected an ambiguous target to be rejected off a terminal, got %v", err)
+	}
+
+	candidate, err := resolvePlayTarget(newPlayContext(), &cobra.Command{}, args, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if candidate.kind != playAlbum || candidate.name != "Blue Train" {
+		t.Errorf("expected --first to pick the album, which wins ties, got %s", candidate)
+	}
+}
+
+func TestPickPlayCandidate(t *testing.T) {
+	candidates := []playCandidate{
+		{kind: playAlbum, name: "Blue Train"},
+		{kind: playTrack, name: "Blue Train - John Coltrane"},
+		{kind: playTrack, name: "Blue in Green - Miles Davis"},
+	}
+
+	tests := []struct {
+		answer string
+		want   string
+	}{
+		{"\n", "Blue Train"},
+		{"2\n", "Blue Train - John Coltrane"},
+		{" 3 \n", "Blue in Green - Miles Davis"},
+	}
+	for _, tt := range tests {
+		var out strings.Builder
+		picked, err := pickPlayCandidate(strings.NewReader(tt.answer), &out, "blue", candidates)
+		if err != nil {
+			t.Errorf("answer %q: %v", tt.answer, err)
+			continue
+		}
+		if picked.name != tt.want {
+			t.Errorf("answer %q picked %q, want %q", tt.answer, picked.name, tt.want)
+		}
+		if !strings.Contains(out.String(), "  2) track 'Blue Train - John Coltrane'") {
+			t.Errorf("expected the candidates to be listed, got %q", out.String())
+		}
+	}
+
+	for _, answer := range []string{"0\n", "4\n", "two\n", ""} {
+		if _, err := pickPlayCandidate(strings.NewReader(answer), &strings.Builder{}, "blue", candidates); !errors.Is(err, music.ErrInvalidOperation) {
+			t.Errorf("answer %q: expected ErrInvalidOperation, got %v", answer, err)
+		}
+	}
+}
@@ -0,0 +1,531 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/madstone-tech/maestro/application/completion"
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/spf13/cobra"
+)
+
+// NewPlaylistCommand creates the playlist command group. Playlists are
+// selected by ID or by name; a name that is not exact may be any unambiguous
+// part of one. Track positions are 1-based, as printed by "playlist show".
+func NewPlaylistCommand(ctx *CommandContext) *cobra.Command {
+	playlistCmd := &cobra.Command{
+		Use:   "playlist",
+		Short: "Manage playlists",
+		Long: `List, inspect and edit playlists. Playlists are selected by ID or by name,
+and any unambiguous part of a name will do. Music.app's smart playlists and
+the library are read-only and are refused by the commands that edit
+playlists; "playlist smart" manages rule-based playlists maestro owns.`,
+	}
+
+	playlistCmd.AddCommand(NewPlaylistListCommand(ctx))
+	playlistCmd.AddCommand(NewPlaylistShowCommand(ctx))
+	playlistCmd.AddCommand(NewPlaylistCreateCommand(ctx))
+	playlistCmd.AddCommand(NewPlaylistRenameCommand(ctx))
+	playlistCmd.AddCommand(NewPlaylistDeleteCommand(ctx))
+	playlistCmd.AddCommand(NewPlaylistAddCommand(ctx))
+	playlistCmd.AddCommand(NewPlaylistRemoveCommand(ctx))
+	playlistCmd.AddCommand(NewPlaylistMoveCommand(ctx))
+	playlistCmd.AddCommand(NewPlaylistSwapCommand(ctx))
+	playlistCmd.AddCommand(NewPlaylistDuplicateCommand(ctx))
+	playlistCmd.AddCommand(NewPlaylistSmartCommand(ctx))
+
+	return playlistCmd
+}
+
+// NewPlaylistListCommand creates the playlist list command
+func NewPlaylistListCommand(ctx *CommandContext) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Args:  cobra.NoArgs,
+		Short: "List playlists with their track counts and durations",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing playlist list command")
+
+			playlists, err := ctx.LibraryRepo.GetPlaylists(ctx.Context)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			durations := make(map[string]music.Duration, len(playlists))
+			for _, playlist := range playlists {
+				tracks, err := ctx.LibraryRepo.GetPlaylistTracks(ctx.Context, playlist.ID)
+				if err != nil {
+					ctx.OutputFormatter.Error(err)
+					return err
+				}
+				durations[playlist.ID.Value()] = totalDuration(tracks)
+			}
+
+			ctx.OutputFormatter.PrintPlaylists(playlists, durations)
+			return nil
+		},
+	}
+}
+
+// NewPlaylistShowCommand creates the playlist show command
+func NewPlaylistShowCommand(ctx *CommandContext) *cobra.Command {
+	return &cobra.Command{
+		Use:               "show <playlist>",
+		Args:              cobra.MinimumNArgs(1),
+		Short:             "Show a playlist and its tracks",
+		ValidArgsFunction: completeArgs(ctx, completion.KindPlaylist),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing playlist show command")
+
+			playlist, err := resolvePlaylist(ctx, strings.Join(args, " "))
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+			return printPlaylist(ctx, playlist.ID)
+		},
+	}
+}
+
+// NewPlaylistCreateCommand creates the playlist create command
+func NewPlaylistCreateCommand(ctx *CommandContext) *cobra.Command {
+	return &cobra.Command{
+		Use:               "create <name>",
+		Args:              cobra.MinimumNArgs(1),
+		Short:             "Create an empty playlist",
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing playlist create command")
+
+			playlist, err := ctx.PlaylistRepo.CreatePlaylist(ctx.Context, strings.Join(args, " "))
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+			return printPlaylist(ctx, playlist.ID)
+		},
+	}
+}
+
+// NewPlaylistRenameCommand creates the playlist rename command
+func NewPlaylistRenameCommand(ctx *CommandContext) *cobra.Command {
+	return &cobra.Command{
+		Use:               "rename <playlist> <new-name>",
+		Args:              cobra.ExactArgs(2),
+		Short:             "Rename a playlist",
+		ValidArgsFunction: completeArgs(ctx, completion.KindPlaylist),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing playlist rename command")
+
+			playlist, err := resolveWritablePlaylist(ctx, args[0])
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			playlist.Name = args[1]
+			if err := ctx.PlaylistRepo.UpdatePlaylist(ctx.Context, playlist); err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+			return printPlaylist(ctx, playlist.ID)
+		},
+	}
+}
+
+// NewPlaylistDeleteCommand creates the playlist delete command
+func NewPlaylistDeleteCommand(ctx *CommandContext) *cobra.Command {
+	return &cobra.Command{
+		Use:               "delete <playlist>",
+		Args:              cobra.MinimumNArgs(1),
+		Short:             "Delete a playlist",
+		ValidArgsFunction: completeArgs(ctx, completion.KindPlaylist),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing playlist delete command")
+
+			playlist, err := resolveWritablePlaylist(ctx, strings.Join(args, " "))
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			if err := ctx.PlaylistRepo.DeletePlaylist(ctx.Context, playlist.ID); err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			ctx.OutputFormatter.Success(fmt.Sprintf("Deleted playlist '%s'", playlist.Name))
+			return nil
+		},
+	}
+}
+
+// NewPlaylistAddCommand creates the playlist add command
+func NewPlaylistAddCommand(ctx *CommandContext) *cobra.Command {
+	var limit int
+	var at string
+	var allowDuplicates bool
+
+	cmd := &cobra.Command{
+		Use:   "add <playlist> <track-id...|query...|->",
+		Args:  cobra.MinimumNArgs(2),
+		Short: "Add tracks to a playlist",
+		Long: `Add tracks to the end of a playlist, or before the 1-based position given
+with --at. A track already in the playlist is refused unless
+--allow-duplicates is set.`,
+		Example: `  maestro playlist add "Friday Mix" so what
+  maestro playlist add --at 1 "Friday Mix" blue in green
+  maestro search --ids --album "blue train" | maestro playlist add "Friday Mix" -`,
+		ValidArgsFunction: completeQuery(ctx, completion.KindPlaylist, completion.KindTrack),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing playlist add command")
+
+			playlist, err := resolveWritablePlaylist(ctx, args[0])
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			trackIDs, err := resolveTracks(ctx, args[1:], cmd.InOrStdin(), limit)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			if at == "" && !allowDuplicates {
+				for _, trackID := range trackIDs {
+					if err := ctx.PlaylistRepo.AddTrackToPlaylist(ctx.Context, playlist.ID, trackID); err != nil {
+						ctx.OutputFormatter.Error(err)
+						return err
+					}
+				}
+				return printPlaylist(ctx, playlist.ID)
+			}
+
+			position := len(playlist.Tracks)
+			if at != "" {
+				if position, err = parsePlaylistPosition(playlist, at, len(playlist.Tracks)+1); err != nil {
+					ctx.OutputFormatter.Error(err)
+					return err
+				}
+			}
+			playlist.AllowDuplicates = allowDuplicates
+			if err := playlist.InsertAt(position, trackIDs...); err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+			return savePlaylistTracks(ctx, playlist)
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 1, "Number of search matches to add (0 for all)")
+	cmd.Flags().StringVar(&at, "at", "", "1-based position to insert the tracks before")
+	cmd.Flags().BoolVar(&allowDuplicates, "allow-duplicates", false, "Add tracks that are already in the playlist")
+
+	return cmd
+}
+
+// NewPlaylistRemoveCommand creates the playlist remove command
+func NewPlaylistRemoveCommand(ctx *CommandContext) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <playlist> <position...|track-id...|query...|->",
+		Args:  cobra.MinimumNArgs(2),
+		Short: "Remove tracks from a playlist",
+		Long: `Remove tracks from a playlist, given by 1-based position or as for "add".
+Positions remove exactly the tracks there; a track given by ID or query that
+appears more than once loses its first appearance.`,
+		ValidArgsFunction: completeQuery(ctx, completion.KindPlaylist, completion.KindTrack),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing playlist remove command")
+
+			playlist, err := resolveWritablePlaylist(ctx, args[0])
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			positions, err := playlistPositions(playlist, args[1:])
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+			if positions != nil {
+				// Remove from the end so that earlier positions stay put
+				sort.Sort(sort.Reverse(sort.IntSlice(positions)))
+				for i, position := range positions {
+					if i > 0 && position == positions[i-1] {
+						continue
+					}
+					if _, err := playlist.RemoveAt(position); err != nil {
+						ctx.OutputFormatter.Error(err)
+						return err
+					}
+				}
+				return savePlaylistTracks(ctx, playlist)
+			}
+
+			trackIDs, err := resolveTracks(ctx, args[1:], cmd.InOrStdin(), 1)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			for _, trackID := range trackIDs {
+				if err := ctx.PlaylistRepo.RemoveTrackFromPlaylist(ctx.Context, playlist.ID, trackID); err != nil {
+					ctx.OutputFormatter.Error(err)
+					return err
+				}
+			}
+			return printPlaylist(ctx, playlist.ID)
+		},
+	}
+}
+
+// NewPlaylistMoveCommand creates the playlist move command
+func NewPlaylistMoveCommand(ctx *CommandContext) *cobra.Command {
+	return &cobra.Command{
+		Use:               "move <playlist> <from> <to>",
+		Args:              cobra.ExactArgs(3),
+		Short:             "Move a track to another position in a playlist",
+		ValidArgsFunction: completeArgs(ctx, completion.KindPlaylist),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing playlist move command")
+
+			playlist, err := resolveWritablePlaylist(ctx, args[0])
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			from, err := playlistPosition(playlist, args[1])
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+			to, err := playlistPosition(playlist, args[2])
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			if err := playlist.Move(from, to); err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+			return savePlaylistTracks(ctx, playlist)
+		},
+	}
+}
+
+// NewPlaylistSwapCommand creates the playlist swap command
+func NewPlaylistSwapCommand(ctx *CommandContext) *cobra.Command {
+	return &cobra.Command{
+		Use:               "swap <playlist> <position> <position>",
+		Args:              cobra.ExactArgs(3),
+		Short:             "Swap the tracks at two positions in a playlist",
+		ValidArgsFunction: completeArgs(ctx, completion.KindPlaylist),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing playlist swap command")
+
+			playlist, err := resolveWritablePlaylist(ctx, args[0])
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			i, err := playlistPosition(playlist, args[1])
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+			j, err := playlistPosition(playlist, args[2])
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			if err := playlist.Swap(i, j); err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+			return savePlaylistTracks(ctx, playlist)
+		},
+	}
+}
+
+// NewPlaylistDuplicateCommand creates the playlist duplicate command
+func NewPlaylistDuplicateCommand(ctx *CommandContext) *cobra.Command {
+	return &cobra.Command{
+		Use:   "duplicate <playlist> [new-name]",
+		Args:  cobra.RangeArgs(1, 2),
+		Short: "Copy a playlist into a new playlist",
+		Long: `Copy a playlist, including a read-only one, into a new user playlist.
+The copy is named "<name> copy" unless a name is given.`,
+		ValidArgsFunction: completeArgs(ctx, completion.KindPlaylist),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing playlist duplicate command")
+
+			source, err := resolvePlaylist(ctx, args[0])
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			name := source.Name + " copy"
+			if len(args) == 2 {
+				name = args[1]
+			}
+
+			playlist, err := ctx.PlaylistRepo.DuplicatePlaylist(ctx.Context, source.ID, name)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+			return printPlaylist(ctx, playlist.ID)
+		},
+	}
+}
+
+// printPlaylist prints a playlist and its tracks.
+func printPlaylist(ctx *CommandContext, playlistID music.PlaylistID) error {
+	playlist, err := ctx.LibraryRepo.GetPlaylist(ctx.Context, playlistID)
+	if err != nil {
+		ctx.OutputFormatter.Error(err)
+		return err
+	}
+
+	tracks, err := ctx.LibraryRepo.GetPlaylistTracks(ctx.Context, playlistID)
+	if err != nil {
+		ctx.OutputFormatter.Error(err)
+		return err
+	}
+
+	ctx.OutputFormatter.PrintPlaylist(playlist, tracks)
+	return nil
+}
+
+// resolvePlaylist finds a playlist by ID, by exact name, or by a part of
+// its name that matches no other playlist, all ignoring case.
+func resolvePlaylist(ctx *CommandContext, ref string) (*music.Playlist, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, music.NewDomainError(music.ErrInvalidPlaylistID, "playlist ID or name cannot be empty")
+	}
+
+	playlists, err := ctx.LibraryRepo.GetPlaylists(ctx.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, playlist := range playlists {
+		if strings.EqualFold(playlist.ID.Value(), ref) {
+			return playlist, nil
+		}
+	}
+	for _, playlist := range playlists {
+		if strings.EqualFold(playlist.Name, ref) {
+			return playlist, nil
+		}
+	}
+
+	var matches []*music.Playlist
+	for _, playlist := range playlists {
+		if strings.Contains(strings.ToLower(playlist.Name), strings.ToLower(ref)) {
+			matches = append(matches, playlist)
+		}
+	}
+
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return nil, music.NewDomainError(music.ErrPlaylistNotFound, fmt.Sprintf("no playlist matches %q", ref)).
+			WithContext("query", ref)
+	default:
+		names := make([]string, len(matches))
+		for i, playlist := range matches {
+			names[i] = fmt.Sprintf("'%s'", playlist.Name)
+		}
+		sort.Strings(names)
+		return nil, music.NewDomainError(music.ErrInvalidPlaylist,
+			fmt.Sprintf("%q matches %d playlists: %s", ref, len(matches), strings.Join(names, ", "))).
+			WithContext("query", ref)
+	}
+}
+
+// resolveWritablePlaylist finds a playlist as resolvePlaylist does and
+// refuses read-only ones before any change is attempted.
+func resolveWritablePlaylist(ctx *CommandContext, ref string) (*music.Playlist, error) {
+	playlist, err := resolvePlaylist(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	if playlist.ReadOnly || playlist.Type.IsReadOnly() {
+		return nil, music.NewDomainError(
+			music.ErrPlaylistReadOnly,
+			fmt.Sprintf("playlist '%s' is a %s playlist and cannot be modified", playlist.Name, playlist.Type),
+		).WithContext("playlist_id", playlist.ID.Value())
+	}
+	return playlist, nil
+}
+
+// playlistPositions converts 1-based position arguments into checked 0-based
+// indexes. It returns nil when any argument is not a number, so that the
+// arguments can be resolved as tracks instead.
+func playlistPositions(playlist *music.Playlist, args []string) ([]int, error) {
+	for _, arg := range args {
+		if _, err := strconv.Atoi(arg); err != nil {
+			return nil, nil
+		}
+	}
+
+	positions := make([]int, len(args))
+	for i, arg := range args {
+		position, err := playlistPosition(playlist, arg)
+		if err != nil {
+			return nil, err
+		}
+		positions[i] = position
+	}
+	return positions, nil
+}
+
+// playlistPosition converts a 1-based position argument into a checked
+// 0-based index into the playlist.
+func playlistPosition(playlist *music.Playlist, arg string) (int, error) {
+	return parsePlaylistPosition(playlist, arg, len(playlist.Tracks))
+}
+
+// parsePlaylistPosition converts a 1-based position argument into a 0-based
+// index below count.
+func parsePlaylistPosition(playlist *music.Playlist, arg string, count int) (int, error) {
+	position, err := strconv.Atoi(arg)
+	if err != nil || position < 1 || position > count {
+		return 0, music.NewDomainError(music.ErrInvalidPlaylistPosition,
+			fmt.Sprintf("invalid position %q: '%s' has %d tracks", arg, playlist.Name, len(playlist.Tracks))).
+			WithContext("playlist_id", playlist.ID.Value())
+	}
+	return position - 1, nil
+}
+
+// savePlaylistTracks writes the tracks of an edited playlist back and prints
+// the result.
+func savePlaylistTracks(ctx *CommandContext, playlist *music.Playlist) error {
+	if err := ctx.PlaylistRepo.ReorderPlaylistTracks(ctx.Context, playlist.ID, playlist.Tracks); err != nil {
+		ctx.OutputFormatter.Error(err)
+		return err
+	}
+	return printPlaylist(ctx, playlist.ID)
+}
+
+// totalDuration adds up the durations of tracks.
+func totalDuration(tracks []*music.Track) music.Duration {
+	var total music.Duration
+	for _, track := range tracks {
+		total = total.Add(track.Duration)
+	}
+	return total
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/infrastructure/memory"
+)
+
+func newPlaylistContext() *CommandContext {
+	ctx := newPlayContext()
+	ctx.PlaylistRepo = ctx.LibraryRepo.(*memory.Repositories)
+	return ctx
+}
+
+func TestPlaylistCommands(t *testing.T) {
+	ctx := newPlaylistContext()
+	run := func(args ...string) string {
+		t.Helper()
+		out, err := runCommand(ctx, NewPlaylistCommand(ctx), args...)
+		if err != nil {
+			t.Fatalf("playlist %v: %v", args, err)
+		}
+		return out
+	}
+	playlist := func(ref string) *music.Playlist {
+		t.Helper()
+		playlist, err := resolvePlaylist(ctx, ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return playlist
+	}
+	expectTracks := func(ref string, want ...string) {
+		t.Helper()
+		var got []string
+		for _, trackID := range playlist(ref).Tracks {
+			got = append(got, trackID.Value())
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s holds %v, want %v", ref, got, want)
+		}
+	}
+
+	run("create", "Friday", "Mix")
+	expectTracks("Friday Mix")
+
+	run("add", "friday", "1001", "1002")
+	run("add", "--allow-duplicates", "friday", "1001")
+	run("add", "--at", "1", "friday", "blue", "in", "green")
+	expectTracks("friday", "1003", "1001", "1002", "1001")
+
+	// Positions remove exactly those tracks, a query the first appearance
+	run("remove", "friday", "4")
+	run("remove", "friday", "so", "what")
+	expectTracks("friday", "1003", "1002")
+
+	run("move", "friday", "1", "2")
+	expectTracks("friday", "1002", "1003")
+	run("swap", "friday", "1", "2")
+	expectTracks("friday", "1003", "1002")
+
+	out := run("show", "friday")
+	if !strings.Contains(out, "Blue in Green") || !strings.Contains(out, "Freddie Freeloader") {
+		t.Errorf("expected the playlist's tracks, got %q", out)
+	}
+
+	run("rename", "friday", "Saturday Mix")
+	if name := playlist("saturday").Name; name != "Saturday Mix" {
+		t.Errorf("expected the playlist renamed, got %q", name)
+	}
+
+	run("duplicate", "long players")
+	if copied := playlist("Long Players copy"); copied.ReadOnly || len(copied.Tracks) != len(playlist("Long Players").Tracks) {
+		t.Errorf("expected a writable copy of Long Players, got %+v", copied)
+	}
+
+	run("delete", "saturday")
+	if _, err := resolvePlaylist(ctx, "saturday"); !errors.Is(err, music.ErrPlaylistNotFound) {
+		t.Errorf("expected the playlist deleted, got %v", err)
+	}
+
+	out = run("list")
+	if !strings.Contains(out, "Album Openers") || !strings.Contains(out, "Long Players copy") {
+		t.Errorf("expected the playlists listed, got %q", out)
+	}
+}
+
+func TestPlaylistCommandErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		code error
+	}{
+		{"no match", []string{"show", "road", "trip"}, music.ErrPlaylistNotFound},
+		{"ambiguous name", []string{"show", "l"}, music.ErrInvalidPlaylist},
+		{"read-only playlist", []string{"add", "long players", "1001"}, music.ErrPlaylistReadOnly},
+		{"duplicate track", []string{"add", "openers", "1001"}, music.ErrTrackAlreadyInPlaylist},
+		{"position past the end", []string{"move", "openers", "1", "6"}, music.ErrInvalidPlaylistPosition},
+		{"insert past the end", []string{"add", "--at", "7", "openers", "1002"}, music.ErrInvalidPlaylistPosition},
+		{"position before the start", []string{"remove", "openers", "0"}, music.ErrInvalidPlaylistPosition},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newPlaylistContext()
+			if _, err := runCommand(ctx, NewPlaylistCommand(ctx), tt.args...); !errors.Is(err, tt.code) {
+				t.Errorf("expected %v, got %v", tt.code, err)
+			}
+		})
+	}
+}
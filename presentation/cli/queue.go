@@ -0,0 +1,300 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/madstone-tech/maestro/application/completion"
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/spf13/cobra"
+)
+
+// NewQueueCommand creates the queue command group. Positions on the command
+// line are 1-based, as printed by "maestro queue show".
+func NewQueueCommand(ctx *CommandContext) *cobra.Command {
+	queueCmd := &cobra.Command{
+		Use:   "queue",
+		Short: "Manage what plays next",
+		Long: `Show and edit the play queue. Commands that take tracks accept track IDs,
+a search expression (the best match is used unless --limit says otherwise),
+or - to read track IDs from stdin, e.g. from "maestro search --ids".`,
+	}
+
+	queueCmd.AddCommand(NewQueueShowCommand(ctx))
+	queueCmd.AddCommand(NewQueueAddCommand(ctx))
+	queueCmd.AddCommand(NewQueueNextCommand(ctx))
+	queueCmd.AddCommand(NewQueueRemoveCommand(ctx))
+	queueCmd.AddCommand(NewQueueClearCommand(ctx))
+	queueCmd.AddCommand(NewQueueShuffleCommand(ctx))
+	queueCmd.AddCommand(NewQueueJumpCommand(ctx))
+	queueCmd.AddCommand(NewQueueUpNextCommand(ctx))
+
+	return queueCmd
+}
+
+// NewQueueShowCommand creates the queue show command
+func NewQueueShowCommand(ctx *CommandContext) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show",
+		Args:  cobra.NoArgs,
+		Short: "Show the queue",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing queue show command")
+			return printQueue(ctx)
+		},
+	}
+}
+
+// NewQueueAddCommand creates the queue add command
+func NewQueueAddCommand(ctx *CommandContext) *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "add <track-id...|query...|->",
+		Short: "Add tracks to the end of the queue",
+		Example: `  maestro queue add 1A2B3C4D5E6F7A8B
+  maestro queue add so what
+  maestro search --ids --artist coltrane | maestro queue add -`,
+		ValidArgsFunction: completeQuery(ctx, completion.KindTrack),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing queue add command")
+
+			trackIDs, err := resolveTracks(ctx, args, cmd.InOrStdin(), limit)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			if err := ctx.QueueRepo.AddTracksToQueue(ctx.Context, trackIDs); err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			return printQueue(ctx)
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 1, "Number of search matches to add (0 for all)")
+
+	return cmd
+}
+
+// NewQueueNextCommand creates the queue next command
+func NewQueueNextCommand(ctx *CommandContext) *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:               "next <track-id...|query...|->",
+		Short:             "Play tracks right after the current one",
+		ValidArgsFunction: completeQuery(ctx, completion.KindTrack),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing queue next command")
+
+			trackIDs, err := resolveTracks(ctx, args, cmd.InOrStdin(), limit)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			// Each track is inserted right after the current one, so insert
+			// them last to first to keep the order they were given in
+			for i := len(trackIDs) - 1; i >= 0; i-- {
+				if err := ctx.QueueRepo.PlayNext(ctx.Context, trackIDs[i]); err != nil {
+					ctx.OutputFormatter.Error(err)
+					return err
+				}
+			}
+
+			return printQueue(ctx)
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 1, "Number of search matches to queue (0 for all)")
+
+	return cmd
+}
+
+// NewQueueRemoveCommand creates the queue remove command
+func NewQueueRemoveCommand(ctx *CommandContext) *cobra.Command {
+	return &cobra.Command{
+		Use:               "remove <position|track-id|query...>",
+		Short:             "Remove a track from the queue",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeQuery(ctx, completion.KindTrack),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing queue remove command")
+
+			position, err := resolveQueuePosition(ctx, args, cmd)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			if err := ctx.QueueRepo.RemoveFromQueue(ctx.Context, position); err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			return printQueue(ctx)
+		},
+	}
+}
+
+// NewQueueClearCommand creates the queue clear command
+func NewQueueClearCommand(ctx *CommandContext) *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear",
+		Args:  cobra.NoArgs,
+		Short: "Remove every track from the queue",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing queue clear command")
+
+			if err := ctx.QueueRepo.ClearQueue(ctx.Context); err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			return printQueue(ctx)
+		},
+	}
+}
+
+// NewQueueShuffleCommand creates the queue shuffle command
+func NewQueueShuffleCommand(ctx *CommandContext) *cobra.Command {
+	return &cobra.Command{
+		Use:   "shuffle",
+		Args:  cobra.NoArgs,
+		Short: "Shuffle the tracks that have not played yet",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing queue shuffle command")
+
+			if err := ctx.QueueRepo.ShuffleQueue(ctx.Context); err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			return printQueue(ctx)
+		},
+	}
+}
+
+// NewQueueJumpCommand creates the queue jump command
+func NewQueueJumpCommand(ctx *CommandContext) *cobra.Command {
+	return &cobra.Command{
+		Use:               "jump <position|track-id|query...>",
+		Short:             "Start playing the queue from a track",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeQuery(ctx, completion.KindTrack),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing queue jump command")
+
+			position, err := resolveQueuePosition(ctx, args, cmd)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			if err := ctx.QueueRepo.SetQueuePosition(ctx.Context, position); err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			return printQueue(ctx)
+		},
+	}
+}
+
+// NewQueueUpNextCommand creates the queue upnext command
+func NewQueueUpNextCommand(ctx *CommandContext) *cobra.Command {
+	return &cobra.Command{
+		Use:   "upnext [count]",
+		Short: "Show the tracks that will play next",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing queue upnext command")
+
+			count := 10
+			if len(args) == 1 {
+				n, err := strconv.Atoi(args[0])
+				if err != nil || n < 0 {
+					err = music.NewDomainError(music.ErrInvalidOperation, fmt.Sprintf("invalid count %q", args[0]))
+					ctx.OutputFormatter.Error(err)
+					return err
+				}
+				count = n
+			}
+
+			tracks, err := ctx.QueueRepo.GetUpNext(ctx.Context, count)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			position, err := ctx.QueueRepo.GetQueuePosition(ctx.Context)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			ctx.OutputFormatter.PrintQueue(tracks, -1, position+1)
+			return nil
+		},
+	}
+}
+
+// printQueue prints the whole queue, marking the current track.
+func printQueue(ctx *CommandContext) error {
+	queue, err := ctx.QueueRepo.GetQueue(ctx.Context)
+	if err != nil {
+		ctx.OutputFormatter.Error(err)
+		return err
+	}
+
+	position, err := ctx.QueueRepo.GetQueuePosition(ctx.Context)
+	if err != nil {
+		ctx.OutputFormatter.Error(err)
+		return err
+	}
+
+	tracks, err := ctx.LibraryRepo.GetTracks(ctx.Context, queue.Tracks)
+	if err != nil {
+		ctx.OutputFormatter.Error(err)
+		return err
+	}
+
+	ctx.OutputFormatter.PrintQueue(tracks, position, 0)
+	return nil
+}
+
+// resolveQueuePosition turns a 1-based position, or tracks given as for
+// resolveTracks, into a 0-based queue position. The best matching track
+// that is queued wins, at its first appearance in the queue.
+func resolveQueuePosition(ctx *CommandContext, args []string, cmd *cobra.Command) (int, error) {
+	if len(args) == 1 {
+		if n, err := strconv.Atoi(args[0]); err == nil {
+			if n < 1 {
+				return 0, music.NewDomainError(music.ErrInvalidQueuePosition, "queue positions start at 1").
+					WithContext("position", n)
+			}
+			return n - 1, nil
+		}
+	}
+
+	trackIDs, err := resolveTracks(ctx, args, cmd.InOrStdin(), 0)
+	if err != nil {
+		return 0, err
+	}
+
+	queue, err := ctx.QueueRepo.GetQueue(ctx.Context)
+	if err != nil {
+		return 0, err
+	}
+	for _, trackID := range trackIDs {
+		for position, queued := range queue.Tracks {
+			if queued.Equals(trackID) {
+				return position, nil
+			}
+		}
+	}
+	return 0, music.NewDomainError(music.ErrTrackNotFound, "no matching track in the queue")
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+func TestQueueCommands(t *testing.T) {
+	ctx := newPlayContext()
+	run := func(args ...string) string {
+		t.Helper()
+		out, err := runCommand(ctx, NewQueueCommand(ctx), args...)
+		if err != nil {
+			t.Fatalf("queue %v: %v", args, err)
+		}
+		return out
+	}
+	expectQueue := func(want ...string) {
+		t.Helper()
+		queue, err := ctx.QueueRepo.GetQueue(ctx.Context)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, trackID := range queue.Tracks {
+			got = append(got, trackID.Value())
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("queue is %v, want %v", got, want)
+		}
+	}
+
+	run("clear")
+	expectQueue()
+
+	run("add", "1001", "1002")
+	run("add", "giant", "steps")
+	cmd := NewQueueCommand(ctx)
+	cmd.SetIn(strings.NewReader("1020\n1021\n"))
+	if _, err := runCommand(ctx, cmd, "add", "-"); err != nil {
+		t.Fatal(err)
+	}
+	expectQueue("1001", "1002", "1011", "1020", "1021")
+
+	run("jump", "2")
+	if position, _ := ctx.QueueRepo.GetQueuePosition(ctx.Context); position != 1 {
+		t.Errorf("expected to jump to position 1, got %d", position)
+	}
+
+	// Tracks played next keep the order they were given in
+	run("next", "1030", "1029")
+	expectQueue("1001", "1002", "1030", "1029", "1011", "1020", "1021")
+
+	out := run("upnext", "2")
+	if !strings.Contains(out, "Milestones") || !strings.Contains(out, "Some Other Time") || strings.Contains(out, "Giant Steps") {
+		t.Errorf("expected the next two tracks, got %q", out)
+	}
+
+	// A lone number is a position, anything else names a queued track
+	run("remove", "giant", "steps")
+	run("remove", "1")
+	expectQueue("1002", "1030", "1029", "1020", "1021")
+
+	// Jumping past tracks queued to play next drops them
+	run("jump", "take", "five")
+	if position, _ := ctx.QueueRepo.GetQueuePosition(ctx.Context); position != 1 {
+		t.Errorf("expected to jump to Take Five at position 1, got %d", position)
+	}
+	expectQueue("1002", "1020", "1021")
+
+	out = run("show")
+	if !strings.Contains(out, "Freddie Freeloader") || !strings.Contains(out, "Take Five") {
+		t.Errorf("expected the queue to be shown, got %q", out)
+	}
+}
+
+func TestQueueCommandErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		code error
+	}{
+		{"position before the start", []string{"jump", "0"}, music.ErrInvalidQueuePosition},
+		{"track not queued", []string{"remove", "naima"}, music.ErrTrackNotFound},
+		{"invalid count", []string{"upnext", "many"}, music.ErrInvalidOperation},
+		{"negative count", []string{"upnext", "--", "-1"}, music.ErrInvalidOperation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newPlayContext()
+			if _, err := runCommand(ctx, NewQueueCommand(ctx), "add", "1001", "1002"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := runCommand(ctx, NewQueueCommand(ctx), tt.args...); !errors.Is(err, tt.code) {
+				t.Errorf("expected %v, got %v", tt.code, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/madstone-tech/maestro/application/completion"
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/spf13/cobra"
+)
+
+// NewRateCommand creates the rate command
+func NewRateCommand(ctx *CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rate <stars> [track-id...|query...|-]",
+		Args:  cobra.MinimumNArgs(1),
+		Short: "Rate the current track or the given tracks",
+		Long: `Give the current track, or the tracks named by IDs or a search expression,
+a rating from 0 to 5 stars. A rating of 0 clears it.`,
+		Example: `  maestro rate 4
+  maestro rate 5 so what
+  maestro rate 0 1A2B3C4D5E6F7A8B
+  maestro rate list 4`,
+		ValidArgsFunction: completeRating(ctx),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing rate command")
+
+			rating, err := music.ParseStars(args[0])
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			tracks, err := ratingTargets(ctx, args[1:], cmd.InOrStdin())
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			for _, track := range tracks {
+				if err := ctx.RatingRepo.SetRating(ctx.Context, track.ID, rating); err != nil {
+					ctx.OutputFormatter.Error(err)
+					return err
+				}
+			}
+
+			if rating.IsZero() {
+				ctx.OutputFormatter.Success("Cleared the rating of " + describeTracks(tracks))
+			} else {
+				ctx.OutputFormatter.Success(fmt.Sprintf("Rated %s %s", describeTracks(tracks), rating))
+			}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(NewRateListCommand(ctx))
+
+	return cmd
+}
+
+// NewRateListCommand creates the rate list command
+func NewRateListCommand(ctx *CommandContext) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list [stars|min-max]",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "List rated tracks, best rated first",
+		Long: `List the tracks rated at least the given number of stars, or between two
+ratings, best rated first. With no argument every rated track is listed.`,
+		Example: `  maestro rate list
+  maestro rate list 4
+  maestro rate list 2-3`,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing rate list command")
+
+			arg := "1"
+			if len(args) == 1 {
+				arg = args[0]
+			}
+			minimum, maximum, err := parseRatingRange(arg)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			tracks, err := ctx.RatingRepo.GetTracksByRating(ctx.Context, minimum, maximum)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			ctx.OutputFormatter.PrintRatedTracks(tracks)
+			return nil
+		},
+	}
+}
+
+// NewLoveCommand creates the love command
+func NewLoveCommand(ctx *CommandContext) *cobra.Command {
+	return newPreferenceCommand(ctx, music.PreferenceLoved, "love", "Love the current track or the given tracks")
+}
+
+// NewDislikeCommand creates the dislike command
+func NewDislikeCommand(ctx *CommandContext) *cobra.Command {
+	return newPreferenceCommand(ctx, music.PreferenceDisliked, "dislike", "Dislike the current track or the given tracks")
+}
+
+// newPreferenceCommand creates a command that sets preference, or clears it
+// with --clear
+func newPreferenceCommand(ctx *CommandContext, preference music.Preference, name, short string) *cobra.Command {
+	var clear bool
+
+	cmd := &cobra.Command{
+		Use:   name + " [track-id...|query...|-]",
+		Short: short,
+		Long: fmt.Sprintf(`Mark the current track, or the tracks named by IDs or a search expression,
+as %s. Loving a track clears a dislike and disliking it clears a love.
+With --clear the track is neither loved nor disliked.`, preference),
+		Example: fmt.Sprintf(`  maestro %[1]s
+  maestro %[1]s so what
+  maestro %[1]s --clear`, name),
+		ValidArgsFunction: completeQuery(ctx, completion.KindTrack),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing " + name + " command")
+
+			tracks, err := ratingTargets(ctx, args, cmd.InOrStdin())
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			target := preference
+			if clear {
+				target = music.PreferenceNone
+			}
+			for _, track := range tracks {
+				// --clear only undoes this command's preference
+				if clear && track.Preference() != preference {
+					continue
+				}
+				if err := ctx.RatingRepo.SetPreference(ctx.Context, track.ID, target); err != nil {
+					ctx.OutputFormatter.Error(err)
+					return err
+				}
+			}
+
+			if clear {
+				ctx.OutputFormatter.Success(fmt.Sprintf("No longer marking %s as %s", describeTracks(tracks), preference))
+			} else {
+				ctx.OutputFormatter.Success(fmt.Sprintf("Marked %s as %s", describeTracks(tracks), preference))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&clear, "clear", false, fmt.Sprintf("Stop marking the tracks as %s", preference))
+
+	return cmd
+}
+
+// ratingTargets returns the tracks a rating command applies to: the current
+// track when args is empty, otherwise the tracks args resolve to, taking
+// only the best match of a search expression.
+func ratingTargets(ctx *CommandContext, args []string, stdin io.Reader) ([]*music.Track, error) {
+	if len(args) == 0 {
+		track, err := ctx.PlayerRepo.GetCurrentTrack(ctx.Context)
+		if err != nil {
+			return nil, err
+		}
+		if track == nil {
+			return nil, music.NewDomainError(music.ErrInvalidPlayerState, "no track is loaded: name the tracks to rate")
+		}
+		return []*music.Track{track}, nil
+	}
+
+	trackIDs, err := resolveTracks(ctx, args, stdin, 1)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.LibraryRepo.GetTracks(ctx.Context, trackIDs)
+}
+
+// parseRatingRange parses "stars" as at least that many stars, up to five,
+// or "min-max" as a range of stars.
+func parseRatingRange(arg string) (music.Rating, music.Rating, error) {
+	low, high, isRange := strings.Cut(arg, "-")
+	minimum, err := music.ParseStars(low)
+	if err != nil {
+		return music.Rating{}, music.Rating{}, err
+	}
+	maximum := music.NewRating(music.MaxRating)
+	if isRange {
+		if maximum, err = music.ParseStars(high); err != nil {
+			return music.Rating{}, music.Rating{}, err
+		}
+	}
+	return minimum, maximum, music.CheckRatingRange(minimum, maximum)
+}
+
+// describeTracks names a single track, or counts several.
+func describeTracks(tracks []*music.Track) string {
+	if len(tracks) == 1 {
+		return fmt.Sprintf("%q by %s", tracks[0].Title, tracks[0].Artist)
+	}
+	return fmt.Sprintf("%d tracks", len(tracks))
+}
+
+// completeRating completes the number of stars, then track queries.
+func completeRating(ctx *CommandContext) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	tracks := completeQuery(ctx, completion.KindTrack)
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return []string{"0", "1", "2", "3", "4", "5"}, cobra.ShellCompDirectiveNoFileComp
+		}
+		return tracks(cmd, args[1:], toComplete)
+	}
+}
@@ -0,0 +1,270 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"gopkg.in/yaml.v3"
+)
+
+// OutputFormat selects how command output is rendered.
+type OutputFormat int
+
+const (
+	// OutputTable is the human-readable layout
+	OutputTable OutputFormat = iota
+
+	// OutputJSON is indented JSON
+	OutputJSON
+
+	// OutputNDJSON is one compact JSON document per line, one per item for lists
+	OutputNDJSON
+
+	// OutputYAML is YAML with the same field names as JSON
+	OutputYAML
+
+	// OutputCSV is a header row followed by one row per item
+	OutputCSV
+
+	// OutputTemplate executes a Go template, once per item for lists
+	OutputTemplate
+)
+
+// String returns the string representation of the OutputFormat.
+func (f OutputFormat) String() string {
+	switch f {
+	case OutputTable:
+		return "table"
+	case OutputJSON:
+		return "json"
+	case OutputNDJSON:
+		return "ndjson"
+	case OutputYAML:
+		return "yaml"
+	case OutputCSV:
+		return "csv"
+	case OutputTemplate:
+		return "template"
+	default:
+		return "unknown"
+	}
+}
+
+// IsValid returns true if the OutputFormat is a valid value.
+func (f OutputFormat) IsValid() bool {
+	return f >= OutputTable && f <= OutputTemplate
+}
+
+// OutputFormats returns every valid OutputFormat in declaration order.
+func OutputFormats() []OutputFormat {
+	return []OutputFormat{OutputTable, OutputJSON, OutputNDJSON, OutputYAML, OutputCSV, OutputTemplate}
+}
+
+// ParseOutputFormat converts a name such as "yaml" into an OutputFormat.
+func ParseOutputFormat(name string) (OutputFormat, error) {
+	for _, format := range OutputFormats() {
+		if strings.EqualFold(strings.TrimSpace(name), format.String()) {
+			return format, nil
+		}
+	}
+	return OutputTable, music.NewDomainError(music.ErrInvalidOperation,
+		fmt.Sprintf("unknown output format %q (expected table, json, ndjson, yaml, csv or template)", name))
+}
+
+// Renderer writes view models in one output format.
+type Renderer interface {
+	// Render writes a view model to w
+	Render(w io.Writer, v interface{}) error
+}
+
+// NewRenderer creates the renderer for format. text is the template for
+// OutputTemplate and must be empty for every other format.
+func NewRenderer(format OutputFormat, text string) (Renderer, error) {
+	if format != OutputTemplate && text != "" {
+		return nil, music.NewDomainError(music.ErrInvalidOperation, "--template requires --output template")
+	}
+
+	switch format {
+	case OutputTable:
+		return tableRenderer{}, nil
+	case OutputJSON:
+		return jsonRenderer{}, nil
+	case OutputNDJSON:
+		return ndjsonRenderer{}, nil
+	case OutputYAML:
+		return yamlRenderer{}, nil
+	case OutputCSV:
+		return csvRenderer{}, nil
+	case OutputTemplate:
+		return newTemplateRenderer(text)
+	default:
+		return nil, music.NewDomainError(music.ErrInvalidOperation, "unknown output format")
+	}
+}
+
+// asView returns v as a view, or an error for values that only the
+// structured formats can render.
+func asView(v interface{}, format OutputFormat) (view, error) {
+	if vv, ok := v.(view); ok {
+		return vv, nil
+	}
+	return nil, music.NewDomainError(music.ErrInvalidOperation,
+		fmt.Sprintf("this output cannot be rendered as %s", format))
+}
+
+// tableRenderer writes the human-readable layout of each view.
+type tableRenderer struct{}
+
+func (tableRenderer) Render(w io.Writer, v interface{}) error {
+	vv, err := asView(v, OutputTable)
+	if err != nil {
+		return err
+	}
+	return vv.writeText(w)
+}
+
+// jsonRenderer writes indented JSON.
+type jsonRenderer struct{}
+
+func (jsonRenderer) Render(w io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// ndjsonRenderer writes one compact JSON document per line.
+type ndjsonRenderer struct{}
+
+func (ndjsonRenderer) Render(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	for _, item := range itemsOf(v) {
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// yamlRenderer writes YAML. Values go through JSON first so that YAML uses
+// the same field names and formatting as JSON.
+type yamlRenderer struct{}
+
+func (yamlRenderer) Render(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// blockStyle clears the JSON flow and quoting styles from a decoded
+// document so that it is written as plain block YAML. Strings with a colon,
+// such as "9:22", stay quoted: YAML 1.1 readers take them for numbers.
+func blockStyle(node *yaml.Node) {
+	if node.Kind != yaml.ScalarNode || node.Tag != "!!str" || !strings.Contains(node.Value, ":") {
+		node.Style = 0
+	}
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// csvRenderer writes a header row followed by the view's rows.
+type csvRenderer struct{}
+
+func (csvRenderer) Render(w io.Writer, v interface{}) error {
+	vv, err := asView(v, OutputCSV)
+	if err != nil {
+		return err
+	}
+
+	header, rows := vv.records()
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// templateFuncs are the functions available to --template.
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"truncate": func(n int, s string) string {
+		if runes := []rune(s); n >= 0 && len(runes) > n {
+			return string(runes[:n])
+		}
+		return s
+	},
+}
+
+// templateRenderer executes a Go template, once per item for lists, and
+// ends each result with a newline.
+type templateRenderer struct {
+	template *template.Template
+}
+
+func newTemplateRenderer(text string) (Renderer, error) {
+	if text == "" {
+		return nil, music.NewDomainError(music.ErrInvalidOperation, "--output template requires --template")
+	}
+
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, music.NewDomainErrorWithCause(music.ErrInvalidOperation, "invalid --template", err)
+	}
+	return templateRenderer{template: tmpl}, nil
+}
+
+func (r templateRenderer) Render(w io.Writer, v interface{}) error {
+	for _, item := range itemsOf(v) {
+		var out bytes.Buffer
+		if err := r.template.Execute(&out, item); err != nil {
+			return music.NewDomainErrorWithCause(music.ErrInvalidOperation, "failed to execute --template", err)
+		}
+		if !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+			out.WriteByte('\n')
+		}
+		if _, err := w.Write(out.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// itemsOf returns the items of a list view, or v itself.
+func itemsOf(v interface{}) []interface{} {
+	if list, ok := v.(listView); ok {
+		return list.items()
+	}
+	return []interface{}{v}
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+func TestParseOutputFormat(t *testing.T) {
+	for _, format := range OutputFormats() {
+		parsed, err := ParseOutputFormat(" " + strings.ToUpper(format.String()) + " ")
+		if err != nil || parsed != format {
+			t.Errorf("ParseOutputFormat(%q) = %v, %v", format, parsed, err)
+		}
+	}
+	if _, err := ParseOutputFormat("xml"); !errors.Is(err, music.ErrInvalidOperation) {
+		t.Errorf("expected ErrInvalidOperation, got %v", err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	message := &MessageView{Success: true, Message: "Volume set to 40%"}
+	ids := TrackIDsView{"1001", "1002"}
+
+	tests := []struct {
+		name     string
+		format   OutputFormat
+		template string
+		value    interface{}
+		want     string
+	}{
+		{"table", OutputTable, "", message, "Volume set to 40%\n"},
+		{"json", OutputJSON, "", message, "{\n  \"success\": true,\n  \"message\": \"Volume set to 40%\"\n}\n"},
+		{"ndjson", OutputNDJSON, "", message, "{\"success\":true,\"message\":\"Volume set to 40%\"}\n"},
+		{"yaml", OutputYAML, "", message, "success: true\nmessage: Volume set to 40%\n"},
+		{"csv", OutputCSV, "", message, "success,message\ntrue,Volume set to 40%\n"},
+		{"template", OutputTemplate, "{{.Message | upper}}", message, "VOLUME SET TO 40%\n"},
+		{"table list", OutputTable, "", ids, "1001\n1002\n"},
+		{"json list", OutputJSON, "", ids, "[\n  \"1001\",\n  \"1002\"\n]\n"},
+		{"ndjson list", OutputNDJSON, "", ids, "\"1001\"\n\"1002\"\n"},
+		{"yaml list", OutputYAML, "", ids, "- \"1001\"\n- \"1002\"\n"},
+		{"csv list", OutputCSV, "", ids, "id\n1001\n1002\n"},
+		{"template list", OutputTemplate, "id={{.}}\n", ids, "id=1001\nid=1002\n"},
+		{"yaml clock time", OutputYAML, "", map[string]string{"duration": "9:22"}, "duration: \"9:22\"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer, err := NewRenderer(tt.format, tt.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out strings.Builder
+			if err := renderer.Render(&out, tt.value); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("got %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	if _, err := NewRenderer(OutputJSON, "{{.}}"); !errors.Is(err, music.ErrInvalidOperation) {
+		t.Errorf("expected --template without --output template to be rejected, got %v", err)
+	}
+	if _, err := NewRenderer(OutputTemplate, ""); !errors.Is(err, music.ErrInvalidOperation) {
+		t.Errorf("expected --output template without --template to be rejected, got %v", err)
+	}
+	if _, err := NewRenderer(OutputTemplate, "{{.Message"); !errors.Is(err, music.ErrInvalidOperation) {
+		t.Errorf("expected an unparsable template to be rejected, got %v", err)
+	}
+
+	renderer, err := NewRenderer(OutputTemplate, "{{.Missing}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := renderer.Render(&strings.Builder{}, &MessageView{}); !errors.Is(err, music.ErrInvalidOperation) {
+		t.Errorf("expected a failing template to be reported, got %v", err)
+	}
+
+	// Only views have a table and CSV layout
+	for _, format := range []OutputFormat{OutputTable, OutputCSV} {
+		renderer, _ := NewRenderer(format, "")
+		if err := renderer.Render(&strings.Builder{}, map[string]int{"level": 40}); !errors.Is(err, music.ErrInvalidOperation) {
+			t.Errorf("%s: expected a plain value to be rejected, got %v", format, err)
+		}
+	}
+}
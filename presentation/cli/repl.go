@@ -0,0 +1,361 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// REPLConfig holds configuration for the interactive shell.
+type REPLConfig struct {
+	// HistoryFile is where entered lines are persisted ("" disables persistence)
+	HistoryFile string
+
+	// HistorySize is the number of lines kept in the history
+	HistorySize int
+
+	// StatusTimeout bounds the player query made for each prompt
+	StatusTimeout time.Duration
+
+	// CompletionTimeout bounds the library queries made for tab completion
+	CompletionTimeout time.Duration
+}
+
+// DefaultREPLConfig returns the default shell configuration.
+func DefaultREPLConfig() *REPLConfig {
+	config := &REPLConfig{
+		HistorySize:       1000,
+		StatusTimeout:     2 * time.Second,
+		CompletionTimeout: 2 * time.Second,
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		config.HistoryFile = filepath.Join(home, ".maestro_history")
+	}
+	return config
+}
+
+// REPL is an interactive shell that runs maestro commands against a single
+// CommandContext, so repositories and their caches are shared across lines.
+type REPL struct {
+	ctx     *CommandContext
+	newRoot func(*CommandContext) *cobra.Command
+	config  *REPLConfig
+	in      *os.File
+	out     io.Writer
+	errOut  io.Writer
+
+	history   *replHistory
+	completer *completer
+}
+
+// NewREPL creates a shell that builds its command tree with newRoot.
+func NewREPL(ctx *CommandContext, newRoot func(*CommandContext) *cobra.Command, config *REPLConfig) *REPL {
+	if config == nil {
+		config = DefaultREPLConfig()
+	}
+
+	r := &REPL{
+		ctx:     ctx,
+		newRoot: newRoot,
+		config:  config,
+		in:      os.Stdin,
+		out:     os.Stdout,
+		errOut:  os.Stderr,
+	}
+	r.history = newREPLHistory(config.HistoryFile, config.HistorySize)
+	r.completer = newCompleter(ctx, newRoot, config.CompletionTimeout)
+	return r
+}
+
+// NewShellCommand creates the shell command
+func NewShellCommand(ctx *CommandContext, newRoot func(*CommandContext) *cobra.Command) *cobra.Command {
+	return &cobra.Command{
+		Use:   "shell",
+		Args:  cobra.NoArgs,
+		Short: "Start an interactive shell",
+		Long: `Start an interactive shell that runs maestro commands without the
+startup cost of a new process for each one.
+
+The prompt shows the current track. Use the arrow keys to browse the history
+(kept in ~/.maestro_history), Tab to complete commands, flags and library
+names, and "exit" or Ctrl+D to leave. Lines can also be piped on stdin.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return NewREPL(ctx, newRoot, nil).Run()
+		},
+	}
+}
+
+// Run reads and executes lines until the input ends or the user exits.
+// When stdin is not a terminal, lines are read without prompt or editing.
+func (r *REPL) Run() error {
+	if err := r.history.load(); err != nil {
+		r.ctx.OutputFormatter.Debug("Could not load shell history: " + err.Error())
+	}
+
+	fd := int(r.in.Fd())
+	if !term.IsTerminal(fd) {
+		return r.runScript(r.in)
+	}
+
+	terminal := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{r.in, r.out}, "")
+	terminal.History = r.history
+	terminal.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		return r.completer.complete(terminal, line, pos)
+	}
+
+	for {
+		terminal.SetPrompt(r.prompt())
+
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		if width, height, err := term.GetSize(fd); err == nil {
+			_ = terminal.SetSize(width, height)
+		}
+		line, err := terminal.ReadLine()
+		_ = term.Restore(fd, state)
+
+		if err == io.EOF {
+			_, _ = fmt.Fprintln(r.out)
+			return nil
+		}
+		if err != nil && !errors.Is(err, term.ErrPasteIndicator) {
+			return err
+		}
+		if !r.execute(line) {
+			return nil
+		}
+	}
+}
+
+// runScript executes one command per input line.
+func (r *REPL) runScript(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		if !r.execute(scanner.Text()) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// execute runs one line and reports whether the shell should keep going.
+func (r *REPL) execute(line string) bool {
+	args, err := splitLine(line)
+	if err != nil {
+		_, _ = fmt.Fprintf(r.errOut, "Error: %s\n", err.Error())
+		return true
+	}
+	if len(args) == 0 {
+		return true
+	}
+
+	switch args[0] {
+	case "exit", "quit":
+		return false
+	case "shell":
+		_, _ = fmt.Fprintln(r.errOut, "Error: already in the maestro shell")
+		return true
+	}
+
+	// Commands report their own errors through the OutputFormatter; only
+	// errors raised before a command runs, such as unknown commands or bad
+	// flags, are printed here.
+	root := r.newRoot(r.ctx)
+	ran := false
+	preRun := root.PersistentPreRunE
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := preRun(cmd, args); err != nil {
+			return err
+		}
+		ran = true
+		return nil
+	}
+	root.SetArgs(args)
+
+	if err := root.ExecuteContext(r.ctx.Context); err != nil && !ran {
+		_, _ = fmt.Fprintf(r.errOut, "Error: %s\n", err.Error())
+	}
+	return true
+}
+
+// prompt renders the current player state, falling back to a plain prompt
+// when Music.app cannot be queried.
+func (r *REPL) prompt() string {
+	ctx, cancel := context.WithTimeout(r.ctx.Context, r.config.StatusTimeout)
+	defer cancel()
+
+	player, err := r.ctx.PlayerRepo.GetCurrentState(ctx)
+	if err != nil || !player.HasCurrentTrack() {
+		return "maestro › "
+	}
+	track, err := r.ctx.PlayerRepo.GetCurrentTrack(ctx)
+	if err != nil || track == nil {
+		return "maestro › "
+	}
+
+	icon := "▶"
+	if player.State != music.PlayerStatePlaying {
+		icon = "⏸"
+	}
+	return fmt.Sprintf("%s %s – %s › ", icon, track.Artist, track.Title)
+}
+
+// replHistory is a bounded term.History that appends every entry to a file.
+type replHistory struct {
+	path    string
+	size    int
+	entries []string // oldest first
+}
+
+func newREPLHistory(path string, size int) *replHistory {
+	if size <= 0 {
+		size = 1
+	}
+	return &replHistory{path: path, size: size}
+}
+
+// load reads the history file, compacting it when it has grown past size.
+func (h *replHistory) load() error {
+	if h.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		lines = nil
+	}
+	if len(lines) > h.size {
+		lines = lines[len(lines)-h.size:]
+		if err := os.WriteFile(h.path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+			return err
+		}
+	}
+	h.entries = lines
+	return nil
+}
+
+// Add records a line, skipping blanks and immediate repeats.
+func (h *replHistory) Add(entry string) {
+	entry = strings.TrimSpace(entry)
+	if entry == "" || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry) {
+		return
+	}
+
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > h.size {
+		h.entries = h.entries[len(h.entries)-h.size:]
+	}
+
+	if h.path == "" {
+		return
+	}
+	file, err := os.OpenFile(h.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return
+	}
+	defer func() { _ = file.Close() }()
+	_, _ = fmt.Fprintln(file, entry)
+}
+
+// Len returns the number of entries.
+func (h *replHistory) Len() int {
+	return len(h.entries)
+}
+
+// At returns an entry, where 0 is the most recent.
+func (h *replHistory) At(idx int) string {
+	return h.entries[len(h.entries)-1-idx]
+}
+
+// splitLine splits a line into arguments the way a POSIX shell would for
+// quotes and backslashes, without any expansion.
+func splitLine(line string) ([]string, error) {
+	tokens, open := tokenize(line)
+	if open != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", open)
+	}
+	args := make([]string, len(tokens))
+	for i, t := range tokens {
+		args[i] = t.value
+	}
+	return args, nil
+}
+
+// token is one argument of a line and the byte offset where it starts.
+type token struct {
+	value string
+	start int
+}
+
+// tokenize splits line into tokens. It also returns the quote character
+// left open at the end of the line, if any.
+func tokenize(line string) ([]token, rune) {
+	var tokens []token
+	var current strings.Builder
+	var quote rune
+	inToken, escaped := false, false
+	start := 0
+
+	for i, c := range line {
+		switch {
+		case escaped:
+			current.WriteRune(c)
+			escaped = false
+		case c == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				current.WriteRune(c)
+			}
+			continue
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ' ' || c == '\t':
+			if inToken {
+				tokens = append(tokens, token{value: current.String(), start: start})
+				current.Reset()
+				inToken = false
+			}
+			continue
+		default:
+			current.WriteRune(c)
+		}
+		if !inToken {
+			inToken = true
+			start = i
+		}
+	}
+
+	if inToken {
+		tokens = append(tokens, token{value: current.String(), start: start})
+	}
+	return tokens, quote
+}
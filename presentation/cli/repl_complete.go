@@ -0,0 +1,245 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Completion limits.
+const (
+	// minTrackPrefix is the shortest prefix that triggers a track search
+	minTrackPrefix = 2
+
+	// maxTrackCompletions caps the tracks offered for one completion
+	maxTrackCompletions = 20
+)
+
+// shellBuiltins are commands handled by the shell itself.
+var shellBuiltins = []string{"exit", "quit"}
+
+// completer completes commands, flags and library names for the shell.
+type completer struct {
+	ctx     *CommandContext
+	newRoot func(*CommandContext) *cobra.Command
+	timeout time.Duration
+
+	// names caches artist and playlist names for the session
+	namesOnce sync.Once
+	names     []string
+
+	// lastLine is the line of the previous Tab press; a second Tab with no
+	// progress lists the candidates
+	lastLine string
+}
+
+func newCompleter(ctx *CommandContext, newRoot func(*CommandContext) *cobra.Command, timeout time.Duration) *completer {
+	return &completer{ctx: ctx, newRoot: newRoot, timeout: timeout}
+}
+
+// complete handles a Tab press with the cursor at byte offset pos. It
+// extends the word under the cursor to the longest common prefix of the
+// candidates, or lists them on out when there is nothing to add.
+func (c *completer) complete(out io.Writer, line string, pos int) (string, int, bool) {
+	head, tail := line[:pos], line[pos:]
+
+	tokens, quote := tokenize(head)
+	partial, start := "", len(head)
+	if len(tokens) > 0 && (quote != 0 || !strings.HasSuffix(head, " ")) {
+		last := tokens[len(tokens)-1]
+		partial, start = last.value, last.start
+		tokens = tokens[:len(tokens)-1]
+	}
+	words := make([]string, len(tokens))
+	for i, t := range tokens {
+		words[i] = t.value
+	}
+
+	candidates := matching(c.candidates(words, partial), partial)
+	if len(candidates) == 0 {
+		return "", 0, false
+	}
+
+	// Keep a quote the user has opened even if the completion needs none
+	quoted := quote != 0
+	replacement := commonPrefix(candidates)
+	if len(candidates) == 1 {
+		replacement = quoteOpen(replacement, quoted)
+		if strings.HasPrefix(replacement, `"`) {
+			replacement += `"`
+		}
+		replacement += " "
+	} else if len([]rune(replacement)) > len([]rune(partial)) {
+		replacement = quoteOpen(replacement, quoted)
+	} else {
+		// Nothing to add: list the choices on the second press
+		if c.lastLine == line {
+			_, _ = fmt.Fprintln(out, strings.Join(candidates, "   "))
+		}
+		c.lastLine = line
+		return "", 0, false
+	}
+
+	c.lastLine = ""
+	newHead := head[:start] + replacement
+	return newHead + tail, len(newHead), true
+}
+
+// candidates returns every completion for the next word after words.
+func (c *completer) candidates(words []string, partial string) []string {
+	root := c.newRoot(c.ctx)
+	// Cobra adds this lazily on Execute
+	root.InitDefaultHelpCmd()
+
+	if len(words) == 0 {
+		names := append([]string{}, shellBuiltins...)
+		return append(names, subcommandNames(root)...)
+	}
+
+	cmd, args, err := root.Find(words)
+	if err != nil || cmd == root {
+		return nil
+	}
+
+	if strings.HasPrefix(partial, "-") {
+		cmd.InitDefaultHelpFlag()
+		return flagNames(cmd)
+	}
+	if cmd.HasAvailableSubCommands() {
+		return subcommandNames(cmd)
+	}
+	if cmd.ValidArgsFunction != nil {
+		completions, _ := cmd.ValidArgsFunction(cmd, args, partial)
+		return stripDescriptions(completions)
+	}
+	if len(cmd.ValidArgs) > 0 {
+		return stripDescriptions(cmd.ValidArgs)
+	}
+	if cmd.Args != nil && cmd.Args(cmd, append(args, partial)) != nil {
+		// The command takes no further arguments
+		return nil
+	}
+	return c.libraryNames(partial)
+}
+
+// libraryNames returns artist and playlist names plus the titles of tracks
+// matching partial.
+func (c *completer) libraryNames(partial string) []string {
+	if c.ctx.LibraryRepo == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx.Context, c.timeout)
+	defer cancel()
+
+	c.namesOnce.Do(func() {
+		if artists, err := c.ctx.LibraryRepo.GetArtists(ctx); err == nil {
+			c.names = append(c.names, artists...)
+		}
+		if playlists, err := c.ctx.LibraryRepo.GetPlaylists(ctx); err == nil {
+			for _, playlist := range playlists {
+				c.names = append(c.names, playlist.Name)
+			}
+		}
+	})
+
+	names := append([]string{}, c.names...)
+	if len([]rune(partial)) >= minTrackPrefix {
+		tracks, err := c.ctx.LibraryRepo.Search(ctx, music.LibrarySearchOptions{Query: partial, Limit: maxTrackCompletions})
+		if err == nil {
+			for _, track := range tracks {
+				names = append(names, track.Title)
+			}
+		}
+	}
+	return names
+}
+
+// subcommandNames returns the names and aliases of cmd's visible subcommands.
+func subcommandNames(cmd *cobra.Command) []string {
+	var names []string
+	for _, sub := range cmd.Commands() {
+		if !sub.IsAvailableCommand() {
+			continue
+		}
+		names = append(names, sub.Name())
+		names = append(names, sub.Aliases...)
+	}
+	return names
+}
+
+// flagNames returns the long and short forms of every flag cmd accepts.
+func flagNames(cmd *cobra.Command) []string {
+	var names []string
+	visit := func(f *pflag.Flag) {
+		if f.Hidden {
+			return
+		}
+		names = append(names, "--"+f.Name)
+		if f.Shorthand != "" {
+			names = append(names, "-"+f.Shorthand)
+		}
+	}
+	cmd.LocalFlags().VisitAll(visit)
+	cmd.InheritedFlags().VisitAll(visit)
+	return names
+}
+
+// stripDescriptions drops the tab-separated descriptions cobra completions
+// may carry.
+func stripDescriptions(completions []string) []string {
+	names := make([]string, len(completions))
+	for i, completion := range completions {
+		names[i], _, _ = strings.Cut(completion, "\t")
+	}
+	return names
+}
+
+// matching returns the sorted, de-duplicated candidates that start with
+// partial, ignoring case.
+func matching(candidates []string, partial string) []string {
+	lower := strings.ToLower(partial)
+	seen := make(map[string]bool, len(candidates))
+	var matches []string
+	for _, candidate := range candidates {
+		if seen[candidate] || !strings.HasPrefix(strings.ToLower(candidate), lower) {
+			continue
+		}
+		seen[candidate] = true
+		matches = append(matches, candidate)
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// commonPrefix returns the longest prefix shared by values, ignoring case
+// and keeping the case of the first value.
+func commonPrefix(values []string) string {
+	prefix := []rune(values[0])
+	for _, value := range values[1:] {
+		runes := []rune(value)
+		n := 0
+		for n < len(prefix) && n < len(runes) && strings.EqualFold(string(prefix[n]), string(runes[n])) {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	return string(prefix)
+}
+
+// quoteOpen double-quotes s when splitLine would otherwise split it, or when
+// force is set. The quote is left open so that typing can continue inside it.
+func quoteOpen(s string, force bool) string {
+	if !force && s != "" && !strings.ContainsAny(s, " \t\"'\\") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
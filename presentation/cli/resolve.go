@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// trackIDPattern matches strings that look like track IDs: Music.app
+// persistent IDs are hexadecimal, and the in-memory library uses numbers.
+var trackIDPattern = regexp.MustCompile(`^[0-9A-Fa-f]+$`)
+
+// resolveTracks turns command arguments into track IDs. A single "-" reads
+// IDs from stdin, arguments that all look like IDs of existing tracks are
+// used as they are, and anything else is a search expression whose best
+// limit matches are used (0 for every match).
+func resolveTracks(ctx *CommandContext, args []string, stdin io.Reader, limit int) ([]music.TrackID, error) {
+	if len(args) == 0 {
+		return nil, music.NewDomainError(music.ErrInvalidTrackID, "no tracks given: pass track IDs, a search expression or - to read IDs from stdin")
+	}
+
+	if len(args) == 1 && args[0] == "-" {
+		return readTrackIDs(stdin)
+	}
+
+	if looksLikeTrackIDs(args) {
+		ids := make([]music.TrackID, len(args))
+		for i, arg := range args {
+			ids[i] = music.NewTrackID(arg)
+		}
+		if _, err := ctx.LibraryRepo.GetTracks(ctx.Context, ids); err == nil {
+			return ids, nil
+		} else if !music.IsTrackNotFound(err) {
+			return nil, err
+		}
+	}
+
+	query := strings.Join(args, " ")
+	tracks, err := ctx.LibraryRepo.Search(ctx.Context, music.LibrarySearchOptions{Query: query, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	if len(tracks) == 0 {
+		return nil, music.NewDomainError(music.ErrTrackNotFound, fmt.Sprintf("no tracks match %q", query)).
+			WithContext("query", query)
+	}
+
+	ids := make([]music.TrackID, len(tracks))
+	for i, track := range tracks {
+		ids[i] = track.ID
+	}
+	return ids, nil
+}
+
+// looksLikeTrackIDs reports whether every argument could be a track ID.
+func looksLikeTrackIDs(args []string) bool {
+	for _, arg := range args {
+		if !trackIDPattern.MatchString(arg) {
+			return false
+		}
+	}
+	return true
+}
+
+// readTrackIDs reads whitespace-separated track IDs, or the JSON array
+// printed by "maestro search --ids --json", from r.
+func readTrackIDs(r io.Reader) ([]music.TrackID, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, music.NewDomainErrorWithCause(music.ErrInvalidTrackID, "failed to read track IDs from stdin", err)
+	}
+
+	var values []string
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal([]byte(trimmed), &values); err != nil {
+			return nil, music.NewDomainErrorWithCause(music.ErrInvalidTrackID, "stdin is not a JSON array of track IDs", err)
+		}
+	} else {
+		values = strings.Fields(trimmed)
+	}
+
+	ids := make([]music.TrackID, 0, len(values))
+	for _, value := range values {
+		if id := music.NewTrackID(value); !id.IsEmpty() {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, music.NewDomainError(music.ErrInvalidTrackID, "no track IDs on stdin")
+	}
+	return ids, nil
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/spf13/cobra"
+)
+
+// NewRootCommand creates the maestro root command with every subcommand.
+// The interactive shell builds a fresh tree for each line it runs, so flag
+// values never leak from one line to the next.
+func NewRootCommand(ctx *CommandContext) *cobra.Command {
+	var jsonOutput, verbose bool
+	var outputName, templateText string
+	var configFile string
+	var overrides []string
+
+	rootCmd := &cobra.Command{
+		Use:   "maestro",
+		Short: "Maestro - Control your music from the command line",
+		Long: `Maestro is a command-line interface for controlling music playback.
+It provides simple commands to play, pause, skip tracks, and manage volume
+using your system's music player.
+
+Output is a table by default. --output selects json, ndjson, yaml or csv,
+and --template formats each item with a Go template over the JSON fields in
+Go case, with the functions json, join, upper, lower and truncate:
+
+  maestro status --template '{{.Track.Artist}} - {{.Track.Title}}'
+  maestro search blue --template '{{.Rank}}. {{.Title}} ({{.Duration}})'
+
+Errors go to stderr in the selected format. Structured errors carry a
+stable code such as "track_not_found", a kind, the exit code, retryable and
+permanent flags and the error's context. Exit codes:
+
+  0  success                  4  player or library unavailable
+  1  other failure            5  timeout
+  2  invalid input or usage   6  permission denied or read-only
+  3  not found                7  rate limited
+                            130  interrupted`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		// The completion command below replaces cobra's default one
+		CompletionOptions: cobra.CompletionOptions{DisableDefaultCmd: true},
+	}
+
+	// Add global flags
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Output in JSON format (same as --output json)")
+	rootCmd.PersistentFlags().StringVarP(&outputName, "output", "o", OutputTable.String(), "Output format (table, json, ndjson, yaml, csv or template)")
+	rootCmd.PersistentFlags().StringVar(&templateText, "template", "", "Go template for each item, e.g. '{{.Title}}' (implies --output template)")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Read configuration from this file as well")
+	rootCmd.PersistentFlags().StringArrayVar(&overrides, "set", nil, "Override a configuration key (section.key=value)")
+
+	// Set up PersistentPreRunE to initialize OutputFormatter and load the
+	// configuration after flags are parsed
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// Fall back to the table format when the requested one is invalid
+		ctx.OutputFormatter = newCommandFormatter(cmd, nil, verbose)
+		renderer, err := outputRenderer(cmd, outputName, templateText, jsonOutput)
+		if err != nil {
+			return err
+		}
+		ctx.OutputFormatter = newCommandFormatter(cmd, renderer, verbose)
+		return loadConfig(ctx, configFile, overrides)
+	}
+
+	// Report output that could not be rendered, such as a template that
+	// does not fit the command's output
+	rootCmd.PersistentPostRunE = func(cmd *cobra.Command, args []string) error {
+		return ctx.OutputFormatter.Err()
+	}
+
+	// Add all commands
+	rootCmd.AddCommand(NewPlayCommand(ctx))
+	rootCmd.AddCommand(NewPauseCommand(ctx))
+	rootCmd.AddCommand(NewStopCommand(ctx))
+	rootCmd.AddCommand(NewResumeCommand(ctx))
+	rootCmd.AddCommand(NewNextCommand(ctx))
+	rootCmd.AddCommand(NewPreviousCommand(ctx))
+	rootCmd.AddCommand(NewSeekCommand(ctx))
+	rootCmd.AddCommand(NewVolumeCommand(ctx))
+	rootCmd.AddCommand(NewStatusCommand(ctx))
+	rootCmd.AddCommand(NewSearchCommand(ctx))
+	rootCmd.AddCommand(NewQueueCommand(ctx))
+	rootCmd.AddCommand(NewPlaylistCommand(ctx))
+	rootCmd.AddCommand(NewRateCommand(ctx))
+	rootCmd.AddCommand(NewLoveCommand(ctx))
+	rootCmd.AddCommand(NewDislikeCommand(ctx))
+	rootCmd.AddCommand(NewHistoryCommand(ctx))
+	rootCmd.AddCommand(NewConfigCommand(ctx))
+	rootCmd.AddCommand(NewDaemonCommand(ctx))
+	rootCmd.AddCommand(NewBatchCommand(ctx, NewRootCommand))
+	rootCmd.AddCommand(NewShellCommand(ctx, NewRootCommand))
+	rootCmd.AddCommand(NewCompletionCommand())
+
+	_ = rootCmd.RegisterFlagCompletionFunc("output", completeNamesOf(OutputFormats()))
+
+	// Give bad flags and arguments the invalid-input exit code
+	rootCmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return usageError(err)
+	})
+	markUsageErrors(rootCmd)
+
+	return rootCmd
+}
+
+// newCommandFormatter creates an output formatter that writes to cmd's
+// output and error writers.
+func newCommandFormatter(cmd *cobra.Command, renderer Renderer, verbose bool) *OutputFormatter {
+	formatter := NewOutputFormatter(renderer, verbose)
+	formatter.SetWriter(cmd.OutOrStdout())
+	formatter.SetErrorWriter(cmd.ErrOrStderr())
+	return formatter
+}
+
+// outputRenderer creates the renderer selected by --output, --template and
+// --json. --template implies the template format and --json the JSON one.
+func outputRenderer(cmd *cobra.Command, outputName, templateText string, jsonOutput bool) (Renderer, error) {
+	format, err := ParseOutputFormat(outputName)
+	if err != nil {
+		return nil, err
+	}
+
+	outputSet := cmd.Flags().Changed("output")
+	implied := format
+	switch {
+	case jsonOutput:
+		implied = OutputJSON
+	case templateText != "":
+		implied = OutputTemplate
+	}
+	if outputSet && implied != format {
+		return nil, music.NewDomainError(music.ErrInvalidOperation,
+			fmt.Sprintf("--output %s conflicts with --json or --template", format))
+	}
+	return NewRenderer(implied, templateText)
+}
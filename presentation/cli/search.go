@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/madstone-tech/maestro/application/completion"
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/spf13/cobra"
+)
+
+// NewSearchCommand creates the search command
+func NewSearchCommand(ctx *CommandContext) *cobra.Command {
+	var artist, album string
+	var fields []string
+	var limit, offset int
+	var idsOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "search [query...]",
+		Short: "Search the music library",
+		Long: `Search the library for tracks whose title, artist or album match the query,
+best matches first. Narrow the results with --artist and --album, choose the
+fields the query is matched against with --field, and page through them with
+--limit and --offset. --ids prints only track IDs, one per line, for piping
+into other commands.`,
+		Example: `  maestro search bohemian
+  maestro search --artist queen --album "a night at the opera"
+  maestro search love --field title --limit 10
+  maestro search --ids beatles | maestro queue add -`,
+		ValidArgsFunction: completeQuery(ctx, completion.KindTrack),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing search command")
+
+			options := music.LibrarySearchOptions{
+				Query:  strings.Join(args, " "),
+				Artist: artist,
+				Album:  album,
+				Limit:  limit,
+				Offset: offset,
+			}
+			for _, name := range fields {
+				field, err := music.ParseSearchField(name)
+				if err != nil {
+					ctx.OutputFormatter.Error(err)
+					return err
+				}
+				options.Fields = append(options.Fields, field)
+			}
+
+			tracks, err := ctx.LibraryRepo.Search(ctx.Context, options)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			ctx.OutputFormatter.PrintSearchResults(music.RankTracks(tracks, options), idsOnly)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&artist, "artist", "", "Only tracks whose artist contains this text")
+	cmd.Flags().StringVar(&album, "album", "", "Only tracks whose album contains this text")
+	cmd.Flags().StringSliceVar(&fields, "field", nil, "Match the query against these fields only (title, artist, album)")
+	cmd.Flags().IntVar(&limit, "limit", 25, "Maximum number of results (0 for no limit)")
+	cmd.Flags().IntVar(&offset, "offset", 0, "Number of results to skip")
+	cmd.Flags().BoolVar(&idsOnly, "ids", false, "Print only track IDs")
+	_ = cmd.RegisterFlagCompletionFunc("artist", completeFlag(ctx, completion.KindArtist))
+	_ = cmd.RegisterFlagCompletionFunc("album", completeFlag(ctx, completion.KindAlbum))
+	_ = cmd.RegisterFlagCompletionFunc("field", completeNamesOf(music.SearchFields()))
+
+	return cmd
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/spf13/cobra"
+)
+
+// runCommand executes cmd with args over ctx and returns what it printed.
+func runCommand(ctx *CommandContext, cmd *cobra.Command, args ...string) (string, error) {
+	var out strings.Builder
+	ctx.OutputFormatter = NewOutputFormatter(nil, false)
+	ctx.OutputFormatter.SetWriter(&out)
+	ctx.OutputFormatter.SetErrorWriter(io.Discard)
+	cmd.SetArgs(append([]string{}, args...))
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestSearchCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"artist and album", []string{"--ids", "--artist", "coltrane", "--album", "giant"},
+			"1011 1012 1013 1014 1015 1016 1017"},
+		{"page", []string{"--ids", "--artist", "coltrane", "--limit", "2", "--offset", "4"}, "1010 1011"},
+		{"title field", []string{"--ids", "--field", "title", "blue", "train"}, "1006"},
+		{"album prefix first", []string{"--ids", "--field", "album", "--limit", "0", "blue"},
+			"1006 1007 1008 1009 1010 1001 1002 1003 1004 1005"},
+		{"no match", []string{"--ids", "bitches", "brew"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newPlayContext()
+			out, err := runCommand(ctx, NewSearchCommand(ctx), tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(strings.Fields(out), " "); got != tt.want {
+				t.Errorf("search %v printed %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchCommandTable(t *testing.T) {
+	ctx := newPlayContext()
+	out, err := runCommand(ctx, NewSearchCommand(ctx), "so", "what")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "So What") || !strings.Contains(out, "Miles Davis") {
+		t.Errorf("expected So What by Miles Davis, got %q", out)
+	}
+}
+
+func TestSearchCommandErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"nothing to look for", nil},
+		{"unknown field", []string{"--field", "year", "blue"}},
+		{"negative limit", []string{"--limit", "-1", "blue"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newPlayContext()
+			if _, err := runCommand(ctx, NewSearchCommand(ctx), tt.args...); !errors.Is(err, music.ErrInvalidSearchQuery) {
+				t.Errorf("expected ErrInvalidSearchQuery, got %v", err)
+			}
+		})
+	}
+}
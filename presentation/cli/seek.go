@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/spf13/cobra"
+)
+
+// NewSeekCommand creates the seek command
+func NewSeekCommand(ctx *CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "seek <position>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Move the playback position within the current track",
+		Long: `Move the playback position within the current track. The position can be
+absolute ("90", "1:30", "2m"), relative to the current position ("+15s",
+"-10") or a percentage of the track ("50%", "+10%"). Positions stop at the
+start of the track and just before its end.`,
+		Example: `  maestro seek 1:30
+  maestro seek +15s
+  maestro seek -10
+  maestro seek 50%`,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing seek command")
+
+			player, err := ctx.PlayerRepo.GetCurrentState(ctx.Context)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			track, err := ctx.PlayerRepo.GetCurrentTrack(ctx.Context)
+			if err == nil && track == nil {
+				err = music.NewDomainError(music.ErrInvalidPlayerState, "no track is loaded")
+			}
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			position, err := resolveSeekPosition(args[0], player.Position, track.Duration)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			if err := ctx.PlayerRepo.Seek(ctx.Context, position); err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			ctx.OutputFormatter.Success(fmt.Sprintf("Position %s / %s", position, track.Duration))
+			return nil
+		},
+	}
+
+	acceptNegativeValues(cmd)
+
+	return cmd
+}
+
+// resolveSeekPosition turns a seek argument into a position within a track
+// of the given length. Positions past the end stop just before it;
+// percentages outside 0% to 100% are rejected.
+func resolveSeekPosition(arg string, current, length music.Duration) (music.Duration, error) {
+	if text, ok := strings.CutSuffix(strings.TrimSpace(arg), "%"); ok {
+		sign := 0
+		if strings.HasPrefix(text, "+") || strings.HasPrefix(text, "-") {
+			sign = 1
+			if text[0] == '-' {
+				sign = -1
+			}
+			text = text[1:]
+		}
+
+		percent, err := strconv.ParseFloat(text, 64)
+		if err != nil || math.IsNaN(percent) || math.IsInf(percent, 0) || percent < 0 ||
+			(sign == 0 && percent > 100) || text != strings.TrimSpace(text) {
+			return music.Duration{}, music.NewDomainError(music.ErrInvalidPosition,
+				fmt.Sprintf("invalid percentage %q: expected 0%% to 100%%", arg)).WithContext("input", arg)
+		}
+
+		offset := music.NewDurationFromMillis(int64(float64(length.Milliseconds()) * percent / 100))
+		if sign == 0 {
+			return clampPosition(offset, length), nil
+		}
+		return offsetPosition(current, offset, sign, length), nil
+	}
+
+	offset, sign, err := music.ParseDuration(arg)
+	if err != nil {
+		return music.Duration{}, err
+	}
+	if sign != 0 {
+		return offsetPosition(current, offset, sign, length), nil
+	}
+	return clampPosition(offset, length), nil
+}
+
+// offsetPosition moves current forwards (sign 1) or backwards (sign -1) by
+// offset, staying within the track.
+func offsetPosition(current, offset music.Duration, sign int, length music.Duration) music.Duration {
+	if sign < 0 {
+		return current.Subtract(offset)
+	}
+	return clampPosition(current.Add(offset), length)
+}
+
+// clampPosition stops position a second before the end of the track so
+// that a seek does not finish it.
+func clampPosition(position, length music.Duration) music.Duration {
+	last := length.Subtract(music.NewDuration(1))
+	if position.Milliseconds() < last.Milliseconds() {
+		return position
+	}
+	return last
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+func TestResolveSeekPosition(t *testing.T) {
+	current, length := music.NewDuration(100), music.NewDuration(300)
+
+	tests := []struct {
+		arg  string
+		want int64
+	}{
+		{"90", 90000},
+		{"1:30", 90000},
+		{"2m", 120000},
+		{"5:00", 299000},
+		{"1h", 299000},
+		{"+30", 130000},
+		{"-1:00", 40000},
+		{"-5m", 0},
+		{"+10m", 299000},
+		{"50%", 150000},
+		{"100%", 299000},
+		{"+10%", 130000},
+		{"-50%", 0},
+		{" 25% ", 75000},
+		{"0.5%", 1500},
+		{"33.3%", 99900},
+	}
+	for _, tt := range tests {
+		got, err := resolveSeekPosition(tt.arg, current, length)
+		if err != nil {
+			t.Errorf("resolveSeekPosition(%q) failed: %v", tt.arg, err)
+			continue
+		}
+		if got.Milliseconds() != tt.want {
+			t.Errorf("resolveSeekPosition(%q) = %dms, want %dms", tt.arg, got.Milliseconds(), tt.want)
+		}
+	}
+
+	for _, arg := range []string{"101%", "-%", "+ 5%", "half%", "NaN%", "Inf%", "+Inf%", "-infinity%", "soon", ""} {
+		if _, err := resolveSeekPosition(arg, current, length); !errors.Is(err, music.ErrInvalidPosition) {
+			t.Errorf("resolveSeekPosition(%q): expected ErrInvalidPosition, got %v", arg, err)
+		}
+	}
+}
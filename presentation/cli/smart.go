@@ -0,0 +1,315 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/madstone-tech/maestro/application/smartlist"
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/spf13/cobra"
+)
+
+// NewPlaylistSmartCommand creates the playlist smart command group
+func NewPlaylistSmartCommand(ctx *CommandContext) *cobra.Command {
+	smartCmd := &cobra.Command{
+		Use:   "smart",
+		Short: "Manage maestro's rule-based playlists",
+		Long: `Create and manage smart playlists whose rules maestro owns.
+
+Music.app does not let scripts read or write the rules of its own smart
+playlists, so maestro keeps rules in ~/.maestro_smart_playlists.json (see
+smart_playlists.file) and writes the tracks they select into a regular
+playlist of the same name. maestrod keeps live-updating playlists in step
+with the library; "refresh" updates them on demand.
+
+Rules are written "<field> <operator> <value>":
+
+  genre is Jazz                     year between 1955 and 1960
+  play_count greater_than 10        last_played in_last 30d
+  title contains blue               loved is true
+
+Fields: ` + joinNames(music.SmartFields()) + `.
+Operators: ` + joinNames(music.SmartOperators()) + `.
+Text is compared without regard to case, durations may be written as 4:30,
+dates as 2006-01-02 and periods as 12h, 30d, 2w, 6mo or 1y.`,
+	}
+
+	smartCmd.AddCommand(NewPlaylistSmartCreateCommand(ctx))
+	smartCmd.AddCommand(NewPlaylistSmartListCommand(ctx))
+	smartCmd.AddCommand(NewPlaylistSmartShowCommand(ctx))
+	smartCmd.AddCommand(NewPlaylistSmartRefreshCommand(ctx))
+	smartCmd.AddCommand(NewPlaylistSmartDeleteCommand(ctx))
+
+	return smartCmd
+}
+
+// NewPlaylistSmartCreateCommand creates the playlist smart create command
+func NewPlaylistSmartCreateCommand(ctx *CommandContext) *cobra.Command {
+	var rules []string
+	var match, limitUnit, sortBy string
+	var limit int
+	var live, replace bool
+
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Args:  cobra.MinimumNArgs(1),
+		Short: "Create a smart playlist and write its tracks to Music.app",
+		Example: `  maestro playlist smart create "Recent Jazz" --rule "genre is jazz" --rule "date_added in_last 30d" --live
+  maestro playlist smart create "Top 25" --sort play_count:desc --limit 25
+  maestro playlist smart create "Hour of Coltrane" --rule "artist is john coltrane" --limit 1 --limit-unit hours`,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing playlist smart create command")
+
+			name := strings.Join(args, " ")
+			smartRules, err := parseSmartRules(rules, match, limit, limitUnit, sortBy, live)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+			playlist, err := music.NewSmartPlaylist(name, smartRules)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			// Replacing keeps the Music.app playlist the old rules wrote to
+			existing, err := ctx.SmartPlaylistRepo.GetSmartPlaylist(ctx.Context, name)
+			switch {
+			case err == nil && !replace:
+				err = music.NewDomainError(music.ErrInvalidOperation,
+					fmt.Sprintf("smart playlist %q already exists (use --replace to change its rules)", existing.Name))
+				ctx.OutputFormatter.Error(err)
+				return err
+			case err == nil:
+				playlist.PlaylistID = existing.PlaylistID
+			case !errors.Is(err, music.ErrPlaylistNotFound):
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			tracks, err := newMaterializer(ctx).Materialize(ctx.Context, playlist)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+			ctx.OutputFormatter.PrintSmartPlaylist(playlist, tracks)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&rules, "rule", "r", nil, `Rule such as "genre is Jazz" (repeatable; none matches every track)`)
+	cmd.Flags().StringVar(&match, "match", music.SmartMatchAll.String(), "Whether tracks must match all rules or any")
+	cmd.Flags().IntVar(&limit, "limit", 0, "Keep at most this many items, minutes or hours (0 for no limit)")
+	cmd.Flags().StringVar(&limitUnit, "limit-unit", music.SmartLimitItems.String(), "Unit of --limit: items, minutes or hours")
+	cmd.Flags().StringVar(&sortBy, "sort", "", "Order tracks by a field, e.g. play_count:desc; with --limit, picks the tracks kept")
+	cmd.Flags().BoolVar(&live, "live", false, "Let maestrod update the playlist as the library changes")
+	cmd.Flags().BoolVar(&replace, "replace", false, "Replace the rules of an existing smart playlist")
+
+	_ = cmd.RegisterFlagCompletionFunc("match", completeNamesOf(music.SmartMatches()))
+	_ = cmd.RegisterFlagCompletionFunc("limit-unit", completeNamesOf(music.SmartLimitUnits()))
+	_ = cmd.RegisterFlagCompletionFunc("sort", completeNamesOf(music.SmartFields()))
+
+	return cmd
+}
+
+// NewPlaylistSmartListCommand creates the playlist smart list command
+func NewPlaylistSmartListCommand(ctx *CommandContext) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Args:  cobra.NoArgs,
+		Short: "List smart playlists with their rules",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing playlist smart list command")
+
+			playlists, err := ctx.SmartPlaylistRepo.GetSmartPlaylists(ctx.Context)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+			ctx.OutputFormatter.PrintSmartPlaylists(playlists)
+			return nil
+		},
+	}
+}
+
+// NewPlaylistSmartShowCommand creates the playlist smart show command
+func NewPlaylistSmartShowCommand(ctx *CommandContext) *cobra.Command {
+	return &cobra.Command{
+		Use:               "show <name>",
+		Args:              cobra.MinimumNArgs(1),
+		Short:             "Show a smart playlist's rules and the tracks they select now",
+		ValidArgsFunction: completeSmartPlaylists(ctx),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing playlist smart show command")
+
+			playlist, err := ctx.SmartPlaylistRepo.GetSmartPlaylist(ctx.Context, strings.Join(args, " "))
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+			tracks, err := newMaterializer(ctx).Evaluate(ctx.Context, playlist)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+			ctx.OutputFormatter.PrintSmartPlaylist(playlist, tracks)
+			return nil
+		},
+	}
+}
+
+// NewPlaylistSmartRefreshCommand creates the playlist smart refresh command
+func NewPlaylistSmartRefreshCommand(ctx *CommandContext) *cobra.Command {
+	return &cobra.Command{
+		Use:               "refresh [name...]",
+		Short:             "Write the tracks smart playlists select to Music.app",
+		Long:              "Re-evaluate the named smart playlists, or all of them, and update their Music.app playlists.",
+		ValidArgsFunction: completeSmartPlaylists(ctx),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing playlist smart refresh command")
+
+			var playlists []*music.SmartPlaylist
+			if len(args) == 0 {
+				all, err := ctx.SmartPlaylistRepo.GetSmartPlaylists(ctx.Context)
+				if err != nil {
+					ctx.OutputFormatter.Error(err)
+					return err
+				}
+				playlists = all
+			}
+			for _, name := range args {
+				playlist, err := ctx.SmartPlaylistRepo.GetSmartPlaylist(ctx.Context, name)
+				if err != nil {
+					ctx.OutputFormatter.Error(err)
+					return err
+				}
+				playlists = append(playlists, playlist)
+			}
+
+			materializer := newMaterializer(ctx)
+			for _, playlist := range playlists {
+				if _, err := materializer.Materialize(ctx.Context, playlist); err != nil {
+					ctx.OutputFormatter.Error(err)
+					return err
+				}
+			}
+			ctx.OutputFormatter.PrintSmartPlaylists(playlists)
+			return nil
+		},
+	}
+}
+
+// NewPlaylistSmartDeleteCommand creates the playlist smart delete command
+func NewPlaylistSmartDeleteCommand(ctx *CommandContext) *cobra.Command {
+	var keepPlaylist bool
+
+	cmd := &cobra.Command{
+		Use:               "delete <name>",
+		Args:              cobra.MinimumNArgs(1),
+		Short:             "Delete a smart playlist and its Music.app playlist",
+		ValidArgsFunction: completeSmartPlaylists(ctx),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing playlist smart delete command")
+
+			name := strings.Join(args, " ")
+			if err := newMaterializer(ctx).Delete(ctx.Context, name, keepPlaylist); err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			message := fmt.Sprintf("Deleted smart playlist '%s'", name)
+			if keepPlaylist {
+				message += "; its tracks stay in a regular playlist"
+			}
+			ctx.OutputFormatter.Success(message)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&keepPlaylist, "keep-playlist", false, "Keep the Music.app playlist as a regular playlist")
+
+	return cmd
+}
+
+// newMaterializer creates a materializer over the command's repositories.
+func newMaterializer(ctx *CommandContext) *smartlist.Materializer {
+	return smartlist.NewMaterializer(ctx.LibraryRepo, ctx.PlaylistRepo, ctx.SmartPlaylistRepo, nil)
+}
+
+// parseSmartRules builds smart playlist rules from the create flags.
+func parseSmartRules(rules []string, match string, limit int, limitUnit, sortBy string, live bool) (music.SmartRules, error) {
+	smartRules := music.SmartRules{LiveUpdating: live}
+
+	var err error
+	if smartRules.Match, err = music.ParseSmartMatch(match); err != nil {
+		return music.SmartRules{}, err
+	}
+	for _, text := range rules {
+		rule, err := music.ParseSmartRule(text)
+		if err != nil {
+			return music.SmartRules{}, err
+		}
+		smartRules.Rules = append(smartRules.Rules, rule)
+	}
+
+	if limit < 0 {
+		return music.SmartRules{}, music.NewDomainError(music.ErrInvalidSmartRule, "--limit cannot be negative")
+	}
+	smartRules.Limit.Count = limit
+	if smartRules.Limit.Unit, err = music.ParseSmartLimitUnit(limitUnit); err != nil {
+		return music.SmartRules{}, err
+	}
+
+	if sortBy != "" {
+		field, direction, _ := strings.Cut(sortBy, ":")
+		order := &music.SmartSort{}
+		if order.Field, err = music.ParseSmartField(field); err != nil {
+			return music.SmartRules{}, err
+		}
+		switch strings.ToLower(direction) {
+		case "", "asc":
+		case "desc":
+			order.Descending = true
+		default:
+			return music.SmartRules{}, music.NewDomainError(music.ErrInvalidSmartRule,
+				fmt.Sprintf("unknown sort direction %q (expected asc or desc)", direction))
+		}
+		smartRules.Sort = order
+	}
+	return smartRules, nil
+}
+
+// completeSmartPlaylists completes the names of smart playlists.
+func completeSmartPlaylists(ctx *CommandContext) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		// Completion skips the pre-run hooks that load the configuration
+		if ctx.SmartPlaylistRepo == nil {
+			if preRun := cmd.Root().PersistentPreRunE; preRun == nil || preRun(cmd, nil) != nil || ctx.SmartPlaylistRepo == nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		}
+
+		playlists, err := ctx.SmartPlaylistRepo.GetSmartPlaylists(ctx.Context)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var names []string
+		for _, playlist := range playlists {
+			if strings.HasPrefix(strings.ToLower(playlist.Name), strings.ToLower(toComplete)) {
+				names = append(names, playlist.Name)
+			}
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// joinNames joins the names of values with commas.
+func joinNames[T fmt.Stringer](values []T) string {
+	names := make([]string, len(values))
+	for i, value := range values {
+		names[i] = value.String()
+	}
+	return strings.Join(names, ", ")
+}
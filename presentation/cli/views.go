@@ -0,0 +1,1060 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/pkg/config"
+	"github.com/madstone-tech/maestro/pkg/health"
+)
+
+// View models are what every output format renders. Their JSON field names
+// are the stable names used by --output json, ndjson, yaml and csv, and
+// their Go field names are what --template sees.
+
+// view is a view model that every renderer can write.
+type view interface {
+	// writeText writes the human-readable layout of the table format
+	writeText(w io.Writer) error
+
+	// records returns the CSV header and rows
+	records() (header []string, rows [][]string)
+}
+
+// listView is a view made of items; ndjson and template output write one
+// line per item.
+type listView interface {
+	view
+	items() []interface{}
+}
+
+// trackColumns are the CSV columns of a TrackView.
+var trackColumns = []string{"id", "title", "artist", "album", "duration", "duration_seconds"}
+
+// TrackView describes a track. The details after the duration are left
+// out of JSON when unknown.
+type TrackView struct {
+	ID              string `json:"id"`
+	Title           string `json:"title"`
+	Artist          string `json:"artist"`
+	Album           string `json:"album"`
+	Duration        string `json:"duration"`
+	DurationSeconds int    `json:"duration_seconds"`
+
+	AlbumArtist string    `json:"album_artist,omitempty"`
+	Genre       string    `json:"genre,omitempty"`
+	Year        int       `json:"year,omitempty"`
+	TrackNumber int       `json:"track_number,omitempty"`
+	TrackCount  int       `json:"track_count,omitempty"`
+	DiscNumber  int       `json:"disc_number,omitempty"`
+	DiscCount   int       `json:"disc_count,omitempty"`
+	Composer    string    `json:"composer,omitempty"`
+	Rating      int       `json:"rating,omitempty"`
+	Loved       bool      `json:"loved,omitempty"`
+	Disliked    bool      `json:"disliked,omitempty"`
+	PlayCount   int       `json:"play_count,omitempty"`
+	SkipCount   int       `json:"skip_count,omitempty"`
+	LastPlayed  time.Time `json:"last_played,omitzero"`
+	DateAdded   time.Time `json:"date_added,omitzero"`
+	BitRate     int       `json:"bit_rate,omitempty"`
+	Kind        string    `json:"kind,omitempty"`
+	Explicit    bool      `json:"explicit,omitempty"`
+}
+
+func newTrackView(track *music.Track) TrackView {
+	return TrackView{
+		ID:              track.ID.Value(),
+		Title:           track.Title,
+		Artist:          track.Artist,
+		Album:           track.Album,
+		Duration:        track.Duration.String(),
+		DurationSeconds: track.Duration.Seconds(),
+		AlbumArtist:     track.AlbumArtist,
+		Genre:           track.Genre,
+		Year:            track.Year,
+		TrackNumber:     track.TrackNumber,
+		TrackCount:      track.TrackCount,
+		DiscNumber:      track.DiscNumber,
+		DiscCount:       track.DiscCount,
+		Composer:        track.Composer,
+		Rating:          track.Rating.Value(),
+		Loved:           track.Loved,
+		Disliked:        track.Disliked,
+		PlayCount:       track.PlayCount,
+		SkipCount:       track.SkipCount,
+		LastPlayed:      track.LastPlayed,
+		DateAdded:       track.DateAdded,
+		BitRate:         track.BitRate,
+		Kind:            track.Kind,
+		Explicit:        track.Explicit,
+	}
+}
+
+func (t *TrackView) writeText(w io.Writer) error {
+	if t == nil {
+		_, err := fmt.Fprintln(w, "No current track")
+		return err
+	}
+	_, _ = fmt.Fprintf(w, "%s - %s\n", t.Artist, t.Title)
+	if t.Album != "" {
+		_, _ = fmt.Fprintf(w, "Album: %s\n", t.Album)
+	}
+	_, _ = fmt.Fprintf(w, "Duration: %s\n", t.Duration)
+	return t.writeDetails(w)
+}
+
+// writeDetails writes a line for each known detail of the track.
+func (t *TrackView) writeDetails(w io.Writer) error {
+	var lines []string
+	if t.AlbumArtist != "" && t.AlbumArtist != t.Artist {
+		lines = append(lines, "Album Artist: "+t.AlbumArtist)
+	}
+	if t.TrackNumber > 0 {
+		lines = append(lines, "Track: "+ofCount(t.TrackNumber, t.TrackCount))
+	}
+	if t.DiscCount > 1 || t.DiscNumber > 1 {
+		lines = append(lines, "Disc: "+ofCount(t.DiscNumber, t.DiscCount))
+	}
+	if t.Genre != "" {
+		lines = append(lines, "Genre: "+t.Genre)
+	}
+	if t.Year > 0 {
+		lines = append(lines, "Year: "+strconv.Itoa(t.Year))
+	}
+	if t.Composer != "" {
+		lines = append(lines, "Composer: "+t.Composer)
+	}
+	if t.Rating > 0 {
+		lines = append(lines, "Rating: "+music.NewRating(t.Rating).String())
+	}
+	if t.Loved {
+		lines = append(lines, "Loved: yes")
+	}
+	if t.Disliked {
+		lines = append(lines, "Disliked: yes")
+	}
+	if t.PlayCount > 0 || t.SkipCount > 0 {
+		lines = append(lines, fmt.Sprintf("Plays: %d (%d skipped)", t.PlayCount, t.SkipCount))
+	}
+	if !t.LastPlayed.IsZero() {
+		lines = append(lines, "Last Played: "+t.LastPlayed.Local().Format("2006-01-02 15:04"))
+	}
+	if !t.DateAdded.IsZero() {
+		lines = append(lines, "Added: "+t.DateAdded.Local().Format("2006-01-02"))
+	}
+	switch {
+	case t.Kind != "" && t.BitRate > 0:
+		lines = append(lines, fmt.Sprintf("Format: %s, %d kbps", t.Kind, t.BitRate))
+	case t.Kind != "":
+		lines = append(lines, "Format: "+t.Kind)
+	case t.BitRate > 0:
+		lines = append(lines, fmt.Sprintf("Format: %d kbps", t.BitRate))
+	}
+	if t.Explicit {
+		lines = append(lines, "Explicit: yes")
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ofCount formats a number with its total when known, as in "3 of 7".
+func ofCount(n, total int) string {
+	if total > 0 {
+		return fmt.Sprintf("%d of %d", n, total)
+	}
+	return strconv.Itoa(n)
+}
+
+func (t *TrackView) records() ([]string, [][]string) {
+	if t == nil {
+		return trackColumns, nil
+	}
+	return trackColumns, [][]string{t.record()}
+}
+
+// record returns the track's values in trackColumns order.
+func (t *TrackView) record() []string {
+	return []string{t.ID, t.Title, t.Artist, t.Album, t.Duration, strconv.Itoa(t.DurationSeconds)}
+}
+
+// StatusView describes the player and its current track.
+type StatusView struct {
+	State           string `json:"state"`
+	Volume          int    `json:"volume"`
+	Position        string `json:"position"`
+	PositionSeconds int    `json:"position_seconds"`
+	Shuffle         bool   `json:"shuffle"`
+	Repeat          string `json:"repeat"`
+
+	// Track is the current track, empty when none is loaded so that
+	// templates such as "{{.Track.Title}}" print nothing rather than fail
+	Track TrackView `json:"-"`
+}
+
+func newStatusView(player *music.Player, track *music.Track) *StatusView {
+	status := &StatusView{
+		State:           player.State.String(),
+		Volume:          player.Volume.Level(),
+		Position:        player.Position.String(),
+		PositionSeconds: player.Position.Seconds(),
+		Shuffle:         player.Shuffle,
+		Repeat:          player.Repeat.String(),
+	}
+	if track != nil {
+		status.Track = newTrackView(track)
+	}
+	return status
+}
+
+// HasTrack reports whether a track is loaded.
+func (s *StatusView) HasTrack() bool {
+	return s.Track.ID != ""
+}
+
+// MarshalJSON writes the track as current_track, or null when none is loaded.
+func (s *StatusView) MarshalJSON() ([]byte, error) {
+	type fields StatusView
+	var track *TrackView
+	if s.HasTrack() {
+		track = &s.Track
+	}
+	return json.Marshal(struct {
+		*fields
+		Track *TrackView `json:"current_track"`
+	}{(*fields)(s), track})
+}
+
+func (s *StatusView) writeText(w io.Writer) error {
+	_, _ = fmt.Fprintf(w, "Status: %s\n", s.State)
+	_, _ = fmt.Fprintf(w, "Volume: %s\n", music.NewVolume(s.Volume))
+
+	if s.HasTrack() {
+		_, _ = fmt.Fprintf(w, "Now Playing: %s - %s\n", s.Track.Artist, s.Track.Title)
+		if s.Track.Album != "" {
+			_, _ = fmt.Fprintf(w, "Album: %s\n", s.Track.Album)
+		}
+		_, _ = fmt.Fprintf(w, "Position: %s / %s\n", s.Position, s.Track.Duration)
+		_ = s.Track.writeDetails(w)
+	} else {
+		_, _ = fmt.Fprintf(w, "No current track\n")
+	}
+
+	_, _ = fmt.Fprintf(w, "Shuffle: %t\n", s.Shuffle)
+	_, err := fmt.Fprintf(w, "Repeat: %s\n", s.Repeat)
+	return err
+}
+
+func (s *StatusView) records() ([]string, [][]string) {
+	header := []string{"state", "volume", "position", "position_seconds", "shuffle", "repeat"}
+	row := []string{s.State, strconv.Itoa(s.Volume), s.Position, strconv.Itoa(s.PositionSeconds),
+		strconv.FormatBool(s.Shuffle), s.Repeat}
+	for _, column := range trackColumns {
+		header = append(header, "track_"+column)
+	}
+
+	track := make([]string, len(trackColumns))
+	if s.HasTrack() {
+		track = s.Track.record()
+	}
+	return header, [][]string{append(row, track...)}
+}
+
+// VolumeView describes the volume.
+type VolumeView struct {
+	Volume int `json:"volume"`
+}
+
+func (v *VolumeView) writeText(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "Volume: %s\n", music.NewVolume(v.Volume))
+	return err
+}
+
+func (v *VolumeView) records() ([]string, [][]string) {
+	return []string{"volume"}, [][]string{{strconv.Itoa(v.Volume)}}
+}
+
+// SearchResultView describes a ranked search result.
+type SearchResultView struct {
+	Rank int `json:"rank"`
+	TrackView
+	Score         float64  `json:"score"`
+	MatchedFields []string `json:"matched_fields"`
+}
+
+// SearchResultsView lists search results, best first.
+type SearchResultsView []SearchResultView
+
+func newSearchResultsView(results []music.SearchResult) SearchResultsView {
+	views := make(SearchResultsView, len(results))
+	for i, result := range results {
+		matched := result.MatchedFields
+		if matched == nil {
+			matched = []string{}
+		}
+		views[i] = SearchResultView{Rank: i + 1, TrackView: newTrackView(result.Track),
+			Score: math.Round(result.Score*100) / 100, MatchedFields: matched}
+	}
+	return views
+}
+
+func (s SearchResultsView) writeText(w io.Writer) error {
+	if len(s) == 0 {
+		_, err := fmt.Fprintln(w, "No tracks found")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "#\tSCORE\tTITLE\tARTIST\tALBUM\tDURATION\tMATCHED")
+	for _, result := range s {
+		_, _ = fmt.Fprintf(tw, "%d\t%.2f\t%s\t%s\t%s\t%s\t%s\n", result.Rank, result.Score,
+			result.Title, result.Artist, result.Album, result.Duration, strings.Join(result.MatchedFields, ","))
+	}
+	return tw.Flush()
+}
+
+func (s SearchResultsView) records() ([]string, [][]string) {
+	header := append(append([]string{"rank"}, trackColumns...), "score", "matched_fields")
+	rows := make([][]string, len(s))
+	for i, result := range s {
+		row := append([]string{strconv.Itoa(result.Rank)}, result.record()...)
+		rows[i] = append(row, strconv.FormatFloat(result.Score, 'f', 2, 64), strings.Join(result.MatchedFields, ","))
+	}
+	return header, rows
+}
+
+func (s SearchResultsView) items() []interface{} {
+	items := make([]interface{}, len(s))
+	for i := range s {
+		items[i] = s[i]
+	}
+	return items
+}
+
+// TrackIDsView lists track IDs.
+type TrackIDsView []string
+
+func (t TrackIDsView) writeText(w io.Writer) error {
+	for _, id := range t {
+		if _, err := fmt.Fprintln(w, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t TrackIDsView) records() ([]string, [][]string) {
+	rows := make([][]string, len(t))
+	for i, id := range t {
+		rows[i] = []string{id}
+	}
+	return []string{"id"}, rows
+}
+
+func (t TrackIDsView) items() []interface{} {
+	items := make([]interface{}, len(t))
+	for i := range t {
+		items[i] = t[i]
+	}
+	return items
+}
+
+// QueueEntryView describes a queued track with its 1-based position.
+type QueueEntryView struct {
+	Position int  `json:"position"`
+	Current  bool `json:"current"`
+	TrackView
+}
+
+// QueueView lists queued tracks.
+type QueueView []QueueEntryView
+
+func newQueueView(tracks []*music.Track, current, first int) QueueView {
+	views := make(QueueView, len(tracks))
+	for i, track := range tracks {
+		views[i] = QueueEntryView{Position: first + i + 1, Current: first+i == current, TrackView: newTrackView(track)}
+	}
+	return views
+}
+
+func (q QueueView) writeText(w io.Writer) error {
+	if len(q) == 0 {
+		_, err := fmt.Fprintln(w, "Queue is empty")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, entry := range q {
+		marker := " "
+		if entry.Current {
+			marker = ">"
+		}
+		_, _ = fmt.Fprintf(tw, "%s %d.\t%s\t%s\t%s\n", marker, entry.Position, entry.Title, entry.Artist, entry.Duration)
+	}
+	return tw.Flush()
+}
+
+func (q QueueView) records() ([]string, [][]string) {
+	header := append([]string{"position", "current"}, trackColumns...)
+	rows := make([][]string, len(q))
+	for i, entry := range q {
+		rows[i] = append([]string{strconv.Itoa(entry.Position), strconv.FormatBool(entry.Current)}, entry.record()...)
+	}
+	return header, rows
+}
+
+func (q QueueView) items() []interface{} {
+	items := make([]interface{}, len(q))
+	for i := range q {
+		items[i] = q[i]
+	}
+	return items
+}
+
+// RatedTracksView lists rated tracks, best rated first.
+type RatedTracksView []TrackView
+
+func newRatedTracksView(tracks []*music.Track) RatedTracksView {
+	views := make(RatedTracksView, len(tracks))
+	for i, track := range tracks {
+		views[i] = newTrackView(track)
+	}
+	return views
+}
+
+func (r RatedTracksView) writeText(w io.Writer) error {
+	if len(r) == 0 {
+		_, err := fmt.Fprintln(w, "No rated tracks found")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "RATING\tTITLE\tARTIST\tALBUM\tDURATION")
+	for _, track := range r {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", music.NewRating(track.Rating), track.Title, track.Artist, track.Album, track.Duration)
+	}
+	return tw.Flush()
+}
+
+func (r RatedTracksView) records() ([]string, [][]string) {
+	header := append(append([]string{}, trackColumns...), "rating", "preference")
+	rows := make([][]string, len(r))
+	for i, track := range r {
+		rows[i] = append(track.record(), strconv.Itoa(track.Rating), track.preference().String())
+	}
+	return header, rows
+}
+
+func (r RatedTracksView) items() []interface{} {
+	items := make([]interface{}, len(r))
+	for i := range r {
+		items[i] = r[i]
+	}
+	return items
+}
+
+// HistoryEntryView describes one play or skip in the history.
+type HistoryEntryView struct {
+	Kind            string    `json:"kind"`
+	Source          string    `json:"source"`
+	StartedAt       time.Time `json:"started_at"`
+	RecordedAt      time.Time `json:"recorded_at"`
+	Listened        string    `json:"listened"`
+	ListenedSeconds int       `json:"listened_seconds"`
+	Track           TrackView `json:"track"`
+}
+
+func newHistoryEntryView(entry *music.HistoryEntry) HistoryEntryView {
+	return HistoryEntryView{
+		Kind:            entry.Kind.String(),
+		Source:          entry.Source.String(),
+		StartedAt:       entry.StartedAt,
+		RecordedAt:      entry.RecordedAt,
+		Listened:        entry.Listened.String(),
+		ListenedSeconds: entry.Listened.Seconds(),
+		Track:           newTrackView(&entry.Track),
+	}
+}
+
+// HistoryView lists history entries, oldest first.
+type HistoryView []HistoryEntryView
+
+func newHistoryView(entries []*music.HistoryEntry) HistoryView {
+	views := make(HistoryView, len(entries))
+	for i, entry := range entries {
+		views[i] = newHistoryEntryView(entry)
+	}
+	return views
+}
+
+func (h HistoryView) writeText(w io.Writer) error {
+	if len(h) == 0 {
+		_, err := fmt.Fprintln(w, "No history found")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "STARTED\tKIND\tTITLE\tARTIST\tALBUM\tLISTENED\tSOURCE")
+	for _, entry := range h {
+		title := entry.Track.Title
+		if title == "" {
+			title = entry.Track.ID
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", formatModified(entry.StartedAt), entry.Kind,
+			title, entry.Track.Artist, entry.Track.Album, entry.Listened, entry.Source)
+	}
+	return tw.Flush()
+}
+
+func (h HistoryView) records() ([]string, [][]string) {
+	header := []string{"kind", "source", "started_at", "recorded_at", "listened", "listened_seconds"}
+	for _, column := range trackColumns {
+		header = append(header, "track_"+column)
+	}
+
+	rows := make([][]string, len(h))
+	for i, entry := range h {
+		rows[i] = append([]string{entry.Kind, entry.Source, entry.StartedAt.Format(time.RFC3339),
+			entry.RecordedAt.Format(time.RFC3339), entry.Listened, strconv.Itoa(entry.ListenedSeconds)}, entry.Track.record()...)
+	}
+	return header, rows
+}
+
+func (h HistoryView) items() []interface{} {
+	items := make([]interface{}, len(h))
+	for i := range h {
+		items[i] = h[i]
+	}
+	return items
+}
+
+// preference returns whether the track is loved, disliked or neither.
+func (t *TrackView) preference() music.Preference {
+	return music.TrackMetadata{Loved: t.Loved, Disliked: t.Disliked}.Preference()
+}
+
+// playlistColumns are the CSV columns of a PlaylistView.
+var playlistColumns = []string{"id", "name", "type", "read_only", "track_count", "duration", "duration_seconds", "modified_at"}
+
+// PlaylistView summarises a playlist.
+type PlaylistView struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	Type            string    `json:"type"`
+	ReadOnly        bool      `json:"read_only"`
+	TrackCount      int       `json:"track_count"`
+	Duration        string    `json:"duration"`
+	DurationSeconds int       `json:"duration_seconds"`
+	ModifiedAt      time.Time `json:"modified_at"`
+}
+
+func newPlaylistView(playlist *music.Playlist, duration music.Duration) PlaylistView {
+	return PlaylistView{
+		ID:              playlist.ID.Value(),
+		Name:            playlist.Name,
+		Type:            playlist.Type.String(),
+		ReadOnly:        playlist.ReadOnly || playlist.Type.IsReadOnly(),
+		TrackCount:      playlist.TrackCount(),
+		Duration:        duration.String(),
+		DurationSeconds: duration.Seconds(),
+		ModifiedAt:      playlist.ModifiedAt,
+	}
+}
+
+// typeLabel returns the playlist type, flagged when it is read-only.
+func (p PlaylistView) typeLabel() string {
+	if p.ReadOnly {
+		return p.Type + " (read-only)"
+	}
+	return p.Type
+}
+
+// record returns the playlist's values in playlistColumns order.
+func (p PlaylistView) record() []string {
+	modified := ""
+	if !p.ModifiedAt.IsZero() {
+		modified = p.ModifiedAt.Format(time.RFC3339)
+	}
+	return []string{p.ID, p.Name, p.Type, strconv.FormatBool(p.ReadOnly), strconv.Itoa(p.TrackCount),
+		p.Duration, strconv.Itoa(p.DurationSeconds), modified}
+}
+
+// PlaylistsView lists playlists.
+type PlaylistsView []PlaylistView
+
+func (p PlaylistsView) writeText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ID\tNAME\tTYPE\tTRACKS\tDURATION\tMODIFIED")
+	for _, playlist := range p {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\n", playlist.ID, playlist.Name, playlist.typeLabel(),
+			playlist.TrackCount, playlist.Duration, formatModified(playlist.ModifiedAt))
+	}
+	return tw.Flush()
+}
+
+func (p PlaylistsView) records() ([]string, [][]string) {
+	rows := make([][]string, len(p))
+	for i, playlist := range p {
+		rows[i] = playlist.record()
+	}
+	return playlistColumns, rows
+}
+
+func (p PlaylistsView) items() []interface{} {
+	items := make([]interface{}, len(p))
+	for i := range p {
+		items[i] = p[i]
+	}
+	return items
+}
+
+// PlaylistTrackView describes a track with its 1-based playlist position.
+type PlaylistTrackView struct {
+	Position int `json:"position"`
+	TrackView
+}
+
+// PlaylistDetailView describes a playlist and its tracks.
+type PlaylistDetailView struct {
+	PlaylistView
+	Tracks []PlaylistTrackView `json:"tracks"`
+}
+
+func newPlaylistDetailView(playlist *music.Playlist, tracks []*music.Track) *PlaylistDetailView {
+	var total music.Duration
+	views := make([]PlaylistTrackView, len(tracks))
+	for i, track := range tracks {
+		total = total.Add(track.Duration)
+		views[i] = PlaylistTrackView{Position: i + 1, TrackView: newTrackView(track)}
+	}
+	return &PlaylistDetailView{PlaylistView: newPlaylistView(playlist, total), Tracks: views}
+}
+
+func (p *PlaylistDetailView) writeText(w io.Writer) error {
+	_, _ = fmt.Fprintf(w, "%s (%s, %s)\n", p.Name, p.ID, p.typeLabel())
+	_, _ = fmt.Fprintf(w, "%d tracks, %s, modified %s\n", p.TrackCount, p.Duration, formatModified(p.ModifiedAt))
+	if len(p.Tracks) == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, track := range p.Tracks {
+		_, _ = fmt.Fprintf(tw, "%d.\t%s\t%s\t%s\t%s\n", track.Position, track.Title, track.Artist, track.Album, track.Duration)
+	}
+	return tw.Flush()
+}
+
+// records returns one row per track, each carrying the playlist's ID and name.
+func (p *PlaylistDetailView) records() ([]string, [][]string) {
+	header := append([]string{"playlist_id", "playlist_name", "position"}, trackColumns...)
+	rows := make([][]string, len(p.Tracks))
+	for i, track := range p.Tracks {
+		rows[i] = append([]string{p.ID, p.Name, strconv.Itoa(track.Position)}, track.record()...)
+	}
+	return header, rows
+}
+
+// smartPlaylistColumns are the CSV columns of a SmartPlaylistView.
+var smartPlaylistColumns = []string{"name", "match", "rules", "limit", "sort", "live_updating", "playlist_id", "materialized_at"}
+
+// SmartPlaylistView summarises a smart playlist. Rules are in the form
+// "playlist smart create --rule" reads.
+type SmartPlaylistView struct {
+	Name           string    `json:"name"`
+	Match          string    `json:"match"`
+	Rules          []string  `json:"rules"`
+	Limit          string    `json:"limit,omitempty"`
+	Sort           string    `json:"sort,omitempty"`
+	LiveUpdating   bool      `json:"live_updating"`
+	PlaylistID     string    `json:"playlist_id"`
+	MaterializedAt time.Time `json:"materialized_at,omitzero"`
+}
+
+func newSmartPlaylistView(playlist *music.SmartPlaylist) SmartPlaylistView {
+	rules := make([]string, len(playlist.Rules.Rules))
+	for i, rule := range playlist.Rules.Rules {
+		rules[i] = rule.String()
+	}
+
+	view := SmartPlaylistView{
+		Name:           playlist.Name,
+		Match:          playlist.Rules.Match.String(),
+		Rules:          rules,
+		LiveUpdating:   playlist.Rules.LiveUpdating,
+		PlaylistID:     playlist.PlaylistID.Value(),
+		MaterializedAt: playlist.MaterializedAt,
+	}
+	if limit := playlist.Rules.Limit; limit.Count > 0 {
+		view.Limit = fmt.Sprintf("%d %s", limit.Count, limit.Unit)
+	}
+	if order := playlist.Rules.Sort; order != nil {
+		view.Sort = order.Field.String()
+		if order.Descending {
+			view.Sort += ":desc"
+		}
+	}
+	return view
+}
+
+// record returns the smart playlist's values in smartPlaylistColumns order.
+func (s SmartPlaylistView) record() []string {
+	materialized := ""
+	if !s.MaterializedAt.IsZero() {
+		materialized = s.MaterializedAt.Format(time.RFC3339)
+	}
+	return []string{s.Name, s.Match, strings.Join(s.Rules, "; "), s.Limit, s.Sort,
+		strconv.FormatBool(s.LiveUpdating), s.PlaylistID, materialized}
+}
+
+// writeRules writes the match, rules, sort and limit, one per line.
+func (s SmartPlaylistView) writeRules(w io.Writer) {
+	switch {
+	case len(s.Rules) == 0:
+		_, _ = fmt.Fprintln(w, "Every track")
+	case len(s.Rules) == 1:
+		_, _ = fmt.Fprintf(w, "Rule: %s\n", s.Rules[0])
+	default:
+		_, _ = fmt.Fprintf(w, "Match %s of:\n", s.Match)
+		for _, rule := range s.Rules {
+			_, _ = fmt.Fprintf(w, "  %s\n", rule)
+		}
+	}
+	if s.Sort != "" {
+		_, _ = fmt.Fprintf(w, "Sort: %s\n", s.Sort)
+	}
+	if s.Limit != "" {
+		_, _ = fmt.Fprintf(w, "Limit: %s\n", s.Limit)
+	}
+}
+
+// liveLabel returns "live" or "-" for the table format.
+func (s SmartPlaylistView) liveLabel() string {
+	if s.LiveUpdating {
+		return "live"
+	}
+	return "-"
+}
+
+// SmartPlaylistsView lists smart playlists.
+type SmartPlaylistsView []SmartPlaylistView
+
+func (s SmartPlaylistsView) writeText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAME\tRULES\tUPDATES\tPLAYLIST\tMATERIALIZED")
+	for _, playlist := range s {
+		rules := "every track"
+		if len(playlist.Rules) > 0 {
+			rules = strings.Join(playlist.Rules, " "+playlist.Match+" ")
+		}
+		id := playlist.PlaylistID
+		if id == "" {
+			id = "-"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", playlist.Name, rules, playlist.liveLabel(), id,
+			formatModified(playlist.MaterializedAt))
+	}
+	return tw.Flush()
+}
+
+func (s SmartPlaylistsView) records() ([]string, [][]string) {
+	rows := make([][]string, len(s))
+	for i, playlist := range s {
+		rows[i] = playlist.record()
+	}
+	return smartPlaylistColumns, rows
+}
+
+func (s SmartPlaylistsView) items() []interface{} {
+	items := make([]interface{}, len(s))
+	for i := range s {
+		items[i] = s[i]
+	}
+	return items
+}
+
+// SmartPlaylistDetailView describes a smart playlist and the tracks its
+// rules select.
+type SmartPlaylistDetailView struct {
+	SmartPlaylistView
+	TrackCount      int                 `json:"track_count"`
+	Duration        string              `json:"duration"`
+	DurationSeconds int                 `json:"duration_seconds"`
+	Tracks          []PlaylistTrackView `json:"tracks"`
+}
+
+func newSmartPlaylistDetailView(playlist *music.SmartPlaylist, tracks []*music.Track) *SmartPlaylistDetailView {
+	var total music.Duration
+	views := make([]PlaylistTrackView, len(tracks))
+	for i, track := range tracks {
+		total = total.Add(track.Duration)
+		views[i] = PlaylistTrackView{Position: i + 1, TrackView: newTrackView(track)}
+	}
+	return &SmartPlaylistDetailView{
+		SmartPlaylistView: newSmartPlaylistView(playlist),
+		TrackCount:        len(tracks),
+		Duration:          total.String(),
+		DurationSeconds:   total.Seconds(),
+		Tracks:            views,
+	}
+}
+
+func (s *SmartPlaylistDetailView) writeText(w io.Writer) error {
+	id := s.PlaylistID
+	if id == "" {
+		id = "not materialized"
+	}
+	updates := "static"
+	if s.LiveUpdating {
+		updates = "live"
+	}
+	_, _ = fmt.Fprintf(w, "%s (%s, %s)\n", s.Name, id, updates)
+	s.writeRules(w)
+	_, _ = fmt.Fprintf(w, "%d tracks, %s, materialized %s\n", s.TrackCount, s.Duration, formatModified(s.MaterializedAt))
+	if len(s.Tracks) == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, track := range s.Tracks {
+		_, _ = fmt.Fprintf(tw, "%d.\t%s\t%s\t%s\t%s\n", track.Position, track.Title, track.Artist, track.Album, track.Duration)
+	}
+	return tw.Flush()
+}
+
+// records returns one row per track, each carrying the smart playlist's name.
+func (s *SmartPlaylistDetailView) records() ([]string, [][]string) {
+	header := append([]string{"smart_playlist", "position"}, trackColumns...)
+	rows := make([][]string, len(s.Tracks))
+	for i, track := range s.Tracks {
+		rows[i] = append([]string{s.Name, strconv.Itoa(track.Position)}, track.record()...)
+	}
+	return header, rows
+}
+
+// formatModified formats a modification time, or "-" when it is unknown
+func formatModified(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// ConfigView describes the effective configuration.
+type ConfigView struct {
+	Config *config.Config `json:"config"`
+	Files  []string       `json:"files"`
+
+	// Sources maps each key to where its value came from, when requested
+	Sources map[string]string `json:"sources,omitempty"`
+}
+
+func newConfigView(cfg *config.Config, withSources bool) *ConfigView {
+	configView := &ConfigView{Config: cfg, Files: cfg.Files()}
+	if withSources {
+		configView.Sources = make(map[string]string)
+		for _, entry := range cfg.Entries() {
+			configView.Sources[entry.Key] = entry.Source.String()
+		}
+	}
+	return configView
+}
+
+func (c *ConfigView) writeText(w io.Writer) error {
+	if c.Sources == nil {
+		return c.Config.WriteTOML(w)
+	}
+
+	section := ""
+	for _, entry := range c.Config.Entries() {
+		table, key, _ := strings.Cut(entry.Key, ".")
+		if table != section {
+			if section != "" {
+				_, _ = fmt.Fprintln(w)
+			}
+			_, _ = fmt.Fprintf(w, "[%s]\n", table)
+			section = table
+		}
+		_, _ = fmt.Fprintf(w, "%-16s = %-20s # %s\n", key, entry.Value, entry.Source)
+	}
+	return nil
+}
+
+func (c *ConfigView) records() ([]string, [][]string) {
+	var rows [][]string
+	for _, entry := range c.Config.Entries() {
+		rows = append(rows, []string{entry.Key, entry.Value, entry.Source.String()})
+	}
+	return []string{"key", "value", "source"}, rows
+}
+
+// HealthView describes a daemon health report.
+type HealthView struct {
+	*health.Report
+}
+
+func (h HealthView) writeText(w io.Writer) error {
+	_, _ = fmt.Fprintf(w, "maestrod %s: %s\n", h.Version, h.Status)
+	for _, component := range h.Components {
+		critical := ""
+		if !component.Critical {
+			critical = " (optional)"
+		}
+		_, _ = fmt.Fprintf(w, "  %-14s %-9s %8s%s", component.Name, component.Status, component.Duration.Round(time.Millisecond), critical)
+		if component.Error != "" {
+			_, _ = fmt.Fprintf(w, "  %s", component.Error)
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+	return nil
+}
+
+func (h HealthView) records() ([]string, [][]string) {
+	rows := make([][]string, len(h.Components))
+	for i, component := range h.Components {
+		rows[i] = []string{component.Name, string(component.Status), strconv.FormatBool(component.Critical),
+			strconv.FormatInt(component.Duration.Milliseconds(), 10), component.Error}
+	}
+	return []string{"name", "status", "critical", "duration_ms", "error"}, rows
+}
+
+// MessageView reports that a command succeeded.
+type MessageView struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+func (m *MessageView) writeText(w io.Writer) error {
+	_, err := fmt.Fprintln(w, m.Message)
+	return err
+}
+
+func (m *MessageView) records() ([]string, [][]string) {
+	return []string{"success", "message"}, [][]string{{strconv.FormatBool(m.Success), m.Message}}
+}
+
+// ErrorView reports that a command failed. Code, Kind and ExitCode are
+// stable identifiers for scripts; Error is for people.
+type ErrorView struct {
+	Success   bool                   `json:"success"`
+	Error     string                 `json:"error"`
+	Code      string                 `json:"code"`
+	Kind      string                 `json:"kind"`
+	ExitCode  int                    `json:"exit_code"`
+	Retryable bool                   `json:"retryable"`
+	Permanent bool                   `json:"permanent"`
+	Context   map[string]interface{} `json:"context"`
+}
+
+func newErrorView(err error) *ErrorView {
+	return &ErrorView{
+		Success:   false,
+		Error:     err.Error(),
+		Code:      music.ErrorID(err),
+		Kind:      music.KindOf(err).String(),
+		ExitCode:  ExitCode(err),
+		Retryable: music.IsRetryable(err),
+		Permanent: music.IsPermanent(err),
+		Context:   music.ErrorContext(err),
+	}
+}
+
+func (e *ErrorView) writeText(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "Error: %s\n", e.Error)
+	return err
+}
+
+func (e *ErrorView) records() ([]string, [][]string) {
+	context, _ := json.Marshal(e.Context)
+	return []string{"success", "error", "code", "kind", "exit_code", "retryable", "permanent", "context"},
+		[][]string{{
+			strconv.FormatBool(e.Success), e.Error, e.Code, e.Kind, strconv.Itoa(e.ExitCode),
+			strconv.FormatBool(e.Retryable), strconv.FormatBool(e.Permanent), string(context),
+		}}
+}
+
+// BatchResultView reports what became of one line of a batch: its status
+// (ok, failed, skipped or invalid), the documents it printed and its error.
+type BatchResultView struct {
+	Line    int               `json:"line"`
+	Command string            `json:"command"`
+	Status  string            `json:"status"`
+	Output  []json.RawMessage `json:"output"`
+	Error   *ErrorView        `json:"error,omitempty"`
+}
+
+// BatchResultsView lists the results of a batch in line order.
+type BatchResultsView []BatchResultView
+
+func (b BatchResultsView) writeText(w io.Writer) error {
+	for _, result := range b {
+		text := fmt.Sprintf("%d: %s %s", result.Line, result.Status, result.Command)
+		if result.Error != nil {
+			text += " (" + result.Error.Error + ")"
+		}
+		if _, err := fmt.Fprintln(w, text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b BatchResultsView) records() ([]string, [][]string) {
+	rows := make([][]string, len(b))
+	for i, result := range b {
+		code, message := "", ""
+		if result.Error != nil {
+			code, message = result.Error.Code, result.Error.Error
+		}
+		rows[i] = []string{strconv.Itoa(result.Line), result.Command, result.Status, code, message}
+	}
+	return []string{"line", "command", "status", "code", "error"}, rows
+}
+
+func (b BatchResultsView) items() []interface{} {
+	items := make([]interface{}, len(b))
+	for i := range b {
+		items[i] = b[i]
+	}
+	return items
+}
+
+// InfoView carries an informational message.
+type InfoView struct {
+	Info string `json:"info"`
+}
+
+func (i *InfoView) writeText(w io.Writer) error {
+	_, err := fmt.Fprintln(w, i.Info)
+	return err
+}
+
+func (i *InfoView) records() ([]string, [][]string) {
+	return []string{"info"}, [][]string{{i.Info}}
+}
+
+// DebugView carries a debug message.
+type DebugView struct {
+	Debug string `json:"debug"`
+}
+
+func (d *DebugView) writeText(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "DEBUG: %s\n", d.Debug)
+	return err
+}
+
+func (d *DebugView) records() ([]string, [][]string) {
+	return []string{"debug"}, [][]string{{d.Debug}}
+}
@@ -0,0 +1,383 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/madstone-tech/maestro/application/playback"
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// fadeReplyTimeout is how long maestrod has to accept a fade once connected.
+const fadeReplyTimeout = 2 * time.Second
+
+// muteFile is where "volume mute" keeps the level that "volume unmute"
+// restores, relative to the home directory.
+const muteFile = ".maestro_mute"
+
+// NewVolumeCommand creates the volume command
+func NewVolumeCommand(ctx *CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "volume [level|+n|-n]",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "Get or set volume",
+		Long: `Get the current volume level, set it to a value between 0 and 100, or
+change it relative to the current level.
+
+Examples:
+  maestro volume        # Show current volume
+  maestro volume 50     # Set volume to 50%
+  maestro volume +10    # Turn it up by 10
+  maestro volume -5     # Turn it down by 5
+  maestro volume mute   # Mute, remembering the level
+  maestro volume unmute # Restore the level from before muting
+  maestro volume fade 20 --over 8s --curve log`,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing volume command")
+
+			// Absolute levels are set without asking for the current one
+			current := music.NewVolume(music.MinVolumeLevel)
+			if len(args) == 0 || isRelativeVolume(args[0]) {
+				player, err := ctx.PlayerRepo.GetCurrentState(ctx.Context)
+				if err != nil {
+					ctx.OutputFormatter.Error(err)
+					return err
+				}
+				current = player.Volume
+			}
+
+			// If no arguments, show current volume
+			if len(args) == 0 {
+				ctx.OutputFormatter.PrintVolume(current)
+				return nil
+			}
+
+			volume, err := parseVolume(args[0], current)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			// Set volume
+			err = ctx.PlayerRepo.SetVolume(ctx.Context, volume)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			ctx.OutputFormatter.Success("Volume set to " + volume.String())
+			return nil
+		},
+	}
+	acceptNegativeValues(cmd)
+
+	cmd.AddCommand(NewVolumeMuteCommand(ctx))
+	cmd.AddCommand(NewVolumeUnmuteCommand(ctx))
+	cmd.AddCommand(NewVolumeFadeCommand(ctx))
+
+	return cmd
+}
+
+// NewVolumeMuteCommand creates the volume mute command
+func NewVolumeMuteCommand(ctx *CommandContext) *cobra.Command {
+	return &cobra.Command{
+		Use:   "mute",
+		Args:  cobra.NoArgs,
+		Short: "Mute, remembering the current level",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing volume mute command")
+
+			player, err := ctx.PlayerRepo.GetCurrentState(ctx.Context)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+			if player.Volume.IsMuted() {
+				ctx.OutputFormatter.Info("Already muted")
+				return nil
+			}
+
+			if err := saveMutedVolume(player.Volume); err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+			if err := ctx.PlayerRepo.SetVolume(ctx.Context, music.NewVolume(music.MinVolumeLevel)); err != nil {
+				_ = clearMutedVolume()
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			ctx.OutputFormatter.Success(fmt.Sprintf("Muted (was %s)", player.Volume))
+			return nil
+		},
+	}
+}
+
+// NewVolumeUnmuteCommand creates the volume unmute command
+func NewVolumeUnmuteCommand(ctx *CommandContext) *cobra.Command {
+	return &cobra.Command{
+		Use:   "unmute",
+		Args:  cobra.NoArgs,
+		Short: "Restore the level from before muting",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing volume unmute command")
+
+			player, err := ctx.PlayerRepo.GetCurrentState(ctx.Context)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			// Someone turned the volume up since muting; that level wins
+			if !player.Volume.IsMuted() {
+				_ = clearMutedVolume()
+				ctx.OutputFormatter.Info("Not muted (volume is " + player.Volume.String() + ")")
+				return nil
+			}
+
+			volume, err := loadMutedVolume()
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+			if err := ctx.PlayerRepo.SetVolume(ctx.Context, volume); err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+			_ = clearMutedVolume()
+
+			ctx.OutputFormatter.Success("Volume restored to " + volume.String())
+			return nil
+		},
+	}
+}
+
+// NewVolumeFadeCommand creates the volume fade command
+func NewVolumeFadeCommand(ctx *CommandContext) *cobra.Command {
+	var over time.Duration
+	var curveName string
+	var local bool
+
+	cmd := &cobra.Command{
+		Use:   "fade <level|+n|-n>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Fade the volume to a level over time",
+		Long: `Fade the volume smoothly to a level. When maestrod is running the fade runs
+in the daemon and the command returns at once; otherwise it runs here until
+it is done. Changing the volume during a fade stops it.`,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.OutputFormatter.Debug("Executing volume fade command")
+
+			curve, err := music.ParseVolumeCurve(curveName)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			player, err := ctx.PlayerRepo.GetCurrentState(ctx.Context)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			target, err := parseVolume(args[0], player.Volume)
+			if err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			request := playback.FadeRequest{Target: target, Over: over, Curve: curve}
+			if err := request.Validate(); err != nil {
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			if !local {
+				started, err := startDaemonFade(ctx.Context, ctx.Config, request)
+				if err != nil {
+					ctx.OutputFormatter.Error(err)
+					return err
+				}
+				if started {
+					ctx.OutputFormatter.Success(fmt.Sprintf("Fading from %s to %s over %s in maestrod", player.Volume, target, over))
+					return nil
+				}
+				ctx.OutputFormatter.Debug("maestrod is not running; fading in this process")
+			}
+
+			fadeCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt)
+			defer stop()
+
+			final, err := playback.NewFader(ctx.PlayerRepo, nil).Fade(fadeCtx, request)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					err = music.NewDomainError(music.ErrInterrupted, "fade stopped at "+final.String())
+				}
+				ctx.OutputFormatter.Error(err)
+				return err
+			}
+
+			ctx.OutputFormatter.Success("Volume faded to " + final.String())
+			return nil
+		},
+	}
+	acceptNegativeValues(cmd)
+
+	cmd.Flags().DurationVar(&over, "over", 5*time.Second, "How long the fade takes")
+	cmd.Flags().StringVar(&curveName, "curve", music.VolumeCurveLinear.String(), "Fade curve (linear or log)")
+	cmd.Flags().BoolVar(&local, "local", false, "Fade in this process even when maestrod is running")
+	_ = cmd.RegisterFlagCompletionFunc("curve", completeNamesOf(music.VolumeCurves()))
+
+	return cmd
+}
+
+// parseVolume parses an absolute level ("50") or a change relative to
+// current ("+10", "-5"); relative changes stop at 0 and 100.
+func parseVolume(arg string, current music.Volume) (music.Volume, error) {
+	text := strings.TrimSpace(arg)
+	sign, digits := "", text
+	if isRelativeVolume(text) {
+		sign, digits = text[:1], text[1:]
+	}
+
+	// Only one sign is allowed, so "+-5" is neither a change nor a level
+	level, err := strconv.Atoi(digits)
+	if err != nil || digits[0] < '0' || digits[0] > '9' {
+		return music.Volume{}, music.NewDomainError(music.ErrInvalidVolume,
+			fmt.Sprintf("invalid volume %q: expected a level from 0 to 100, or +n or -n", arg))
+	}
+
+	switch {
+	case sign == "+":
+		return current.Increase(level), nil
+	case sign == "-":
+		return current.Decrease(level), nil
+	case level < music.MinVolumeLevel || level > music.MaxVolumeLevel:
+		return music.Volume{}, music.WrapInvalidVolume(level, nil)
+	default:
+		return music.NewVolume(level), nil
+	}
+}
+
+// isRelativeVolume reports whether arg is a change such as "+10" or "-5"
+// rather than a level.
+func isRelativeVolume(arg string) bool {
+	text := strings.TrimSpace(arg)
+	return strings.HasPrefix(text, "+") || strings.HasPrefix(text, "-")
+}
+
+// startDaemonFade asks maestrod on the configured control transport to run
+// a fade. It reports false without an error when no daemon is listening.
+func startDaemonFade(ctx context.Context, cfg *config.Config, request playback.FadeRequest) (bool, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return false, err
+	}
+
+	client, err := cfg.ControlClient()
+	if err != nil {
+		return false, music.NewDomainErrorWithCause(music.ErrOperationFailed, "failed to set up the connection to maestrod", err)
+	}
+
+	requestCtx, cancel := context.WithTimeout(ctx, cfg.Transport.DialTimeout.Std()+fadeReplyTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(requestCtx, http.MethodPost, cfg.ControlURL(playback.FadePath), bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return false, nil
+		}
+		return false, music.NewDomainErrorWithCause(music.ErrOperationFailed, "failed to reach maestrod", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return true, nil
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		// Something else, or a daemon without fades, owns the address
+		return false, nil
+	default:
+		var failure struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&failure)
+		return false, music.NewDomainError(music.ErrOperationFailed, "maestrod refused the fade: "+failure.Error)
+	}
+}
+
+// mutePath returns the path of the mute state file.
+func mutePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", music.NewDomainErrorWithCause(music.ErrOperationFailed, "cannot find the home directory", err)
+	}
+	return filepath.Join(home, muteFile), nil
+}
+
+// saveMutedVolume remembers the level to restore on unmute.
+func saveMutedVolume(volume music.Volume) error {
+	path, err := mutePath()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(strconv.Itoa(volume.Level())+"\n"), 0o600); err != nil {
+		return music.NewDomainErrorWithCause(music.ErrOperationFailed, "failed to remember the volume", err)
+	}
+	return nil
+}
+
+// loadMutedVolume returns the level remembered by saveMutedVolume.
+func loadMutedVolume() (music.Volume, error) {
+	path, err := mutePath()
+	if err != nil {
+		return music.Volume{}, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return music.Volume{}, music.NewDomainError(music.ErrInvalidOperation,
+			`no volume to restore: mute with "maestro volume mute" or set a level`)
+	}
+	if err != nil {
+		return music.Volume{}, music.NewDomainErrorWithCause(music.ErrOperationFailed, "failed to read the remembered volume", err)
+	}
+	level, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || level <= music.MinVolumeLevel || level > music.MaxVolumeLevel {
+		return music.Volume{}, music.NewDomainError(music.ErrInvalidVolume, "remembered volume in "+path+" is invalid")
+	}
+	return music.NewVolume(level), nil
+}
+
+// clearMutedVolume forgets the remembered level.
+func clearMutedVolume() error {
+	path, err := mutePath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
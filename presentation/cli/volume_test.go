@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/spf13/cobra"
+)
+
+func TestParseVolume(t *testing.T) {
+	current := music.NewVolume(50)
+
+	tests := []struct {
+		arg  string
+		want int
+	}{
+		{"0", 0},
+		{"75", 75},
+		{" 100 ", 100},
+		{"+10", 60},
+		{"-5", 45},
+		{"+80", 100},
+		{"-80", 0},
+		{"-0", 50},
+	}
+	for _, tt := range tests {
+		got, err := parseVolume(tt.arg, current)
+		if err != nil {
+			t.Errorf("parseVolume(%q) failed: %v", tt.arg, err)
+			continue
+		}
+		if got.Level() != tt.want {
+			t.Errorf("parseVolume(%q) = %d, want %d", tt.arg, got.Level(), tt.want)
+		}
+	}
+
+	for _, arg := range []string{"101", "+-5", "-+5", "--5", "++5", "+", "-", "loud", "5%", ""} {
+		if _, err := parseVolume(arg, current); !errors.Is(err, music.ErrInvalidVolume) {
+			t.Errorf("parseVolume(%q): expected ErrInvalidVolume, got %v", arg, err)
+		}
+	}
+}
+
+// volumeFailingPlayer refuses volume changes.
+type volumeFailingPlayer struct {
+	music.PlayerRepository
+}
+
+func (volumeFailingPlayer) SetVolume(context.Context, music.Volume) error {
+	return music.NewDomainError(music.ErrOperationFailed, "volume unavailable")
+}
+
+func TestVolumeMuteFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, muteFile)
+
+	ctx := newPlayContext()
+	ctx.OutputFormatter = NewOutputFormatter(nil, false)
+	ctx.OutputFormatter.SetWriter(&strings.Builder{})
+	ctx.OutputFormatter.SetErrorWriter(io.Discard)
+	_ = ctx.PlayerRepo.SetVolume(ctx.Context, music.NewVolume(40))
+
+	repos := ctx.PlayerRepo
+	ctx.PlayerRepo = volumeFailingPlayer{repos}
+	if err := runNoArgs(NewVolumeMuteCommand(ctx)); !errors.Is(err, music.ErrOperationFailed) {
+		t.Fatalf("expected mute to fail when the volume cannot be set, got %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected no mute file after a failed mute, got %v", err)
+	}
+
+	ctx.PlayerRepo = repos
+	if err := runNoArgs(NewVolumeMuteCommand(ctx)); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "40\n" {
+		t.Errorf("expected the mute file to hold 40, got %q (%v)", data, err)
+	}
+
+	if err := runNoArgs(NewVolumeUnmuteCommand(ctx)); err != nil {
+		t.Fatal(err)
+	}
+	player, _ := ctx.PlayerRepo.GetCurrentState(ctx.Context)
+	if player.Volume.Level() != 40 {
+		t.Errorf("expected the volume restored to 40, got %s", player.Volume)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected unmute to remove the mute file, got %v", err)
+	}
+}
+
+func TestBatchLeavesMuteUncoalesced(t *testing.T) {
+	for _, path := range []string{"maestro volume mute", "maestro volume unmute"} {
+		if coalescedCommands[path] {
+			t.Errorf("expected %q to reach the player before it updates the mute file", path)
+		}
+	}
+}
+
+// runNoArgs executes cmd without arguments rather than the test binary's.
+func runNoArgs(cmd *cobra.Command) error {
+	cmd.SetArgs([]string{})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	return cmd.Execute()
+}
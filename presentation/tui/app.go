@@ -0,0 +1,460 @@
+// Package tui implements the Maestro terminal user interface.
+//
+// The TUI is a Bubble Tea program with four views: now playing, queue,
+// library and search. Every view talks to Music.app through the music
+// repository interfaces only, so the same program runs against the
+// AppleScript adapters, a daemon client or the in-memory backend.
+package tui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// Config holds configuration for the TUI.
+type Config struct {
+	// RefreshInterval is how often the player state is polled
+	RefreshInterval time.Duration
+
+	// FrameInterval is how often the playback position is redrawn between
+	// refreshes, estimated from the last observed state; 0 disables it
+	FrameInterval time.Duration
+
+	// SearchDelay is how long typing must pause before a search runs
+	SearchDelay time.Duration
+
+	// SearchLimit caps the number of search results
+	SearchLimit int
+
+	// SeekStep is how far the seek keys move the playback position
+	SeekStep time.Duration
+
+	// VolumeStep is how much the volume keys change the volume
+	VolumeStep int
+
+	// Events, if set, triggers refreshes as soon as player or queue
+	// changes are observed instead of waiting for the next poll
+	Events music.EventSource
+
+	// KeyMap holds the key bindings
+	KeyMap KeyMap
+}
+
+// DefaultConfig returns the default TUI configuration.
+func DefaultConfig() *Config {
+	return &Config{
+		RefreshInterval: time.Second,
+		FrameInterval:   250 * time.Millisecond,
+		SearchDelay:     200 * time.Millisecond,
+		SearchLimit:     50,
+		SeekStep:        10 * time.Second,
+		VolumeStep:      5,
+		KeyMap:          DefaultKeyMap(),
+	}
+}
+
+// view is one screen of the TUI.
+type view interface {
+	// title is the tab label
+	title() string
+
+	// init returns the command that loads the view's data
+	init() tea.Cmd
+
+	// update handles a message; key messages are only sent to the active view
+	update(msg tea.Msg) tea.Cmd
+
+	// render draws the view into the given area
+	render(width, height int) string
+
+	// capturesInput reports whether the view is editing text, which disables
+	// single-letter global keys
+	capturesInput() bool
+
+	// help returns the view's key bindings for the help line
+	help() []binding
+}
+
+// shared is the state every view can read: repositories, configuration and
+// the last observed player status.
+type shared struct {
+	ctx    context.Context
+	repos  music.RepositoryManager
+	config *Config
+	keys   KeyMap
+
+	player *music.Player
+	track  *music.Track
+}
+
+// action runs fn as a command and reports the outcome as an actionMsg.
+func (s *shared) action(info string, fn func(ctx context.Context) error) tea.Cmd {
+	ctx := s.ctx
+	return func() tea.Msg {
+		return actionMsg{info: info, err: fn(ctx)}
+	}
+}
+
+// Messages shared by the app and its views.
+type (
+	// tickMsg triggers a periodic status refresh
+	tickMsg time.Time
+
+	// frameMsg triggers a redraw of the estimated playback position
+	frameMsg time.Time
+
+	// statusMsg carries a refreshed player status
+	statusMsg struct {
+		player *music.Player
+		track  *music.Track
+		err    error
+	}
+
+	// eventMsg carries an event from the configured EventSource
+	eventMsg music.Event
+
+	// actionMsg reports the outcome of a user action
+	actionMsg struct {
+		info string
+		err  error
+	}
+
+	// focusSearchMsg asks the search view to focus its input
+	focusSearchMsg struct{}
+)
+
+// App is the root Bubble Tea model.
+type App struct {
+	shared *shared
+	views  []view
+	active int
+
+	events <-chan music.Event
+
+	width  int
+	height int
+	notice string
+	err    error
+}
+
+// Ensure App implements tea.Model
+var _ tea.Model = (*App)(nil)
+
+// NewApp creates the TUI for the given repositories.
+func NewApp(repos music.RepositoryManager, config *Config) *App {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	s := &shared{
+		ctx:    context.Background(),
+		repos:  repos,
+		config: config,
+		keys:   config.KeyMap,
+	}
+
+	return &App{
+		shared: s,
+		views: []view{
+			newNowPlayingView(s),
+			newQueueView(s),
+			newLibraryView(s),
+			newSearchView(s),
+		},
+		width:  80,
+		height: 24,
+	}
+}
+
+// Run starts the program on the terminal and blocks until the user quits or
+// ctx is done.
+func (a *App) Run(ctx context.Context, options ...tea.ProgramOption) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	a.shared.ctx = ctx
+	if a.shared.config.Events != nil {
+		a.events = a.shared.config.Events.Subscribe(ctx)
+	}
+
+	options = append([]tea.ProgramOption{tea.WithContext(ctx)}, options...)
+	_, err := tea.NewProgram(a, options...).Run()
+	if errors.Is(err, tea.ErrProgramKilled) && ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// Init loads the player status and every view's data.
+func (a *App) Init() tea.Cmd {
+	cmds := []tea.Cmd{a.refreshStatus(), a.tick(), a.frame(), a.waitForEvent()}
+	for _, v := range a.views {
+		cmds = append(cmds, v.init())
+	}
+	return tea.Batch(cmds...)
+}
+
+// Update handles a message.
+func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		a.width, a.height = msg.Width, msg.Height
+		return a, nil
+
+	case tea.KeyMsg:
+		return a, a.handleKey(msg)
+
+	case tickMsg:
+		return a, tea.Batch(a.refreshStatus(), a.tick())
+
+	case frameMsg:
+		// Nothing to load: returning redraws the view at the new time
+		return a, a.frame()
+
+	case statusMsg:
+		if msg.err != nil {
+			a.err = msg.err
+			return a, nil
+		}
+		a.shared.player, a.shared.track = msg.player, msg.track
+		return a, a.broadcast(msg)
+
+	case eventMsg:
+		return a, tea.Batch(a.refreshStatus(), a.broadcast(msg), a.waitForEvent())
+
+	case actionMsg:
+		a.notice, a.err = msg.info, msg.err
+		if msg.err != nil {
+			a.notice = ""
+		}
+		return a, tea.Batch(a.refreshStatus(), a.broadcast(msg))
+	}
+
+	return a, a.broadcast(msg)
+}
+
+// View renders the header, the active view and the footer.
+func (a *App) View() string {
+	header := a.renderHeader()
+	footer := a.renderFooter()
+
+	height := a.height - lipgloss.Height(header) - lipgloss.Height(footer) - 2
+	if height < 1 {
+		height = 1
+	}
+	body := a.views[a.active].render(a.width, height)
+	body = lipgloss.NewStyle().Height(height).MaxHeight(height).Render(body)
+
+	return strings.Join([]string{header, "", body, "", footer}, "\n")
+}
+
+// handleKey routes a key to the global bindings or the active view.
+func (a *App) handleKey(msg tea.KeyMsg) tea.Cmd {
+	keys := a.shared.keys
+	active := a.views[a.active]
+	a.notice, a.err = "", nil
+
+	// Keys that work even while a view is editing text
+	switch {
+	case msg.Type == tea.KeyCtrlC:
+		return tea.Quit
+	case keys.NextView.matches(msg):
+		return a.switchTo((a.active + 1) % len(a.views))
+	case keys.PrevView.matches(msg):
+		return a.switchTo((a.active + len(a.views) - 1) % len(a.views))
+	}
+	if active.capturesInput() {
+		return active.update(msg)
+	}
+
+	player := a.shared.player
+	switch {
+	case keys.Quit.matches(msg):
+		return tea.Quit
+	case len(msg.Runes) == 1 && msg.Runes[0] >= '1' && int(msg.Runes[0]-'0') <= len(a.views):
+		return a.switchTo(int(msg.Runes[0] - '1'))
+	case keys.Search.matches(msg):
+		a.active = len(a.views) - 1
+		return a.views[a.active].update(focusSearchMsg{})
+	case keys.PlayPause.matches(msg):
+		if player != nil && player.IsPlaying() {
+			return a.shared.action("Paused", a.shared.repos.Pause)
+		}
+		return a.shared.action("Playing", a.shared.repos.Start)
+	case keys.Next.matches(msg):
+		return a.shared.action("Next track", a.shared.repos.Next)
+	case keys.Previous.matches(msg):
+		return a.shared.action("Previous track", a.shared.repos.Previous)
+	case keys.SeekBack.matches(msg):
+		return a.seek(-a.shared.config.SeekStep)
+	case keys.SeekAhead.matches(msg):
+		return a.seek(a.shared.config.SeekStep)
+	case keys.VolumeUp.matches(msg) && player != nil:
+		return a.setVolume(player.Volume.Increase(a.shared.config.VolumeStep))
+	case keys.VolumeDn.matches(msg) && player != nil:
+		return a.setVolume(player.Volume.Decrease(a.shared.config.VolumeStep))
+	case keys.Shuffle.matches(msg) && player != nil:
+		enabled := !player.Shuffle
+		info := "Shuffle off"
+		if enabled {
+			info = "Shuffle on"
+		}
+		return a.shared.action(info, func(ctx context.Context) error {
+			return a.shared.repos.SetShuffle(ctx, enabled)
+		})
+	case keys.Repeat.matches(msg) && player != nil:
+		mode := nextRepeatMode(player.Repeat)
+		return a.shared.action("Repeat "+mode.String(), func(ctx context.Context) error {
+			return a.shared.repos.SetRepeat(ctx, mode)
+		})
+	case keys.Refresh.matches(msg):
+		return tea.Batch(a.refreshStatus(), active.init())
+	}
+
+	return active.update(msg)
+}
+
+func (a *App) switchTo(index int) tea.Cmd {
+	a.active = index
+	return nil
+}
+
+// seek moves the playback position by delta, clamped to the current track.
+func (a *App) seek(delta time.Duration) tea.Cmd {
+	player, track := a.shared.player, a.shared.track
+	if player == nil || track == nil {
+		return nil
+	}
+
+	target := displayPosition(player, track, time.Now()).ToTime() + delta
+	if target < 0 {
+		target = 0
+	}
+	if length := track.Duration.ToTime(); length > 0 && target > length {
+		target = length
+	}
+	position := music.NewDurationFromTime(target)
+
+	return a.shared.action("Seeked to "+position.String(), func(ctx context.Context) error {
+		return a.shared.repos.Seek(ctx, position)
+	})
+}
+
+func (a *App) setVolume(volume music.Volume) tea.Cmd {
+	return a.shared.action("Volume "+volume.String(), func(ctx context.Context) error {
+		return a.shared.repos.SetVolume(ctx, volume)
+	})
+}
+
+// refreshStatus loads the player state and current track.
+func (a *App) refreshStatus() tea.Cmd {
+	ctx, repos := a.shared.ctx, a.shared.repos
+	return func() tea.Msg {
+		player, err := repos.GetCurrentState(ctx)
+		if err != nil {
+			return statusMsg{err: err}
+		}
+		var track *music.Track
+		if player.HasCurrentTrack() {
+			if track, err = repos.GetCurrentTrack(ctx); err != nil {
+				return statusMsg{err: err}
+			}
+		}
+		return statusMsg{player: player, track: track}
+	}
+}
+
+func (a *App) tick() tea.Cmd {
+	return tea.Tick(a.shared.config.RefreshInterval, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+// frame schedules the next redraw of the estimated playback position.
+func (a *App) frame() tea.Cmd {
+	if a.shared.config.FrameInterval <= 0 {
+		return nil
+	}
+	return tea.Tick(a.shared.config.FrameInterval, func(t time.Time) tea.Msg {
+		return frameMsg(t)
+	})
+}
+
+// waitForEvent waits for the next event, if an EventSource is configured.
+func (a *App) waitForEvent() tea.Cmd {
+	if a.events == nil {
+		return nil
+	}
+	events := a.events
+	return func() tea.Msg {
+		event, ok := <-events
+		if !ok {
+			return nil
+		}
+		return eventMsg(event)
+	}
+}
+
+// broadcast sends a non-key message to every view.
+func (a *App) broadcast(msg tea.Msg) tea.Cmd {
+	cmds := make([]tea.Cmd, 0, len(a.views))
+	for _, v := range a.views {
+		cmds = append(cmds, v.update(msg))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (a *App) renderHeader() string {
+	tabs := make([]string, 0, len(a.views)+1)
+	tabs = append(tabs, styles.title.Render("♫ Maestro")+" ")
+	for i, v := range a.views {
+		label := string(rune('1'+i)) + " " + v.title()
+		if i == a.active {
+			tabs = append(tabs, styles.activeTab.Render(label))
+		} else {
+			tabs = append(tabs, styles.tab.Render(label))
+		}
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, tabs...)
+}
+
+func (a *App) renderFooter() string {
+	lines := make([]string, 0, 3)
+
+	// The now playing view already shows the full status
+	if _, ok := a.views[a.active].(*nowPlayingView); !ok {
+		lines = append(lines, miniStatus(a.shared.player, a.shared.track, a.width))
+	}
+
+	switch {
+	case a.err != nil:
+		lines = append(lines, styles.err.Render("✗ "+a.err.Error()))
+	case a.notice != "":
+		lines = append(lines, styles.info.Render("✓ "+a.notice))
+	default:
+		lines = append(lines, "")
+	}
+
+	keys := a.shared.keys
+	lines = append(lines, helpLine(a.views[a.active].help()...))
+	lines = append(lines, helpLine(keys.NextView, keys.PlayPause, keys.Next, keys.Previous, keys.SeekAhead, keys.VolumeUp, keys.Search, keys.Quit))
+	return strings.Join(lines, "\n")
+}
+
+// nextRepeatMode cycles off → all → one → off.
+func nextRepeatMode(mode music.RepeatMode) music.RepeatMode {
+	modes := music.RepeatModes()
+	for i, m := range modes {
+		if m == mode {
+			return modes[(i+1)%len(modes)]
+		}
+	}
+	return music.RepeatModeOff
+}
@@ -0,0 +1,212 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/infrastructure/memory"
+)
+
+// harness drives an App synchronously against the in-memory backend.
+type harness struct {
+	t     *testing.T
+	app   *App
+	repos *memory.Repositories
+}
+
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+
+	config := DefaultConfig()
+	// Ticks never fire during a test; searches run almost immediately
+	config.RefreshInterval = time.Hour
+	config.FrameInterval = time.Hour
+	config.SearchDelay = time.Millisecond
+
+	repos := memory.NewRepositories(memory.DemoConfig())
+	h := &harness{t: t, app: NewApp(repos, config), repos: repos}
+	h.app.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	h.run(h.app.Init())
+	return h
+}
+
+// send delivers msg and every message its commands produce.
+func (h *harness) send(msg tea.Msg) {
+	h.t.Helper()
+	_, cmd := h.app.Update(msg)
+	h.run(cmd)
+}
+
+// keys sends each key in turn. Single characters are sent as runes.
+func (h *harness) keys(keys ...string) {
+	h.t.Helper()
+	for _, key := range keys {
+		switch key {
+		case "enter":
+			h.send(tea.KeyMsg{Type: tea.KeyEnter})
+		case "esc":
+			h.send(tea.KeyMsg{Type: tea.KeyEsc})
+		case "down":
+			h.send(tea.KeyMsg{Type: tea.KeyDown})
+		case "space":
+			h.send(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+		default:
+			h.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		}
+	}
+}
+
+// run executes cmd and feeds the resulting messages back into the app.
+// Commands that block, such as ticks, are abandoned.
+func (h *harness) run(cmd tea.Cmd) {
+	h.t.Helper()
+	for _, msg := range collect(cmd) {
+		h.send(msg)
+	}
+}
+
+func collect(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+
+	done := make(chan tea.Msg, 1)
+	go func() { done <- cmd() }()
+
+	select {
+	case msg := <-done:
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			var msgs []tea.Msg
+			for _, c := range batch {
+				msgs = append(msgs, collect(c)...)
+			}
+			return msgs
+		}
+		if msg == nil {
+			return nil
+		}
+		return []tea.Msg{msg}
+	case <-time.After(100 * time.Millisecond):
+		return nil
+	}
+}
+
+func TestLibraryBrowseAndPlay(t *testing.T) {
+	h := newHarness(t)
+
+	// Artists are sorted, so the first entries are Bill Evans Trio and
+	// their only album
+	h.keys("3", "enter", "enter")
+	if view := h.app.View(); !strings.Contains(view, "Bill Evans Trio › Waltz for Debby") {
+		t.Fatalf("breadcrumb missing from view:\n%s", view)
+	}
+
+	h.keys("down", "enter")
+	track, err := h.repos.GetCurrentTrack(context.Background())
+	if err != nil {
+		t.Fatalf("GetCurrentTrack: %v", err)
+	}
+	if track == nil || track.Title != "Waltz for Debby" {
+		t.Fatalf("playing %v, want Waltz for Debby", track)
+	}
+
+	// Going back up restores the album list
+	h.keys("esc")
+	if view := h.app.View(); strings.Contains(view, "My Foolish Heart") || !strings.Contains(view, "Artists › Bill Evans Trio") {
+		t.Fatalf("expected album level after esc:\n%s", view)
+	}
+}
+
+func TestNowPlayingAndTransport(t *testing.T) {
+	h := newHarness(t)
+
+	if view := h.app.View(); !strings.Contains(view, "Nothing playing") {
+		t.Fatalf("expected empty now playing view:\n%s", view)
+	}
+
+	h.keys("3", "enter", "enter", "enter", "1")
+	view := h.app.View()
+	for _, want := range []string{"My Foolish Heart", "Bill Evans Trio", "Waltz for Debby", "0:00 / 4:56"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("now playing view missing %q:\n%s", want, view)
+		}
+	}
+
+	h.keys("space")
+	player, _ := h.repos.GetCurrentState(context.Background())
+	if !player.IsPaused() {
+		t.Errorf("space should pause, state is %s", player.State)
+	}
+
+	h.keys("n")
+	track, _ := h.repos.GetCurrentTrack(context.Background())
+	if track == nil || track.Title != "Waltz for Debby" {
+		t.Errorf("next should play Waltz for Debby, got %v", track)
+	}
+
+	h.keys("+", "s", "r")
+	player, _ = h.repos.GetCurrentState(context.Background())
+	if player.Volume.Level() != 55 || !player.Shuffle || player.Repeat.String() != "all" {
+		t.Errorf("volume %d shuffle %v repeat %s, want 55 true all", player.Volume.Level(), player.Shuffle, player.Repeat)
+	}
+
+	// Queued tracks are listed under up next
+	h.keys("3", "down", "down", "a", "1")
+	if view := h.app.View(); !strings.Contains(view, "Up next") || !strings.Contains(view, "Detour Ahead") {
+		t.Errorf("now playing view missing up next:\n%s", view)
+	}
+}
+
+func TestSearchAndQueue(t *testing.T) {
+	h := newHarness(t)
+
+	h.keys("/", "t", "a", "k", "e", " ", "f")
+	if view := h.app.View(); !strings.Contains(view, "1 result") || !strings.Contains(view, "Take Five") {
+		t.Fatalf("search results missing:\n%s", view)
+	}
+
+	// Letters are typed into the query until focus moves to the results
+	h.keys("enter", "a")
+	queue, err := h.repos.GetQueue(context.Background())
+	if err != nil {
+		t.Fatalf("GetQueue: %v", err)
+	}
+	if len(queue.Tracks) != 1 {
+		t.Fatalf("queue has %d tracks, want 1", len(queue.Tracks))
+	}
+
+	h.keys("2")
+	if view := h.app.View(); !strings.Contains(view, "1 track in queue") || !strings.Contains(view, "Take Five") {
+		t.Fatalf("queue view missing track:\n%s", view)
+	}
+
+	h.keys("x")
+	if view := h.app.View(); !strings.Contains(view, "The queue is empty") {
+		t.Fatalf("queue should be empty after remove:\n%s", view)
+	}
+}
+
+func TestDisplayPosition(t *testing.T) {
+	updated := time.Now()
+	player := music.NewPlayer()
+	player.State = music.PlayerStatePlaying
+	player.Position = music.NewDurationFromMillis(58500)
+	player.LastUpdated = updated
+
+	track, err := music.NewTrack(music.NewTrackID("1"), "Title", "Artist", "Album", music.NewDuration(60))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := displayPosition(player, track, updated.Add(time.Second)); got.Milliseconds() != 59500 {
+		t.Errorf("expected the position to advance between refreshes, got %dms", got.Milliseconds())
+	}
+	if got := displayPosition(player, track, updated.Add(time.Minute)); got != track.Duration {
+		t.Errorf("expected the estimate to stop at the end of the track, got %s", got)
+	}
+}
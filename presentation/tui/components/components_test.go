@@ -0,0 +1,99 @@
+package components
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+func TestProgressBar(t *testing.T) {
+	tests := []struct {
+		name               string
+		position, duration int
+		want               string
+	}{
+		{"start", 0, 100, "●─────────"},
+		{"half", 50, 100, "━━━━━●────"},
+		{"end", 100, 100, "━━━━━━━━━●"},
+		{"past end", 150, 100, "━━━━━━━━━●"},
+		{"unknown duration", 30, 0, "──────────"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ProgressBar(10, music.NewDuration(tt.position), music.NewDuration(tt.duration))
+			if got != tt.want {
+				t.Errorf("ProgressBar = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevelBar(t *testing.T) {
+	if got := LevelBar(10, 55); got != "██████░░░░" {
+		t.Errorf("LevelBar(10, 55) = %q", got)
+	}
+	if got := LevelBar(4, 150); got != "████" {
+		t.Errorf("LevelBar(4, 150) = %q", got)
+	}
+}
+
+func TestListScrollsToCursor(t *testing.T) {
+	var list List
+	if list.Cursor() != -1 {
+		t.Fatalf("empty list cursor = %d, want -1", list.Cursor())
+	}
+
+	list.SetRows([]string{"a", "b", "c", "d", "e"})
+	list.Move(3)
+	got := list.View(2, strings.ToUpper, "")
+	if got != "  c\n› D" {
+		t.Errorf("View = %q", got)
+	}
+
+	list.Move(10)
+	if list.Cursor() != 4 {
+		t.Errorf("cursor = %d, want 4", list.Cursor())
+	}
+
+	list.SetRows([]string{"a"})
+	if list.Cursor() != 0 {
+		t.Errorf("cursor after shrinking = %d, want 0", list.Cursor())
+	}
+}
+
+func TestTextInput(t *testing.T) {
+	input := TextInput{Prompt: "> ", Placeholder: "type"}
+	if got := input.View(false, func(s string) string { return "(" + s + ")" }); got != "> (type)" {
+		t.Errorf("placeholder view = %q", got)
+	}
+
+	input.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("miles")})
+	input.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	input.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("davis")})
+	if input.Value() != "miles davis" {
+		t.Fatalf("value = %q", input.Value())
+	}
+
+	if !input.Update(tea.KeyMsg{Type: tea.KeyCtrlW}) || input.Value() != "miles " {
+		t.Errorf("ctrl+w left %q", input.Value())
+	}
+	if !input.Update(tea.KeyMsg{Type: tea.KeyBackspace}) || input.Value() != "miles" {
+		t.Errorf("backspace left %q", input.Value())
+	}
+	if input.Update(tea.KeyMsg{Type: tea.KeyUp}) {
+		t.Error("non-editing key reported a change")
+	}
+}
+
+func TestTruncateAndPad(t *testing.T) {
+	if got := Truncate("Blue in Green", 6); got != "Blue …" {
+		t.Errorf("Truncate = %q", got)
+	}
+	if got := Pad("Naima", 7); got != "Naima  " {
+		t.Errorf("Pad = %q", got)
+	}
+}
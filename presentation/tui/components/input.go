@@ -0,0 +1,77 @@
+package components
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TextInput is a single-line text field that edits at the end of its value.
+type TextInput struct {
+	// Prompt is rendered before the value
+	Prompt string
+
+	// Placeholder is rendered when the value is empty
+	Placeholder string
+
+	value []rune
+}
+
+// Value returns the current text.
+func (t *TextInput) Value() string {
+	return string(t.value)
+}
+
+// SetValue replaces the current text.
+func (t *TextInput) SetValue(value string) {
+	t.value = []rune(value)
+}
+
+// Update applies an editing key and reports whether the value changed. Keys
+// that do not edit text are ignored.
+func (t *TextInput) Update(msg tea.KeyMsg) bool {
+	switch msg.Type {
+	case tea.KeyRunes, tea.KeySpace:
+		t.value = append(t.value, msg.Runes...)
+		return len(msg.Runes) > 0
+	case tea.KeyBackspace:
+		if len(t.value) == 0 {
+			return false
+		}
+		t.value = t.value[:len(t.value)-1]
+		return true
+	case tea.KeyCtrlW:
+		return t.deleteWord()
+	case tea.KeyCtrlU:
+		if len(t.value) == 0 {
+			return false
+		}
+		t.value = nil
+		return true
+	}
+	return false
+}
+
+// View renders the prompt, the value or placeholder, and a cursor when focused.
+func (t *TextInput) View(focused bool, placeholderStyle func(string) string) string {
+	cursor := ""
+	if focused {
+		cursor = "▏"
+	}
+	if len(t.value) == 0 {
+		return t.Prompt + cursor + placeholderStyle(t.Placeholder)
+	}
+	return t.Prompt + string(t.value) + cursor
+}
+
+// deleteWord removes the last word and any spaces after it.
+func (t *TextInput) deleteWord() bool {
+	end := len(t.value)
+	i := end
+	for i > 0 && t.value[i-1] == ' ' {
+		i--
+	}
+	for i > 0 && t.value[i-1] != ' ' {
+		i--
+	}
+	t.value = t.value[:i]
+	return i != end
+}
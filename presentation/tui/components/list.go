@@ -0,0 +1,91 @@
+package components
+
+import (
+	"strings"
+)
+
+// List is a scrollable, selectable list of rows. Rows are pre-rendered
+// strings; the list only tracks the cursor and the scroll offset.
+type List struct {
+	rows   []string
+	cursor int
+	offset int
+}
+
+// SetRows replaces the rows, keeping the cursor in range.
+func (l *List) SetRows(rows []string) {
+	l.rows = rows
+	l.clamp()
+}
+
+// Len returns the number of rows.
+func (l *List) Len() int {
+	return len(l.rows)
+}
+
+// Cursor returns the index of the selected row, or -1 when the list is empty.
+func (l *List) Cursor() int {
+	if len(l.rows) == 0 {
+		return -1
+	}
+	return l.cursor
+}
+
+// Select moves the cursor to index, clamped to the rows.
+func (l *List) Select(index int) {
+	l.cursor = index
+	l.clamp()
+}
+
+// Move moves the cursor by delta rows, clamped to the rows.
+func (l *List) Move(delta int) {
+	l.Select(l.cursor + delta)
+}
+
+// View renders at most height rows, scrolled so the cursor is visible, with
+// the selected row rendered by highlight. Empty lists render placeholder.
+func (l *List) View(height int, highlight func(string) string, placeholder string) string {
+	if len(l.rows) == 0 {
+		return placeholder
+	}
+	if height <= 0 {
+		height = 1
+	}
+
+	if l.cursor < l.offset {
+		l.offset = l.cursor
+	}
+	if l.cursor >= l.offset+height {
+		l.offset = l.cursor - height + 1
+	}
+	if last := len(l.rows) - height; l.offset > last {
+		l.offset = last
+	}
+	if l.offset < 0 {
+		l.offset = 0
+	}
+
+	end := l.offset + height
+	if end > len(l.rows) {
+		end = len(l.rows)
+	}
+
+	lines := make([]string, 0, end-l.offset)
+	for i := l.offset; i < end; i++ {
+		if i == l.cursor {
+			lines = append(lines, highlight("› "+l.rows[i]))
+		} else {
+			lines = append(lines, "  "+l.rows[i])
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (l *List) clamp() {
+	if l.cursor >= len(l.rows) {
+		l.cursor = len(l.rows) - 1
+	}
+	if l.cursor < 0 {
+		l.cursor = 0
+	}
+}
@@ -0,0 +1,73 @@
+// Package components provides reusable widgets for the Maestro terminal UI.
+//
+// Components are plain values with rendering methods; they hold no references
+// to repositories and perform no I/O, so views compose them freely.
+package components
+
+import (
+	"strings"
+
+	"github.com/madstone-tech/maestro/domain/music"
+)
+
+// Progress bar glyphs.
+const (
+	progressFilled = "━"
+	progressHead   = "●"
+	progressEmpty  = "─"
+)
+
+// ProgressBar renders a bar of the given width showing how far position is
+// through a track of the given duration. Positions past the end are clamped;
+// an unknown (zero) duration renders an empty bar.
+func ProgressBar(width int, position, duration music.Duration) string {
+	if width <= 0 {
+		return ""
+	}
+
+	fraction := Fraction(position, duration)
+	filled := int(fraction * float64(width))
+	if filled >= width {
+		filled = width - 1
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.Repeat(progressFilled, filled))
+	if duration.Milliseconds() > 0 {
+		b.WriteString(progressHead)
+	} else {
+		b.WriteString(progressEmpty)
+	}
+	b.WriteString(strings.Repeat(progressEmpty, width-filled-1))
+	return b.String()
+}
+
+// Fraction returns how far position is through duration, between 0 and 1.
+func Fraction(position, duration music.Duration) float64 {
+	if duration.Milliseconds() <= 0 {
+		return 0
+	}
+	fraction := float64(position.Milliseconds()) / float64(duration.Milliseconds())
+	if fraction < 0 {
+		return 0
+	}
+	if fraction > 1 {
+		return 1
+	}
+	return fraction
+}
+
+// LevelBar renders a compact meter for a 0-100 level, such as the volume.
+func LevelBar(width int, level int) string {
+	if width <= 0 {
+		return ""
+	}
+	if level < 0 {
+		level = 0
+	}
+	if level > 100 {
+		level = 100
+	}
+	filled := (level*width + 50) / 100
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
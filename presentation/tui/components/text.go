@@ -0,0 +1,28 @@
+package components
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Truncate shortens s to at most width runes, ending with an ellipsis when
+// anything was cut.
+func Truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
+
+// Pad truncates or right-pads s with spaces to exactly width runes.
+func Pad(s string, width int) string {
+	s = Truncate(s, width)
+	if n := utf8.RuneCountInString(s); n < width {
+		s += strings.Repeat(" ", width-n)
+	}
+	return s
+}
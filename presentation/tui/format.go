@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/presentation/tui/components"
+)
+
+// stateIcon returns a glyph for the player state.
+func stateIcon(player *music.Player) string {
+	if player == nil {
+		return "■"
+	}
+	switch player.State {
+	case music.PlayerStatePlaying:
+		return "▶"
+	case music.PlayerStatePaused:
+		return "⏸"
+	case music.PlayerStateBuffering:
+		return "…"
+	default:
+		return "■"
+	}
+}
+
+// displayPosition returns the playback position to show at now: the
+// player's estimate, clamped to the track duration when it is known.
+func displayPosition(player *music.Player, track *music.Track, now time.Time) music.Duration {
+	position := player.EstimatedPosition(now)
+	if track != nil && track.Duration.Milliseconds() > 0 && position.Milliseconds() > track.Duration.Milliseconds() {
+		return track.Duration
+	}
+	return position
+}
+
+// miniStatus renders a one-line summary of the current track.
+func miniStatus(player *music.Player, track *music.Track, width int) string {
+	if player == nil || track == nil {
+		return styles.muted.Render(stateIcon(player) + " Nothing playing")
+	}
+	times := fmt.Sprintf(" %s / %s", displayPosition(player, track, time.Now()), track.Duration)
+	label := components.Truncate(fmt.Sprintf("%s %s – %s", stateIcon(player), track.Artist, track.Title), width-len(times))
+	return styles.current.Render(label) + styles.muted.Render(times)
+}
+
+// trackRow renders a track as aligned title, artist, album and duration
+// columns that fit width.
+func trackRow(track *music.Track, width int) string {
+	duration := track.Duration.String()
+	// Leave room for the list cursor and the gaps between columns
+	available := width - len(duration) - 8
+	if available < 12 {
+		return components.Truncate(track.Title, width-4)
+	}
+
+	titleWidth := available * 2 / 5
+	artistWidth := available * 3 / 10
+	albumWidth := available - titleWidth - artistWidth
+
+	return strings.Join([]string{
+		components.Pad(track.Title, titleWidth),
+		components.Pad(track.Artist, artistWidth),
+		components.Pad(track.Album, albumWidth),
+		duration,
+	}, "  ")
+}
+
+// trackRows renders every track with trackRow.
+func trackRows(tracks []*music.Track, width int) []string {
+	rows := make([]string, len(tracks))
+	for i, track := range tracks {
+		rows[i] = trackRow(track, width)
+	}
+	return rows
+}
+
+// trackIDs returns the IDs of tracks.
+func trackIDs(tracks []*music.Track) []music.TrackID {
+	ids := make([]music.TrackID, len(tracks))
+	for i, track := range tracks {
+		ids[i] = track.ID
+	}
+	return ids
+}
+
+// plural returns "1 track" or "n tracks".
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
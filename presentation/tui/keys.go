@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// binding is a set of keys that trigger one action, with its help text.
+type binding struct {
+	keys []string
+	help string
+}
+
+func newBinding(help string, keys ...string) binding {
+	return binding{keys: keys, help: help}
+}
+
+// matches returns true if the key message is one of the binding's keys.
+func (b binding) matches(msg tea.KeyMsg) bool {
+	key := msg.String()
+	for _, k := range b.keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// label returns the keys as shown in the help line.
+func (b binding) label() string {
+	labels := make([]string, len(b.keys))
+	for i, k := range b.keys {
+		switch k {
+		case " ":
+			labels[i] = "space"
+		case "up":
+			labels[i] = "↑"
+		case "down":
+			labels[i] = "↓"
+		case "left":
+			labels[i] = "←"
+		case "right":
+			labels[i] = "→"
+		default:
+			labels[i] = k
+		}
+	}
+	return strings.Join(labels, "/")
+}
+
+// KeyMap holds every key binding of the TUI.
+type KeyMap struct {
+	Quit      binding
+	NextView  binding
+	PrevView  binding
+	PlayPause binding
+	Next      binding
+	Previous  binding
+	SeekBack  binding
+	SeekAhead binding
+	VolumeUp  binding
+	VolumeDn  binding
+	Shuffle   binding
+	Repeat    binding
+	Up        binding
+	Down      binding
+	PageUp    binding
+	PageDown  binding
+	Select    binding
+	Back      binding
+	Enqueue   binding
+	PlayNext  binding
+	Remove    binding
+	Search    binding
+	Refresh   binding
+}
+
+// DefaultKeyMap returns the default key bindings.
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Quit:      newBinding("quit", "q", "ctrl+c"),
+		NextView:  newBinding("next view", "tab"),
+		PrevView:  newBinding("previous view", "shift+tab"),
+		PlayPause: newBinding("play/pause", " "),
+		Next:      newBinding("next", "n"),
+		Previous:  newBinding("previous", "p"),
+		SeekBack:  newBinding("back 10s", "left"),
+		SeekAhead: newBinding("ahead 10s", "right"),
+		VolumeUp:  newBinding("volume up", "+", "="),
+		VolumeDn:  newBinding("volume down", "-"),
+		Shuffle:   newBinding("shuffle", "s"),
+		Repeat:    newBinding("repeat", "r"),
+		Up:        newBinding("up", "up", "k"),
+		Down:      newBinding("down", "down", "j"),
+		PageUp:    newBinding("page up", "pgup"),
+		PageDown:  newBinding("page down", "pgdown"),
+		Select:    newBinding("select", "enter"),
+		Back:      newBinding("back", "esc", "backspace"),
+		Enqueue:   newBinding("add to queue", "a"),
+		PlayNext:  newBinding("play next", "A"),
+		Remove:    newBinding("remove", "x", "delete"),
+		Search:    newBinding("search", "/"),
+		Refresh:   newBinding("refresh", "ctrl+r"),
+	}
+}
+
+// helpLine renders bindings as "key action" pairs.
+func helpLine(bindings ...binding) string {
+	parts := make([]string, 0, len(bindings))
+	for _, b := range bindings {
+		parts = append(parts, styles.helpKey.Render(b.label())+" "+styles.help.Render(b.help))
+	}
+	return strings.Join(parts, styles.help.Render(" • "))
+}
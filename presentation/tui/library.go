@@ -0,0 +1,251 @@
+package tui
+
+import (
+	"context"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/presentation/tui/components"
+)
+
+// libraryLevel is the depth of the library browser.
+type libraryLevel int
+
+const (
+	levelArtists libraryLevel = iota
+	levelAlbums
+	levelTracks
+)
+
+// libraryMsg carries the entries loaded for a level.
+type libraryMsg struct {
+	level  libraryLevel
+	names  []string
+	tracks []*music.Track
+	err    error
+}
+
+// libraryView browses the library from artists to albums to tracks.
+type libraryView struct {
+	shared *shared
+	list   components.List
+	level  libraryLevel
+	artist string
+	album  string
+	width  int
+
+	// cursors remembers the selection of each level when descending
+	cursors [3]int
+
+	names  []string
+	tracks []*music.Track
+	err    error
+}
+
+func newLibraryView(s *shared) *libraryView {
+	return &libraryView{shared: s}
+}
+
+func (v *libraryView) title() string { return "Library" }
+
+func (v *libraryView) capturesInput() bool { return false }
+
+func (v *libraryView) help() []binding {
+	keys := v.shared.keys
+	open := newBinding("open", "enter")
+	if v.level == levelTracks {
+		open = newBinding("play", "enter")
+	}
+	return []binding{keys.Up, keys.Down, open, keys.Back, keys.Enqueue, keys.PlayNext}
+}
+
+func (v *libraryView) init() tea.Cmd {
+	return v.load(v.level)
+}
+
+// load fetches the entries of level for the current artist and album.
+func (v *libraryView) load(level libraryLevel) tea.Cmd {
+	ctx, repos := v.shared.ctx, v.shared.repos
+	artist, album := v.artist, v.album
+
+	return func() tea.Msg {
+		msg := libraryMsg{level: level}
+		switch level {
+		case levelArtists:
+			msg.names, msg.err = repos.GetArtists(ctx)
+		case levelAlbums:
+			msg.names, msg.err = repos.GetAlbumsByArtist(ctx, artist)
+		case levelTracks:
+			msg.tracks, msg.err = albumTracks(ctx, repos, artist, album)
+		}
+		return msg
+	}
+}
+
+func (v *libraryView) update(msg tea.Msg) tea.Cmd {
+	keys := v.shared.keys
+
+	switch msg := msg.(type) {
+	case libraryMsg:
+		if msg.level != v.level {
+			return nil
+		}
+		v.err = msg.err
+		v.names, v.tracks = msg.names, msg.tracks
+		v.refreshRows()
+		v.list.Select(v.cursors[v.level])
+	case tea.KeyMsg:
+		index := v.list.Cursor()
+		switch {
+		case keys.Up.matches(msg):
+			v.list.Move(-1)
+		case keys.Down.matches(msg):
+			v.list.Move(1)
+		case keys.PageUp.matches(msg):
+			v.list.Move(-10)
+		case keys.PageDown.matches(msg):
+			v.list.Move(10)
+		case keys.Back.matches(msg) && v.level > levelArtists:
+			v.cursors[v.level] = 0
+			v.level--
+			return v.load(v.level)
+		case keys.Select.matches(msg) && index >= 0:
+			return v.open(index)
+		case keys.Enqueue.matches(msg) && index >= 0:
+			return v.enqueue(index, false)
+		case keys.PlayNext.matches(msg) && index >= 0:
+			return v.enqueue(index, true)
+		}
+	}
+	return nil
+}
+
+// open descends into the selected artist or album, or plays the selected track.
+func (v *libraryView) open(index int) tea.Cmd {
+	v.cursors[v.level] = index
+
+	switch v.level {
+	case levelArtists:
+		v.artist = v.names[index]
+		v.level = levelAlbums
+	case levelAlbums:
+		v.album = v.names[index]
+		v.level = levelTracks
+	default:
+		track := v.tracks[index]
+		return v.shared.action("Playing "+track.Title, func(ctx context.Context) error {
+			return v.shared.repos.Play(ctx, track.ID)
+		})
+	}
+
+	v.cursors[v.level] = 0
+	v.list.SetRows(nil)
+	return v.load(v.level)
+}
+
+// enqueue adds the selected artist, album or track to the queue, either at
+// the end or right after the current track.
+func (v *libraryView) enqueue(index int, next bool) tea.Cmd {
+	repos := v.shared.repos
+	level, artist, album := v.level, v.artist, v.album
+
+	var label string
+	switch level {
+	case levelArtists:
+		artist = v.names[index]
+		label = artist
+	case levelAlbums:
+		album = v.names[index]
+		label = album
+	default:
+		label = v.tracks[index].Title
+	}
+	info := "Queued " + label
+	if next {
+		info = label + " plays next"
+	}
+
+	var track *music.Track
+	if level == levelTracks {
+		track = v.tracks[index]
+	}
+
+	return v.shared.action(info, func(ctx context.Context) error {
+		var tracks []*music.Track
+		var err error
+		switch level {
+		case levelArtists:
+			tracks, err = repos.GetTracksByArtist(ctx, artist)
+		case levelAlbums:
+			tracks, err = albumTracks(ctx, repos, artist, album)
+		default:
+			tracks = []*music.Track{track}
+		}
+		if err != nil {
+			return err
+		}
+		if !next {
+			return repos.AddTracksToQueue(ctx, trackIDs(tracks))
+		}
+		// PlayNext inserts before the previous insert, so go in reverse to
+		// keep the album order
+		for i := len(tracks) - 1; i >= 0; i-- {
+			if err := repos.PlayNext(ctx, tracks[i].ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (v *libraryView) render(width, height int) string {
+	if width != v.width {
+		v.width = width
+		v.refreshRows()
+	}
+
+	crumbs := []string{"Artists"}
+	if v.level >= levelAlbums {
+		crumbs = append(crumbs, v.artist)
+	}
+	if v.level >= levelTracks {
+		crumbs = append(crumbs, v.album)
+	}
+	header := styles.breadcrumb.Render(components.Truncate(strings.Join(crumbs, " › "), width))
+
+	if v.err != nil {
+		return header + "\n\n" + styles.err.Render(v.err.Error())
+	}
+	body := v.list.View(height-2, render(styles.selected), styles.muted.Render("Nothing here."))
+	return header + "\n\n" + body
+}
+
+func (v *libraryView) refreshRows() {
+	if v.level == levelTracks {
+		v.list.SetRows(trackRows(v.tracks, v.width))
+		return
+	}
+	rows := make([]string, len(v.names))
+	for i, name := range v.names {
+		rows[i] = components.Truncate(name, v.width-2)
+	}
+	v.list.SetRows(rows)
+}
+
+// albumTracks returns the tracks of album by artist. Albums are matched by
+// name only, so compilations that share a name are filtered by artist.
+func albumTracks(ctx context.Context, repos music.LibraryRepository, artist, album string) ([]*music.Track, error) {
+	tracks, err := repos.GetTracksByAlbum(ctx, album)
+	if err != nil {
+		return nil, err
+	}
+	filtered := tracks[:0]
+	for _, track := range tracks {
+		if strings.EqualFold(track.Artist, artist) {
+			filtered = append(filtered, track)
+		}
+	}
+	return filtered, nil
+}
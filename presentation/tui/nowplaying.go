@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/presentation/tui/components"
+)
+
+// upNextCount is how many upcoming tracks the now playing view lists.
+const upNextCount = 5
+
+// upNextMsg carries the tracks that will play next.
+type upNextMsg struct {
+	tracks []*music.Track
+	err    error
+}
+
+// nowPlayingView shows the current track, playback progress and settings.
+type nowPlayingView struct {
+	shared  *shared
+	upNext  []*music.Track
+	trackID *music.TrackID
+}
+
+func newNowPlayingView(s *shared) *nowPlayingView {
+	return &nowPlayingView{shared: s}
+}
+
+func (v *nowPlayingView) title() string { return "Now Playing" }
+
+func (v *nowPlayingView) capturesInput() bool { return false }
+
+func (v *nowPlayingView) help() []binding {
+	keys := v.shared.keys
+	return []binding{keys.SeekBack, keys.VolumeDn, keys.Shuffle, keys.Repeat}
+}
+
+func (v *nowPlayingView) init() tea.Cmd {
+	ctx, repos := v.shared.ctx, v.shared.repos
+	return func() tea.Msg {
+		tracks, err := repos.GetUpNext(ctx, upNextCount)
+		return upNextMsg{tracks: tracks, err: err}
+	}
+}
+
+func (v *nowPlayingView) update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case upNextMsg:
+		// Up next is informational; keep the last list on errors
+		if msg.err == nil {
+			v.upNext = msg.tracks
+		}
+	case statusMsg:
+		// Reload up next whenever the track changes
+		current := msg.player.CurrentTrack
+		changed := (current == nil) != (v.trackID == nil) || (current != nil && !current.Equals(*v.trackID))
+		v.trackID = current
+		if changed {
+			return v.init()
+		}
+	case eventMsg:
+		if msg.Type == music.EventQueueChanged {
+			return v.init()
+		}
+	case actionMsg:
+		return v.init()
+	}
+	return nil
+}
+
+func (v *nowPlayingView) render(width, height int) string {
+	player, track := v.shared.player, v.shared.track
+	if player == nil || track == nil {
+		return styles.muted.Render("Nothing playing.\n\nPick something from the library (3) or search (/).")
+	}
+
+	position := displayPosition(player, track, time.Now())
+	barWidth := width - 4
+	if barWidth > 60 {
+		barWidth = 60
+	}
+
+	lines := []string{
+		styles.trackTitle.Render(stateIcon(player) + "  " + track.Title),
+		styles.artist.Render(track.Artist),
+		styles.album.Render(track.Album),
+		"",
+		styles.progress.Render(components.ProgressBar(barWidth, position, track.Duration)),
+		styles.muted.Render(fmt.Sprintf("%s / %s", position, track.Duration)),
+		"",
+		fmt.Sprintf("Volume %s %s   Shuffle %s   Repeat %s",
+			components.LevelBar(10, player.Volume.Level()), player.Volume,
+			onOff(player.Shuffle), player.Repeat),
+	}
+
+	if len(v.upNext) > 0 {
+		lines = append(lines, "", styles.breadcrumb.Render("Up next"))
+		for _, next := range v.upNext {
+			lines = append(lines, "  "+components.Truncate(next.Title+" – "+next.Artist, width-2))
+		}
+	}
+
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func onOff(enabled bool) string {
+	if enabled {
+		return "on"
+	}
+	return "off"
+}
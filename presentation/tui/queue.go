@@ -0,0 +1,127 @@
+package tui
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/presentation/tui/components"
+)
+
+// queueMsg carries the loaded queue.
+type queueMsg struct {
+	tracks   []*music.Track
+	position int
+	err      error
+}
+
+// queueView lists the playback queue and marks the current entry.
+type queueView struct {
+	shared   *shared
+	list     components.List
+	tracks   []*music.Track
+	position int
+	err      error
+	width    int
+}
+
+func newQueueView(s *shared) *queueView {
+	return &queueView{shared: s, position: -1}
+}
+
+func (v *queueView) title() string { return "Queue" }
+
+func (v *queueView) capturesInput() bool { return false }
+
+func (v *queueView) help() []binding {
+	keys := v.shared.keys
+	return []binding{keys.Up, keys.Down, newBinding("play", "enter"), keys.Remove}
+}
+
+func (v *queueView) init() tea.Cmd {
+	ctx, repos := v.shared.ctx, v.shared.repos
+	return func() tea.Msg {
+		queue, err := repos.GetQueue(ctx)
+		if err != nil {
+			return queueMsg{err: err}
+		}
+		tracks, err := repos.GetTracks(ctx, queue.Tracks)
+		if err != nil {
+			return queueMsg{err: err}
+		}
+		position, err := repos.GetQueuePosition(ctx)
+		if err != nil {
+			return queueMsg{err: err}
+		}
+		return queueMsg{tracks: tracks, position: position}
+	}
+}
+
+func (v *queueView) update(msg tea.Msg) tea.Cmd {
+	keys := v.shared.keys
+
+	switch msg := msg.(type) {
+	case queueMsg:
+		v.err = msg.err
+		if msg.err == nil {
+			v.tracks, v.position = msg.tracks, msg.position
+			v.refreshRows()
+		}
+	case eventMsg:
+		if msg.Type == music.EventQueueChanged || msg.Type == music.EventTrackChanged {
+			return v.init()
+		}
+	case actionMsg:
+		return v.init()
+	case tea.KeyMsg:
+		index := v.list.Cursor()
+		switch {
+		case keys.Up.matches(msg):
+			v.list.Move(-1)
+		case keys.Down.matches(msg):
+			v.list.Move(1)
+		case keys.PageUp.matches(msg):
+			v.list.Move(-10)
+		case keys.PageDown.matches(msg):
+			v.list.Move(10)
+		case keys.Select.matches(msg) && index >= 0:
+			return v.shared.action("Playing "+v.tracks[index].Title, func(ctx context.Context) error {
+				return v.shared.repos.SetQueuePosition(ctx, index)
+			})
+		case keys.Remove.matches(msg) && index >= 0:
+			return v.shared.action("Removed "+v.tracks[index].Title, func(ctx context.Context) error {
+				return v.shared.repos.RemoveFromQueue(ctx, index)
+			})
+		}
+	}
+	return nil
+}
+
+func (v *queueView) render(width, height int) string {
+	if v.err != nil {
+		return styles.err.Render(v.err.Error())
+	}
+	if width != v.width {
+		v.width = width
+		v.refreshRows()
+	}
+
+	header := styles.breadcrumb.Render(plural(len(v.tracks), "track") + " in queue")
+	body := v.list.View(height-2, render(styles.selected), styles.muted.Render("The queue is empty. Press a in the library or search to add tracks."))
+	return header + "\n\n" + body
+}
+
+// refreshRows re-renders the rows, marking the playing entry.
+func (v *queueView) refreshRows() {
+	width := v.width - 2
+	rows := make([]string, len(v.tracks))
+	for i, track := range v.tracks {
+		if i == v.position {
+			rows[i] = styles.current.Render("♪ " + trackRow(track, width))
+		} else {
+			rows[i] = "  " + trackRow(track, width)
+		}
+	}
+	v.list.SetRows(rows)
+}
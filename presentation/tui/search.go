@@ -0,0 +1,185 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/madstone-tech/maestro/domain/music"
+	"github.com/madstone-tech/maestro/presentation/tui/components"
+)
+
+type (
+	// searchDebounceMsg fires when typing has paused; seq identifies the
+	// keystroke that scheduled it so stale timers are ignored
+	searchDebounceMsg struct {
+		seq int
+	}
+
+	// searchResultsMsg carries the results of the search started at seq
+	searchResultsMsg struct {
+		seq    int
+		tracks []*music.Track
+		err    error
+	}
+)
+
+// searchView searches the library as the user types.
+type searchView struct {
+	shared  *shared
+	input   components.TextInput
+	list    components.List
+	focused bool
+	seq     int
+	query   string
+	results []*music.Track
+	err     error
+	width   int
+}
+
+func newSearchView(s *shared) *searchView {
+	return &searchView{
+		shared:  s,
+		input:   components.TextInput{Prompt: "Search: ", Placeholder: "title, artist or album"},
+		focused: true,
+	}
+}
+
+func (v *searchView) title() string { return "Search" }
+
+func (v *searchView) capturesInput() bool { return v.focused }
+
+func (v *searchView) help() []binding {
+	keys := v.shared.keys
+	if v.focused {
+		return []binding{newBinding("results", "enter", "down"), newBinding("leave input", "esc")}
+	}
+	return []binding{keys.Up, keys.Down, newBinding("play", "enter"), keys.Enqueue, keys.PlayNext, newBinding("edit query", "/", "esc")}
+}
+
+func (v *searchView) init() tea.Cmd { return nil }
+
+func (v *searchView) update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case focusSearchMsg:
+		v.focused = true
+	case searchDebounceMsg:
+		if msg.seq == v.seq {
+			return v.search(msg.seq, v.input.Value())
+		}
+	case searchResultsMsg:
+		if msg.seq == v.seq {
+			v.err = msg.err
+			v.results = msg.tracks
+			v.list.SetRows(trackRows(v.results, v.width))
+			v.list.Select(0)
+		}
+	case tea.KeyMsg:
+		if v.focused {
+			return v.updateInput(msg)
+		}
+		return v.updateResults(msg)
+	}
+	return nil
+}
+
+// updateInput edits the query and schedules a search once typing pauses.
+func (v *searchView) updateInput(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEsc:
+		v.focused = false
+		return nil
+	case tea.KeyEnter, tea.KeyDown:
+		if v.list.Len() > 0 {
+			v.focused = false
+		}
+		return nil
+	}
+
+	if !v.input.Update(msg) {
+		return nil
+	}
+	v.seq++
+	seq := v.seq
+	return tea.Tick(v.shared.config.SearchDelay, func(time.Time) tea.Msg {
+		return searchDebounceMsg{seq: seq}
+	})
+}
+
+func (v *searchView) updateResults(msg tea.KeyMsg) tea.Cmd {
+	keys := v.shared.keys
+	index := v.list.Cursor()
+
+	switch {
+	case keys.Back.matches(msg), keys.Search.matches(msg):
+		v.focused = true
+	case keys.Up.matches(msg) && index == 0:
+		v.focused = true
+	case keys.Up.matches(msg):
+		v.list.Move(-1)
+	case keys.Down.matches(msg):
+		v.list.Move(1)
+	case keys.PageUp.matches(msg):
+		v.list.Move(-10)
+	case keys.PageDown.matches(msg):
+		v.list.Move(10)
+	case keys.Select.matches(msg) && index >= 0:
+		track := v.results[index]
+		return v.shared.action("Playing "+track.Title, func(ctx context.Context) error {
+			return v.shared.repos.Play(ctx, track.ID)
+		})
+	case keys.Enqueue.matches(msg) && index >= 0:
+		track := v.results[index]
+		return v.shared.action("Queued "+track.Title, func(ctx context.Context) error {
+			return v.shared.repos.AddToQueue(ctx, track.ID)
+		})
+	case keys.PlayNext.matches(msg) && index >= 0:
+		track := v.results[index]
+		return v.shared.action(track.Title+" plays next", func(ctx context.Context) error {
+			return v.shared.repos.PlayNext(ctx, track.ID)
+		})
+	}
+	return nil
+}
+
+// search runs the query; an empty query clears the results.
+func (v *searchView) search(seq int, query string) tea.Cmd {
+	query = strings.TrimSpace(query)
+	v.query = query
+	if query == "" {
+		return func() tea.Msg { return searchResultsMsg{seq: seq} }
+	}
+
+	ctx, repos, limit := v.shared.ctx, v.shared.repos, v.shared.config.SearchLimit
+	return func() tea.Msg {
+		tracks, err := repos.Search(ctx, music.LibrarySearchOptions{Query: query, Limit: limit})
+		return searchResultsMsg{seq: seq, tracks: tracks, err: err}
+	}
+}
+
+func (v *searchView) render(width, height int) string {
+	if width != v.width {
+		v.width = width
+		v.list.SetRows(trackRows(v.results, width))
+	}
+
+	header := v.input.View(v.focused, render(styles.placeholder))
+
+	var body string
+	switch {
+	case v.err != nil:
+		body = styles.err.Render(v.err.Error())
+	case v.query == "":
+		body = styles.muted.Render("Start typing to search the library.")
+	default:
+		summary := styles.breadcrumb.Render(plural(len(v.results), "result"))
+		highlight := render(styles.selected)
+		if v.focused {
+			highlight = func(s string) string { return s }
+		}
+		body = summary + "\n" + v.list.View(height-3, highlight, styles.muted.Render("No matches."))
+	}
+	return header + "\n\n" + body
+}
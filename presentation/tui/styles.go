@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"github.com/charmbracelet/lipgloss"
+)
+
+// styles holds the lipgloss styles shared by every view.
+var styles = struct {
+	title       lipgloss.Style
+	activeTab   lipgloss.Style
+	tab         lipgloss.Style
+	trackTitle  lipgloss.Style
+	artist      lipgloss.Style
+	album       lipgloss.Style
+	progress    lipgloss.Style
+	muted       lipgloss.Style
+	selected    lipgloss.Style
+	current     lipgloss.Style
+	breadcrumb  lipgloss.Style
+	err         lipgloss.Style
+	info        lipgloss.Style
+	help        lipgloss.Style
+	helpKey     lipgloss.Style
+	placeholder lipgloss.Style
+}{
+	title:       lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212")),
+	activeTab:   lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("230")).Background(lipgloss.Color("62")).Padding(0, 1),
+	tab:         lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Padding(0, 1),
+	trackTitle:  lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("230")),
+	artist:      lipgloss.NewStyle().Foreground(lipgloss.Color("212")),
+	album:       lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Italic(true),
+	progress:    lipgloss.NewStyle().Foreground(lipgloss.Color("62")),
+	muted:       lipgloss.NewStyle().Foreground(lipgloss.Color("241")),
+	selected:    lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212")),
+	current:     lipgloss.NewStyle().Foreground(lipgloss.Color("42")),
+	breadcrumb:  lipgloss.NewStyle().Foreground(lipgloss.Color("111")),
+	err:         lipgloss.NewStyle().Foreground(lipgloss.Color("203")),
+	info:        lipgloss.NewStyle().Foreground(lipgloss.Color("42")),
+	help:        lipgloss.NewStyle().Foreground(lipgloss.Color("241")),
+	helpKey:     lipgloss.NewStyle().Foreground(lipgloss.Color("250")),
+	placeholder: lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
+}
+
+// render adapts a style to the func(string) string used by components.
+func render(style lipgloss.Style) func(string) string {
+	return func(s string) string { return style.Render(s) }
+}